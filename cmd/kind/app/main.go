@@ -17,6 +17,9 @@ limitations under the License.
 package app
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 
@@ -30,28 +33,36 @@ import (
 )
 
 // Main is the kind main(), it will invoke Run(), if an error is returned
-// it will then call os.Exit
+// it will then call os.Exit with the exit code for the error
+// See: sigs.k8s.io/kind/pkg/errors.ExitCodeForError
 func Main() {
 	if err := Run(cmd.NewLogger(), cmd.StandardIOStreams(), os.Args[1:]); err != nil {
-		os.Exit(1)
+		os.Exit(errors.ExitCodeForError(err))
 	}
 }
 
 // Run invokes the kind root command, returning the error.
 // See: sigs.k8s.io/kind/pkg/cmd/kind
 func Run(logger log.Logger, streams cmd.IOStreams, args []string) error {
+	jsonErrors := checkErrorFormat(args) == "json"
+	// errors are still written in quiet mode
+	errOut := streams.ErrOut
 	// NOTE: we handle the quiet flag here so we can fully silence cobra
 	if checkQuiet(args) {
 		// if we are in quiet mode, we want to suppress all status output
-		// only streams.Out should be written to (program output)
-		logger = log.NoopLogger{}
+		// only streams.Out (program output) and errors should be written,
+		// the root command sets the logger to only write errors
 		streams.ErrOut = ioutil.Discard
 	}
 	// actually run the command
 	c := kind.NewCommand(logger, streams)
 	c.SetArgs(args)
 	if err := c.Execute(); err != nil {
-		logError(logger, err)
+		if jsonErrors {
+			writeJSONError(errOut, err)
+		} else {
+			logError(logger, err)
+		}
 		return err
 	}
 	return nil
@@ -67,7 +78,7 @@ func checkQuiet(args []string) bool {
 		"quiet",
 		"q",
 		false,
-		"silence all stderr output",
+		"silence all stderr output except errors",
 	)
 	// NOTE: pflag will error if -h / --help is specified
 	// We don't care here. That will be handled downstream
@@ -77,6 +88,51 @@ func checkQuiet(args []string) bool {
 	return quiet
 }
 
+// checkErrorFormat returns the value of --error-format in args
+func checkErrorFormat(args []string) string {
+	flags := pflag.NewFlagSet("persistent-error-format", pflag.ContinueOnError)
+	flags.ParseErrorsWhitelist.UnknownFlags = true
+	format := ""
+	flags.StringVar(
+		&format,
+		"error-format",
+		"text",
+		"format for errors written to stderr, one of: text, json",
+	)
+	// see checkQuiet
+	flags.Usage = func() {}
+	_ = flags.Parse(args)
+	return format
+}
+
+// jsonError is the --error-format=json representation of an error
+type jsonError struct {
+	Error    string `json:"error"`
+	Reason   string `json:"reason,omitempty"`
+	ExitCode int    `json:"exitCode"`
+	Output   string `json:"commandOutput,omitempty"`
+}
+
+// writeJSONError writes err to w as a single line of JSON
+func writeJSONError(w io.Writer, err error) {
+	out := jsonError{
+		Error:    err.Error(),
+		ExitCode: errors.ExitCodeForError(err),
+	}
+	if r := errors.ReasonForError(err); r != nil {
+		out.Reason = r.Code
+	}
+	if runErr := exec.RunErrorForError(err); runErr != nil {
+		out.Output = string(runErr.Output)
+	}
+	b, jerr := json.Marshal(out)
+	if jerr != nil {
+		fmt.Fprintf(w, "ERROR: %v\n", err)
+		return
+	}
+	fmt.Fprintln(w, string(b))
+}
+
 // logError logs the error and the root stacktrace if there is one
 func logError(logger log.Logger, err error) {
 	colorEnabled := cmd.ColorEnabled(logger)
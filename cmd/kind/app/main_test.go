@@ -17,7 +17,11 @@ limitations under the License.
 package app
 
 import (
+	"bytes"
 	"testing"
+
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/log"
 )
 
 func TestCheckQuiet(t *testing.T) {
@@ -78,3 +82,42 @@ func TestCheckQuiet(t *testing.T) {
 		})
 	}
 }
+
+func TestErrorFormat(t *testing.T) {
+	cases := []struct {
+		Name          string
+		Args          []string
+		ExpectedError bool
+	}{
+		{
+			Name: "default",
+			Args: []string{"version"},
+		},
+		{
+			Name: "text",
+			Args: []string{"--error-format", "text", "version"},
+		},
+		{
+			Name: "json",
+			Args: []string{"--error-format=json", "version"},
+		},
+		{
+			Name:          "unknown",
+			Args:          []string{"--error-format", "xml", "version"},
+			ExpectedError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			var out, errOut bytes.Buffer
+			streams := cmd.IOStreams{In: &bytes.Buffer{}, Out: &out, ErrOut: &errOut}
+			err := Run(log.NoopLogger{}, streams, tc.Args)
+			if tc.ExpectedError && err == nil {
+				t.Errorf("expected an error")
+			} else if !tc.ExpectedError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package builder implements a fluent builder for v1alpha4 cluster configs
+//
+// Example:
+//
+//	cfg, err := builder.NewCluster().
+//		WithWorkers(3).
+//		WithPortMapping(v1alpha4.PortMapping{ContainerPort: 80, HostPort: 8080}).
+//		Build()
+package builder
+
+import (
+	"sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// ClusterBuilder builds a v1alpha4.Cluster, see NewCluster
+// The zero value is not usable
+type ClusterBuilder struct {
+	cluster       v1alpha4.Cluster
+	controlPlanes int
+	workers       int
+	nodeImage     string
+	// portMappings are added to the first control plane node
+	portMappings []v1alpha4.PortMapping
+	// mounts are added to all nodes
+	mounts []v1alpha4.Mount
+	// extraNodes are appended after the control planes and workers
+	extraNodes []v1alpha4.Node
+}
+
+// NewCluster returns a new ClusterBuilder for a single control plane cluster
+func NewCluster() *ClusterBuilder {
+	return &ClusterBuilder{
+		cluster: v1alpha4.Cluster{
+			TypeMeta: v1alpha4.TypeMeta{
+				Kind:       "Cluster",
+				APIVersion: "kind.x-k8s.io/v1alpha4",
+			},
+		},
+		controlPlanes: 1,
+	}
+}
+
+// WithName sets the cluster name
+func (b *ClusterBuilder) WithName(name string) *ClusterBuilder {
+	b.cluster.Name = name
+	return b
+}
+
+// WithControlPlanes sets the number of control plane nodes
+func (b *ClusterBuilder) WithControlPlanes(n int) *ClusterBuilder {
+	b.controlPlanes = n
+	return b
+}
+
+// WithWorkers sets the number of worker nodes
+func (b *ClusterBuilder) WithWorkers(n int) *ClusterBuilder {
+	b.workers = n
+	return b
+}
+
+// WithNode appends a node with a custom configuration, in addition to
+// those from WithControlPlanes and WithWorkers
+func (b *ClusterBuilder) WithNode(node v1alpha4.Node) *ClusterBuilder {
+	b.extraNodes = append(b.extraNodes, *node.DeepCopy())
+	return b
+}
+
+// WithNodeImage sets the image for all nodes that do not specify one
+func (b *ClusterBuilder) WithNodeImage(image string) *ClusterBuilder {
+	b.nodeImage = image
+	return b
+}
+
+// WithPortMapping adds an extra port mapping to the first control plane node
+func (b *ClusterBuilder) WithPortMapping(mapping v1alpha4.PortMapping) *ClusterBuilder {
+	b.portMappings = append(b.portMappings, mapping)
+	return b
+}
+
+// WithMount adds an extra mount to all nodes
+func (b *ClusterBuilder) WithMount(mount v1alpha4.Mount) *ClusterBuilder {
+	b.mounts = append(b.mounts, mount)
+	return b
+}
+
+// WithIPFamily sets the cluster IP family
+func (b *ClusterBuilder) WithIPFamily(family v1alpha4.ClusterIPFamily) *ClusterBuilder {
+	b.cluster.Networking.IPFamily = family
+	return b
+}
+
+// WithAPIServer sets the listen address and port of the API server on the host
+func (b *ClusterBuilder) WithAPIServer(address string, port int32) *ClusterBuilder {
+	b.cluster.Networking.APIServerAddress = address
+	b.cluster.Networking.APIServerPort = port
+	return b
+}
+
+// WithPodSubnet sets the pod subnet
+func (b *ClusterBuilder) WithPodSubnet(subnet string) *ClusterBuilder {
+	b.cluster.Networking.PodSubnet = subnet
+	return b
+}
+
+// WithServiceSubnet sets the service subnet
+func (b *ClusterBuilder) WithServiceSubnet(subnet string) *ClusterBuilder {
+	b.cluster.Networking.ServiceSubnet = subnet
+	return b
+}
+
+// WithKubeProxyMode sets the kube-proxy mode
+func (b *ClusterBuilder) WithKubeProxyMode(mode v1alpha4.ProxyMode) *ClusterBuilder {
+	b.cluster.Networking.KubeProxyMode = mode
+	return b
+}
+
+// WithoutDefaultCNI disables installing the default CNI
+func (b *ClusterBuilder) WithoutDefaultCNI() *ClusterBuilder {
+	b.cluster.Networking.DisableDefaultCNI = true
+	return b
+}
+
+// WithPresets enables optional presets, e.g. v1alpha4.ObservabilityPreset
+func (b *ClusterBuilder) WithPresets(presets ...v1alpha4.Preset) *ClusterBuilder {
+	b.cluster.Presets = append(b.cluster.Presets, presets...)
+	return b
+}
+
+// WithFeatureGate sets a Kubernetes feature gate on all components
+func (b *ClusterBuilder) WithFeatureGate(name string, enabled bool) *ClusterBuilder {
+	if b.cluster.FeatureGates == nil {
+		b.cluster.FeatureGates = map[string]bool{}
+	}
+	b.cluster.FeatureGates[name] = enabled
+	return b
+}
+
+// WithKubeadmConfigPatches adds cluster wide kubeadm config patches
+func (b *ClusterBuilder) WithKubeadmConfigPatches(patches ...string) *ClusterBuilder {
+	b.cluster.KubeadmConfigPatches = append(b.cluster.KubeadmConfigPatches, patches...)
+	return b
+}
+
+// WithContainerdConfigPatches adds containerd config patches
+func (b *ClusterBuilder) WithContainerdConfigPatches(patches ...string) *ClusterBuilder {
+	b.cluster.ContainerdConfigPatches = append(b.cluster.ContainerdConfigPatches, patches...)
+	return b
+}
+
+// Build returns a defaulted copy of the config, or an error if it is invalid
+// The builder may continue to be used after calling Build
+func (b *ClusterBuilder) Build() (*v1alpha4.Cluster, error) {
+	if b.controlPlanes < 1 {
+		return nil, errors.Errorf("a cluster requires at least one control plane node, got %d", b.controlPlanes)
+	}
+	if b.workers < 0 {
+		return nil, errors.Errorf("invalid number of worker nodes: %d", b.workers)
+	}
+	out := b.cluster.DeepCopy()
+	out.Nodes = nil
+	for i := 0; i < b.controlPlanes; i++ {
+		out.Nodes = append(out.Nodes, v1alpha4.Node{Role: v1alpha4.ControlPlaneRole})
+	}
+	for i := 0; i < b.workers; i++ {
+		out.Nodes = append(out.Nodes, v1alpha4.Node{Role: v1alpha4.WorkerRole})
+	}
+	for i := range b.extraNodes {
+		out.Nodes = append(out.Nodes, *b.extraNodes[i].DeepCopy())
+	}
+	for i := range out.Nodes {
+		n := &out.Nodes[i]
+		if n.Image == "" {
+			n.Image = b.nodeImage
+		}
+		n.ExtraMounts = append(n.ExtraMounts, b.mounts...)
+	}
+	out.Nodes[0].ExtraPortMappings = append(out.Nodes[0].ExtraPortMappings, b.portMappings...)
+	v1alpha4.SetDefaultsCluster(out)
+
+	// validate using the same rules as kind create cluster
+	internal := config.Convertv1alpha4(out.DeepCopy())
+	config.SetDefaultsCluster(internal)
+	if err := internal.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid cluster config")
+	}
+	return out, nil
+}
+
+// MustBuild is like Build but panics if the config is invalid
+func (b *ClusterBuilder) MustBuild() *v1alpha4.Cluster {
+	cfg, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return cfg
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
+)
+
+func TestBuild(t *testing.T) {
+	t.Parallel()
+	cfg, err := NewCluster().
+		WithName("test").
+		WithWorkers(2).
+		WithNodeImage("kindest/node:test").
+		WithPortMapping(v1alpha4.PortMapping{ContainerPort: 80, HostPort: 8080}).
+		WithMount(v1alpha4.Mount{HostPath: "/tmp", ContainerPath: "/host-tmp"}).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Nodes) != 3 {
+		t.Fatalf("expected 3 nodes, got %d", len(cfg.Nodes))
+	}
+	if cfg.Nodes[0].Role != v1alpha4.ControlPlaneRole || cfg.Nodes[2].Role != v1alpha4.WorkerRole {
+		t.Errorf("unexpected node roles: %q, %q", cfg.Nodes[0].Role, cfg.Nodes[2].Role)
+	}
+	for _, n := range cfg.Nodes {
+		if n.Image != "kindest/node:test" {
+			t.Errorf("expected node image to be set, got %q", n.Image)
+		}
+		if len(n.ExtraMounts) != 1 {
+			t.Errorf("expected one mount on every node, got %d", len(n.ExtraMounts))
+		}
+	}
+	if len(cfg.Nodes[0].ExtraPortMappings) != 1 || len(cfg.Nodes[1].ExtraPortMappings) != 0 {
+		t.Errorf("expected port mapping only on the first control plane")
+	}
+	if cfg.Networking.PodSubnet == "" {
+		t.Errorf("expected networking to be defaulted")
+	}
+}
+
+func TestBuildInvalid(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name    string
+		Builder *ClusterBuilder
+	}{
+		{
+			Name:    "no control planes",
+			Builder: NewCluster().WithControlPlanes(0),
+		},
+		{
+			Name:    "negative workers",
+			Builder: NewCluster().WithWorkers(-1),
+		},
+		{
+			Name:    "bogus pod subnet",
+			Builder: NewCluster().WithPodSubnet("aa"),
+		},
+		{
+			Name:    "bogus port mapping",
+			Builder: NewCluster().WithPortMapping(v1alpha4.PortMapping{ContainerPort: 80, HostPort: 999999}),
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := tc.Builder.Build(); err == nil {
+				t.Errorf("expected an error building an invalid config")
+			}
+		})
+	}
+}
@@ -30,6 +30,12 @@ type Cluster struct {
 	// control plane load balancer will be provisioned implicitly
 	Nodes []Node `yaml:"nodes,omitempty"`
 
+	// NodeNameTemplate generates the names of nodes without a name, e.g.
+	// "{{cluster}}-{{role}}-{{index}}", where {{index}} counts nodes of the same
+	// role from 1. If unset nodes are named like "kind-control-plane" and
+	// "kind-worker2".
+	NodeNameTemplate string `yaml:"nodeNameTemplate,omitempty"`
+
 	/* Advanced fields */
 
 	// Networking contains cluster wide network settings
@@ -41,6 +47,29 @@ type Cluster struct {
 	// https://kubernetes.io/docs/reference/command-line-tools-reference/feature-gates/
 	FeatureGates map[string]bool `yaml:"featureGates,omitempty"`
 
+	// ComponentFeatureGates contains feature gates for individual Kubernetes
+	// components, these are merged over FeatureGates for that component only.
+	ComponentFeatureGates ComponentFeatureGates `yaml:"componentFeatureGates,omitempty"`
+
+	// RuntimeConfig is passed to kube-apiserver as --runtime-config, it enables
+	// or disables API group versions, e.g. `"api/alpha": "true"` or
+	// `"batch/v2alpha1": "false"`.
+	//
+	// https://kubernetes.io/docs/reference/command-line-tools-reference/kube-apiserver/
+	RuntimeConfig map[string]string `yaml:"runtimeConfig,omitempty"`
+
+	// AdmissionConfiguration is the path to an AdmissionConfiguration file on the
+	// host, passed to the API server as --admission-control-config-file. Its
+	// directory is mounted read-only into the API server, so files it references
+	// relative to it are available too.
+	AdmissionConfiguration string `yaml:"admissionConfiguration,omitempty"`
+
+	// AuthorizationConfiguration is the path to a structured AuthorizationConfiguration
+	// file on the host, passed to the API server as --authorization-config
+	// instead of --authorization-mode. Like AdmissionConfiguration its directory
+	// is mounted into the API server. This requires Kubernetes v1.30 or later.
+	AuthorizationConfiguration string `yaml:"authorizationConfiguration,omitempty"`
+
 	// KubeadmConfigPatches are applied to the generated kubeadm config as
 	// merge patches. The `kind` field must match the target object, and
 	// if `apiVersion` is specified it will only be applied to matching objects.
@@ -67,6 +96,11 @@ type Cluster struct {
 	// The cluster-level patches are appied before the node-level patches.
 	KubeadmConfigPatchesJSON6902 []PatchJSON6902 `yaml:"kubeadmConfigPatchesJSON6902,omitempty"`
 
+	// TargetedKubeadmConfigPatches are merge patches like KubeadmConfigPatches
+	// that only apply to the nodes and component selected by their Target.
+	// They are applied after KubeadmConfigPatches and before the node-level patches.
+	TargetedKubeadmConfigPatches []TargetedPatch `yaml:"targetedKubeadmConfigPatches,omitempty"`
+
 	// ContainerdConfigPatches are applied to every node's containerd config
 	// in the order listed.
 	// These should be toml stringsto be applied as merge patches
@@ -76,6 +110,102 @@ type Cluster struct {
 	// in the order listed.
 	// These should be YAML or JSON formatting RFC 6902 JSON patches
 	ContainerdConfigPatchesJSON6902 []string `yaml:"containerdConfigPatchesJSON6902,omitempty"`
+
+	// GPUDevicePlugin deploys the NVIDIA device plugin when any node requests
+	// GPUs, so that pods may request nvidia.com/gpu resources.
+	GPUDevicePlugin bool `yaml:"gpuDevicePlugin,omitempty"`
+
+	// Presets are optional bundles of components installed after the CNI,
+	// "observability" and "node-problem-detector" are supported.
+	Presets []Preset `yaml:"presets,omitempty"`
+
+	// ContainerLogs configures container log rotation on all nodes, so that
+	// long running clusters do not fill the host disk.
+	ContainerLogs ContainerLogs `yaml:"containerLogs,omitempty"`
+
+	// ImageGC configures kubelet image garbage collection on all nodes.
+	// kind disables it by default, as kubelet sees the usage of the host disk.
+	ImageGC ImageGC `yaml:"imageGC,omitempty"`
+
+	// KubeletServerTLSBootstrap has kubelets request serving certificates signed
+	// by the cluster CA instead of self-signing them, kind approves these during
+	// cluster creation. This allows e.g. metrics-server to verify kubelets.
+	KubeletServerTLSBootstrap bool `yaml:"kubeletServerTLSBootstrap,omitempty"`
+
+	// Storage configures the default storage provisioner and any additional
+	// StorageClasses installed while creating the cluster
+	Storage Storage `yaml:"storage,omitempty"`
+
+	// ControlPlaneMode selects which control plane components are run,
+	// APIServerOnlyMode runs only etcd and the API server on a single node,
+	// for testing controllers and webhooks against a real API server
+	// Defaults to FullControlPlaneMode
+	ControlPlaneMode ControlPlaneMode `yaml:"controlPlaneMode,omitempty"`
+
+	// ExternalControlPlane configures the nodes to join an existing control plane
+	// that is not managed by kind, e.g. another cluster, a VM or a cloud cluster.
+	// If set, all nodes must be workers.
+	ExternalControlPlane ExternalControlPlane `yaml:"externalControlPlane,omitempty"`
+
+	// Images overrides the infrastructure images kind uses, e.g. to redirect
+	// them to a mirrored registry in air-gapped environments
+	Images Images `yaml:"images,omitempty"`
+
+	// RestartPolicy is the restart policy of the node containers, it selects when
+	// the container runtime restarts them after they exit, see RestartPolicy
+	// Defaults to OnFailureRestartPolicy, restarting them once, such as on host reboot
+	RestartPolicy RestartPolicy `yaml:"restartPolicy,omitempty"`
+
+	// ContainerdSnapshotter selects the snapshotter containerd uses on all nodes,
+	// FuseOverlayFSSnapshotter works where overlayfs cannot be nested, e.g. rootless,
+	// StargzSnapshotter lazily pulls eStargz images
+	// Defaults to OverlayFSSnapshotter
+	ContainerdSnapshotter ContainerdSnapshotter `yaml:"containerdSnapshotter,omitempty"`
+
+	// ContainerdMetrics enables containerd's Prometheus metrics endpoint on all
+	// nodes, it is served on port 1338 of each node's IP at /v1/metrics
+	ContainerdMetrics bool `yaml:"containerdMetrics,omitempty"`
+
+	// LoadBalancer configures the external load balancer kind runs in front of
+	// the API servers of clusters with multiple control plane nodes
+	LoadBalancer LoadBalancer `yaml:"loadBalancer,omitempty"`
+
+	// Authentication configures how the API server authenticates requests
+	Authentication Authentication `yaml:"authentication,omitempty"`
+
+	// DiskQuota limits the total disk space used by the containerd storage, kubelet
+	// data (including emptyDir volumes) and logs of all of the nodes, e.g. "50Gi".
+	// These are placed on a filesystem of this size in a sparse image file on the
+	// container runtime host, so a runaway workload cannot fill the host disk.
+	// This requires a rootful container runtime on a Linux host or VM.
+	DiskQuota string `yaml:"diskQuota,omitempty"`
+
+	// Inotify raises the host fs.inotify limits while creating the cluster, if
+	// they are lower. These limits are not namespaced, so they are shared by
+	// every node and the host, and cannot be set separately for each node.
+	Inotify Inotify `yaml:"inotify,omitempty"`
+
+	// SecurityProfiles installs custom seccomp profiles and AppArmor policies
+	// on the nodes, for testing pod security settings that use them.
+	SecurityProfiles SecurityProfiles `yaml:"securityProfiles,omitempty"`
+
+	// Trust configures extra certificate authorities trusted by the nodes, for
+	// TLS-intercepting proxies and private registries with internal CAs.
+	Trust Trust `yaml:"trust,omitempty"`
+
+	// Registries configures how every node connects to the listed image
+	// registries, e.g. over plain HTTP or with a private CA, using containerd
+	// registry host files instead of containerdConfigPatches.
+	Registries []Registry `yaml:"registries,omitempty"`
+
+	// CredentialProviders configures kubelet image credential providers on
+	// every node, to test registry credential helpers such as the ECR, GCR or
+	// ACR credential providers.
+	CredentialProviders CredentialProviders `yaml:"credentialProviders,omitempty"`
+
+	// Etcd tunes the local etcd of the control-plane nodes and can place its
+	// data on a dedicated volume, for long-lived clusters and experiments.
+	Etcd Etcd `yaml:"etcd,omitempty"`
 }
 
 // TypeMeta partially copies apimachinery/pkg/apis/meta/v1.TypeMeta
@@ -95,6 +225,22 @@ type Node struct {
 	// Defaults to "control-plane"
 	Role NodeRole `yaml:"role,omitempty"`
 
+	// Name is the node container name, which is also its hostname and Kubernetes
+	// node name. If unset the name is generated, see Cluster.NodeNameTemplate.
+	Name string `yaml:"name,omitempty"`
+
+	// Hostname overrides the node hostname, which kubeadm also uses as the
+	// Kubernetes node name. Defaults to the node name.
+	Hostname string `yaml:"hostname,omitempty"`
+
+	// MachineID sets a stable /etc/machine-id for the node, 32 lowercase hex
+	// characters. If unset a random one is generated each time the node starts.
+	MachineID string `yaml:"machineID,omitempty"`
+
+	// Labels are extra Kubernetes labels registered for the node by its kubelet,
+	// e.g. ingress-ready: "true"
+	Labels map[string]string `yaml:"labels,omitempty"`
+
 	// Image is the node image to use when creating this node
 	// If unset a default image will be used, see defaults.Image
 	Image string `yaml:"image,omitempty"`
@@ -106,10 +252,55 @@ type Node struct {
 	// These may be used to bind a hostPath
 	ExtraMounts []Mount `yaml:"extraMounts,omitempty"`
 
+	// PersistentVolumes are local PersistentVolumes pre-provisioned on this node.
+	// Each path must be within one of ExtraMounts, so that the data persists
+	// across node container restarts.
+	PersistentVolumes []PersistentVolume `yaml:"persistentVolumes,omitempty"`
+
 	// ExtraPortMappings describes additional port mappings for the node container
 	// binded to a host Port
 	ExtraPortMappings []PortMapping `yaml:"extraPortMappings,omitempty"`
 
+	// GPUs requests GPU devices for the node container, e.g. "all", "2"
+	// or "device=0,1". This maps to `--gpus` for docker and to CDI device
+	// requests for podman, and requires the NVIDIA container toolkit on the host.
+	GPUs string `yaml:"gpus,omitempty"`
+
+	// CgroupNS sets the cgroup namespace mode of the node container, one of
+	// "private" or "host". If unset the container runtime default is used.
+	CgroupNS string `yaml:"cgroupns,omitempty"`
+
+	// CPUSet pins the node container to host CPUs, e.g. "0-3" or "0,2".
+	// This is useful to exercise the kubelet CPU Manager with a static policy.
+	CPUSet string `yaml:"cpuset,omitempty"`
+
+	// Hugepages requests hugepages for the node, keyed by page size ("2Mi" or
+	// "1Gi") to the number of pages. The pages must be preallocated on the
+	// host, kind checks that enough are free and mounts hugetlbfs into the node.
+	Hugepages map[string]int32 `yaml:"hugepages,omitempty"`
+
+	// Ulimits sets resource limits of the node container by name, e.g. "nofile",
+	// to "soft:hard" or a single value for both, -1 is unlimited.
+	Ulimits map[string]string `yaml:"ulimits,omitempty"`
+
+	// Swap adds a swapfile to the node and configures the kubelet swapBehavior,
+	// to exercise the Kubernetes swap support. Swap is not namespaced, so the
+	// swapfile is used by the whole host until the node is deleted.
+	Swap Swap `yaml:"swap,omitempty"`
+
+	// ClockOffset shifts the wall clock of the kubelet and containerd on the
+	// node by a duration such as "720h" or "-24h". This uses libfaketime from
+	// the node image and is intended for testing certificate rotation and
+	// token expiry. Pods and commands run on the node, e.g. kubeadm and
+	// kubectl, keep the real clock.
+	ClockOffset string `yaml:"clockOffset,omitempty"`
+
+	// StaticPodsHostPath is a host directory of static pod manifests for the node,
+	// in addition to those generated by kubeadm. The directory is mounted into the
+	// node and its *.yaml, *.yml and *.json files are linked into the kubelet
+	// static pod path, following files added or removed later.
+	StaticPodsHostPath string `yaml:"staticPodsHostPath,omitempty"`
+
 	// KubeadmConfigPatches are applied to the generated kubeadm config as
 	// merge patches. The `kind` field must match the target object, and
 	// if `apiVersion` is specified it will only be applied to matching objects.
@@ -165,10 +356,15 @@ type Networking struct {
 	// is restarted it will be randomized. Leave this unset to allow kind to pick it.
 	APIServerPort int32 `yaml:"apiServerPort,omitempty"`
 	// APIServerAddress is the listen address on the host for the Kubernetes
-	// API Server. This should be an IP address.
+	// API Server. This should be an IP address, or `iface:<name>` to listen on
+	// the address of that host network interface, resolved at creation time.
 	//
 	// Defaults to 127.0.0.1
 	APIServerAddress string `yaml:"apiServerAddress,omitempty"`
+	// AdditionalAPIServerAddresses are further listen addresses on the host for
+	// the Kubernetes API Server, on the same port as APIServerAddress.
+	// Like APIServerAddress each entry is an IP address or `iface:<name>`.
+	AdditionalAPIServerAddresses []string `yaml:"additionalAPIServerAddresses,omitempty"`
 	// PodSubnet is the CIDR used for pod IPs
 	// kind will select a default if unspecified
 	PodSubnet string `yaml:"podSubnet,omitempty"`
@@ -178,7 +374,8 @@ type Networking struct {
 	// If DisableDefaultCNI is true, kind will not install the default CNI setup.
 	// Instead the user should install their own CNI after creating the cluster.
 	DisableDefaultCNI bool `yaml:"disableDefaultCNI,omitempty"`
-	// KubeProxyMode defines if kube-proxy should operate in iptables or ipvs mode
+	// KubeProxyMode defines if kube-proxy should operate in iptables or ipvs mode,
+	// or "none" to not deploy kube-proxy at all which requires DisableDefaultCNI
 	// Defaults to 'iptables' mode
 	KubeProxyMode ProxyMode `yaml:"kubeProxyMode,omitempty"`
 }
@@ -201,6 +398,383 @@ const (
 	IPTablesMode ProxyMode = "iptables"
 	// IPVSMode sets ProxyMode to iptables
 	IPVSMode ProxyMode = "ipvs"
+	// NoneProxyMode disables kube-proxy, e.g. for CNIs that replace it
+	NoneProxyMode ProxyMode = "none"
+)
+
+// ComponentFeatureGates configures feature gates for individual Kubernetes
+// components, each entry takes precedence over the cluster wide FeatureGates
+type ComponentFeatureGates struct {
+	APIServer         map[string]bool `yaml:"apiServer,omitempty"`
+	ControllerManager map[string]bool `yaml:"controllerManager,omitempty"`
+	Scheduler         map[string]bool `yaml:"scheduler,omitempty"`
+	Kubelet           map[string]bool `yaml:"kubelet,omitempty"`
+	KubeProxy         map[string]bool `yaml:"kubeProxy,omitempty"`
+}
+
+// ContainerLogs configures container log rotation on the nodes
+type ContainerLogs struct {
+	// MaxSize is the maximum size of a container log file before it is
+	// rotated, as a quantity such as "10Mi". This is kubelet's
+	// containerLogMaxSize and defaults to "5Mi".
+	MaxSize string `yaml:"maxSize,omitempty"`
+	// MaxFiles is the maximum number of log files kept per container,
+	// including the active one. This is kubelet's containerLogMaxFiles and
+	// defaults to 3.
+	MaxFiles int32 `yaml:"maxFiles,omitempty"`
+	// MaxLineSize is containerd's max_container_log_line_size in bytes,
+	// longer lines are split. -1 means unlimited. If unset the containerd
+	// default is used.
+	MaxLineSize int32 `yaml:"maxLineSize,omitempty"`
+}
+
+// ImageGC configures kubelet image garbage collection on the nodes
+type ImageGC struct {
+	// HighThresholdPercent is the disk usage percentage of the node image
+	// filesystem, usually the host disk, above which kubelet removes unused
+	// images. This is kubelet's imageGCHighThresholdPercent, if unset it is
+	// 100 so that images are never removed.
+	HighThresholdPercent int32 `yaml:"highThresholdPercent,omitempty"`
+	// LowThresholdPercent is the disk usage percentage kubelet removes unused
+	// images down to. This is kubelet's imageGCLowThresholdPercent and
+	// defaults to kubelet's default of 80, it must be below HighThresholdPercent.
+	LowThresholdPercent int32 `yaml:"lowThresholdPercent,omitempty"`
+	// MinimumAge is how long an image must be unused before it may be
+	// removed, as a duration such as "10m". This is kubelet's
+	// imageMinimumGCAge, if unset the kubelet default is used.
+	MinimumAge string `yaml:"minimumAge,omitempty"`
+}
+
+// Storage configures the storage provisioner installed by kind
+type Storage struct {
+	// Provisioner is "local-path" (the default) to install the node image's
+	// local path provisioner, "none" to install no provisioner, or the
+	// http(s) URL of a manifest to apply instead, e.g. a CSI driver
+	Provisioner StorageProvisioner `yaml:"provisioner,omitempty"`
+
+	// ExtraStorageClasses are created after the provisioner is installed
+	ExtraStorageClasses []StorageClass `yaml:"extraStorageClasses,omitempty"`
+}
+
+// Images are overrides for the images kind uses besides the node image,
+// an empty value uses the default
+type Images struct {
+	// Repository is the registry the control plane component images are
+	// pulled from, this is the kubeadm imageRepository and also applies to
+	// CoreDNS and etcd unless these are set
+	Repository string `yaml:"repository,omitempty"`
+
+	// Pause is the pod sandbox image containerd uses
+	Pause string `yaml:"pause,omitempty"`
+
+	// CoreDNS is the CoreDNS image, e.g. "registry.example/coredns:1.6.7"
+	// kubeadm only allows overriding the repository and tag, the image
+	// name must be coredns
+	CoreDNS string `yaml:"coreDNS,omitempty"`
+
+	// Etcd is the etcd image, e.g. "registry.example/etcd:3.4.3-0"
+	// As with CoreDNS, the image name must be etcd
+	Etcd string `yaml:"etcd,omitempty"`
+
+	// LoadBalancer is the load balancer image used with multiple
+	// control-plane nodes
+	LoadBalancer string `yaml:"loadBalancer,omitempty"`
+
+	// Overrides maps the other images kind uses, as listed by
+	// `kind get required-images`, to the image to use instead, e.g. for
+	// kindnetd, the local-path provisioner and its helper, the presets and
+	// the OIDC test issuer
+	Overrides map[string]string `yaml:"overrides,omitempty"`
+}
+
+// LoadBalancer configures the external load balancer
+type LoadBalancer struct {
+	// Implementation selects the load balancer, see LoadBalancerImplementation
+	// Images.LoadBalancer overrides the image for the implementation
+	// Defaults to HAProxyLoadBalancer
+	Implementation LoadBalancerImplementation `yaml:"implementation,omitempty"`
+	// HealthCheck configures checking the control plane nodes
+	HealthCheck LoadBalancerHealthCheck `yaml:"healthCheck,omitempty"`
+	// ExtraFrontends are additional TCP ports the load balancer forwards to
+	// the control plane nodes, e.g. for services running on all of them
+	ExtraFrontends []LoadBalancerFrontend `yaml:"extraFrontends,omitempty"`
+}
+
+// LoadBalancerImplementation is the software used for the load balancer
+type LoadBalancerImplementation string
+
+const (
+	// HAProxyLoadBalancer actively health checks the API servers
+	HAProxyLoadBalancer LoadBalancerImplementation = "haproxy"
+	// NginxLoadBalancer only passively checks the backends, taking a
+	// backend out for the Interval after Fall failed connections
+	NginxLoadBalancer LoadBalancerImplementation = "nginx"
+)
+
+// LoadBalancerHealthCheck configures checking the load balancer backends
+type LoadBalancerHealthCheck struct {
+	// Interval is the time between checks, e.g. "2s", the default
+	Interval string `yaml:"interval,omitempty"`
+	// Rise is the number of passed checks before a backend is used again
+	// Defaults to 2, only used by HAProxyLoadBalancer
+	Rise int32 `yaml:"rise,omitempty"`
+	// Fall is the number of failed checks before a backend is taken out
+	// Defaults to 3
+	Fall int32 `yaml:"fall,omitempty"`
+}
+
+// LoadBalancerFrontend is an additional TCP port forwarded by the load
+// balancer to all control plane nodes
+type LoadBalancerFrontend struct {
+	// Name identifies the frontend, e.g. "konnectivity"
+	Name string `yaml:"name,omitempty"`
+	// Port is the port the load balancer listens on in the cluster network
+	Port int32 `yaml:"port,omitempty"`
+	// BackendPort is the port on the control plane nodes, defaults to Port
+	BackendPort int32 `yaml:"backendPort,omitempty"`
+	// HostPort optionally publishes Port on the host at
+	// Networking.APIServerAddress, zero does not publish it
+	HostPort int32 `yaml:"hostPort,omitempty"`
+}
+
+// Authentication configures how the API server authenticates requests
+type Authentication struct {
+	// OIDC configures authenticating OpenID Connect ID tokens
+	OIDC OIDC `yaml:"oidc,omitempty"`
+}
+
+// OIDC configures the API server --oidc-* flags, it is enabled when
+// IssuerURL is set or TestIssuer is true
+type OIDC struct {
+	// IssuerURL is the https URL of the OpenID Connect provider, it must be
+	// reachable from the control plane nodes
+	IssuerURL string `yaml:"issuerURL,omitempty"`
+	// ClientID is the audience ID tokens must be issued for
+	ClientID string `yaml:"clientID,omitempty"`
+	// CA is the PEM encoded CA certificate for the provider, if unset the
+	// node's system roots are used
+	CA string `yaml:"ca,omitempty"`
+	// UsernameClaim is the claim used as the user name, the API server
+	// defaults to "sub"
+	UsernameClaim string `yaml:"usernameClaim,omitempty"`
+	// UsernamePrefix is prepended to user names
+	UsernamePrefix string `yaml:"usernamePrefix,omitempty"`
+	// GroupsClaim is the claim used as the user's groups
+	GroupsClaim string `yaml:"groupsClaim,omitempty"`
+	// GroupsPrefix is prepended to group names
+	GroupsPrefix string `yaml:"groupsPrefix,omitempty"`
+	// RequiredClaims must be present in ID tokens with these values
+	RequiredClaims map[string]string `yaml:"requiredClaims,omitempty"`
+
+	// TestIssuer runs a dex OpenID Connect provider for testing in a container
+	// on the cluster network, IssuerURL and CA must not be set as they are
+	// generated. It has a static user admin@example.com with the password
+	// "password", and the client "kind" with the secret "kind-secret".
+	TestIssuer bool `yaml:"testIssuer,omitempty"`
+}
+
+// Inotify configures the host fs.inotify limits, zero values are unchanged
+type Inotify struct {
+	// MaxUserWatches is the minimum fs.inotify.max_user_watches
+	MaxUserWatches int32 `yaml:"maxUserWatches,omitempty"`
+	// MaxUserInstances is the minimum fs.inotify.max_user_instances
+	MaxUserInstances int32 `yaml:"maxUserInstances,omitempty"`
+}
+
+// Etcd configures the local etcd of the control-plane nodes
+type Etcd struct {
+	// QuotaBackendBytes is the backend size quota, a quantity such as "8Gi",
+	// etcd defaults to 2Gi
+	QuotaBackendBytes string `yaml:"quotaBackendBytes,omitempty"`
+	// HeartbeatInterval is the raft heartbeat interval, a duration such as
+	// "250ms", etcd defaults to 100ms
+	HeartbeatInterval string `yaml:"heartbeatInterval,omitempty"`
+	// ElectionTimeout is the raft election timeout, a duration such as
+	// "2500ms", etcd defaults to 1s. It must be at least five times the
+	// heartbeat interval.
+	ElectionTimeout string `yaml:"electionTimeout,omitempty"`
+	// DataVolume places the etcd data dir on a named volume of the node
+	// runtime, or on a host directory if it is an absolute path. Each
+	// control-plane node gets its own volume or subdirectory, named after the
+	// node, e.g. "etcd-kind-control-plane" or "/data/etcd/kind-control-plane".
+	// These are kept when the cluster is deleted and must be removed before
+	// reusing them for a new cluster.
+	DataVolume string `yaml:"dataVolume,omitempty"`
+}
+
+// CredentialProviders configures the kubelet image credential providers
+type CredentialProviders struct {
+	// BinDir is a host directory with the credential provider binaries, it is
+	// mounted read-only on every node
+	BinDir string `yaml:"binDir,omitempty"`
+	// Providers are the credential providers the kubelet runs, in order
+	Providers []CredentialProvider `yaml:"providers,omitempty"`
+}
+
+// CredentialProvider is a kubelet image credential provider, see the kubelet
+// CredentialProviderConfig documentation for the meaning of each field
+type CredentialProvider struct {
+	// Name is the file name of the provider binary in BinDir
+	Name string `yaml:"name,omitempty"`
+	// MatchImages are the image patterns the provider is used for, e.g.
+	// "*.dkr.ecr.*.amazonaws.com"
+	MatchImages []string `yaml:"matchImages,omitempty"`
+	// DefaultCacheDuration is how long the kubelet caches the credentials if
+	// the provider does not say, e.g. "12h"
+	DefaultCacheDuration string `yaml:"defaultCacheDuration,omitempty"`
+	// Args are passed to the provider binary
+	Args []string `yaml:"args,omitempty"`
+	// Env are environment variables set for the provider binary
+	Env map[string]string `yaml:"env,omitempty"`
+}
+
+// Trust configures the certificate authorities the nodes trust
+type Trust struct {
+	// ExtraCAs are host paths of PEM encoded CA certificates, or inline PEM,
+	// installed in every node's system trust store. containerd verifies
+	// registries against the system trust store, so this covers image pulls.
+	ExtraCAs []string `yaml:"extraCAs,omitempty"`
+}
+
+// Registry configures the connection to an image registry
+type Registry struct {
+	// Host is the registry host and optional port as used in image names,
+	// e.g. "registry.local:5000"
+	Host string `yaml:"host,omitempty"`
+	// PlainHTTP connects to the registry over HTTP instead of HTTPS
+	PlainHTTP bool `yaml:"plainHTTP,omitempty"`
+	// InsecureSkipVerify connects over HTTPS without verifying the registry
+	// certificate
+	InsecureSkipVerify bool `yaml:"insecureSkipVerify,omitempty"`
+	// CA is a host path of a PEM encoded CA certificate, or inline PEM, used
+	// to verify the registry certificate instead of the system trust store
+	CA string `yaml:"ca,omitempty"`
+}
+
+// SecurityProfiles are the seccomp profiles and AppArmor policies for the nodes
+type SecurityProfiles struct {
+	// SeccompDir is a host directory of seccomp profiles, mounted read-only on
+	// every node at /var/lib/kubelet/seccomp/profiles. Pods use them with e.g.
+	// localhostProfile: profiles/audit.json
+	SeccompDir string `yaml:"seccompDir,omitempty"`
+	// AppArmor are host paths of AppArmor profiles, these are loaded into the
+	// host kernel from a node if the host has AppArmor enabled
+	AppArmor []string `yaml:"appArmor,omitempty"`
+}
+
+// Swap configures a swapfile for a node, the zero value has no swap
+type Swap struct {
+	// Size of the swapfile, a quantity such as "1Gi"
+	Size string `yaml:"size,omitempty"`
+	// Behavior is the kubelet memorySwap.swapBehavior, LimitedSwap by default
+	Behavior SwapBehavior `yaml:"behavior,omitempty"`
+}
+
+// SwapBehavior is the kubelet memorySwap.swapBehavior
+type SwapBehavior string
+
+const (
+	// LimitedSwapBehavior limits the swap Burstable pods may use by their
+	// memory requests, other pods cannot use swap
+	LimitedSwapBehavior SwapBehavior = "LimitedSwap"
+	// NoSwapBehavior keeps all pods from using swap, only system daemons use it
+	NoSwapBehavior SwapBehavior = "NoSwap"
+)
+
+// ExternalControlPlane is a control plane not managed by kind for the nodes
+// to join, with the values from e.g. `kubeadm token create --print-join-command`
+type ExternalControlPlane struct {
+	// Endpoint is the host:port of the API server, it must be reachable
+	// from the node containers
+	Endpoint string `yaml:"endpoint,omitempty"`
+
+	// Token is a bootstrap token for joining the control plane
+	Token string `yaml:"token,omitempty"`
+
+	// CACertHashes are the "sha256:<hex>" hashes of the cluster CA public key
+	// used to verify the control plane, if empty the CA is not verified
+	CACertHashes []string `yaml:"caCertHashes,omitempty"`
+}
+
+// ControlPlaneMode selects the control plane components, see ControlPlaneMode
+type ControlPlaneMode string
+
+const (
+	// FullControlPlaneMode runs a complete cluster, this is the default
+	FullControlPlaneMode ControlPlaneMode = "Full"
+	// APIServerOnlyMode runs only etcd and kube-apiserver on a single
+	// control-plane node, with kubeadm generated certificates.
+	// There is no scheduler, controller-manager, CNI or registered Node,
+	// the kubelet only runs the static pods for etcd and the API server.
+	APIServerOnlyMode ControlPlaneMode = "APIServerOnly"
+)
+
+// RestartPolicy selects when node containers are restarted, see RestartPolicy
+type RestartPolicy string
+
+const (
+	// OnFailureRestartPolicy restarts node containers once after they fail,
+	// including when the host or container runtime restarts, this is the default
+	OnFailureRestartPolicy RestartPolicy = "on-failure"
+	// NoRestartPolicy never restarts node containers
+	NoRestartPolicy RestartPolicy = "no"
+	// UnlessStoppedRestartPolicy always restarts node containers unless
+	// they were stopped explicitly
+	UnlessStoppedRestartPolicy RestartPolicy = "unless-stopped"
+	// AlwaysRestartPolicy always restarts node containers
+	AlwaysRestartPolicy RestartPolicy = "always"
+)
+
+// ContainerdSnapshotter selects the containerd snapshotter, see ContainerdSnapshotter
+type ContainerdSnapshotter string
+
+const (
+	// OverlayFSSnapshotter is the kernel overlayfs snapshotter, this is the default
+	OverlayFSSnapshotter ContainerdSnapshotter = "overlayfs"
+	// FuseOverlayFSSnapshotter is the userspace fuse-overlayfs snapshotter,
+	// it requires /dev/fuse on the host
+	FuseOverlayFSSnapshotter ContainerdSnapshotter = "fuse-overlayfs"
+	// StargzSnapshotter is the stargz snapshotter, it lazily pulls eStargz
+	// images and requires /dev/fuse on the host
+	StargzSnapshotter ContainerdSnapshotter = "stargz"
+)
+
+// StorageProvisioner selects the storage provisioner, see Storage
+type StorageProvisioner string
+
+const (
+	// LocalPathProvisioner installs the node image's default storage manifest
+	LocalPathProvisioner StorageProvisioner = "local-path"
+	// NoneProvisioner installs no storage provisioner or StorageClass
+	NoneProvisioner StorageProvisioner = "none"
+)
+
+// StorageClass is a minimal storage.k8s.io/v1 StorageClass
+type StorageClass struct {
+	// Name is the StorageClass name
+	Name string `yaml:"name,omitempty"`
+	// Provisioner is the name of the volume provisioner, e.g. a CSI driver
+	Provisioner string `yaml:"provisioner,omitempty"`
+	// Default marks this as the default StorageClass, replacing the
+	// local-path "standard" class as the default
+	Default bool `yaml:"default,omitempty"`
+	// Parameters are passed to the provisioner
+	Parameters map[string]string `yaml:"parameters,omitempty"`
+	// ReclaimPolicy is Delete (the default) or Retain
+	ReclaimPolicy string `yaml:"reclaimPolicy,omitempty"`
+	// VolumeBindingMode is Immediate (the default) or WaitForFirstConsumer
+	VolumeBindingMode string `yaml:"volumeBindingMode,omitempty"`
+}
+
+// Preset is an optional bundle of cluster components
+type Preset string
+
+const (
+	// ObservabilityPreset installs metrics-server and kube-state-metrics
+	ObservabilityPreset Preset = "observability"
+	// NodeProblemDetectorPreset installs node-problem-detector, reporting
+	// kernel problems such as OOM kills, and runtime restarts, on the nodes
+	NodeProblemDetectorPreset Preset = "node-problem-detector"
 )
 
 // PatchJSON6902 represents an inline kustomize json 6902 patch
@@ -210,10 +784,53 @@ type PatchJSON6902 struct {
 	Group   string `yaml:"group"`
 	Version string `yaml:"version"`
 	Kind    string `yaml:"kind"`
+
+	// Target optionally selects the nodes and component the patch applies to,
+	// with a component the group, version and kind are implied and the
+	// patch paths are relative to the component
+	Target PatchTarget `yaml:"target,omitempty"`
 	// Patch should contain the contents of the json patch as a string
 	Patch string `yaml:"patch"`
 }
 
+// TargetedPatch is a kubeadm config merge patch for selected nodes and components
+type TargetedPatch struct {
+	// Target selects the nodes and component the patch applies to
+	Target PatchTarget `yaml:"target,omitempty"`
+	// Patch is the merge patch as an inline yaml blob-string, with a
+	// Target.Component it is relative to the component and `kind` is implied
+	Patch string `yaml:"patch"`
+}
+
+// PatchTarget selects the nodes and component a kubeadm config patch
+// applies to, unset fields match all nodes or the whole config
+type PatchTarget struct {
+	// Role selects the nodes with this role
+	Role NodeRole `yaml:"role,omitempty"`
+	// NodeName selects the node with this name, e.g. "kind-worker2"
+	NodeName string `yaml:"nodeName,omitempty"`
+	// Component selects the config of a single component, see PatchComponent
+	Component PatchComponent `yaml:"component,omitempty"`
+}
+
+// PatchComponent is a Kubernetes component configured by the kubeadm config
+type PatchComponent string
+
+const (
+	// APIServerComponent is ClusterConfiguration.apiServer
+	APIServerComponent PatchComponent = "kube-apiserver"
+	// ControllerManagerComponent is ClusterConfiguration.controllerManager
+	ControllerManagerComponent PatchComponent = "kube-controller-manager"
+	// SchedulerComponent is ClusterConfiguration.scheduler
+	SchedulerComponent PatchComponent = "kube-scheduler"
+	// EtcdComponent is ClusterConfiguration.etcd.local
+	EtcdComponent PatchComponent = "etcd"
+	// KubeletComponent is the KubeletConfiguration
+	KubeletComponent PatchComponent = "kubelet"
+	// KubeProxyComponent is the KubeProxyConfiguration
+	KubeProxyComponent PatchComponent = "kube-proxy"
+)
+
 /*
 These types are from
 https://github.com/kubernetes/kubernetes/blob/063e7ff358fdc8b0916e6f39beedc0d025734cb1/pkg/kubelet/apis/cri/runtime/v1alpha2/api.pb.go#L183
@@ -229,9 +846,10 @@ https://github.com/kubernetes/kubernetes/blob/063e7ff358fdc8b0916e6f39beedc0d025
 //  containerPath: /foo
 //  hostPath: /bar
 //  readOnly: true
-//  selinuxRelabel: false
+//  selinuxRelabel: shared
 //  propagation: None
 // Propagation may be one of: None, HostToContainer, Bidirectional
+// selinuxRelabel may be one of: shared, private, none, or a boolean
 type Mount struct {
 	// Path of the mount within the container.
 	ContainerPath string `yaml:"containerPath,omitempty"`
@@ -242,11 +860,41 @@ type Mount struct {
 	// If set, the mount is read-only.
 	Readonly bool `yaml:"readOnly,omitempty"`
 	// If set, the mount needs SELinux relabeling.
-	SelinuxRelabel bool `yaml:"selinuxRelabel,omitempty"`
+	//
+	// In yaml this is selinuxRelabel: true, see also SelinuxRelabelMode.
+	SelinuxRelabel bool `yaml:"-"`
+	// SelinuxRelabelMode sets the SELinux relabeling of the mount, "shared"
+	// (:z) allows all containers to use the content while "private" (:Z)
+	// restricts it to this node. It takes precedence over SelinuxRelabel.
+	//
+	// In yaml this is selinuxRelabel: shared|private|none, false is "none".
+	SelinuxRelabelMode MountSelinuxRelabel `yaml:"-"`
 	// Requested propagation mode.
 	Propagation MountPropagation `yaml:"propagation,omitempty"`
 }
 
+// PersistentVolume is a local PersistentVolume with node affinity for the
+// node it is declared on
+// In yaml this looks like:
+//  name: data-0
+//  path: /data/pv0
+//  capacity: 10Gi
+//  storageClassName: standard
+//  reclaimPolicy: Retain
+type PersistentVolume struct {
+	// Name is the PersistentVolume name, unique within the cluster
+	Name string `yaml:"name,omitempty"`
+	// Path is the directory backing the volume in the node container, it
+	// must be within the containerPath of one of the node's extraMounts
+	Path string `yaml:"path,omitempty"`
+	// Capacity is the declared size of the volume, e.g. "10Gi"
+	Capacity string `yaml:"capacity,omitempty"`
+	// StorageClassName defaults to "standard", the default StorageClass
+	StorageClassName string `yaml:"storageClassName,omitempty"`
+	// ReclaimPolicy must be Retain (the default), the host data is never deleted
+	ReclaimPolicy string `yaml:"reclaimPolicy,omitempty"`
+}
+
 // PortMapping specifies a host port mapped into a container port.
 // In yaml this looks like:
 //  containerPort: 80
@@ -288,6 +936,21 @@ const (
 	MountPropagationBidirectional MountPropagation = "Bidirectional"
 )
 
+// MountSelinuxRelabel represents an "enum" for SELinux relabeling options,
+// see also Mount.
+type MountSelinuxRelabel string
+
+const (
+	// MountSelinuxRelabelShared relabels the content with a shared label
+	// so that all containers may read and write it (":z").
+	MountSelinuxRelabelShared MountSelinuxRelabel = "shared"
+	// MountSelinuxRelabelPrivate relabels the content with a private
+	// unshared label for this node only (":Z").
+	MountSelinuxRelabelPrivate MountSelinuxRelabel = "private"
+	// MountSelinuxRelabelNone specifies the content should not be relabeled.
+	MountSelinuxRelabelNone MountSelinuxRelabel = "none"
+)
+
 // PortMappingProtocol represents an "enum" for port mapping protocol options,
 // see also PortMapping.
 type PortMappingProtocol string
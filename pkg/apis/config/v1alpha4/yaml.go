@@ -30,8 +30,12 @@ Custom YAML (de)serialization for these types
 // https://godoc.org/gopkg.in/yaml.v3
 func (m *Mount) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	// first unmarshal in the alias type (to avoid a recursion loop on unmarshal)
+	// selinuxRelabel is decoded separately as it may be a bool or a mode
 	type MountAlias Mount
-	var a MountAlias
+	var a struct {
+		MountAlias     `yaml:",inline"`
+		SelinuxRelabel interface{} `yaml:"selinuxRelabel,omitempty"`
+	}
 	if err := unmarshal(&a); err != nil {
 		return err
 	}
@@ -44,11 +48,49 @@ func (m *Mount) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	default:
 		return errors.Errorf("Unknown MountPropagation: %q", a.Propagation)
 	}
+	// and selinuxRelabel
+	switch v := a.SelinuxRelabel.(type) {
+	case nil: // unset
+	case bool:
+		if v {
+			a.MountAlias.SelinuxRelabel = true
+		} else {
+			a.SelinuxRelabelMode = MountSelinuxRelabelNone
+		}
+	case string:
+		a.SelinuxRelabelMode = MountSelinuxRelabel(strings.ToLower(v))
+		switch a.SelinuxRelabelMode {
+		case MountSelinuxRelabelShared:
+		case MountSelinuxRelabelPrivate:
+		case MountSelinuxRelabelNone:
+		default:
+			return errors.Errorf("Unknown MountSelinuxRelabel: %q", v)
+		}
+	default:
+		return errors.Errorf("Unknown MountSelinuxRelabel: %v", v)
+	}
 	// and copy over the fields
-	*m = Mount(a)
+	*m = Mount(a.MountAlias)
 	return nil
 }
 
+// MarshalYAML implements custom encoding YAML
+// https://godoc.org/gopkg.in/yaml.v3
+func (m Mount) MarshalYAML() (interface{}, error) {
+	// selinuxRelabel is encoded separately as it may be a bool or a mode
+	type MountAlias Mount
+	a := struct {
+		MountAlias     `yaml:",inline"`
+		SelinuxRelabel interface{} `yaml:"selinuxRelabel,omitempty"`
+	}{MountAlias: MountAlias(m)}
+	if m.SelinuxRelabelMode != "" {
+		a.SelinuxRelabel = string(m.SelinuxRelabelMode)
+	} else if m.SelinuxRelabel {
+		a.SelinuxRelabel = true
+	}
+	return a, nil
+}
+
 // UnmarshalYAML implements custom decoding YAML
 // https://godoc.org/gopkg.in/yaml.v3
 func (p *PortMapping) UnmarshalYAML(unmarshal func(interface{}) error) error {
@@ -88,6 +88,8 @@ type buildContext struct {
 	image     string
 	baseImage string
 	logger    log.Logger
+	// presetImages is true if optional preset images should be baked in
+	presetImages bool
 	// non-option fields
 	arch     string // TODO(bentheelder): this should be an option
 	kubeRoot string
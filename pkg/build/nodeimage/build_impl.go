@@ -33,6 +33,7 @@ import (
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/exec"
 	"sigs.k8s.io/kind/pkg/fs"
+	"sigs.k8s.io/kind/pkg/internal/presets"
 )
 
 // Build builds the cluster node image, the sourcedir must be set on
@@ -251,6 +252,12 @@ func (c *buildContext) prePullImages(bits kube.Bits, dir, containerID string) ([
 	// all builds should install the default storage driver images currently
 	requiredImages = append(requiredImages, defaultStorageImages...)
 
+	// optionally install the images for presets
+	if c.presetImages {
+		requiredImages = append(requiredImages, presets.ObservabilityImages...)
+		requiredImages = append(requiredImages, presets.NodeProblemDetectorImages...)
+	}
+
 	// Create "images" subdir.
 	imagesDir := path.Join(dir, "bits", "images")
 	if err := os.MkdirAll(imagesDir, 0777); err != nil {
@@ -63,6 +63,15 @@ func WithKuberoot(root string) Option {
 	})
 }
 
+// WithPresetImages configures a build to include the images used by optional
+// cluster presets, such as observability, so they need not be pulled later
+func WithPresetImages(include bool) Option {
+	return optionAdapter(func(b *buildContext) error {
+		b.presetImages = include
+		return nil
+	})
+}
+
 // WithLogger sets the logger
 func WithLogger(logger log.Logger) Option {
 	return optionAdapter(func(b *buildContext) error {
@@ -46,4 +46,26 @@ const (
 	// Please note that `kind` nodes hosting external etcd are not
 	// kubernetes nodes
 	ExternalEtcdNodeRoleValue string = "external-etcd"
+
+	// OIDCIssuerNodeRoleValue identifies a node that hosts the test OpenID
+	// Connect provider, see the authentication.oidc.testIssuer config field.
+	//
+	// Please note that `kind` nodes hosting the OIDC issuer are not
+	// kubernetes nodes
+	OIDCIssuerNodeRoleValue string = "oidc-issuer"
+
+	// ExposeProxyNodeRoleValue identifies a node that republishes the API
+	// server and selected NodePorts on other host addresses, see `kind expose`.
+	//
+	// Please note that `kind` nodes hosting the expose proxy are not
+	// kubernetes nodes
+	ExposeProxyNodeRoleValue string = "expose-proxy"
+
+	// DiskNodeRoleValue identifies a node that mounts the size limited
+	// filesystem holding the storage of the other nodes, see the diskQuota
+	// config field.
+	//
+	// Please note that `kind` nodes hosting the disk are not
+	// kubernetes nodes
+	DiskNodeRoleValue string = "disk"
 )
@@ -17,11 +17,20 @@ limitations under the License.
 package cluster
 
 import (
+	"io/ioutil"
 	"time"
 
+	yaml "gopkg.in/yaml.v3"
+
 	"sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
 	internalcreate "sigs.k8s.io/kind/pkg/cluster/internal/create"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/waitforready"
+	"sigs.k8s.io/kind/pkg/cluster/internal/patch"
+	"sigs.k8s.io/kind/pkg/cluster/waiter"
+	"sigs.k8s.io/kind/pkg/errors"
+	internalconfig "sigs.k8s.io/kind/pkg/internal/apis/config"
 	internalencoding "sigs.k8s.io/kind/pkg/internal/apis/config/encoding"
+	"sigs.k8s.io/kind/pkg/internal/presets"
 )
 
 // CreateOption is a Provider.Create option
@@ -40,7 +49,16 @@ func CreateWithConfigFile(path string) CreateOption {
 	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
 		var err error
 		o.Config, err = internalencoding.Load(path)
-		return err
+		if err != nil || path == "" || path == "-" {
+			return err
+		}
+		// record the source config for the cluster state
+		raw, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		o.RawConfig = string(raw)
+		return nil
 	})
 }
 
@@ -49,6 +67,7 @@ func CreateWithRawConfig(raw []byte) CreateOption {
 	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
 		var err error
 		o.Config, err = internalencoding.Parse(raw)
+		o.RawConfig = string(raw)
 		return err
 	})
 }
@@ -56,6 +75,11 @@ func CreateWithRawConfig(raw []byte) CreateOption {
 // CreateWithV1Alpha4Config configures the cluster with a v1alpha4 config
 func CreateWithV1Alpha4Config(config *v1alpha4.Cluster) CreateOption {
 	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		raw, err := yaml.Marshal(config)
+		if err != nil {
+			return err
+		}
+		o.RawConfig = string(raw)
 		o.Config = internalencoding.V1Alpha4ToInternal(config)
 		return nil
 	})
@@ -89,6 +113,35 @@ func CreateWithWaitForReady(waitTime time.Duration) CreateOption {
 	})
 }
 
+// CreateWithWaitFor configures what to wait for once the cluster is set up,
+// instead of CreateWithWaitForReady. targets is a comma separated list of
+// NAME[=CONDITION][:TIMEOUT], e.g. "nodes=Ready:2m,coredns,default-sa".
+// The targets are control-plane, nodes (for a node condition, Ready by
+// default), coredns, default-sa and metrics. Each timeout defaults to 5m and
+// counts from when waiting starts, a target that times out is a warning.
+func CreateWithWaitFor(targets string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		parsed, err := waitforready.ParseTargets(targets)
+		if err != nil {
+			return err
+		}
+		o.WaitFor = parsed
+		return nil
+	})
+}
+
+// CreateWithWaiters configures what to wait for once the cluster is set up,
+// instead of CreateWithWaitForReady and after any CreateWithWaitFor targets,
+// e.g. CreateWithWaiters(waiter.Nodes(), waiter.Deployment("kube-system", "coredns")).
+// The waiters are waited for in order, see the waiter package.
+// May be given more than once, the waiters are appended.
+func CreateWithWaiters(waiters ...waiter.Waiter) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.Waiters = append(o.Waiters, waiters...)
+		return nil
+	})
+}
+
 // CreateWithKubeconfigPath sets the explicit --kubeconfig path
 func CreateWithKubeconfigPath(explicitPath string) CreateOption {
 	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
@@ -124,3 +177,137 @@ func CreateWithDisplaySalutation(displaySalutation bool) CreateOption {
 		return nil
 	})
 }
+
+// KubeadmConfigMutator may modify one document of the generated kubeadm
+// config in place, kind is the document's kind e.g. "InitConfiguration"
+type KubeadmConfigMutator func(kind string, doc map[string]interface{}) error
+
+// CreateWithKubeadmConfigMutators adds mutators that are called on each
+// document of the kubeadm config generated for every node, after any
+// kubeadmConfigPatches from the cluster config have been applied
+func CreateWithKubeadmConfigMutators(mutators ...KubeadmConfigMutator) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		for _, m := range mutators {
+			o.KubeadmConfigMutators = append(o.KubeadmConfigMutators, patch.Mutator(m))
+		}
+		return nil
+	})
+}
+
+// CreateWithPresets enables optional presets in addition to any in the
+// cluster config, e.g. v1alpha4.ObservabilityPreset
+func CreateWithPresets(presets ...v1alpha4.Preset) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		for _, p := range presets {
+			o.Presets = append(o.Presets, internalconfig.Preset(p))
+		}
+		return nil
+	})
+}
+
+// CreateWithIngress installs an ingress controller, one of "nginx", "contour"
+// or "none". The first control-plane node is labeled ingress-ready=true and
+// forwards ports 80 and 443 from the host, for the controller to run on
+func CreateWithIngress(controller string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		switch controller {
+		case "", presets.IngressNone, presets.IngressNginx, presets.IngressContour:
+		default:
+			return errors.Errorf("unknown ingress controller %q, expected one of: nginx, contour, none", controller)
+		}
+		o.Ingress = controller
+		return nil
+	})
+}
+
+// CreateWithOffline forbids fetching anything remote during creation,
+// images must already be present locally or in CreateWithImageArchives
+// and creation fails with errors.ErrImagesMissing listing any that are not
+func CreateWithOffline(offline bool) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.Offline = offline
+		return nil
+	})
+}
+
+// CreateWithImageArchives loads the image archives at paths into the nodes
+// before Kubernetes is set up, e.g. to provide images for CreateWithOffline
+func CreateWithImageArchives(paths ...string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.ImageArchives = append(o.ImageArchives, paths...)
+		return nil
+	})
+}
+
+// CreateWithResume continues a previously failed creation of the cluster
+// from its last completed phase instead of failing because the nodes exist.
+// The nodes are kept if creation fails, so that it can be resumed again.
+func CreateWithResume(resume bool) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.Resume = resume
+		return nil
+	})
+}
+
+// CreateWithRetry configures retrying transient failures pulling images and
+// creating the network and node containers. Each operation is attempted up
+// to attempts times, waiting backoff before the first retry and doubling it
+// for each retry after. attempts of zero keeps the default policy.
+func CreateWithRetry(attempts int, backoff time.Duration) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		if attempts > 0 {
+			o.Retry.Attempts = attempts
+			o.Retry.Backoff = backoff
+		}
+		return nil
+	})
+}
+
+// CreateWithKubeadmVerbosity sets the kubeadm --v log level used for
+// kubeadm init and join, the default is 6. The output of kubeadm is
+// streamed to the logger at V(3)
+func CreateWithKubeadmVerbosity(verbosity int) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		if verbosity < 0 {
+			return errors.Errorf("invalid kubeadm verbosity %d, must not be negative", verbosity)
+		}
+		o.KubeadmVerbosity = &verbosity
+		return nil
+	})
+}
+
+// CreateWithWarningsAsErrors fails cluster creation on any warning about the
+// config or the host, such as deprecated feature gates or a storage driver
+// that is known to be slow, instead of only printing them
+func CreateWithWarningsAsErrors(warningsAsErrors bool) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.WarningsAsErrors = warningsAsErrors
+		return nil
+	})
+}
+
+// CreateWithTTL records that the cluster expires ttl after it is created,
+// expired clusters may be deleted by `kind gc`, see Provider.ClusterInfo
+func CreateWithTTL(ttl time.Duration) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		if ttl < 0 {
+			return errors.New("the cluster TTL must not be negative")
+		}
+		o.TTL = ttl
+		return nil
+	})
+}
+
+// CreateWithLabels records labels in the cluster's local state,
+// see Provider.ClusterInfo
+func CreateWithLabels(labels map[string]string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		if o.Labels == nil {
+			o.Labels = map[string]string{}
+		}
+		for k, v := range labels {
+			o.Labels[k] = v
+		}
+		return nil
+	})
+}
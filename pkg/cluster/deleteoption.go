@@ -0,0 +1,54 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+// DeleteOption is a Provider.Delete option
+type DeleteOption interface {
+	apply(*deleteOptions) error
+}
+
+type deleteOptions struct {
+	force    bool
+	graceful bool
+}
+
+type deleteOptionAdapter func(*deleteOptions) error
+
+func (c deleteOptionAdapter) apply(o *deleteOptions) error {
+	return c(o)
+}
+
+// DeleteWithForce deletes the cluster as quickly as possible, skipping the
+// shutdown of DeleteWithGracefulShutdown. The node swapfiles are still
+// disabled, they would otherwise stay in use by the host
+func DeleteWithForce(force bool) DeleteOption {
+	return deleteOptionAdapter(func(o *deleteOptions) error {
+		o.force = force
+		return nil
+	})
+}
+
+// DeleteWithGracefulShutdown drains the nodes and cleanly stops the kubelet,
+// etcd and all other containers before the nodes are deleted, so that e.g.
+// images committed from the nodes hold consistent state. It is best effort,
+// failures are logged as warnings and the cluster is deleted regardless.
+func DeleteWithGracefulShutdown(graceful bool) DeleteOption {
+	return deleteOptionAdapter(func(o *deleteOptions) error {
+		o.graceful = graceful
+		return nil
+	})
+}
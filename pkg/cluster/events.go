@@ -0,0 +1,44 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"sigs.k8s.io/kind/pkg/internal/events"
+)
+
+// Event is a progress event sent to a sink configured with
+// ProviderWithEventSink, fields are set depending on Type
+type Event = events.Event
+
+// EventType identifies the kind of an Event
+type EventType = events.Type
+
+const (
+	// EventPhaseStarted is sent when a phase of create or delete begins
+	EventPhaseStarted = events.PhaseStarted
+	// EventPhaseCompleted is sent when a phase ends, with Success and Duration
+	EventPhaseCompleted = events.PhaseCompleted
+	// EventNodeCreated is sent after each node container is created
+	EventNodeCreated = events.NodeCreated
+	// EventImagePulled is sent after a node image is pulled, with Duration
+	EventImagePulled = events.ImagePulled
+	// EventWarning is sent for each user facing warning
+	EventWarning = events.Warning
+	// EventStepCompleted is sent when a timed step within a phase ends,
+	// such as creating the docker network, with Phase naming the step
+	EventStepCompleted = events.StepCompleted
+)
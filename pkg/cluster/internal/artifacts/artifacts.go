@@ -0,0 +1,124 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package artifacts implements reading the files a node image ships, such as
+// the Kubernetes binaries, from a node image or a node
+package artifacts
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
+)
+
+// Artifact is a file in the node image
+type Artifact struct {
+	// Name identifies the artifact, e.g. "kubectl"
+	Name string
+	// Path is the path of the file in the node image
+	Path string
+	// File is the name to write the file as on the host
+	File string
+	// Executable is true for binaries
+	Executable bool
+}
+
+// All are the artifacts that can be read, in order
+var All = []Artifact{
+	{Name: "kubectl", Path: "/usr/bin/kubectl", File: "kubectl", Executable: true},
+	{Name: "kubeadm", Path: "/usr/bin/kubeadm", File: "kubeadm", Executable: true},
+	{Name: "kubelet", Path: "/usr/bin/kubelet", File: "kubelet", Executable: true},
+	{Name: "containerd-config", Path: "/etc/containerd/config.toml", File: "config.toml"},
+}
+
+// Names returns the names of All
+func Names() []string {
+	names := []string{}
+	for _, a := range All {
+		names = append(names, a.Name)
+	}
+	return names
+}
+
+// Lookup returns the artifact named name
+func Lookup(name string) (Artifact, error) {
+	for _, a := range All {
+		if a.Name == name {
+			return a, nil
+		}
+	}
+	return Artifact{}, errors.Errorf("unknown artifact %q, expected one of: %s", name, strings.Join(Names(), ", "))
+}
+
+// Source is where artifacts are read from, a node image or a node of the
+// cluster
+type Source struct {
+	// Image is the node image to read from, if set a throwaway container is
+	// started from it and the cluster is not used
+	Image string
+	// Node is the node of the cluster to read from if Image is not set, with
+	// or without the cluster name prefix, defaults to the bootstrap control
+	// plane node
+	Node string
+}
+
+// Copy writes the artifact a from src to w
+func Copy(ctx context.Context, p provider.Provider, cluster string, src Source, a Artifact, w io.Writer) error {
+	if src.Image != "" {
+		if err := p.ImageCommand(ctx, src.Image, "cat", a.Path).SetStdout(w).Run(); err != nil {
+			return errors.Wrapf(err, "failed to read %s from image %s", a.Path, src.Image)
+		}
+		return nil
+	}
+	n, err := p.ListNodes(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	if len(n) == 0 {
+		return errors.ClusterNotFound(cluster)
+	}
+	node, err := selectNode(n, cluster, src.Node)
+	if err != nil {
+		return err
+	}
+	if err := node.CommandContext(ctx, "cat", a.Path).SetStdout(w).Run(); err != nil {
+		return errors.Wrapf(err, "failed to read %s from node %s", a.Path, node.String())
+	}
+	return nil
+}
+
+// selectNode returns the node in allNodes matching node, which may be either
+// the full node name or the name without the cluster prefix, e.g. "worker"
+// if node is empty the bootstrap control plane node is returned
+func selectNode(allNodes []nodes.Node, cluster, node string) (nodes.Node, error) {
+	if node == "" {
+		return nodeutils.BootstrapControlPlaneNode(allNodes)
+	}
+	names := make([]string, 0, len(allNodes))
+	for _, n := range allNodes {
+		if n.String() == node || n.String() == cluster+"-"+node {
+			return n, nil
+		}
+		names = append(names, n.String())
+	}
+	return nil, errors.Errorf("unknown node %q, expected one of: %s", node, strings.Join(names, ", "))
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package artifacts
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+type fakeNode struct {
+	nodes.Node
+	name string
+	role string
+}
+
+func (n *fakeNode) String() string {
+	return n.name
+}
+
+func (n *fakeNode) Role() (string, error) {
+	return n.role, nil
+}
+
+func TestLookup(t *testing.T) {
+	t.Parallel()
+	a, err := Lookup("containerd-config")
+	assert.ExpectError(t, false, err)
+	assert.StringEqual(t, "/etc/containerd/config.toml", a.Path)
+	assert.BoolEqual(t, false, a.Executable)
+	_, err = Lookup("kube-proxy")
+	assert.ExpectError(t, true, err)
+}
+
+func TestSelectNode(t *testing.T) {
+	t.Parallel()
+	all := []nodes.Node{
+		&fakeNode{name: "foo-worker", role: constants.WorkerNodeRoleValue},
+		&fakeNode{name: "foo-control-plane", role: constants.ControlPlaneNodeRoleValue},
+	}
+	n, err := selectNode(all, "foo", "")
+	assert.ExpectError(t, false, err)
+	assert.StringEqual(t, "foo-control-plane", n.String())
+	n, err = selectNode(all, "foo", "worker")
+	assert.ExpectError(t, false, err)
+	assert.StringEqual(t, "foo-worker", n.String())
+	_, err = selectNode(all, "foo", "worker2")
+	assert.ExpectError(t, true, err)
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package clone implements creating a cluster as a copy of another
+package clone
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
+	"sigs.k8s.io/kind/pkg/cluster/internal/state"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/apis/config/encoding"
+)
+
+// Cluster creates a new cluster as a copy of the cluster from
+//
+// The node containers of from are committed to images and the new cluster
+// is created from these with the same config, less anything that must be
+// unique to a cluster: explicit node names, hostnames, machine-ids and fixed
+// host ports. Images loaded into the source nodes are copied over, and
+// Kubernetes is then set up from scratch with new certificates.
+// The committed images are labeled with the new cluster's name, and deleted
+// along with it. opts.Config is ignored in favor of the source cluster's
+// config, and opts.NameOverride defaults to "<from>-clone".
+func Cluster(ctx context.Context, logger log.Logger, p provider.Provider, from string, opts *create.ClusterOptions) error {
+	if opts.NameOverride == "" {
+		opts.NameOverride = from + "-clone"
+	}
+	srcNodes, err := p.ListNodes(ctx, from)
+	if err != nil {
+		return err
+	}
+	if len(srcNodes) == 0 {
+		return errors.ClusterNotFound(from)
+	}
+
+	// get the config the source cluster was created with
+	srcCfg, raw, err := sourceConfig(from)
+	if err != nil {
+		return err
+	}
+	srcNames := config.NodeNames(srcCfg)
+	byName := make(map[string]nodes.Node, len(srcNodes))
+	for _, n := range srcNodes {
+		byName[n.String()] = n
+	}
+	for _, name := range srcNames {
+		if _, ok := byName[name]; !ok {
+			return errors.Errorf("cluster %q has no node %q, it no longer matches the config it was created with", from, name)
+		}
+	}
+
+	// commit all of the source nodes
+	logger.V(0).Infof("Committing nodes of cluster %q ...", from)
+	images := commitImages(srcNames, time.Now())
+	fns := make([]func() error, len(srcNames))
+	for i := range srcNames {
+		node, image := byName[srcNames[i]], images[i]
+		fns[i] = func() error {
+			return p.CommitNode(ctx, node, image, opts.NameOverride)
+		}
+	}
+	if err := errors.UntilErrorConcurrent(fns); err != nil {
+		if derr := p.DeleteImages(context.Background(), opts.NameOverride); derr != nil {
+			logger.Warnf("failed to delete the committed node images: %v", derr)
+		}
+		return err
+	}
+
+	// then create the clone from the committed images, recording the
+	// adjusted config rather than the source cluster's
+	cloneRaw, err := cloneRawConfig(raw, srcCfg, images)
+	if err != nil {
+		return err
+	}
+	opts.Config, err = encoding.Parse(cloneRaw)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse the clone config")
+	}
+	opts.RawConfig = string(cloneRaw)
+	opts.NodeImage = ""
+	opts.CloneSources = make([]nodes.Node, len(srcNames))
+	for i, name := range srcNames {
+		opts.CloneSources[i] = byName[name]
+	}
+	return create.Cluster(ctx, logger, p, opts)
+}
+
+// sourceConfig returns the parsed and raw config cluster was created with
+func sourceConfig(cluster string) (*config.Cluster, string, error) {
+	recorded, err := state.Default().Read(cluster)
+	if err != nil {
+		return nil, "", err
+	}
+	raw := ""
+	if recorded != nil {
+		raw = recorded.Config
+	}
+	var cfg *config.Cluster
+	if raw == "" {
+		cfg, err = encoding.Load("")
+	} else {
+		cfg, err = encoding.Parse([]byte(raw))
+	}
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to parse the recorded config of cluster %q", cluster)
+	}
+	cfg.Name = cluster
+	return cfg, raw, nil
+}
+
+// commitImages returns the image to commit each of the named nodes to
+func commitImages(nodeNames []string, now time.Time) []string {
+	tag := now.UTC().Format("20060102-150405")
+	images := make([]string, len(nodeNames))
+	for i, name := range nodeNames {
+		// localhost/ keeps podman from resolving these against registries
+		images[i] = fmt.Sprintf("localhost/kind-clone/%s:%s", name, tag)
+	}
+	return images
+}
+
+// cloneRawConfig returns raw, the config of the source cluster parsed as cfg,
+// using images for the nodes, with the fields that would conflict with the
+// source cluster cleared
+func cloneRawConfig(raw string, cfg *config.Cluster, images []string) ([]byte, error) {
+	overrides := []string{`name=""`, `nodeNameTemplate=""`, "networking.apiServerPort=0"}
+	for i, n := range cfg.Nodes {
+		node := fmt.Sprintf("nodes[%d].", i)
+		// the role is set for the default config, which has no nodes
+		overrides = append(overrides,
+			node+"role="+string(n.Role),
+			node+"image="+images[i],
+			node+`name=""`,
+			node+`hostname=""`,
+			node+`machineID=""`,
+		)
+		for j, pm := range n.ExtraPortMappings {
+			// fixed ports are taken by the source cluster, -1 is left as is
+			if pm.HostPort > 0 {
+				overrides = append(overrides, fmt.Sprintf("%sextraPortMappings[%d].hostPort=0", node, j))
+			}
+		}
+	}
+	return encoding.ApplyOverrides([]byte(raw), overrides)
+}
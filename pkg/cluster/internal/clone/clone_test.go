@@ -0,0 +1,88 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clone
+
+import (
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/apis/config/encoding"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestCommitImages(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2020, 5, 4, 3, 2, 1, 0, time.UTC)
+	assert.DeepEqual(t, []string{
+		"localhost/kind-clone/kind-control-plane:20200504-030201",
+		"localhost/kind-clone/kind-worker:20200504-030201",
+	}, commitImages([]string{"kind-control-plane", "kind-worker"}, now))
+}
+
+func TestCloneRawConfig(t *testing.T) {
+	t.Parallel()
+	raw := `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+name: foo
+nodeNameTemplate: "{{role}}{{index}}"
+networking:
+  apiServerPort: 6443
+  podSubnet: 10.10.0.0/16
+nodes:
+- role: control-plane
+  name: cp
+  hostname: cp.example
+  machineID: 0123456789abcdef0123456789abcdef
+  extraPortMappings:
+  - containerPort: 80
+    hostPort: 8080
+  - containerPort: 443
+    hostPort: -1
+- role: worker
+  clockOffset: 1h
+`
+	src, err := encoding.Parse([]byte(raw))
+	assert.ExpectError(t, false, err)
+	out, err := cloneRawConfig(raw, src, []string{"image-0", "image-1"})
+	assert.ExpectError(t, false, err)
+	clone, err := encoding.Parse(out)
+	assert.ExpectError(t, false, err)
+	assert.StringEqual(t, "", clone.Name)
+	assert.StringEqual(t, "", clone.NodeNameTemplate)
+	assert.DeepEqual(t, int32(0), clone.Networking.APIServerPort)
+	assert.StringEqual(t, "10.10.0.0/16", clone.Networking.PodSubnet)
+	assert.DeepEqual(t, 2, len(clone.Nodes))
+	cp := clone.Nodes[0]
+	assert.StringEqual(t, "image-0", cp.Image)
+	assert.StringEqual(t, "", cp.Name+cp.Hostname+cp.MachineID)
+	assert.DeepEqual(t, int32(0), cp.ExtraPortMappings[0].HostPort)
+	assert.DeepEqual(t, int32(-1), cp.ExtraPortMappings[1].HostPort)
+	assert.StringEqual(t, "image-1", clone.Nodes[1].Image)
+	assert.StringEqual(t, "1h", clone.Nodes[1].ClockOffset)
+
+	// a cluster created without a config has one control plane
+	src, err = encoding.Load("")
+	assert.ExpectError(t, false, err)
+	out, err = cloneRawConfig("", src, []string{"image-0"})
+	assert.ExpectError(t, false, err)
+	clone, err = encoding.Parse(out)
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, 1, len(clone.Nodes))
+	assert.DeepEqual(t, config.ControlPlaneRole, clone.Nodes[0].Role)
+	assert.StringEqual(t, "image-0", clone.Nodes[0].Image)
+}
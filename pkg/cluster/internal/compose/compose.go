@@ -0,0 +1,173 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package compose renders cluster node containers as docker compose or
+// Kubernetes Pod YAML from the node provider's inspect output
+package compose
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+const (
+	// Compose is the docker compose file format
+	Compose = "compose"
+	// Kube is Kubernetes Pod YAML, as consumed by `podman kube play`
+	Kube = "kube"
+)
+
+// container is the subset of docker compatible inspect output we render
+type container struct {
+	ID        string `json:"Id"`
+	Name      string
+	ImageName string // podman only
+	Config    struct {
+		Hostname string
+		Image    string
+		Env      []string
+		Labels   map[string]string
+		Tty      bool
+	}
+	HostConfig struct {
+		Privileged    bool
+		SecurityOpt   []string
+		Tmpfs         map[string]string
+		CgroupnsMode  string
+		RestartPolicy struct {
+			Name              string
+			MaximumRetryCount int
+		}
+		PortBindings map[string][]struct {
+			HostIP   string `json:"HostIp"`
+			HostPort string
+		}
+	}
+	Mounts []struct {
+		Type        string
+		Source      string
+		Destination string
+		RW          bool
+		Propagation string
+	}
+	NetworkSettings struct {
+		Networks map[string]struct {
+			Aliases []string
+		}
+	}
+}
+
+// Render returns the containers described by inspect, the JSON output of
+// `docker inspect` or `podman inspect`, in format (Compose or Kube)
+func Render(inspect []byte, cluster, format string) (string, error) {
+	var containers []container
+	if err := json.Unmarshal(inspect, &containers); err != nil {
+		return "", errors.Wrap(err, "failed to parse inspect output")
+	}
+	for i := range containers {
+		c := &containers[i]
+		c.Name = strings.TrimPrefix(c.Name, "/")
+		if c.Config.Image == "" {
+			c.Config.Image = c.ImageName
+		}
+	}
+	sort.Slice(containers, func(i, j int) bool {
+		return containers[i].Name < containers[j].Name
+	})
+	header := fmt.Sprintf("# generated by kind from the nodes of cluster %q\n", cluster)
+	switch format {
+	case Compose:
+		out, err := marshal(composeFile(cluster, containers))
+		return header + out, err
+	case Kube:
+		docs := make([]string, 0, len(containers))
+		for _, c := range containers {
+			doc, err := marshal(kubePod(c))
+			if err != nil {
+				return "", err
+			}
+			docs = append(docs, doc)
+		}
+		return header + strings.Join(docs, "---\n"), nil
+	}
+	return "", errors.Errorf("unknown format %q, expected %s or %s", format, Compose, Kube)
+}
+
+func marshal(v interface{}) (string, error) {
+	var buff bytes.Buffer
+	enc := yaml.NewEncoder(&buff)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return "", errors.Wrap(err, "failed to encode YAML")
+	}
+	return buff.String(), nil
+}
+
+// ports returns the container's published ports as sorted
+// (hostIP, hostPort, containerPort, protocol) tuples
+func ports(c container) [][4]string {
+	out := [][4]string{}
+	for port, bindings := range c.HostConfig.PortBindings {
+		containerPort, protocol := port, "tcp"
+		if i := strings.Index(port, "/"); i != -1 {
+			containerPort, protocol = port[:i], port[i+1:]
+		}
+		for _, b := range bindings {
+			out = append(out, [4]string{b.HostIP, b.HostPort, containerPort, protocol})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return strings.Join(out[i][:], " ") < strings.Join(out[j][:], " ")
+	})
+	return out
+}
+
+// aliases returns the network aliases, less the container ID alias docker adds
+func aliases(c container, network string) []string {
+	out := []string{}
+	for _, alias := range c.NetworkSettings.Networks[network].Aliases {
+		if len(c.ID) >= 12 && alias == c.ID[:12] {
+			continue
+		}
+		out = append(out, alias)
+	}
+	return out
+}
+
+func sortedNetworks(c container) []string {
+	out := make([]string, 0, len(c.NetworkSettings.Networks))
+	for name := range c.NetworkSettings.Networks {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func sortedTmpfs(c container) []string {
+	out := make([]string, 0, len(c.HostConfig.Tmpfs))
+	for path := range c.HostConfig.Tmpfs {
+		out = append(out, path)
+	}
+	sort.Strings(out)
+	return out
+}
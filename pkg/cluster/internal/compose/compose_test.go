@@ -0,0 +1,150 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compose
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+const inspect = `[
+  {
+    "Id": "0123456789abcdef",
+    "Name": "/kind-worker",
+    "Config": {
+      "Hostname": "kind-worker",
+      "Image": "kindest/node:v1.21.1",
+      "Env": ["container=docker"],
+      "Labels": {"io.x-k8s.kind.cluster": "kind"},
+      "Tty": true
+    },
+    "HostConfig": {
+      "Privileged": true,
+      "SecurityOpt": ["seccomp=unconfined"],
+      "Tmpfs": {"/tmp": "", "/run": ""},
+      "RestartPolicy": {"Name": "on-failure", "MaximumRetryCount": 1},
+      "PortBindings": {"80/tcp": [{"HostIp": "127.0.0.1", "HostPort": "8080"}]}
+    },
+    "Mounts": [
+      {"Type": "volume", "Source": "/var/lib/docker/volumes/abc/_data", "Destination": "/var", "RW": true},
+      {"Type": "bind", "Source": "/lib/modules", "Destination": "/lib/modules", "RW": false, "Propagation": "rprivate"}
+    ],
+    "NetworkSettings": {"Networks": {"kind": {"Aliases": ["0123456789ab", "kind-worker"]}}}
+  }
+]`
+
+func TestRenderCompose(t *testing.T) {
+	t.Parallel()
+	out, err := Render([]byte(inspect), "kind", Compose)
+	assert.ExpectError(t, false, err)
+	assert.StringEqual(t, `# generated by kind from the nodes of cluster "kind"
+name: kind
+services:
+  kind-worker:
+    image: kindest/node:v1.21.1
+    container_name: kind-worker
+    hostname: kind-worker
+    privileged: true
+    tty: true
+    restart: on-failure:1
+    security_opt:
+      - seccomp=unconfined
+    tmpfs:
+      - /run
+      - /tmp
+    environment:
+      - container=docker
+    labels:
+      io.x-k8s.kind.cluster: kind
+    volumes:
+      - type: volume
+        target: /var
+      - type: bind
+        source: /lib/modules
+        target: /lib/modules
+        read_only: true
+    ports:
+      - 127.0.0.1:8080:80/tcp
+    networks:
+      kind:
+        aliases:
+          - kind-worker
+networks:
+  kind:
+    external: true
+`, out)
+}
+
+func TestRenderKube(t *testing.T) {
+	t.Parallel()
+	out, err := Render([]byte(inspect), "kind", Kube)
+	assert.ExpectError(t, false, err)
+	assert.StringEqual(t, `# generated by kind from the nodes of cluster "kind"
+apiVersion: v1
+kind: Pod
+metadata:
+  name: kind-worker
+  labels:
+    io.x-k8s.kind.cluster: kind
+spec:
+  hostname: kind-worker
+  restartPolicy: OnFailure
+  containers:
+    - name: kind-worker
+      image: kindest/node:v1.21.1
+      tty: true
+      env:
+        - name: container
+          value: docker
+      securityContext:
+        privileged: true
+      ports:
+        - containerPort: 80
+          hostPort: 8080
+          hostIP: 127.0.0.1
+          protocol: TCP
+      volumeMounts:
+        - name: volume-0
+          mountPath: /var
+        - name: volume-1
+          mountPath: /lib/modules
+          readOnly: true
+        - name: volume-2
+          mountPath: /run
+        - name: volume-3
+          mountPath: /tmp
+  volumes:
+    - name: volume-0
+      emptyDir: {}
+    - name: volume-1
+      hostPath:
+        path: /lib/modules
+    - name: volume-2
+      emptyDir:
+        medium: Memory
+    - name: volume-3
+      emptyDir:
+        medium: Memory
+`, out)
+}
+
+func TestRenderUnknownFormat(t *testing.T) {
+	t.Parallel()
+	_, err := Render([]byte(inspect), "kind", "helm")
+	assert.ExpectError(t, true, err)
+}
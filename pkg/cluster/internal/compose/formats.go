@@ -0,0 +1,234 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compose
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type composeVolume struct {
+	Type     string            `yaml:"type"`
+	Source   string            `yaml:"source,omitempty"`
+	Target   string            `yaml:"target"`
+	ReadOnly bool              `yaml:"read_only,omitempty"`
+	Bind     map[string]string `yaml:"bind,omitempty"`
+}
+
+type composeNetwork struct {
+	Aliases []string `yaml:"aliases,omitempty"`
+}
+
+type composeService struct {
+	Image         string                     `yaml:"image"`
+	ContainerName string                     `yaml:"container_name"`
+	Hostname      string                     `yaml:"hostname,omitempty"`
+	Privileged    bool                       `yaml:"privileged,omitempty"`
+	Tty           bool                       `yaml:"tty,omitempty"`
+	Restart       string                     `yaml:"restart,omitempty"`
+	CgroupNS      string                     `yaml:"cgroup,omitempty"`
+	SecurityOpt   []string                   `yaml:"security_opt,omitempty"`
+	Tmpfs         []string                   `yaml:"tmpfs,omitempty"`
+	Environment   []string                   `yaml:"environment,omitempty"`
+	Labels        map[string]string          `yaml:"labels,omitempty"`
+	Volumes       []composeVolume            `yaml:"volumes,omitempty"`
+	Ports         []string                   `yaml:"ports,omitempty"`
+	Networks      map[string]*composeNetwork `yaml:"networks,omitempty"`
+}
+
+type composeFileSpec struct {
+	Name     string                     `yaml:"name"`
+	Services map[string]composeService  `yaml:"services"`
+	Networks map[string]map[string]bool `yaml:"networks,omitempty"`
+}
+
+// composeFile converts containers to a compose file, networks are external
+// since kind creates and shares them between clusters
+func composeFile(cluster string, containers []container) composeFileSpec {
+	out := composeFileSpec{
+		Name:     cluster,
+		Services: map[string]composeService{},
+	}
+	for _, c := range containers {
+		s := composeService{
+			Image:         c.Config.Image,
+			ContainerName: c.Name,
+			Hostname:      c.Config.Hostname,
+			Privileged:    c.HostConfig.Privileged,
+			Tty:           c.Config.Tty,
+			CgroupNS:      c.HostConfig.CgroupnsMode,
+			SecurityOpt:   c.HostConfig.SecurityOpt,
+			Tmpfs:         sortedTmpfs(c),
+			Environment:   c.Config.Env,
+			Labels:        c.Config.Labels,
+		}
+		if policy := c.HostConfig.RestartPolicy; policy.Name != "" && policy.Name != "no" {
+			s.Restart = policy.Name
+			if policy.MaximumRetryCount > 0 {
+				s.Restart += ":" + strconv.Itoa(policy.MaximumRetryCount)
+			}
+		}
+		for _, m := range c.Mounts {
+			v := composeVolume{Type: m.Type, Target: m.Destination, ReadOnly: !m.RW}
+			if m.Type == "bind" {
+				v.Source = m.Source
+				if m.Propagation != "" && m.Propagation != "rprivate" {
+					v.Bind = map[string]string{"propagation": m.Propagation}
+				}
+			}
+			s.Volumes = append(s.Volumes, v)
+		}
+		for _, p := range ports(c) {
+			hostIP, hostPort, containerPort, protocol := p[0], p[1], p[2], p[3]
+			mapping := fmt.Sprintf("%s:%s/%s", hostPort, containerPort, protocol)
+			if hostIP != "" {
+				// IPv6 host addresses must be bracketed
+				if strings.Contains(hostIP, ":") {
+					hostIP = "[" + hostIP + "]"
+				}
+				mapping = hostIP + ":" + mapping
+			}
+			s.Ports = append(s.Ports, mapping)
+		}
+		for _, network := range sortedNetworks(c) {
+			if s.Networks == nil {
+				s.Networks = map[string]*composeNetwork{}
+			}
+			if out.Networks == nil {
+				out.Networks = map[string]map[string]bool{}
+			}
+			s.Networks[network] = &composeNetwork{Aliases: aliases(c, network)}
+			out.Networks[network] = map[string]bool{"external": true}
+		}
+		out.Services[c.Name] = s
+	}
+	return out
+}
+
+type kubeEnvVar struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+}
+
+type kubePort struct {
+	ContainerPort int    `yaml:"containerPort"`
+	HostPort      int    `yaml:"hostPort,omitempty"`
+	HostIP        string `yaml:"hostIP,omitempty"`
+	Protocol      string `yaml:"protocol"`
+}
+
+type kubeVolumeMount struct {
+	Name      string `yaml:"name"`
+	MountPath string `yaml:"mountPath"`
+	ReadOnly  bool   `yaml:"readOnly,omitempty"`
+}
+
+type kubeVolume struct {
+	Name     string            `yaml:"name"`
+	HostPath map[string]string `yaml:"hostPath,omitempty"`
+	EmptyDir *kubeEmptyDir     `yaml:"emptyDir,omitempty"`
+}
+
+type kubeEmptyDir struct {
+	Medium string `yaml:"medium,omitempty"`
+}
+
+type kubeContainer struct {
+	Name            string            `yaml:"name"`
+	Image           string            `yaml:"image"`
+	TTY             bool              `yaml:"tty,omitempty"`
+	Env             []kubeEnvVar      `yaml:"env,omitempty"`
+	SecurityContext map[string]bool   `yaml:"securityContext,omitempty"`
+	Ports           []kubePort        `yaml:"ports,omitempty"`
+	VolumeMounts    []kubeVolumeMount `yaml:"volumeMounts,omitempty"`
+}
+
+type kubePodSpec struct {
+	Hostname      string          `yaml:"hostname,omitempty"`
+	RestartPolicy string          `yaml:"restartPolicy,omitempty"`
+	Containers    []kubeContainer `yaml:"containers"`
+	Volumes       []kubeVolume    `yaml:"volumes,omitempty"`
+}
+
+type kubePodSpecFile struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Metadata   struct {
+		Name   string            `yaml:"name"`
+		Labels map[string]string `yaml:"labels,omitempty"`
+	} `yaml:"metadata"`
+	Spec kubePodSpec `yaml:"spec"`
+}
+
+// kubePod converts a container to a Pod, anonymous volumes such as /var and
+// tmpfs mounts become emptyDir volumes
+func kubePod(c container) kubePodSpecFile {
+	out := kubePodSpecFile{APIVersion: "v1", Kind: "Pod"}
+	out.Metadata.Name = c.Name
+	out.Metadata.Labels = c.Config.Labels
+	out.Spec.Hostname = c.Config.Hostname
+	switch c.HostConfig.RestartPolicy.Name {
+	case "always", "unless-stopped":
+		out.Spec.RestartPolicy = "Always"
+	case "on-failure":
+		out.Spec.RestartPolicy = "OnFailure"
+	}
+	k := kubeContainer{
+		Name:  c.Name,
+		Image: c.Config.Image,
+		TTY:   c.Config.Tty,
+	}
+	if c.HostConfig.Privileged {
+		k.SecurityContext = map[string]bool{"privileged": true}
+	}
+	for _, env := range c.Config.Env {
+		parts := strings.SplitN(env, "=", 2)
+		v := kubeEnvVar{Name: parts[0]}
+		if len(parts) == 2 {
+			v.Value = parts[1]
+		}
+		k.Env = append(k.Env, v)
+	}
+	for _, p := range ports(c) {
+		containerPort, _ := strconv.Atoi(p[2])
+		hostPort, _ := strconv.Atoi(p[1])
+		k.Ports = append(k.Ports, kubePort{
+			ContainerPort: containerPort,
+			HostPort:      hostPort,
+			HostIP:        p[0],
+			Protocol:      strings.ToUpper(p[3]),
+		})
+	}
+	addVolume := func(v kubeVolume, mountPath string, readOnly bool) {
+		v.Name = fmt.Sprintf("volume-%d", len(out.Spec.Volumes))
+		out.Spec.Volumes = append(out.Spec.Volumes, v)
+		k.VolumeMounts = append(k.VolumeMounts, kubeVolumeMount{Name: v.Name, MountPath: mountPath, ReadOnly: readOnly})
+	}
+	for _, m := range c.Mounts {
+		if m.Type == "bind" {
+			addVolume(kubeVolume{HostPath: map[string]string{"path": m.Source}}, m.Destination, !m.RW)
+		} else {
+			addVolume(kubeVolume{EmptyDir: &kubeEmptyDir{}}, m.Destination, !m.RW)
+		}
+	}
+	for _, path := range sortedTmpfs(c) {
+		addVolume(kubeVolume{EmptyDir: &kubeEmptyDir{Medium: "Memory"}}, path, false)
+	}
+	out.Spec.Containers = []kubeContainer{k}
+	return out
+}
@@ -17,9 +17,11 @@ limitations under the License.
 package actions
 
 import (
+	"context"
 	"sync"
 
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/internal/apis/config"
 	"sigs.k8s.io/kind/pkg/internal/cli"
 	"sigs.k8s.io/kind/pkg/log"
@@ -35,6 +37,8 @@ type Action interface {
 
 // ActionContext is data supplied to all actions
 type ActionContext struct {
+	// Context bounds the action, nodes from Nodes() are bound to it
+	Context  context.Context
 	Logger   log.Logger
 	Status   *cli.Status
 	Config   *config.Cluster
@@ -44,12 +48,14 @@ type ActionContext struct {
 
 // NewActionContext returns a new ActionContext
 func NewActionContext(
+	ctx context.Context,
 	logger log.Logger,
 	status *cli.Status,
 	provider provider.Provider,
 	cfg *config.Cluster,
 ) *ActionContext {
 	return &ActionContext{
+		Context:  ctx,
 		Logger:   logger,
 		Status:   status,
 		Provider: provider,
@@ -75,16 +81,34 @@ func (cd *cachedData) setNodes(n []nodes.Node) {
 	cd.nodes = n
 }
 
+// WithNodes returns a copy of ac whose Nodes() are n, for running actions
+// against some of the nodes, e.g. a node added to an existing cluster
+func (ac *ActionContext) WithNodes(n []nodes.Node) *ActionContext {
+	c := *ac
+	c.cache = &cachedData{nodes: n}
+	return &c
+}
+
 // Nodes returns the list of cluster nodes, this is a cached call
 func (ac *ActionContext) Nodes() ([]nodes.Node, error) {
 	cachedNodes := ac.cache.getNodes()
 	if cachedNodes != nil {
 		return cachedNodes, nil
 	}
-	n, err := ac.Provider.ListNodes(ac.Config.Name)
+	n, err := ac.Provider.ListNodes(ac.Context, ac.Config.Name)
 	if err != nil {
 		return nil, err
 	}
 	ac.cache.setNodes(n)
 	return n, nil
 }
+
+// ConfigNode returns the config entry a node was created from
+func ConfigNode(cfg *config.Cluster, node nodes.Node) (*config.Node, error) {
+	for i, name := range config.NodeNames(cfg) {
+		if name == node.String() {
+			return &cfg.Nodes[i], nil
+		}
+	}
+	return nil, errors.Errorf("failed to match node %q to config", node.String())
+}
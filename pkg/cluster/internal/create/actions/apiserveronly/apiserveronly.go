@@ -0,0 +1,121 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package apiserveronly implements the action for starting only etcd and
+// the API server, see config.APIServerOnlyMode
+package apiserveronly
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/diagnostics"
+)
+
+// standaloneKubeletDropIn runs the kubelet without an API server so that it
+// only runs the static pods and never registers a Node
+const standaloneKubeletDropIn = `[Service]
+Environment="KUBELET_KUBECONFIG_ARGS="
+`
+
+// kubeadmPhases are the kubeadm init phases needed to run etcd and the API
+// server, in order. kubelet-start writes the kubelet config and restarts it.
+var kubeadmPhases = [][]string{
+	{"certs", "all"},
+	{"kubeconfig", "admin"},
+	{"etcd", "local"},
+	{"control-plane", "apiserver"},
+	{"kubelet-start"},
+}
+
+// readyTimeout matches the default kubeadm waits for the control plane
+const readyTimeout = 4 * time.Minute
+
+type action struct {
+	verbosity int
+}
+
+// NewAction returns a new action for starting only etcd and the API server,
+// running kubeadm with --v=verbosity
+func NewAction(verbosity int) actions.Action {
+	return &action{verbosity: verbosity}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	ctx.Status.Start("Starting API server 🕹️")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	node, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return err
+	}
+
+	if err := nodeutils.WriteFile(node, "/etc/systemd/system/kubelet.service.d/20-kind-standalone.conf", standaloneKubeletDropIn); err != nil {
+		return errors.Wrap(err, "failed to configure standalone kubelet")
+	}
+	if err := node.Command("systemctl", "daemon-reload").Run(); err != nil {
+		return errors.Wrap(err, "failed to reload systemd")
+	}
+
+	for _, phase := range kubeadmPhases {
+		args := append([]string{"init", "phase"}, phase...)
+		args = append(args, "--config=/kind/kubeadm.conf")
+		if err := actions.RunKubeadm(ctx.Logger, node, a.verbosity, args...); err != nil {
+			return diagnostics.WithNode(
+				errors.Wrapf(err, "failed to run kubeadm init phase %s", strings.Join(phase, " ")), node,
+			)
+		}
+	}
+
+	if err := waitForAPIServer(ctx.Context, node, time.Now().Add(readyTimeout)); err != nil {
+		return diagnostics.WithNode(err, node)
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// waitForAPIServer polls the API server readiness until it is ready, until
+// has passed or ctx is done
+func waitForAPIServer(ctx context.Context, node nodes.Node, until time.Time) error {
+	for until.After(time.Now()) {
+		if err := node.CommandContext(ctx,
+			"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf",
+			"get", "--raw=/healthz",
+		).Run(); err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "stopped waiting for the API server to be ready")
+		case <-time.After(time.Second):
+		}
+	}
+	return errors.New("timed out waiting for the API server to be ready")
+}
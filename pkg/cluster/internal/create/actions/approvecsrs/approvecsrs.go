@@ -0,0 +1,190 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package approvecsrs implements the action to approve the kubelet serving
+// certificate requests of the nodes
+package approvecsrs
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+)
+
+// timeout bounds waiting for every node to request a serving certificate
+const timeout = 2 * time.Minute
+
+// csrJSONPath lists the fields of each certificate signing request needed to
+// identify kubelet serving certificates, separated by tabs
+const csrJSONPath = `{range .items[*]}{.metadata.name}{"\t"}{.spec.username}{"\t"}{.spec.signerName}{"\t"}{.spec.usages}{"\t"}{.status.conditions[*].type}{"\n"}{end}`
+
+type action struct{}
+
+// NewAction returns a new action for approving kubelet serving certificates
+func NewAction() actions.Action {
+	return &action{}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	ctx.Status.Start("Approving kubelet serving certificates 🔏")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	internalNodes, err := nodeutils.InternalNodes(allNodes)
+	if err != nil {
+		return err
+	}
+	controlPlanes, err := nodeutils.ControlPlaneNodes(allNodes)
+	if err != nil {
+		return err
+	}
+	node := controlPlanes[0] // kind expects at least one always
+
+	names := make([]string, 0, len(internalNodes))
+	for _, n := range internalNodes {
+		names = append(names, common.KubernetesNodeName(ctx.Config, n))
+	}
+	if err := Approve(ctx.Context, node, names); err != nil {
+		return err
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// Approve approves the kubelet serving certificate requests of the
+// Kubernetes nodes names with kubectl on controlPlane, until each of them
+// has an approved request. This is also used for nodes joined later
+func Approve(ctx context.Context, controlPlane nodes.Node, names []string) error {
+	// kubelets request their serving certificate shortly after joining,
+	// approve requests until there is one for every node
+	deadline := time.Now().Add(timeout)
+	for {
+		csrs, err := listCSRs(ctx, controlPlane)
+		if err != nil {
+			return err
+		}
+		approved, pending := servingCSRs(csrs, names)
+		if len(pending) > 0 {
+			args := append([]string{
+				"--kubeconfig=/etc/kubernetes/admin.conf", "certificate", "approve",
+			}, pending...)
+			if err := controlPlane.CommandContext(ctx, "kubectl", args...).Run(); err != nil {
+				return errors.Wrap(err, "failed to approve kubelet serving certificates")
+			}
+			continue
+		}
+		if approved >= len(names) {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errors.Errorf("timed out waiting for kubelet serving certificate requests, %d of %d nodes approved", approved, len(names))
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "stopped waiting for kubelet serving certificate requests, %d of %d nodes approved", approved, len(names))
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// csr is the subset of a certificate signing request used by this action
+type csr struct {
+	Name       string
+	Username   string
+	SignerName string
+	Usages     string
+	Conditions string
+}
+
+func listCSRs(ctx context.Context, node nodes.Node) ([]csr, error) {
+	lines, err := exec.OutputLines(node.CommandContext(ctx,
+		"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf",
+		"get", "csr", "-o", "jsonpath="+csrJSONPath,
+	))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list certificate signing requests")
+	}
+	return parseCSRs(lines), nil
+}
+
+// parseCSRs parses the output of csrJSONPath
+func parseCSRs(lines []string) []csr {
+	csrs := []csr{}
+	for _, line := range lines {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 5 || fields[0] == "" {
+			continue
+		}
+		csrs = append(csrs, csr{
+			Name:       fields[0],
+			Username:   fields[1],
+			SignerName: fields[2],
+			Usages:     fields[3],
+			Conditions: fields[4],
+		})
+	}
+	return csrs
+}
+
+// servingCSRs returns the number of the Kubernetes nodes names with an
+// approved kubelet serving certificate request, and the names of their
+// requests still pending
+func servingCSRs(csrs []csr, names []string) (approved int, pending []string) {
+	users := map[string]bool{}
+	for _, name := range names {
+		users["system:node:"+name] = true
+	}
+	approvedNodes := map[string]bool{}
+	for _, c := range csrs {
+		if !users[c.Username] || !isKubeletServing(c) {
+			continue
+		}
+		switch {
+		case strings.Contains(c.Conditions, "Approved"):
+			approvedNodes[c.Username] = true
+		case strings.Contains(c.Conditions, "Denied"):
+		default:
+			pending = append(pending, c.Name)
+		}
+	}
+	return len(approvedNodes), pending
+}
+
+// isKubeletServing returns true if c was requested by a kubelet for its
+// serving certificate, requests only record a signerName from Kubernetes 1.18
+func isKubeletServing(c csr) bool {
+	if !strings.HasPrefix(c.Username, "system:node:") {
+		return false
+	}
+	if c.SignerName != "" {
+		return c.SignerName == "kubernetes.io/kubelet-serving"
+	}
+	return strings.Contains(c.Usages, "server auth")
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package approvecsrs
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestServingCSRs(t *testing.T) {
+	t.Parallel()
+	csrs := parseCSRs([]string{
+		// client certificates are approved by kube-controller-manager
+		"csr-a\tsystem:bootstrap:abcdef\tkubernetes.io/kube-apiserver-client-kubelet\t[\"client auth\"]\tApproved",
+		"csr-b\tsystem:node:kind-control-plane\tkubernetes.io/kubelet-serving\t[\"server auth\"]\tApproved",
+		"csr-c\tsystem:node:kind-worker\tkubernetes.io/kubelet-serving\t[\"server auth\"]\t",
+		// before Kubernetes 1.18 there is no signerName
+		"csr-d\tsystem:node:kind-worker2\t\t[\"digital signature\",\"key encipherment\",\"server auth\"]\t",
+		"csr-e\tsystem:node:kind-worker3\tkubernetes.io/kubelet-serving\t[\"server auth\"]\tDenied",
+		"",
+	})
+	approved, pending := servingCSRs(csrs, []string{"kind-control-plane", "kind-worker", "kind-worker2", "kind-worker3"})
+	if approved != 1 {
+		t.Errorf("expected 1 approved node, got %d", approved)
+	}
+	assert.DeepEqual(t, []string{"csr-c", "csr-d"}, pending)
+
+	// only the requests of the given nodes are approved, e.g. a joined node
+	approved, pending = servingCSRs(csrs, []string{"kind-worker"})
+	if approved != 0 {
+		t.Errorf("expected 0 approved nodes, got %d", approved)
+	}
+	assert.DeepEqual(t, []string{"csr-c"}, pending)
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package clockoffset implements the action to shift node clocks
+package clockoffset
+
+import (
+	"fmt"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+)
+
+type action struct{}
+
+// NewAction returns a new action for applying node clock offsets
+//
+// This runs after the nodes have joined so that kubeadm generates
+// certificates with the real clock, otherwise the control plane would
+// reject them as not yet valid.
+func NewAction() actions.Action {
+	return &action{}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	ctx.Status.Start("Shifting node clocks ⏰")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+
+	fns := []func() error{}
+	for _, node := range allNodes {
+		node := node // capture loop variable
+		configNode, err := actions.ConfigNode(ctx.Config, node)
+		if err != nil {
+			// nodes not from the config, e.g. the load balancer
+			continue
+		}
+		if configNode.ClockOffset == "" {
+			continue
+		}
+		offset, err := time.ParseDuration(configNode.ClockOffset)
+		if err != nil {
+			return errors.Wrapf(err, "invalid clockOffset for node %s", node.String())
+		}
+		fns = append(fns, func() error {
+			return apply(node, offset)
+		})
+	}
+	if err := errors.UntilErrorConcurrent(fns); err != nil {
+		return err
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// apply configures libfaketime for the node services that run pods and
+// restarts them so they pick up the offset
+func apply(node nodes.Node, offset time.Duration) error {
+	// libfaketime reads relative offsets in seconds from /etc/faketimerc
+	if err := nodeutils.WriteFile(node, "/etc/faketimerc", faketime(offset)); err != nil {
+		return errors.Wrap(err, "failed to write faketime config")
+	}
+	if err := node.Command("bash", "-c", preloadScript).Run(); err != nil {
+		return errors.Wrapf(err, "failed to enable libfaketime on node %s, the node image may not include it", node.String())
+	}
+	if err := node.Command("systemctl", "restart", "containerd", "kubelet").Run(); err != nil {
+		return errors.Wrap(err, "failed to restart node services after shifting the clock")
+	}
+	return nil
+}
+
+// faketime returns the libfaketime relative offset spec for offset
+func faketime(offset time.Duration) string {
+	return fmt.Sprintf("%+d\n", int64(offset/time.Second))
+}
+
+// preloadScript preloads libfaketime into containerd and the kubelet with
+// systemd drop-ins, rather than /etc/ld.so.preload, so that kubeadm, kubectl
+// and other commands run on the node keep the real clock. The library path
+// depends on the node architecture
+const preloadScript = `set -e
+lib="$(ls /usr/lib/*/faketime/libfaketime.so.1 | head -n1)"
+for unit in containerd kubelet; do
+  mkdir -p "/etc/systemd/system/${unit}.service.d"
+  printf '[Service]\nEnvironment="LD_PRELOAD=%s"\n' "${lib}" >"/etc/systemd/system/${unit}.service.d/20-faketime.conf"
+done
+systemctl daemon-reload
+`
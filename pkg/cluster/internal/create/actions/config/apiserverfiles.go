@@ -0,0 +1,71 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// AdmissionConfigDir and AuthorizationConfigDir are where the directories of
+// the admissionConfiguration and authorizationConfiguration files are mounted
+// on the control plane nodes, and into the API server
+const (
+	AdmissionConfigDir     = "/etc/kubernetes/kind/admission"
+	AuthorizationConfigDir = "/etc/kubernetes/kind/authorization"
+)
+
+// APIServerConfigMounts returns the mounts the control plane nodes need for
+// the API server config files of cfg, the files must exist
+func APIServerConfigMounts(cfg *config.Cluster) ([]config.Mount, error) {
+	mounts := []config.Mount{}
+	files := []struct{ field, hostPath, dir string }{
+		{"admissionConfiguration", cfg.AdmissionConfiguration, AdmissionConfigDir},
+		{"authorizationConfiguration", cfg.AuthorizationConfiguration, AuthorizationConfigDir},
+	}
+	for _, f := range files {
+		if f.hostPath == "" {
+			continue
+		}
+		info, err := os.Stat(f.hostPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", f.field)
+		}
+		if info.IsDir() {
+			return nil, errors.Errorf("invalid %s: %s is a directory", f.field, f.hostPath)
+		}
+		mounts = append(mounts, config.Mount{
+			HostPath:      filepath.Dir(f.hostPath),
+			ContainerPath: f.dir,
+			Readonly:      true,
+		})
+	}
+	return mounts, nil
+}
+
+// apiServerConfigFile returns the path on the node of the file mounted
+// from hostPath into dir, if set
+func apiServerConfigFile(hostPath, dir string) string {
+	if hostPath == "" {
+		return ""
+	}
+	return path.Join(dir, filepath.Base(hostPath))
+}
@@ -19,26 +19,37 @@ package config
 
 import (
 	"bytes"
+	"fmt"
 	"strings"
 
 	"sigs.k8s.io/kind/pkg/cluster/constants"
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
 
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/configureregistries"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/credentialproviders"
 	"sigs.k8s.io/kind/pkg/cluster/internal/kubeadm"
+	"sigs.k8s.io/kind/pkg/cluster/internal/oidc"
 	"sigs.k8s.io/kind/pkg/cluster/internal/patch"
 	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/warnings"
 )
 
 // Action implements action for creating the node config files
-type Action struct{}
+type Action struct {
+	mutators []patch.Mutator
+}
 
 // NewAction returns a new action for creating the config files
-func NewAction() actions.Action {
-	return &Action{}
+// mutators are applied to the generated kubeadm config after all patches
+func NewAction(mutators []patch.Mutator) actions.Action {
+	return &Action{
+		mutators: mutators,
+	}
 }
 
 // Execute runs the action
@@ -51,31 +62,21 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 		return err
 	}
 
-	controlPlaneEndpoint, err := ctx.Provider.GetAPIServerInternalEndpoint(ctx.Config.Name)
-	if err != nil {
-		return err
+	// nodes either join our own control plane or an external one
+	controlPlaneEndpoint := ctx.Config.ExternalControlPlane.Endpoint
+	if controlPlaneEndpoint == "" {
+		if controlPlaneEndpoint, err = ctx.Provider.GetAPIServerInternalEndpoint(ctx.Context, ctx.Config.Name); err != nil {
+			return err
+		}
 	}
 
 	// create kubeadm init config
 	fns := []func() error{}
-
-	configData := kubeadm.ConfigData{
-		ClusterName:          ctx.Config.Name,
-		ControlPlaneEndpoint: controlPlaneEndpoint,
-		APIBindPort:          common.APIServerInternalPort,
-		APIServerAddress:     ctx.Config.Networking.APIServerAddress,
-		Token:                kubeadm.Token,
-		PodSubnet:            ctx.Config.Networking.PodSubnet,
-		KubeProxyMode:        string(ctx.Config.Networking.KubeProxyMode),
-		ServiceSubnet:        ctx.Config.Networking.ServiceSubnet,
-		ControlPlane:         true,
-		IPv6:                 ctx.Config.Networking.IPFamily == "ipv6",
-		FeatureGates:         ctx.Config.FeatureGates,
-	}
+	configData := KubeadmConfigData(ctx.Config, controlPlaneEndpoint)
 
 	kubeadmConfigPlusPatches := func(node nodes.Node, data kubeadm.ConfigData) func() error {
 		return func() error {
-			kubeadmConfig, err := getKubeadmConfig(ctx.Config, data, node)
+			kubeadmConfig, err := getKubeadmConfig(ctx.Config, data, node, a.mutators)
 			if err != nil {
 				// TODO(bentheelder): logging here
 				return errors.Wrap(err, "failed to generate kubeadm config content")
@@ -92,6 +93,13 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 		return err
 	}
 
+	// catch feature gate typos before kubeadm fails on them
+	if len(controlPlanes) > 0 {
+		if err := reportConfigWarnings(ctx, controlPlanes[0]); err != nil {
+			return err
+		}
+	}
+
 	for _, node := range controlPlanes {
 		node := node             // capture loop variable
 		configData := configData // copy config data
@@ -119,40 +127,83 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 	}
 
 	// if we have containerd config, patch all the nodes concurrently
-	if len(ctx.Config.ContainerdConfigPatches) > 0 || len(ctx.Config.ContainerdConfigPatchesJSON6902) > 0 {
-		// we only want to patch kubernetes nodes
-		// this is a cheap workaround to re-use the already listed
-		// workers + control planes
-		kubeNodes := append([]nodes.Node{}, controlPlanes...)
-		kubeNodes = append(kubeNodes, workers...)
-		fns := make([]func() error, len(kubeNodes))
-		for i, node := range kubeNodes {
-			node := node // capture loop variable
-			fns[i] = func() error {
-				// read and patch the config
-				const containerdConfigPath = "/etc/containerd/config.toml"
-				var buff bytes.Buffer
-				if err := node.Command("cat", containerdConfigPath).SetStdout(&buff).Run(); err != nil {
-					return errors.Wrap(err, "failed to read containerd config from node")
-				}
-				patched, err := patch.TOML(buff.String(), ctx.Config.ContainerdConfigPatches, ctx.Config.ContainerdConfigPatchesJSON6902)
-				if err != nil {
-					return errors.Wrap(err, "failed to patch contianerd config")
-				}
-				if err := nodeutils.WriteFile(node, containerdConfigPath, patched); err != nil {
-					return errors.Wrap(err, "failed to write patched containerd config")
+	// we only want to patch kubernetes nodes
+	// this is a cheap workaround to re-use the already listed
+	// workers + control planes
+	kubeNodes := append([]nodes.Node{}, controlPlanes...)
+	kubeNodes = append(kubeNodes, workers...)
+	fns = []func() error{}
+	for _, node := range kubeNodes {
+		node := node // capture loop variable
+		configNode, err := actions.ConfigNode(ctx.Config, node)
+		if err != nil {
+			return err
+		}
+		patches := ctx.Config.ContainerdConfigPatches
+		// GPU nodes need the NVIDIA runtime, user patches may still override this
+		if configNode.GPUs != "" {
+			patches = append([]string{nvidiaContainerdConfigPatch}, patches...)
+		}
+		// and the sandbox image override
+		if pause := ctx.Config.Images.Pause; pause != "" {
+			patches = append([]string{fmt.Sprintf(sandboxImageContainerdConfigPatch, pause)}, patches...)
+		}
+		// select the snapshotter, this is applied first so users may tune it
+		snapshotter, hasSnapshotter := snapshotters[ctx.Config.ContainerdSnapshotter]
+		if hasSnapshotter {
+			patches = append([]string{snapshotter.patch}, patches...)
+		}
+		// and the metrics endpoint
+		if ctx.Config.ContainerdMetrics {
+			patches = append([]string{metricsContainerdConfigPatch}, patches...)
+		}
+		// the registries host files replace the old registry mirrors config
+		if len(ctx.Config.Registries) > 0 {
+			patches = append([]string{fmt.Sprintf(registriesContainerdConfigPatch, configureregistries.HostsDir)}, patches...)
+		}
+		// likewise user patches may override the container log line limit
+		if maxLineSize := ctx.Config.ContainerLogs.MaxLineSize; maxLineSize != 0 {
+			patches = append([]string{fmt.Sprintf(maxLineSizeContainerdConfigPatch, maxLineSize)}, patches...)
+		}
+		if len(patches) == 0 && len(ctx.Config.ContainerdConfigPatchesJSON6902) == 0 {
+			continue
+		}
+		fns = append(fns, func() error {
+			// older containerd would silently ignore the registries
+			if len(ctx.Config.Registries) > 0 {
+				if err := configureregistries.CheckContainerd(node); err != nil {
+					return err
 				}
-				// restart containerd now that we've re-configured it
-				// skip if the systemd (also the containerd) is not running
-				if err := node.Command("bash", "-c", `! systemctl is-system-running || systemctl restart containerd`).Run(); err != nil {
-					return errors.Wrap(err, "failed to restart containerd after patching config")
+			}
+			// read and patch the config
+			const containerdConfigPath = "/etc/containerd/config.toml"
+			var buff bytes.Buffer
+			if err := node.Command("cat", containerdConfigPath).SetStdout(&buff).Run(); err != nil {
+				return errors.Wrap(err, "failed to read containerd config from node")
+			}
+			patched, err := patch.TOML(buff.String(), patches, ctx.Config.ContainerdConfigPatchesJSON6902)
+			if err != nil {
+				return errors.Wrap(err, "failed to patch contianerd config")
+			}
+			if err := nodeutils.WriteFile(node, containerdConfigPath, patched); err != nil {
+				return errors.Wrap(err, "failed to write patched containerd config")
+			}
+			// the proxy snapshotter must be serving before containerd restarts
+			if hasSnapshotter {
+				if err := startSnapshotter(node, ctx.Config.ContainerdSnapshotter, snapshotter); err != nil {
+					return err
 				}
-				return nil
 			}
-		}
-		if err := errors.UntilErrorConcurrent(fns); err != nil {
-			return err
-		}
+			// restart containerd now that we've re-configured it
+			// skip if the systemd (also the containerd) is not running
+			if err := node.Command("bash", "-c", `! systemctl is-system-running || systemctl restart containerd`).Run(); err != nil {
+				return errors.Wrap(err, "failed to restart containerd after patching config")
+			}
+			return nil
+		})
+	}
+	if err := errors.UntilErrorConcurrent(fns); err != nil {
+		return err
 	}
 
 	// mark success
@@ -160,9 +211,81 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 	return nil
 }
 
+// KubeadmConfigData returns the kubeadm config template data for cfg that is
+// the same on every node, the nodes join the control plane at controlPlaneEndpoint
+func KubeadmConfigData(cfg *config.Cluster, controlPlaneEndpoint string) kubeadm.ConfigData {
+	token := kubeadm.Token
+	if cfg.ExternalControlPlane.Endpoint != "" {
+		token = cfg.ExternalControlPlane.Token
+	}
+	data := kubeadm.ConfigData{
+		ClusterName:                  cfg.Name,
+		ControlPlaneEndpoint:         controlPlaneEndpoint,
+		APIBindPort:                  common.APIServerInternalPort,
+		APIServerAddress:             cfg.Networking.APIServerAddress,
+		AdditionalAPIServerAddresses: cfg.Networking.AdditionalAPIServerAddresses,
+		Token:                        token,
+		CACertHashes:                 cfg.ExternalControlPlane.CACertHashes,
+		PodSubnet:                    cfg.Networking.PodSubnet,
+		KubeProxyMode:                string(cfg.Networking.KubeProxyMode),
+		ServiceSubnet:                cfg.Networking.ServiceSubnet,
+		ControlPlane:                 true,
+		IPv6:                         cfg.Networking.IPFamily == "ipv6",
+		FeatureGates:                 cfg.FeatureGates,
+		ComponentFeatureGates: kubeadm.ComponentFeatureGates{
+			APIServer:         cfg.ComponentFeatureGates.APIServer,
+			ControllerManager: cfg.ComponentFeatureGates.ControllerManager,
+			Scheduler:         cfg.ComponentFeatureGates.Scheduler,
+			Kubelet:           cfg.ComponentFeatureGates.Kubelet,
+			KubeProxy:         cfg.ComponentFeatureGates.KubeProxy,
+		},
+		RuntimeConfig:               cfg.RuntimeConfig,
+		OIDC:                        kubeadmOIDC(cfg),
+		AdmissionConfigFile:         apiServerConfigFile(cfg.AdmissionConfiguration, AdmissionConfigDir),
+		AuthorizationConfigFile:     apiServerConfigFile(cfg.AuthorizationConfiguration, AuthorizationConfigDir),
+		ContainerLogMaxSize:         cfg.ContainerLogs.MaxSize,
+		ContainerLogMaxFiles:        cfg.ContainerLogs.MaxFiles,
+		ImageGCHighThresholdPercent: cfg.ImageGC.HighThresholdPercent,
+		ImageGCLowThresholdPercent:  cfg.ImageGC.LowThresholdPercent,
+		ImageMinimumGCAge:           cfg.ImageGC.MinimumAge,
+		KubeletServerTLSBootstrap:   cfg.KubeletServerTLSBootstrap,
+		ImageRepository:             cfg.Images.Repository,
+		CoreDNSImage:                cfg.Images.CoreDNS,
+		EtcdImage:                   cfg.Images.Etcd,
+	}
+	if len(cfg.CredentialProviders.Providers) > 0 {
+		data.ImageCredentialProviderConfig = credentialproviders.ConfigPath
+		data.ImageCredentialProviderBinDir = credentialproviders.BinDir
+	}
+	etcdConfigData(cfg, &data)
+	return data
+}
+
+// kubeadmOIDC returns the API server OIDC flags for cfg
+func kubeadmOIDC(cfg *config.Cluster) kubeadm.OIDC {
+	o := cfg.Authentication.OIDC
+	out := kubeadm.OIDC{
+		IssuerURL:      o.IssuerURL,
+		ClientID:       o.ClientID,
+		UsernameClaim:  o.UsernameClaim,
+		UsernamePrefix: o.UsernamePrefix,
+		GroupsClaim:    o.GroupsClaim,
+		GroupsPrefix:   o.GroupsPrefix,
+		RequiredClaims: o.RequiredClaims,
+	}
+	if o.TestIssuer {
+		out.IssuerURL = oidc.IssuerURL(cfg.Name)
+	}
+	// the configureoidc action writes the CA
+	if o.TestIssuer || o.CA != "" {
+		out.CAFile = oidc.CAPath
+	}
+	return out
+}
+
 // getKubeadmConfig generates the kubeadm config contents for the cluster
 // by running data through the template and applying patches as needed.
-func getKubeadmConfig(cfg *config.Cluster, data kubeadm.ConfigData, node nodes.Node) (path string, err error) {
+func getKubeadmConfig(cfg *config.Cluster, data kubeadm.ConfigData, node nodes.Node, mutators []patch.Mutator) (path string, err error) {
 	kubeVersion, err := nodeutils.KubeVersion(node)
 	if err != nil {
 		// TODO(bentheelder): logging here
@@ -170,22 +293,11 @@ func getKubeadmConfig(cfg *config.Cluster, data kubeadm.ConfigData, node nodes.N
 	}
 	data.KubernetesVersion = kubeVersion
 
-	// TODO: gross hack!
-	// identify node in config by matching name (since these are named in order)
-	// we should really just streamline the bootstrap code and maintain
-	// this mapping ... something for the next major refactor
-	var configNode *config.Node
-	namer := common.MakeNodeNamer("")
-	for i := range cfg.Nodes {
-		n := &cfg.Nodes[i]
-		nodeSuffix := namer(string(n.Role))
-		if strings.HasSuffix(node.String(), nodeSuffix) {
-			configNode = n
-		}
-	}
-	if configNode == nil {
-		return "", errors.Errorf("failed to match node %q to config", node.String())
+	configNode, err := actions.ConfigNode(cfg, node)
+	if err != nil {
+		return "", err
 	}
+	data.NodeLabels = configNode.Labels
 
 	// get the node ip address
 	nodeAddress, nodeAddressIPv6, err := node.IP()
@@ -202,31 +314,189 @@ func getKubeadmConfig(cfg *config.Cluster, data kubeadm.ConfigData, node nodes.N
 		data.NodeAddress = nodeAddressIPv6
 	}
 
+	return RenderKubeadmConfig(cfg, data, node.String(), configNode, mutators)
+}
+
+// RenderKubeadmConfig runs the complete data for configNode through the
+// kubeadm config template, then applies the cluster patches targeting
+// nodeName and the node patches followed by mutators
+func RenderKubeadmConfig(cfg *config.Cluster, data kubeadm.ConfigData, nodeName string, configNode *config.Node, mutators []patch.Mutator) (string, error) {
 	// generate the config contents
 	cf, err := kubeadm.Config(data)
 	if err != nil {
 		return "", err
 	}
 
-	clusterPatches, clusterJSONPatches := allPatchesFromConfig(cfg)
+	api, err := kubeadm.APIVersionFor(data.KubernetesVersion)
+	if err != nil {
+		return "", err
+	}
+
+	clusterPatches, clusterJSONPatches, err := patchesForNode(cfg, nodeName, configNode)
+	if err != nil {
+		return "", err
+	}
 	// apply cluster-level patches first
-	patchedConfig, err := patch.KubeYAML(cf, clusterPatches, clusterJSONPatches)
+	patchedConfig, err := applyPatches(cf, api, clusterPatches, clusterJSONPatches)
 	if err != nil {
 		return "", err
 	}
 
 	// if needed, apply current node's patches
 	if len(configNode.KubeadmConfigPatches) > 0 || len(configNode.KubeadmConfigPatchesJSON6902) > 0 {
-		patchedConfig, err = patch.KubeYAML(patchedConfig, configNode.KubeadmConfigPatches, configNode.KubeadmConfigPatchesJSON6902)
+		nodeJSONPatches, err := jsonPatchesForNode(configNode.KubeadmConfigPatchesJSON6902, nodeName, configNode)
+		if err != nil {
+			return "", err
+		}
+		patchedConfig, err = applyPatches(patchedConfig, api, configNode.KubeadmConfigPatches, nodeJSONPatches)
 		if err != nil {
 			return "", err
 		}
 	}
 
+	// programmatic mutators get the final say
+	patchedConfig, err = patch.KubeYAMLMutate(patchedConfig, mutators)
+	if err != nil {
+		return "", err
+	}
+
 	// fix all the patches to have name metadata matching the generated config
 	return removeMetadata(patchedConfig), nil
 }
 
+// reportConfigWarnings warns about feature gates, runtime config entries and
+// kubeadm config patches that are unknown, locked, removed or ignored in the
+// node's Kubernetes version
+func reportConfigWarnings(ctx *actions.ActionContext, node nodes.Node) error {
+	kubeVersion, err := nodeutils.KubeVersion(node)
+	if err != nil {
+		// this will be reported when generating the kubeadm config
+		return nil
+	}
+	// every component shares the same feature gates, kubelet is the one
+	// that is present on every node
+	help, err := exec.Output(node.Command("kubelet", "--help"))
+	if err != nil {
+		ctx.Logger.V(1).Infof("Unable to list known feature gates: %v", err)
+	}
+	known := kubeadm.KnownFeatureGates(string(help))
+	cfg := ctx.Config
+	scopes := []struct {
+		name  string
+		gates map[string]bool
+	}{
+		{"featureGates", cfg.FeatureGates},
+		{"componentFeatureGates.apiServer", cfg.ComponentFeatureGates.APIServer},
+		{"componentFeatureGates.controllerManager", cfg.ComponentFeatureGates.ControllerManager},
+		{"componentFeatureGates.scheduler", cfg.ComponentFeatureGates.Scheduler},
+		{"componentFeatureGates.kubelet", cfg.ComponentFeatureGates.Kubelet},
+		{"componentFeatureGates.kubeProxy", cfg.ComponentFeatureGates.KubeProxy},
+	}
+	messages := []string{}
+	for _, scope := range scopes {
+		for _, warning := range kubeadm.FeatureGateWarnings(kubeVersion, known, scope.gates) {
+			messages = append(messages, fmt.Sprintf("%s: %s", scope.name, warning))
+		}
+	}
+	for _, warning := range kubeadm.RuntimeConfigWarnings(kubeVersion, cfg.RuntimeConfig) {
+		messages = append(messages, fmt.Sprintf("runtimeConfig: %s", warning))
+	}
+	messages = append(messages, kubeadm.PatchAPIVersionWarnings(kubeVersion, "kubeadmConfigPatches", cfg.KubeadmConfigPatches)...)
+	for i, n := range cfg.Nodes {
+		field := fmt.Sprintf("nodes[%d].kubeadmConfigPatches", i)
+		messages = append(messages, kubeadm.PatchAPIVersionWarnings(kubeVersion, field, n.KubeadmConfigPatches)...)
+	}
+	ws := make([]warnings.Warning, len(messages))
+	for i, m := range messages {
+		ws[i] = warnings.Warning{Source: "config", Message: m}
+	}
+	return warnings.Report(ctx.Context, ctx.Logger, ws...)
+}
+
+// nvidiaContainerdConfigPatch makes the NVIDIA container runtime the default
+// so that pods on GPU nodes can be allocated devices by the device plugin
+const nvidiaContainerdConfigPatch = `[plugins."io.containerd.grpc.v1.cri".containerd]
+  default_runtime_name = "nvidia"
+[plugins."io.containerd.grpc.v1.cri".containerd.runtimes.nvidia]
+  runtime_type = "io.containerd.runc.v2"
+[plugins."io.containerd.grpc.v1.cri".containerd.runtimes.nvidia.options]
+  BinaryName = "/usr/bin/nvidia-container-runtime"
+`
+
+// maxLineSizeContainerdConfigPatch sets the CRI container log line limit
+const maxLineSizeContainerdConfigPatch = `[plugins."io.containerd.grpc.v1.cri"]
+  max_container_log_line_size = %d
+`
+
+// metricsContainerdConfigPatch serves containerd's Prometheus metrics on all
+// node addresses, so it can be scraped from pods
+const metricsContainerdConfigPatch = `[metrics]
+  address = "0.0.0.0:1338"
+`
+
+// registriesContainerdConfigPatch sets the directory of the CRI registry
+// host files
+const registriesContainerdConfigPatch = `[plugins."io.containerd.grpc.v1.cri".registry]
+  config_path = %q
+`
+
+// sandboxImageContainerdConfigPatch sets the CRI pod sandbox (pause) image
+const sandboxImageContainerdConfigPatch = `[plugins."io.containerd.grpc.v1.cri"]
+  sandbox_image = %q
+`
+
+// proxySnapshotter is a containerd snapshotter plugin shipped in the node
+// image and run as a systemd service alongside containerd
+type proxySnapshotter struct {
+	// binary is the snapshotter plugin binary, used to detect old node images
+	binary string
+	// service is the systemd unit running binary
+	service string
+	// patch points containerd at the plugin and selects it for CRI
+	patch string
+}
+
+// snapshotters are the non-default containerd snapshotters
+var snapshotters = map[config.ContainerdSnapshotter]proxySnapshotter{
+	config.FuseOverlayFSSnapshotter: {
+		binary:  "containerd-fuse-overlayfs-grpc",
+		service: "containerd-fuse-overlayfs.service",
+		patch: `[proxy_plugins]
+  [proxy_plugins."fuse-overlayfs"]
+    type = "snapshot"
+    address = "/run/containerd-fuse-overlayfs.sock"
+[plugins."io.containerd.grpc.v1.cri".containerd]
+  snapshotter = "fuse-overlayfs"
+`,
+	},
+	// lazy pulling needs the CRI to pass image references to the snapshotter
+	config.StargzSnapshotter: {
+		binary:  "containerd-stargz-grpc",
+		service: "stargz-snapshotter.service",
+		patch: `[proxy_plugins]
+  [proxy_plugins."stargz"]
+    type = "snapshot"
+    address = "/run/containerd-stargz-grpc/containerd-stargz-grpc.sock"
+[plugins."io.containerd.grpc.v1.cri".containerd]
+  snapshotter = "stargz"
+  disable_snapshot_annotations = false
+`,
+	},
+}
+
+// startSnapshotter enables the snapshotter service on node, starting it
+// if systemd is already running
+func startSnapshotter(node nodes.Node, name config.ContainerdSnapshotter, snapshotter proxySnapshotter) error {
+	if err := node.Command("bash", "-c", "command -v "+snapshotter.binary).Run(); err != nil {
+		return errors.Errorf("node image does not include %s, a newer node image is required for the %s snapshotter", snapshotter.binary, name)
+	}
+	enable := fmt.Sprintf(`systemctl enable %[1]s && { ! systemctl is-system-running || systemctl start %[1]s; }`, snapshotter.service)
+	if err := node.Command("bash", "-c", enable).Run(); err != nil {
+		return errors.Wrapf(err, "failed to start the %s snapshotter", name)
+	}
+	return nil
+}
+
 // trims out the metadata.name we put in the config for kustomize matching,
 // kubeadm will complain about this otherwise
 func removeMetadata(kustomized string) string {
@@ -240,10 +510,6 @@ func removeMetadata(kustomized string) string {
 	)
 }
 
-func allPatchesFromConfig(cfg *config.Cluster) (patches []string, jsonPatches []config.PatchJSON6902) {
-	return cfg.KubeadmConfigPatches, cfg.KubeadmConfigPatchesJSON6902
-}
-
 // writeKubeadmConfig writes the kubeadm configuration in the specified node
 func writeKubeadmConfig(kubeadmConfig string, node nodes.Node) error {
 	// copy the config to the node
@@ -0,0 +1,31 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/kubeadm"
+)
+
+// etcdConfigData sets the etcd tuning of cfg in data
+func etcdConfigData(cfg *config.Cluster, data *kubeadm.ConfigData) {
+	// these are checked by cfg.Validate()
+	data.EtcdQuotaBackendBytes, _ = config.EtcdQuotaBackendBytes(&cfg.Etcd)
+	data.EtcdHeartbeatInterval, _ = config.EtcdMilliseconds("etcd.heartbeatInterval", cfg.Etcd.HeartbeatInterval)
+	data.EtcdElectionTimeout, _ = config.EtcdMilliseconds("etcd.electionTimeout", cfg.Etcd.ElectionTimeout)
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"sort"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/kubeadm"
+	"sigs.k8s.io/kind/pkg/cluster/internal/patch"
+)
+
+// extraArgsPaths are the paths of the component flags in each kubeadm kind
+var extraArgsPaths = map[string][][]string{
+	"ClusterConfiguration": {
+		{"apiServer", "extraArgs"},
+		{"controllerManager", "extraArgs"},
+		{"scheduler", "extraArgs"},
+		{"etcd", "local", "extraArgs"},
+	},
+	"InitConfiguration": {{"nodeRegistration", "kubeletExtraArgs"}},
+	"JoinConfiguration": {{"nodeRegistration", "kubeletExtraArgs"}},
+}
+
+// applyPatches applies the merge and JSON 6902 patches to the kubeadm config.
+// Merge patches written for kubeadm APIs before v1beta4 set extraArgs as a
+// map, so for v1beta4 they are applied to the map form, where they merge
+// with the flags kind sets instead of replacing them. Patches setting the
+// v1beta4 list replace the flags, as they would without this conversion.
+func applyPatches(kubeadmConfig string, api kubeadm.APIVersion, patches []string, jsonPatches []config.PatchJSON6902) (string, error) {
+	if !api.ExtraArgsList || len(patches) == 0 {
+		return patch.KubeYAML(kubeadmConfig, patches, jsonPatches)
+	}
+	asMaps, err := patch.KubeYAMLMutate(kubeadmConfig, []patch.Mutator{extraArgsToMaps})
+	if err != nil {
+		return "", err
+	}
+	patched, err := patch.KubeYAML(asMaps, patches, nil)
+	if err != nil {
+		return "", err
+	}
+	asLists, err := patch.KubeYAMLMutate(patched, []patch.Mutator{extraArgsToLists})
+	if err != nil {
+		return "", err
+	}
+	return patch.KubeYAML(asLists, nil, jsonPatches)
+}
+
+// extraArgsToMaps converts v1beta4 extraArgs lists of name / value pairs to
+// maps of names to values, if a name is repeated the last value is kept
+func extraArgsToMaps(kind string, doc map[string]interface{}) error {
+	for _, path := range extraArgsPaths[kind] {
+		parent, key := lookupParent(doc, path)
+		list, ok := parent[key].([]interface{})
+		if !ok {
+			continue
+		}
+		args := make(map[string]interface{}, len(list))
+		for _, item := range list {
+			if arg, ok := item.(map[string]interface{}); ok {
+				if name, ok := arg["name"].(string); ok {
+					args[name] = arg["value"]
+				}
+			}
+		}
+		parent[key] = args
+	}
+	return nil
+}
+
+// extraArgsToLists converts extraArgs maps to v1beta4 lists, sorted by name
+func extraArgsToLists(kind string, doc map[string]interface{}) error {
+	for _, path := range extraArgsPaths[kind] {
+		parent, key := lookupParent(doc, path)
+		args, ok := parent[key].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		names := make([]string, 0, len(args))
+		for name := range args {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		list := make([]interface{}, 0, len(names))
+		for _, name := range names {
+			list = append(list, map[string]interface{}{"name": name, "value": args[name]})
+		}
+		parent[key] = list
+	}
+	return nil
+}
+
+// lookupParent returns the object containing the last element of path in
+// doc and that element, the object is nil if it does not exist
+func lookupParent(doc map[string]interface{}, path []string) (map[string]interface{}, string) {
+	parent := doc
+	for _, key := range path[:len(path)-1] {
+		next, ok := parent[key].(map[string]interface{})
+		if !ok {
+			return nil, ""
+		}
+		parent = next
+	}
+	return parent, path[len(path)-1]
+}
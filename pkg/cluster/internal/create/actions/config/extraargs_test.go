@@ -0,0 +1,98 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/kubeadm"
+)
+
+func TestApplyPatchesExtraArgs(t *testing.T) {
+	t.Parallel()
+	data := kubeadm.ConfigData{
+		KubernetesVersion: "v1.31.0",
+		KubeProxyMode:     "iptables",
+		FeatureGates:      map[string]bool{"Foo": true},
+	}
+	generated, err := kubeadm.Config(data)
+	if err != nil {
+		t.Fatalf("failed to generate config: %v", err)
+	}
+	api, err := kubeadm.APIVersionFor(data.KubernetesVersion)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cases := []struct {
+		Name        string
+		Patch       string
+		Contains    []string
+		NotContains []string
+	}{
+		{
+			Name: "map patch merges with the generated flags",
+			Patch: `kind: ClusterConfiguration
+apiServer:
+  extraArgs:
+    v: "4"
+`,
+			Contains: []string{
+				"  extraArgs:\n  - name: feature-gates\n    value: Foo=true\n  - name: v\n    value: \"4\"\n",
+			},
+		},
+		{
+			Name: "list patch replaces the generated flags",
+			Patch: `kind: ClusterConfiguration
+apiServer:
+  extraArgs:
+  - name: v
+    value: "4"
+`,
+			Contains: []string{
+				"  extraArgs:\n  - name: v\n    value: \"4\"\n",
+			},
+			NotContains: []string{
+				"  extraArgs:\n  - name: feature-gates\n    value: Foo=true\n  - name: v\n",
+			},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			patched, err := applyPatches(generated, api, []string{tc.Patch}, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, expected := range tc.Contains {
+				if !strings.Contains(patched, expected) {
+					t.Errorf("expected patched config to contain %q, got:\n%s", expected, patched)
+				}
+			}
+			for _, unexpected := range tc.NotContains {
+				if strings.Contains(patched, unexpected) {
+					t.Errorf("expected patched config not to contain %q, got:\n%s", unexpected, patched)
+				}
+			}
+			// the kubelet flags are converted back to the list form too
+			if !strings.Contains(patched, "  kubeletExtraArgs:\n  - name: fail-swap-on\n") {
+				t.Errorf("expected kubeletExtraArgs as a list, got:\n%s", patched)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// clusterConfigurationPaths are the paths of the control plane components
+// within the kubeadm ClusterConfiguration
+var clusterConfigurationPaths = map[config.PatchComponent][]string{
+	config.APIServerComponent:         {"apiServer"},
+	config.ControllerManagerComponent: {"controllerManager"},
+	config.SchedulerComponent:         {"scheduler"},
+	config.EtcdComponent:              {"etcd", "local"},
+}
+
+// componentKinds are the kubeadm kinds configuring the node components
+var componentKinds = map[config.PatchComponent]string{
+	config.KubeletComponent:   "KubeletConfiguration",
+	config.KubeProxyComponent: "KubeProxyConfiguration",
+}
+
+// patchesForNode returns the cluster level merge and JSON 6902 patches
+// targeting the node, with component targets converted to plain patches
+func patchesForNode(cfg *config.Cluster, nodeName string, node *config.Node) ([]string, []config.PatchJSON6902, error) {
+	patches := append([]string{}, cfg.KubeadmConfigPatches...)
+	for _, p := range cfg.TargetedKubeadmConfigPatches {
+		if !targetsNode(p.Target, nodeName, node) {
+			continue
+		}
+		converted, err := componentMergePatch(p.Target.Component, p.Patch)
+		if err != nil {
+			return nil, nil, err
+		}
+		patches = append(patches, converted)
+	}
+	jsonPatches, err := jsonPatchesForNode(cfg.KubeadmConfigPatchesJSON6902, nodeName, node)
+	if err != nil {
+		return nil, nil, err
+	}
+	return patches, jsonPatches, nil
+}
+
+// jsonPatchesForNode returns the JSON 6902 patches targeting the node,
+// with component targets converted to plain patches
+func jsonPatchesForNode(jsonPatches []config.PatchJSON6902, nodeName string, node *config.Node) ([]config.PatchJSON6902, error) {
+	out := []config.PatchJSON6902{}
+	for _, p := range jsonPatches {
+		if !targetsNode(p.Target, nodeName, node) {
+			continue
+		}
+		converted, err := componentJSONPatch(p)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, converted)
+	}
+	return out, nil
+}
+
+// targetsNode returns true if the node is selected by target,
+// an empty role or node name selects every node
+func targetsNode(target config.PatchTarget, nodeName string, node *config.Node) bool {
+	if target.Role != "" && target.Role != node.Role {
+		return false
+	}
+	return target.NodeName == "" || target.NodeName == nodeName
+}
+
+// componentMergePatch converts a merge patch of the component's
+// configuration to a merge patch of the kubeadm config
+func componentMergePatch(component config.PatchComponent, patch string) (string, error) {
+	if component == "" {
+		return patch, nil
+	}
+	body := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(patch), &body); err != nil {
+		return "", errors.Wrapf(err, "failed to parse %s patch", component)
+	}
+	if kind, ok := componentKinds[component]; ok {
+		body["kind"] = kind
+	} else if path, ok := clusterConfigurationPaths[component]; ok {
+		for i := len(path) - 1; i >= 0; i-- {
+			body = map[string]interface{}{path[i]: body}
+		}
+		body["kind"] = "ClusterConfiguration"
+	} else {
+		return "", errors.Errorf("unknown patch component %q", component)
+	}
+	out, err := yaml.Marshal(body)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return string(out), nil
+}
+
+// componentJSONPatch converts a JSON 6902 patch of the component's
+// configuration to a JSON 6902 patch of the kubeadm config
+func componentJSONPatch(patch config.PatchJSON6902) (config.PatchJSON6902, error) {
+	component := patch.Target.Component
+	if component == "" {
+		return patch, nil
+	}
+	prefix := ""
+	if kind, ok := componentKinds[component]; ok {
+		patch.Kind = kind
+	} else if path, ok := clusterConfigurationPaths[component]; ok {
+		patch.Kind = "ClusterConfiguration"
+		prefix = "/" + strings.Join(path, "/")
+	} else {
+		return patch, errors.Errorf("unknown patch component %q", component)
+	}
+	patch.Group, patch.Version = "", ""
+	patch.Target = config.PatchTarget{}
+	if prefix == "" {
+		return patch, nil
+	}
+	ops := []map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(patch.Patch), &ops); err != nil {
+		return patch, errors.Wrapf(err, "failed to parse %s JSON 6902 patch", component)
+	}
+	for _, op := range ops {
+		for _, key := range []string{"path", "from"} {
+			if p, ok := op[key].(string); ok {
+				op[key] = prefix + p
+			}
+		}
+	}
+	out, err := yaml.Marshal(ops)
+	if err != nil {
+		return patch, errors.WithStack(err)
+	}
+	patch.Patch = string(out)
+	return patch, nil
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+func TestRenderKubeadmConfigTargetedPatches(t *testing.T) {
+	t.Parallel()
+	cfg := &config.Cluster{
+		Nodes: []config.Node{
+			{Role: config.ControlPlaneRole},
+			{Role: config.WorkerRole},
+			{Role: config.WorkerRole},
+		},
+		TargetedKubeadmConfigPatches: []config.TargetedPatch{
+			{
+				Target: config.PatchTarget{NodeName: "kind-worker2", Component: config.KubeletComponent},
+				Patch:  "maxPods: 50\n",
+			},
+			{
+				Target: config.PatchTarget{Role: config.ControlPlaneRole, Component: config.APIServerComponent},
+				Patch:  "extraArgs:\n  v: \"4\"\n",
+			},
+		},
+		KubeadmConfigPatchesJSON6902: []config.PatchJSON6902{
+			{
+				Target: config.PatchTarget{Role: config.WorkerRole, Component: config.KubeProxyComponent},
+				Patch:  "- op: add\n  path: /metricsBindAddress\n  value: 0.0.0.0:10249\n",
+			},
+			{
+				Target: config.PatchTarget{Role: config.ControlPlaneRole, Component: config.ControllerManagerComponent},
+				Patch:  "- op: add\n  path: /extraArgs/v\n  value: \"5\"\n",
+			},
+		},
+	}
+	config.SetDefaultsCluster(cfg)
+	cases := []struct {
+		Node        int
+		Name        string
+		Contains    []string
+		NotContains []string
+	}{
+		{
+			Node: 0,
+			Name: "kind-control-plane",
+			Contains: []string{
+				"apiServer:\n  certSANs:\n  - localhost\n  - 127.0.0.1\n  extraArgs:\n    v: \"4\"\n",
+				"controllerManager:\n  extraArgs:\n    enable-hostpath-provisioner: \"true\"\n    v: \"5\"\n",
+			},
+			NotContains: []string{"maxPods: 50", "metricsBindAddress"},
+		},
+		{
+			Node:        1,
+			Name:        "kind-worker",
+			Contains:    []string{"metricsBindAddress: 0.0.0.0:10249"},
+			NotContains: []string{"maxPods: 50", "v: \"4\"", "v: \"5\""},
+		},
+		{
+			Node:        2,
+			Name:        "kind-worker2",
+			Contains:    []string{"maxPods: 50", "metricsBindAddress: 0.0.0.0:10249"},
+			NotContains: []string{"v: \"4\"", "v: \"5\""},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			data := KubeadmConfigData(cfg, "kind-control-plane:6443")
+			data.KubernetesVersion = "v1.30.0"
+			data.NodeAddress = "NODE_ADDRESS"
+			data.ControlPlane = cfg.Nodes[tc.Node].Role == config.ControlPlaneRole
+			rendered, err := RenderKubeadmConfig(cfg, data, tc.Name, &cfg.Nodes[tc.Node], nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, expected := range tc.Contains {
+				if !strings.Contains(rendered, expected) {
+					t.Errorf("expected config to contain %q, got:\n%s", expected, rendered)
+				}
+			}
+			for _, unexpected := range tc.NotContains {
+				if strings.Contains(rendered, unexpected) {
+					t.Errorf("expected config not to contain %q, got:\n%s", unexpected, rendered)
+				}
+			}
+		})
+	}
+}
+
+func TestComponentJSONPatch(t *testing.T) {
+	t.Parallel()
+	converted, err := componentJSONPatch(config.PatchJSON6902{
+		Target: config.PatchTarget{Component: config.EtcdComponent},
+		Patch:  "- op: move\n  from: /extraArgs/a\n  path: /extraArgs/b\n",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if converted.Kind != "ClusterConfiguration" || converted.Target != (config.PatchTarget{}) {
+		t.Errorf("expected an untargeted ClusterConfiguration patch, got: %+v", converted)
+	}
+	expected := "- from: /etcd/local/extraArgs/a\n  op: move\n  path: /etcd/local/extraArgs/b\n"
+	if converted.Patch != expected {
+		t.Errorf("expected patch %q, got %q", expected, converted.Patch)
+	}
+}
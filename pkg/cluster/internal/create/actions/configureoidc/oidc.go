@@ -0,0 +1,99 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package configureoidc implements the action to set up OpenID Connect
+// authentication: the test issuer and the issuer CA on the control plane
+package configureoidc
+
+import (
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/oidc"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+)
+
+type action struct{}
+
+// NewAction returns a new action for configuring OpenID Connect
+func NewAction() actions.Action {
+	return &action{}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	ctx.Status.Start("Configuring OIDC authentication 🪪")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+
+	ca := ctx.Config.Authentication.OIDC.CA
+	if ctx.Config.Authentication.OIDC.TestIssuer {
+		issuers, err := nodeutils.SelectNodesByRole(allNodes, constants.OIDCIssuerNodeRoleValue)
+		if err != nil {
+			return err
+		}
+		if len(issuers) != 1 {
+			return errors.Errorf("expected one OIDC issuer node, found %d", len(issuers))
+		}
+		issuer := issuers[0]
+
+		certs, err := oidc.GenerateCertificates(oidc.IssuerName(ctx.Config.Name))
+		if err != nil {
+			return err
+		}
+		config, err := oidc.Config(&oidc.ConfigData{
+			IssuerURL: oidc.IssuerURL(ctx.Config.Name),
+			ClientID:  ctx.Config.Authentication.OIDC.ClientID,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to generate OIDC issuer config")
+		}
+		// the issuer starts once the config is written, so it goes last
+		files := []struct{ path, content string }{
+			{oidc.CertPath, string(certs.Cert)},
+			{oidc.KeyPath, string(certs.Key)},
+			{oidc.ConfigPath, config},
+		}
+		for _, f := range files {
+			if err := nodeutils.WriteFile(issuer, f.path, f.content); err != nil {
+				return errors.Wrap(err, "failed to copy OIDC issuer config to node")
+			}
+		}
+		ca = string(certs.CA)
+	}
+
+	// the API server verifies the issuer with the CA, if any
+	if ca != "" {
+		controlPlanes, err := nodeutils.ControlPlaneNodes(allNodes)
+		if err != nil {
+			return err
+		}
+		for _, node := range controlPlanes {
+			if err := nodeutils.WriteFile(node, oidc.CAPath, ca); err != nil {
+				return errors.Wrapf(err, "failed to copy OIDC issuer CA to node %s", node)
+			}
+		}
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package configureregistries implements the action to write the containerd
+// registry host files for the configured registries
+package configureregistries
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// HostsDir is the containerd registry config_path, with a directory of host
+// files for each registry
+const HostsDir = "/etc/containerd/certs.d"
+
+// minContainerd is the first containerd version reading the registry host
+// files from the CRI config_path
+var minContainerd = version.MustParseSemantic("v1.5.0")
+
+// CheckContainerd returns an error if the containerd of node does not
+// support the registry host files
+func CheckContainerd(node nodes.Node) error {
+	v, err := nodeutils.ContainerdVersion(node)
+	if err != nil {
+		return err
+	}
+	return errors.Wrapf(checkVersion(v), "node %s does not support registries", node.String())
+}
+
+// checkVersion returns an error if containerd version v is too old for the
+// registry host files
+func checkVersion(v string) error {
+	parsed, err := version.ParseGeneric(v)
+	if err != nil {
+		return errors.Errorf("failed to parse the containerd version %q", v)
+	}
+	if parsed.LessThan(minContainerd) {
+		return errors.Errorf("containerd %s is older than the required %s, use a newer node image", v, minContainerd)
+	}
+	return nil
+}
+
+// Resolve replaces the registries ca file paths of cfg with the file
+// contents, so that creating the cluster fails before any nodes are created
+// if a file is missing or not a certificate
+func Resolve(cfg *config.Cluster) error {
+	// the slice may be shared with the caller's config
+	registries := make([]config.Registry, len(cfg.Registries))
+	for i, r := range cfg.Registries {
+		registries[i] = r
+		if r.CA == "" || config.IsInlinePEM(r.CA) {
+			continue
+		}
+		contents, err := ioutil.ReadFile(r.CA)
+		if err != nil {
+			return errors.Wrapf(err, "invalid registries: failed to read ca of %q", r.Host)
+		}
+		if !config.IsCertificatePEM(string(contents)) {
+			return errors.Errorf("invalid registries: %s does not contain PEM encoded certificates", r.CA)
+		}
+		registries[i].CA = string(contents)
+	}
+	cfg.Registries = registries
+	return nil
+}
+
+type action struct{}
+
+// NewAction returns a new action for writing the registry host files
+func NewAction() actions.Action {
+	return &action{}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	ctx.Status.Start("Configuring registries 🗄")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	internalNodes, err := nodeutils.InternalNodes(allNodes)
+	if err != nil {
+		return err
+	}
+
+	files := hostFiles(ctx.Config.Registries)
+	fns := []func() error{}
+	for _, node := range internalNodes {
+		node := node // capture loop variable
+		fns = append(fns, func() error {
+			return install(node, files)
+		})
+	}
+	if err := errors.UntilErrorConcurrent(fns); err != nil {
+		return err
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// install writes files to node, containerd reads the host files on every
+// pull so it does not need to be restarted
+func install(node nodes.Node, files map[string]string) error {
+	for name, contents := range files {
+		if err := nodeutils.WriteFile(node, name, contents); err != nil {
+			return errors.Wrapf(err, "failed to write registry config to node %s", node.String())
+		}
+	}
+	return nil
+}
+
+// hostFiles returns the hosts.toml and ca.crt files for registries, by path
+func hostFiles(registries []config.Registry) map[string]string {
+	files := map[string]string{}
+	for _, r := range registries {
+		files[path.Join(HostsDir, r.Host, "hosts.toml")] = hostsTOML(r)
+		if r.CA != "" {
+			files[CAPath(r.Host)] = r.CA
+		}
+	}
+	return files
+}
+
+// Server returns the URL containerd pulls the images of r from
+func Server(r config.Registry) string {
+	scheme := "https"
+	if r.PlainHTTP {
+		scheme = "http"
+	}
+	host := r.Host
+	// docker.io images are served by a different host
+	if host == "docker.io" {
+		host = "registry-1.docker.io"
+	}
+	return scheme + "://" + host
+}
+
+// CAPath returns the path of the CA certificate of the registry host on the
+// nodes, if it has a CA
+func CAPath(host string) string {
+	return path.Join(HostsDir, host, "ca.crt")
+}
+
+// hostsTOML returns the containerd host file for r, see
+// https://github.com/containerd/containerd/blob/main/docs/hosts.md
+func hostsTOML(r config.Registry) string {
+	server := Server(r)
+	var b strings.Builder
+	fmt.Fprintf(&b, "server = %q\n\n", server)
+	fmt.Fprintf(&b, "[host.%q]\n", server)
+	b.WriteString("  capabilities = [\"pull\", \"resolve\", \"push\"]\n")
+	if r.InsecureSkipVerify {
+		b.WriteString("  skip_verify = true\n")
+	}
+	if r.CA != "" {
+		fmt.Fprintf(&b, "  ca = %q\n", CAPath(r.Host))
+	}
+	return b.String()
+}
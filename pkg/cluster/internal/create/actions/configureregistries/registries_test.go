@@ -0,0 +1,72 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configureregistries
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestHostFiles(t *testing.T) {
+	t.Parallel()
+	const ca = "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"
+	assert.DeepEqual(t,
+		map[string]string{
+			"/etc/containerd/certs.d/registry.local:5000/hosts.toml": "server = \"http://registry.local:5000\"\n\n" +
+				"[host.\"http://registry.local:5000\"]\n" +
+				"  capabilities = [\"pull\", \"resolve\", \"push\"]\n",
+			"/etc/containerd/certs.d/harbor.corp/hosts.toml": "server = \"https://harbor.corp\"\n\n" +
+				"[host.\"https://harbor.corp\"]\n" +
+				"  capabilities = [\"pull\", \"resolve\", \"push\"]\n" +
+				"  ca = \"/etc/containerd/certs.d/harbor.corp/ca.crt\"\n",
+			"/etc/containerd/certs.d/harbor.corp/ca.crt": ca,
+			"/etc/containerd/certs.d/docker.io/hosts.toml": "server = \"https://registry-1.docker.io\"\n\n" +
+				"[host.\"https://registry-1.docker.io\"]\n" +
+				"  capabilities = [\"pull\", \"resolve\", \"push\"]\n" +
+				"  skip_verify = true\n",
+		},
+		hostFiles([]config.Registry{
+			{Host: "registry.local:5000", PlainHTTP: true},
+			{Host: "harbor.corp", CA: ca},
+			{Host: "docker.io", InsecureSkipVerify: true},
+		}),
+	)
+}
+
+func TestCheckVersion(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Version     string
+		ExpectError bool
+	}{
+		{Version: "v1.4.0-rc.1-4-g43366250", ExpectError: true},
+		{Version: "v1.4.3", ExpectError: true},
+		{Version: "v1.5.0"},
+		{Version: "v1.7.13"},
+		{Version: "v2.0.0"},
+		{Version: "unknown", ExpectError: true},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Version, func(t *testing.T) {
+			t.Parallel()
+			assert.ExpectError(t, tc.ExpectError, checkVersion(tc.Version))
+		})
+	}
+}
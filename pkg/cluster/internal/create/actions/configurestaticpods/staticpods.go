@@ -0,0 +1,164 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package configurestaticpods implements the action to link the static pod
+// manifests of each node's staticPodsHostPath into the kubelet static pod path
+package configurestaticpods
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// manifestsDir is the kubelet static pod path, as configured by kubeadm
+const manifestsDir = "/etc/kubernetes/manifests"
+
+// linkPrefix prefixes the links to the passthrough manifests, so they can be
+// told apart from the manifests kubeadm writes
+const linkPrefix = "kind-static-"
+
+// syncScriptPath is the script linking the passthrough manifests
+const syncScriptPath = "/kind/sync-static-pods.sh"
+
+// unitName is the name of the systemd service running the sync script, and
+// of the path unit watching the passthrough directory
+const unitName = "kind-static-pods"
+
+type action struct{}
+
+// NewAction returns a new action for linking the manifests of the nodes'
+// staticPodsHostPath into the kubelet static pod path
+//
+// kubelet follows symlinks and rereads the static pod path periodically, so
+// changes to the linked manifests are picked up. A systemd path unit relinks
+// the directory when manifests are added or removed, and at boot.
+func NewAction() actions.Action {
+	return &action{}
+}
+
+// Needed returns true if any node in cfg has a staticPodsHostPath
+func Needed(cfg *config.Cluster) bool {
+	for _, n := range cfg.Nodes {
+		if n.StaticPodsHostPath != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	ctx.Status.Start("Linking static pods 📜")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+
+	fns := []func() error{}
+	for _, node := range allNodes {
+		node := node // capture loop variable
+		configNode, err := actions.ConfigNode(ctx.Config, node)
+		if err != nil {
+			// nodes not from the config, e.g. the load balancer
+			continue
+		}
+		if configNode.StaticPodsHostPath == "" {
+			continue
+		}
+		fns = append(fns, func() error {
+			return configure(node)
+		})
+	}
+	if err := errors.UntilErrorConcurrent(fns); err != nil {
+		return err
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// configure installs and starts the units linking the passthrough manifests
+// on node
+func configure(node nodes.Node) error {
+	files := map[string]string{
+		syncScriptPath: syncScript(common.StaticPodsContainerPath, manifestsDir),
+		"/etc/systemd/system/" + unitName + ".service": serviceUnit,
+		"/etc/systemd/system/" + unitName + ".path":    pathUnit(common.StaticPodsContainerPath),
+	}
+	for dest, content := range files {
+		if err := nodeutils.WriteFile(node, dest, content); err != nil {
+			return errors.Wrapf(err, "failed to write %s on node %s", dest, node.String())
+		}
+	}
+	for _, args := range [][]string{
+		{"daemon-reload"},
+		{"enable", unitName + ".service", unitName + ".path"},
+		{"start", unitName + ".path"},
+		{"start", unitName + ".service"},
+	} {
+		if err := node.Command("systemctl", args...).Run(); err != nil {
+			return errors.Wrapf(err, "failed to link static pods on node %s", node.String())
+		}
+	}
+	return nil
+}
+
+// syncScript returns a script linking the manifests in dir into
+// staticPodPath, and removing the links to manifests no longer in dir
+func syncScript(dir, staticPodPath string) string {
+	return fmt.Sprintf(`#!/bin/sh
+mkdir -p %[2]s
+for link in %[2]s/%[3]s*; do
+  if [ -L "$link" ] && [ ! -e "$link" ]; then rm -f "$link"; fi
+done
+for manifest in %[1]s/*.yaml %[1]s/*.yml %[1]s/*.json; do
+  if [ -f "$manifest" ]; then ln -sfn "$manifest" "%[2]s/%[3]s$(basename "$manifest")"; fi
+done
+`, dir, staticPodPath, linkPrefix)
+}
+
+const serviceUnit = `[Unit]
+Description=Link the static pod manifests passed through by kind into the kubelet static pod path
+
+[Service]
+Type=oneshot
+ExecStart=/bin/sh ` + syncScriptPath + `
+
+[Install]
+WantedBy=multi-user.target
+`
+
+func pathUnit(dir string) string {
+	return fmt.Sprintf(`[Unit]
+Description=Watch the static pod manifests passed through by kind
+
+[Path]
+PathChanged=%s
+
+[Install]
+WantedBy=multi-user.target
+`, dir)
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configurestaticpods
+
+import (
+	"io/ioutil"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestNeeded(t *testing.T) {
+	t.Parallel()
+	assert.BoolEqual(t, false, Needed(&config.Cluster{Nodes: []config.Node{{}}}))
+	assert.BoolEqual(t, true, Needed(&config.Cluster{Nodes: []config.Node{{}, {StaticPodsHostPath: "./pods"}}}))
+}
+
+func TestSyncScript(t *testing.T) {
+	t.Parallel()
+	if _, err := osexec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+	dir, err := ioutil.TempDir("", "kind-static-pods-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	src := filepath.Join(dir, "src")
+	dst := filepath.Join(dir, "manifests")
+	for _, d := range []string{src, dst} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range []string{"src/app.yaml", "src/other.json", "src/README.md", "manifests/kube-apiserver.yaml"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, f), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a link to a manifest that was removed since
+	if err := os.Symlink(filepath.Join(src, "removed.yaml"), filepath.Join(dst, linkPrefix+"removed.yaml")); err != nil {
+		t.Fatal(err)
+	}
+
+	if out, err := osexec.Command("sh", "-c", syncScript(src, dst)).CombinedOutput(); err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, out)
+	}
+	entries, err := ioutil.ReadDir(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	assert.DeepEqual(t, []string{"kind-static-app.yaml", "kind-static-other.json", "kube-apiserver.yaml"}, names)
+	target, err := os.Readlink(filepath.Join(dst, "kind-static-app.yaml"))
+	assert.ExpectError(t, false, err)
+	assert.StringEqual(t, filepath.Join(src, "app.yaml"), target)
+}
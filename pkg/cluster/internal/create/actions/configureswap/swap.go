@@ -0,0 +1,106 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package configureswap implements the action to enable swap on nodes
+package configureswap
+
+import (
+	"bytes"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/swap"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+type action struct{}
+
+// NewAction returns a new action for enabling swap on the nodes requesting it
+//
+// This runs after the nodes have joined, the kubelet config of joining nodes
+// is downloaded from the cluster by kubeadm so it can only be changed per
+// node once it is written.
+func NewAction() actions.Action {
+	return &action{}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	ctx.Status.Start("Enabling swap 🔄")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+
+	fns := []func() error{}
+	for _, node := range allNodes {
+		node := node // capture loop variable
+		configNode, err := actions.ConfigNode(ctx.Config, node)
+		if err != nil {
+			// nodes not from the config, e.g. the load balancer
+			continue
+		}
+		if configNode.Swap.Size == "" {
+			continue
+		}
+		size, err := config.SwapBytes(configNode.Swap.Size)
+		if err != nil {
+			return err
+		}
+		behavior := configNode.Swap.Behavior
+		fns = append(fns, func() error {
+			return enable(node, size, behavior)
+		})
+	}
+	if err := errors.UntilErrorConcurrent(fns); err != nil {
+		return err
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// enable adds the swapfile to node and restarts the kubelet with swapBehavior
+func enable(node nodes.Node, size int64, behavior config.SwapBehavior) error {
+	kubeVersion, err := nodeutils.KubeVersion(node)
+	if err != nil {
+		return errors.Wrap(err, "failed to get kubernetes version from node")
+	}
+	var buff bytes.Buffer
+	if err := node.Command("cat", swap.KubeletConfigPath).SetStdout(&buff).Run(); err != nil {
+		return errors.Wrapf(err, "failed to read the kubelet config of node %s", node.String())
+	}
+	kubeletConfig, err := swap.KubeletConfig(buff.Bytes(), behavior, kubeVersion)
+	if err != nil {
+		return err
+	}
+	if err := node.Command("sh", "-c", swap.EnableCommand(size)).Run(); err != nil {
+		return errors.Wrapf(err, "failed to enable swap on node %s, this requires a rootful container runtime", node.String())
+	}
+	if err := nodeutils.WriteFile(node, swap.KubeletConfigPath, string(kubeletConfig)); err != nil {
+		return errors.Wrap(err, "failed to write the kubelet config")
+	}
+	if err := node.Command("systemctl", "restart", "kubelet").Run(); err != nil {
+		return errors.Wrap(err, "failed to restart the kubelet after enabling swap")
+	}
+	return nil
+}
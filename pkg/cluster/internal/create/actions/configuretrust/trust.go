@@ -0,0 +1,135 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package configuretrust implements the action to install extra CA
+// certificates in the node trust stores
+package configuretrust
+
+import (
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"path"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// CADir is where the extra CA certificates are installed on the nodes, for
+// update-ca-certificates to add them to the system trust store
+const CADir = "/usr/local/share/ca-certificates/kind"
+
+// Resolve replaces the trust.extraCAs file paths of cfg with the file
+// contents, so that creating the cluster fails before any nodes are created
+// if a file is missing or not a certificate
+func Resolve(cfg *config.Cluster) error {
+	// the slice may be shared with the caller's config
+	cas := make([]string, len(cfg.Trust.ExtraCAs))
+	for i, ca := range cfg.Trust.ExtraCAs {
+		if config.IsInlinePEM(ca) {
+			cas[i] = ca
+			continue
+		}
+		contents, err := ioutil.ReadFile(ca)
+		if err != nil {
+			return errors.Wrap(err, "invalid trust.extraCAs")
+		}
+		if !config.IsCertificatePEM(string(contents)) {
+			return errors.Errorf("invalid trust.extraCAs: %s does not contain PEM encoded certificates", ca)
+		}
+		cas[i] = string(contents)
+	}
+	cfg.Trust.ExtraCAs = cas
+	return nil
+}
+
+type action struct{}
+
+// NewAction returns a new action for installing the extra CA certificates
+func NewAction() actions.Action {
+	return &action{}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	ctx.Status.Start("Installing CA certificates 🔏")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	internalNodes, err := nodeutils.InternalNodes(allNodes)
+	if err != nil {
+		return err
+	}
+
+	files := certificateFiles(ctx.Config.Trust.ExtraCAs)
+	fns := []func() error{}
+	for _, node := range internalNodes {
+		node := node // capture loop variable
+		fns = append(fns, func() error {
+			return install(node, files)
+		})
+	}
+	if err := errors.UntilErrorConcurrent(fns); err != nil {
+		return err
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// install adds files to the trust store of node, and restarts containerd
+// to use the updated trust store for pulling images
+func install(node nodes.Node, files map[string]string) error {
+	for name, contents := range files {
+		if err := nodeutils.WriteFile(node, path.Join(CADir, name), contents); err != nil {
+			return errors.Wrapf(err, "failed to copy CA certificate to node %s", node.String())
+		}
+	}
+	if err := node.Command("update-ca-certificates").Run(); err != nil {
+		return errors.Wrapf(err, "failed to update the trust store on node %s", node.String())
+	}
+	// skip if the systemd (also the containerd) is not running
+	if err := node.Command("bash", "-c", `! systemctl is-system-running || systemctl restart containerd`).Run(); err != nil {
+		return errors.Wrap(err, "failed to restart containerd after updating the trust store")
+	}
+	return nil
+}
+
+// certificateFiles splits the PEM bundles cas into one file per certificate,
+// update-ca-certificates only links the first certificate of each file
+func certificateFiles(cas []string) map[string]string {
+	files := map[string]string{}
+	for i, ca := range cas {
+		rest := []byte(ca)
+		for j := 0; ; j++ {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				break
+			}
+			files[fmt.Sprintf("extra-ca-%d-%d.crt", i, j)] = string(pem.EncodeToMemory(block))
+		}
+	}
+	return files
+}
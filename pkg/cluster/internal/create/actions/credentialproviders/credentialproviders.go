@@ -0,0 +1,175 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package credentialproviders implements the action to configure the kubelet
+// image credential providers, and the mounts of the provider binaries
+package credentialproviders
+
+import (
+	"os"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// BinDir is where the credentialProviders.binDir is mounted on the nodes,
+// for the kubelet --image-credential-provider-bin-dir
+const BinDir = "/etc/kubernetes/kind/credential-providers/bin"
+
+// ConfigPath is the kubelet --image-credential-provider-config on the nodes
+const ConfigPath = "/etc/kubernetes/kind/credential-providers/config.yaml"
+
+// minVersion is the first Kubernetes version with the credential providers
+// enabled by default
+var minVersion = version.MustParseSemantic("v1.24.0")
+
+// v1Version is the first Kubernetes version with the v1 credential provider APIs
+var v1Version = version.MustParseSemantic("v1.26.0")
+
+// Mounts returns the mounts every node needs for the credential provider
+// binaries of cfg, the directory must exist
+func Mounts(cfg *config.Cluster) ([]config.Mount, error) {
+	if len(cfg.CredentialProviders.Providers) == 0 {
+		return nil, nil
+	}
+	dir := cfg.CredentialProviders.BinDir
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid credentialProviders.binDir")
+	}
+	if !info.IsDir() {
+		return nil, errors.Errorf("invalid credentialProviders.binDir: %s is not a directory", dir)
+	}
+	return []config.Mount{{
+		HostPath:      dir,
+		ContainerPath: BinDir,
+		Readonly:      true,
+	}}, nil
+}
+
+type action struct{}
+
+// NewAction returns a new action for configuring the credential providers
+//
+// This runs before kubeadm, the kubelet reads the config when it starts.
+func NewAction() actions.Action {
+	return &action{}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	ctx.Status.Start("Configuring credential providers 🔑")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	internalNodes, err := nodeutils.InternalNodes(allNodes)
+	if err != nil {
+		return err
+	}
+
+	for _, node := range internalNodes {
+		kubeVersion, err := nodeutils.KubeVersion(node)
+		if err != nil {
+			return errors.Wrap(err, "failed to get kubernetes version from node")
+		}
+		providerConfig, err := Config(ctx.Config.CredentialProviders.Providers, kubeVersion)
+		if err != nil {
+			return err
+		}
+		if err := nodeutils.WriteFile(node, ConfigPath, providerConfig); err != nil {
+			return errors.Wrapf(err, "failed to copy credential provider config to node %s", node.String())
+		}
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+type providerConfig struct {
+	APIVersion string     `json:"apiVersion"`
+	Kind       string     `json:"kind"`
+	Providers  []provider `json:"providers"`
+}
+
+type provider struct {
+	Name                 string   `json:"name"`
+	MatchImages          []string `json:"matchImages"`
+	DefaultCacheDuration string   `json:"defaultCacheDuration"`
+	APIVersion           string   `json:"apiVersion"`
+	Args                 []string `json:"args,omitempty"`
+	Env                  []envVar `json:"env,omitempty"`
+}
+
+type envVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Config returns the kubelet CredentialProviderConfig for providers, in the
+// API version kubeVersion supports
+func Config(providers []config.CredentialProvider, kubeVersion string) (string, error) {
+	v, err := version.ParseGeneric(kubeVersion)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse kubernetes version %q", kubeVersion)
+	}
+	if v.LessThan(minVersion) {
+		return "", errors.Errorf("credentialProviders require Kubernetes %s or later, the node is %s", minVersion, kubeVersion)
+	}
+	apiVersion := "v1beta1"
+	if !v.LessThan(v1Version) {
+		apiVersion = "v1"
+	}
+	out := providerConfig{
+		APIVersion: "kubelet.config.k8s.io/" + apiVersion,
+		Kind:       "CredentialProviderConfig",
+	}
+	for _, p := range providers {
+		cacheDuration := p.DefaultCacheDuration
+		// the kubelet requires a duration, 0 disables caching
+		if cacheDuration == "" {
+			cacheDuration = "0s"
+		}
+		env := make([]envVar, 0, len(p.Env))
+		for name, value := range p.Env {
+			env = append(env, envVar{Name: name, Value: value})
+		}
+		sort.Slice(env, func(i, j int) bool { return env[i].Name < env[j].Name })
+		out.Providers = append(out.Providers, provider{
+			Name:                 p.Name,
+			MatchImages:          p.MatchImages,
+			DefaultCacheDuration: cacheDuration,
+			APIVersion:           "credentialprovider.kubelet.k8s.io/" + apiVersion,
+			Args:                 p.Args,
+			Env:                  env,
+		})
+	}
+	b, err := yaml.Marshal(out)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to encode credential provider config")
+	}
+	return string(b), nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentialproviders
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestConfig(t *testing.T) {
+	t.Parallel()
+	providers := []config.CredentialProvider{
+		{
+			Name:                 "ecr-credential-provider",
+			MatchImages:          []string{"*.dkr.ecr.*.amazonaws.com"},
+			DefaultCacheDuration: "12h",
+			Args:                 []string{"get-credentials"},
+			Env:                  map[string]string{"AWS_REGION": "us-east-1", "AWS_PROFILE": "default"},
+		},
+		{
+			Name:        "test-provider",
+			MatchImages: []string{"registry.example.com"},
+		},
+	}
+	cases := []struct {
+		Name        string
+		KubeVersion string
+		Expected    string
+		ExpectError bool
+	}{
+		{
+			Name:        "v1",
+			KubeVersion: "v1.31.0",
+			Expected: `apiVersion: kubelet.config.k8s.io/v1
+kind: CredentialProviderConfig
+providers:
+- apiVersion: credentialprovider.kubelet.k8s.io/v1
+  args:
+  - get-credentials
+  defaultCacheDuration: 12h
+  env:
+  - name: AWS_PROFILE
+    value: default
+  - name: AWS_REGION
+    value: us-east-1
+  matchImages:
+  - '*.dkr.ecr.*.amazonaws.com'
+  name: ecr-credential-provider
+- apiVersion: credentialprovider.kubelet.k8s.io/v1
+  defaultCacheDuration: 0s
+  matchImages:
+  - registry.example.com
+  name: test-provider
+`,
+		},
+		{
+			Name:        "v1beta1",
+			KubeVersion: "v1.25.16",
+			Expected: `apiVersion: kubelet.config.k8s.io/v1beta1
+kind: CredentialProviderConfig
+providers:
+- apiVersion: credentialprovider.kubelet.k8s.io/v1beta1
+  args:
+  - get-credentials
+  defaultCacheDuration: 12h
+  env:
+  - name: AWS_PROFILE
+    value: default
+  - name: AWS_REGION
+    value: us-east-1
+  matchImages:
+  - '*.dkr.ecr.*.amazonaws.com'
+  name: ecr-credential-provider
+- apiVersion: credentialprovider.kubelet.k8s.io/v1beta1
+  defaultCacheDuration: 0s
+  matchImages:
+  - registry.example.com
+  name: test-provider
+`,
+		},
+		{
+			Name:        "too old",
+			KubeVersion: "v1.23.17",
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			out, err := Config(providers, tc.KubeVersion)
+			assert.ExpectError(t, tc.ExpectError, err)
+			assert.StringEqual(t, tc.Expected, out)
+		})
+	}
+}
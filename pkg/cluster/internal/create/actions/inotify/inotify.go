@@ -0,0 +1,92 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package inotify implements the action to raise the host inotify limits
+package inotify
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+type action struct{}
+
+// NewAction returns a new action for raising the host inotify limits
+//
+// These limits are not namespaced, so writing them from any privileged
+// node raises them for the whole host, they are only ever raised and
+// revert when the host reboots.
+func NewAction() actions.Action {
+	return &action{}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	ctx.Status.Start("Raising inotify limits 👀")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	node, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return err
+	}
+
+	for _, cmd := range commands(ctx.Config.Inotify) {
+		if err := node.Command("sh", "-c", cmd).Run(); err != nil {
+			return errors.Wrap(err, "failed to raise the host inotify limits, the container runtime may be rootless")
+		}
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// Needed returns true if the action has anything to raise for cfg
+func Needed(cfg *config.Cluster) bool {
+	return len(commands(cfg.Inotify)) > 0
+}
+
+// commands returns the shell commands raising each configured limit, if it
+// is currently lower
+func commands(inotify config.Inotify) []string {
+	limits := []struct {
+		name  string
+		value int32
+	}{
+		{"max_user_watches", inotify.MaxUserWatches},
+		{"max_user_instances", inotify.MaxUserInstances},
+	}
+	cmds := []string{}
+	for _, limit := range limits {
+		if limit.value <= 0 {
+			continue
+		}
+		cmds = append(cmds, fmt.Sprintf(
+			`[ "$(cat /proc/sys/fs/inotify/%[1]s)" -ge %[2]d ] || sysctl -w fs.inotify.%[1]s=%[2]d`,
+			limit.name, limit.value,
+		))
+	}
+	return cmds
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inotify
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestCommands(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name     string
+		Inotify  config.Inotify
+		Expected []string
+	}{
+		{
+			Name:     "unset",
+			Expected: []string{},
+		},
+		{
+			Name:    "watches only",
+			Inotify: config.Inotify{MaxUserWatches: 524288},
+			Expected: []string{
+				`[ "$(cat /proc/sys/fs/inotify/max_user_watches)" -ge 524288 ] || sysctl -w fs.inotify.max_user_watches=524288`,
+			},
+		},
+		{
+			Name:    "both",
+			Inotify: config.Inotify{MaxUserWatches: 524288, MaxUserInstances: 512},
+			Expected: []string{
+				`[ "$(cat /proc/sys/fs/inotify/max_user_watches)" -ge 524288 ] || sysctl -w fs.inotify.max_user_watches=524288`,
+				`[ "$(cat /proc/sys/fs/inotify/max_user_instances)" -ge 512 ] || sysctl -w fs.inotify.max_user_instances=512`,
+			},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			assert.DeepEqual(t, tc.Expected, commands(tc.Inotify))
+		})
+	}
+}
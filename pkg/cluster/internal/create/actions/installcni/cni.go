@@ -25,6 +25,7 @@ import (
 	"sigs.k8s.io/kind/pkg/errors"
 
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 )
 
@@ -82,6 +83,7 @@ func (a *action) Execute(ctx *actions.ActionContext) error {
 		}
 		manifest = out.String()
 	}
+	manifest = common.OverrideManifestImages(ctx.Config, manifest)
 
 	// install the manifest
 	if err := node.Command(
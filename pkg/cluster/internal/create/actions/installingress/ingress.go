@@ -0,0 +1,109 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package installingress implements the action to install an ingress
+// controller on the ingress ready node
+package installingress
+
+import (
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/internal/presets"
+)
+
+type action struct {
+	controller string
+}
+
+// NewAction returns a new action for installing the ingress controller,
+// one of presets.IngressNginx or presets.IngressContour
+func NewAction(controller string) actions.Action {
+	return &action{
+		controller: controller,
+	}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	manifest, ok := presets.IngressManifests[a.controller]
+	if !ok {
+		return errors.Errorf("unknown ingress controller %q", a.controller)
+	}
+
+	ctx.Status.Start("Installing " + a.controller + " ingress 🚪")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+
+	// get the target node for this task
+	controlPlanes, err := nodeutils.ControlPlaneNodes(allNodes)
+	if err != nil {
+		return err
+	}
+	node := controlPlanes[0] // kind expects at least one always
+
+	kubeVersion, err := nodeutils.KubeVersion(node)
+	if err != nil {
+		return errors.Wrap(err, "failed to get kubernetes version from node")
+	}
+	if err := checkVersion(a.controller, kubeVersion); err != nil {
+		return err
+	}
+
+	// apply the manifest
+	if err := node.Command(
+		"kubectl",
+		"--kubeconfig=/etc/kubernetes/admin.conf", "apply", "-f", manifest,
+	).Run(); err != nil {
+		return errors.Wrapf(err, "failed to apply %s ingress manifest", a.controller)
+	}
+
+	// contour needs to be scheduled on the node forwarding the ports
+	if a.controller == presets.IngressContour {
+		if err := node.Command(
+			"kubectl",
+			"--kubeconfig=/etc/kubernetes/admin.conf", "patch", "daemonsets",
+			"-n", "projectcontour", "envoy", "-p", presets.ContourEnvoyPatch,
+		).Run(); err != nil {
+			return errors.Wrap(err, "failed to patch contour envoy daemonset")
+		}
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// checkVersion returns an error if the manifest of controller does not
+// support Kubernetes kubeVersion
+func checkVersion(controller, kubeVersion string) error {
+	v, err := version.ParseGeneric(kubeVersion)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse kubernetes version %q", kubeVersion)
+	}
+	min := version.MustParseGeneric(presets.IngressMinKubernetes[controller])
+	if v.LessThan(min) {
+		return errors.Errorf("the %s ingress controller requires Kubernetes %s or later, the nodes run %s, use a newer node image", controller, min, kubeVersion)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package installingress
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+	"sigs.k8s.io/kind/pkg/internal/presets"
+)
+
+func TestCheckVersion(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name        string
+		Controller  string
+		Version     string
+		ExpectError bool
+	}{
+		{Name: "nginx default node image", Controller: presets.IngressNginx, Version: "v1.18.8", ExpectError: true},
+		{Name: "nginx", Controller: presets.IngressNginx, Version: "v1.25.3"},
+		{Name: "contour too old", Controller: presets.IngressContour, Version: "v1.25.3", ExpectError: true},
+		{Name: "contour", Controller: presets.IngressContour, Version: "v1.26.0"},
+		{Name: "invalid version", Controller: presets.IngressNginx, Version: "latest", ExpectError: true},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			assert.ExpectError(t, tc.ExpectError, checkVersion(tc.Controller, tc.Version))
+		})
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package installnodeproblemdetector implements the action to install the
+// node-problem-detector preset
+package installnodeproblemdetector
+
+import (
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/internal/presets"
+)
+
+type action struct{}
+
+// NewAction returns a new action for installing the node-problem-detector preset
+func NewAction() actions.Action {
+	return &action{}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	ctx.Status.Start("Installing node-problem-detector preset 🩺")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+
+	// the systemd monitor reads the persistent journal, which the node's
+	// fresh /var/log volume does not have yet
+	internalNodes, err := nodeutils.InternalNodes(allNodes)
+	if err != nil {
+		return err
+	}
+	fns := []func() error{}
+	for _, node := range internalNodes {
+		node := node // capture loop variable
+		fns = append(fns, func() error {
+			return persistJournal(node)
+		})
+	}
+	if err := errors.UntilErrorConcurrent(fns); err != nil {
+		return err
+	}
+
+	// get the target node for this task
+	controlPlanes, err := nodeutils.ControlPlaneNodes(allNodes)
+	if err != nil {
+		return err
+	}
+	node := controlPlanes[0] // kind expects at least one always
+
+	// apply the manifest
+	cmd := node.Command(
+		"kubectl",
+		"--kubeconfig=/etc/kubernetes/admin.conf", "apply", "-f", "-",
+	)
+	cmd.SetStdin(strings.NewReader(common.OverrideManifestImages(ctx.Config, presets.NodeProblemDetectorManifest)))
+	if err := cmd.Run(); err != nil {
+		return errors.Wrap(err, "failed to apply node-problem-detector preset manifest")
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// persistJournal has journald write to /var/log/journal on node
+func persistJournal(node nodes.Node) error {
+	if err := node.Command("bash", "-c", "mkdir -p /var/log/journal && journalctl --flush").Run(); err != nil {
+		return errors.Wrapf(err, "failed to enable the persistent journal on node %s", node.String())
+	}
+	return nil
+}
@@ -22,12 +22,16 @@ import (
 	"bytes"
 	"strings"
 
+	yaml "gopkg.in/yaml.v3"
+
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/log"
 
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
 )
 
 type action struct{}
@@ -54,9 +58,45 @@ func (a *action) Execute(ctx *actions.ActionContext) error {
 	}
 	node := controlPlanes[0] // kind expects at least one always
 
-	// add the default storage class
-	if err := addDefaultStorage(ctx.Logger, node); err != nil {
-		return errors.Wrap(err, "failed to add default storage class")
+	storage := &ctx.Config.Storage
+	switch storage.Provisioner {
+	case config.LocalPathProvisioner:
+		// add the default storage class
+		if err := addDefaultStorage(ctx.Logger, ctx.Config, node); err != nil {
+			return errors.Wrap(err, "failed to add default storage class")
+		}
+		// a user supplied default class replaces ours as the default
+		if hasDefaultStorageClass(storage.ExtraStorageClasses) {
+			if err := node.Command(
+				"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf",
+				"annotate", "--overwrite", "storageclass", defaultStorageClassName,
+				defaultStorageClassAnnotation+"=false",
+			).Run(); err != nil {
+				return errors.Wrap(err, "failed to unset the default storage class")
+			}
+		}
+	case config.NoneProvisioner:
+	default:
+		// anything else is a validated manifest URL
+		if err := node.Command(
+			"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf",
+			"apply", "-f", string(storage.Provisioner),
+		).Run(); err != nil {
+			return errors.Wrapf(err, "failed to apply storage provisioner manifest %s", storage.Provisioner)
+		}
+	}
+
+	// then add any extra storage classes
+	if len(storage.ExtraStorageClasses) > 0 {
+		manifest, err := storageClassesManifest(storage.ExtraStorageClasses)
+		if err != nil {
+			return err
+		}
+		if err := node.Command(
+			"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf", "apply", "-f", "-",
+		).SetStdin(strings.NewReader(manifest)).Run(); err != nil {
+			return errors.Wrap(err, "failed to add extra storage classes")
+		}
 	}
 
 	// mark success
@@ -64,6 +104,12 @@ func (a *action) Execute(ctx *actions.ActionContext) error {
 	return nil
 }
 
+// defaultStorageClassName is the name of the StorageClass in the node
+// image's default storage manifest, and the legacy fallback below
+const defaultStorageClassName = "standard"
+
+const defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+
 // legacy default storage class
 // we need this for e2es (StatefulSet)
 // newer kind images ship a storage driver manifest
@@ -77,7 +123,7 @@ metadata:
     storageclass.kubernetes.io/is-default-class: "true"
 provisioner: kubernetes.io/host-path`
 
-func addDefaultStorage(logger log.Logger, controlPlane nodes.Node) error {
+func addDefaultStorage(logger log.Logger, cfg *config.Cluster, controlPlane nodes.Node) error {
 	// start with fallback default, and then try to get the newer kind node
 	// storage manifest if present
 	manifest := defaultStorageManifest
@@ -85,7 +131,7 @@ func addDefaultStorage(logger log.Logger, controlPlane nodes.Node) error {
 	if err := controlPlane.Command("cat", "/kind/manifests/default-storage.yaml").SetStdout(&raw).Run(); err != nil {
 		logger.Warn("Could not read storage manifest, falling back on old k8s.io/host-path default ...")
 	} else {
-		manifest = raw.String()
+		manifest = common.OverrideManifestImages(cfg, raw.String())
 	}
 
 	// apply the manifest
@@ -97,3 +143,50 @@ func addDefaultStorage(logger log.Logger, controlPlane nodes.Node) error {
 	cmd.SetStdin(in)
 	return cmd.Run()
 }
+
+func hasDefaultStorageClass(classes []config.StorageClass) bool {
+	for _, class := range classes {
+		if class.Default {
+			return true
+		}
+	}
+	return false
+}
+
+// storageClassesManifest returns a multi-document manifest for classes
+func storageClassesManifest(classes []config.StorageClass) (string, error) {
+	type metadata struct {
+		Name        string            `yaml:"name"`
+		Annotations map[string]string `yaml:"annotations,omitempty"`
+	}
+	type storageClass struct {
+		APIVersion        string            `yaml:"apiVersion"`
+		Kind              string            `yaml:"kind"`
+		Metadata          metadata          `yaml:"metadata"`
+		Provisioner       string            `yaml:"provisioner"`
+		Parameters        map[string]string `yaml:"parameters,omitempty"`
+		ReclaimPolicy     string            `yaml:"reclaimPolicy,omitempty"`
+		VolumeBindingMode string            `yaml:"volumeBindingMode,omitempty"`
+	}
+	var docs []string
+	for _, class := range classes {
+		out := storageClass{
+			APIVersion:        "storage.k8s.io/v1",
+			Kind:              "StorageClass",
+			Metadata:          metadata{Name: class.Name},
+			Provisioner:       class.Provisioner,
+			Parameters:        class.Parameters,
+			ReclaimPolicy:     class.ReclaimPolicy,
+			VolumeBindingMode: class.VolumeBindingMode,
+		}
+		if class.Default {
+			out.Metadata.Annotations = map[string]string{defaultStorageClassAnnotation: "true"}
+		}
+		b, err := yaml.Marshal(&out)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to encode storage class %s", class.Name)
+		}
+		docs = append(docs, string(b))
+	}
+	return strings.Join(docs, "---\n"), nil
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package installstorage
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestStorageClassesManifest(t *testing.T) {
+	t.Parallel()
+	manifest, err := storageClassesManifest([]config.StorageClass{
+		{
+			Name:              "csi",
+			Provisioner:       "csi.example.com",
+			Default:           true,
+			Parameters:        map[string]string{"type": "ssd"},
+			VolumeBindingMode: "WaitForFirstConsumer",
+		},
+		{
+			Name:          "csi-retain",
+			Provisioner:   "csi.example.com",
+			ReclaimPolicy: "Retain",
+		},
+	})
+	assert.ExpectError(t, false, err)
+	assert.StringEqual(t, `apiVersion: storage.k8s.io/v1
+kind: StorageClass
+metadata:
+    name: csi
+    annotations:
+        storageclass.kubernetes.io/is-default-class: "true"
+provisioner: csi.example.com
+parameters:
+    type: ssd
+volumeBindingMode: WaitForFirstConsumer
+---
+apiVersion: storage.k8s.io/v1
+kind: StorageClass
+metadata:
+    name: csi-retain
+provisioner: csi.example.com
+reclaimPolicy: Retain
+`, manifest)
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package actions
+
+import (
+	"bytes"
+	"fmt"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+// DefaultKubeadmVerbosity is the kubeadm --v log level used unless overridden
+const DefaultKubeadmVerbosity = 6
+
+// RunKubeadm runs kubeadm with args and --v=verbosity on node, streaming the
+// output live to logger at V(3) with each line prefixed by the node name.
+// On failure the output is also available from the returned exec.RunError
+func RunKubeadm(logger log.Logger, node nodes.Node, verbosity int, args ...string) error {
+	args = append(args, fmt.Sprintf("--v=%d", verbosity))
+	cmd := node.Command("kubeadm", args...)
+	infoLogger := logger.V(3)
+	if !infoLogger.Enabled() {
+		return cmd.Run()
+	}
+	w := &lineLogger{logger: infoLogger, prefix: "[" + node.String() + "] "}
+	err := cmd.SetStdout(w).SetStderr(w).Run()
+	w.Flush()
+	return err
+}
+
+// lineLogger is an io.Writer logging each complete line written to it
+type lineLogger struct {
+	logger  log.InfoLogger
+	prefix  string
+	partial []byte
+}
+
+func (l *lineLogger) Write(p []byte) (int, error) {
+	l.partial = append(l.partial, p...)
+	for {
+		i := bytes.IndexByte(l.partial, '\n')
+		if i < 0 {
+			break
+		}
+		l.logger.Info(l.prefix + string(l.partial[:i]))
+		l.partial = l.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush logs any remaining partial line
+func (l *lineLogger) Flush() {
+	if len(l.partial) > 0 {
+		l.logger.Info(l.prefix + string(l.partial))
+		l.partial = nil
+	}
+}
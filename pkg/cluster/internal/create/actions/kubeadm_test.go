@@ -0,0 +1,60 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package actions
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type recordingLogger struct {
+	lines []string
+}
+
+func (r *recordingLogger) Info(message string) {
+	r.lines = append(r.lines, message)
+}
+
+func (r *recordingLogger) Infof(format string, args ...interface{}) {
+	r.Info(fmt.Sprintf(format, args...))
+}
+
+func (r *recordingLogger) Enabled() bool {
+	return true
+}
+
+func TestLineLogger(t *testing.T) {
+	t.Parallel()
+	recorder := &recordingLogger{}
+	w := &lineLogger{logger: recorder, prefix: "[node] "}
+	for _, chunk := range []string{"[init] Using Kubernetes", " version: v1.31.0\n[preflight]", " skipped\n\n", "[certs] done"} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	w.Flush()
+	expected := []string{
+		"[node] [init] Using Kubernetes version: v1.31.0",
+		"[node] [preflight] skipped",
+		"[node] ",
+		"[node] [certs] done",
+	}
+	if !reflect.DeepEqual(recorder.lines, expected) {
+		t.Errorf("expected %q, got %q", expected, recorder.lines)
+	}
+}
@@ -18,24 +18,43 @@ limitations under the License.
 package kubeadminit
 
 import (
-	"strings"
+	"net"
 
 	"sigs.k8s.io/kind/pkg/errors"
-	"sigs.k8s.io/kind/pkg/exec"
 
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/diagnostics"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
 )
 
+// SharedControlPlaneFiles are copied from the bootstrap control plane node
+// to the other control plane nodes before they join
+var SharedControlPlaneFiles = []string{
+	// copy over admin config so we can use any control plane to get it later
+	"/etc/kubernetes/admin.conf",
+	// copy over certs
+	"/etc/kubernetes/pki/ca.crt", "/etc/kubernetes/pki/ca.key",
+	"/etc/kubernetes/pki/front-proxy-ca.crt", "/etc/kubernetes/pki/front-proxy-ca.key",
+	"/etc/kubernetes/pki/sa.pub", "/etc/kubernetes/pki/sa.key",
+	// TODO: if we gain external etcd support these will be
+	// handled differently
+	"/etc/kubernetes/pki/etcd/ca.crt", "/etc/kubernetes/pki/etcd/ca.key",
+}
+
 // kubeadmInitAction implements action for executing the kubadm init
 // and a set of default post init operations like e.g. install the
 // CNI network plugin.
-type action struct{}
+type action struct {
+	verbosity int
+}
 
-// NewAction returns a new action for kubeadm init
-func NewAction() actions.Action {
-	return &action{}
+// NewAction returns a new action for kubeadm init, running kubeadm with
+// --v=verbosity
+func NewAction(verbosity int) actions.Action {
+	return &action{verbosity: verbosity}
 }
 
 // Execute runs the action
@@ -56,23 +75,25 @@ func (a *action) Execute(ctx *actions.ActionContext) error {
 		return err
 	}
 
+	// skip preflight checks, as these have undesirable side effects
+	// and don't tell us much. requires kubeadm 1.13+
+	skipPhases := "preflight"
+	// without kube-proxy the CNI is expected to replace it
+	kubeProxyless := ctx.Config.Networking.KubeProxyMode == config.NoneProxyMode
+	if kubeProxyless {
+		skipPhases += ",addon/kube-proxy"
+	}
+
 	// run kubeadm
-	cmd := node.Command(
+	if err := actions.RunKubeadm(ctx.Logger, node, a.verbosity,
 		// init because this is the control plane node
-		"kubeadm", "init",
-		// skip preflight checks, as these have undesirable side effects
-		// and don't tell us much. requires kubeadm 1.13+
-		"--skip-phases=preflight",
+		"init",
+		"--skip-phases="+skipPhases,
 		// specify our generated config file
 		"--config=/kind/kubeadm.conf",
 		"--skip-token-print",
-		// increase verbosity for debugging
-		"--v=6",
-	)
-	lines, err := exec.CombinedOutputLines(cmd)
-	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
-	if err != nil {
-		return errors.Wrap(err, "failed to init node with kubeadm")
+	); err != nil {
+		return diagnostics.WithNode(errors.Wrap(err, "failed to init node with kubeadm"), node)
 	}
 
 	// copy some files to the other control plane nodes
@@ -81,23 +102,21 @@ func (a *action) Execute(ctx *actions.ActionContext) error {
 		return err
 	}
 	for _, otherNode := range otherControlPlanes {
-		for _, file := range []string{
-			// copy over admin config so we can use any control plane to get it later
-			"/etc/kubernetes/admin.conf",
-			// copy over certs
-			"/etc/kubernetes/pki/ca.crt", "/etc/kubernetes/pki/ca.key",
-			"/etc/kubernetes/pki/front-proxy-ca.crt", "/etc/kubernetes/pki/front-proxy-ca.key",
-			"/etc/kubernetes/pki/sa.pub", "/etc/kubernetes/pki/sa.key",
-			// TODO: if we gain external etcd support these will be
-			// handled differently
-			"/etc/kubernetes/pki/etcd/ca.crt", "/etc/kubernetes/pki/etcd/ca.key",
-		} {
+		for _, file := range SharedControlPlaneFiles {
 			if err := nodeutils.CopyNodeToNode(node, otherNode, file); err != nil {
 				return errors.Wrap(err, "failed to copy admin kubeconfig")
 			}
 		}
 	}
 
+	// kube-proxy replacements can't use the kubernetes service to find the
+	// API server, so publish the endpoint for them
+	if kubeProxyless {
+		if err := writeAPIServerEndpointConfigMap(ctx, node); err != nil {
+			return err
+		}
+	}
+
 	// if we are only provisioning one node, remove the master taint
 	// https://kubernetes.io/docs/setup/independent/create-cluster-kubeadm/#master-isolation
 	if len(allNodes) == 1 {
@@ -113,3 +132,29 @@ func (a *action) Execute(ctx *actions.ActionContext) error {
 	ctx.Status.End(true)
 	return nil
 }
+
+// APIServerEndpointConfigMap is the kube-system ConfigMap holding the
+// internal API server endpoint when kube-proxy is disabled, its keys can be
+// loaded with envFrom or passed to e.g. Cilium's k8sServiceHost/k8sServicePort
+const APIServerEndpointConfigMap = "kind-api-server-endpoint"
+
+func writeAPIServerEndpointConfigMap(ctx *actions.ActionContext, node nodes.Node) error {
+	endpoint, err := ctx.Provider.GetAPIServerInternalEndpoint(ctx.Context, ctx.Config.Name)
+	if err != nil {
+		return err
+	}
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse API server endpoint %q", endpoint)
+	}
+	if err := node.Command(
+		"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf",
+		"create", "configmap", APIServerEndpointConfigMap,
+		"--namespace=kube-system",
+		"--from-literal=KUBERNETES_SERVICE_HOST="+host,
+		"--from-literal=KUBERNETES_SERVICE_PORT="+port,
+	).Run(); err != nil {
+		return errors.Wrap(err, "failed to create API server endpoint ConfigMap")
+	}
+	return nil
+}
@@ -18,26 +18,27 @@ limitations under the License.
 package kubeadmjoin
 
 import (
-	"strings"
-
 	"sigs.k8s.io/kind/pkg/cluster/constants"
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/errors"
-	"sigs.k8s.io/kind/pkg/exec"
 	"sigs.k8s.io/kind/pkg/log"
 
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/diagnostics"
 )
 
 // Action implements action for creating the kubeadm join
 // and deployng it on the bootrap control-plane node.
-type Action struct{}
+type Action struct {
+	verbosity int
+}
 
-// NewAction returns a new action for creating the kubeadm jion
-func NewAction() actions.Action {
-	return &Action{}
+// NewAction returns a new action for creating the kubeadm jion, running
+// kubeadm with --v=verbosity
+func NewAction(verbosity int) actions.Action {
+	return &Action{verbosity: verbosity}
 }
 
 // Execute runs the action
@@ -47,24 +48,21 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 		return err
 	}
 
-	// join secondary control plane nodes if any
-	secondaryControlPlanes, err := nodeutils.SecondaryControlPlaneNodes(allNodes)
+	secondaryControlPlanes, workers, err := joinNodes(allNodes)
 	if err != nil {
 		return err
 	}
+
+	// join secondary control plane nodes if any
 	if len(secondaryControlPlanes) > 0 {
-		if err := joinSecondaryControlPlanes(ctx, secondaryControlPlanes); err != nil {
+		if err := joinSecondaryControlPlanes(ctx, secondaryControlPlanes, a.verbosity); err != nil {
 			return err
 		}
 	}
 
 	// then join worker nodes if any
-	workers, err := nodeutils.SelectNodesByRole(allNodes, constants.WorkerNodeRoleValue)
-	if err != nil {
-		return err
-	}
 	if len(workers) > 0 {
-		if err := joinWorkers(ctx, workers); err != nil {
+		if err := joinWorkers(ctx, workers, a.verbosity); err != nil {
 			return err
 		}
 	}
@@ -72,9 +70,31 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 	return nil
 }
 
+// joinNodes returns the secondary control plane nodes and the worker nodes
+// in allNodes to join, when joining an external control plane there are no
+// control plane nodes at all
+func joinNodes(allNodes []nodes.Node) (secondaryControlPlanes, workers []nodes.Node, err error) {
+	controlPlanes, err := nodeutils.SelectNodesByRole(allNodes, constants.ControlPlaneNodeRoleValue)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(controlPlanes) > 0 {
+		secondaryControlPlanes, err = nodeutils.SecondaryControlPlaneNodes(allNodes)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	workers, err = nodeutils.SelectNodesByRole(allNodes, constants.WorkerNodeRoleValue)
+	if err != nil {
+		return nil, nil, err
+	}
+	return secondaryControlPlanes, workers, nil
+}
+
 func joinSecondaryControlPlanes(
 	ctx *actions.ActionContext,
 	secondaryControlPlanes []nodes.Node,
+	verbosity int,
 ) error {
 	ctx.Status.Start("Joining more control-plane nodes 🎮")
 	defer ctx.Status.End(false)
@@ -83,7 +103,7 @@ func joinSecondaryControlPlanes(
 	// (this is not safe currently)
 	for _, node := range secondaryControlPlanes {
 		node := node // capture loop variable
-		if err := runKubeadmJoin(ctx.Logger, node); err != nil {
+		if err := RunKubeadmJoin(ctx.Logger, node, verbosity); err != nil {
 			return err
 		}
 	}
@@ -95,6 +115,7 @@ func joinSecondaryControlPlanes(
 func joinWorkers(
 	ctx *actions.ActionContext,
 	workers []nodes.Node,
+	verbosity int,
 ) error {
 	ctx.Status.Start("Joining worker nodes 🚜")
 	defer ctx.Status.End(false)
@@ -104,7 +125,7 @@ func joinWorkers(
 	for _, node := range workers {
 		node := node // capture loop variable
 		fns = append(fns, func() error {
-			return runKubeadmJoin(ctx.Logger, node)
+			return RunKubeadmJoin(ctx.Logger, node, verbosity)
 		})
 	}
 	if err := errors.UntilErrorConcurrent(fns); err != nil {
@@ -116,23 +137,18 @@ func joinWorkers(
 }
 
 // runKubeadmJoin executes kubadm join command
-func runKubeadmJoin(logger log.Logger, node nodes.Node) error {
+func RunKubeadmJoin(logger log.Logger, node nodes.Node, verbosity int) error {
 	// run kubeadm join
 	// TODO(bentheelder): this should be using the config file
-	cmd := node.Command(
-		"kubeadm", "join",
+	if err := actions.RunKubeadm(logger, node, verbosity,
+		"join",
 		// the join command uses the config file generated in a well known location
 		"--config", "/kind/kubeadm.conf",
 		// skip preflight checks, as these have undesirable side effects
 		// and don't tell us much. requires kubeadm 1.13+
 		"--skip-phases=preflight",
-		// increase verbosity for debugging
-		"--v=6",
-	)
-	lines, err := exec.CombinedOutputLines(cmd)
-	logger.V(3).Info(strings.Join(lines, "\n"))
-	if err != nil {
-		return errors.Wrap(err, "failed to join node with kubeadm")
+	); err != nil {
+		return diagnostics.WithNode(errors.Wrap(err, "failed to join node with kubeadm"), node)
 	}
 
 	return nil
@@ -0,0 +1,88 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeadmjoin
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+type fakeNode struct {
+	nodes.Node
+	name string
+	role string
+}
+
+func (n *fakeNode) String() string {
+	return n.name
+}
+
+func (n *fakeNode) Role() (string, error) {
+	return n.role, nil
+}
+
+func names(ns []nodes.Node) []string {
+	out := []string{}
+	for _, n := range ns {
+		out = append(out, n.String())
+	}
+	return out
+}
+
+func TestJoinNodes(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name                  string
+		Nodes                 []nodes.Node
+		ExpectedControlPlanes []string
+		ExpectedWorkers       []string
+	}{
+		{
+			Name: "control planes and workers",
+			Nodes: []nodes.Node{
+				&fakeNode{name: "kind-worker", role: constants.WorkerNodeRoleValue},
+				&fakeNode{name: "kind-control-plane2", role: constants.ControlPlaneNodeRoleValue},
+				&fakeNode{name: "kind-control-plane", role: constants.ControlPlaneNodeRoleValue},
+				&fakeNode{name: "kind-external-load-balancer", role: constants.ExternalLoadBalancerNodeRoleValue},
+			},
+			ExpectedControlPlanes: []string{"kind-control-plane2"},
+			ExpectedWorkers:       []string{"kind-worker"},
+		},
+		{
+			Name: "external control plane",
+			Nodes: []nodes.Node{
+				&fakeNode{name: "kind-worker", role: constants.WorkerNodeRoleValue},
+				&fakeNode{name: "kind-worker2", role: constants.WorkerNodeRoleValue},
+			},
+			ExpectedControlPlanes: []string{},
+			ExpectedWorkers:       []string{"kind-worker", "kind-worker2"},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			controlPlanes, workers, err := joinNodes(tc.Nodes)
+			assert.ExpectError(t, false, err)
+			assert.DeepEqual(t, tc.ExpectedControlPlanes, names(controlPlanes))
+			assert.DeepEqual(t, tc.ExpectedWorkers, names(workers))
+		})
+	}
+}
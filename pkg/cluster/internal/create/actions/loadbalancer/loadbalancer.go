@@ -19,6 +19,7 @@ package loadbalancer
 
 import (
 	"fmt"
+	"time"
 
 	"sigs.k8s.io/kind/pkg/cluster/constants"
 	"sigs.k8s.io/kind/pkg/errors"
@@ -73,24 +74,47 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 	for _, n := range controlPlaneNodes {
 		backendServers[n.String()] = fmt.Sprintf("%s:%d", n.String(), common.APIServerInternalPort)
 	}
+	lb := ctx.Config.LoadBalancer
+	extraFrontends := make([]loadbalancer.Frontend, 0, len(lb.ExtraFrontends))
+	for _, f := range lb.ExtraFrontends {
+		servers := map[string]string{}
+		for _, n := range controlPlaneNodes {
+			servers[n.String()] = fmt.Sprintf("%s:%d", n.String(), f.BackendPort)
+		}
+		extraFrontends = append(extraFrontends, loadbalancer.Frontend{
+			Name:    f.Name,
+			Port:    int(f.Port),
+			Servers: servers,
+		})
+	}
+	interval, err := time.ParseDuration(lb.HealthCheck.Interval)
+	if err != nil {
+		return errors.Wrap(err, "invalid loadBalancer.healthCheck.interval")
+	}
 
 	// create loadbalancer config data
-	loadbalancerConfig, err := loadbalancer.Config(&loadbalancer.ConfigData{
+	loadbalancerConfig, err := loadbalancer.ConfigFor(lb.Implementation, &loadbalancer.ConfigData{
 		ControlPlanePort: common.APIServerInternalPort,
 		BackendServers:   backendServers,
 		IPv6:             ctx.Config.Networking.IPFamily == config.IPv6Family,
+		HealthCheck: loadbalancer.HealthCheck{
+			IntervalMillis: interval.Milliseconds(),
+			Rise:           lb.HealthCheck.Rise,
+			Fall:           lb.HealthCheck.Fall,
+		},
+		ExtraFrontends: extraFrontends,
 	})
 	if err != nil {
 		return errors.Wrap(err, "failed to generate loadbalancer config data")
 	}
 
 	// create loadbalancer config on the node
-	if err := nodeutils.WriteFile(loadBalancerNode, loadbalancer.ConfigPath, loadbalancerConfig); err != nil {
+	if err := nodeutils.WriteFile(loadBalancerNode, loadbalancer.ConfigPathFor(lb.Implementation), loadbalancerConfig); err != nil {
 		// TODO: logging here
 		return errors.Wrap(err, "failed to copy loadbalancer config to node")
 	}
 
-	// reload the config. haproxy will reload on SIGHUP
+	// reload the config. haproxy and nginx will reload on SIGHUP
 	if err := loadBalancerNode.Command("kill", "-s", "HUP", "1").Run(); err != nil {
 		return errors.Wrap(err, "failed to reload loadbalancer")
 	}
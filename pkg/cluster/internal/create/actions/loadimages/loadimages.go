@@ -0,0 +1,85 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package loadimages implements the action for loading image archives into
+// the nodes before Kubernetes is set up
+package loadimages
+
+import (
+	"os"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+)
+
+type action struct {
+	archives []string
+}
+
+// NewAction returns a new action for loading the image archives into all
+// Kubernetes nodes
+func NewAction(archives []string) actions.Action {
+	return &action{
+		archives: archives,
+	}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	ctx.Status.Start("Loading image archives 📦")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	kubeNodes, err := nodeutils.InternalNodes(allNodes)
+	if err != nil {
+		return err
+	}
+
+	fns := []func() error{}
+	for _, node := range kubeNodes {
+		node := node // capture loop variable
+		fns = append(fns, func() error {
+			for _, archive := range a.archives {
+				if err := loadArchive(ctx, node, archive); err != nil {
+					return errors.Wrapf(err, "failed to load image archive %s into node %s", archive, node.String())
+				}
+			}
+			return nil
+		})
+	}
+	if err := errors.UntilErrorConcurrent(fns); err != nil {
+		return err
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+func loadArchive(ctx *actions.ActionContext, node nodes.Node, archive string) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return nodes.LoadImage(ctx.Context, node, f)
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package offline implements the action that checks all images needed to
+// set up Kubernetes are on the nodes, so that nothing is pulled
+package offline
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/requiredimages"
+)
+
+type action struct{}
+
+// NewAction returns a new action for checking the nodes have every image
+// required without pulling
+func NewAction() actions.Action {
+	return &action{}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	ctx.Status.Start("Checking images are available offline 🔒")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	kubeNodes, err := nodeutils.InternalNodes(allNodes)
+	if err != nil {
+		return err
+	}
+
+	required, err := requiredImages(ctx, kubeNodes[0])
+	if err != nil {
+		return err
+	}
+
+	// check every node, so we report everything that is missing at once
+	missing := []string{}
+	for _, node := range kubeNodes {
+		present, err := nodeImages(node)
+		if err != nil {
+			return err
+		}
+		for _, image := range missingImages(required, present) {
+			missing = append(missing, fmt.Sprintf("%s: %s", node.String(), image))
+		}
+	}
+	if len(missing) > 0 {
+		return errors.WithReason(
+			errors.Errorf(
+				"offline mode requires these images to be in the node image or an image archive:\n%s",
+				strings.Join(missing, "\n"),
+			),
+			errors.ErrImagesMissing,
+		)
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// requiredImages returns the images that setting up Kubernetes will use
+// node is used to read the kubeadm config and manifests
+func requiredImages(ctx *actions.ActionContext, node nodes.Node) (sets.String, error) {
+	images, err := requiredimages.ForKubernetes(
+		ctx.Config, node.Command, "--config=/kind/kubeadm.conf",
+	)
+	if err != nil {
+		return nil, err
+	}
+	required := sets.NewString()
+	for _, image := range images {
+		required.Insert(image.Image)
+	}
+	return required, nil
+}
+
+// nodeImages returns the normalized names of the images on node
+func nodeImages(node nodes.Node) (sets.String, error) {
+	lines, err := exec.OutputLines(node.Command(
+		"ctr", "--namespace=k8s.io", "images", "list", "--quiet",
+	))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list images on node %s", node.String())
+	}
+	present := sets.NewString()
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			present.Insert(normalizeImage(line))
+		}
+	}
+	return present, nil
+}
+
+// missingImages returns the sorted images in required not in present,
+// present must already be normalized
+func missingImages(required, present sets.String) []string {
+	missing := []string{}
+	for _, image := range required.List() {
+		if !present.Has(normalizeImage(image)) {
+			missing = append(missing, image)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// normalizeImage returns the fully qualified form of image as listed by
+// containerd, e.g. "kindest/kindnetd:v1" becomes "docker.io/kindest/kindnetd:v1"
+func normalizeImage(image string) string {
+	if name := image[strings.LastIndex(image, "/")+1:]; !strings.ContainsAny(name, ":@") {
+		image += ":latest"
+	}
+	parts := strings.SplitN(image, "/", 2)
+	if len(parts) == 1 {
+		return "docker.io/library/" + image
+	}
+	if !strings.ContainsAny(parts[0], ".:") && parts[0] != "localhost" {
+		return "docker.io/" + image
+	}
+	return image
+}
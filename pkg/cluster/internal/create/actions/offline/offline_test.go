@@ -0,0 +1,54 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package offline
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestNormalizeImage(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Image    string
+		Expected string
+	}{
+		{Image: "busybox", Expected: "docker.io/library/busybox:latest"},
+		{Image: "kindest/kindnetd:v20200725-4d6bea59", Expected: "docker.io/kindest/kindnetd:v20200725-4d6bea59"},
+		{Image: "k8s.gcr.io/pause:3.2", Expected: "k8s.gcr.io/pause:3.2"},
+		{Image: "localhost/kind-clone/foo:1", Expected: "localhost/kind-clone/foo:1"},
+		{Image: "registry.example:5000/etcd", Expected: "registry.example:5000/etcd:latest"},
+		{Image: "k8s.gcr.io/etcd@sha256:abc", Expected: "k8s.gcr.io/etcd@sha256:abc"},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Image, func(t *testing.T) {
+			t.Parallel()
+			assert.StringEqual(t, tc.Expected, normalizeImage(tc.Image))
+		})
+	}
+}
+
+func TestMissingImages(t *testing.T) {
+	t.Parallel()
+	required := sets.NewString("k8s.gcr.io/pause:3.2", "kindest/kindnetd:v1", "registry.example/etcd:3.4.3-0")
+	present := sets.NewString("k8s.gcr.io/pause:3.2", "docker.io/kindest/kindnetd:v1")
+	assert.DeepEqual(t, []string{"registry.example/etcd:3.4.3-0"}, missingImages(required, present))
+}
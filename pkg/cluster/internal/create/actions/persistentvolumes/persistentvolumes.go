@@ -0,0 +1,166 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package persistentvolumes implements the action to pre-provision local
+// PersistentVolumes on nodes
+package persistentvolumes
+
+import (
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+type action struct{}
+
+// NewAction returns a new action for creating the configured PersistentVolumes
+func NewAction() actions.Action {
+	return &action{}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	ctx.Status.Start("Creating PersistentVolumes 🗄")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+
+	// create the backing directories on each node and collect the volumes
+	volumes := []volume{}
+	fns := []func() error{}
+	for _, node := range allNodes {
+		node := node // capture loop variable
+		configNode, err := actions.ConfigNode(ctx.Config, node)
+		if err != nil {
+			// nodes not from the config, e.g. the load balancer
+			continue
+		}
+		if len(configNode.PersistentVolumes) == 0 {
+			continue
+		}
+		// the kubelet registers the node by its hostname
+		hostname := common.NodeHostname(configNode, node.String())
+		args := []string{"-p"}
+		for _, pv := range configNode.PersistentVolumes {
+			volumes = append(volumes, volume{PersistentVolume: pv, NodeName: hostname})
+			args = append(args, pv.Path)
+		}
+		fns = append(fns, func() error {
+			if err := node.Command("mkdir", args...).Run(); err != nil {
+				return errors.Wrapf(err, "failed to create persistent volume directories on node %s", node.String())
+			}
+			return nil
+		})
+	}
+	if err := errors.UntilErrorConcurrent(fns); err != nil {
+		return err
+	}
+
+	// then create the volumes from the first control plane
+	controlPlanes, err := nodeutils.ControlPlaneNodes(allNodes)
+	if err != nil {
+		return err
+	}
+	manifest, err := persistentVolumesManifest(volumes)
+	if err != nil {
+		return err
+	}
+	if err := controlPlanes[0].Command(
+		"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf", "apply", "-f", "-",
+	).SetStdin(strings.NewReader(manifest)).Run(); err != nil {
+		return errors.Wrap(err, "failed to create persistent volumes")
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// volume is a configured PersistentVolume and the node it belongs to
+type volume struct {
+	config.PersistentVolume
+	NodeName string
+}
+
+// persistentVolumesManifest returns a multi-document manifest of local
+// PersistentVolumes with node affinity for their node
+func persistentVolumesManifest(volumes []volume) (string, error) {
+	type matchExpression struct {
+		Key      string   `yaml:"key"`
+		Operator string   `yaml:"operator"`
+		Values   []string `yaml:"values"`
+	}
+	type nodeSelectorTerm struct {
+		MatchExpressions []matchExpression `yaml:"matchExpressions"`
+	}
+	type nodeAffinity struct {
+		Required struct {
+			NodeSelectorTerms []nodeSelectorTerm `yaml:"nodeSelectorTerms"`
+		} `yaml:"required"`
+	}
+	type spec struct {
+		Capacity                      map[string]string `yaml:"capacity"`
+		AccessModes                   []string          `yaml:"accessModes"`
+		PersistentVolumeReclaimPolicy string            `yaml:"persistentVolumeReclaimPolicy"`
+		StorageClassName              string            `yaml:"storageClassName"`
+		Local                         map[string]string `yaml:"local"`
+		NodeAffinity                  nodeAffinity      `yaml:"nodeAffinity"`
+	}
+	type persistentVolume struct {
+		APIVersion string            `yaml:"apiVersion"`
+		Kind       string            `yaml:"kind"`
+		Metadata   map[string]string `yaml:"metadata"`
+		Spec       spec              `yaml:"spec"`
+	}
+	var docs []string
+	for _, v := range volumes {
+		out := persistentVolume{
+			APIVersion: "v1",
+			Kind:       "PersistentVolume",
+			Metadata:   map[string]string{"name": v.Name},
+			Spec: spec{
+				Capacity:                      map[string]string{"storage": v.Capacity},
+				AccessModes:                   []string{"ReadWriteOnce"},
+				PersistentVolumeReclaimPolicy: v.ReclaimPolicy,
+				StorageClassName:              v.StorageClassName,
+				Local:                         map[string]string{"path": v.Path},
+			},
+		}
+		out.Spec.NodeAffinity.Required.NodeSelectorTerms = []nodeSelectorTerm{{
+			MatchExpressions: []matchExpression{{
+				Key:      "kubernetes.io/hostname",
+				Operator: "In",
+				Values:   []string{v.NodeName},
+			}},
+		}}
+		b, err := yaml.Marshal(&out)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to encode persistent volume %s", v.Name)
+		}
+		docs = append(docs, string(b))
+	}
+	return strings.Join(docs, "---\n"), nil
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package persistentvolumes
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestPersistentVolumesManifest(t *testing.T) {
+	t.Parallel()
+	manifest, err := persistentVolumesManifest([]volume{
+		{
+			PersistentVolume: config.PersistentVolume{
+				Name:             "data-0",
+				Path:             "/data/pv0",
+				Capacity:         "10Gi",
+				StorageClassName: "standard",
+				ReclaimPolicy:    "Retain",
+			},
+			NodeName: "kind-worker",
+		},
+	})
+	assert.ExpectError(t, false, err)
+	assert.StringEqual(t, `apiVersion: v1
+kind: PersistentVolume
+metadata:
+    name: data-0
+spec:
+    capacity:
+        storage: 10Gi
+    accessModes:
+        - ReadWriteOnce
+    persistentVolumeReclaimPolicy: Retain
+    storageClassName: standard
+    local:
+        path: /data/pv0
+    nodeAffinity:
+        required:
+            nodeSelectorTerms:
+                - matchExpressions:
+                    - key: kubernetes.io/hostname
+                      operator: In
+                      values:
+                        - kind-worker
+`, manifest)
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package recordimages implements the action to record the images preloaded
+// in the node image
+package recordimages
+
+import (
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/prune"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+)
+
+type action struct{}
+
+// NewAction returns a new action for recording the preloaded images
+//
+// This must run before any image is loaded into or pulled by the nodes, so
+// `kind prune images` can tell the images baked into the node image apart,
+// the containerd of the base image does not mark them as pinned.
+func NewAction() actions.Action {
+	return &action{}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+
+	internalNodes, err := nodeutils.InternalNodes(allNodes)
+	if err != nil {
+		return err
+	}
+
+	fns := []func() error{}
+	for _, node := range internalNodes {
+		node := node // capture loop variable
+		fns = append(fns, func() error {
+			return record(node)
+		})
+	}
+	return errors.UntilErrorConcurrent(fns)
+}
+
+func record(node nodes.Node) error {
+	if err := prune.RecordPreloadedImages(node); err != nil {
+		return errors.Wrapf(err, "failed to record the preloaded images of node %s", node.String())
+	}
+	return nil
+}
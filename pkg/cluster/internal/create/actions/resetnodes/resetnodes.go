@@ -0,0 +1,166 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package resetnodes implements the action to clear the Kubernetes state
+// of nodes started from images committed from another cluster
+package resetnodes
+
+import (
+	"io"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+type action struct {
+	sources []nodes.Node
+}
+
+// NewAction returns a new action for resetting cloned nodes, sources are
+// the nodes the images of the config's nodes were committed from, in order
+//
+// Committed node images carry the source cluster's certificates, static pod
+// manifests and kubeconfigs, these must be removed so that kubeadm generates
+// new ones for this cluster. The machine-id is already regenerated on boot.
+// Images in /var are not committed, they are copied from the source nodes
+// now so that nothing the source cluster already pulled is pulled again.
+func NewAction(sources []nodes.Node) actions.Action {
+	return &action{sources: sources}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	ctx.Status.Start("Resetting cloned nodes 🧽")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+
+	index := map[string]int{}
+	for i, name := range config.NodeNames(ctx.Config) {
+		index[name] = i
+	}
+	fns := []func() error{}
+	for _, node := range allNodes {
+		node := node // capture loop variable
+		i, ok := index[node.String()]
+		if !ok {
+			// nodes not from the config, e.g. the load balancer
+			continue
+		}
+		var source nodes.Node
+		if i < len(a.sources) {
+			source = a.sources[i]
+		}
+		fns = append(fns, func() error {
+			if err := node.Command("bash", "-c", resetScript).Run(); err != nil {
+				return errors.Wrapf(err, "failed to reset node %s", node.String())
+			}
+			if source == nil {
+				return nil
+			}
+			// missing images are only pulled again, this is not fatal
+			if err := copyImages(source, node); err != nil {
+				ctx.Logger.Warnf("failed to copy images to the cloned node: %v", err)
+			}
+			return nil
+		})
+	}
+	if err := errors.UntilErrorConcurrent(fns); err != nil {
+		return err
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// resetScript removes the node's cluster identity, and any clock offset
+// so that certificates are issued with the real clock, the clockoffset
+// action re-applies it after join
+const resetScript = `set -e
+rm -rf /etc/kubernetes/pki /etc/kubernetes/*.conf /etc/kubernetes/manifests/* /etc/cni/net.d/* /kind/kubeadm.conf
+if [[ -f /etc/faketimerc ]]; then
+  rm -f /etc/faketimerc /etc/systemd/system/{containerd,kubelet}.service.d/20-faketime.conf
+  systemctl daemon-reload
+  systemctl restart containerd
+fi
+`
+
+// copyImages loads the images in src that are missing from dst into dst
+func copyImages(src, dst nodes.Node) error {
+	srcImages, err := listImages(src)
+	if err != nil {
+		return err
+	}
+	dstImages, err := listImages(dst)
+	if err != nil {
+		return err
+	}
+	missing := missingImages(srcImages, dstImages)
+	if len(missing) == 0 {
+		return nil
+	}
+	// stream the images from one node to the other
+	pr, pw := io.Pipe()
+	go func() {
+		args := append([]string{"--namespace=k8s.io", "images", "export", "-"}, missing...)
+		_ = pw.CloseWithError(src.Command("ctr", args...).SetStdout(pw).Run())
+	}()
+	if err := nodeutils.LoadImageArchive(dst, pr); err != nil {
+		_ = pr.CloseWithError(err)
+		return errors.Wrapf(err, "failed to copy images from %s to %s", src.String(), dst.String())
+	}
+	return nil
+}
+
+// missingImages returns the images in src that are not in dst
+func missingImages(src, dst []string) []string {
+	have := make(map[string]bool, len(dst))
+	for _, image := range dst {
+		have[image] = true
+	}
+	missing := []string{}
+	for _, image := range src {
+		if !have[image] {
+			missing = append(missing, image)
+		}
+	}
+	return missing
+}
+
+// listImages returns the image references on node, excluding bare digests
+func listImages(node nodes.Node) ([]string, error) {
+	lines, err := exec.OutputLines(node.Command("ctr", "--namespace=k8s.io", "images", "list", "-q"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list images on %s", node.String())
+	}
+	images := []string{}
+	for _, line := range lines {
+		if line != "" && !strings.HasPrefix(line, "sha256:") {
+			images = append(images, line)
+		}
+	}
+	return images, nil
+}
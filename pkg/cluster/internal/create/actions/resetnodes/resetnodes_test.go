@@ -0,0 +1,33 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resetnodes
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestMissingImages(t *testing.T) {
+	t.Parallel()
+	assert.DeepEqual(t, []string{"docker.io/library/nginx:1.19"}, missingImages(
+		[]string{"k8s.gcr.io/pause:3.2", "docker.io/library/nginx:1.19"},
+		[]string{"k8s.gcr.io/pause:3.2", "docker.io/kindest/kindnetd:0.5.4"},
+	))
+	assert.DeepEqual(t, []string{}, missingImages([]string{"k8s.gcr.io/pause:3.2"}, []string{"k8s.gcr.io/pause:3.2"}))
+	assert.DeepEqual(t, []string{}, missingImages(nil, nil))
+}
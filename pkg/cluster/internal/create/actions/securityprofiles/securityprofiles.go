@@ -0,0 +1,128 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package securityprofiles implements the action to load AppArmor profiles,
+// and the mounts of the seccomp profiles
+package securityprofiles
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/warnings"
+)
+
+// SeccompDir is where the seccompDir is mounted on the nodes, the kubelet
+// resolves localhostProfile relative to /var/lib/kubelet/seccomp
+const SeccompDir = "/var/lib/kubelet/seccomp/profiles"
+
+// AppArmorDir is where the AppArmor profiles are copied to on the node
+const AppArmorDir = "/etc/apparmor.d/kind"
+
+// enabledCommand succeeds if the host kernel has AppArmor enabled and the
+// node can load profiles into it, securityfs is not mounted in the node
+const enabledCommand = `[ "$(cat /sys/module/apparmor/parameters/enabled 2>/dev/null)" = Y ] && ` +
+	`{ [ -d /sys/kernel/security/apparmor ] || mount -t securityfs securityfs /sys/kernel/security; } && ` +
+	`[ -w /sys/kernel/security/apparmor/.replace ]`
+
+// Mounts returns the mounts every node needs for the seccomp profiles of cfg,
+// the directory must exist
+func Mounts(cfg *config.Cluster) ([]config.Mount, error) {
+	dir := cfg.SecurityProfiles.SeccompDir
+	if dir == "" {
+		return nil, nil
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid securityProfiles.seccompDir")
+	}
+	if !info.IsDir() {
+		return nil, errors.Errorf("invalid securityProfiles.seccompDir: %s is not a directory", dir)
+	}
+	return []config.Mount{{
+		HostPath:      dir,
+		ContainerPath: SeccompDir,
+		Readonly:      true,
+	}}, nil
+}
+
+type action struct{}
+
+// NewAction returns a new action for loading the AppArmor profiles
+//
+// AppArmor is not namespaced, so the profiles are loaded into the host kernel
+// once, from the first control plane node, and stay loaded until the host
+// restarts. Hosts without AppArmor, or where the node cannot load profiles
+// such as with a rootless container runtime, are skipped with a warning.
+func NewAction() actions.Action {
+	return &action{}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	ctx.Status.Start("Loading AppArmor profiles 🛡")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	node, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return err
+	}
+
+	if err := node.Command("sh", "-c", enabledCommand).Run(); err != nil {
+		ctx.Status.End(true)
+		return warnings.Report(ctx.Context, ctx.Logger, warnings.Warning{
+			Source:  "securityProfiles",
+			Message: "skipping the AppArmor profiles, AppArmor is not enabled on the host or the node cannot load profiles",
+		})
+	}
+	for _, p := range ctx.Config.SecurityProfiles.AppArmor {
+		if err := load(node, p); err != nil {
+			return err
+		}
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// load copies the AppArmor profile at hostPath to node and loads it
+func load(node nodes.Node, hostPath string) error {
+	contents, err := ioutil.ReadFile(hostPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to read AppArmor profile")
+	}
+	dest := path.Join(AppArmorDir, filepath.Base(hostPath))
+	if err := nodeutils.WriteFile(node, dest, string(contents)); err != nil {
+		return errors.Wrap(err, "failed to copy AppArmor profile to node")
+	}
+	if err := node.Command("apparmor_parser", "--replace", dest).Run(); err != nil {
+		return errors.Wrapf(err, "failed to load AppArmor profile %s", hostPath)
+	}
+	return nil
+}
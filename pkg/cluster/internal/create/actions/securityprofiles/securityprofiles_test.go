@@ -0,0 +1,53 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package securityprofiles
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestMounts(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kind-seccomp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "audit.json")
+	if err := ioutil.WriteFile(file, []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	mounts, err := Mounts(&config.Cluster{})
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, []config.Mount(nil), mounts)
+
+	mounts, err = Mounts(&config.Cluster{SecurityProfiles: config.SecurityProfiles{SeccompDir: dir}})
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, []config.Mount{{HostPath: dir, ContainerPath: SeccompDir, Readonly: true}}, mounts)
+
+	_, err = Mounts(&config.Cluster{SecurityProfiles: config.SecurityProfiles{SeccompDir: file}})
+	assert.ExpectError(t, true, err)
+	_, err = Mounts(&config.Cluster{SecurityProfiles: config.SecurityProfiles{SeccompDir: filepath.Join(dir, "missing")}})
+	assert.ExpectError(t, true, err)
+}
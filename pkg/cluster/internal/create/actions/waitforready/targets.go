@@ -0,0 +1,118 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package waitforready
+
+import (
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/waiter"
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// The wait targets
+const (
+	// ControlPlaneTarget waits for the control plane nodes to be Ready
+	ControlPlaneTarget = "control-plane"
+	// NodesTarget waits for every node to have a condition, Ready by default
+	NodesTarget = "nodes"
+	// CoreDNSTarget waits for the CoreDNS deployment to be available
+	CoreDNSTarget = "coredns"
+	// DefaultServiceAccountTarget waits for the default service account of
+	// the default namespace, which pods in it need before they can be created
+	DefaultServiceAccountTarget = "default-sa"
+	// MetricsTarget waits for the metrics API, see the observability preset
+	MetricsTarget = "metrics"
+)
+
+// TargetNames lists the valid Target names
+var TargetNames = []string{ControlPlaneTarget, NodesTarget, CoreDNSTarget, DefaultServiceAccountTarget, MetricsTarget}
+
+// DefaultTimeout is the timeout of a target that does not set one
+const DefaultTimeout = waiter.DefaultTimeout
+
+// Target is something to wait for once the cluster is created
+type Target struct {
+	// Name is one of TargetNames
+	Name string
+	// Condition is the node condition type the nodes target waits for
+	Condition string
+	// Timeout bounds the wait, from when waiting starts for the first target
+	Timeout time.Duration
+}
+
+// ParseTargets parses a comma separated list of targets, each of the form
+// NAME[=CONDITION][:TIMEOUT], e.g. "nodes=Ready:2m,coredns,default-sa"
+func ParseTargets(s string) ([]Target, error) {
+	targets := []Target{}
+	for _, spec := range strings.Split(s, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		t := Target{Timeout: DefaultTimeout}
+		if i := strings.Index(spec, ":"); i != -1 {
+			timeout, err := time.ParseDuration(spec[i+1:])
+			if err != nil || timeout <= 0 {
+				return nil, errors.Errorf("invalid wait target %q, the timeout must be a positive duration", spec)
+			}
+			t.Timeout = timeout
+			spec = spec[:i]
+		}
+		parts := strings.SplitN(spec, "=", 2)
+		t.Name = parts[0]
+		if len(parts) == 2 {
+			t.Condition = parts[1]
+		}
+		switch t.Name {
+		case NodesTarget:
+			if t.Condition == "" {
+				t.Condition = "Ready"
+			}
+		case ControlPlaneTarget, CoreDNSTarget, DefaultServiceAccountTarget, MetricsTarget:
+			if t.Condition != "" {
+				return nil, errors.Errorf("invalid wait target %q, only %s takes a condition", spec, NodesTarget)
+			}
+		default:
+			return nil, errors.Errorf("unknown wait target %q, expected one of: %s", t.Name, strings.Join(TargetNames, ", "))
+		}
+		targets = append(targets, t)
+	}
+	if len(targets) == 0 {
+		return nil, errors.New("no wait targets given")
+	}
+	return targets, nil
+}
+
+// Waiter returns the waiter for the target, targets that time out are only
+// reported as warnings
+func (t Target) Waiter() waiter.Waiter {
+	var w waiter.Waiter
+	switch t.Name {
+	case ControlPlaneTarget:
+		w = waiter.ControlPlane()
+	case NodesTarget:
+		w = waiter.NodeCondition(t.Condition)
+	case CoreDNSTarget:
+		w = waiter.Deployment("kube-system", "coredns").WithDescription("coredns = Available")
+	case DefaultServiceAccountTarget:
+		w = waiter.ServiceAccount("default", "default").WithDescription("default service account")
+	default:
+		w = waiter.APIService("v1beta1.metrics.k8s.io").WithDescription("metrics API = Available")
+	}
+	return w.WithTimeout(t.Timeout).WithWarnOnly()
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package waitforready
+
+import (
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestParseTargets(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name          string
+		Value         string
+		Expected      []Target
+		ExpectedError bool
+	}{
+		{
+			Name:  "defaults",
+			Value: "control-plane,nodes,coredns",
+			Expected: []Target{
+				{Name: ControlPlaneTarget, Timeout: DefaultTimeout},
+				{Name: NodesTarget, Condition: "Ready", Timeout: DefaultTimeout},
+				{Name: CoreDNSTarget, Timeout: DefaultTimeout},
+			},
+		},
+		{
+			Name:  "conditions and timeouts",
+			Value: "nodes=NetworkUnavailable:2m, default-sa:30s,metrics:10m",
+			Expected: []Target{
+				{Name: NodesTarget, Condition: "NetworkUnavailable", Timeout: 2 * time.Minute},
+				{Name: DefaultServiceAccountTarget, Timeout: 30 * time.Second},
+				{Name: MetricsTarget, Timeout: 10 * time.Minute},
+			},
+		},
+		{
+			Name:          "unknown target",
+			Value:         "nodes,pods",
+			ExpectedError: true,
+		},
+		{
+			Name:          "condition on a target without one",
+			Value:         "coredns=Ready",
+			ExpectedError: true,
+		},
+		{
+			Name:          "invalid timeout",
+			Value:         "nodes:soon",
+			ExpectedError: true,
+		},
+		{
+			Name:          "zero timeout",
+			Value:         "nodes:0s",
+			ExpectedError: true,
+		},
+		{
+			Name:          "empty",
+			Value:         " , ",
+			ExpectedError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			targets, err := ParseTargets(tc.Value)
+			assert.ExpectError(t, tc.ExpectedError, err)
+			if !tc.ExpectedError {
+				assert.DeepEqual(t, tc.Expected, targets)
+			}
+		})
+	}
+}
+
+func TestTargetWaiter(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Target              Target
+		ExpectedDescription string
+	}{
+		{Target: Target{Name: ControlPlaneTarget}, ExpectedDescription: "control-plane = Ready"},
+		{Target: Target{Name: NodesTarget, Condition: "Ready"}, ExpectedDescription: "nodes = Ready"},
+		{Target: Target{Name: CoreDNSTarget}, ExpectedDescription: "coredns = Available"},
+		{Target: Target{Name: DefaultServiceAccountTarget}, ExpectedDescription: "default service account"},
+		{Target: Target{Name: MetricsTarget, Timeout: time.Minute}, ExpectedDescription: "metrics API = Available"},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Target.Name, func(t *testing.T) {
+			t.Parallel()
+			w := tc.Target.Waiter()
+			assert.StringEqual(t, tc.ExpectedDescription, w.Description())
+			expectedTimeout := tc.Target.Timeout
+			if expectedTimeout == 0 {
+				expectedTimeout = DefaultTimeout
+			}
+			if w.Timeout() != expectedTimeout {
+				t.Errorf("expected timeout %v, got %v", expectedTimeout, w.Timeout())
+			}
+		})
+	}
+}
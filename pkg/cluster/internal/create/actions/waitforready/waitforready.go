@@ -18,40 +18,43 @@ limitations under the License.
 package waitforready
 
 import (
+	"context"
 	"fmt"
-	"strings"
 	"time"
 
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/diagnostics"
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
-	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/cluster/waiter"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/fs"
 )
 
+// diagnosticsTimeout bounds collecting diagnostics after a timeout
+const diagnosticsTimeout = 30 * time.Second
+
+// pollInterval is how often a waiter is checked again while not ready
+const pollInterval = 250 * time.Millisecond
+
 // Action implements an action for waiting for the cluster to be ready
 type Action struct {
-	waitTime time.Duration
+	waiters []waiter.Waiter
 }
 
-// NewAction returns a new action for waiting for the cluster to be ready
-func NewAction(waitTime time.Duration) actions.Action {
+// NewAction returns a new action for waiting for the waiters in order
+func NewAction(waiters []waiter.Waiter) actions.Action {
 	return &Action{
-		waitTime: waitTime,
+		waiters: waiters,
 	}
 }
 
 // Execute runs the action
 func (a *Action) Execute(ctx *actions.ActionContext) error {
-	// skip entirely if the wait time is 0
-	if a.waitTime == time.Duration(0) {
+	// skip entirely if there is nothing to wait for
+	if len(a.waiters) == 0 {
 		return nil
 	}
-	ctx.Status.Start(
-		fmt.Sprintf(
-			"Waiting ≤ %s for control-plane = Ready ⏳",
-			formatDuration(a.waitTime),
-		),
-	)
 
 	allNodes, err := ctx.Nodes()
 	if err != nil {
@@ -63,54 +66,73 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 		return err
 	}
 	node := controlPlanes[0] // kind expects at least one always
-
-	// Wait for the nodes to reach Ready status.
-	startTime := time.Now()
-	isReady := waitForReady(node, startTime.Add(a.waitTime))
-	if !isReady {
-		ctx.Status.End(false)
-		ctx.Logger.V(0).Info(" • WARNING: Timed out waiting for Ready ⚠️")
-		return nil
+	internalNodes, err := nodeutils.InternalNodes(allNodes)
+	if err != nil {
+		return err
 	}
 
-	// mark success
-	ctx.Status.End(true)
-	ctx.Logger.V(0).Infof(" • Ready after %s 💚", formatDuration(time.Since(startTime)))
-	return nil
-}
+	c := &waiter.Cluster{
+		Name:         ctx.Config.Name,
+		ControlPlane: node,
+		Nodes:        internalNodes,
+	}
 
-// WaitForReady uses kubectl inside the "node" container to check if the
-// control plane nodes are "Ready".
-func waitForReady(node nodes.Node, until time.Time) bool {
-	return tryUntil(until, func() bool {
-		cmd := node.Command(
-			"kubectl",
-			"--kubeconfig=/etc/kubernetes/admin.conf",
-			"get",
-			"nodes",
-			"--selector=node-role.kubernetes.io/master",
-			// When the node reaches status ready, the status field will be set
-			// to true.
-			"-o=jsonpath='{.items..status.conditions[-1:].status}'",
+	// each timeout counts from the start, so the waiters are waited for
+	// as if concurrently
+	startTime := time.Now()
+	timedOut := false
+	for _, w := range a.waiters {
+		ctx.Status.Start(
+			fmt.Sprintf(
+				"Waiting ≤ %s for %s ⏳",
+				formatDuration(w.Timeout()), w.Description(),
+			),
 		)
-		lines, err := exec.CombinedOutputLines(cmd)
-		if err != nil {
-			return false
-		}
-
-		// 'lines' will return the status of all nodes labeled as master. For
-		// example, if we have three control plane nodes, and all are ready,
-		// then the status will have the following format: `True True True'.
-		status := strings.Fields(lines[0])
-		for _, s := range status {
-			// Check node status. If node is ready then this will be 'True',
-			// 'False' or 'Unkown' otherwise.
-			if !strings.Contains(s, "True") {
-				return false
+		var lastErr error
+		isReady := tryUntil(startTime.Add(w.Timeout()), func() bool {
+			ready, err := w.Ready(ctx.Context, c)
+			lastErr = err
+			return ready
+		})
+		if !isReady {
+			ctx.Status.End(false)
+			if lastErr != nil {
+				ctx.Logger.V(1).Infof("last error waiting for %s: %v", w.Description(), lastErr)
+			}
+			if !w.WarnOnly() {
+				reportNotReady(ctx, node, internalNodes)
+				if lastErr != nil {
+					return errors.Wrapf(lastErr, "timed out waiting for %s", w.Description())
+				}
+				return errors.Errorf("timed out waiting for %s", w.Description())
 			}
+			ctx.Logger.V(0).Infof(" • WARNING: Timed out waiting for %s ⚠️", w.Description())
+			timedOut = true
+			continue
 		}
-		return true
-	})
+		ctx.Status.End(true)
+		ctx.Logger.V(0).Infof(" • Ready after %s 💚", formatDuration(time.Since(startTime)))
+	}
+	if timedOut {
+		reportNotReady(ctx, node, internalNodes)
+	}
+	return nil
+}
+
+// reportNotReady logs what is not ready in the cluster and why, and writes
+// a bundle of diagnostics for the not ready nodes
+func reportNotReady(ctx *actions.ActionContext, node nodes.Node, internalNodes []nodes.Node) {
+	collectCtx, cancel := context.WithTimeout(ctx.Context, diagnosticsTimeout)
+	defer cancel()
+	report := diagnostics.CollectCluster(collectCtx, ctx.Config, node, internalNodes)
+	dir, err := fs.TempDir("", "kind-diagnostics-")
+	if err == nil {
+		err = report.WriteBundle(dir)
+	}
+	if err != nil {
+		ctx.Logger.Warnf("failed to write diagnostics bundle: %v", err)
+	}
+	ctx.Logger.V(0).Info(report.Summary())
 }
 
 // helper that calls `try()`` in a loop until the deadline `until`
@@ -120,6 +142,7 @@ func tryUntil(until time.Time, try func() bool) bool {
 		if try() {
 			return true
 		}
+		time.Sleep(pollInterval)
 	}
 	return false
 }
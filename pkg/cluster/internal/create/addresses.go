@@ -0,0 +1,108 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"net"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// interfacePrefix marks an API server address as a host network interface name
+const interfacePrefix = "iface:"
+
+// interfaceAddrs returns the IP addresses of the named host network interface
+type interfaceAddrs func(name string) ([]net.IP, error)
+
+// hostInterfaceAddrs is the interfaceAddrs for the host running kind
+func hostInterfaceAddrs(name string) ([]net.IP, error) {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, err
+	}
+	ips := []net.IP{}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok {
+			ips = append(ips, ipNet.IP)
+		}
+	}
+	return ips, nil
+}
+
+// resolveAPIServerAddresses replaces the `iface:<name>` API server addresses
+// in cfg with an address of that interface in the cluster's IP family, so a
+// config can be shared between hosts with different addresses
+func resolveAPIServerAddresses(cfg *config.Cluster, lookup interfaceAddrs) error {
+	resolve := func(address string) (string, error) {
+		if !strings.HasPrefix(address, interfacePrefix) {
+			return address, nil
+		}
+		name := strings.TrimPrefix(address, interfacePrefix)
+		ips, err := lookup(name)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to get addresses of interface %q", name)
+		}
+		ip := interfaceIP(ips, cfg.Networking.IPFamily == config.IPv6Family)
+		if ip == nil {
+			return "", errors.Errorf("interface %q has no usable %s address", name, ipFamilyName(cfg))
+		}
+		return ip.String(), nil
+	}
+
+	address, err := resolve(cfg.Networking.APIServerAddress)
+	if err != nil {
+		return errors.Wrap(err, "invalid apiServerAddress")
+	}
+	cfg.Networking.APIServerAddress = address
+	for i, a := range cfg.Networking.AdditionalAPIServerAddresses {
+		address, err := resolve(a)
+		if err != nil {
+			return errors.Wrap(err, "invalid additionalAPIServerAddresses")
+		}
+		cfg.Networking.AdditionalAPIServerAddresses[i] = address
+	}
+	return nil
+}
+
+// interfaceIP picks the address to listen on from an interface's addresses,
+// link local addresses are skipped as they cannot be bound without a zone
+func interfaceIP(ips []net.IP, ipv6 bool) net.IP {
+	for _, ip := range ips {
+		if (ip.To4() == nil) != ipv6 {
+			continue
+		}
+		if ip.IsGlobalUnicast() || ip.IsLoopback() {
+			return ip
+		}
+	}
+	return nil
+}
+
+// ipFamilyName returns the IP family API server addresses are resolved in
+func ipFamilyName(cfg *config.Cluster) string {
+	if cfg.Networking.IPFamily == config.IPv6Family {
+		return "IPv6"
+	}
+	return "IPv4"
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"net"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestResolveAPIServerAddresses(t *testing.T) {
+	t.Parallel()
+	lookup := func(name string) ([]net.IP, error) {
+		switch name {
+		case "tailscale0":
+			return []net.IP{net.ParseIP("fe80::1"), net.ParseIP("100.64.0.2"), net.ParseIP("fd7a:115c:a1e0::2")}, nil
+		case "eth0":
+			return []net.IP{net.ParseIP("fe80::2"), net.ParseIP("2001:db8::2")}, nil
+		}
+		return nil, errors.New("no such network interface")
+	}
+	cases := []struct {
+		Name             string
+		IPFamily         config.ClusterIPFamily
+		Address          string
+		Additional       []string
+		ExpectAddress    string
+		ExpectAdditional []string
+		ExpectError      bool
+	}{
+		{
+			Name:          "IPs are kept",
+			IPFamily:      config.IPv4Family,
+			Address:       "127.0.0.1",
+			ExpectAddress: "127.0.0.1",
+		},
+		{
+			Name:             "IPv4 interfaces",
+			IPFamily:         config.IPv4Family,
+			Address:          "127.0.0.1",
+			Additional:       []string{"iface:tailscale0", "192.168.1.10"},
+			ExpectAddress:    "127.0.0.1",
+			ExpectAdditional: []string{"100.64.0.2", "192.168.1.10"},
+		},
+		{
+			Name:          "IPv6 interface skips link local addresses",
+			IPFamily:      config.IPv6Family,
+			Address:       "iface:eth0",
+			ExpectAddress: "2001:db8::2",
+		},
+		{
+			Name:        "interface without an address in the family",
+			IPFamily:    config.IPv4Family,
+			Address:     "iface:eth0",
+			ExpectError: true,
+		},
+		{
+			Name:        "missing interface",
+			IPFamily:    config.IPv4Family,
+			Address:     "127.0.0.1",
+			Additional:  []string{"iface:wg0"},
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			cfg := &config.Cluster{Networking: config.Networking{
+				IPFamily:                     tc.IPFamily,
+				APIServerAddress:             tc.Address,
+				AdditionalAPIServerAddresses: tc.Additional,
+			}}
+			err := resolveAPIServerAddresses(cfg, lookup)
+			assert.ExpectError(t, tc.ExpectError, err)
+			if tc.ExpectError {
+				return
+			}
+			assert.StringEqual(t, tc.ExpectAddress, cfg.Networking.APIServerAddress)
+			assert.DeepEqual(t, tc.ExpectAdditional, cfg.Networking.AdditionalAPIServerAddresses)
+		})
+	}
+}
@@ -17,36 +17,72 @@ limitations under the License.
 package create
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
+	"os"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/alessio/shellescape"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	"sigs.k8s.io/kind/pkg/cluster/internal/delete"
+	"sigs.k8s.io/kind/pkg/cluster/internal/diagnostics"
 	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/cluster/waiter"
 	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/fs"
 	"sigs.k8s.io/kind/pkg/internal/apis/config"
 	"sigs.k8s.io/kind/pkg/internal/apis/config/encoding"
 	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/internal/presets"
+	"sigs.k8s.io/kind/pkg/internal/warnings"
 	"sigs.k8s.io/kind/pkg/log"
 
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/apiserveronly"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/approvecsrs"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/clockoffset"
 	configaction "sigs.k8s.io/kind/pkg/cluster/internal/create/actions/config"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/configureoidc"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/configureregistries"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/configurestaticpods"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/configureswap"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/configuretrust"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/credentialproviders"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/inotify"
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/installcni"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/installgpu"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/installingress"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/installnodeproblemdetector"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/installobservability"
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/installstorage"
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/kubeadminit"
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/kubeadmjoin"
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/loadbalancer"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/loadimages"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/offline"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/persistentvolumes"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/recordimages"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/resetnodes"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/securityprofiles"
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/waitforready"
 	"sigs.k8s.io/kind/pkg/cluster/internal/kubeconfig"
+	"sigs.k8s.io/kind/pkg/cluster/internal/patch"
+	"sigs.k8s.io/kind/pkg/cluster/internal/state"
 )
 
 const (
 	// Typical host name max limit is 64 characters (https://linux.die.net/man/2/sethostname)
 	// We append -control-plane (14 characters) to the cluster name on the control plane container
 	clusterNameMax = 50
+	// diagnosticsTimeout bounds collecting diagnostics after a failure
+	diagnosticsTimeout = 30 * time.Second
 )
 
 // similar to valid docker container names, but since we will prefix
@@ -59,30 +95,68 @@ var validNameRE = regexp.MustCompile(`^[a-z0-9_.-]+$`)
 type ClusterOptions struct {
 	Config       *config.Cluster
 	NameOverride string // overrides config.Name
+	NamePrefix   string // prefixes the cluster name, after NameOverride
 	// NodeImage overrides the nodes' images in Config if non-zero
-	NodeImage      string
-	Retain         bool
-	WaitForReady   time.Duration
+	NodeImage    string
+	Retain       bool
+	WaitForReady time.Duration
+	// WaitFor are waited for after setup, instead of WaitForReady if set
+	WaitFor []waitforready.Target
+	// Waiters are waited for after WaitFor, instead of WaitForReady if set
+	Waiters        []waiter.Waiter
 	KubeconfigPath string
 	// see https://github.com/kubernetes-sigs/kind/issues/324
 	StopBeforeSettingUpKubernetes bool // if false kind should setup kubernetes after creating nodes
+	// KubeadmConfigMutators are applied to the generated kubeadm config
+	KubeadmConfigMutators []patch.Mutator
+	// RawConfig is the source of Config, if any, recorded in the cluster state
+	RawConfig string
+	// Labels are recorded in the cluster state
+	Labels map[string]string
+	// TTL is how long after creation the cluster expires, if set, the
+	// expiration is recorded in the cluster state
+	TTL time.Duration
+	// CloneSources are the nodes the config's node images were committed
+	// from, in order, for clusters cloned from another cluster. The Kubernetes
+	// state of the nodes is cleared and the images loaded into the sources
+	// are copied over before setting up Kubernetes
+	CloneSources []nodes.Node
+	// Presets are enabled in addition to any in Config
+	Presets []config.Preset
+	// Ingress is the ingress controller to install, see presets.IngressManifests,
+	// the first control-plane node is labeled for it and forwards ports 80 and 443
+	Ingress string
+	// Offline forbids pulling images or fetching anything remote, creation
+	// fails up front listing any images that are not available locally
+	Offline bool
+	// ImageArchives are loaded into the nodes before Kubernetes is set up
+	ImageArchives []string
+	// Resume continues a failed creation of the cluster from the last
+	// completed phase, and keeps the nodes if creation fails again
+	Resume bool
+	// Retry configures retrying transient provider failures, the zero value
+	// means common.DefaultRetryPolicy
+	Retry common.RetryPolicy
+	// LockWait is how long to wait for another process holding the
+	// cluster's lock, see state.Store.Lock
+	LockWait time.Duration
+	// KubeadmVerbosity is the kubeadm --v log level, nil means
+	// actions.DefaultKubeadmVerbosity
+	KubeadmVerbosity *int
+	// WarningsAsErrors fails creation on any warning about the config or host
+	WarningsAsErrors bool
 	// Options to control output
 	DisplayUsage      bool
 	DisplaySalutation bool
 }
 
-// Cluster creates a cluster
-func Cluster(logger log.Logger, p provider.Provider, opts *ClusterOptions) error {
+// Cluster creates a cluster, ctx bounds all of the work done
+func Cluster(ctx context.Context, logger log.Logger, p provider.Provider, opts *ClusterOptions) error {
 	// default / process options (namely config)
 	if err := fixupOptions(opts); err != nil {
 		return err
 	}
 
-	// Check if the cluster name already exists
-	if err := alreadyExists(p, opts.Config.Name); err != nil {
-		return err
-	}
-
 	// TODO: move to config validation
 	// validate the name
 	if !validNameRE.MatchString(opts.Config.Name) {
@@ -91,9 +165,43 @@ func Cluster(logger log.Logger, p provider.Provider, opts *ClusterOptions) error
 			opts.Config.Name, validNameRE.String(),
 		)
 	}
+
+	// only one kind process may operate on a cluster at a time
+	unlock, err := state.Default().Lock(ctx, opts.Config.Name, opts.LockWait)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if opts.Retry.Attempts > 0 {
+		ctx = common.WithRetryPolicy(ctx, opts.Retry)
+	}
+	recorder := warnings.NewRecorder(opts.WarningsAsErrors)
+	ctx = warnings.WithRecorder(ctx, recorder)
+
+	// a failed creation may be resumed when its nodes were kept
+	var progress *state.Progress
+	if opts.Resume {
+		if progress, err = resumeProgress(ctx, p, opts); err != nil {
+			return err
+		}
+	}
+
+	// Check if the cluster name already exists
+	if progress == nil {
+		if err := alreadyExists(ctx, p, opts.Config.Name); err != nil {
+			return err
+		}
+	}
+
 	// warn if cluster name might typically be too long
 	if len(opts.Config.Name) > clusterNameMax {
-		logger.Warnf("cluster name %q is probably too long, this might not work properly on some systems", opts.Config.Name)
+		if err := warnings.Report(ctx, logger, warnings.Warning{
+			Source:  "config",
+			Message: fmt.Sprintf("cluster name %q is probably too long, this might not work properly on some systems", opts.Config.Name),
+		}); err != nil {
+			return err
+		}
 	}
 
 	// then validate
@@ -101,53 +209,112 @@ func Cluster(logger log.Logger, p provider.Provider, opts *ClusterOptions) error
 		return err
 	}
 
+	// fail before creating anything if we'd need the network
+	if opts.Offline {
+		if err := checkOffline(ctx, p, opts); err != nil {
+			return err
+		}
+	}
+
 	// setup a status object to show progress to the user
 	status := cli.StatusForLogger(logger)
 
-	// we're going to start creating now, tell the user
-	logger.V(0).Infof("Creating cluster %q ...\n", opts.Config.Name)
+	if progress != nil {
+		// the nodes were provisioned by the failed creation
+		logger.V(0).Infof("Resuming creating cluster %q ...\n", opts.Config.Name)
+	} else {
+		// pick random host ports up front so they can be recorded, preferring
+		// those chosen for a previous cluster of the same name
+		previous := previousPorts(logger, opts.Config.Name)
+		ports, err := resolvePorts(opts.Config, previous, common.IsPortFree, common.GetFreePort)
+		if err != nil {
+			return err
+		}
+		// rootless runtimes cannot publish privileged ports, forward them
+		if err := forwardRootlessPorts(ctx, logger, p, opts.Config, previous, ports); err != nil {
+			return err
+		}
+
+		// we're going to start creating now, tell the user
+		logger.V(0).Infof("Creating cluster %q ...\n", opts.Config.Name)
 
-	// Create node containers implementing defined config Nodes
-	if err := p.Provision(status, opts.Config); err != nil {
-		// In case of errors nodes are deleted (except if retain is explicitly set)
-		if !opts.Retain {
-			_ = delete.Cluster(logger, p, opts.Config.Name, opts.KubeconfigPath)
+		// Create node containers implementing defined config Nodes
+		if err := p.Provision(ctx, status, opts.Config); err != nil {
+			// In case of errors nodes are deleted (except if retain is explicitly set)
+			// NOTE: this uses a fresh context, ctx may have been cancelled
+			if !opts.Retain {
+				_ = delete.Cluster(context.Background(), logger, p, opts.Config.Name, opts.KubeconfigPath)
+			}
+			return err
+		}
+		if err := state.Default().WritePorts(opts.Config.Name, ports); err != nil {
+			logger.Warnf("failed to record cluster ports: %v", err)
+		}
+		progress = &state.Progress{
+			Config:    opts.RawConfig,
+			NodeImage: opts.NodeImage,
 		}
-		return err
 	}
 
-	// TODO(bentheelder): make this controllable from the command line?
-	actionsToRun := []actions.Action{
-		loadbalancer.NewAction(), // setup external loadbalancer
-		configaction.NewAction(), // setup kubeadm config
-	}
-	if !opts.StopBeforeSettingUpKubernetes {
-		actionsToRun = append(actionsToRun,
-			kubeadminit.NewAction(), // run kubeadm init
-		)
-		// this step might be skipped, but is next after init
-		if !opts.Config.Networking.DisableDefaultCNI {
-			actionsToRun = append(actionsToRun,
-				installcni.NewAction(), // install CNI
-			)
-		}
-		// add remaining steps
-		actionsToRun = append(actionsToRun,
-			installstorage.NewAction(),                // install StorageClass
-			kubeadmjoin.NewAction(),                   // run kubeadm join
-			waitforready.NewAction(opts.WaitForReady), // wait for cluster readiness
-		)
+	kubeadmVerbosity := actions.DefaultKubeadmVerbosity
+	if opts.KubeadmVerbosity != nil {
+		kubeadmVerbosity = *opts.KubeadmVerbosity
 	}
 
+	actionsToRun := clusterActions(opts, kubeadmVerbosity)
+
 	// run all actions
-	actionsContext := actions.NewActionContext(logger, status, p, opts.Config)
+	actionsContext := actions.NewActionContext(ctx, logger, status, p, opts.Config)
+	completed := sets.NewString(progress.Completed...)
 	for _, action := range actionsToRun {
+		name := actionName(action)
+		if completed.Has(name) {
+			logger.V(1).Infof("Skipping %s, it completed before resuming", name)
+			continue
+		}
 		if err := action.Execute(actionsContext); err != nil {
-			if !opts.Retain {
-				_ = delete.Cluster(logger, p, opts.Config.Name, opts.KubeconfigPath)
+			// diagnostics must be collected before the nodes are deleted
+			err = collectDiagnostics(actionsContext, err)
+			if opts.Resume {
+				logger.V(0).Infof("Kept the nodes, resume creating the cluster to continue from %s", name)
+			} else if !opts.Retain {
+				_ = delete.Cluster(context.Background(), logger, p, opts.Config.Name, opts.KubeconfigPath)
 			}
 			return err
 		}
+		// record progress so a failure after this point can be resumed
+		progress.Completed = append(progress.Completed, name)
+		if err := state.Default().WriteProgress(opts.Config.Name, progress); err != nil {
+			logger.Warnf("failed to record cluster creation progress: %v", err)
+		}
+	}
+	if err := state.Default().RemoveProgress(opts.Config.Name); err != nil {
+		logger.Warnf("failed to remove cluster creation progress: %v", err)
+	}
+
+	// record the cluster in the local state store, this is best effort
+	recorded := &state.Cluster{
+		Name:              opts.Config.Name,
+		Provider:          p.String(),
+		CreationTimestamp: time.Now().UTC(),
+		Config:            opts.RawConfig,
+		NodeImage:         opts.NodeImage,
+		Labels:            opts.Labels,
+	}
+	if opts.TTL > 0 {
+		expiration := recorded.CreationTimestamp.Add(opts.TTL)
+		recorded.ExpirationTimestamp = &expiration
+	}
+	if err := state.Default().Write(recorded); err != nil {
+		logger.Warnf("failed to record cluster state: %v", err)
+	}
+
+	// repeat the warnings, they are easily missed amongst the progress output
+	if reported := recorder.Warnings(); len(reported) > 0 {
+		logger.V(0).Infof("Created cluster %q with %d warning(s):", opts.Config.Name, len(reported))
+		for _, w := range reported {
+			logger.V(0).Infof(" • %s", w)
+		}
 	}
 
 	// skip the rest if we're not setting up kubernetes
@@ -155,7 +322,13 @@ func Cluster(logger log.Logger, p provider.Provider, opts *ClusterOptions) error
 		return nil
 	}
 
-	if err := kubeconfig.Export(p, opts.Config.Name, opts.KubeconfigPath); err != nil {
+	// there is no kubeconfig to export for a control plane we don't manage
+	if endpoint := opts.Config.ExternalControlPlane.Endpoint; endpoint != "" {
+		logger.V(0).Infof("Joined nodes to the external control plane at %s", endpoint)
+		return nil
+	}
+
+	if err := kubeconfig.Export(ctx, p, opts.Config.Name, opts.KubeconfigPath); err != nil {
 		return err
 	}
 
@@ -171,10 +344,96 @@ func Cluster(logger log.Logger, p provider.Provider, opts *ClusterOptions) error
 	return nil
 }
 
+// resumeProgress returns the progress of a failed creation of the cluster
+// in opts, or nil if there is nothing to resume
+func resumeProgress(ctx context.Context, p provider.Provider, opts *ClusterOptions) (*state.Progress, error) {
+	progress, err := state.Default().ReadProgress(opts.Config.Name)
+	if err != nil || progress == nil {
+		return nil, err
+	}
+	if progress.Config != opts.RawConfig || progress.NodeImage != opts.NodeImage {
+		return nil, errors.Errorf(
+			"the config for cluster %q has changed since creating it failed, delete the cluster to start over",
+			opts.Config.Name,
+		)
+	}
+	n, err := p.ListNodes(ctx, opts.Config.Name)
+	if err != nil {
+		return nil, err
+	}
+	// the nodes were deleted since, start over
+	if len(n) == 0 {
+		return nil, state.Default().RemoveProgress(opts.Config.Name)
+	}
+	return progress, nil
+}
+
+// actionName returns the name of the package implementing a, e.g. "kubeadminit",
+// which identifies the phase in the recorded progress
+func actionName(a actions.Action) string {
+	name := strings.TrimPrefix(fmt.Sprintf("%T", a), "*")
+	return strings.SplitN(name, ".", 2)[0]
+}
+
+// collectDiagnostics annotates err with diagnostics from the node that failed,
+// or the bootstrap control plane node if the failing node is unknown
+func collectDiagnostics(ac *actions.ActionContext, err error) error {
+	n := diagnostics.NodeForError(err)
+	if n == nil {
+		allNodes, lerr := ac.Nodes()
+		if lerr != nil {
+			return err
+		}
+		if n, lerr = nodeutils.BootstrapControlPlaneNode(allNodes); lerr != nil {
+			return err
+		}
+	}
+	// NOTE: this uses a fresh context, ctx may have been cancelled
+	ctx, cancel := context.WithTimeout(context.Background(), diagnosticsTimeout)
+	defer cancel()
+	report := diagnostics.Collect(ctx, n)
+	dir, derr := fs.TempDir("", "kind-diagnostics-")
+	if derr == nil {
+		derr = report.WriteBundle(dir)
+	}
+	if derr != nil {
+		ac.Logger.Warnf("failed to write diagnostics bundle: %v", derr)
+	}
+	return diagnostics.WithReport(err, report)
+}
+
+// checkOffline returns an error if creating the cluster would fetch anything
+// remote that can be checked before provisioning, other than images on the
+// nodes which are checked once they are created
+func checkOffline(ctx context.Context, p provider.Provider, opts *ClusterOptions) error {
+	if opts.Ingress != "" && opts.Ingress != presets.IngressNone {
+		return errors.Errorf("offline mode does not support fetching the %s ingress manifest", opts.Ingress)
+	}
+	if provisioner := opts.Config.Storage.Provisioner; provisioner != config.LocalPathProvisioner && provisioner != config.NoneProvisioner {
+		return errors.Errorf("offline mode does not support fetching storage.provisioner %s", provisioner)
+	}
+	for _, archive := range opts.ImageArchives {
+		if _, err := os.Stat(archive); err != nil {
+			return errors.Wrap(err, "invalid image archive")
+		}
+	}
+	missing, err := p.MissingImages(ctx, opts.Config)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return errors.WithReason(
+			errors.Errorf("offline mode requires these images to be present locally:\n%s", strings.Join(missing, "\n")),
+			errors.ErrImagesMissing,
+		)
+	}
+	return nil
+}
+
 // alreadyExists returns an error if the cluster name already exists
 // or if we had an error checking
-func alreadyExists(p provider.Provider, name string) error {
-	n, err := p.ListNodes(name)
+func alreadyExists(ctx context.Context, p provider.Provider, name string) error {
+	n, err := p.ListNodes(ctx, name)
 	if err != nil {
 		return err
 	}
@@ -222,6 +481,9 @@ func fixupOptions(opts *ClusterOptions) error {
 	if opts.NameOverride != "" {
 		opts.Config.Name = opts.NameOverride
 	}
+	if opts.NamePrefix != "" {
+		opts.Config.Name = opts.NamePrefix + "-" + opts.Config.Name
+	}
 
 	// if NodeImage was set, override the image on all nodes
 	if opts.NodeImage != "" {
@@ -233,9 +495,323 @@ func fixupOptions(opts *ClusterOptions) error {
 		}
 	}
 
+	// enable any additional presets
+	for _, p := range opts.Presets {
+		if !hasPreset(opts.Config, p) {
+			opts.Config.Presets = append(opts.Config.Presets, p)
+		}
+	}
+
 	// default config fields (important for usage as a library, where the config
 	// may be constructed in memory rather than from disk)
 	config.SetDefaultsCluster(opts.Config)
 
+	// listen on the current addresses of any named host interfaces
+	if err := resolveAPIServerAddresses(opts.Config, hostInterfaceAddrs); err != nil {
+		return err
+	}
+
+	if opts.Ingress != "" && opts.Ingress != presets.IngressNone {
+		// only full clusters run the ingress controller
+		if opts.Config.ControlPlaneMode == config.APIServerOnlyMode {
+			return errors.New("--ingress is not supported with controlPlaneMode APIServerOnly")
+		}
+		if opts.Config.ExternalControlPlane.Endpoint != "" {
+			return errors.New("--ingress is not supported with an externalControlPlane")
+		}
+		setupIngressNode(opts.Config)
+	}
+
+	// mount the API server config files into the control plane nodes
+	mounts, err := configaction.APIServerConfigMounts(opts.Config)
+	if err != nil {
+		return err
+	}
+	for i := range opts.Config.Nodes {
+		if n := &opts.Config.Nodes[i]; n.Role == config.ControlPlaneRole {
+			n.ExtraMounts = append(n.ExtraMounts, mounts...)
+		}
+	}
+
+	// read the extra CA and registry CA files now, before any nodes are created
+	if err := configuretrust.Resolve(opts.Config); err != nil {
+		return err
+	}
+	if err := configureregistries.Resolve(opts.Config); err != nil {
+		return err
+	}
+
+	// mount the seccomp profiles and credential provider binaries into every node
+	seccompMounts, err := securityprofiles.Mounts(opts.Config)
+	if err != nil {
+		return err
+	}
+	providerMounts, err := credentialproviders.Mounts(opts.Config)
+	if err != nil {
+		return err
+	}
+	for i := range opts.Config.Nodes {
+		n := &opts.Config.Nodes[i]
+		n.ExtraMounts = append(n.ExtraMounts, seccompMounts...)
+		n.ExtraMounts = append(n.ExtraMounts, providerMounts...)
+	}
+
 	return nil
 }
+
+// setupIngressNode labels the first control-plane node as ingress ready
+// and forwards presets.IngressPorts to it, unless already forwarded
+func setupIngressNode(cfg *config.Cluster) {
+	for i := range cfg.Nodes {
+		n := &cfg.Nodes[i]
+		if n.Role != config.ControlPlaneRole {
+			continue
+		}
+		if n.Labels == nil {
+			n.Labels = map[string]string{}
+		}
+		n.Labels[presets.IngressReadyLabel] = "true"
+		for _, port := range presets.IngressPorts {
+			if !forwardsPort(n, port) {
+				n.ExtraPortMappings = append(n.ExtraPortMappings, config.PortMapping{
+					ContainerPort: port,
+					HostPort:      port,
+					Protocol:      config.PortMappingProtocolTCP,
+				})
+			}
+		}
+		return
+	}
+}
+
+// forwardsPort returns true if n already forwards containerPort from the host
+func forwardsPort(n *config.Node, containerPort int32) bool {
+	for _, m := range n.ExtraPortMappings {
+		if m.ContainerPort == containerPort {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPreset returns true if preset is enabled in cfg
+func hasPreset(cfg *config.Cluster, preset config.Preset) bool {
+	for _, p := range cfg.Presets {
+		if p == preset {
+			return true
+		}
+	}
+	return false
+}
+
+// previousPorts returns the ports recorded for a previous cluster named name
+func previousPorts(logger log.Logger, name string) *state.Ports {
+	ports, err := state.Default().ReadPorts(name)
+	if err != nil {
+		logger.Warnf("ignoring previously recorded ports: %v", err)
+	}
+	return ports
+}
+
+// clusterHasGPUs returns true if any node in cfg requests GPUs
+func clusterHasGPUs(cfg *config.Cluster) bool {
+	for _, n := range cfg.Nodes {
+		if n.GPUs != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// clusterHasSwap returns true if any node in cfg requests swap
+func clusterHasSwap(cfg *config.Cluster) bool {
+	for _, n := range cfg.Nodes {
+		if n.Swap.Size != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// clusterHasClockOffset returns true if any node in cfg requests a clock offset
+func clusterHasClockOffset(cfg *config.Cluster) bool {
+	for _, n := range cfg.Nodes {
+		if n.ClockOffset != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// clusterHasPersistentVolumes returns true if any node in cfg declares PersistentVolumes
+func clusterHasPersistentVolumes(cfg *config.Cluster) bool {
+	for _, n := range cfg.Nodes {
+		if len(n.PersistentVolumes) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// waitTargets returns what to wait for after setup, WaitFor then Waiters or
+// else the control plane for WaitForReady
+func waitTargets(opts *ClusterOptions) []waiter.Waiter {
+	waiters := []waiter.Waiter{}
+	for _, t := range opts.WaitFor {
+		waiters = append(waiters, t.Waiter())
+	}
+	waiters = append(waiters, opts.Waiters...)
+	if len(waiters) == 0 && opts.WaitForReady > 0 {
+		waiters = append(waiters, waiter.ControlPlane().WithTimeout(opts.WaitForReady).WithWarnOnly())
+	}
+	return waiters
+}
+
+// clusterActions returns the actions to run after provisioning the nodes
+// to set up the cluster for opts
+func clusterActions(opts *ClusterOptions, kubeadmVerbosity int) []actions.Action {
+	// TODO(bentheelder): make this controllable from the command line?
+	actionsToRun := []actions.Action{}
+	if len(opts.CloneSources) > 0 {
+		actionsToRun = append(actionsToRun,
+			resetnodes.NewAction(opts.CloneSources), // clear state from the cloned cluster
+		)
+	}
+	actionsToRun = append(actionsToRun,
+		loadbalancer.NewAction(), // setup external loadbalancer
+	)
+	actionsToRun = append(actionsToRun, NodeSetupActions(opts.Config, opts.KubeadmConfigMutators)...)
+	if opts.Config.Authentication.OIDC.IssuerURL != "" || opts.Config.Authentication.OIDC.TestIssuer {
+		actionsToRun = append(actionsToRun,
+			configureoidc.NewAction(), // setup OIDC issuer and CA
+		)
+	}
+	if len(opts.ImageArchives) > 0 {
+		actionsToRun = append(actionsToRun,
+			loadimages.NewAction(opts.ImageArchives), // load user provided images
+		)
+	}
+	if opts.Offline && !opts.StopBeforeSettingUpKubernetes {
+		actionsToRun = append(actionsToRun,
+			offline.NewAction(), // check nothing will be pulled
+		)
+	}
+	if !opts.StopBeforeSettingUpKubernetes && opts.Config.ControlPlaneMode == config.APIServerOnlyMode {
+		actionsToRun = append(actionsToRun,
+			apiserveronly.NewAction(kubeadmVerbosity), // run only etcd and the API server
+		)
+	} else if !opts.StopBeforeSettingUpKubernetes && opts.Config.ExternalControlPlane.Endpoint != "" {
+		actionsToRun = append(actionsToRun,
+			kubeadmjoin.NewAction(kubeadmVerbosity), // join the external control plane
+		)
+	} else if !opts.StopBeforeSettingUpKubernetes {
+		actionsToRun = append(actionsToRun,
+			kubeadminit.NewAction(kubeadmVerbosity), // run kubeadm init
+		)
+		// this step might be skipped, but is next after init
+		if !opts.Config.Networking.DisableDefaultCNI {
+			actionsToRun = append(actionsToRun,
+				installcni.NewAction(), // install CNI
+			)
+		}
+		// optional presets are installed right after the CNI
+		if hasPreset(opts.Config, config.ObservabilityPreset) {
+			actionsToRun = append(actionsToRun,
+				installobservability.NewAction(), // install metrics-server etc.
+			)
+		}
+		if hasPreset(opts.Config, config.NodeProblemDetectorPreset) {
+			actionsToRun = append(actionsToRun,
+				installnodeproblemdetector.NewAction(), // install node-problem-detector
+			)
+		}
+		if opts.Ingress != "" && opts.Ingress != presets.IngressNone {
+			actionsToRun = append(actionsToRun,
+				installingress.NewAction(opts.Ingress), // install ingress controller
+			)
+		}
+		// storage may be disabled entirely when users bring their own
+		if opts.Config.Storage.Provisioner != config.NoneProvisioner || len(opts.Config.Storage.ExtraStorageClasses) > 0 {
+			actionsToRun = append(actionsToRun,
+				installstorage.NewAction(), // install StorageClass
+			)
+		}
+		if clusterHasPersistentVolumes(opts.Config) {
+			actionsToRun = append(actionsToRun,
+				persistentvolumes.NewAction(), // pre-provision PersistentVolumes
+			)
+		}
+		// the device plugin is opt-in and only useful with GPU nodes
+		if opts.Config.GPUDevicePlugin && clusterHasGPUs(opts.Config) {
+			actionsToRun = append(actionsToRun,
+				installgpu.NewAction(), // install GPU device plugin
+			)
+		}
+		// add remaining steps
+		actionsToRun = append(actionsToRun,
+			kubeadmjoin.NewAction(kubeadmVerbosity), // run kubeadm join
+		)
+		if opts.Config.KubeletServerTLSBootstrap {
+			actionsToRun = append(actionsToRun,
+				approvecsrs.NewAction(), // approve kubelet serving certificates
+			)
+		}
+		if clusterHasSwap(opts.Config) {
+			actionsToRun = append(actionsToRun,
+				configureswap.NewAction(), // enable swap on nodes
+			)
+		}
+		// clocks are shifted only after kubeadm has issued certificates
+		if clusterHasClockOffset(opts.Config) {
+			actionsToRun = append(actionsToRun,
+				clockoffset.NewAction(), // shift node clocks
+			)
+		}
+		actionsToRun = append(actionsToRun,
+			waitforready.NewAction(waitTargets(opts)), // wait for cluster readiness
+		)
+	}
+	return actionsToRun
+}
+
+// NodeSetupActions returns the actions that configure the nodes before
+// Kubernetes is set up on them, starting with writing the kubeadm config.
+// They act on the nodes listed by the action context, so they also set up
+// nodes added to an existing cluster
+func NodeSetupActions(cfg *config.Cluster, mutators []patch.Mutator) []actions.Action {
+	actionsToRun := []actions.Action{
+		recordimages.NewAction(),         // record the preloaded images
+		configaction.NewAction(mutators), // setup kubeadm config
+	}
+	if len(cfg.Trust.ExtraCAs) > 0 {
+		actionsToRun = append(actionsToRun,
+			configuretrust.NewAction(), // install extra CA certificates
+		)
+	}
+	if len(cfg.Registries) > 0 {
+		actionsToRun = append(actionsToRun,
+			configureregistries.NewAction(), // write registry host files
+		)
+	}
+	if len(cfg.CredentialProviders.Providers) > 0 {
+		actionsToRun = append(actionsToRun,
+			credentialproviders.NewAction(), // configure kubelet credential providers
+		)
+	}
+	if inotify.Needed(cfg) {
+		actionsToRun = append(actionsToRun,
+			inotify.NewAction(), // raise the host inotify limits
+		)
+	}
+	if len(cfg.SecurityProfiles.AppArmor) > 0 {
+		actionsToRun = append(actionsToRun,
+			securityprofiles.NewAction(), // load AppArmor profiles
+		)
+	}
+	if configurestaticpods.Needed(cfg) {
+		actionsToRun = append(actionsToRun,
+			configurestaticpods.NewAction(), // link passthrough static pods
+		)
+	}
+	return actionsToRun
+}
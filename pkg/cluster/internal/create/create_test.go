@@ -0,0 +1,116 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestSetupIngressNode(t *testing.T) {
+	t.Parallel()
+	cfg := &config.Cluster{
+		Nodes: []config.Node{
+			{Role: config.WorkerRole},
+			{
+				Role: config.ControlPlaneRole,
+				ExtraPortMappings: []config.PortMapping{
+					{ContainerPort: 443, HostPort: 8443},
+				},
+			},
+			{Role: config.ControlPlaneRole},
+		},
+	}
+	setupIngressNode(cfg)
+	expected := config.Node{
+		Role:   config.ControlPlaneRole,
+		Labels: map[string]string{"ingress-ready": "true"},
+		ExtraPortMappings: []config.PortMapping{
+			// an existing mapping is kept
+			{ContainerPort: 443, HostPort: 8443},
+			{ContainerPort: 80, HostPort: 80, Protocol: config.PortMappingProtocolTCP},
+		},
+	}
+	assert.DeepEqual(t, expected, cfg.Nodes[1])
+	for _, i := range []int{0, 2} {
+		if len(cfg.Nodes[i].Labels) != 0 || len(cfg.Nodes[i].ExtraPortMappings) != 0 {
+			t.Errorf("expected node %d to be unchanged, got %+v", i, cfg.Nodes[i])
+		}
+	}
+}
+
+func TestClusterActionsExternalControlPlane(t *testing.T) {
+	t.Parallel()
+	// every node is a worker when joining an external control plane
+	cfg := &config.Cluster{
+		ExternalControlPlane: config.ExternalControlPlane{
+			Endpoint: "10.0.0.1:6443",
+			Token:    "abcdef.0123456789abcdef",
+		},
+		Nodes: []config.Node{
+			{Role: config.WorkerRole},
+			{Role: config.WorkerRole},
+		},
+	}
+	config.SetDefaultsCluster(cfg)
+	assert.ExpectError(t, false, cfg.Validate())
+	names := []string{}
+	for _, a := range clusterActions(&ClusterOptions{Config: cfg}, 0) {
+		names = append(names, actionName(a))
+	}
+	assert.DeepEqual(t, []string{"loadbalancer", "recordimages", "config", "kubeadmjoin"}, names)
+}
+
+func TestFixupOptionsIngress(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name        string
+		Config      *config.Cluster
+		ExpectError bool
+	}{
+		{
+			Name:   "full cluster",
+			Config: &config.Cluster{Nodes: []config.Node{{Role: config.ControlPlaneRole}}},
+		},
+		{
+			Name: "API server only",
+			Config: &config.Cluster{
+				ControlPlaneMode: config.APIServerOnlyMode,
+				Nodes:            []config.Node{{Role: config.ControlPlaneRole}},
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "external control plane",
+			Config: &config.Cluster{
+				ExternalControlPlane: config.ExternalControlPlane{Endpoint: "10.0.0.1:6443"},
+				Nodes:                []config.Node{{Role: config.WorkerRole}},
+			},
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			err := fixupOptions(&ClusterOptions{Config: tc.Config, Ingress: "nginx"})
+			assert.ExpectError(t, tc.ExpectError, err)
+		})
+	}
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
+	"sigs.k8s.io/kind/pkg/cluster/internal/state"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/portforward"
+	"sigs.k8s.io/kind/pkg/internal/warnings"
+)
+
+// unprivilegedPortStartPath is the sysctl setting the first port
+// unprivileged processes may bind
+const unprivilegedPortStartPath = "/proc/sys/net/ipv4/ip_unprivileged_port_start"
+
+// unprivilegedPortStart returns the first host port a rootless container
+// runtime may publish, 1024 unless the sysctl says otherwise
+func unprivilegedPortStart() int32 {
+	contents, err := ioutil.ReadFile(unprivilegedPortStartPath)
+	if err != nil {
+		return 1024
+	}
+	port, err := strconv.ParseInt(strings.TrimSpace(string(contents)), 10, 32)
+	if err != nil {
+		return 1024
+	}
+	return int32(port)
+}
+
+// forwardRootlessPorts records in ports the privileged host ports of cfg
+// that are published elsewhere because the container runtime is rootless,
+// and tells the user how to run `kind forward` for them
+func forwardRootlessPorts(ctx context.Context, logger log.Logger, p provider.Provider, cfg *config.Cluster, previous, ports *state.Ports) error {
+	info, err := p.Info(ctx)
+	if err != nil {
+		logger.V(1).Infof("not checking for a rootless container runtime: %v", err)
+		return nil
+	}
+	if !info.Rootless {
+		return nil
+	}
+	portStart := unprivilegedPortStart()
+	forwards, err := forwardPrivilegedPorts(cfg, portStart, previous, ports, common.IsPortFree, common.GetFreePort)
+	if err != nil || len(forwards) == 0 {
+		return err
+	}
+	ports.Forwards = forwards
+	args := []string{"kind", "forward"}
+	for _, f := range toPortForwards(forwards) {
+		args = append(args, "--port", f.String())
+	}
+	return warnings.Report(ctx, logger, warnings.Warning{
+		Source: "ports",
+		Message: fmt.Sprintf(
+			"the container runtime is rootless and cannot publish host ports below %d, they are published on loopback ports instead.\n"+
+				"To listen on the requested ports run:\n  sudo %s\n"+
+				"See `kind forward --help` to run this with systemd socket activation instead.",
+			portStart, strings.Join(args, " "),
+		),
+	})
+}
+
+// forwardPrivilegedPorts publishes the node port mappings in cfg with a host
+// port below portStart on a free loopback port instead, preferring those
+// forwarded for previous, and returns the forwards from the requested ports.
+// Ports already in chosen are not picked.
+func forwardPrivilegedPorts(cfg *config.Cluster, portStart int32, previous, chosen *state.Ports, isFree portChecker, pick portPicker) ([]state.Forward, error) {
+	if previous == nil {
+		previous = &state.Ports{}
+	}
+	used := map[int32]bool{chosen.APIServer: true}
+	for _, m := range chosen.Mappings {
+		used[m.HostPort] = true
+	}
+	forwards := []state.Forward{}
+	for i := range cfg.Nodes {
+		for j := range cfg.Nodes[i].ExtraPortMappings {
+			pm := &cfg.Nodes[i].ExtraPortMappings[j]
+			if pm.HostPort <= 0 || pm.HostPort >= portStart {
+				continue
+			}
+			if protocol(pm) != string(config.PortMappingProtocolTCP) {
+				return nil, errors.Errorf(
+					"host port %d/%s is below %d, which the rootless container runtime cannot publish, only TCP ports can be forwarded",
+					pm.HostPort, protocol(pm), portStart,
+				)
+			}
+			f := state.Forward{
+				ListenAddress: listenAddress(cfg, pm),
+				HostPort:      pm.HostPort,
+				TargetAddress: "127.0.0.1",
+			}
+			if ip := net.ParseIP(f.ListenAddress); ip != nil && ip.To4() == nil {
+				f.TargetAddress = "::1"
+			}
+			f.TargetPort = previousTargetPort(previous, f)
+			if f.TargetPort == 0 || used[f.TargetPort] || !isFree(f.TargetPort, f.TargetAddress) {
+				port, err := pickUnused(f.TargetAddress, used, pick)
+				if err != nil {
+					return nil, errors.Wrapf(err, "failed to pick a port to forward host port %d to", pm.HostPort)
+				}
+				f.TargetPort = port
+			}
+			used[f.TargetPort] = true
+			pm.ListenAddress = f.TargetAddress
+			pm.HostPort = f.TargetPort
+			forwards = append(forwards, f)
+		}
+	}
+	return forwards, nil
+}
+
+// previousTargetPort returns the port previously forwarded to for the same
+// host port, or zero if there is none
+func previousTargetPort(previous *state.Ports, f state.Forward) int32 {
+	for _, p := range previous.Forwards {
+		if p.ListenAddress == f.ListenAddress && p.HostPort == f.HostPort && p.TargetAddress == f.TargetAddress {
+			return p.TargetPort
+		}
+	}
+	return 0
+}
+
+// pickUnused returns a free port on address that is not in used
+func pickUnused(address string, used map[int32]bool, pick portPicker) (int32, error) {
+	for i := 0; i < 10; i++ {
+		p, err := pick(address)
+		if err != nil {
+			return 0, err
+		}
+		if !used[p] {
+			return p, nil
+		}
+	}
+	return 0, errors.Errorf("failed to get an unused random host port on %q", address)
+}
+
+// toPortForwards converts forwards to the `kind forward` representation
+func toPortForwards(forwards []state.Forward) []portforward.Forward {
+	out := make([]portforward.Forward, 0, len(forwards))
+	for _, f := range forwards {
+		out = append(out, portforward.Forward{
+			Listen: net.JoinHostPort(f.ListenAddress, fmt.Sprint(f.HostPort)),
+			Target: net.JoinHostPort(f.TargetAddress, fmt.Sprint(f.TargetPort)),
+		})
+	}
+	return out
+}
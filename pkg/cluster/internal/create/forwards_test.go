@@ -0,0 +1,86 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/state"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestForwardPrivilegedPorts(t *testing.T) {
+	t.Parallel()
+	cfg := &config.Cluster{
+		Nodes: []config.Node{
+			{
+				Role: config.ControlPlaneRole,
+				ExtraPortMappings: []config.PortMapping{
+					{ContainerPort: 80, HostPort: 80},
+					{ContainerPort: 443, HostPort: 443, ListenAddress: "::"},
+					{ContainerPort: 8080, HostPort: 8080},
+				},
+			},
+		},
+	}
+	previous := &state.Ports{
+		Forwards: []state.Forward{
+			{ListenAddress: "0.0.0.0", HostPort: 80, TargetAddress: "127.0.0.1", TargetPort: 40080},
+		},
+	}
+	// the first pick collides with the API server port and must be skipped
+	chosen := &state.Ports{APIServer: 40000}
+	picks := []int32{40000, 40443}
+	pick := func(string) (int32, error) {
+		p := picks[0]
+		picks = picks[1:]
+		return p, nil
+	}
+	isFree := func(int32, string) bool { return true }
+
+	forwards, err := forwardPrivilegedPorts(cfg, 1024, previous, chosen, isFree, pick)
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, []state.Forward{
+		{ListenAddress: "0.0.0.0", HostPort: 80, TargetAddress: "127.0.0.1", TargetPort: 40080},
+		{ListenAddress: "::", HostPort: 443, TargetAddress: "::1", TargetPort: 40443},
+	}, forwards)
+	assert.DeepEqual(t, []config.PortMapping{
+		{ContainerPort: 80, HostPort: 40080, ListenAddress: "127.0.0.1"},
+		{ContainerPort: 443, HostPort: 40443, ListenAddress: "::1"},
+		{ContainerPort: 8080, HostPort: 8080},
+	}, cfg.Nodes[0].ExtraPortMappings)
+	assert.StringEqual(t, "[::]:443=[::1]:40443", toPortForwards(forwards)[1].String())
+}
+
+func TestForwardPrivilegedPortsUDP(t *testing.T) {
+	t.Parallel()
+	cfg := &config.Cluster{
+		Nodes: []config.Node{
+			{
+				Role: config.ControlPlaneRole,
+				ExtraPortMappings: []config.PortMapping{
+					{ContainerPort: 53, HostPort: 53, Protocol: config.PortMappingProtocolUDP},
+				},
+			},
+		},
+	}
+	pick := func(string) (int32, error) { return 40053, nil }
+	isFree := func(int32, string) bool { return true }
+	_, err := forwardPrivilegedPorts(cfg, 1024, nil, &state.Ports{}, isFree, pick)
+	assert.ExpectError(t, true, err)
+}
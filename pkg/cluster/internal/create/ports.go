@@ -0,0 +1,142 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/state"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// portChecker reports whether a host port is free, see common.IsPortFree
+type portChecker func(port int32, listenAddr string) bool
+
+// portPicker returns a free host port, see common.GetFreePort
+type portPicker func(listenAddr string) (int32, error)
+
+// resolvePorts replaces the random (zero) host ports in cfg with concrete
+// ports, reusing those in previous when they are still free so that ports
+// are stable when a cluster is recreated. All of the host ports are returned
+// to be recorded for reporting and the next creation.
+func resolvePorts(cfg *config.Cluster, previous *state.Ports, isFree portChecker, pick portPicker) (*state.Ports, error) {
+	if previous == nil {
+		previous = &state.Ports{}
+	}
+	chosen := &state.Ports{}
+	used := map[int32]bool{}
+	resolve := func(listenAddr string, reuse int32) (int32, error) {
+		if reuse != 0 && !used[reuse] && isFree(reuse, listenAddr) {
+			used[reuse] = true
+			return reuse, nil
+		}
+		// a newly picked port may collide with one we are about to reuse
+		for i := 0; i < 10; i++ {
+			p, err := pick(listenAddr)
+			if err != nil {
+				return 0, errors.Wrap(err, "failed to get random host port")
+			}
+			if !used[p] {
+				used[p] = true
+				return p, nil
+			}
+		}
+		return 0, errors.Errorf("failed to get an unused random host port on %q", listenAddr)
+	}
+
+	// explicitly requested ports must not be handed out for random ones
+	for _, n := range cfg.Nodes {
+		for _, pm := range n.ExtraPortMappings {
+			if pm.HostPort > 0 {
+				used[pm.HostPort] = true
+			}
+		}
+	}
+	if cfg.Networking.APIServerPort > 0 {
+		used[cfg.Networking.APIServerPort] = true
+	}
+
+	if cfg.Networking.APIServerPort == 0 {
+		p, err := resolve(cfg.Networking.APIServerAddress, previous.APIServer)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to pick API server port")
+		}
+		cfg.Networking.APIServerPort = p
+		chosen.APIServer = p
+	}
+
+	names := config.NodeNames(cfg)
+	for i := range cfg.Nodes {
+		node := &cfg.Nodes[i]
+		name := names[i]
+		for j := range node.ExtraPortMappings {
+			pm := &node.ExtraPortMappings[j]
+			// -1 leaves the choice to the container runtime, we can't know it
+			if pm.HostPort < 0 {
+				continue
+			}
+			mapping := state.PortMapping{
+				Node:          name,
+				ListenAddress: listenAddress(cfg, pm),
+				HostPort:      pm.HostPort,
+				ContainerPort: pm.ContainerPort,
+				Protocol:      protocol(pm),
+			}
+			if pm.HostPort == 0 {
+				p, err := resolve(mapping.ListenAddress, previousHostPort(previous, mapping))
+				if err != nil {
+					return nil, errors.Wrapf(err, "failed to pick host port for %s container port %d", name, pm.ContainerPort)
+				}
+				pm.HostPort = p
+				mapping.HostPort = p
+			}
+			chosen.Mappings = append(chosen.Mappings, mapping)
+		}
+	}
+	return chosen, nil
+}
+
+// previousHostPort returns the host port previously chosen for the same
+// mapping, or zero if there is none
+func previousHostPort(previous *state.Ports, mapping state.PortMapping) int32 {
+	for _, p := range previous.Mappings {
+		if p.Node == mapping.Node && p.ContainerPort == mapping.ContainerPort &&
+			p.Protocol == mapping.Protocol && p.ListenAddress == mapping.ListenAddress {
+			return p.HostPort
+		}
+	}
+	return 0
+}
+
+// listenAddress returns the host address the mapping will bind, matching
+// the node providers' defaulting
+func listenAddress(cfg *config.Cluster, pm *config.PortMapping) string {
+	if pm.ListenAddress != "" {
+		return pm.ListenAddress
+	}
+	if cfg.Networking.IPFamily == config.IPv6Family {
+		return "::"
+	}
+	return "0.0.0.0"
+}
+
+func protocol(pm *config.PortMapping) string {
+	if pm.Protocol == "" {
+		return string(config.PortMappingProtocolTCP)
+	}
+	return string(pm.Protocol)
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/state"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestResolvePorts(t *testing.T) {
+	t.Parallel()
+	cfg := &config.Cluster{
+		Name: "kind",
+		Networking: config.Networking{
+			APIServerAddress: "127.0.0.1",
+		},
+		Nodes: []config.Node{
+			{Role: config.ControlPlaneRole},
+			{
+				Role: config.WorkerRole,
+				ExtraPortMappings: []config.PortMapping{
+					{ContainerPort: 80},
+					{ContainerPort: 443, HostPort: 8443},
+					{ContainerPort: 53, Protocol: config.PortMappingProtocolUDP},
+				},
+			},
+		},
+	}
+	previous := &state.Ports{
+		APIServer: 40000,
+		Mappings: []state.PortMapping{
+			{Node: "kind-worker", ListenAddress: "0.0.0.0", HostPort: 40001, ContainerPort: 80, Protocol: "TCP"},
+			// taken since, must not be reused
+			{Node: "kind-worker", ListenAddress: "0.0.0.0", HostPort: 40002, ContainerPort: 53, Protocol: "UDP"},
+		},
+	}
+	isFree := func(port int32, _ string) bool {
+		return port != 40002
+	}
+	// the first pick collides with an explicit port and must be skipped
+	picks := []int32{8443, 50000}
+	pick := func(string) (int32, error) {
+		p := picks[0]
+		picks = picks[1:]
+		return p, nil
+	}
+
+	chosen, err := resolvePorts(cfg, previous, isFree, pick)
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, &state.Ports{
+		APIServer: 40000,
+		Mappings: []state.PortMapping{
+			{Node: "kind-worker", ListenAddress: "0.0.0.0", HostPort: 40001, ContainerPort: 80, Protocol: "TCP"},
+			{Node: "kind-worker", ListenAddress: "0.0.0.0", HostPort: 8443, ContainerPort: 443, Protocol: "TCP"},
+			{Node: "kind-worker", ListenAddress: "0.0.0.0", HostPort: 50000, ContainerPort: 53, Protocol: "UDP"},
+		},
+	}, chosen)
+	if cfg.Networking.APIServerPort != 40000 {
+		t.Errorf("expected API server port 40000, got %d", cfg.Networking.APIServerPort)
+	}
+	mappings := cfg.Nodes[1].ExtraPortMappings
+	if mappings[0].HostPort != 40001 || mappings[1].HostPort != 8443 || mappings[2].HostPort != 50000 {
+		t.Errorf("unexpected resolved port mappings: %+v", mappings)
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	configaction "sigs.k8s.io/kind/pkg/cluster/internal/create/actions/config"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// NodeAddressPlaceholder stands in for the node addresses in rendered
+// kubeadm configs, they are only known once the nodes are created
+const NodeAddressPlaceholder = "NODE_ADDRESS"
+
+// KubeadmConfig is the kubeadm config generated for a node
+type KubeadmConfig struct {
+	// Node is the node name
+	Node string
+	// Config is the kubeadm config, with all patches applied
+	Config string
+}
+
+// RenderKubeadmConfigs returns the kubeadm config creating a cluster with
+// opts would generate for each node, without creating anything.
+// If kubernetesVersion is empty it is read from the node images,
+// pulling them if needed.
+func RenderKubeadmConfigs(ctx context.Context, p provider.Provider, opts *ClusterOptions, kubernetesVersion string) ([]KubeadmConfig, error) {
+	if err := fixupOptions(opts); err != nil {
+		return nil, err
+	}
+	if err := opts.Config.Validate(); err != nil {
+		return nil, err
+	}
+	cfg := opts.Config
+	names := config.NodeNames(cfg)
+
+	// this mirrors the endpoint the providers pick once the nodes exist
+	controlPlaneEndpoint := cfg.ExternalControlPlane.Endpoint
+	if controlPlaneEndpoint == "" {
+		endpointNode := ""
+		controlPlanes := 0
+		for i, n := range cfg.Nodes {
+			if n.Role != config.ControlPlaneRole {
+				continue
+			}
+			if controlPlanes++; controlPlanes == 1 {
+				endpointNode = names[i]
+			}
+		}
+		if controlPlanes > 1 {
+			endpointNode = common.MakeNodeNamer(cfg.Name)(constants.ExternalLoadBalancerNodeRoleValue)
+		}
+		controlPlaneEndpoint = net.JoinHostPort(endpointNode, strconv.Itoa(common.APIServerInternalPort))
+	}
+	data := configaction.KubeadmConfigData(cfg, controlPlaneEndpoint)
+
+	versions := map[string]string{}
+	rendered := make([]KubeadmConfig, 0, len(cfg.Nodes))
+	for i := range cfg.Nodes {
+		configNode := &cfg.Nodes[i]
+		nodeData := data
+		nodeData.ControlPlane = configNode.Role == config.ControlPlaneRole
+		nodeData.NodeAddress = NodeAddressPlaceholder
+		nodeData.KubernetesVersion = kubernetesVersion
+		if nodeData.KubernetesVersion == "" {
+			version, seen := versions[configNode.Image]
+			if !seen {
+				out, err := exec.Output(p.ImageCommand(ctx, configNode.Image, "cat", "/kind/version"))
+				if err != nil {
+					return nil, errors.Wrapf(err, "failed to read the Kubernetes version of %s", configNode.Image)
+				}
+				version = strings.TrimSpace(string(out))
+				versions[configNode.Image] = version
+			}
+			nodeData.KubernetesVersion = version
+		}
+		kubeadmConfig, err := configaction.RenderKubeadmConfig(cfg, nodeData, names[i], configNode, opts.KubeadmConfigMutators)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to generate the kubeadm config for node %s", names[i])
+		}
+		rendered = append(rendered, KubeadmConfig{Node: names[i], Config: kubeadmConfig})
+	}
+	return rendered, nil
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"context"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/oidc"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
+	"sigs.k8s.io/kind/pkg/cluster/internal/requiredimages"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// RequiredImages returns the images creating a cluster with opts would use,
+// without creating anything. The Kubernetes images are listed by running
+// the node image of the first control plane, pulling it if needed.
+func RequiredImages(ctx context.Context, p provider.Provider, opts *ClusterOptions) ([]requiredimages.Image, error) {
+	if err := fixupOptions(opts); err != nil {
+		return nil, err
+	}
+	if err := opts.Config.Validate(); err != nil {
+		return nil, err
+	}
+	cfg := opts.Config
+
+	// images the provider needs on the host
+	images := []requiredimages.Image{}
+	for _, image := range common.RequiredNodeImages(cfg).List() {
+		images = append(images, requiredimages.Image{Image: image, Source: requiredimages.SourceNode})
+	}
+	controlPlanes := 0
+	nodeImage := cfg.Nodes[0].Image
+	for _, n := range cfg.Nodes {
+		if n.Role != config.ControlPlaneRole {
+			continue
+		}
+		if controlPlanes++; controlPlanes == 1 {
+			nodeImage = n.Image
+		}
+	}
+	if controlPlanes > 1 {
+		images = append(images, requiredimages.Image{
+			Image:  common.LoadBalancerImage(cfg),
+			Source: requiredimages.SourceLoadBalancer,
+		})
+	}
+	if cfg.Authentication.OIDC.TestIssuer {
+		images = append(images, requiredimages.Image{
+			Image:  common.OverrideImage(cfg, oidc.Image),
+			Source: requiredimages.SourceOIDCIssuer,
+		})
+	}
+
+	// images the nodes need, without a config file kubeadm needs to be
+	// told the version rather than looking up the latest release
+	run := func(command string, args ...string) exec.Cmd {
+		return p.ImageCommand(ctx, nodeImage, command, args...)
+	}
+	version, err := exec.Output(run("cat", "/kind/version"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the Kubernetes version of %s", nodeImage)
+	}
+	kubeadmArgs := []string{"--kubernetes-version=" + strings.TrimSpace(string(version))}
+	if cfg.Images.Repository != "" {
+		kubeadmArgs = append(kubeadmArgs, "--image-repository="+cfg.Images.Repository)
+	}
+	kubeImages, err := requiredimages.ForKubernetes(cfg, run, kubeadmArgs...)
+	if err != nil {
+		return nil, err
+	}
+	return append(images, kubeImages...), nil
+}
@@ -17,18 +17,27 @@ limitations under the License.
 package delete
 
 import (
+	"context"
+
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/log"
 
 	"sigs.k8s.io/kind/pkg/cluster/internal/kubeconfig"
 	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
+	"sigs.k8s.io/kind/pkg/cluster/internal/state"
+	"sigs.k8s.io/kind/pkg/internal/cli"
 )
 
 // Cluster deletes the cluster identified by ctx
 // explicitKubeconfigPath is --kubeconfig, following the rules from
 // https://kubernetes.io/docs/reference/generated/kubectl/kubectl-commands
-func Cluster(logger log.Logger, p provider.Provider, name, explicitKubeconfigPath string) error {
-	n, err := p.ListNodes(name)
+func Cluster(ctx context.Context, logger log.Logger, p provider.Provider, name, explicitKubeconfigPath string) (err error) {
+	// the status emits the phase events for the deletion
+	status := cli.StatusForLogger(logger)
+	status.Start("Deleting nodes")
+	defer func() { status.End(err == nil) }()
+
+	n, err := p.ListNodes(ctx, name)
 	if err != nil {
 		return errors.Wrap(err, "error listing nodes")
 	}
@@ -38,10 +47,19 @@ func Cluster(logger log.Logger, p provider.Provider, name, explicitKubeconfigPat
 		logger.Errorf("failed to update kubeconfig: %v", kerr)
 	}
 
-	err = p.DeleteNodes(n)
+	// swap is host global, so this runs even when forced
+	disableSwap(logger, n)
+	err = p.DeleteNodes(ctx, n)
 	if err != nil {
 		return err
 	}
+	// node images committed for clones are only needed by its nodes
+	if err := p.DeleteImages(ctx, name); err != nil {
+		logger.Warnf("failed to delete cloned node images: %v", err)
+	}
+	if err := state.Default().Remove(name); err != nil {
+		logger.Warnf("failed to remove cluster state: %v", err)
+	}
 	if kerr != nil {
 		return err
 	}
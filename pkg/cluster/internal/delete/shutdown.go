@@ -0,0 +1,140 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package delete
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
+	"sigs.k8s.io/kind/pkg/cluster/internal/state"
+)
+
+// drainTimeout bounds draining each node
+const drainTimeout = "60s"
+
+// stopTimeout is how long containers get to exit after SIGTERM
+const stopTimeout = "30"
+
+// Shutdown gracefully stops the cluster before it is deleted: the nodes are
+// drained, then the kubelet and all containers are stopped, workers before
+// control planes, so that etcd shuts down cleanly and every node's data is
+// synced to disk. It is best effort, failures are logged as warnings.
+func Shutdown(ctx context.Context, logger log.Logger, p provider.Provider, name string) error {
+	allNodes, err := p.ListNodes(ctx, name)
+	if err != nil {
+		return errors.Wrap(err, "error listing nodes")
+	}
+	internalNodes, err := nodeutils.InternalNodes(allNodes)
+	if err != nil {
+		return err
+	}
+	if len(internalNodes) == 0 {
+		return nil
+	}
+
+	controlPlane, err := nodeutils.BootstrapControlPlaneNode(internalNodes)
+	if err != nil {
+		logger.Warnf("skipping draining nodes: %v", err)
+	} else {
+		// nodes are registered in Kubernetes by their hostname
+		cfg, err := state.Default().ReadConfig(name)
+		if err != nil {
+			logger.Warnf("draining nodes by container name: %v", err)
+		}
+		drainNodes(ctx, logger, controlPlane, internalNodes, cfg)
+	}
+
+	// stop the workers first, the API server and etcd last
+	controlPlanes, err := nodeutils.ControlPlaneNodes(internalNodes)
+	if err != nil {
+		return err
+	}
+	workers := []nodes.Node{}
+	for _, n := range internalNodes {
+		if !containsNode(controlPlanes, n) {
+			workers = append(workers, n)
+		}
+	}
+	stopNodes(logger, workers)
+	stopNodes(logger, controlPlanes)
+	return nil
+}
+
+// drainNodes cordons and drains every node using the API server on controlPlane,
+// cfg is the config the cluster was created from and may be nil
+func drainNodes(ctx context.Context, logger log.Logger, controlPlane nodes.Node, allNodes []nodes.Node, cfg *config.Cluster) {
+	emptyDirFlag := "--delete-emptydir-data"
+	if v, err := nodeutils.KubeVersion(controlPlane); err == nil {
+		if parsed, err := version.ParseGeneric(v); err == nil && parsed.LessThan(version.MustParseGeneric("v1.20.0")) {
+			emptyDirFlag = "--delete-local-data"
+		}
+	}
+	for _, n := range allNodes {
+		logger.V(1).Infof("Draining node %s ...", n.String())
+		if err := controlPlane.CommandContext(ctx,
+			"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf",
+			"drain", common.KubernetesNodeName(cfg, n),
+			"--ignore-daemonsets", "--force", emptyDirFlag,
+			"--timeout="+drainTimeout,
+		).Run(); err != nil {
+			logger.Warnf("failed to drain node %s: %v", n.String(), err)
+		}
+	}
+}
+
+// stopNodes stops the kubelet and then all containers on each node
+// concurrently, and syncs the node's filesystems
+func stopNodes(logger log.Logger, toStop []nodes.Node) {
+	fns := make([]func() error, 0, len(toStop))
+	for _, n := range toStop {
+		n := n // capture loop variable
+		fns = append(fns, func() error {
+			logger.V(1).Infof("Stopping node %s ...", n.String())
+			if err := n.Command("systemctl", "stop", "kubelet").Run(); err != nil {
+				return errors.Wrapf(err, "failed to stop the kubelet on node %s", n.String())
+			}
+			if err := n.Command("sh", "-c", fmt.Sprintf(
+				"crictl ps -q | xargs -r crictl stop --timeout %s && sync", stopTimeout,
+			)).Run(); err != nil {
+				return errors.Wrapf(err, "failed to stop the containers on node %s", n.String())
+			}
+			return nil
+		})
+	}
+	if err := errors.AggregateConcurrent(fns); err != nil {
+		logger.Warnf("graceful shutdown incomplete: %v", err)
+	}
+}
+
+func containsNode(list []nodes.Node, n nodes.Node) bool {
+	for _, l := range list {
+		if l.String() == n.String() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package delete
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+// fakeNode records the commands run on it, cat prints version
+type fakeNode struct {
+	nodes.Node
+	name     string
+	version  string
+	commands [][]string
+	contexts []context.Context
+}
+
+func (n *fakeNode) String() string {
+	return n.name
+}
+
+func (n *fakeNode) Command(command string, args ...string) exec.Cmd {
+	return n.CommandContext(context.Background(), command, args...)
+}
+
+func (n *fakeNode) CommandContext(ctx context.Context, command string, args ...string) exec.Cmd {
+	n.commands = append(n.commands, append([]string{command}, args...))
+	n.contexts = append(n.contexts, ctx)
+	c := &fakeCmd{}
+	if command == "cat" {
+		c.output = n.version + "\n"
+	}
+	return c
+}
+
+type fakeCmd struct {
+	output string
+	stdout io.Writer
+}
+
+var _ exec.Cmd = &fakeCmd{}
+
+func (c *fakeCmd) Run() error {
+	if c.stdout != nil {
+		_, err := io.WriteString(c.stdout, c.output)
+		return err
+	}
+	return nil
+}
+
+func (c *fakeCmd) SetEnv(...string) exec.Cmd      { return c }
+func (c *fakeCmd) SetStdin(io.Reader) exec.Cmd    { return c }
+func (c *fakeCmd) SetStdout(w io.Writer) exec.Cmd { c.stdout = w; return c }
+func (c *fakeCmd) SetStderr(io.Writer) exec.Cmd   { return c }
+
+func TestDrainNodes(t *testing.T) {
+	t.Parallel()
+	type ctxKey struct{}
+	cfg := &config.Cluster{
+		Name: "kind",
+		Nodes: []config.Node{
+			{Role: config.ControlPlaneRole},
+			{Role: config.WorkerRole, Hostname: "worker.example.com"},
+		},
+	}
+	cases := []struct {
+		Name         string
+		Version      string
+		Config       *config.Cluster
+		ExpectDrains []string
+		ExpectFlag   string
+	}{
+		{
+			Name:         "hostnames from the config",
+			Version:      "v1.21.1",
+			Config:       cfg,
+			ExpectDrains: []string{"kind-control-plane", "worker.example.com"},
+			ExpectFlag:   "--delete-emptydir-data",
+		},
+		{
+			Name:         "no config",
+			Version:      "v1.21.1",
+			ExpectDrains: []string{"kind-control-plane", "kind-worker"},
+			ExpectFlag:   "--delete-emptydir-data",
+		},
+		{
+			Name:         "old kubectl",
+			Version:      "v1.19.4",
+			Config:       cfg,
+			ExpectDrains: []string{"kind-control-plane", "worker.example.com"},
+			ExpectFlag:   "--delete-local-data",
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			controlPlane := &fakeNode{name: "kind-control-plane", version: tc.Version}
+			worker := &fakeNode{name: "kind-worker"}
+			ctx := context.WithValue(context.Background(), ctxKey{}, tc.Name)
+			drainNodes(ctx, log.NoopLogger{}, controlPlane, []nodes.Node{controlPlane, worker}, tc.Config)
+
+			drains := []string{}
+			for i, c := range controlPlane.commands {
+				if c[0] != "kubectl" {
+					continue
+				}
+				// the drains must be bound by ctx
+				if controlPlane.contexts[i] != ctx {
+					t.Errorf("expected %v to run with the drain context", c)
+				}
+				drains = append(drains, c[3])
+				if !strings.Contains(strings.Join(c, " "), tc.ExpectFlag) {
+					t.Errorf("expected %v to contain %s", c, tc.ExpectFlag)
+				}
+			}
+			assert.DeepEqual(t, tc.ExpectDrains, drains)
+			assert.BoolEqual(t, true, len(worker.commands) == 0)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package delete
+
+import (
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/swap"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+)
+
+// disableSwap disables the node swapfiles before deleting the nodes, the
+// host would otherwise keep using the deleted files until it restarts.
+// It is best effort, stopped nodes are skipped.
+func disableSwap(logger log.Logger, allNodes []nodes.Node) {
+	internalNodes, err := nodeutils.InternalNodes(allNodes)
+	if err != nil {
+		return
+	}
+	fns := make([]func() error, 0, len(internalNodes))
+	for _, n := range internalNodes {
+		n := n // capture loop variable
+		fns = append(fns, func() error {
+			if err := n.Command("sh", "-c", swap.DisableCommand).Run(); err != nil {
+				return errors.Wrapf(err, "failed to disable swap on node %s", n.String())
+			}
+			return nil
+		})
+	}
+	if err := errors.AggregateConcurrent(fns); err != nil {
+		logger.V(1).Infof("disabling swap: %v", err)
+	}
+}
@@ -0,0 +1,391 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnostics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// recentEvents is how many of the most recent warning events are included
+// in the cluster summary
+const recentEvents = 10
+
+// clusterSources are the kubectl commands run for the cluster bundle
+var clusterSources = []source{
+	{
+		name:    "nodes",
+		file:    "nodes.txt",
+		command: []string{"describe", "nodes"},
+	},
+	{
+		name:    "pods",
+		file:    "pods.txt",
+		command: []string{"get", "pods", "--all-namespaces", "--output=wide"},
+	},
+	{
+		name:    "events",
+		file:    "events.txt",
+		command: []string{"get", "events", "--all-namespaces", "--sort-by=.lastTimestamp"},
+	},
+}
+
+// ClusterReport is the diagnostics collected from a cluster that did not
+// become ready in time
+type ClusterReport struct {
+	// NotReady describes each not ready node and pod, and why
+	NotReady []string
+	// Events are the most recent warning events, oldest first
+	Events []string
+	// Sections are the kubectl output written to the bundle
+	Sections []Section
+	// Nodes are the diagnostics of the not ready nodes
+	Nodes []*Report
+	// Dir is where the bundle was written, if it was
+	Dir string
+}
+
+// CollectCluster gathers diagnostics about why the cluster is not ready,
+// using kubectl inside kubectlNode, and from each of internalNodes that is
+// not Ready, matched to the Kubernetes nodes by their hostname in cfg.
+// If the API server can't be reached only kubectlNode's diagnostics are
+// collected. This is best effort, like Collect
+func CollectCluster(ctx context.Context, cfg *config.Cluster, kubectlNode nodes.Node, internalNodes []nodes.Node) *ClusterReport {
+	r := &ClusterReport{}
+	nodeList, err := kubectl(ctx, kubectlNode, "get", "nodes", "--output=json")
+	if err != nil {
+		r.NotReady = append(r.NotReady, fmt.Sprintf("failed to list nodes, the API server may not be reachable: %v", err))
+		r.Nodes = append(r.Nodes, Collect(ctx, kubectlNode))
+		return r
+	}
+	podList, err := kubectl(ctx, kubectlNode, "get", "pods", "--all-namespaces", "--output=json")
+	if err != nil {
+		r.NotReady = append(r.NotReady, fmt.Sprintf("failed to list pods: %v", err))
+	}
+	notReadyNodes, notReady, err := summarizeNotReady(cfg, nodeList, podList, internalNodes)
+	if err != nil {
+		r.NotReady = append(r.NotReady, err.Error())
+	}
+	r.NotReady = append(r.NotReady, notReady...)
+	if eventList, err := kubectl(ctx, kubectlNode, "get", "events", "--all-namespaces", "--field-selector=type=Warning", "--output=json"); err == nil {
+		if r.Events, err = summarizeEvents(eventList, recentEvents); err != nil {
+			r.Events = append(r.Events, err.Error())
+		}
+	}
+	for _, s := range clusterSources {
+		out, err := kubectl(ctx, kubectlNode, s.command...)
+		if err != nil {
+			out = append(out, fmt.Sprintf("failed to collect %s: %v", s.name, err)...)
+		}
+		r.Sections = append(r.Sections, Section{Name: s.name, File: s.file, Output: string(out)})
+	}
+	for _, n := range notReadyNodes {
+		r.Nodes = append(r.Nodes, Collect(ctx, n))
+	}
+	return r
+}
+
+// kubectl runs kubectl with args as the cluster admin inside node, and
+// returns its stdout
+func kubectl(ctx context.Context, node nodes.Node, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := node.CommandContext(ctx,
+		"kubectl", append([]string{"--kubeconfig=/etc/kubernetes/admin.conf"}, args...)...,
+	)
+	if err := cmd.SetStdout(&stdout).SetStderr(&stderr).Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return stdout.Bytes(), errors.Wrap(err, msg)
+		}
+		return stdout.Bytes(), err
+	}
+	return stdout.Bytes(), nil
+}
+
+// WriteBundle writes the kubectl output to dir, and the diagnostics of each
+// not ready node to a directory for the node under dir
+func (r *ClusterReport) WriteBundle(dir string) error {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return errors.Wrap(err, "failed to create diagnostics directory")
+	}
+	for _, s := range r.Sections {
+		if err := ioutil.WriteFile(filepath.Join(dir, s.File), []byte(s.Output), 0644); err != nil {
+			return errors.Wrapf(err, "failed to write %s diagnostics", s.Name)
+		}
+	}
+	for _, n := range r.Nodes {
+		if err := n.WriteBundle(dir); err != nil {
+			return err
+		}
+	}
+	r.Dir = dir
+	return nil
+}
+
+// Summary returns what is not ready and the recent warning events, followed
+// by the summary of each not ready node's diagnostics
+func (r *ClusterReport) Summary() string {
+	var b strings.Builder
+	b.WriteString("Not ready:")
+	if len(r.NotReady) == 0 {
+		b.WriteString("\n  nothing, the cluster became ready while collecting diagnostics")
+	}
+	for _, s := range r.NotReady {
+		fmt.Fprintf(&b, "\n  %s", s)
+	}
+	if len(r.Events) > 0 {
+		fmt.Fprintf(&b, "\nRecent warning events:")
+		for _, e := range r.Events {
+			fmt.Fprintf(&b, "\n  %s", e)
+		}
+	}
+	for _, n := range r.Nodes {
+		// the bundle location is printed once, below
+		nodeReport := *n
+		nodeReport.Dir = ""
+		fmt.Fprintf(&b, "\n\n%s", nodeReport.Summary())
+	}
+	if r.Dir != "" {
+		fmt.Fprintf(&b, "\n\nFull diagnostics written to: %s", r.Dir)
+	}
+	return b.String()
+}
+
+type condition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+type nodeList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Status struct {
+			Conditions []condition `json:"conditions"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+type containerStatus struct {
+	Name  string `json:"name"`
+	Ready bool   `json:"ready"`
+	State struct {
+		Waiting *struct {
+			Reason  string `json:"reason"`
+			Message string `json:"message"`
+		} `json:"waiting"`
+		Terminated *struct {
+			Reason   string `json:"reason"`
+			ExitCode int    `json:"exitCode"`
+		} `json:"terminated"`
+	} `json:"state"`
+	RestartCount int `json:"restartCount"`
+}
+
+type podList struct {
+	Items []struct {
+		Metadata struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+		Spec struct {
+			NodeName string `json:"nodeName"`
+		} `json:"spec"`
+		Status struct {
+			Phase                 string            `json:"phase"`
+			Conditions            []condition       `json:"conditions"`
+			InitContainerStatuses []containerStatus `json:"initContainerStatuses"`
+			ContainerStatuses     []containerStatus `json:"containerStatuses"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+type eventList struct {
+	Items []struct {
+		InvolvedObject struct {
+			Kind      string `json:"kind"`
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"involvedObject"`
+		Reason        string    `json:"reason"`
+		Message       string    `json:"message"`
+		Count         int       `json:"count"`
+		LastTimestamp time.Time `json:"lastTimestamp"`
+		EventTime     time.Time `json:"eventTime"`
+	} `json:"items"`
+}
+
+// summarizeNotReady describes the nodes that are not Ready or not
+// registered, and the pods that are not Ready, from `kubectl get -o json`
+// output. It also returns the not ready nodes of internalNodes, which are
+// named in Kubernetes after their hostname in cfg
+func summarizeNotReady(cfg *config.Cluster, nodesJSON, podsJSON []byte, internalNodes []nodes.Node) ([]nodes.Node, []string, error) {
+	var nl nodeList
+	if err := json.Unmarshal(nodesJSON, &nl); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to parse nodes")
+	}
+	out := []string{}
+	ready := map[string]bool{}
+	registered := map[string]bool{}
+	for _, n := range nl.Items {
+		registered[n.Metadata.Name] = true
+		c := findCondition(n.Status.Conditions, "Ready")
+		if c != nil && c.Status == "True" {
+			ready[n.Metadata.Name] = true
+			continue
+		}
+		out = append(out, fmt.Sprintf("node %s: %s", n.Metadata.Name, describeCondition(c)))
+	}
+	notReadyNodes := []nodes.Node{}
+	for _, n := range internalNodes {
+		name := common.KubernetesNodeName(cfg, n)
+		if !ready[name] {
+			notReadyNodes = append(notReadyNodes, n)
+		}
+		if !registered[name] {
+			out = append(out, fmt.Sprintf("node %s: not registered with the API server", name))
+		}
+	}
+
+	if len(podsJSON) == 0 {
+		return notReadyNodes, out, nil
+	}
+	var pl podList
+	if err := json.Unmarshal(podsJSON, &pl); err != nil {
+		return notReadyNodes, out, errors.Wrap(err, "failed to parse pods")
+	}
+	for _, p := range pl.Items {
+		if p.Status.Phase == "Succeeded" {
+			continue
+		}
+		if c := findCondition(p.Status.Conditions, "Ready"); c != nil && c.Status == "True" {
+			continue
+		}
+		reasons := []string{p.Status.Phase}
+		if p.Spec.NodeName != "" {
+			reasons[0] += " on " + p.Spec.NodeName
+		} else if c := findCondition(p.Status.Conditions, "PodScheduled"); c != nil && c.Status != "True" {
+			reasons = append(reasons, describeCondition(c))
+		}
+		statuses := append(p.Status.InitContainerStatuses, p.Status.ContainerStatuses...)
+		for _, cs := range statuses {
+			if reason := describeContainer(cs); reason != "" {
+				reasons = append(reasons, reason)
+			}
+		}
+		out = append(out, fmt.Sprintf("pod %s/%s: %s", p.Metadata.Namespace, p.Metadata.Name, strings.Join(reasons, ", ")))
+	}
+	return notReadyNodes, out, nil
+}
+
+// summarizeEvents describes the last limit events of `kubectl get events
+// -o json` output, oldest first
+func summarizeEvents(eventsJSON []byte, limit int) ([]string, error) {
+	var el eventList
+	if err := json.Unmarshal(eventsJSON, &el); err != nil {
+		return nil, errors.Wrap(err, "failed to parse events")
+	}
+	items := el.Items
+	// newer events only set eventTime
+	timestamp := func(i int) time.Time {
+		if items[i].LastTimestamp.IsZero() {
+			return items[i].EventTime
+		}
+		return items[i].LastTimestamp
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return timestamp(i).Before(timestamp(j))
+	})
+	if len(items) > limit {
+		items = items[len(items)-limit:]
+	}
+	out := []string{}
+	for _, e := range items {
+		object := strings.ToLower(e.InvolvedObject.Kind) + "/" + e.InvolvedObject.Name
+		if e.InvolvedObject.Namespace != "" {
+			object = e.InvolvedObject.Namespace + "/" + object
+		}
+		line := fmt.Sprintf("%s: %s: %s", object, e.Reason, strings.TrimSpace(e.Message))
+		if e.Count > 1 {
+			line += fmt.Sprintf(" (x%d)", e.Count)
+		}
+		out = append(out, line)
+	}
+	return out, nil
+}
+
+func findCondition(conditions []condition, conditionType string) *condition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// describeCondition returns e.g. "Ready=False: KubeletNotReady: message"
+func describeCondition(c *condition) string {
+	if c == nil {
+		return "no Ready condition"
+	}
+	s := c.Type + "=" + c.Status
+	if c.Reason != "" {
+		s += ": " + c.Reason
+	}
+	if c.Message != "" {
+		s += ": " + c.Message
+	}
+	return s
+}
+
+// describeContainer returns why a container is not ready, if it is waiting
+// or has terminated
+func describeContainer(cs containerStatus) string {
+	if cs.Ready {
+		return ""
+	}
+	switch {
+	case cs.State.Waiting != nil:
+		s := fmt.Sprintf("container %s waiting: %s", cs.Name, cs.State.Waiting.Reason)
+		if cs.State.Waiting.Message != "" {
+			s += ": " + cs.State.Waiting.Message
+		}
+		if cs.RestartCount > 0 {
+			s += fmt.Sprintf(" (%d restarts)", cs.RestartCount)
+		}
+		return s
+	case cs.State.Terminated != nil && cs.State.Terminated.ExitCode != 0:
+		return fmt.Sprintf("container %s terminated: %s (exit code %d)", cs.Name, cs.State.Terminated.Reason, cs.State.Terminated.ExitCode)
+	}
+	return ""
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnostics
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestSummarizeNotReady(t *testing.T) {
+	t.Parallel()
+	nodesJSON := []byte(`{"items": [
+		{"metadata": {"name": "devbox"}, "status": {"conditions": [{"type": "Ready", "status": "True"}]}},
+		{"metadata": {"name": "kind-worker"}, "status": {"conditions": [
+			{"type": "MemoryPressure", "status": "False"},
+			{"type": "Ready", "status": "False", "reason": "KubeletNotReady", "message": "container runtime network not ready"}
+		]}}
+	]}`)
+	podsJSON := []byte(`{"items": [
+		{"metadata": {"namespace": "kube-system", "name": "etcd-kind-control-plane"}, "spec": {"nodeName": "kind-control-plane"},
+		 "status": {"phase": "Running", "conditions": [{"type": "Ready", "status": "True"}]}},
+		{"metadata": {"namespace": "kube-system", "name": "coredns-abc"},
+		 "status": {"phase": "Pending", "conditions": [{"type": "PodScheduled", "status": "False", "reason": "Unschedulable", "message": "0/2 nodes are available"}]}},
+		{"metadata": {"namespace": "default", "name": "app"}, "spec": {"nodeName": "kind-worker"},
+		 "status": {"phase": "Pending", "conditions": [{"type": "Ready", "status": "False"}],
+		  "initContainerStatuses": [{"name": "init", "ready": false, "state": {"terminated": {"reason": "Error", "exitCode": 1}}}],
+		  "containerStatuses": [{"name": "app", "ready": false, "restartCount": 2, "state": {"waiting": {"reason": "ImagePullBackOff", "message": "Back-off pulling image"}}}]}},
+		{"metadata": {"namespace": "default", "name": "job"}, "status": {"phase": "Succeeded"}}
+	]}`)
+	internalNodes := []nodes.Node{
+		&fakeNode{name: "kind-control-plane"},
+		&fakeNode{name: "kind-worker"},
+		&fakeNode{name: "kind-worker2"},
+	}
+	// the control plane is registered with its hostname
+	cfg := &config.Cluster{Name: "kind", Nodes: []config.Node{
+		{Name: "kind-control-plane", Hostname: "devbox"},
+		{Name: "kind-worker"},
+		{Name: "kind-worker2"},
+	}}
+	notReadyNodes, notReady, err := summarizeNotReady(cfg, nodesJSON, podsJSON, internalNodes)
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, []nodes.Node{internalNodes[1], internalNodes[2]}, notReadyNodes)
+	assert.DeepEqual(t, []string{
+		"node kind-worker: Ready=False: KubeletNotReady: container runtime network not ready",
+		"node kind-worker2: not registered with the API server",
+		"pod kube-system/coredns-abc: Pending, PodScheduled=False: Unschedulable: 0/2 nodes are available",
+		"pod default/app: Pending on kind-worker, container init terminated: Error (exit code 1), container app waiting: ImagePullBackOff: Back-off pulling image (2 restarts)",
+	}, notReady)
+
+	_, _, err = summarizeNotReady(nil, []byte("{"), nil, nil)
+	assert.ExpectError(t, true, err)
+}
+
+func TestSummarizeEvents(t *testing.T) {
+	t.Parallel()
+	events, err := summarizeEvents([]byte(`{"items": [
+		{"involvedObject": {"kind": "Pod", "namespace": "default", "name": "app"}, "reason": "Failed", "message": "Failed to pull image\n", "count": 3, "lastTimestamp": "2020-01-01T00:00:03Z"},
+		{"involvedObject": {"kind": "Node", "name": "kind-worker"}, "reason": "InvalidDiskCapacity", "message": "invalid capacity 0", "count": 1, "lastTimestamp": "2020-01-01T00:00:01Z"},
+		{"involvedObject": {"kind": "Pod", "namespace": "default", "name": "other"}, "reason": "BackOff", "message": "Back-off", "lastTimestamp": null, "eventTime": "2020-01-01T00:00:02.000000Z"}
+	]}`), 2)
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, []string{
+		"default/pod/other: BackOff: Back-off",
+		"default/pod/app: Failed: Failed to pull image (x3)",
+	}, events)
+}
+
+func TestClusterReportSummary(t *testing.T) {
+	t.Parallel()
+	r := &ClusterReport{
+		NotReady: []string{"node kind-worker: Ready=False"},
+		Events:   []string{"default/pod/app: Failed: Failed to pull image"},
+		Nodes: []*Report{
+			{Node: "kind-worker", Dir: "/tmp/ignored", Sections: []Section{{Name: "kubelet status", Output: "kubelet failed"}}},
+		},
+		Dir: "/tmp/kind-diagnostics-123",
+	}
+	summary := r.Summary()
+	for _, expected := range []string{
+		"Not ready:\n  node kind-worker: Ready=False",
+		"Recent warning events:\n  default/pod/app: Failed: Failed to pull image",
+		"Diagnostics for node \"kind-worker\":",
+		"kubelet failed",
+		"Full diagnostics written to: /tmp/kind-diagnostics-123",
+	} {
+		if !strings.Contains(summary, expected) {
+			t.Errorf("expected %q in summary:\n%s", expected, summary)
+		}
+	}
+	if strings.Contains(summary, "/tmp/ignored") {
+		t.Errorf("expected the bundle location once in summary:\n%s", summary)
+	}
+}
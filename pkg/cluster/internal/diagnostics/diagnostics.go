@@ -0,0 +1,205 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package diagnostics collects debugging information from a node when
+// cluster creation fails, so it can be surfaced with the error
+package diagnostics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// summaryLines is how many trailing lines of each section are included in
+// the error message, the bundle on disk contains the full output
+const summaryLines = 15
+
+// source is a command run on the node to produce a Section
+type source struct {
+	name    string
+	file    string
+	command []string
+}
+
+var sources = []source{
+	{
+		name:    "kubelet status",
+		file:    "kubelet-status.txt",
+		command: []string{"systemctl", "status", "--no-pager", "--full", "kubelet"},
+	},
+	{
+		name:    "containerd errors",
+		file:    "containerd-errors.log",
+		command: []string{"journalctl", "--no-pager", "--unit=containerd.service", "--priority=err", "--lines=50"},
+	},
+	{
+		name:    "journal",
+		file:    "journal.log",
+		command: []string{"journalctl", "--no-pager", "--lines=300"},
+	},
+}
+
+// Section is the output of a single diagnostic
+type Section struct {
+	Name   string
+	File   string
+	Output string
+}
+
+// Report is the diagnostics collected from a node
+type Report struct {
+	Node     string
+	Sections []Section
+	// Dir is where the bundle was written, if it was
+	Dir string
+}
+
+// Collect gathers diagnostics from n, this is best effort and failures
+// to collect a section are recorded in its Output
+func Collect(ctx context.Context, n nodes.Node) *Report {
+	r := &Report{Node: n.String()}
+	for _, s := range sources {
+		var buff bytes.Buffer
+		cmd := n.CommandContext(ctx, s.command[0], s.command[1:]...)
+		// systemctl status exits non-zero for inactive units, which is
+		// exactly when we want the output, so only note the error
+		if err := cmd.SetStdout(&buff).SetStderr(&buff).Run(); err != nil && buff.Len() == 0 {
+			fmt.Fprintf(&buff, "failed to collect %s: %v", s.name, err)
+		}
+		r.Sections = append(r.Sections, Section{
+			Name:   s.name,
+			File:   s.file,
+			Output: buff.String(),
+		})
+	}
+	return r
+}
+
+// WriteBundle writes the full report to a directory for the node under dir
+func (r *Report) WriteBundle(dir string) error {
+	nodeDir := filepath.Join(dir, r.Node)
+	if err := os.MkdirAll(nodeDir, os.ModePerm); err != nil {
+		return errors.Wrap(err, "failed to create diagnostics directory")
+	}
+	for _, s := range r.Sections {
+		if err := ioutil.WriteFile(filepath.Join(nodeDir, s.File), []byte(s.Output), 0644); err != nil {
+			return errors.Wrapf(err, "failed to write %s diagnostics", s.Name)
+		}
+	}
+	r.Dir = dir
+	return nil
+}
+
+// Summary returns the tail of each section, for display with an error
+func (r *Report) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Diagnostics for node %q:", r.Node)
+	for _, s := range r.Sections {
+		fmt.Fprintf(&b, "\n--- %s (last %d lines) ---\n%s", s.Name, summaryLines, tail(s.Output, summaryLines))
+	}
+	if r.Dir != "" {
+		fmt.Fprintf(&b, "\nFull diagnostics written to: %s", r.Dir)
+	}
+	return b.String()
+}
+
+// tail returns the last n lines of s
+func tail(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// WithReport annotates err with r, such that the error message includes
+// the report summary. If err is nil, WithReport returns nil.
+func WithReport(err error, r *Report) error {
+	if err == nil {
+		return nil
+	}
+	return &reportError{err: err, report: r}
+}
+
+type reportError struct {
+	err    error
+	report *Report
+}
+
+func (e *reportError) Error() string {
+	return e.err.Error() + "\n\n" + e.report.Summary()
+}
+
+// Cause mimics github.com/pkg/errors's Cause pattern for errors
+func (e *reportError) Cause() error {
+	return e.err
+}
+
+// Unwrap supports the standard library's errors.Unwrap
+func (e *reportError) Unwrap() error {
+	return e.err
+}
+
+// WithNode annotates err with the node that failed, so that diagnostics
+// are collected from it. If err is nil, WithNode returns nil.
+func WithNode(err error, n nodes.Node) error {
+	if err == nil {
+		return nil
+	}
+	return &nodeError{err: err, node: n}
+}
+
+// NodeForError returns the outermost node annotated by WithNode, or nil
+func NodeForError(err error) nodes.Node {
+	for err != nil {
+		if v, ok := err.(*nodeError); ok {
+			return v.node
+		}
+		causerErr, ok := err.(errors.Causer)
+		if !ok {
+			break
+		}
+		err = causerErr.Cause()
+	}
+	return nil
+}
+
+type nodeError struct {
+	err  error
+	node nodes.Node
+}
+
+func (e *nodeError) Error() string {
+	return e.err.Error()
+}
+
+// Cause mimics github.com/pkg/errors's Cause pattern for errors
+func (e *nodeError) Cause() error {
+	return e.err
+}
+
+// Unwrap supports the standard library's errors.Unwrap
+func (e *nodeError) Unwrap() error {
+	return e.err
+}
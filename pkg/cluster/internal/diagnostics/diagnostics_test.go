@@ -0,0 +1,98 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnostics
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+type fakeNode struct {
+	nodes.Node
+	name string
+}
+
+func (n *fakeNode) String() string {
+	return n.name
+}
+
+func TestTail(t *testing.T) {
+	t.Parallel()
+	assert.StringEqual(t, "c\nd", tail("a\nb\nc\nd\n", 2))
+	assert.StringEqual(t, "a\nb", tail("a\nb", 5))
+	assert.StringEqual(t, "", tail("", 5))
+}
+
+func TestNodeForError(t *testing.T) {
+	t.Parallel()
+	n := &fakeNode{name: "kind-worker"}
+	err := errors.Wrap(WithNode(errors.New("join failed"), n), "failed to join")
+	assert.StringEqual(t, "failed to join: join failed", err.Error())
+	if NodeForError(err) != n {
+		t.Errorf("expected the annotated node")
+	}
+	if NodeForError(errors.New("other")) != nil {
+		t.Errorf("expected no node for an unannotated error")
+	}
+	assert.ExpectError(t, false, WithNode(nil, n))
+}
+
+func TestReport(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kind-diagnostics-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	r := &Report{
+		Node: "kind-control-plane",
+		Sections: []Section{
+			{Name: "kubelet status", File: "kubelet-status.txt", Output: strings.Repeat("old\n", 20) + "kubelet failed\n"},
+		},
+	}
+	if err := r.WriteBundle(dir); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, "kind-control-plane", "kubelet-status.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.StringEqual(t, r.Sections[0].Output, string(b))
+
+	reason := errors.ErrClusterNotFound
+	wrapped := WithReport(errors.WithReason(errors.New("init failed"), reason), r)
+	if errors.ReasonForError(wrapped) != reason {
+		t.Errorf("expected the reason to be preserved")
+	}
+	msg := wrapped.Error()
+	for _, expected := range []string{"init failed\n\nDiagnostics for node \"kind-control-plane\"", "kubelet failed", "Full diagnostics written to: " + dir} {
+		if !strings.Contains(msg, expected) {
+			t.Errorf("expected error to contain %q, got: %s", expected, msg)
+		}
+	}
+	if strings.Count(msg, "old") != summaryLines-1 {
+		t.Errorf("expected the summary to be limited to %d lines, got: %s", summaryLines, msg)
+	}
+}
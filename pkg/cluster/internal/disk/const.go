@@ -0,0 +1,145 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disk
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// HostDir is where cluster disks are kept on the container runtime host,
+// it is bind mounted with shared propagation so the disk mounted inside the
+// disk container is visible to the nodes. This requires / to be a shared
+// mount on the host, which is the default with systemd
+const HostDir = "/var/lib/kind-disks"
+
+// ContainerPath is where HostPath is mounted in the disk container
+const ContainerPath = "/kind-disk"
+
+// MountPath is where the disk container mounts the cluster disk
+const MountPath = ContainerPath + "/mnt"
+
+// imagePath is the sparse file backing the cluster disk
+const imagePath = ContainerPath + "/disk.img"
+
+// ReadyPath is written in the disk container once the cluster disk is
+// mounted and the node directories exist
+const ReadyPath = "/run/kind-disk-ready"
+
+// NodeStorageDirs are the node directories that are placed on the cluster
+// disk, instead of anonymous volumes
+var NodeStorageDirs = []string{"/var/lib/containerd", "/var/lib/kubelet", "/var/log"}
+
+// Name returns the container name of the disk container for the cluster
+func Name(clusterName string) string {
+	return fmt.Sprintf("%s-%s", clusterName, constants.DiskNodeRoleValue)
+}
+
+// HostPath returns the directory holding the cluster's disk on the host
+func HostPath(clusterName string) string {
+	return path.Join(HostDir, clusterName)
+}
+
+// HostPathFor returns HostPath for the cluster of the disk container name
+func HostPathFor(name string) string {
+	return HostPath(strings.TrimSuffix(name, "-"+constants.DiskNodeRoleValue))
+}
+
+// PrepareArgs returns the container run args, after "run", that create
+// HostPath. Unlike docker, podman does not create missing bind mount sources
+func PrepareArgs(clusterName, image string) []string {
+	parent := path.Dir(HostDir)
+	return []string{
+		"--rm",
+		"--volume", fmt.Sprintf("%s:/host%s", parent, parent),
+		"--entrypoint", "mkdir",
+		image,
+		"-p", path.Join("/host", HostPath(clusterName)),
+	}
+}
+
+// Command creates, if needed, and mounts the cluster disk of size bytes
+// with a directory for each node's storage, and then keeps the disk
+// container running
+func Command(size int64, nodeNames []string) []string {
+	dirs := []string{}
+	for _, node := range nodeNames {
+		for _, dir := range NodeStorageDirs {
+			dirs = append(dirs, path.Join(MountPath, node, path.Base(dir)))
+		}
+	}
+	script := strings.Join([]string{
+		"set -e",
+		"mkdir -p " + MountPath,
+		fmt.Sprintf("if [ ! -f %s ]; then truncate -s %d %s && mkfs.ext4 -q -F %s; fi", imagePath, size, imagePath, imagePath),
+		fmt.Sprintf("mountpoint -q %s || mount -o loop %s %s", MountPath, imagePath, MountPath),
+		"mkdir -p " + strings.Join(dirs, " "),
+		"touch " + ReadyPath,
+		"exec sleep infinity",
+	}, "\n")
+	return []string{"sh", "-c", script}
+}
+
+// CleanupCommand unmounts and removes the cluster disk, it is run in the
+// disk container once the nodes using the disk are deleted
+var CleanupCommand = []string{
+	"sh", "-c",
+	fmt.Sprintf("umount %s || true; rm -rf %s %s", MountPath, MountPath, imagePath),
+}
+
+// NodeMountArgs returns the container run args placing the node's storage
+// on the cluster disk. These are --mount rather than --volume args so that
+// creating the node fails instead of using the host disk if the cluster disk
+// is not mounted
+func NodeMountArgs(clusterName, nodeName string) []string {
+	args := []string{}
+	for _, dir := range NodeStorageDirs {
+		source := path.Join(HostPath(clusterName), strings.TrimPrefix(MountPath, ContainerPath), nodeName, path.Base(dir))
+		args = append(args, "--mount", fmt.Sprintf("type=bind,source=%s,destination=%s", source, dir))
+	}
+	return args
+}
+
+// readyTimeout bounds waiting for the disk container, creating the
+// filesystem on a sparse file takes a few seconds at most
+const readyTimeout = time.Minute
+
+// WaitReady waits for the disk container name to write ReadyPath, binaryName
+// is the container runtime CLI, e.g. "docker"
+func WaitReady(ctx context.Context, binaryName, name string) error {
+	deadline := time.Now().Add(readyTimeout)
+	for {
+		if exec.CommandContext(ctx, binaryName, "exec", name, "test", "-f", ReadyPath).Run() == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errors.Errorf("timed out waiting for %s to mount the cluster disk, check its logs", name)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disk
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestNodeMountArgs(t *testing.T) {
+	t.Parallel()
+	assert.StringEqual(t,
+		"--mount type=bind,source=/var/lib/kind-disks/kind/mnt/kind-worker/containerd,destination=/var/lib/containerd "+
+			"--mount type=bind,source=/var/lib/kind-disks/kind/mnt/kind-worker/kubelet,destination=/var/lib/kubelet "+
+			"--mount type=bind,source=/var/lib/kind-disks/kind/mnt/kind-worker/log,destination=/var/log",
+		strings.Join(NodeMountArgs("kind", "kind-worker"), " "),
+	)
+}
+
+func TestCommand(t *testing.T) {
+	t.Parallel()
+	command := Command(1<<30, []string{"kind-control-plane"})
+	script := command[len(command)-1]
+	for _, expected := range []string{
+		"truncate -s 1073741824 /kind-disk/disk.img",
+		"mkdir -p /kind-disk/mnt/kind-control-plane/containerd /kind-disk/mnt/kind-control-plane/kubelet /kind-disk/mnt/kind-control-plane/log",
+	} {
+		if !strings.Contains(script, expected) {
+			t.Errorf("expected script to contain %q, got:\n%s", expected, script)
+		}
+	}
+}
+
+func TestDuTotal(t *testing.T) {
+	t.Parallel()
+	total, err := duTotal([]string{"1024\t/var/lib/containerd", "2048\t/var/lib/kubelet", "", "1\t/var/log"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if total != 3073 {
+		t.Errorf("expected 3073 but got %d", total)
+	}
+	if _, err := duTotal([]string{"du: cannot access '/var/log'"}); err == nil {
+		t.Errorf("expected an error for unexpected output")
+	}
+}
+
+func TestDfSizeUsed(t *testing.T) {
+	t.Parallel()
+	size, used, err := dfSizeUsed([]string{"  1B-blocks      Used", " 1023303680  2498560"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if size != 1023303680 || used != 2498560 {
+		t.Errorf("expected 1023303680 and 2498560 but got %d and %d", size, used)
+	}
+	if _, _, err := dfSizeUsed([]string{"  1B-blocks      Used"}); err == nil {
+		t.Errorf("expected an error for missing output")
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disk
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
+)
+
+// Usage is the disk space used by a cluster
+type Usage struct {
+	// Nodes is the space used by each node's storage, ordered like ListNodes
+	Nodes []NodeUsage
+	// Size is the usable size of the cluster disk in bytes, zero if the
+	// cluster has no diskQuota
+	Size int64
+	// Used is the space used on the cluster disk in bytes, including
+	// filesystem overhead
+	Used int64
+}
+
+// NodeUsage is the disk space used by a node's storage
+type NodeUsage struct {
+	Name  string
+	Bytes int64
+}
+
+// ClusterUsage returns the disk space used by the cluster's nodes, and on
+// its cluster disk if it has a diskQuota. The nodes must be running
+func ClusterUsage(ctx context.Context, p provider.Provider, cluster string) (*Usage, error) {
+	n, err := p.ListNodes(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	if len(n) == 0 {
+		return nil, errors.ClusterNotFound(cluster)
+	}
+	internalNodes, err := nodeutils.InternalNodes(n)
+	if err != nil {
+		return nil, err
+	}
+	usage := &Usage{}
+	for _, node := range internalNodes {
+		// -x keeps du off of the container and pod volume mounts below
+		args := append([]string{"-sxb"}, NodeStorageDirs...)
+		lines, err := exec.OutputLines(node.CommandContext(ctx, "du", args...))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get disk usage of node %s", node.String())
+		}
+		bytes, err := duTotal(lines)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get disk usage of node %s", node.String())
+		}
+		usage.Nodes = append(usage.Nodes, NodeUsage{Name: node.String(), Bytes: bytes})
+	}
+
+	disks, err := nodeutils.SelectNodesByRole(n, constants.DiskNodeRoleValue)
+	if err != nil {
+		return nil, err
+	}
+	if len(disks) == 0 {
+		return usage, nil
+	}
+	lines, err := exec.OutputLines(disks[0].CommandContext(ctx, "df", "-B1", "--output=size,used", MountPath))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster disk usage")
+	}
+	usage.Size, usage.Used, err = dfSizeUsed(lines)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster disk usage")
+	}
+	return usage, nil
+}
+
+// SplitNodes separates disk containers from the other nodes, for deleting
+// them last
+func SplitNodes(allNodes []nodes.Node) (others, disks []nodes.Node, err error) {
+	for _, node := range allNodes {
+		role, err := node.Role()
+		if err != nil {
+			return nil, nil, err
+		}
+		if role == constants.DiskNodeRoleValue {
+			disks = append(disks, node)
+		} else {
+			others = append(others, node)
+		}
+	}
+	return others, disks, nil
+}
+
+// duTotal sums the sizes in du -s output lines, like "1024\t/var/log"
+func duTotal(lines []string) (int64, error) {
+	var total int64
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		bytes, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return 0, errors.Errorf("unexpected du output: %q", line)
+		}
+		total += bytes
+	}
+	return total, nil
+}
+
+// dfSizeUsed parses df --output=size,used output, a header and one line
+func dfSizeUsed(lines []string) (int64, int64, error) {
+	if len(lines) != 2 {
+		return 0, 0, errors.Errorf("unexpected df output: %q", strings.Join(lines, "\n"))
+	}
+	fields := strings.Fields(lines[1])
+	if len(fields) != 2 {
+		return 0, 0, errors.Errorf("unexpected df output: %q", lines[1])
+	}
+	size, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return 0, 0, errors.Errorf("unexpected df output: %q", lines[1])
+	}
+	used, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return 0, 0, errors.Errorf("unexpected df output: %q", lines[1])
+	}
+	return size, used, nil
+}
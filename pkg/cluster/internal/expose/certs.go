@@ -0,0 +1,135 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package expose
+
+import (
+	"bytes"
+	"net"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/patch"
+)
+
+const (
+	kubeadmConfigPath = "/kind/kubeadm.conf"
+	apiServerCertPath = "/etc/kubernetes/pki/apiserver.crt"
+	apiServerKeyPath  = "/etc/kubernetes/pki/apiserver.key"
+)
+
+// addCertSANs regenerates the API server certificate on the control plane
+// node with sans if it is missing any of them, the API server reloads it
+// without restarting
+func addCertSANs(node nodes.Node, sans []string) error {
+	var buff bytes.Buffer
+	if err := node.Command("cat", kubeadmConfigPath).SetStdout(&buff).Run(); err != nil {
+		return errors.Wrapf(err, "failed to read the kubeadm config of %s", node.String())
+	}
+	kubeadmConfig, changed, err := withCertSANs(buff.String(), sans)
+	if err != nil || !changed {
+		return err
+	}
+	if err := nodeutils.WriteFile(node, kubeadmConfigPath, kubeadmConfig); err != nil {
+		return errors.Wrapf(err, "failed to write the kubeadm config of %s", node.String())
+	}
+
+	// kubeadm keeps an existing certificate, so move it aside until the new
+	// one has been generated
+	if err := node.Command("mv", apiServerCertPath, apiServerCertPath+".bak").Run(); err != nil {
+		return errors.Wrapf(err, "failed to move the API server certificate of %s", node.String())
+	}
+	if err := node.Command("mv", apiServerKeyPath, apiServerKeyPath+".bak").Run(); err != nil {
+		return errors.Wrapf(err, "failed to move the API server key of %s", node.String())
+	}
+	if err := node.Command(
+		"kubeadm", "init", "phase", "certs", "apiserver", "--config", kubeadmConfigPath,
+	).Run(); err != nil {
+		_ = node.Command("mv", apiServerCertPath+".bak", apiServerCertPath).Run()
+		_ = node.Command("mv", apiServerKeyPath+".bak", apiServerKeyPath).Run()
+		return errors.Wrapf(err, "failed to regenerate the API server certificate of %s", node.String())
+	}
+	return node.Command("rm", "-f", apiServerCertPath+".bak", apiServerKeyPath+".bak").Run()
+}
+
+// withCertSANs returns kubeadmConfig with sans added to the API server
+// certSANs of the ClusterConfiguration, and whether any were missing
+func withCertSANs(kubeadmConfig string, sans []string) (string, bool, error) {
+	found, changed := false, false
+	addSANs := func(kind string, doc map[string]interface{}) error {
+		if kind != "ClusterConfiguration" {
+			return nil
+		}
+		apiServer, ok := doc["apiServer"].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		list, ok := apiServer["certSANs"].([]interface{})
+		if !ok {
+			return nil
+		}
+		found = true
+		existing := map[string]bool{}
+		for _, san := range list {
+			if s, ok := san.(string); ok {
+				existing[s] = true
+			}
+		}
+		for _, san := range sans {
+			if !existing[san] {
+				existing[san] = true
+				list = append(list, san)
+				changed = true
+			}
+		}
+		apiServer["certSANs"] = list
+		return nil
+	}
+	mutated, err := patch.KubeYAMLMutate(kubeadmConfig, []patch.Mutator{addSANs})
+	if err != nil {
+		return "", false, errors.Wrap(err, "failed to parse the kubeadm config")
+	}
+	if !found {
+		return "", false, errors.New("failed to find the API server certSANs in the kubeadm config")
+	}
+	if !changed {
+		return kubeadmConfig, false, nil
+	}
+	return mutated, true, nil
+}
+
+// defaultSANs returns the names remote clients may reach the host by when it
+// listens on address, the addresses are listed before the host name
+func defaultSANs(address net.IP, ipv6 bool, hostname func() (string, error), interfaceAddrs func() ([]net.Addr, error)) []string {
+	sans := []string{}
+	if !address.IsUnspecified() {
+		sans = append(sans, address.String())
+	} else if addrs, err := interfaceAddrs(); err == nil {
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok || !ipNet.IP.IsGlobalUnicast() || (ipNet.IP.To4() == nil) != ipv6 {
+				continue
+			}
+			sans = append(sans, ipNet.IP.String())
+		}
+	}
+	if name, err := hostname(); err == nil && name != "" {
+		sans = append(sans, name)
+	}
+	return sans
+}
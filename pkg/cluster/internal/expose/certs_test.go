@@ -0,0 +1,117 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package expose
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+
+	kubeadmconfig "sigs.k8s.io/kind/pkg/cluster/internal/create/actions/config"
+)
+
+func TestWithCertSANs(t *testing.T) {
+	t.Parallel()
+	// the kubeadm config exactly as it is written to the node
+	cfg := &config.Cluster{
+		Nodes: []config.Node{{Role: config.ControlPlaneRole}},
+	}
+	config.SetDefaultsCluster(cfg)
+	data := kubeadmconfig.KubeadmConfigData(cfg, "kind-control-plane:6443")
+	data.KubernetesVersion = "v1.30.0"
+	data.NodeAddress = "172.18.0.2"
+	data.ControlPlane = true
+	rendered, err := kubeadmconfig.RenderKubeadmConfig(cfg, data, "kind-control-plane", &cfg.Nodes[0], nil)
+	assert.ExpectError(t, false, err)
+
+	result, changed, err := withCertSANs(rendered, []string{"127.0.0.1", "192.168.1.10", "devbox"})
+	assert.ExpectError(t, false, err)
+	assert.BoolEqual(t, true, changed)
+	expected := "certSANs:\n  - localhost\n  - 127.0.0.1\n  - 192.168.1.10\n  - devbox\n"
+	if !strings.Contains(result, expected) {
+		t.Errorf("expected config to contain %q, got:\n%s", expected, result)
+	}
+	// the other documents are kept
+	for _, kind := range []string{"kind: InitConfiguration", "kind: KubeletConfiguration", "kind: KubeProxyConfiguration"} {
+		if !strings.Contains(result, kind) {
+			t.Errorf("expected config to contain %q, got:\n%s", kind, result)
+		}
+	}
+
+	// all SANs present
+	again, changed, err := withCertSANs(result, []string{"localhost", "devbox"})
+	assert.ExpectError(t, false, err)
+	assert.BoolEqual(t, false, changed)
+	assert.StringEqual(t, result, again)
+
+	// no certSANs
+	_, _, err = withCertSANs("apiVersion: kubeadm.k8s.io/v1beta2\nkind: ClusterConfiguration\n", []string{"devbox"})
+	assert.ExpectError(t, true, err)
+}
+
+func TestDefaultSANs(t *testing.T) {
+	t.Parallel()
+	hostname := func() (string, error) { return "devbox", nil }
+	interfaceAddrs := func() ([]net.Addr, error) {
+		return []net.Addr{
+			&net.IPNet{IP: net.ParseIP("127.0.0.1")},
+			&net.IPNet{IP: net.ParseIP("192.168.1.10")},
+			&net.IPNet{IP: net.ParseIP("fe80::1")},
+			&net.IPNet{IP: net.ParseIP("2001:db8::10")},
+		}, nil
+	}
+	noAddrs := func() ([]net.Addr, error) { return nil, errors.New("no addresses") }
+	cases := []struct {
+		Name           string
+		Address        string
+		IPv6           bool
+		InterfaceAddrs func() ([]net.Addr, error)
+		Expect         []string
+	}{
+		{
+			Name:           "specific address",
+			Address:        "100.64.0.2",
+			InterfaceAddrs: noAddrs,
+			Expect:         []string{"100.64.0.2", "devbox"},
+		},
+		{
+			Name:           "all IPv4 interfaces",
+			Address:        "0.0.0.0",
+			InterfaceAddrs: interfaceAddrs,
+			Expect:         []string{"192.168.1.10", "devbox"},
+		},
+		{
+			Name:           "all IPv6 interfaces",
+			Address:        "::",
+			IPv6:           true,
+			InterfaceAddrs: interfaceAddrs,
+			Expect:         []string{"2001:db8::10", "devbox"},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			result := defaultSANs(net.ParseIP(tc.Address), tc.IPv6, hostname, tc.InterfaceAddrs)
+			assert.DeepEqual(t, tc.Expect, result)
+		})
+	}
+}
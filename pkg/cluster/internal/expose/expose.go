@@ -0,0 +1,219 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package expose
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/loadbalancer"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
+	"sigs.k8s.io/kind/pkg/cluster/internal/state"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/cli"
+)
+
+// Options configures how a cluster is exposed
+type Options struct {
+	// Address is the host address to listen on, e.g. 0.0.0.0 for all interfaces
+	Address string
+	// Port is the host port for the API server, zero picks a free port
+	Port int32
+	// NodePorts are also published on Address, on the same host port
+	NodePorts []int32
+	// SANs are added to the API server certificate, the first is used as the
+	// kubeconfig server. When empty they default to Address and the host name,
+	// or the host's addresses and name if Address is unspecified
+	SANs []string
+}
+
+// Cluster (re)creates the expose proxy container for cluster, which
+// forwards to the control plane and nodes from opts.Address, and adds the
+// SANs to the API server certificates. It returns the API server endpoint
+// for remote clients.
+func Cluster(ctx context.Context, logger log.Logger, p provider.Provider, cluster string, opts Options) (string, error) {
+	n, err := p.ListNodes(ctx, cluster)
+	if err != nil {
+		return "", err
+	}
+	if len(n) == 0 {
+		return "", errors.ClusterNotFound(cluster)
+	}
+	ip := net.ParseIP(opts.Address)
+	if ip == nil {
+		return "", errors.Errorf("invalid address %q, it must be an IP", opts.Address)
+	}
+	cfg, err := state.Default().ReadConfig(cluster)
+	if err != nil {
+		return "", err
+	}
+	if cfg.ExternalControlPlane.Endpoint != "" {
+		return "", errors.Errorf("cluster %q uses an external control plane, there is no API server to expose", cluster)
+	}
+	sans := opts.SANs
+	if len(sans) == 0 {
+		sans = defaultSANs(ip, cfg.Networking.IPFamily == config.IPv6Family, os.Hostname, net.InterfaceAddrs)
+	}
+	if len(sans) == 0 {
+		return "", errors.New("failed to detect the host's addresses, specify the SANs to use")
+	}
+
+	// re-running replaces any previous proxy
+	if err := Delete(ctx, p, cluster); err != nil {
+		return "", err
+	}
+	port := opts.Port
+	if port == 0 {
+		port, err = common.GetFreePort(opts.Address)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to get a free API server port")
+		}
+	}
+	portMappings := []config.PortMapping{{
+		ListenAddress: opts.Address,
+		HostPort:      port,
+		ContainerPort: common.APIServerInternalPort,
+	}}
+	for _, nodePort := range opts.NodePorts {
+		portMappings = append(portMappings, config.PortMapping{
+			ListenAddress: opts.Address,
+			HostPort:      nodePort,
+			ContainerPort: nodePort,
+		})
+	}
+
+	status := cli.StatusForLogger(logger)
+	status.Start("Starting the expose proxy 🌐")
+	defer status.End(false)
+	name := common.MakeNodeNamer(cluster)(constants.ExposeProxyNodeRoleValue)
+	if err := p.CreateProxy(ctx, cfg, name, portMappings); err != nil {
+		return "", err
+	}
+	if err := configureProxy(ctx, p, cluster, cfg, opts.NodePorts); err != nil {
+		return "", err
+	}
+	status.End(true)
+
+	status.Start("Adding the exposed addresses to the API server certificate 📜")
+	controlPlanes, err := nodeutils.ControlPlaneNodes(n)
+	if err != nil {
+		return "", err
+	}
+	fns := []func() error{}
+	for _, node := range controlPlanes {
+		node := node // capture loop variable
+		fns = append(fns, func() error {
+			return addCertSANs(node, sans)
+		})
+	}
+	if err := errors.UntilErrorConcurrent(fns); err != nil {
+		return "", err
+	}
+	status.End(true)
+	return net.JoinHostPort(sans[0], fmt.Sprintf("%d", port)), nil
+}
+
+// Delete removes the expose proxy of cluster, if there is one
+func Delete(ctx context.Context, p provider.Provider, cluster string) error {
+	n, err := p.ListNodes(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	proxies, err := nodeutils.SelectNodesByRole(n, constants.ExposeProxyNodeRoleValue)
+	if err != nil || len(proxies) == 0 {
+		return err
+	}
+	return p.DeleteNodes(ctx, proxies)
+}
+
+// configureProxy writes the load balancer config for the proxy, forwarding
+// the API server to the control plane nodes and nodePorts to every node
+func configureProxy(ctx context.Context, p provider.Provider, cluster string, cfg *config.Cluster, nodePorts []int32) error {
+	n, err := p.ListNodes(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	proxies, err := nodeutils.SelectNodesByRole(n, constants.ExposeProxyNodeRoleValue)
+	if err != nil {
+		return err
+	}
+	if len(proxies) != 1 {
+		return errors.Errorf("expected one expose proxy for cluster %q, found %d", cluster, len(proxies))
+	}
+	controlPlanes, err := nodeutils.ControlPlaneNodes(n)
+	if err != nil {
+		return err
+	}
+	internalNodes, err := nodeutils.InternalNodes(n)
+	if err != nil {
+		return err
+	}
+
+	backendServers := map[string]string{}
+	for _, node := range controlPlanes {
+		backendServers[node.String()] = fmt.Sprintf("%s:%d", node.String(), common.APIServerInternalPort)
+	}
+	frontends := make([]loadbalancer.Frontend, 0, len(nodePorts))
+	for _, nodePort := range nodePorts {
+		servers := map[string]string{}
+		for _, node := range internalNodes {
+			servers[node.String()] = fmt.Sprintf("%s:%d", node.String(), nodePort)
+		}
+		frontends = append(frontends, loadbalancer.Frontend{
+			Name:    fmt.Sprintf("nodeport-%d", nodePort),
+			Port:    int(nodePort),
+			Servers: servers,
+		})
+	}
+	lb := cfg.LoadBalancer
+	interval, err := time.ParseDuration(lb.HealthCheck.Interval)
+	if err != nil {
+		return errors.Wrap(err, "invalid loadBalancer.healthCheck.interval")
+	}
+	proxyConfig, err := loadbalancer.ConfigFor(lb.Implementation, &loadbalancer.ConfigData{
+		ControlPlanePort: common.APIServerInternalPort,
+		BackendServers:   backendServers,
+		IPv6:             cfg.Networking.IPFamily == config.IPv6Family,
+		HealthCheck: loadbalancer.HealthCheck{
+			IntervalMillis: interval.Milliseconds(),
+			Rise:           lb.HealthCheck.Rise,
+			Fall:           lb.HealthCheck.Fall,
+		},
+		ExtraFrontends: frontends,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to generate expose proxy config")
+	}
+	proxy := proxies[0]
+	if err := nodeutils.WriteFile(proxy, loadbalancer.ConfigPathFor(lb.Implementation), proxyConfig); err != nil {
+		return errors.Wrap(err, "failed to copy expose proxy config to node")
+	}
+	// haproxy and nginx will reload on SIGHUP
+	if err := proxy.Command("kill", "-s", "HUP", "1").Run(); err != nil {
+		return errors.Wrap(err, "failed to reload expose proxy")
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package heal implements restarting the exited nodes of a cluster
+package heal
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/loadbalancer"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
+	"sigs.k8s.io/kind/pkg/cluster/internal/state"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/internal/cli"
+)
+
+// Cluster restarts the node containers of cluster that have exited, waits
+// for them to boot and then re-runs the fixups that depend on the node
+// addresses, which may have changed. It returns the restarted nodes.
+func Cluster(ctx context.Context, logger log.Logger, p provider.Provider, cluster string) ([]nodes.Node, error) {
+	n, err := p.ListNodes(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	if len(n) == 0 {
+		return nil, errors.ClusterNotFound(cluster)
+	}
+	inspected, err := p.InspectNodes(ctx, n)
+	if err != nil {
+		return nil, err
+	}
+	exited, err := exitedNodes(n, inspected)
+	if err != nil || len(exited) == 0 {
+		return nil, err
+	}
+
+	status := cli.StatusForLogger(logger)
+	status.Start("Restarting exited nodes 🩹")
+	defer status.End(false)
+	if err := p.RestartNodes(ctx, exited); err != nil {
+		return nil, err
+	}
+	// only the kubernetes nodes run systemd, not e.g. the load balancer
+	kubeNodes, err := nodeutils.InternalNodes(exited)
+	if err != nil {
+		return nil, err
+	}
+	if err := nodeutils.WaitForBoot(ctx, kubeNodes...); err != nil {
+		return nil, err
+	}
+	fns := []func() error{}
+	for _, node := range kubeNodes {
+		node := node // capture loop variable
+		fns = append(fns, func() error {
+			if err := node.Command("bash", "-c", dnsFixupScript).Run(); err != nil {
+				return errors.Wrapf(err, "failed to fix up DNS on node %s", node.String())
+			}
+			return nil
+		})
+	}
+	if err := errors.UntilErrorConcurrent(fns); err != nil {
+		return nil, err
+	}
+	status.End(true)
+
+	// restarted nodes may have new addresses, re-point the load balancer
+	if err := reconfigureLoadBalancer(ctx, logger, status, p, cluster, n); err != nil {
+		return nil, err
+	}
+	return exited, nil
+}
+
+// dnsFixupScript runs the node image's fix-dns, which the entrypoint also runs
+// at boot to point the node's DNS at the container runtime host rather than
+// docker's embedded resolver on loopback. This catches a resolv.conf restored
+// by the runtime on restart, older node images only fix DNS at boot
+const dnsFixupScript = `if command -v fix-dns >/dev/null; then fix-dns; fi`
+
+// reconfigureLoadBalancer updates the load balancer of cluster, if any, for
+// the current control plane nodes in allNodes
+func reconfigureLoadBalancer(ctx context.Context, logger log.Logger, status *cli.Status, p provider.Provider, cluster string, allNodes []nodes.Node) error {
+	recorded, err := state.Default().Read(cluster)
+	if err != nil {
+		return err
+	}
+	if recorded != nil && recorded.Config != "" {
+		cfg, err := state.Default().ReadConfig(cluster)
+		if err != nil {
+			return err
+		}
+		actionsContext := actions.NewActionContext(ctx, logger, status, p, cfg)
+		return loadbalancer.NewAction().Execute(actionsContext)
+	}
+	// without the config the load balancer config can't be regenerated,
+	// but it names the backends by node name, so a reload resolves their
+	// new addresses
+	lb, err := nodeutils.ExternalLoadBalancerNode(allNodes)
+	if err != nil || lb == nil {
+		return err
+	}
+	if err := lb.Command("kill", "-s", "HUP", "1").Run(); err != nil {
+		return errors.Wrap(err, "failed to reload loadbalancer")
+	}
+	return nil
+}
+
+// inspectedContainer is the subset of docker / podman inspect output we need
+type inspectedContainer struct {
+	Name  string
+	State struct {
+		Running bool
+	}
+}
+
+// exitedNodes returns the nodes in n that are not running according to
+// inspected, the container runtime's inspect output for n
+func exitedNodes(n []nodes.Node, inspected []byte) ([]nodes.Node, error) {
+	containers := []inspectedContainer{}
+	if err := json.Unmarshal(inspected, &containers); err != nil {
+		return nil, errors.Wrap(err, "failed to decode node container details")
+	}
+	running := map[string]bool{}
+	for _, c := range containers {
+		// docker reports names with a leading slash
+		running[strings.TrimPrefix(c.Name, "/")] = c.State.Running
+	}
+	exited := []nodes.Node{}
+	for _, node := range n {
+		if !running[node.String()] {
+			exited = append(exited, node)
+		}
+	}
+	return exited, nil
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package heal
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+type fakeNode struct {
+	nodes.Node
+	name string
+}
+
+func (n *fakeNode) String() string {
+	return n.name
+}
+
+func TestExitedNodes(t *testing.T) {
+	t.Parallel()
+	all := []nodes.Node{
+		&fakeNode{name: "kind-control-plane"},
+		&fakeNode{name: "kind-worker"},
+		&fakeNode{name: "kind-worker2"},
+	}
+	// docker prefixes names with a slash, podman does not
+	inspected := `[
+  {"Name": "/kind-control-plane", "State": {"Running": true, "Status": "running"}},
+  {"Name": "kind-worker", "State": {"Running": false, "Status": "exited"}}
+]`
+	exited, err := exitedNodes(all, []byte(inspected))
+	assert.ExpectError(t, false, err)
+	names := []string{}
+	for _, n := range exited {
+		names = append(names, n.String())
+	}
+	// a node missing from the output is not running either
+	assert.DeepEqual(t, []string{"kind-worker", "kind-worker2"}, names)
+
+	_, err = exitedNodes(all, []byte("not json"))
+	assert.ExpectError(t, true, err)
+}
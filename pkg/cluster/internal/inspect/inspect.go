@@ -0,0 +1,204 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package inspect reports everything kind resolved for a cluster, from the
+// defaulted config down to the files it generated on each node
+package inspect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/loadbalancer"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
+	"sigs.k8s.io/kind/pkg/cluster/internal/state"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+const (
+	// YAML is the YAML output format
+	YAML = "yaml"
+	// JSON is the JSON output format
+	JSON = "json"
+)
+
+// Report is the resolved configuration of a cluster
+type Report struct {
+	// Name is the cluster name
+	Name string `json:"name"`
+	// Provider is the node provider, e.g. "docker"
+	Provider string `json:"provider"`
+	// Config is the fully defaulted internal cluster config, nil if the
+	// cluster did not record it
+	Config *config.Cluster `json:"config"`
+	// Nodes are the cluster's nodes, sorted by name
+	Nodes []Node `json:"nodes"`
+	// Errors are problems with the cluster as a whole, e.g. missing state
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Node is the resolved configuration of a node
+type Node struct {
+	// Name is the node container name
+	Name string `json:"name"`
+	// Role is the node role, e.g. "control-plane"
+	Role string `json:"role"`
+	// Files are the contents of the files kind generated on the node, by path
+	Files map[string]string `json:"files,omitempty"`
+	// Container is the node provider's inspect output for the node container
+	Container interface{} `json:"container,omitempty"`
+	// Errors are failures reading the files, e.g. because the node is stopped
+	Errors []string `json:"errors,omitempty"`
+}
+
+// generatedFiles returns the files kind generates on a node with role, cfg
+// may be nil if it was not recorded
+func generatedFiles(cfg *config.Cluster, role string) []string {
+	if role == constants.ExternalLoadBalancerNodeRoleValue || role == constants.ExposeProxyNodeRoleValue {
+		var implementation config.LoadBalancerImplementation
+		if cfg != nil {
+			implementation = cfg.LoadBalancer.Implementation
+		}
+		return []string{loadbalancer.ConfigPathFor(implementation)}
+	}
+	return []string{"/kind/kubeadm.conf", "/etc/containerd/config.toml"}
+}
+
+// Cluster gathers the Report for cluster. Files that cannot be read are
+// recorded in the node's Errors, so that broken clusters can be inspected.
+// A cluster without a recorded config is reported without one, rather than
+// with the default config it may not have been created from.
+func Cluster(ctx context.Context, p provider.Provider, cluster string) (*Report, error) {
+	n, err := p.ListNodes(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	if len(n) == 0 {
+		return nil, errors.ClusterNotFound(cluster)
+	}
+	report := &Report{Name: cluster, Provider: p.String()}
+	recorded, err := state.Default().Read(cluster)
+	if err != nil {
+		return nil, err
+	}
+	var cfg *config.Cluster
+	if recorded == nil || recorded.Config == "" {
+		report.Errors = append(report.Errors, fmt.Sprintf("cluster %q has no recorded config, it was created by an older kind version or its state was removed", cluster))
+	} else if cfg, err = state.Default().ReadConfig(cluster); err != nil {
+		return nil, err
+	}
+	report.Config = cfg
+	inspected, err := p.InspectNodes(ctx, n)
+	if err != nil {
+		return nil, err
+	}
+	containers, err := containersByName(inspected)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(n, func(i, j int) bool { return n[i].String() < n[j].String() })
+	for _, node := range n {
+		role, err := node.Role()
+		if err != nil {
+			return nil, err
+		}
+		r := Node{
+			Name:      node.String(),
+			Role:      role,
+			Files:     map[string]string{},
+			Container: containers[node.String()],
+		}
+		for _, path := range generatedFiles(cfg, role) {
+			contents, err := readFile(node, path)
+			if err != nil {
+				r.Errors = append(r.Errors, err.Error())
+				continue
+			}
+			r.Files[path] = contents
+		}
+		report.Nodes = append(report.Nodes, r)
+	}
+	return report, nil
+}
+
+func readFile(node nodes.Node, path string) (string, error) {
+	var buff bytes.Buffer
+	if err := node.Command("cat", path).SetStdout(&buff).Run(); err != nil {
+		return "", errors.Wrapf(err, "failed to read %s", path)
+	}
+	return buff.String(), nil
+}
+
+// containersByName splits the JSON array of inspect output by container name
+func containersByName(inspected []byte) (map[string]interface{}, error) {
+	var containers []map[string]interface{}
+	if err := json.Unmarshal(inspected, &containers); err != nil {
+		return nil, errors.Wrap(err, "failed to parse node inspect output")
+	}
+	byName := make(map[string]interface{}, len(containers))
+	for _, c := range containers {
+		name, _ := c["Name"].(string)
+		byName[strings.TrimPrefix(name, "/")] = c
+	}
+	return byName, nil
+}
+
+// Render returns r in format, one of YAML or JSON
+func Render(r *Report, format string) (string, error) {
+	raw, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to encode report")
+	}
+	switch format {
+	case JSON:
+		return string(raw) + "\n", nil
+	case YAML:
+		// JSON is YAML, decoding into a node keeps the field order
+		var doc yaml.Node
+		if err := yaml.Unmarshal(raw, &doc); err != nil {
+			return "", errors.Wrap(err, "failed to convert report to YAML")
+		}
+		resetStyle(&doc)
+		var out bytes.Buffer
+		encoder := yaml.NewEncoder(&out)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(&doc); err != nil {
+			return "", errors.Wrap(err, "failed to encode report as YAML")
+		}
+		return out.String(), nil
+	}
+	return "", errors.Errorf("unknown format %q, expected one of: %s, %s", format, YAML, JSON)
+}
+
+// resetStyle drops the JSON flow and quoting styles so that the file
+// contents in the report are rendered as readable YAML block literals
+func resetStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		resetStyle(c)
+	}
+}
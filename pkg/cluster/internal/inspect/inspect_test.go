@@ -0,0 +1,77 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inspect
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+	r := &Report{
+		Name:     "kind",
+		Provider: "docker",
+		Config:   &config.Cluster{Name: "kind"},
+		Nodes: []Node{{
+			Name:  "kind-control-plane",
+			Role:  "control-plane",
+			Files: map[string]string{"/kind/kubeadm.conf": "kind: ClusterConfiguration\nclusterName: \"true\"\n"},
+		}},
+	}
+	out, err := Render(r, YAML)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, expected := range []string{
+		"name: kind\nprovider: docker\n",
+		"/kind/kubeadm.conf: |\n",
+		"        clusterName: \"true\"\n",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected YAML to contain %q, got:\n%s", expected, out)
+		}
+	}
+	out, err = Render(r, JSON)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(out, "{\n  \"name\": \"kind\",\n") {
+		t.Errorf("unexpected JSON:\n%s", out)
+	}
+	if _, err := Render(r, "toml"); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
+
+func TestContainersByName(t *testing.T) {
+	t.Parallel()
+	byName, err := containersByName([]byte(`[{"Name": "/kind-control-plane"}, {"Name": "kind-worker"}]`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, name := range []string{"kind-control-plane", "kind-worker"} {
+		if byName[name] == nil {
+			t.Errorf("expected container %q in %v", name, byName)
+		}
+	}
+	if _, err := containersByName([]byte(`{`)); err == nil {
+		t.Errorf("expected an error for invalid inspect output")
+	}
+}
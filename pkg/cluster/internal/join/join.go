@@ -0,0 +1,129 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package join
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
+)
+
+// Options configures the generated join command
+type Options struct {
+	// ControlPlane joins as an additional control plane node, this uploads
+	// the control plane certificates encrypted with a fresh certificate key
+	ControlPlane bool
+	// TTL is the lifetime of the bootstrap token, zero for kubeadm's
+	// default of 24h
+	TTL time.Duration
+	// Endpoint overrides the API server host:port in the command, by default
+	// it is the endpoint the kind nodes use, which is only reachable from
+	// containers on the cluster's network
+	Endpoint string
+}
+
+// Command creates a fresh bootstrap token on the cluster's bootstrap control
+// plane node, and a certificate key if opts.ControlPlane is set, and returns
+// the matching kubeadm join command
+func Command(ctx context.Context, p provider.Provider, cluster string, opts Options) (string, error) {
+	n, err := p.ListNodes(ctx, cluster)
+	if err != nil {
+		return "", err
+	}
+	if len(n) == 0 {
+		return "", errors.ClusterNotFound(cluster)
+	}
+	node, err := nodeutils.BootstrapControlPlaneNode(n)
+	if err != nil {
+		return "", errors.Wrapf(err, "cluster %q has no control plane to join", cluster)
+	}
+
+	args := []string{"token", "create", "--print-join-command", "--kubeconfig=/etc/kubernetes/admin.conf"}
+	if opts.TTL != 0 {
+		args = append(args, fmt.Sprintf("--ttl=%s", opts.TTL))
+	}
+	lines, err := exec.OutputLines(node.CommandContext(ctx, "kubeadm", args...))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create a bootstrap token")
+	}
+	command, err := joinCommandFromOutput(lines)
+	if err != nil {
+		return "", err
+	}
+	if opts.Endpoint != "" {
+		command = withEndpoint(command, opts.Endpoint)
+	}
+	if !opts.ControlPlane {
+		return command, nil
+	}
+
+	// the certificates are uploaded to the kubeadm-certs secret, encrypted
+	// with the key, where joining control plane nodes download them from
+	lines, err = exec.OutputLines(node.CommandContext(ctx,
+		"kubeadm", "init", "phase", "upload-certs", "--upload-certs", "--config=/kind/kubeadm.conf",
+	))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to upload the control plane certificates")
+	}
+	key, err := certificateKeyFromOutput(lines)
+	if err != nil {
+		return "", err
+	}
+	return command + " --control-plane --certificate-key " + key, nil
+}
+
+// joinCommandFromOutput returns the join command printed by
+// `kubeadm token create --print-join-command`
+func joinCommandFromOutput(lines []string) (string, error) {
+	for i := len(lines) - 1; i >= 0; i-- {
+		if line := strings.TrimSpace(lines[i]); strings.HasPrefix(line, "kubeadm join ") {
+			return line, nil
+		}
+	}
+	return "", errors.Errorf("unexpected kubeadm token create output: %q", lines)
+}
+
+// withEndpoint replaces the API server endpoint of the join command
+func withEndpoint(command, endpoint string) string {
+	fields := strings.Fields(command)
+	if len(fields) < 3 {
+		return command
+	}
+	fields[2] = endpoint
+	return strings.Join(fields, " ")
+}
+
+var certificateKeyRE = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// certificateKeyFromOutput returns the certificate key printed by
+// `kubeadm init phase upload-certs --upload-certs`
+func certificateKeyFromOutput(lines []string) (string, error) {
+	for i := len(lines) - 1; i >= 0; i-- {
+		if line := strings.TrimSpace(lines[i]); certificateKeyRE.MatchString(line) {
+			return line, nil
+		}
+	}
+	return "", errors.Errorf("unexpected kubeadm upload-certs output: %q", lines)
+}
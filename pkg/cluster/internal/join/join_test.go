@@ -0,0 +1,54 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package join
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestJoinCommandFromOutput(t *testing.T) {
+	t.Parallel()
+	command, err := joinCommandFromOutput([]string{
+		"kubeadm join kind-control-plane:6443 --token abcdef.0123456789abcdef --discovery-token-ca-cert-hash sha256:0123 ",
+	})
+	assert.ExpectError(t, false, err)
+	assert.StringEqual(t, "kubeadm join kind-control-plane:6443 --token abcdef.0123456789abcdef --discovery-token-ca-cert-hash sha256:0123", command)
+	assert.StringEqual(t,
+		"kubeadm join 192.168.1.10:6443 --token abcdef.0123456789abcdef --discovery-token-ca-cert-hash sha256:0123",
+		withEndpoint(command, "192.168.1.10:6443"),
+	)
+
+	_, err = joinCommandFromOutput([]string{"error: nope"})
+	assert.ExpectError(t, true, err)
+}
+
+func TestCertificateKeyFromOutput(t *testing.T) {
+	t.Parallel()
+	const key = "5d817a5480c54bb079eab4f7b75b4dfe21bd36e059dfb46bf39f724adb3349aa"
+	got, err := certificateKeyFromOutput([]string{
+		"[upload-certs] Storing the certificates in Secret \"kubeadm-certs\" in the \"kube-system\" Namespace",
+		"[upload-certs] Using certificate key:",
+		key,
+	})
+	assert.ExpectError(t, false, err)
+	assert.StringEqual(t, key, got)
+
+	_, err = certificateKeyFromOutput([]string{"[upload-certs] Using certificate key:"})
+	assert.ExpectError(t, true, err)
+}
@@ -19,7 +19,9 @@ package kubeadm
 import (
 	"bytes"
 	"fmt"
+	"path"
 	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -39,13 +41,20 @@ type ConfigData struct {
 	APIBindPort int
 	// The API server external listen IP (which we will port forward)
 	APIServerAddress string
+	// Any further API server external listen IPs, also in the serving cert
+	AdditionalAPIServerAddresses []string
 	// ControlPlane flag specifies the node belongs to the control plane
 	ControlPlane bool
 	// The main IP address of the node
 	NodeAddress string
+	// NodeLabels are registered for the node by the kubelet, if set
+	NodeLabels map[string]string
 	// The Token for TLS bootstrap
 	Token string
-	// KubeProxyMode defines the kube-proxy mode between iptables or ipvs
+	// CACertHashes verify the control plane CA on join, if empty it is not verified
+	CACertHashes []string
+	// KubeProxyMode defines the kube-proxy mode between iptables or ipvs,
+	// or "none" to omit the kube-proxy configuration
 	KubeProxyMode string
 	// The subnet used for pods
 	PodSubnet string
@@ -54,6 +63,45 @@ type ConfigData struct {
 	// IPv4 values take precedence over IPv6 by default, if true set IPv6 default values
 	IPv6         bool
 	FeatureGates map[string]bool
+	// ComponentFeatureGates are merged over FeatureGates for each component
+	ComponentFeatureGates ComponentFeatureGates
+	// RuntimeConfig is passed to the API server as --runtime-config
+	RuntimeConfig map[string]string
+	// OIDC configures the API server --oidc-* flags, if OIDC.IssuerURL is set
+	OIDC OIDC
+	// AdmissionConfigFile and AuthorizationConfigFile are the paths on the
+	// node of the API server admission and structured authorization config,
+	// if set their directories are mounted into the API server
+	AdmissionConfigFile     string
+	AuthorizationConfigFile string
+	// ContainerLogMaxSize and ContainerLogMaxFiles configure kubelet's
+	// container log rotation, if set
+	ContainerLogMaxSize  string
+	ContainerLogMaxFiles int32
+	// ImageGCHighThresholdPercent, ImageGCLowThresholdPercent and
+	// ImageMinimumGCAge configure kubelet's image garbage collection,
+	// by default image garbage collection is disabled
+	ImageGCHighThresholdPercent int32
+	ImageGCLowThresholdPercent  int32
+	ImageMinimumGCAge           string
+	// KubeletServerTLSBootstrap has the kubelet request a serving certificate
+	// from the cluster CA instead of self-signing one
+	KubeletServerTLSBootstrap bool
+	// ImageCredentialProviderConfig and ImageCredentialProviderBinDir are the
+	// kubelet image credential provider flags, if set
+	ImageCredentialProviderConfig string
+	ImageCredentialProviderBinDir string
+	// ImageRepository is the registry for the control plane images, if set
+	ImageRepository string
+	// CoreDNSImage and EtcdImage override these images, if set, they must
+	// be of the form <repository>/<name>:<tag>
+	CoreDNSImage string
+	EtcdImage    string
+	// EtcdQuotaBackendBytes, EtcdHeartbeatInterval and EtcdElectionTimeout
+	// tune the local etcd if set, the interval and timeout are in milliseconds
+	EtcdQuotaBackendBytes int64
+	EtcdHeartbeatInterval int64
+	EtcdElectionTimeout   int64
 	// DerivedConfigData is populated by Derive()
 	// These auto-generated fields are available to Config templates,
 	// but not meant to be set by hand
@@ -69,6 +117,99 @@ type DerivedConfigData struct {
 	SortedFeatureGateKeys []string
 	// FeatureGatesString is of the form `Foo=true,Baz=false`
 	FeatureGatesString string
+	// APIServerFeatureGatesString, ControllerManagerFeatureGatesString and
+	// SchedulerFeatureGatesString are like FeatureGatesString, but with
+	// the ComponentFeatureGates for that component merged in
+	APIServerFeatureGatesString         string
+	ControllerManagerFeatureGatesString string
+	SchedulerFeatureGatesString         string
+	// KubeletFeatureGates and KubeProxyFeatureGates are FeatureGates with
+	// the ComponentFeatureGates for that component merged in
+	KubeletFeatureGates   map[string]bool
+	KubeProxyFeatureGates map[string]bool
+	// RuntimeConfigString is of the form `api/alpha=true,batch/v2alpha1=false`
+	RuntimeConfigString string
+	// CoreDNSImageRepository, CoreDNSImageTag, EtcdImageRepository and
+	// EtcdImageTag are split from CoreDNSImage and EtcdImage
+	CoreDNSImageRepository string
+	CoreDNSImageTag        string
+	EtcdImageRepository    string
+	EtcdImageTag           string
+	// APIVersion is the kubeadm config API version for KubernetesVersion,
+	// it is set by Config
+	APIVersion APIVersion
+	// ControlPlaneTimeout is how long kubeadm init waits for the control plane
+	ControlPlaneTimeout string
+	// APIServerExtraArgs, ControllerManagerExtraArgs, SchedulerExtraArgs,
+	// KubeletExtraArgs and EtcdExtraArgs are the flags kind sets for each
+	// component
+	APIServerExtraArgs         []ExtraArg
+	ControllerManagerExtraArgs []ExtraArg
+	SchedulerExtraArgs         []ExtraArg
+	KubeletExtraArgs           []ExtraArg
+	EtcdExtraArgs              []ExtraArg
+	// APIServerExtraVolumes are the directories mounted into the API server
+	APIServerExtraVolumes []ExtraVolume
+}
+
+// ExtraVolume is a read-only host directory mounted into a component at
+// the same path
+type ExtraVolume struct {
+	Name string
+	Path string
+}
+
+// OIDC holds the API server OpenID Connect authentication flags
+type OIDC struct {
+	IssuerURL      string
+	ClientID       string
+	CAFile         string
+	UsernameClaim  string
+	UsernamePrefix string
+	GroupsClaim    string
+	GroupsPrefix   string
+	RequiredClaims map[string]string
+}
+
+// args returns the API server flags for o, if enabled
+func (o *OIDC) args() []ExtraArg {
+	if o.IssuerURL == "" {
+		return nil
+	}
+	args := []ExtraArg{
+		{"oidc-issuer-url", o.IssuerURL},
+		{"oidc-client-id", o.ClientID},
+	}
+	optional := []ExtraArg{
+		{"oidc-ca-file", o.CAFile},
+		{"oidc-username-claim", o.UsernameClaim},
+		{"oidc-username-prefix", o.UsernamePrefix},
+		{"oidc-groups-claim", o.GroupsClaim},
+		{"oidc-groups-prefix", o.GroupsPrefix},
+	}
+	for _, arg := range optional {
+		if arg.Value != "" {
+			args = append(args, arg)
+		}
+	}
+	if len(o.RequiredClaims) > 0 {
+		claims := make([]string, 0, len(o.RequiredClaims))
+		for k, v := range o.RequiredClaims {
+			claims = append(claims, k+"="+v)
+		}
+		sort.Strings(claims)
+		args = append(args, ExtraArg{"oidc-required-claim", strings.Join(claims, ",")})
+	}
+	return args
+}
+
+// ComponentFeatureGates holds feature gates for individual components
+type ComponentFeatureGates struct {
+	APIServer         map[string]bool
+	ControllerManager map[string]bool
+	Scheduler         map[string]bool
+	Kubelet           map[string]bool
+	KubeProxy         map[string]bool
 }
 
 // Derive automatically derives DockerStableTag if not specified
@@ -92,6 +233,155 @@ func (c *ConfigData) Derive() {
 		featureGates = append(featureGates, fmt.Sprintf("%s=%t", k, v))
 	}
 	c.FeatureGatesString = strings.Join(featureGates, ",")
+
+	// merge in the per component feature gates
+	c.APIServerFeatureGatesString = joinFeatureGates(mergeFeatureGates(c.FeatureGates, c.ComponentFeatureGates.APIServer))
+	c.ControllerManagerFeatureGatesString = joinFeatureGates(mergeFeatureGates(c.FeatureGates, c.ComponentFeatureGates.ControllerManager))
+	c.SchedulerFeatureGatesString = joinFeatureGates(mergeFeatureGates(c.FeatureGates, c.ComponentFeatureGates.Scheduler))
+	c.KubeletFeatureGates = mergeFeatureGates(c.FeatureGates, c.ComponentFeatureGates.Kubelet)
+	c.KubeProxyFeatureGates = mergeFeatureGates(c.FeatureGates, c.ComponentFeatureGates.KubeProxy)
+
+	// create a sorted key=value,... string of RuntimeConfig
+	runtimeConfigKeys := make([]string, 0, len(c.RuntimeConfig))
+	for k := range c.RuntimeConfig {
+		runtimeConfigKeys = append(runtimeConfigKeys, k)
+	}
+	sort.Strings(runtimeConfigKeys)
+	var runtimeConfig []string
+	for _, k := range runtimeConfigKeys {
+		runtimeConfig = append(runtimeConfig, fmt.Sprintf("%s=%s", k, c.RuntimeConfig[k]))
+	}
+	c.RuntimeConfigString = strings.Join(runtimeConfig, ",")
+
+	// kubeadm takes the repository and tag separately and adds the name
+	c.CoreDNSImageRepository, c.CoreDNSImageTag = splitImage(c.CoreDNSImage)
+	c.EtcdImageRepository, c.EtcdImageTag = splitImage(c.EtcdImage)
+
+	if c.ControlPlaneTimeout == "" {
+		c.ControlPlaneTimeout = controlPlaneTimeout
+	}
+	c.deriveExtraArgs()
+}
+
+// deriveExtraArgs populates the component ExtraArgs from the other fields
+func (c *ConfigData) deriveExtraArgs() {
+	c.APIServerExtraArgs = nil
+	if c.APIServerFeatureGatesString != "" {
+		c.APIServerExtraArgs = append(c.APIServerExtraArgs, ExtraArg{"feature-gates", c.APIServerFeatureGatesString})
+	}
+	if c.RuntimeConfigString != "" {
+		c.APIServerExtraArgs = append(c.APIServerExtraArgs, ExtraArg{"runtime-config", c.RuntimeConfigString})
+	}
+	c.APIServerExtraArgs = append(c.APIServerExtraArgs, c.OIDC.args()...)
+	c.APIServerExtraVolumes = nil
+	if c.AdmissionConfigFile != "" {
+		c.APIServerExtraArgs = append(c.APIServerExtraArgs, ExtraArg{"admission-control-config-file", c.AdmissionConfigFile})
+		c.APIServerExtraVolumes = append(c.APIServerExtraVolumes, ExtraVolume{"kind-admission-config", path.Dir(c.AdmissionConfigFile)})
+	}
+	if c.AuthorizationConfigFile != "" {
+		c.APIServerExtraArgs = append(c.APIServerExtraArgs, ExtraArg{"authorization-config", c.AuthorizationConfigFile})
+		c.APIServerExtraVolumes = append(c.APIServerExtraVolumes, ExtraVolume{"kind-authorization-config", path.Dir(c.AuthorizationConfigFile)})
+	}
+
+	c.ControllerManagerExtraArgs = nil
+	if c.ControllerManagerFeatureGatesString != "" {
+		c.ControllerManagerExtraArgs = append(c.ControllerManagerExtraArgs, ExtraArg{"feature-gates", c.ControllerManagerFeatureGatesString})
+	}
+	c.ControllerManagerExtraArgs = append(c.ControllerManagerExtraArgs, ExtraArg{"enable-hostpath-provisioner", "true"})
+
+	c.SchedulerExtraArgs = nil
+	if c.SchedulerFeatureGatesString != "" {
+		c.SchedulerExtraArgs = append(c.SchedulerExtraArgs, ExtraArg{"feature-gates", c.SchedulerFeatureGatesString})
+	}
+
+	// configure ipv6 default addresses for IPv6 clusters
+	if c.IPv6 {
+		c.ControllerManagerExtraArgs = append(c.ControllerManagerExtraArgs, ExtraArg{"bind-address", "::"})
+		// kube-scheduler dropped the insecure --address flag in v1.23
+		if ver, err := version.ParseGeneric(c.KubernetesVersion); err == nil && ver.LessThan(version.MustParseSemantic("v1.23.0")) {
+			c.SchedulerExtraArgs = append(c.SchedulerExtraArgs, ExtraArg{"address", "::"})
+		}
+		c.SchedulerExtraArgs = append(c.SchedulerExtraArgs, ExtraArg{"bind-address", "::1"})
+	}
+
+	c.KubeletExtraArgs = []ExtraArg{
+		{"fail-swap-on", "false"},
+		{"node-ip", c.NodeAddress},
+	}
+	if c.ImageCredentialProviderConfig != "" {
+		c.KubeletExtraArgs = append(c.KubeletExtraArgs,
+			ExtraArg{"image-credential-provider-config", c.ImageCredentialProviderConfig},
+			ExtraArg{"image-credential-provider-bin-dir", c.ImageCredentialProviderBinDir},
+		)
+	}
+	if len(c.NodeLabels) > 0 {
+		labels := make([]string, 0, len(c.NodeLabels))
+		for k, v := range c.NodeLabels {
+			labels = append(labels, k+"="+v)
+		}
+		sort.Strings(labels)
+		c.KubeletExtraArgs = append(c.KubeletExtraArgs, ExtraArg{"node-labels", strings.Join(labels, ",")})
+	}
+
+	c.EtcdExtraArgs = nil
+	if c.EtcdQuotaBackendBytes > 0 {
+		c.EtcdExtraArgs = append(c.EtcdExtraArgs, ExtraArg{"quota-backend-bytes", strconv.FormatInt(c.EtcdQuotaBackendBytes, 10)})
+	}
+	if c.EtcdHeartbeatInterval > 0 {
+		c.EtcdExtraArgs = append(c.EtcdExtraArgs, ExtraArg{"heartbeat-interval", strconv.FormatInt(c.EtcdHeartbeatInterval, 10)})
+	}
+	if c.EtcdElectionTimeout > 0 {
+		c.EtcdExtraArgs = append(c.EtcdExtraArgs, ExtraArg{"election-timeout", strconv.FormatInt(c.EtcdElectionTimeout, 10)})
+	}
+}
+
+// splitImage splits image of the form <repository>/<name>:<tag> into the
+// repository and tag, or returns empty strings if image is not of this form
+func splitImage(image string) (repository, tag string) {
+	slash := strings.LastIndex(image, "/")
+	colon := strings.LastIndex(image, ":")
+	if slash < 0 || colon < slash {
+		return "", ""
+	}
+	return image[:slash], image[colon+1:]
+}
+
+// mergeFeatureGates returns a new map of gates with overrides applied
+func mergeFeatureGates(gates, overrides map[string]bool) map[string]bool {
+	merged := make(map[string]bool, len(gates)+len(overrides))
+	for k, v := range gates {
+		merged[k] = v
+	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+	return merged
+}
+
+// joinFeatureGates returns gates in the sorted form `Foo=true,Baz=false`
+func joinFeatureGates(gates map[string]bool) string {
+	keys := sortedKeys(gates)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%t", k, gates[k]))
+	}
+	return strings.Join(parts, ",")
+}
+
+// APIVersion describes a kubeadm config API version kind generates
+type APIVersion struct {
+	// Name is the kubeadm API group version, e.g. "kubeadm.k8s.io/v1beta2"
+	Name string
+	// MinKubernetesVersion is the oldest Kubernetes version kind uses Name for
+	MinKubernetesVersion *version.Version
+	// CRISocket is the containerd socket, older versions take a bare path
+	CRISocket string
+	// ExtraArgsList is true if extraArgs are a list of name / value pairs
+	// rather than a map of names to values
+	ExtraArgsList bool
+	// Timeouts is true if timeouts are set in the Init and JoinConfiguration
+	// rather than with apiServer.timeoutForControlPlane
+	Timeouts bool
 }
 
 // See docs for these APIs at:
@@ -99,9 +389,61 @@ func (c *ConfigData) Derive() {
 // EG:
 // https://godoc.org/k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm/v1beta1
 
-// ConfigTemplateBetaV1 is the kubadm config template for API version v1beta1
-const ConfigTemplateBetaV1 = `# config generated by kind
-apiVersion: kubeadm.k8s.io/v1beta1
+// APIVersions are the kubeadm config API versions, newest first
+var APIVersions = []APIVersion{
+	{
+		Name:                 "kubeadm.k8s.io/v1beta4",
+		MinKubernetesVersion: version.MustParseSemantic("v1.31.0"),
+		CRISocket:            "unix:///run/containerd/containerd.sock",
+		ExtraArgsList:        true,
+		Timeouts:             true,
+	},
+	{
+		Name:                 "kubeadm.k8s.io/v1beta3",
+		MinKubernetesVersion: version.MustParseSemantic("v1.23.0"),
+		CRISocket:            "unix:///run/containerd/containerd.sock",
+	},
+	{
+		Name:                 "kubeadm.k8s.io/v1beta2",
+		MinKubernetesVersion: version.MustParseSemantic("v1.15.0"),
+		CRISocket:            "unix:///run/containerd/containerd.sock",
+	},
+	{
+		Name:                 "kubeadm.k8s.io/v1beta1",
+		MinKubernetesVersion: version.MustParseSemantic("v1.0.0"),
+		CRISocket:            "/run/containerd/containerd.sock",
+	},
+}
+
+// APIVersionFor returns the kubeadm config API version kind generates
+// for kubernetesVersion
+func APIVersionFor(kubernetesVersion string) (APIVersion, error) {
+	ver, err := version.ParseGeneric(kubernetesVersion)
+	if err != nil {
+		return APIVersion{}, err
+	}
+	for _, api := range APIVersions {
+		if ver.AtLeast(api.MinKubernetesVersion) {
+			return api, nil
+		}
+	}
+	return APIVersions[len(APIVersions)-1], nil
+}
+
+// ExtraArg is a component command line flag, without the leading dashes
+type ExtraArg struct {
+	Name  string
+	Value string
+}
+
+// controlPlaneTimeout is how long kubeadm init waits for the control plane,
+// it is set explicitly so that JSON 6902 patches can replace it in any version
+const controlPlaneTimeout = "4m0s"
+
+// ConfigTemplate is the kubeadm config template for every API version,
+// the version specific fields are selected by the APIVersion in the data
+const ConfigTemplate = `# config generated by kind
+apiVersion: {{ .APIVersion.Name }}
 kind: ClusterConfiguration
 metadata:
   name: config
@@ -112,153 +454,47 @@ controlPlaneEndpoint: "{{ .ControlPlaneEndpoint }}"
 # so we need to ensure the cert is valid for localhost so we can talk
 # to the cluster after rewriting the kubeconfig to point to localhost
 apiServer:
-  certSANs: [localhost, "{{.APIServerAddress}}"]
-{{ if .FeatureGates }}
-  extraArgs:
-    "feature-gates": "{{ .FeatureGatesString }}"
-{{ end}}
-controllerManager:
-{{ if .FeatureGates }}
-  extraArgs:
-    "feature-gates": "{{ .FeatureGatesString }}"
-{{ end}}
-    enable-hostpath-provisioner: "true"
-    # configure ipv6 default addresses for IPv6 clusters
-    {{ if .IPv6 -}}
-    bind-address: "::"
-    {{- end }}
-scheduler:
-  extraArgs:
-{{ if .FeatureGates }}
-    "feature-gates": "{{ .FeatureGatesString }}"
-{{ end }}
-    # configure ipv6 default addresses for IPv6 clusters
-    {{ if .IPv6 -}}
-    address: "::"
-    bind-address: "::1"
-    {{- end }}
-networking:
-  podSubnet: "{{ .PodSubnet }}"
-  serviceSubnet: "{{ .ServiceSubnet }}"
----
-apiVersion: kubeadm.k8s.io/v1beta1
-kind: InitConfiguration
-metadata:
-  name: config
-# we use a well know token for TLS bootstrap
-bootstrapTokens:
-- token: "{{ .Token }}"
-# we use a well know port for making the API server discoverable inside docker network. 
-# from the host machine such port will be accessible via a random local port instead.
-localAPIEndpoint:
-  advertiseAddress: "{{ .NodeAddress }}"
-  bindPort: {{.APIBindPort}}
-nodeRegistration:
-  criSocket: "/run/containerd/containerd.sock"
-  kubeletExtraArgs:
-    fail-swap-on: "false"
-    node-ip: "{{ .NodeAddress }}"
----
-# no-op entry that exists solely so it can be patched
-apiVersion: kubeadm.k8s.io/v1beta1
-kind: JoinConfiguration
-metadata:
-  name: config
-{{ if .ControlPlane -}}
-controlPlane:
-  localAPIEndpoint:
-    advertiseAddress: "{{ .NodeAddress }}"
-    bindPort: {{.APIBindPort}}
+  certSANs: [localhost, "{{.APIServerAddress}}"{{ range .AdditionalAPIServerAddresses }}, "{{ . }}"{{ end }}]
+{{- if not .APIVersion.Timeouts }}
+  timeoutForControlPlane: {{ .ControlPlaneTimeout }}
+{{- end }}
+{{ extraArgs "extraArgs" 2 .APIServerExtraArgs }}
+{{- if .APIServerExtraVolumes }}
+  extraVolumes:
+{{- range .APIServerExtraVolumes }}
+  - name: {{ .Name }}
+    hostPath: "{{ .Path }}"
+    mountPath: "{{ .Path }}"
+    readOnly: true
+    pathType: Directory
 {{- end }}
-nodeRegistration:
-  criSocket: "/run/containerd/containerd.sock"
-  kubeletExtraArgs:
-    fail-swap-on: "false"
-    node-ip: "{{ .NodeAddress }}"
-discovery:
-  bootstrapToken:
-    apiServerEndpoint: "{{ .ControlPlaneEndpoint }}"
-    token: "{{ .Token }}"
-    unsafeSkipCAVerification: true
----
-apiVersion: kubelet.config.k8s.io/v1beta1
-kind: KubeletConfiguration
-metadata:
-  name: config
-# configure ipv6 addresses in IPv6 mode
-{{ if .IPv6 -}}
-address: "::"
-healthzBindAddress: "::"
 {{- end }}
-# disable disk resource management by default
-# kubelet will see the host disk that the inner container runtime
-# is ultimately backed by and attempt to recover disk space. we don't want that.
-imageGCHighThresholdPercent: 100
-evictionHard:
-  nodefs.available: "0%"
-  nodefs.inodesFree: "0%"
-  imagefs.available: "0%"
-{{if .FeatureGates}}featureGates:
-{{ range $key := .SortedFeatureGateKeys }}
-  "{{ $key }}": {{$.FeatureGates $key }}
-{{end}}{{end}}
----
-apiVersion: kubeproxy.config.k8s.io/v1alpha1
-kind: KubeProxyConfiguration
-metadata:
-  name: config
-mode: "{{ .KubeProxyMode }}"
-{{if .FeatureGates}}featureGates:
-{{ range $key := .SortedFeatureGateKeys }}
-  "{{ $key }}": {{ index $.FeatureGates $key }}
-{{end}}{{end}}
-iptables:
-  minSyncPeriod: 1s
-`
-
-// ConfigTemplateBetaV2 is the kubadm config template for API version v1beta2
-const ConfigTemplateBetaV2 = `# config generated by kind
-apiVersion: kubeadm.k8s.io/v1beta2
-kind: ClusterConfiguration
-metadata:
-  name: config
-kubernetesVersion: {{.KubernetesVersion}}
-clusterName: "{{.ClusterName}}"
-controlPlaneEndpoint: "{{ .ControlPlaneEndpoint }}"
-# on docker for mac we have to expose the api server via port forward,
-# so we need to ensure the cert is valid for localhost so we can talk
-# to the cluster after rewriting the kubeconfig to point to localhost
-apiServer:
-  certSANs: [localhost, "{{.APIServerAddress}}"]
-{{ if .FeatureGates }}
-  extraArgs:
-    "feature-gates": "{{ .FeatureGatesString }}"
-{{ end }}
 controllerManager:
-  extraArgs:
-{{ if .FeatureGates }}
-    "feature-gates": "{{ .FeatureGatesString }}"
-{{ end }}
-    enable-hostpath-provisioner: "true"
-    # configure ipv6 default addresses for IPv6 clusters
-    {{ if .IPv6 -}}
-    bind-address: "::"
-    {{- end }}
+{{ extraArgs "extraArgs" 2 .ControllerManagerExtraArgs }}
 scheduler:
-  extraArgs:
-{{ if .FeatureGates }}
-    "feature-gates": "{{ .FeatureGatesString }}"
-{{ end }}
-    # configure ipv6 default addresses for IPv6 clusters
-    {{ if .IPv6 -}}
-    address: "::"
-    bind-address: "::1"
-    {{- end }}
+{{ extraArgs "extraArgs" 2 .SchedulerExtraArgs }}
 networking:
   podSubnet: "{{ .PodSubnet }}"
   serviceSubnet: "{{ .ServiceSubnet }}"
+{{ if .ImageRepository -}}
+imageRepository: "{{ .ImageRepository }}"
+{{ end -}}
+{{ if .CoreDNSImageRepository -}}
+dns:
+  imageRepository: "{{ .CoreDNSImageRepository }}"
+  imageTag: "{{ .CoreDNSImageTag }}"
+{{ end -}}
+{{ if or .EtcdImageRepository .EtcdExtraArgs -}}
+etcd:
+  local:
+{{- if .EtcdImageRepository }}
+    imageRepository: "{{ .EtcdImageRepository }}"
+    imageTag: "{{ .EtcdImageTag }}"
+{{- end }}
+{{ extraArgs "extraArgs" 4 .EtcdExtraArgs }}
+{{ end -}}
 ---
-apiVersion: kubeadm.k8s.io/v1beta2
+apiVersion: {{ .APIVersion.Name }}
 kind: InitConfiguration
 metadata:
   name: config
@@ -271,13 +507,15 @@ localAPIEndpoint:
   advertiseAddress: "{{ .NodeAddress }}"
   bindPort: {{.APIBindPort}}
 nodeRegistration:
-  criSocket: "unix:///run/containerd/containerd.sock"
-  kubeletExtraArgs:
-    fail-swap-on: "false"
-    node-ip: "{{ .NodeAddress }}"
+  criSocket: "{{ .APIVersion.CRISocket }}"
+{{ extraArgs "kubeletExtraArgs" 2 .KubeletExtraArgs }}
+{{ if .APIVersion.Timeouts -}}
+timeouts:
+  controlPlaneComponentHealthCheck: {{ .ControlPlaneTimeout }}
+{{ end -}}
 ---
 # no-op entry that exists solely so it can be patched
-apiVersion: kubeadm.k8s.io/v1beta2
+apiVersion: {{ .APIVersion.Name }}
 kind: JoinConfiguration
 metadata:
   name: config
@@ -288,15 +526,24 @@ controlPlane:
     bindPort: {{.APIBindPort}}
 {{- end }}
 nodeRegistration:
-  criSocket: "unix:///run/containerd/containerd.sock"
-  kubeletExtraArgs:
-    fail-swap-on: "false"
-    node-ip: "{{ .NodeAddress }}"
+  criSocket: "{{ .APIVersion.CRISocket }}"
+{{ extraArgs "kubeletExtraArgs" 2 .KubeletExtraArgs }}
 discovery:
   bootstrapToken:
     apiServerEndpoint: "{{ .ControlPlaneEndpoint }}"
     token: "{{ .Token }}"
+{{- if .CACertHashes }}
+    caCertHashes:
+{{- range .CACertHashes }}
+    - "{{ . }}"
+{{- end }}
+{{- else }}
     unsafeSkipCAVerification: true
+{{- end }}
+{{- if .APIVersion.Timeouts }}
+timeouts:
+  controlPlaneComponentHealthCheck: {{ .ControlPlaneTimeout }}
+{{- end }}
 ---
 apiVersion: kubelet.config.k8s.io/v1beta1
 kind: KubeletConfiguration
@@ -309,50 +556,94 @@ healthzBindAddress: "::"
 {{- end }}
 # disable disk resource management by default
 # kubelet will see the host disk that the inner container runtime
-# is ultimately backed by and attempt to recover disk space. we don't want that.
-imageGCHighThresholdPercent: 100
+# is ultimately backed by and attempt to recover disk space. we don't want that
+# unless image garbage collection is explicitly configured.
+imageGCHighThresholdPercent: {{ if .ImageGCHighThresholdPercent }}{{ .ImageGCHighThresholdPercent }}{{ else }}100{{ end }}
+{{ if .ImageGCLowThresholdPercent -}}
+imageGCLowThresholdPercent: {{ .ImageGCLowThresholdPercent }}
+{{ end -}}
+{{ if .ImageMinimumGCAge -}}
+imageMinimumGCAge: "{{ .ImageMinimumGCAge }}"
+{{ end -}}
 evictionHard:
   nodefs.available: "0%"
   nodefs.inodesFree: "0%"
   imagefs.available: "0%"
-{{if .FeatureGates}}featureGates:
-{{ range $key := .SortedFeatureGateKeys }}
-  "{{ $key }}": {{ index $.FeatureGates $key }}
+{{ if .ContainerLogMaxSize -}}
+containerLogMaxSize: "{{ .ContainerLogMaxSize }}"
+{{ end -}}
+{{ if .ContainerLogMaxFiles -}}
+containerLogMaxFiles: {{ .ContainerLogMaxFiles }}
+{{ end -}}
+{{ if .KubeletServerTLSBootstrap -}}
+serverTLSBootstrap: true
+{{ end -}}
+{{if .KubeletFeatureGates}}featureGates:
+{{ range $key, $value := .KubeletFeatureGates }}
+  "{{ $key }}": {{ $value }}
 {{end}}{{end}}
+{{- if ne .KubeProxyMode "none" }}
 ---
 apiVersion: kubeproxy.config.k8s.io/v1alpha1
 kind: KubeProxyConfiguration
 metadata:
   name: config
 mode: "{{ .KubeProxyMode }}"
-{{if .FeatureGates}}featureGates:
-{{ range $key := .SortedFeatureGateKeys }}
-  "{{ $key }}": {{ index $.FeatureGates $key }}
+{{if .KubeProxyFeatureGates}}featureGates:
+{{ range $key, $value := .KubeProxyFeatureGates }}
+  "{{ $key }}": {{ $value }}
 {{end}}{{end}}
 iptables:
   minSyncPeriod: 1s
-`
+{{ end }}`
+
+// extraArgsFunc returns the extraArgs template function for api, it renders
+// args under key at indent in the format api takes, or nothing if args is empty
+func extraArgsFunc(api APIVersion) func(key string, indent int, args []ExtraArg) string {
+	return func(key string, indent int, args []ExtraArg) string {
+		if len(args) == 0 {
+			return ""
+		}
+		prefix := strings.Repeat(" ", indent)
+		lines := []string{prefix + key + ":"}
+		for _, arg := range args {
+			if api.ExtraArgsList {
+				lines = append(lines,
+					fmt.Sprintf("%s  - name: %q", prefix, arg.Name),
+					fmt.Sprintf("%s    value: %q", prefix, arg.Value),
+				)
+			} else {
+				lines = append(lines, fmt.Sprintf("%s  %q: %q", prefix, arg.Name, arg.Value))
+			}
+		}
+		return strings.Join(lines, "\n")
+	}
+}
 
 // Config returns a kubeadm config generated from config data, in particular
 // the kubernetes version
 func Config(data ConfigData) (config string, err error) {
-	ver, err := version.ParseGeneric(data.KubernetesVersion)
+	api, err := APIVersionFor(data.KubernetesVersion)
 	if err != nil {
 		return "", err
 	}
+	data.APIVersion = api
+
+	// kubeadm only omits --authorization-mode for --authorization-config from v1.30
+	if data.AuthorizationConfigFile != "" {
+		if ver, err := version.ParseGeneric(data.KubernetesVersion); err == nil && ver.LessThan(version.MustParseSemantic("v1.30.0")) {
+			return "", errors.Errorf("authorizationConfiguration requires Kubernetes v1.30 or later, got %s", data.KubernetesVersion)
+		}
+	}
 
 	// ensure featureGates is non-nil, as we may add entries
 	if data.FeatureGates == nil {
 		data.FeatureGates = make(map[string]bool)
 	}
 
-	// assume the latest API version, then fallback if the k8s version is too low
-	templateSource := ConfigTemplateBetaV2
-	if ver.LessThan(version.MustParseSemantic("v1.15.0")) {
-		templateSource = ConfigTemplateBetaV1
-	}
-
-	t, err := template.New("kubeadm-config").Parse(templateSource)
+	t, err := template.New("kubeadm-config").
+		Funcs(template.FuncMap{"extraArgs": extraArgsFunc(api)}).
+		Parse(ConfigTemplate)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to parse config template")
 	}
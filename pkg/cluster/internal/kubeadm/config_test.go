@@ -0,0 +1,310 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeadm
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	yaml "gopkg.in/yaml.v3"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestSplitImage(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Image      string
+		Repository string
+		Tag        string
+	}{
+		{Image: "registry.example/k8s/coredns:1.6.7", Repository: "registry.example/k8s", Tag: "1.6.7"},
+		{Image: "registry.example:5000/etcd:3.4.3-0", Repository: "registry.example:5000", Tag: "3.4.3-0"},
+		{Image: "registry.example:5000/etcd"},
+		{Image: ""},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Image, func(t *testing.T) {
+			t.Parallel()
+			repository, tag := splitImage(tc.Image)
+			assert.StringEqual(t, tc.Repository, repository)
+			assert.StringEqual(t, tc.Tag, tag)
+		})
+	}
+}
+
+func TestConfigAPIVersions(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		KubernetesVersion string
+		APIVersion        string
+		ExtraArgsList     bool
+	}{
+		{KubernetesVersion: "v1.14.10", APIVersion: "kubeadm.k8s.io/v1beta1"},
+		{KubernetesVersion: "v1.18.2", APIVersion: "kubeadm.k8s.io/v1beta2"},
+		// pre-releases already use the API of the release
+		{KubernetesVersion: "v1.23.0-alpha.1", APIVersion: "kubeadm.k8s.io/v1beta3"},
+		{KubernetesVersion: "v1.23.0", APIVersion: "kubeadm.k8s.io/v1beta3"},
+		{KubernetesVersion: "v1.31.1", APIVersion: "kubeadm.k8s.io/v1beta4", ExtraArgsList: true},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.KubernetesVersion, func(t *testing.T) {
+			t.Parallel()
+			out, err := Config(ConfigData{
+				KubernetesVersion: tc.KubernetesVersion,
+				ClusterName:       "kind",
+				NodeAddress:       "fc00:f853:ccd:e793::2",
+				KubeProxyMode:     "iptables",
+				IPv6:              true,
+				FeatureGates:      map[string]bool{"Foo": true},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			decoder := yaml.NewDecoder(bytes.NewBufferString(out))
+			kinds := map[string]map[string]interface{}{}
+			for {
+				doc := map[string]interface{}{}
+				if err := decoder.Decode(&doc); err == io.EOF {
+					break
+				} else if err != nil {
+					t.Fatalf("failed to parse generated config: %v\n%s", err, out)
+				}
+				kinds[doc["kind"].(string)] = doc
+			}
+			for _, kind := range []string{"ClusterConfiguration", "InitConfiguration", "JoinConfiguration"} {
+				assert.StringEqual(t, tc.APIVersion, kinds[kind]["apiVersion"].(string))
+			}
+			// extraArgs are a list from v1beta4, and a map before that
+			args := kinds["ClusterConfiguration"]["controllerManager"].(map[string]interface{})["extraArgs"]
+			if _, isList := args.([]interface{}); isList != tc.ExtraArgsList {
+				t.Errorf("expected extraArgs as a list to be %v, got %#v", tc.ExtraArgsList, args)
+			}
+			kubeletArgs := kinds["InitConfiguration"]["nodeRegistration"].(map[string]interface{})["kubeletExtraArgs"]
+			if _, isList := kubeletArgs.([]interface{}); isList != tc.ExtraArgsList {
+				t.Errorf("expected kubeletExtraArgs as a list to be %v, got %#v", tc.ExtraArgsList, kubeletArgs)
+			}
+			// the control plane timeout moved out of the apiServer in v1beta4
+			_, hasTimeouts := kinds["InitConfiguration"]["timeouts"]
+			_, hasAPIServerTimeout := kinds["ClusterConfiguration"]["apiServer"].(map[string]interface{})["timeoutForControlPlane"]
+			if hasTimeouts != tc.ExtraArgsList || hasAPIServerTimeout == tc.ExtraArgsList {
+				t.Errorf("unexpected control plane timeout placement for %s:\n%s", tc.APIVersion, out)
+			}
+		})
+	}
+}
+
+func TestConfigNodeLabels(t *testing.T) {
+	t.Parallel()
+	for _, kubeVersion := range []string{"v1.18.2", "v1.31.1"} {
+		kubeVersion := kubeVersion // capture range variable
+		t.Run(kubeVersion, func(t *testing.T) {
+			t.Parallel()
+			out, err := Config(ConfigData{
+				KubernetesVersion: kubeVersion,
+				ClusterName:       "kind",
+				NodeAddress:       "172.18.0.2",
+				KubeProxyMode:     "iptables",
+				NodeLabels:        map[string]string{"tier": "edge", "ingress-ready": "true"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.Contains(out, "node-labels") || !strings.Contains(out, `"ingress-ready=true,tier=edge"`) {
+				t.Errorf("expected sorted node-labels in config:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestConfigEtcdExtraArgs(t *testing.T) {
+	t.Parallel()
+	for _, kubeVersion := range []string{"v1.23.0", "v1.31.1"} {
+		kubeVersion := kubeVersion // capture range variable
+		t.Run(kubeVersion, func(t *testing.T) {
+			t.Parallel()
+			out, err := Config(ConfigData{
+				KubernetesVersion:     kubeVersion,
+				ClusterName:           "kind",
+				NodeAddress:           "172.18.0.2",
+				KubeProxyMode:         "iptables",
+				EtcdImage:             "registry.example/etcd:3.5.15-0",
+				EtcdQuotaBackendBytes: 8 << 30,
+				EtcdHeartbeatInterval: 250,
+				EtcdElectionTimeout:   2500,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			decoder := yaml.NewDecoder(bytes.NewBufferString(out))
+			var local map[string]interface{}
+			for {
+				doc := map[string]interface{}{}
+				if err := decoder.Decode(&doc); err == io.EOF {
+					break
+				} else if err != nil {
+					t.Fatalf("failed to parse generated config: %v\n%s", err, out)
+				}
+				if doc["kind"] == "ClusterConfiguration" {
+					local = doc["etcd"].(map[string]interface{})["local"].(map[string]interface{})
+				}
+			}
+			assert.StringEqual(t, "registry.example", local["imageRepository"].(string))
+			if local["extraArgs"] == nil {
+				t.Fatalf("expected etcd extraArgs in config:\n%s", out)
+			}
+			for _, arg := range []string{`"quota-backend-bytes"`, `"8589934592"`, `"heartbeat-interval"`, `"250"`, `"election-timeout"`, `"2500"`} {
+				if !strings.Contains(out, arg) {
+					t.Errorf("expected %s in config:\n%s", arg, out)
+				}
+			}
+		})
+	}
+}
+
+func TestOIDCArgs(t *testing.T) {
+	t.Parallel()
+	o := OIDC{
+		IssuerURL:      "https://kind-oidc-issuer:5556/dex",
+		ClientID:       "kind",
+		CAFile:         "/etc/kubernetes/pki/oidc-ca.crt",
+		UsernameClaim:  "email",
+		RequiredClaims: map[string]string{"hd": "example.com", "aud": "kind"},
+	}
+	assert.DeepEqual(t, []ExtraArg{
+		{"oidc-issuer-url", "https://kind-oidc-issuer:5556/dex"},
+		{"oidc-client-id", "kind"},
+		{"oidc-ca-file", "/etc/kubernetes/pki/oidc-ca.crt"},
+		{"oidc-username-claim", "email"},
+		{"oidc-required-claim", "aud=kind,hd=example.com"},
+	}, o.args())
+	if args := (&OIDC{ClientID: "kind"}).args(); args != nil {
+		t.Errorf("expected no args without an issuer, got %v", args)
+	}
+}
+
+func TestConfigAPIServerConfigFiles(t *testing.T) {
+	t.Parallel()
+	data := ConfigData{
+		KubernetesVersion:       "v1.30.0",
+		ClusterName:             "kind",
+		NodeAddress:             "172.18.0.2",
+		KubeProxyMode:           "iptables",
+		AdmissionConfigFile:     "/etc/kubernetes/kind/admission/admission.yaml",
+		AuthorizationConfigFile: "/etc/kubernetes/kind/authorization/authz.yaml",
+	}
+	out, err := Config(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewBufferString(out))
+	var apiServer map[string]interface{}
+	for {
+		doc := map[string]interface{}{}
+		if err := decoder.Decode(&doc); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("failed to parse generated config: %v\n%s", err, out)
+		}
+		if doc["kind"] == "ClusterConfiguration" {
+			apiServer = doc["apiServer"].(map[string]interface{})
+		}
+	}
+	volumes, _ := apiServer["extraVolumes"].([]interface{})
+	if len(volumes) != 2 {
+		t.Fatalf("expected 2 API server extraVolumes, got %#v", apiServer["extraVolumes"])
+	}
+	admission := volumes[0].(map[string]interface{})
+	assert.StringEqual(t, "/etc/kubernetes/kind/admission", admission["hostPath"].(string))
+	assert.StringEqual(t, "/etc/kubernetes/kind/admission", admission["mountPath"].(string))
+	for _, flag := range []string{"admission-control-config-file", "authorization-config"} {
+		if !strings.Contains(out, flag) {
+			t.Errorf("expected %s in config:\n%s", flag, out)
+		}
+	}
+
+	// structured authorization needs v1.30
+	data.KubernetesVersion = "v1.29.2"
+	if _, err := Config(data); err == nil {
+		t.Errorf("expected an error for authorizationConfiguration with %s", data.KubernetesVersion)
+	}
+}
+
+func TestConfigImageGC(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name       string
+		Data       ConfigData
+		ExpectHigh int
+		ExpectLow  interface{}
+		ExpectAge  interface{}
+	}{
+		{
+			Name:       "disabled by default",
+			ExpectHigh: 100,
+		},
+		{
+			Name: "configured",
+			Data: ConfigData{
+				ImageGCHighThresholdPercent: 85,
+				ImageGCLowThresholdPercent:  70,
+				ImageMinimumGCAge:           "10m",
+			},
+			ExpectHigh: 85,
+			ExpectLow:  70,
+			ExpectAge:  "10m",
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			data := tc.Data
+			data.KubernetesVersion = "v1.31.1"
+			data.ClusterName = "kind"
+			data.NodeAddress = "172.18.0.2"
+			data.KubeProxyMode = "iptables"
+			out, err := Config(data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			decoder := yaml.NewDecoder(bytes.NewBufferString(out))
+			var kubelet map[string]interface{}
+			for {
+				doc := map[string]interface{}{}
+				if err := decoder.Decode(&doc); err == io.EOF {
+					break
+				} else if err != nil {
+					t.Fatalf("failed to parse generated config: %v\n%s", err, out)
+				}
+				if doc["kind"] == "KubeletConfiguration" {
+					kubelet = doc
+				}
+			}
+			if kubelet == nil {
+				t.Fatalf("expected a KubeletConfiguration in config:\n%s", out)
+			}
+			assert.DeepEqual(t, tc.ExpectHigh, kubelet["imageGCHighThresholdPercent"])
+			assert.DeepEqual(t, tc.ExpectLow, kubelet["imageGCLowThresholdPercent"])
+			assert.DeepEqual(t, tc.ExpectAge, kubelet["imageMinimumGCAge"])
+		})
+	}
+}
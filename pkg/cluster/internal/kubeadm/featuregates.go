@@ -0,0 +1,185 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeadm
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// lockedFeatureGate records when a feature gate went GA and was locked to
+// true, and when it was subsequently removed, if it has been
+type lockedFeatureGate struct {
+	GA      string
+	Removed string
+}
+
+// lockedFeatureGates are commonly set feature gates that have graduated,
+// these are not listed in the component --feature-gates help once locked
+var lockedFeatureGates = map[string]lockedFeatureGate{
+	"CSIDriverRegistry":   {GA: "v1.18.0"},
+	"CSIMigration":        {GA: "v1.25.0", Removed: "v1.27.0"},
+	"EndpointSlice":       {GA: "v1.21.0", Removed: "v1.25.0"},
+	"EphemeralContainers": {GA: "v1.25.0", Removed: "v1.27.0"},
+	"IPv6DualStack":       {GA: "v1.23.0", Removed: "v1.25.0"},
+	"NodeLease":           {GA: "v1.17.0"},
+	"PodPriority":         {GA: "v1.14.0", Removed: "v1.18.0"},
+	"PodSecurity":         {GA: "v1.25.0", Removed: "v1.28.0"},
+	"ServerSideApply":     {GA: "v1.22.0"},
+	"StartupProbe":        {GA: "v1.20.0"},
+	"TTLAfterFinished":    {GA: "v1.23.0", Removed: "v1.25.0"},
+}
+
+// removedAPIVersions maps API group versions to the Kubernetes version
+// that stopped serving them, enabling these in runtime-config will fail
+var removedAPIVersions = map[string]string{
+	"admissionregistration.k8s.io/v1beta1": "v1.22.0",
+	"apiextensions.k8s.io/v1beta1":         "v1.22.0",
+	"apps/v1beta1":                         "v1.16.0",
+	"apps/v1beta2":                         "v1.16.0",
+	"autoscaling/v2beta1":                  "v1.25.0",
+	"autoscaling/v2beta2":                  "v1.26.0",
+	"batch/v1beta1":                        "v1.25.0",
+	"discovery.k8s.io/v1beta1":             "v1.25.0",
+	"events.k8s.io/v1beta1":                "v1.25.0",
+	"extensions/v1beta1":                   "v1.22.0",
+	"networking.k8s.io/v1beta1":            "v1.22.0",
+	"policy/v1beta1":                       "v1.25.0",
+	"rbac.authorization.k8s.io/v1beta1":    "v1.22.0",
+}
+
+// knownFeatureGateRE matches an entry in the --feature-gates flag help, e.g.
+// `APIListChunking=true|false (BETA - default=true)`
+var knownFeatureGateRE = regexp.MustCompile(`([A-Za-z0-9]+)=true\|false \(([A-Z]+) - default=(?:true|false)\)`)
+
+// KnownFeatureGates parses the feature gates listed in the --feature-gates
+// help of a Kubernetes component, e.g. `kubelet --help`, into a map of gate
+// name to stage (ALPHA, BETA, DEPRECATED ...)
+// It returns nil if no feature gates are listed
+func KnownFeatureGates(help string) map[string]string {
+	var known map[string]string
+	for _, m := range knownFeatureGateRE.FindAllStringSubmatch(help, -1) {
+		if known == nil {
+			known = make(map[string]string)
+		}
+		known[m[1]] = m[2]
+	}
+	return known
+}
+
+// FeatureGateWarnings returns a warning for each of gates that is unknown,
+// deprecated, locked or removed in kubeVersion
+// known is the result of KnownFeatureGates for kubeVersion, if nil only
+// graduated gates are checked
+func FeatureGateWarnings(kubeVersion string, known map[string]string, gates map[string]bool) []string {
+	ver, err := version.ParseGeneric(kubeVersion)
+	if err != nil {
+		return nil
+	}
+	warnings := []string{}
+	for _, name := range sortedKeys(gates) {
+		if locked, ok := lockedFeatureGates[name]; ok {
+			if locked.Removed != "" && ver.AtLeast(version.MustParseSemantic(locked.Removed)) {
+				warnings = append(warnings, fmt.Sprintf("feature gate %q was removed in Kubernetes %s", name, locked.Removed))
+				continue
+			}
+			if ver.AtLeast(version.MustParseSemantic(locked.GA)) {
+				if !gates[name] {
+					warnings = append(warnings, fmt.Sprintf("feature gate %q is GA and locked to true since Kubernetes %s, it cannot be disabled", name, locked.GA))
+				} else {
+					warnings = append(warnings, fmt.Sprintf("feature gate %q is GA and locked to true since Kubernetes %s, it no longer needs to be set", name, locked.GA))
+				}
+				continue
+			}
+		}
+		if known == nil {
+			continue
+		}
+		stage, ok := known[name]
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("unknown feature gate %q for Kubernetes %s, is it misspelled?", name, kubeVersion))
+		} else if stage == "DEPRECATED" {
+			warnings = append(warnings, fmt.Sprintf("feature gate %q is deprecated in Kubernetes %s", name, kubeVersion))
+		}
+	}
+	return warnings
+}
+
+// RuntimeConfigWarnings returns a warning for each runtimeConfig entry
+// naming an API group version that is no longer served by kubeVersion
+func RuntimeConfigWarnings(kubeVersion string, runtimeConfig map[string]string) []string {
+	ver, err := version.ParseGeneric(kubeVersion)
+	if err != nil {
+		return nil
+	}
+	keys := make([]string, 0, len(runtimeConfig))
+	for k := range runtimeConfig {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	warnings := []string{}
+	for _, key := range keys {
+		groupVersion := key
+		// group/version/resource entries belong to group/version
+		if m := groupVersionRE.FindStringSubmatch(key); m != nil {
+			groupVersion = m[1]
+		}
+		if removed, ok := removedAPIVersions[groupVersion]; ok && ver.AtLeast(version.MustParseSemantic(removed)) {
+			warnings = append(warnings, fmt.Sprintf("runtime config %q: %s is not served since Kubernetes %s", key, groupVersion, removed))
+		}
+	}
+	return warnings
+}
+
+var groupVersionRE = regexp.MustCompile(`^([^/]+/[^/]+)/`)
+
+// kubeadmAPIVersionRE matches the kubeadm apiVersion set by a config patch
+var kubeadmAPIVersionRE = regexp.MustCompile(`(?m)^apiVersion:\s*["']?(kubeadm\.k8s\.io/[^"'\s]+)`)
+
+// PatchAPIVersionWarnings returns a warning for each of the patches in field
+// that sets a kubeadm apiVersion other than the one kind generates for
+// kubeVersion, such patches match nothing and are ignored
+func PatchAPIVersionWarnings(kubeVersion, field string, patches []string) []string {
+	api, err := APIVersionFor(kubeVersion)
+	if err != nil {
+		return nil
+	}
+	warnings := []string{}
+	for i, p := range patches {
+		for _, m := range kubeadmAPIVersionRE.FindAllStringSubmatch(p, -1) {
+			if m[1] != api.Name {
+				warnings = append(warnings, fmt.Sprintf(
+					"%s[%d] sets apiVersion %s but kind generates %s for Kubernetes %s, the patch is ignored",
+					field, i, m[1], api.Name, kubeVersion,
+				))
+			}
+		}
+	}
+	return warnings
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
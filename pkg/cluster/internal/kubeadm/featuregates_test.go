@@ -0,0 +1,155 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeadm
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+const kubeletHelp = `
+      --feature-gates mapStringBool   A set of key=value pairs that describe feature gates for alpha/experimental features. Options are:
+                                      APIListChunking=true|false (BETA - default=true)
+                                      AllAlpha=true|false (ALPHA - default=false)
+                                      DynamicKubeletConfig=true|false (DEPRECATED - default=false)
+                                      IPv6DualStack=true|false (BETA - default=true)
+`
+
+func TestKnownFeatureGates(t *testing.T) {
+	t.Parallel()
+	assert.DeepEqual(t, map[string]string{
+		"APIListChunking":      "BETA",
+		"AllAlpha":             "ALPHA",
+		"DynamicKubeletConfig": "DEPRECATED",
+		"IPv6DualStack":        "BETA",
+	}, KnownFeatureGates(kubeletHelp))
+	assert.DeepEqual(t, map[string]string(nil), KnownFeatureGates("Usage:\n  kubelet [flags]"))
+}
+
+func TestFeatureGateWarnings(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name        string
+		KubeVersion string
+		Known       map[string]string
+		Gates       map[string]bool
+		Expected    []string
+	}{
+		{
+			Name:        "known gates",
+			KubeVersion: "v1.21.1",
+			Known:       KnownFeatureGates(kubeletHelp),
+			Gates:       map[string]bool{"AllAlpha": true, "IPv6DualStack": true},
+			Expected:    []string{},
+		},
+		{
+			Name:        "typo and deprecated",
+			KubeVersion: "v1.21.1",
+			Known:       KnownFeatureGates(kubeletHelp),
+			Gates:       map[string]bool{"IPv6DualStak": true, "DynamicKubeletConfig": true},
+			Expected:    []string{"deprecated", "misspelled"},
+		},
+		{
+			Name:        "locked and removed without known gates",
+			KubeVersion: "v1.23.0",
+			Gates:       map[string]bool{"IPv6DualStack": false, "PodPriority": true, "Bogus": true},
+			Expected:    []string{"cannot be disabled", "was removed"},
+		},
+		{
+			Name:        "removed gate",
+			KubeVersion: "v1.25.3",
+			Known:       KnownFeatureGates(kubeletHelp),
+			Gates:       map[string]bool{"IPv6DualStack": true},
+			Expected:    []string{"was removed in Kubernetes v1.25.0"},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			warnings := FeatureGateWarnings(tc.KubeVersion, tc.Known, tc.Gates)
+			if len(warnings) != len(tc.Expected) {
+				t.Fatalf("expected %d warnings but got: %v", len(tc.Expected), warnings)
+			}
+			for i, expected := range tc.Expected {
+				if !strings.Contains(warnings[i], expected) {
+					t.Errorf("expected warning %q to contain %q", warnings[i], expected)
+				}
+			}
+		})
+	}
+}
+
+func TestRuntimeConfigWarnings(t *testing.T) {
+	t.Parallel()
+	runtimeConfig := map[string]string{
+		"api/alpha":                  "true",
+		"batch/v1beta1/cronjobs":     "true",
+		"extensions/v1beta1":         "false",
+		"scheduling.k8s.io/v1alpha1": "true",
+	}
+	assert.DeepEqual(t, []string{}, RuntimeConfigWarnings("v1.21.0", runtimeConfig))
+	warnings := RuntimeConfigWarnings("v1.25.0", runtimeConfig)
+	if len(warnings) != 2 || !strings.Contains(warnings[0], "batch/v1beta1") || !strings.Contains(warnings[1], "extensions/v1beta1") {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+}
+
+func TestConfigComponentFeatureGates(t *testing.T) {
+	t.Parallel()
+	for _, kubeVersion := range []string{"v1.14.0", "v1.21.1"} {
+		cfg, err := Config(ConfigData{
+			KubernetesVersion: kubeVersion,
+			KubeProxyMode:     "iptables",
+			FeatureGates:      map[string]bool{"Foo": true, "Bar": true},
+			ComponentFeatureGates: ComponentFeatureGates{
+				Kubelet:   map[string]bool{"Foo": false},
+				Scheduler: map[string]bool{"Baz": true},
+			},
+			RuntimeConfig: map[string]string{"api/alpha": "true"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", kubeVersion, err)
+		}
+		for _, expected := range []string{
+			`"feature-gates": "Bar=true,Baz=true,Foo=true"`,
+			`"runtime-config": "api/alpha=true"`,
+			"featureGates:\n\n  \"Bar\": true\n\n  \"Foo\": false\n",
+		} {
+			if !strings.Contains(cfg, expected) {
+				t.Errorf("expected %s config to contain %q:\n%s", kubeVersion, expected, cfg)
+			}
+		}
+	}
+}
+
+func TestPatchAPIVersionWarnings(t *testing.T) {
+	t.Parallel()
+	patches := []string{
+		"apiVersion: kubeadm.k8s.io/v1beta3\nkind: ClusterConfiguration\n",
+		"apiVersion: \"kubeadm.k8s.io/v1beta2\"\nkind: InitConfiguration\n",
+		"apiVersion: kubelet.config.k8s.io/v1beta1\nkind: KubeletConfiguration\n",
+		"kind: JoinConfiguration\n",
+	}
+	warnings := PatchAPIVersionWarnings("v1.25.3", "kubeadmConfigPatches", patches)
+	assert.DeepEqual(t, []string{
+		"kubeadmConfigPatches[1] sets apiVersion kubeadm.k8s.io/v1beta2 but kind generates kubeadm.k8s.io/v1beta3 for Kubernetes v1.25.3, the patch is ignored",
+	}, warnings)
+	assert.DeepEqual(t, []string(nil), PatchAPIVersionWarnings("bogus", "kubeadmConfigPatches", patches))
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package kubeconfig
 
 import (
+	"encoding/base64"
+
 	"sigs.k8s.io/kind/pkg/errors"
 )
 
@@ -39,3 +41,85 @@ func checkKubeadmExpectations(cfg *Config) error {
 	}
 	return nil
 }
+
+// Credentials holds the details needed to connect to a cluster's API server
+type Credentials struct {
+	// Context is the name of the context for the cluster
+	Context string
+	// Server is the API server address (https://hostname:port)
+	Server string
+	// CertificateAuthorityData is the PEM encoded CA bundle for the server
+	CertificateAuthorityData []byte
+	// ClientCertificateData is the PEM encoded client certificate
+	ClientCertificateData []byte
+	// ClientKeyData is the PEM encoded client key
+	ClientKeyData []byte
+}
+
+// CredentialsFor extracts the Credentials for the current context of cfg
+// cfg is expected to use embedded certificate data, as kind configs do
+func CredentialsFor(cfg *Config) (*Credentials, error) {
+	var ctx *NamedContext
+	for i := range cfg.Contexts {
+		if cfg.Contexts[i].Name == cfg.CurrentContext {
+			ctx = &cfg.Contexts[i]
+		}
+	}
+	if ctx == nil {
+		return nil, errors.Errorf("current context %q not found", cfg.CurrentContext)
+	}
+	out := &Credentials{Context: ctx.Name}
+	found := false
+	for _, c := range cfg.Clusters {
+		if c.Name != ctx.Context.Cluster {
+			continue
+		}
+		found = true
+		out.Server = c.Cluster.Server
+		ca, err := decodeField(c.Cluster.OtherFields, "certificate-authority-data")
+		if err != nil {
+			return nil, err
+		}
+		out.CertificateAuthorityData = ca
+	}
+	if !found {
+		return nil, errors.Errorf("cluster %q not found", ctx.Context.Cluster)
+	}
+	found = false
+	for _, u := range cfg.Users {
+		if u.Name != ctx.Context.User {
+			continue
+		}
+		found = true
+		cert, err := decodeField(u.User, "client-certificate-data")
+		if err != nil {
+			return nil, err
+		}
+		key, err := decodeField(u.User, "client-key-data")
+		if err != nil {
+			return nil, err
+		}
+		out.ClientCertificateData, out.ClientKeyData = cert, key
+	}
+	if !found {
+		return nil, errors.Errorf("user %q not found", ctx.Context.User)
+	}
+	return out, nil
+}
+
+// decodeField decodes the base64 encoded string field key in fields, if set
+func decodeField(fields map[string]interface{}, key string) ([]byte, error) {
+	v, ok := fields[key]
+	if !ok {
+		return nil, nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return nil, errors.Errorf("expected %s to be a string", key)
+	}
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode %s", key)
+	}
+	return b, nil
+}
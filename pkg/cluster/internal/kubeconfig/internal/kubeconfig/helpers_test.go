@@ -88,3 +88,49 @@ func TestCheckKubeadmExpectations(t *testing.T) {
 		})
 	}
 }
+
+func TestCredentialsFor(t *testing.T) {
+	t.Parallel()
+	cfg := &Config{
+		CurrentContext: "kind-foo",
+		Clusters: []NamedCluster{
+			{
+				Name: "kind-foo",
+				Cluster: Cluster{
+					Server: "https://127.0.0.1:6443",
+					OtherFields: map[string]interface{}{
+						"certificate-authority-data": "Y2E=",
+					},
+				},
+			},
+		},
+		Users: []NamedUser{
+			{
+				Name: "kind-foo",
+				User: map[string]interface{}{
+					"client-certificate-data": "Y2VydA==",
+					"client-key-data":         "a2V5",
+				},
+			},
+		},
+		Contexts: []NamedContext{
+			{
+				Name:    "kind-foo",
+				Context: Context{Cluster: "kind-foo", User: "kind-foo"},
+			},
+		},
+	}
+	creds, err := CredentialsFor(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.StringEqual(t, "kind-foo", creds.Context)
+	assert.StringEqual(t, "https://127.0.0.1:6443", creds.Server)
+	assert.StringEqual(t, "ca", string(creds.CertificateAuthorityData))
+	assert.StringEqual(t, "cert", string(creds.ClientCertificateData))
+	assert.StringEqual(t, "key", string(creds.ClientKeyData))
+
+	cfg.CurrentContext = "missing"
+	_, err = CredentialsFor(cfg)
+	assert.ExpectError(t, true, err)
+}
@@ -19,20 +19,65 @@ package kubeconfig
 import (
 	"os"
 	"path/filepath"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/errors"
 )
 
-// these are from
-// https://github.com/kubernetes/client-go/blob/611184f7c43ae2d520727f01d49620c7ed33412d/tools/clientcmd/loader.go#L439-L440
+// lockTimeout bounds waiting for another process to release the config lock
+const lockTimeout = 30 * time.Second
 
-func lockFile(filename string) error {
+// lockPollInterval is how often the client-go lock file is retried
+const lockPollInterval = 50 * time.Millisecond
+
+// lockFile locks filename for a read-modify-write, returning a function
+// that unlocks it.
+// kind processes serialize on an flock of a kind specific lock file where the
+// platform supports it, then all processes take the client-go lock file.
+// Other processes holding the client-go lock are waited for up to lockTimeout.
+func lockFile(filename string) (func(), error) {
 	// Make sure the dir exists before we try to create a lock file.
 	dir := filepath.Dir(filename)
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
 		if err = os.MkdirAll(dir, 0755); err != nil {
-			return err
+			return nil, err
+		}
+	}
+	unflock, err := flockFile(kindLockName(filename))
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		err := createLockFile(lockName(filename))
+		if err == nil {
+			break
+		}
+		if !os.IsExist(err) {
+			unflock()
+			return nil, err
 		}
+		if time.Now().After(deadline) {
+			unflock()
+			return nil, errors.Errorf(
+				"timed out waiting for %s, if no other process is writing the kubeconfig delete it",
+				lockName(filename),
+			)
+		}
+		time.Sleep(lockPollInterval)
 	}
-	f, err := os.OpenFile(lockName(filename), os.O_CREATE|os.O_EXCL, 0)
+	return func() {
+		_ = os.Remove(lockName(filename))
+		unflock()
+	}, nil
+}
+
+// createLockFile creates the client-go lock file at path, failing with an
+// os.IsExist error if it is already held
+// these are from
+// https://github.com/kubernetes/client-go/blob/611184f7c43ae2d520727f01d49620c7ed33412d/tools/clientcmd/loader.go#L439-L440
+func createLockFile(path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL, 0)
 	if err != nil {
 		return err
 	}
@@ -40,10 +85,12 @@ func lockFile(filename string) error {
 	return nil
 }
 
-func unlockFile(filename string) error {
-	return os.Remove(lockName(filename))
-}
-
 func lockName(filename string) string {
 	return filename + ".lock"
 }
+
+// kindLockName is the file kind flocks, unlike the client-go lock file it
+// is never removed as that would race with other processes locking it
+func kindLockName(filename string) string {
+	return filename + ".kind.lock"
+}
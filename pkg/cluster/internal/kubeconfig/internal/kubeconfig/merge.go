@@ -30,12 +30,11 @@ func WriteMerged(kindConfig *Config, explicitConfigPath string) error {
 	configPath := pathForMerge(explicitConfigPath, os.Getenv)
 
 	// lock config file the same as client-go
-	if err := lockFile(configPath); err != nil {
+	unlock, err := lockFile(configPath)
+	if err != nil {
 		return errors.Wrap(err, "failed to lock config file")
 	}
-	defer func() {
-		_ = unlockFile(configPath)
-	}()
+	defer unlock()
 
 	// read in existing
 	existing, err := read(configPath)
@@ -17,6 +17,7 @@ limitations under the License.
 package kubeconfig
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -230,6 +231,7 @@ func TestWriteMerged(t *testing.T) {
 	t.Run("normal merge", testWriteMergedNormal)
 	t.Run("bad kind config", testWriteMergedBogusConfig)
 	t.Run("merge into non-existent file", testWriteMergedNoExistingFile)
+	t.Run("concurrent merges", testWriteMergedConcurrent)
 }
 
 func testWriteMergedNormal(t *testing.T) {
@@ -445,3 +447,41 @@ users:
 `
 	assert.StringEqual(t, expected, string(contents))
 }
+
+func testWriteMergedConcurrent(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kind-testwritemerged")
+	if err != nil {
+		t.Fatalf("Failed to create tempdir: %d", err)
+	}
+	defer os.RemoveAll(dir)
+	configPath := filepath.Join(dir, "config")
+
+	// every cluster must survive concurrent merges into the same file
+	const clusters = 10
+	errs := make(chan error, clusters)
+	for i := 0; i < clusters; i++ {
+		go func(name string) {
+			errs <- WriteMerged(&Config{
+				Clusters: []NamedCluster{{Name: name}},
+				Users:    []NamedUser{{Name: name}},
+				Contexts: []NamedContext{{Name: name}},
+			}, configPath)
+		}(fmt.Sprintf("kind-%d", i))
+	}
+	for i := 0; i < clusters; i++ {
+		assert.ExpectError(t, false, <-errs)
+	}
+
+	merged, err := read(configPath)
+	if err != nil {
+		t.Fatalf("Failed to read merged kubeconfig: %v", err)
+	}
+	if len(merged.Contexts) != clusters {
+		t.Fatalf("expected %d contexts but got %d: %+v", clusters, len(merged.Contexts), merged.Contexts)
+	}
+	// the lock files are released
+	if _, err := os.Stat(lockName(configPath)); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed: %v", lockName(configPath), err)
+	}
+}
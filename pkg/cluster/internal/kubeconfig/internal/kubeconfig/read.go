@@ -17,6 +17,7 @@ limitations under the License.
 package kubeconfig
 
 import (
+	"bytes"
 	"io/ioutil"
 	"os"
 
@@ -30,7 +31,7 @@ import (
 // server is ignored if unset.
 func KINDFromRawKubeadm(rawKubeadmKubeConfig, clusterName, server string) (*Config, error) {
 	cfg := &Config{}
-	if err := yaml.Unmarshal([]byte(rawKubeadmKubeConfig), cfg); err != nil {
+	if err := yaml.Unmarshal(normalizeLineEndings([]byte(rawKubeadmKubeConfig)), cfg); err != nil {
 		return nil, err
 	}
 
@@ -67,6 +68,7 @@ func read(configPath string) (*Config, error) {
 	} else if err != nil {
 		return nil, errors.WithStack(err)
 	}
+	defer f.Close()
 
 	// otherwise read in and deserialize
 	cfg := &Config{}
@@ -74,9 +76,15 @@ func read(configPath string) (*Config, error) {
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	if err := yaml.Unmarshal(rawExisting, cfg); err != nil {
+	if err := yaml.Unmarshal(normalizeLineEndings(rawExisting), cfg); err != nil {
 		return nil, errors.WithStack(err)
 	}
 
 	return cfg, nil
 }
+
+// normalizeLineEndings converts CRLF line endings to LF, KUBECONFIG files
+// edited on windows hosts commonly contain these
+func normalizeLineEndings(raw []byte) []byte {
+	return bytes.Replace(raw, []byte("\r\n"), []byte("\n"), -1)
+}
@@ -102,4 +102,34 @@ users:
 			t.Errorf("type: %s", reflect.TypeOf(cfg.OtherFields["preferences"]))
 		}
 	})
+	// test that windows line endings are handled
+	t.Run("CRLF config", func(t *testing.T) {
+		t.Parallel()
+		const rawConfig = "apiVersion: v1\r\n" +
+			"clusters:\r\n" +
+			"- cluster:\r\n" +
+			"    server: https://192.168.9.4:6443\r\n" +
+			"  name: kind\r\n" +
+			"contexts:\r\n" +
+			"- context:\r\n" +
+			"    cluster: kind\r\n" +
+			"    user: kubernetes-admin\r\n" +
+			"  name: kubernetes-admin@kind\r\n" +
+			"current-context: kubernetes-admin@kind\r\n" +
+			"kind: Config\r\n" +
+			"users:\r\n" +
+			"- name: kubernetes-admin\r\n" +
+			"  user:\r\n" +
+			"    client-key-data: yep\r\n"
+		cfg, err := KINDFromRawKubeadm(rawConfig, "kind", "")
+		if err != nil {
+			t.Fatalf("failed to decode kubeconfig: %v", err)
+		}
+		if cfg.Clusters[0].Cluster.Server != "https://192.168.9.4:6443" {
+			t.Errorf("unexpected server %q", cfg.Clusters[0].Cluster.Server)
+		}
+		if cfg.Users[0].User["client-key-data"] != "yep" {
+			t.Errorf("unexpected user %+v", cfg.Users[0].User)
+		}
+	})
 }
@@ -29,12 +29,11 @@ func RemoveKIND(kindClusterName string, explicitPath string) error {
 	for _, configPath := range paths(explicitPath, os.Getenv) {
 		if err := func(configPath string) error {
 			// lock before modifying
-			if err := lockFile(configPath); err != nil {
+			unlock, err := lockFile(configPath)
+			if err != nil {
 				return errors.Wrap(err, "failed to lock config file")
 			}
-			defer func(configPath string) {
-				_ = unlockFile(configPath)
-			}(configPath)
+			defer unlock()
 
 			// read in existing
 			existing, err := read(configPath)
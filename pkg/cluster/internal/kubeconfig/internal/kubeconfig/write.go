@@ -26,11 +26,16 @@ import (
 
 // write writes cfg to configPath
 // it will ensure the directories in the path if necessary
+// the file is replaced atomically so readers never see a partial config
 func write(cfg *Config, configPath string) error {
 	encoded, err := Encode(cfg)
 	if err != nil {
 		return err
 	}
+	// replace the target of a symlinked config rather than the link
+	if resolved, err := filepath.EvalSymlinks(configPath); err == nil {
+		configPath = resolved
+	}
 	// NOTE: 0755 / 0600 are to match client-go
 	dir := filepath.Dir(configPath)
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
@@ -38,8 +43,37 @@ func write(cfg *Config, configPath string) error {
 			return errors.Wrap(err, "failed to create directory for KUBECONFIG")
 		}
 	}
-	if err := ioutil.WriteFile(configPath, encoded, 0600); err != nil {
+	// the temp file must be in the same directory for rename to be atomic
+	if err := writeAtomic(configPath, encoded); err != nil {
+		if os.IsPermission(err) {
+			return errors.Wrapf(err, "failed to write KUBECONFIG, ensure %q is writable by the current user and not marked read-only", configPath)
+		}
 		return errors.Wrap(err, "failed to write KUBECONFIG")
 	}
 	return nil
 }
+
+// writeAtomic writes contents to a temp file next to path, then renames
+// it over path
+func writeAtomic(path string, contents []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp-")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	// TempFile already creates the file 0600
+	_, err = f.Write(contents)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+	}
+	return err
+}
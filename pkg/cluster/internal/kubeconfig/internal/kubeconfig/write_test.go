@@ -28,6 +28,7 @@ import (
 func TestWrite(t *testing.T) {
 	t.Parallel()
 	t.Run("non-existent file", testWriteNoExistingFile)
+	t.Run("symlinked file", testWriteSymlink)
 }
 
 func testWriteNoExistingFile(t *testing.T) {
@@ -111,3 +112,31 @@ users:
 `
 	assert.StringEqual(t, expected, string(contents))
 }
+
+func testWriteSymlink(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kind-testwrite")
+	if err != nil {
+		t.Fatalf("Failed to create tempdir: %d", err)
+	}
+	defer os.RemoveAll(dir)
+
+	target := filepath.Join(dir, "target")
+	assert.ExpectError(t, false, ioutil.WriteFile(target, []byte("{}"), 0600))
+	link := filepath.Join(dir, "config")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	assert.ExpectError(t, false, write(&Config{CurrentContext: "kind-kind"}, link))
+
+	// the link is kept and the target replaced
+	info, err := os.Lstat(link)
+	assert.ExpectError(t, false, err)
+	if info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("expected %s to still be a symlink", link)
+	}
+	cfg, err := read(target)
+	assert.ExpectError(t, false, err)
+	assert.StringEqual(t, "kind-kind", cfg.CurrentContext)
+}
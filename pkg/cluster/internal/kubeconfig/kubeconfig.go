@@ -20,7 +20,9 @@ package kubeconfig
 
 import (
 	"bytes"
+	"context"
 
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 	"sigs.k8s.io/kind/pkg/errors"
 
@@ -32,8 +34,8 @@ import (
 
 // Export exports the kubeconfig given the cluster context and a path to write it to
 // This will always be an external kubeconfig
-func Export(p provider.Provider, name, explicitPath string) error {
-	cfg, err := get(p, name, true)
+func Export(ctx context.Context, p provider.Provider, name, explicitPath string) error {
+	cfg, err := get(ctx, p, name, true)
 	if err != nil {
 		return err
 	}
@@ -50,8 +52,8 @@ func Remove(clusterName, explicitPath string) error {
 
 // Get returns the kubeconfig for the cluster
 // external controls if the internal IP address is used or the host endpoint
-func Get(p provider.Provider, name string, external bool) (string, error) {
-	cfg, err := get(p, name, external)
+func Get(ctx context.Context, p provider.Provider, name string, external bool) (string, error) {
+	cfg, err := get(ctx, p, name, external)
 	if err != nil {
 		return "", err
 	}
@@ -62,43 +64,124 @@ func Get(p provider.Provider, name string, external bool) (string, error) {
 	return string(b), err
 }
 
+// Credentials is the subset of a kubeconfig needed to connect to a cluster
+type Credentials = kubeconfig.Credentials
+
+// GetCredentials returns the API server connection details for the cluster
+// external controls if the internal IP address is used or the host endpoint
+func GetCredentials(ctx context.Context, p provider.Provider, name string, external bool) (*Credentials, error) {
+	cfg, err := get(ctx, p, name, external)
+	if err != nil {
+		return nil, err
+	}
+	return kubeconfig.CredentialsFor(cfg)
+}
+
 // ContextForCluster returns the context name for a kind cluster based on
 // it's name. This key is used for all list entries of kind clusters
 func ContextForCluster(kindClusterName string) string {
 	return kubeconfig.KINDClusterKey(kindClusterName)
 }
 
-func get(p provider.Provider, name string, external bool) (*kubeconfig.Config, error) {
-	// find a control plane node to get the kubeadm config from
-	n, err := p.ListNodes(name)
+func get(ctx context.Context, p provider.Provider, name string, external bool) (*kubeconfig.Config, error) {
+	n, err := listNodes(ctx, p, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// if we're doing external we need to override the server endpoint
+	server := ""
+	if external {
+		endpoint, err := p.GetAPIServerEndpoint(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		server = "https://" + endpoint
+	}
+	return fromAdminConf(n, name, server)
+}
+
+// GetInternalLoadBalancer is like Get for the internal kubeconfig, but the
+// server is the provider's internal endpoint of the cluster's load balancer,
+// so that clients on the cluster network keep working while a control plane
+// node restarts. It fails for clusters without a load balancer.
+func GetInternalLoadBalancer(ctx context.Context, p provider.Provider, name string) (string, error) {
+	n, err := listNodes(ctx, p, name)
+	if err != nil {
+		return "", err
+	}
+	lb, err := nodeutils.ExternalLoadBalancerNode(n)
+	if err != nil {
+		return "", err
+	}
+	if lb == nil {
+		return "", errors.Errorf("cluster %q has no load balancer, it has a single control plane node", name)
+	}
+	// with a load balancer the internal endpoint is the load balancer's
+	endpoint, err := p.GetAPIServerInternalEndpoint(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	cfg, err := fromAdminConf(n, name, "https://"+endpoint)
+	if err != nil {
+		return "", err
+	}
+	b, err := kubeconfig.Encode(cfg)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// GetForServer is like Get, but the server is set to server, e.g. the
+// endpoint the API server is republished on by `kind expose`
+func GetForServer(ctx context.Context, p provider.Provider, name, server string) (string, error) {
+	n, err := listNodes(ctx, p, name)
+	if err != nil {
+		return "", err
+	}
+	cfg, err := fromAdminConf(n, name, server)
+	if err != nil {
+		return "", err
+	}
+	b, err := kubeconfig.Encode(cfg)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// listNodes returns the cluster's nodes, failing if there are none
+func listNodes(ctx context.Context, p provider.Provider, name string) ([]nodes.Node, error) {
+	n, err := p.ListNodes(ctx, name)
 	if err != nil {
 		return nil, err
 	}
+	if len(n) == 0 {
+		return nil, errors.WithReason(errors.Errorf("no nodes found for cluster %q", name), errors.ErrClusterNotFound)
+	}
+	return n, nil
+}
+
+// fromAdminConf reads the kubeadm admin kubeconfig from the first control
+// plane node, overriding the server endpoint if server is set
+func fromAdminConf(n []nodes.Node, name, server string) (*kubeconfig.Config, error) {
+	// find a control plane node to get the kubeadm config from
 	var buff bytes.Buffer
-	nodes, err := nodeutils.ControlPlaneNodes(n)
+	controlPlanes, err := nodeutils.ControlPlaneNodes(n)
 	if err != nil {
 		return nil, err
 	}
-	if len(nodes) < 1 {
+	if len(controlPlanes) < 1 {
 		return nil, errors.New("could not locate any control plane nodes")
 	}
-	node := nodes[0]
+	node := controlPlanes[0]
 
 	// grab kubeconfig version from the node
 	if err := node.Command("cat", "/etc/kubernetes/admin.conf").SetStdout(&buff).Run(); err != nil {
 		return nil, errors.Wrap(err, "failed to get cluster internal kubeconfig")
 	}
 
-	// if we're doing external we need to override the server endpoint
-	server := ""
-	if external {
-		endpoint, err := p.GetAPIServerEndpoint(name)
-		if err != nil {
-			return nil, err
-		}
-		server = "https://" + endpoint
-	}
-
 	// actually encode
 	return kubeconfig.KINDFromRawKubeadm(buff.String(), name, server)
 }
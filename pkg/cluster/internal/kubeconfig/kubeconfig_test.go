@@ -0,0 +1,152 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeconfig
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
+)
+
+const adminConf = `apiVersion: v1
+clusters:
+- cluster:
+    certificate-authority-data: definitelyacert
+    server: https://kind-control-plane:6443
+  name: kind
+contexts:
+- context:
+    cluster: kind
+    user: kubernetes-admin
+  name: kubernetes-admin@kind
+current-context: kubernetes-admin@kind
+kind: Config
+preferences: {}
+users:
+- name: kubernetes-admin
+  user:
+    client-certificate-data: seemslegit
+    client-key-data: yep
+`
+
+type fakeProvider struct {
+	provider.Provider
+	nodes            []nodes.Node
+	internalEndpoint string
+}
+
+func (p *fakeProvider) ListNodes(ctx context.Context, cluster string) ([]nodes.Node, error) {
+	return p.nodes, nil
+}
+
+func (p *fakeProvider) GetAPIServerInternalEndpoint(ctx context.Context, cluster string) (string, error) {
+	return p.internalEndpoint, nil
+}
+
+// fakeNode prints adminConf for any command run on it
+type fakeNode struct {
+	nodes.Node
+	name string
+	role string
+}
+
+func (n *fakeNode) String() string {
+	return n.name
+}
+
+func (n *fakeNode) Role() (string, error) {
+	return n.role, nil
+}
+
+func (n *fakeNode) Command(command string, args ...string) exec.Cmd {
+	return &fakeCmd{}
+}
+
+type fakeCmd struct {
+	exec.Cmd
+	stdout io.Writer
+}
+
+func (c *fakeCmd) SetStdout(w io.Writer) exec.Cmd {
+	c.stdout = w
+	return c
+}
+
+func (c *fakeCmd) Run() error {
+	_, err := io.WriteString(c.stdout, adminConf)
+	return err
+}
+
+func TestGetInternalLoadBalancer(t *testing.T) {
+	t.Parallel()
+	controlPlane := &fakeNode{name: "kind-control-plane", role: constants.ControlPlaneNodeRoleValue}
+	controlPlane2 := &fakeNode{name: "kind-control-plane2", role: constants.ControlPlaneNodeRoleValue}
+	lb := &fakeNode{name: "kind-external-load-balancer", role: constants.ExternalLoadBalancerNodeRoleValue}
+
+	t.Run("no load balancer", func(t *testing.T) {
+		t.Parallel()
+		p := &fakeProvider{
+			nodes:            []nodes.Node{controlPlane},
+			internalEndpoint: "kind-control-plane:6443",
+		}
+		_, err := GetInternalLoadBalancer(context.Background(), p, "kind")
+		assert.ExpectError(t, true, err)
+		if err != nil && !strings.Contains(err.Error(), "has no load balancer") {
+			t.Errorf("expected a no load balancer error, got: %v", err)
+		}
+	})
+
+	t.Run("no nodes", func(t *testing.T) {
+		t.Parallel()
+		_, err := GetInternalLoadBalancer(context.Background(), &fakeProvider{}, "kind")
+		assert.ExpectError(t, true, err)
+		if errors.ReasonForError(err) != errors.ErrClusterNotFound {
+			t.Errorf("expected the cluster not found reason, got: %v", err)
+		}
+	})
+
+	t.Run("load balancer", func(t *testing.T) {
+		t.Parallel()
+		p := &fakeProvider{
+			nodes:            []nodes.Node{lb, controlPlane, controlPlane2},
+			internalEndpoint: "kind-external-load-balancer:6443",
+		}
+		cfg, err := GetInternalLoadBalancer(context.Background(), p, "kind")
+		assert.ExpectError(t, false, err)
+		for _, expected := range []string{
+			"server: https://kind-external-load-balancer:6443",
+			"current-context: kind-kind",
+			"client-key-data: yep",
+		} {
+			if !strings.Contains(cfg, expected) {
+				t.Errorf("expected kubeconfig to contain %q, got:\n%s", expected, cfg)
+			}
+		}
+		if strings.Contains(cfg, "https://kind-control-plane:6443") {
+			t.Errorf("expected the control plane server to be replaced, got:\n%s", cfg)
+		}
+	})
+}
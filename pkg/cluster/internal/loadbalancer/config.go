@@ -21,6 +21,7 @@ import (
 	"text/template"
 
 	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
 )
 
 // ConfigData is supplied to the loadbalancer config template
@@ -28,6 +29,26 @@ type ConfigData struct {
 	ControlPlanePort int
 	BackendServers   map[string]string
 	IPv6             bool
+	// HealthCheck configures checking the backends
+	HealthCheck HealthCheck
+	// ExtraFrontends are additional TCP ports forwarded to their servers
+	ExtraFrontends []Frontend
+}
+
+// HealthCheck configures checking the load balancer backends
+type HealthCheck struct {
+	// IntervalMillis is the time between checks in milliseconds
+	IntervalMillis int64
+	Rise           int32
+	Fall           int32
+}
+
+// Frontend is an additional TCP port forwarded to Servers
+type Frontend struct {
+	Name string
+	Port int
+	// Servers maps the server names to their addresses
+	Servers map[string]string
 }
 
 // DefaultConfigTemplate is the loadbalancer config template
@@ -49,7 +70,7 @@ defaults
   timeout client 50000
   timeout server 50000
   # allow to boot despite dns don't resolve backends
-  default-server init-addr none
+  default-server init-addr none inter {{ .HealthCheck.IntervalMillis }} rise {{ .HealthCheck.Rise }} fall {{ .HealthCheck.Fall }}
 
 frontend control-plane
   bind *:{{ .ControlPlanePort }}
@@ -64,15 +85,86 @@ backend kube-apiservers
   {{range $server, $address := .BackendServers}}
   server {{ $server }} {{ $address }} check check-ssl verify none resolvers docker resolve-prefer {{ if $.IPv6 -}} ipv6 {{- else -}} ipv4 {{- end }}
   {{- end}}
+{{- range .ExtraFrontends }}
+
+frontend {{ .Name }}
+  bind *:{{ .Port }}
+  {{ if $.IPv6 -}}
+  bind :::{{ .Port }};
+  {{- end }}
+  default_backend {{ .Name }}
+
+backend {{ .Name }}
+  {{- range $server, $address := .Servers }}
+  server {{ $server }} {{ $address }} check resolvers docker resolve-prefer {{ if $.IPv6 -}} ipv6 {{- else -}} ipv4 {{- end }}
+  {{- end }}
+{{- end }}
+`
+
+// NginxConfigTemplate is the nginx loadbalancer config template, nginx only
+// checks the backends passively, taking one out for the interval after fall
+// failed connections
+const NginxConfigTemplate = `# generated by kind
+worker_processes 1;
+
+events {
+  worker_connections 1024;
+}
+
+stream {
+  upstream kube-apiservers {
+    {{- range $server, $address := .BackendServers }}
+    server {{ $address }} max_fails={{ $.HealthCheck.Fall }} fail_timeout={{ $.HealthCheck.IntervalMillis }}ms;
+    {{- end }}
+  }
+
+  server {
+    listen {{ .ControlPlanePort }};
+    {{- if .IPv6 }}
+    listen [::]:{{ .ControlPlanePort }};
+    {{- end }}
+    proxy_pass kube-apiservers;
+  }
+{{- range .ExtraFrontends }}
+
+  upstream {{ .Name }} {
+    {{- range $server, $address := .Servers }}
+    server {{ $address }} max_fails={{ $.HealthCheck.Fall }} fail_timeout={{ $.HealthCheck.IntervalMillis }}ms;
+    {{- end }}
+  }
+
+  server {
+    listen {{ .Port }};
+    {{- if $.IPv6 }}
+    listen [::]:{{ .Port }};
+    {{- end }}
+    proxy_pass {{ .Name }};
+  }
+{{- end }}
+}
 `
 
 // Config returns a kubeadm config generated from config data, in particular
 // the kubernetes version
 func Config(data *ConfigData) (config string, err error) {
-	t, err := template.New("loadbalancer-config").Parse(DefaultConfigTemplate)
+	return execute(DefaultConfigTemplate, data)
+}
+
+// ConfigFor returns the config for the load balancer implementation
+// generated from config data
+func ConfigFor(implementation config.LoadBalancerImplementation, data *ConfigData) (string, error) {
+	if implementation == config.NginxLoadBalancer {
+		return execute(NginxConfigTemplate, data)
+	}
+	return Config(data)
+}
+
+func execute(configTemplate string, data *ConfigData) (string, error) {
+	t, err := template.New("loadbalancer-config").Parse(configTemplate)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to parse config template")
 	}
+
 	// execute the template
 	var buff bytes.Buffer
 	err = t.Execute(&buff, data)
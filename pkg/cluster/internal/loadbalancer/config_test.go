@@ -0,0 +1,89 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadbalancer
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func TestConfigFor(t *testing.T) {
+	t.Parallel()
+	data := func(ipv6 bool) *ConfigData {
+		return &ConfigData{
+			ControlPlanePort: 6443,
+			BackendServers: map[string]string{
+				"kind-control-plane2": "kind-control-plane2:6443",
+				"kind-control-plane":  "kind-control-plane:6443",
+			},
+			IPv6: ipv6,
+			HealthCheck: HealthCheck{
+				IntervalMillis: 2000,
+				Rise:           2,
+				Fall:           3,
+			},
+			ExtraFrontends: []Frontend{
+				{
+					Name: "konnectivity",
+					Port: 8132,
+					Servers: map[string]string{
+						"kind-control-plane":  "kind-control-plane:8132",
+						"kind-control-plane2": "kind-control-plane2:8132",
+					},
+				},
+			},
+		}
+	}
+	cases := []struct {
+		Name           string
+		Implementation config.LoadBalancerImplementation
+		IPv6           bool
+		Golden         string
+	}{
+		{Name: "haproxy", Implementation: config.HAProxyLoadBalancer, Golden: "haproxy.cfg"},
+		{Name: "haproxy ipv6", Implementation: config.HAProxyLoadBalancer, IPv6: true, Golden: "haproxy-ipv6.cfg"},
+		{Name: "default", Implementation: "", Golden: "haproxy.cfg"},
+		{Name: "nginx", Implementation: config.NginxLoadBalancer, Golden: "nginx.conf"},
+		{Name: "nginx ipv6", Implementation: config.NginxLoadBalancer, IPv6: true, Golden: "nginx-ipv6.conf"},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			rendered, err := ConfigFor(tc.Implementation, data(tc.IPv6))
+			assert.ExpectError(t, false, err)
+			golden := filepath.Join("testdata", tc.Golden)
+			if *update && tc.Implementation != "" {
+				if err := ioutil.WriteFile(golden, []byte(rendered), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			expected, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.StringEqual(t, string(expected), rendered)
+		})
+	}
+}
@@ -16,8 +16,32 @@ limitations under the License.
 
 package loadbalancer
 
+import "sigs.k8s.io/kind/pkg/internal/apis/config"
+
 // Image defines the loadbalancer image:tag
 const Image = "kindest/haproxy:v20200708-548e36db"
 
 // ConfigPath defines the path to the config file in the image
 const ConfigPath = "/usr/local/etc/haproxy/haproxy.cfg"
+
+// NginxImage defines the nginx loadbalancer image:tag
+const NginxImage = "docker.io/library/nginx:1.25.3-alpine"
+
+// NginxConfigPath defines the path to the config file in the nginx image
+const NginxConfigPath = "/etc/nginx/nginx.conf"
+
+// ImageFor returns the default image for the load balancer implementation
+func ImageFor(implementation config.LoadBalancerImplementation) string {
+	if implementation == config.NginxLoadBalancer {
+		return NginxImage
+	}
+	return Image
+}
+
+// ConfigPathFor returns the config file path for the load balancer implementation
+func ConfigPathFor(implementation config.LoadBalancerImplementation) string {
+	if implementation == config.NginxLoadBalancer {
+		return NginxConfigPath
+	}
+	return ConfigPath
+}
@@ -56,6 +56,33 @@ func DumpDir(logger log.Logger, node nodes.Node, nodeDir, hostDir string) (err e
 	})
 }
 
+// DumpLogDirs dumps the node's /var/log to hostDir, or only the directories
+// under it needed for the selected components if opts filters components
+func DumpLogDirs(logger log.Logger, node nodes.Node, opts *Options, hostDir string) error {
+	if opts.All() {
+		return DumpDir(logger, node, "/var/log", hostDir)
+	}
+	var dirs []string
+	if opts.Has(ComponentPods) {
+		dirs = append(dirs, "pods", "containers")
+	}
+	if opts.Has(ComponentAudit) {
+		dirs = append(dirs, "kubernetes")
+	}
+	var errs []error
+	for _, dir := range dirs {
+		nodeDir := path.Join("/var/log", dir)
+		// not every node image has every directory, skip the missing ones
+		if err := node.Command("test", "-d", nodeDir).Run(); err != nil {
+			continue
+		}
+		if err := DumpDir(logger, node, nodeDir, filepath.Join(hostDir, dir)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.NewAggregate(errs)
+}
+
 // untar reads the tar file from r and writes it into dir.
 func untar(logger log.Logger, r io.Reader, dir string) (err error) {
 	tr := tar.NewReader(r)
@@ -0,0 +1,109 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// Log components that may be selected with Options.Components
+const (
+	// ComponentKubelet is the kubelet journal
+	ComponentKubelet = "kubelet"
+	// ComponentContainerd is the containerd journal
+	ComponentContainerd = "containerd"
+	// ComponentJournal is the full node journal
+	ComponentJournal = "journal"
+	// ComponentPods is the pod and container logs under /var/log
+	ComponentPods = "pods"
+	// ComponentAudit is Kubernetes audit logs and the kernel audit journal
+	ComponentAudit = "audit"
+	// ComponentNetwork is iptables and nftables rule dumps
+	ComponentNetwork = "network"
+	// ComponentConfig is the containerd and kubeadm configuration
+	ComponentConfig = "config"
+	// ComponentNode is the node container's inspect output and serial log
+	ComponentNode = "node"
+)
+
+// Components lists all of the valid log components
+var Components = []string{
+	ComponentKubelet,
+	ComponentContainerd,
+	ComponentJournal,
+	ComponentPods,
+	ComponentAudit,
+	ComponentNetwork,
+	ComponentConfig,
+	ComponentNode,
+}
+
+// Options controls which logs are collected
+type Options struct {
+	// Since limits journal logs to entries newer than this, if non-zero
+	Since time.Duration
+	// Components limits collection to these components, if non-empty
+	Components []string
+}
+
+// Validate returns an error if o contains unknown components
+func (o *Options) Validate() error {
+	for _, c := range o.Components {
+		found := false
+		for _, valid := range Components {
+			found = found || c == valid
+		}
+		if !found {
+			return errors.Errorf("unknown log component %q, expected one of: %s", c, strings.Join(Components, ", "))
+		}
+	}
+	if o.Since < 0 {
+		return errors.Errorf("invalid since duration: %v", o.Since)
+	}
+	return nil
+}
+
+// All returns true if all components should be collected
+func (o *Options) All() bool {
+	return o == nil || len(o.Components) == 0
+}
+
+// Has returns true if component should be collected
+func (o *Options) Has(component string) bool {
+	if o.All() {
+		return true
+	}
+	for _, c := range o.Components {
+		if c == component {
+			return true
+		}
+	}
+	return false
+}
+
+// JournalArgs returns the journalctl arguments for o, followed by args
+func (o *Options) JournalArgs(args ...string) []string {
+	out := []string{"--no-pager"}
+	if o != nil && o.Since > 0 {
+		out = append(out, fmt.Sprintf("--since=-%ds", int64(o.Since.Seconds())))
+	}
+	return append(out, args...)
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestOptionsValidate(t *testing.T) {
+	t.Parallel()
+	assert.ExpectError(t, false, (&Options{}).Validate())
+	assert.ExpectError(t, false, (&Options{Components: []string{ComponentKubelet, ComponentPods}}).Validate())
+	assert.ExpectError(t, true, (&Options{Components: []string{"bogus"}}).Validate())
+	assert.ExpectError(t, true, (&Options{Since: -time.Second}).Validate())
+}
+
+func TestOptionsHas(t *testing.T) {
+	t.Parallel()
+	var nilOptions *Options
+	if !nilOptions.Has(ComponentKubelet) || !(&Options{}).Has(ComponentNetwork) {
+		t.Errorf("expected all components with no filter")
+	}
+	o := &Options{Components: []string{ComponentKubelet}}
+	if !o.Has(ComponentKubelet) || o.Has(ComponentPods) {
+		t.Errorf("expected only the selected component")
+	}
+}
+
+func TestOptionsJournalArgs(t *testing.T) {
+	t.Parallel()
+	assert.DeepEqual(t, []string{"--no-pager", "-u", "kubelet.service"}, (&Options{}).JournalArgs("-u", "kubelet.service"))
+	assert.DeepEqual(t, []string{"--no-pager", "--since=-5400s"}, (&Options{Since: 90 * time.Minute}).JournalArgs())
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package network implements describing the container network of a cluster
+package network
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
+)
+
+// Info returns the container network of cluster with the addresses of the
+// cluster's containers on it, and the MTU of the nodes' network interface
+func Info(ctx context.Context, p provider.Provider, cluster string) (*provider.NetworkInfo, error) {
+	n, err := p.ListNodes(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	if len(n) == 0 {
+		return nil, errors.ClusterNotFound(cluster)
+	}
+	info, err := p.NetworkInfo(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range n {
+		ipv4, ipv6, err := node.IP()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get the addresses of node %s", node.String())
+		}
+		// e.g. the cluster disk container is not on the network
+		if ipv4 == "" && ipv6 == "" {
+			continue
+		}
+		info.Nodes = append(info.Nodes, provider.NodeAddress{
+			Name: node.String(),
+			IPv4: ipv4,
+			IPv6: ipv6,
+		})
+	}
+	sort.Slice(info.Nodes, func(i, j int) bool {
+		return info.Nodes[i].Name < info.Nodes[j].Name
+	})
+	info.MTU = nodeMTU(ctx, n)
+	return info, nil
+}
+
+// nodeMTU returns the MTU of eth0 in the first running Kubernetes node, the
+// network interface the kind CNI and pods use, or zero if it can't be read
+func nodeMTU(ctx context.Context, n []nodes.Node) int {
+	for _, node := range n {
+		role, err := node.Role()
+		if err != nil || (role != constants.ControlPlaneNodeRoleValue && role != constants.WorkerNodeRoleValue) {
+			continue
+		}
+		lines, err := exec.OutputLines(node.CommandContext(ctx, "cat", "/sys/class/net/eth0/mtu"))
+		if err != nil || len(lines) != 1 {
+			continue
+		}
+		if mtu, err := strconv.Atoi(strings.TrimSpace(lines[0])); err == nil {
+			return mtu
+		}
+	}
+	return 0
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oidc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"testing"
+)
+
+func TestGenerateCertificates(t *testing.T) {
+	t.Parallel()
+	certs, err := GenerateCertificates("kind-oidc-issuer")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pair, err := tls.X509KeyPair(certs.Cert, certs.Key)
+	if err != nil {
+		t.Fatalf("serving certificate and key do not match: %v", err)
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(certs.CA) {
+		t.Fatalf("failed to parse CA")
+	}
+	if _, err := cert.Verify(x509.VerifyOptions{
+		DNSName: "kind-oidc-issuer",
+		Roots:   roots,
+	}); err != nil {
+		t.Errorf("serving certificate does not verify against the CA: %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oidc
+
+import (
+	"bytes"
+	"text/template"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// ConfigData is supplied to the test issuer config template
+type ConfigData struct {
+	IssuerURL string
+	ClientID  string
+}
+
+// ConfigTemplate is the dex config for the test issuer. The password grant
+// allows fetching ID tokens without a browser
+const ConfigTemplate = `issuer: {{ .IssuerURL }}
+storage:
+  type: memory
+web:
+  https: 0.0.0.0:{{ .Port }}
+  tlsCert: {{ .CertPath }}
+  tlsKey: {{ .KeyPath }}
+oauth2:
+  skipApprovalScreen: true
+  passwordConnector: local
+enablePasswordDB: true
+staticClients:
+- id: {{ .ClientID }}
+  name: kind
+  secret: kind-secret
+  redirectURIs:
+  - http://localhost:8000
+  - urn:ietf:wg:oauth:2.0:oob
+staticPasswords:
+- email: admin@example.com
+  # bcrypt hash of "password"
+  hash: "$2a$10$2b2cU8CPhOTaGrs1HRQuAueS7JTT5ZHsHSzYiFPm1leZck7Mc8T4W"
+  username: admin
+  userID: 08a8684b-db88-4b73-90a9-3cd1661f5466
+`
+
+// Config returns a test issuer config generated from config data
+func Config(data *ConfigData) (config string, err error) {
+	t, err := template.New("dex-config").Parse(ConfigTemplate)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse config template")
+	}
+	// execute the template
+	var buff bytes.Buffer
+	err = t.Execute(&buff, struct {
+		*ConfigData
+		Port     int
+		CertPath string
+		KeyPath  string
+	}{data, Port, CertPath, KeyPath})
+	if err != nil {
+		return "", errors.Wrap(err, "error executing config template")
+	}
+	return buff.String(), nil
+}
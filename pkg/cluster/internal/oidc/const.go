@@ -0,0 +1,61 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oidc
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+)
+
+// Image defines the test issuer image:tag
+const Image = "ghcr.io/dexidp/dex:v2.37.0"
+
+// Port is the https port the test issuer listens on
+const Port = 5556
+
+// ConfigPath defines the path to the config file in the image, the issuer
+// is started once it is written. /var/dex is writable by the dex user
+const ConfigPath = "/var/dex/kind.yaml"
+
+// CertPath and KeyPath define the paths to the serving certificate and key
+// in the image
+const (
+	CertPath = "/var/dex/tls.crt"
+	KeyPath  = "/var/dex/tls.key"
+)
+
+// Command starts the test issuer when ConfigPath is written
+var Command = []string{
+	"sh", "-c",
+	fmt.Sprintf("until [ -f %s ]; do sleep 1; done; exec dex serve %s", ConfigPath, ConfigPath),
+}
+
+// CAPath is where the issuer CA is written on the control plane nodes,
+// the API server static pod mounts this directory
+const CAPath = "/etc/kubernetes/pki/oidc-ca.crt"
+
+// IssuerName returns the container name, and hostname, of the test issuer
+// for the cluster
+func IssuerName(clusterName string) string {
+	return fmt.Sprintf("%s-%s", clusterName, constants.OIDCIssuerNodeRoleValue)
+}
+
+// IssuerURL returns the issuer URL of the test issuer for the cluster
+func IssuerURL(clusterName string) string {
+	return fmt.Sprintf("https://%s:%d/dex", IssuerName(clusterName), Port)
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package patch
 
 import (
+	"encoding/json"
 	"strings"
 
 	"sigs.k8s.io/kind/pkg/errors"
@@ -77,3 +78,43 @@ func KubeYAML(toPatch string, patches []string, patches6902 []config.PatchJSON69
 	// verify that all patches were used
 	return builder.String(), nil
 }
+
+// Mutator modifies a decoded Kubernetes object YAML document in place
+// kind is the document's kind, for matching
+type Mutator func(kind string, doc map[string]interface{}) error
+
+// KubeYAMLMutate takes a Kubernetes object YAML document stream and calls
+// each of mutators on every document in order, returning the result
+func KubeYAMLMutate(toMutate string, mutators []Mutator) (string, error) {
+	if len(mutators) == 0 {
+		return toMutate, nil
+	}
+	resources, err := parseResources(toMutate)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse yaml to mutate")
+	}
+	builder := &strings.Builder{}
+	for i, r := range resources {
+		doc := map[string]interface{}{}
+		if err := json.Unmarshal(r.json, &doc); err != nil {
+			return "", errors.Wrap(err, "failed to decode resource")
+		}
+		for _, m := range mutators {
+			if err := m(r.matchInfo.Kind, doc); err != nil {
+				return "", errors.Wrapf(err, "failed to mutate %s", r.matchInfo.Kind)
+			}
+		}
+		if r.json, err = json.Marshal(doc); err != nil {
+			return "", errors.Wrap(err, "failed to encode mutated resource")
+		}
+		if err := r.encodeTo(builder); err != nil {
+			return "", errors.Wrap(err, "failed to write mutated resource")
+		}
+		if i+1 < len(resources) {
+			if _, err := builder.WriteString("---\n"); err != nil {
+				return "", errors.Wrap(err, "failed to write document separator")
+			}
+		}
+	}
+	return builder.String(), nil
+}
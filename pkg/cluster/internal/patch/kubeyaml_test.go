@@ -75,6 +75,34 @@ func TestKubeYAML(t *testing.T) {
 	}
 }
 
+func TestKubeYAMLMutate(t *testing.T) {
+	t.Parallel()
+	const toMutate = `apiVersion: kubeadm.k8s.io/v1beta2
+kind: ClusterConfiguration
+clusterName: kind
+---
+apiVersion: kubeadm.k8s.io/v1beta2
+kind: InitConfiguration
+`
+	const expected = `apiVersion: kubeadm.k8s.io/v1beta2
+clusterName: mutated
+kind: ClusterConfiguration
+---
+apiVersion: kubeadm.k8s.io/v1beta2
+kind: InitConfiguration
+`
+	out, err := KubeYAMLMutate(toMutate, []Mutator{
+		func(kind string, doc map[string]interface{}) error {
+			if kind == "ClusterConfiguration" {
+				doc["clusterName"] = "mutated"
+			}
+			return nil
+		},
+	})
+	assert.ExpectError(t, false, err)
+	assert.StringEqual(t, expected, out)
+}
+
 const normalKubeadmConfig = `# config generated by kind
 apiVersion: kubeadm.k8s.io/v1beta2
 kind: ClusterConfiguration
@@ -19,6 +19,13 @@ package docker
 // clusterLabelKey is applied to each "node" docker container for identification
 const clusterLabelKey = "io.x-k8s.kind.cluster"
 
+// cloneLabelKey is applied to node images committed by kind clone, with the
+// name of the cluster they were committed for
+const cloneLabelKey = "io.x-k8s.kind.clone"
+
 // nodeRoleLabelKey is applied to each "node" docker container for categorization
 // of nodes by role
 const nodeRoleLabelKey = "io.x-k8s.kind.role"
+
+// DefaultVMRuntime is the docker runtime used for VM nodes when not specified
+const DefaultVMRuntime = "kata-runtime"
@@ -0,0 +1,81 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
+)
+
+// NodeEvents is part of the providers.Provider interface
+func (p *Provider) NodeEvents(ctx context.Context, cluster string, opts provider.NodeEventsOptions, fn func(provider.NodeEvent)) error {
+	// docker only keeps recent events, since 0 asks for all of them
+	since := "0"
+	if !opts.Since.IsZero() {
+		since = strconv.FormatInt(opts.Since.Unix(), 10)
+	}
+	args := []string{
+		"events",
+		"--format", "{{json .}}",
+		"--filter", "type=container",
+		"--filter", fmt.Sprintf("label=%s=%s", clusterLabelKey, cluster),
+		"--since", since,
+	}
+	for _, action := range provider.NodeEventActions {
+		args = append(args, "--filter", "event="+action)
+	}
+	if !opts.Follow {
+		args = append(args, "--until", strconv.FormatInt(time.Now().Unix(), 10))
+	}
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	return common.StreamOutputLines(ctx, cmd, func(line string) error {
+		if e, ok := parseEvent(line); ok {
+			fn(e)
+		}
+		return nil
+	})
+}
+
+// parseEvent parses a line of docker events --format '{{json .}}' output,
+// returning false for lines that are not container events
+func parseEvent(line string) (provider.NodeEvent, bool) {
+	event := struct {
+		Type   string
+		Action string
+		Actor  struct {
+			Attributes map[string]string
+		}
+		TimeNano int64 `json:"timeNano"`
+	}{}
+	if err := json.Unmarshal([]byte(line), &event); err != nil || event.Type != "container" {
+		return provider.NodeEvent{}, false
+	}
+	return provider.NodeEvent{
+		Time:     time.Unix(0, event.TimeNano),
+		Node:     event.Actor.Attributes["name"],
+		Action:   event.Action,
+		ExitCode: event.Actor.Attributes["exitCode"],
+	}, true
+}
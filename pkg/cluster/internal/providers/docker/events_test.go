@@ -0,0 +1,66 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestParseEvent(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name     string
+		Line     string
+		Expected provider.NodeEvent
+		OK       bool
+	}{
+		{
+			Name: "die",
+			Line: `{"status":"die","id":"1a2b","from":"kindest/node:v1.19.1","Type":"container","Action":"die",` +
+				`"Actor":{"ID":"1a2b","Attributes":{"exitCode":"137","io.x-k8s.kind.cluster":"kind","name":"kind-worker"}},` +
+				`"scope":"local","time":1602669600,"timeNano":1602669600123456789}`,
+			Expected: provider.NodeEvent{
+				Time:     time.Unix(0, 1602669600123456789),
+				Node:     "kind-worker",
+				Action:   "die",
+				ExitCode: "137",
+			},
+			OK: true,
+		},
+		{
+			Name: "network event",
+			Line: `{"Type":"network","Action":"connect","Actor":{"ID":"3c4d","Attributes":{"name":"kind"}},"timeNano":1602669600123456789}`,
+		},
+		{
+			Name: "garbage",
+			Line: `Error response from daemon: bad filter`,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			e, ok := parseEvent(tc.Line)
+			assert.BoolEqual(t, tc.OK, ok)
+			assert.DeepEqual(t, tc.Expected, e)
+		})
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package docker
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -25,59 +26,79 @@ import (
 	"sigs.k8s.io/kind/pkg/exec"
 	"sigs.k8s.io/kind/pkg/log"
 
+	"sigs.k8s.io/kind/pkg/cluster/internal/oidc"
 	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
 	"sigs.k8s.io/kind/pkg/internal/apis/config"
 	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/internal/events"
 )
 
 // ensureNodeImages ensures that the node images used by the create
 // configuration are present
-func ensureNodeImages(logger log.Logger, status *cli.Status, cfg *config.Cluster) error {
+func ensureNodeImages(ctx context.Context, logger log.Logger, status *cli.Status, cfg *config.Cluster) error {
 	// pull each required image
 	for _, image := range common.RequiredNodeImages(cfg).List() {
 		// prints user friendly message
 		friendlyImageName, image := sanitizeImage(image)
 		status.Start(fmt.Sprintf("Ensuring node image (%s) 🖼", friendlyImageName))
-		if _, err := pullIfNotPresent(logger, image, 4); err != nil {
+		start := time.Now()
+		pulled, err := pullIfNotPresent(ctx, logger, image)
+		if err != nil {
 			status.End(false)
-			return err
+			return errors.WithReason(err, errors.ErrNodeImagePullFailed)
+		}
+		if pulled {
+			events.Emit(logger, events.Event{
+				Type:     events.ImagePulled,
+				Image:    image,
+				Duration: time.Since(start),
+			})
 		}
 	}
 	return nil
 }
 
+// missingImages returns the images required by cfg that are not present locally
+func missingImages(ctx context.Context, cfg *config.Cluster) ([]string, error) {
+	images := common.RequiredNodeImages(cfg)
+	if clusterHasImplicitLoadBalancer(cfg) {
+		images.Insert(common.LoadBalancerImage(cfg))
+	}
+	if cfg.Authentication.OIDC.TestIssuer {
+		images.Insert(common.OverrideImage(cfg, oidc.Image))
+	}
+	missing := []string{}
+	for _, image := range images.List() {
+		_, image := sanitizeImage(image)
+		if err := exec.CommandContext(ctx, "docker", "inspect", "--type=image", image).Run(); err != nil {
+			missing = append(missing, image)
+		}
+	}
+	return missing, nil
+}
+
 // pullIfNotPresent will pull an image if it is not present locally
-// retrying up to retries times
+// retrying as configured by the retry policy of ctx
 // it returns true if it attempted to pull, and any errors from pulling
-func pullIfNotPresent(logger log.Logger, image string, retries int) (pulled bool, err error) {
+func pullIfNotPresent(ctx context.Context, logger log.Logger, image string) (pulled bool, err error) {
 	// TODO(bentheelder): switch most (all) of the logging here to debug level
 	// once we have configurable log levels
 	// if this did not return an error, then the image exists locally
-	cmd := exec.Command("docker", "inspect", "--type=image", image)
+	cmd := exec.CommandContext(ctx, "docker", "inspect", "--type=image", image)
 	if err := cmd.Run(); err == nil {
 		logger.V(1).Infof("Image: %s present locally", image)
 		return false, nil
 	}
 	// otherwise try to pull it
-	return true, pull(logger, image, retries)
+	return true, pull(ctx, logger, image)
 }
 
-// pull pulls an image, retrying up to retries times
-func pull(logger log.Logger, image string, retries int) error {
+// pull pulls an image, retrying as configured by the retry policy of ctx
+func pull(ctx context.Context, logger log.Logger, image string) error {
 	logger.V(1).Infof("Pulling image: %s ...", image)
-	err := exec.Command("docker", "pull", image).Run()
-	// retry pulling up to retries times if necessary
-	if err != nil {
-		for i := 0; i < retries; i++ {
-			time.Sleep(time.Second * time.Duration(i+1))
-			logger.V(1).Infof("Trying again to pull image: %q ... %v", image, err)
-			// TODO(bentheelder): add some backoff / sleep?
-			err = exec.Command("docker", "pull", image).Run()
-			if err == nil {
-				break
-			}
-		}
-	}
+	err := common.Retry(ctx, logger, "pull image "+image, func() error {
+		return exec.CommandContext(ctx, "docker", "pull", image).Run()
+	})
 	return errors.Wrapf(err, "failed to pull image %q", image)
 }
 
@@ -17,10 +17,12 @@ limitations under the License.
 package docker
 
 import (
+	"context"
 	"crypto/sha1"
 	"encoding/binary"
 	"errors"
 	"net"
+	"os"
 	"regexp"
 	"strings"
 
@@ -40,11 +42,24 @@ import (
 // networks.
 const fixedNetworkName = "kind"
 
+// clusterNetworkName returns the network nodes are attached to,
+// fixedNetworkName unless overridden by KIND_EXPERIMENTAL_DOCKER_NETWORK,
+// prefixed with the cluster name prefix if any
+func clusterNetworkName(namePrefix string) string {
+	if n := os.Getenv("KIND_EXPERIMENTAL_DOCKER_NETWORK"); n != "" {
+		return n
+	}
+	if namePrefix != "" {
+		return namePrefix + "-" + fixedNetworkName
+	}
+	return fixedNetworkName
+}
+
 // ensureNetwork checks if docker network by name exists, if not it creates it
-func ensureNetwork(name string) error {
+func ensureNetwork(ctx context.Context, name string) error {
 	// TODO: the network might already exist and not have ipv6 ... :|
 	// discussion: https://github.com/kubernetes-sigs/kind/pull/1508#discussion_r414594198
-	exists, err := checkIfNetworkExists(name)
+	exists, err := checkIfNetworkExists(ctx, name)
 	if err != nil {
 		return err
 	}
@@ -57,7 +72,7 @@ func ensureNetwork(name string) error {
 	// obtained from the ULA fc00::/8 range
 	// Make N attempts with "probing" in case we happen to collide
 	subnet := generateULASubnetFromName(name, 0)
-	err = createNetwork(name, subnet)
+	err = createNetwork(ctx, name, subnet)
 	if err == nil {
 		// Success!
 		return nil
@@ -69,7 +84,7 @@ func ensureNetwork(name string) error {
 	// If it is, make more attempts below
 	if isIPv6UnavailableError(err) {
 		// only one attempt, IPAM is automatic in ipv4 only
-		return createNetwork(name, "")
+		return createNetwork(ctx, name, "")
 	} else if !isPoolOverlapError(err) {
 		// unknown error ...
 		return err
@@ -79,7 +94,7 @@ func ensureNetwork(name string) error {
 	const maxAttempts = 5
 	for attempt := int32(1); attempt < maxAttempts; attempt++ {
 		subnet := generateULASubnetFromName(name, attempt)
-		err = createNetwork(name, subnet)
+		err = createNetwork(ctx, name, subnet)
 		if err == nil {
 			// success!
 			return nil
@@ -91,18 +106,18 @@ func ensureNetwork(name string) error {
 	return errors.New("exhausted attempts trying to find a non-overlapping subnet")
 }
 
-func createNetwork(name, ipv6Subnet string) error {
+func createNetwork(ctx context.Context, name, ipv6Subnet string) error {
 	if ipv6Subnet == "" {
-		return exec.Command("docker", "network", "create", "-d=bridge",
+		return exec.CommandContext(ctx, "docker", "network", "create", "-d=bridge",
 			"-o", "com.docker.network.bridge.enable_ip_masquerade=true",
 			name).Run()
 	}
-	return exec.Command("docker", "network", "create", "-d=bridge",
+	return exec.CommandContext(ctx, "docker", "network", "create", "-d=bridge",
 		"-o", "com.docker.network.bridge.enable_ip_masquerade=true",
 		"--ipv6", "--subnet", ipv6Subnet, name).Run()
 }
 
-func checkIfNetworkExists(name string) (bool, error) {
+func checkIfNetworkExists(ctx context.Context, name string) (bool, error) {
 	out, err := exec.Output(exec.Command(
 		"docker", "network", "ls",
 		"--filter=name=^"+regexp.QuoteMeta(name)+"$",
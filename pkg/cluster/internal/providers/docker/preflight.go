@@ -0,0 +1,95 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// preflightInfo gathers what common.Preflight needs from docker, this is
+// best effort and anything that can't be determined is left unset
+func preflightInfo(ctx context.Context, cfg *config.Cluster, networkName string) *common.PreflightInfo {
+	desktop := isDockerDesktop(ctx)
+	// rootless docker lists name=rootless in its security options
+	rootless := strings.Contains(dockerInfo(ctx, "{{json .SecurityOptions}}"), "name=rootless")
+	info := &common.PreflightInfo{
+		Architecture:       dockerInfo(ctx, "{{.Architecture}}"),
+		ImageArchitectures: map[string]string{},
+		// Docker Desktop ships with qemu emulation for foreign images
+		Emulation:     desktop,
+		StoragePath:   dockerInfo(ctx, "{{.DockerRootDir}}"),
+		StorageDriver: dockerInfo(ctx, "{{.Driver}}"),
+		DockerDesktop: desktop,
+		VM:            detectVM(ctx),
+		Rootless:      rootless,
+		Remote:        isRemote(ctx),
+	}
+	for _, image := range common.RequiredNodeImages(cfg).List() {
+		_, image := sanitizeImage(image)
+		cmd := exec.CommandContext(ctx, "docker", "image", "inspect", "-f", "{{.Architecture}}", image)
+		if lines, err := exec.OutputLines(cmd); err == nil && len(lines) == 1 {
+			info.ImageArchitectures[image] = strings.TrimSpace(lines[0])
+		}
+	}
+	if subnets, err := getSubnets(ctx, networkName); err == nil {
+		info.NetworkSubnets = subnets
+	}
+	return info
+}
+
+// dockerInfo returns the docker info field for format, or "" if unavailable
+func dockerInfo(ctx context.Context, format string) string {
+	lines, err := exec.OutputLines(exec.CommandContext(ctx, "docker", "info", "-f", format))
+	if err != nil || len(lines) != 1 {
+		return ""
+	}
+	return strings.TrimSpace(lines[0])
+}
+
+// isRemote returns true if the docker daemon is on another host, per
+// DOCKER_HOST or else the endpoint of the current docker context
+func isRemote(ctx context.Context) bool {
+	host := os.Getenv("DOCKER_HOST")
+	if host == "" {
+		host = contextHost(ctx)
+	}
+	return isRemoteHost(host)
+}
+
+// contextHost returns the docker endpoint of the current docker context,
+// which respects DOCKER_CONTEXT, or "" if unavailable
+func contextHost(ctx context.Context) string {
+	cmd := exec.CommandContext(ctx, "docker", "context", "inspect", "-f", "{{.Endpoints.docker.Host}}")
+	lines, err := exec.OutputLines(cmd)
+	if err != nil || len(lines) != 1 {
+		return ""
+	}
+	return strings.TrimSpace(lines[0])
+}
+
+// isRemoteHost returns true if the docker endpoint host is not a local
+// socket or named pipe, e.g. tcp:// or ssh://
+func isRemoteHost(host string) bool {
+	return host != "" && !strings.HasPrefix(host, "unix://") && !strings.HasPrefix(host, "npipe://")
+}
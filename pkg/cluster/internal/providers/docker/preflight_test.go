@@ -0,0 +1,44 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestIsRemoteHost(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Host     string
+		Expected bool
+	}{
+		{Host: "", Expected: false},
+		{Host: "unix:///var/run/docker.sock", Expected: false},
+		{Host: "npipe:////./pipe/docker_engine", Expected: false},
+		{Host: "tcp://10.0.0.2:2376", Expected: true},
+		{Host: "ssh://user@build-host", Expected: true},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Host, func(t *testing.T) {
+			t.Parallel()
+			assert.BoolEqual(t, tc.Expected, isRemoteHost(tc.Host))
+		})
+	}
+}
@@ -17,11 +17,13 @@ limitations under the License.
 package docker
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/util/sets"
 
@@ -30,18 +32,36 @@ import (
 	"sigs.k8s.io/kind/pkg/exec"
 	"sigs.k8s.io/kind/pkg/log"
 
+	"sigs.k8s.io/kind/pkg/cluster/internal/disk"
 	internallogs "sigs.k8s.io/kind/pkg/cluster/internal/logs"
 	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
 	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 	"sigs.k8s.io/kind/pkg/internal/apis/config"
 	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/internal/events"
 )
 
 // NewProvider returns a new provider based on executing `docker ...`
-func NewProvider(logger log.Logger) provider.Provider {
+// namePrefix is the cluster name prefix, which also prefixes the network
+func NewProvider(logger log.Logger, namePrefix string) provider.Provider {
 	return &Provider{
-		logger: logger,
+		logger:     logger,
+		namePrefix: namePrefix,
+	}
+}
+
+// NewVMProvider returns a new experimental provider that runs each node
+// container under ociRuntime, a docker runtime that boots containers as
+// lightweight VMs with their own kernel (such as kata-runtime)
+func NewVMProvider(logger log.Logger, namePrefix, ociRuntime string) provider.Provider {
+	if ociRuntime == "" {
+		ociRuntime = DefaultVMRuntime
+	}
+	return &Provider{
+		logger:     logger,
+		namePrefix: namePrefix,
+		vmRuntime:  ociRuntime,
 	}
 }
 
@@ -49,34 +69,80 @@ func NewProvider(logger log.Logger) provider.Provider {
 // see NewProvider
 type Provider struct {
 	logger log.Logger
+	// vmRuntime is the docker runtime used for nodes, if set
+	vmRuntime string
+	// namePrefix is the cluster name prefix, if set
+	namePrefix string
+}
+
+// String is part of the providers.Provider interface
+func (p *Provider) String() string {
+	if p.vmRuntime != "" {
+		return "docker-vm"
+	}
+	return "docker"
 }
 
 // Provision is part of the providers.Provider interface
-func (p *Provider) Provision(status *cli.Status, cfg *config.Cluster) (err error) {
+func (p *Provider) Provision(ctx context.Context, status *cli.Status, cfg *config.Cluster) (err error) {
 	// TODO: validate cfg
+	// the VM runtime must be registered with dockerd for this to work
+	if p.vmRuntime != "" {
+		if !hasRuntime(ctx, p.vmRuntime) {
+			return errors.Errorf("docker runtime %q is not available, it must be configured in dockerd to use VM nodes", p.vmRuntime)
+		}
+		p.logger.Warnf("WARNING: Using experimental VM nodes with docker runtime %q", p.vmRuntime)
+	}
+
 	// ensure node images are pulled before actually provisioning
-	if err := ensureNodeImages(p.logger, status, cfg); err != nil {
+	if err := ensureNodeImages(ctx, p.logger, status, cfg); err != nil {
 		return err
 	}
 
 	// ensure the pre-requesite network exists
-	networkName := fixedNetworkName
-	if n := os.Getenv("KIND_EXPERIMENTAL_DOCKER_NETWORK"); n != "" {
+	networkName := clusterNetworkName(p.namePrefix)
+	if os.Getenv("KIND_EXPERIMENTAL_DOCKER_NETWORK") != "" {
 		p.logger.Warn("WARNING: Overriding docker network due to KIND_EXPERIMENTAL_DOCKER_NETWORK")
 		p.logger.Warn("WARNING: Here be dragons! This is not supported currently.")
-		networkName = n
 	}
-	if err := ensureNetwork(networkName); err != nil {
+	networkStart := time.Now()
+	if err := common.Retry(ctx, p.logger, "ensure network "+networkName, func() error {
+		return ensureNetwork(ctx, networkName)
+	}); err != nil {
 		return errors.Wrap(err, "failed to ensure docker network")
 	}
+	events.Emit(p.logger, events.Event{
+		Type:     events.StepCompleted,
+		Phase:    "Ensuring network",
+		Duration: time.Since(networkStart),
+	})
+
+	// Docker Desktop (including the WSL2 backend) and Colima run the daemon
+	// in a VM, container IPs are not routable from the host so kind relies
+	// solely on the published API server port, which we always map to loopback
+	if vm := detectVM(ctx); vm != nil {
+		p.logger.V(1).Infof("Detected %s, nodes will only be reachable via published ports", vm.Name)
+	}
+
+	// fail fast on host problems that would otherwise break creation midway
+	if err := common.Preflight(ctx, p.logger, cfg, preflightInfo(ctx, cfg, networkName)); err != nil {
+		return err
+	}
 
 	// actually provision the cluster
 	icons := strings.Repeat("📦 ", len(cfg.Nodes))
 	status.Start(fmt.Sprintf("Preparing nodes %s", icons))
 	defer func() { status.End(err == nil) }()
 
+	// the nodes' storage is placed on the cluster disk, so it comes first
+	if cfg.DiskQuota != "" {
+		if err := createDisk(ctx, p.logger, cfg); err != nil {
+			return err
+		}
+	}
+
 	// plan creating the containers
-	createContainerFuncs, err := planCreation(cfg, networkName)
+	createContainerFuncs, err := planCreation(ctx, p.logger, cfg, networkName, p.vmRuntime, "")
 	if err != nil {
 		return err
 	}
@@ -85,9 +151,54 @@ func (p *Provider) Provision(status *cli.Status, cfg *config.Cluster) (err error
 	return errors.UntilErrorConcurrent(createContainerFuncs)
 }
 
+// ProvisionNode is part of the providers.Provider interface
+func (p *Provider) ProvisionNode(ctx context.Context, status *cli.Status, cfg *config.Cluster, name string) (err error) {
+	if err := ensureNodeImages(ctx, p.logger, status, cfg); err != nil {
+		return err
+	}
+
+	status.Start(fmt.Sprintf("Preparing node %s 📦", name))
+	defer func() { status.End(err == nil) }()
+
+	// the cluster network already exists
+	createContainerFuncs, err := planCreation(ctx, p.logger, cfg, clusterNetworkName(p.namePrefix), p.vmRuntime, name)
+	if err != nil {
+		return err
+	}
+	if len(createContainerFuncs) == 0 {
+		return errors.Errorf("node %q is not in the config", name)
+	}
+	return errors.UntilErrorConcurrent(createContainerFuncs)
+}
+
+// Info is part of the providers.Provider interface
+func (p *Provider) Info(ctx context.Context) (*provider.Info, error) {
+	lines, err := exec.OutputLines(exec.CommandContext(ctx, "docker", "info", "-f", "{{json .SecurityOptions}}"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get docker info")
+	}
+	return &provider.Info{
+		// rootless docker lists name=rootless in its security options
+		Rootless: strings.Contains(strings.Join(lines, ""), "name=rootless"),
+	}, nil
+}
+
+// MissingImages is part of the providers.Provider interface
+func (p *Provider) MissingImages(ctx context.Context, cfg *config.Cluster) ([]string, error) {
+	return missingImages(ctx, cfg)
+}
+
+// ImageCommand is part of the providers.Provider interface
+func (p *Provider) ImageCommand(ctx context.Context, image, command string, args ...string) exec.Cmd {
+	_, image = sanitizeImage(image)
+	return exec.CommandContext(ctx, "docker",
+		append([]string{"run", "--rm", "--entrypoint", command, image}, args...)...,
+	)
+}
+
 // ListClusters is part of the providers.Provider interface
-func (p *Provider) ListClusters() ([]string, error) {
-	cmd := exec.Command("docker",
+func (p *Provider) ListClusters(ctx context.Context) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "docker",
 		"ps",
 		"-a", // show stopped nodes
 		// filter for nodes with the cluster label
@@ -97,14 +208,14 @@ func (p *Provider) ListClusters() ([]string, error) {
 	)
 	lines, err := exec.OutputLines(cmd)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to list clusters")
+		return nil, withUnavailableReason(errors.Wrap(err, "failed to list clusters"))
 	}
 	return sets.NewString(lines...).List(), nil
 }
 
 // ListNodes is part of the providers.Provider interface
-func (p *Provider) ListNodes(cluster string) ([]nodes.Node, error) {
-	cmd := exec.Command("docker",
+func (p *Provider) ListNodes(ctx context.Context, cluster string) ([]nodes.Node, error) {
+	cmd := exec.CommandContext(ctx, "docker",
 		"ps",
 		"-a", // show stopped nodes
 		// filter for nodes with the cluster label
@@ -114,41 +225,143 @@ func (p *Provider) ListNodes(cluster string) ([]nodes.Node, error) {
 	)
 	lines, err := exec.OutputLines(cmd)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to list clusters")
+		return nil, withUnavailableReason(errors.Wrap(err, "failed to list clusters"))
 	}
 	// convert names to node handles
 	ret := make([]nodes.Node, 0, len(lines))
 	for _, name := range lines {
-		ret = append(ret, p.node(name))
+		ret = append(ret, p.node(ctx, name))
 	}
 	return ret, nil
 }
 
 // DeleteNodes is part of the providers.Provider interface
-func (p *Provider) DeleteNodes(n []nodes.Node) error {
+func (p *Provider) DeleteNodes(ctx context.Context, n []nodes.Node) error {
 	if len(n) == 0 {
 		return nil
 	}
 	const command = "docker"
-	args := make([]string, 0, len(n)+3) // allocate once
-	args = append(args,
-		"rm",
-		"-f", // force the container to be delete now
-		"-v", // delete volumes
-	)
+	// the cluster disk can only be unmounted once the nodes using it are gone
+	n, disks, err := disk.SplitNodes(n)
+	if err != nil {
+		return err
+	}
+	if len(n) > 0 {
+		args := make([]string, 0, len(n)+3) // allocate once
+		args = append(args,
+			"rm",
+			"-f", // force the container to be delete now
+			"-v", // delete volumes
+		)
+		for _, node := range n {
+			args = append(args, node.String())
+		}
+		if err := exec.CommandContext(ctx, command, args...).Run(); err != nil {
+			return errors.Wrap(err, "failed to delete nodes")
+		}
+	}
+	for _, d := range disks {
+		// this fails if the disk container is stopped, which must not
+		// prevent deleting the cluster
+		if err := d.CommandContext(ctx, disk.CleanupCommand[0], disk.CleanupCommand[1:]...).Run(); err != nil {
+			p.logger.Warnf("failed to remove the cluster disk, unmount and remove it in %s on the host: %v", disk.HostPathFor(d.String()), err)
+		}
+		if err := exec.CommandContext(ctx, command, "rm", "-f", "-v", d.String()).Run(); err != nil {
+			return errors.Wrap(err, "failed to delete nodes")
+		}
+	}
+	return nil
+}
+
+// RestartNodes is part of the providers.Provider interface
+func (p *Provider) RestartNodes(ctx context.Context, n []nodes.Node) error {
+	if len(n) == 0 {
+		return nil
+	}
+	args := []string{"restart"}
 	for _, node := range n {
 		args = append(args, node.String())
 	}
-	if err := exec.Command(command, args...).Run(); err != nil {
-		return errors.Wrap(err, "failed to delete nodes")
+	if err := exec.CommandContext(ctx, "docker", args...).Run(); err != nil {
+		return errors.Wrap(err, "failed to restart nodes")
+	}
+	return nil
+}
+
+// CreateProxy is part of the providers.Provider interface
+func (p *Provider) CreateProxy(ctx context.Context, cfg *config.Cluster, name string, portMappings []config.PortMapping) error {
+	args, err := commonArgs(ctx, cfg.Name, cfg, clusterNetworkName(p.namePrefix), config.NodeNames(cfg))
+	if err != nil {
+		return err
+	}
+	args, err = runArgsForProxy(cfg, name, portMappings, args)
+	if err != nil {
+		return err
+	}
+	return createContainer(ctx, p.logger, name, args)
+}
+
+// CommitNode is part of the providers.Provider interface
+func (p *Provider) CommitNode(ctx context.Context, n nodes.Node, image, cluster string) error {
+	if err := exec.CommandContext(ctx, "docker",
+		"commit",
+		// the node's stable machine-id, if any, must not carry over
+		"--change", "ENV KIND_MACHINE_ID=",
+		"--change", fmt.Sprintf("LABEL %s=%s", cloneLabelKey, cluster),
+		n.String(), image,
+	).Run(); err != nil {
+		return errors.Wrapf(err, "failed to commit node %s", n.String())
+	}
+	return nil
+}
+
+// DeleteImages is part of the providers.Provider interface
+func (p *Provider) DeleteImages(ctx context.Context, cluster string) error {
+	cmd := exec.CommandContext(ctx, "docker",
+		"images",
+		"--filter", fmt.Sprintf("label=%s=%s", cloneLabelKey, cluster),
+		"--format", "{{.Repository}}:{{.Tag}}",
+	)
+	images, err := exec.OutputLines(cmd)
+	if err != nil {
+		return errors.Wrap(err, "failed to list committed node images")
+	}
+	if len(images) == 0 {
+		return nil
+	}
+	// remove by reference, images cloned from these keep the shared layers
+	if err := exec.CommandContext(ctx, "docker", append([]string{"rmi"}, images...)...).Run(); err != nil {
+		return errors.Wrap(err, "failed to delete committed node images")
 	}
 	return nil
 }
 
+// InspectNodes is part of the providers.Provider interface
+func (p *Provider) InspectNodes(ctx context.Context, n []nodes.Node) ([]byte, error) {
+	args := []string{"inspect", "--type=container"}
+	for _, node := range n {
+		args = append(args, node.String())
+	}
+	out, err := exec.Output(exec.CommandContext(ctx, "docker", args...))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to inspect nodes")
+	}
+	return out, nil
+}
+
+// NetworkInfo is part of the providers.Provider interface
+func (p *Provider) NetworkInfo(ctx context.Context, cluster string) (*provider.NetworkInfo, error) {
+	out, err := exec.Output(exec.CommandContext(ctx, "docker", "network", "inspect", clusterNetworkName(p.namePrefix)))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to inspect the cluster network")
+	}
+	return common.ParseNetworkInspect(out)
+}
+
 // GetAPIServerEndpoint is part of the providers.Provider interface
-func (p *Provider) GetAPIServerEndpoint(cluster string) (string, error) {
+func (p *Provider) GetAPIServerEndpoint(ctx context.Context, cluster string) (string, error) {
 	// locate the node that hosts this
-	allNodes, err := p.ListNodes(cluster)
+	allNodes, err := p.ListNodes(ctx, cluster)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to list nodes")
 	}
@@ -158,7 +371,8 @@ func (p *Provider) GetAPIServerEndpoint(cluster string) (string, error) {
 	}
 
 	// retrieve the specific port mapping using docker inspect
-	cmd := exec.Command(
+	cmd := exec.CommandContext(
+		ctx,
 		"docker", "inspect",
 		"--format", fmt.Sprintf(
 			"{{ with (index (index .NetworkSettings.Ports \"%d/tcp\") 0) }}{{ printf \"%%s\t%%s\" .HostIp .HostPort }}{{ end }}", common.APIServerInternalPort,
@@ -177,14 +391,14 @@ func (p *Provider) GetAPIServerEndpoint(cluster string) (string, error) {
 		return "", errors.Errorf("network details should only be two parts, got %d", len(parts))
 	}
 
-	// join host and port
-	return net.JoinHostPort(parts[0], parts[1]), nil
+	// join host and port, making sure the host is something we can dial
+	return net.JoinHostPort(reachableHostIP(parts[0]), parts[1]), nil
 }
 
 // GetAPIServerInternalEndpoint is part of the providers.Provider interface
-func (p *Provider) GetAPIServerInternalEndpoint(cluster string) (string, error) {
+func (p *Provider) GetAPIServerInternalEndpoint(ctx context.Context, cluster string) (string, error) {
 	// locate the node that hosts this
-	allNodes, err := p.ListNodes(cluster)
+	allNodes, err := p.ListNodes(ctx, cluster)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to list nodes")
 	}
@@ -197,14 +411,15 @@ func (p *Provider) GetAPIServerInternalEndpoint(cluster string) (string, error)
 }
 
 // node returns a new node handle for this provider
-func (p *Provider) node(name string) nodes.Node {
+func (p *Provider) node(ctx context.Context, name string) nodes.Node {
 	return &node{
 		name: name,
+		ctx:  ctx,
 	}
 }
 
 // CollectLogs will populate dir with cluster logs and other debug files
-func (p *Provider) CollectLogs(dir string, nodes []nodes.Node) error {
+func (p *Provider) CollectLogs(ctx context.Context, dir string, nodes []nodes.Node, opts *internallogs.Options) error {
 	execToPathFn := func(cmd exec.Cmd, path string) func() error {
 		return func() error {
 			f, err := common.FileOnHost(path)
@@ -220,10 +435,17 @@ func (p *Provider) CollectLogs(dir string, nodes []nodes.Node) error {
 		// TODO(bentheelder): record the kind version here as well
 		// record info about the host docker
 		execToPathFn(
-			exec.Command("docker", "info"),
+			exec.CommandContext(ctx, "docker", "info"),
 			filepath.Join(dir, "docker-info.txt"),
 		),
 	}
+	if opts.Has(internallogs.ComponentNetwork) {
+		// record the docker network the nodes are attached to
+		fns = append(fns, execToPathFn(
+			exec.CommandContext(ctx, "docker", "network", "inspect", clusterNetworkName(p.namePrefix)),
+			filepath.Join(dir, "docker-network.json"),
+		))
+	}
 
 	// collect /var/log for each node and plan collecting more logs
 	var errs []error
@@ -231,22 +453,24 @@ func (p *Provider) CollectLogs(dir string, nodes []nodes.Node) error {
 		node := n // https://golang.org/doc/faq#closures_and_goroutines
 		name := node.String()
 		path := filepath.Join(dir, name)
-		if err := internallogs.DumpDir(p.logger, node, "/var/log", path); err != nil {
+		if err := internallogs.DumpLogDirs(p.logger, node, opts, path); err != nil {
 			errs = append(errs, err)
 		}
 
-		fns = append(fns,
-			func() error { return common.CollectLogs(node, path) },
-			execToPathFn(exec.Command("docker", "inspect", name), filepath.Join(path, "inspect.json")),
-			func() error {
-				f, err := common.FileOnHost(filepath.Join(path, "serial.log"))
-				if err != nil {
-					return err
-				}
-				defer f.Close()
-				return node.SerialLogs(f)
-			},
-		)
+		fns = append(fns, func() error { return common.CollectLogs(node, path, opts) })
+		if opts.Has(internallogs.ComponentNode) {
+			fns = append(fns,
+				execToPathFn(exec.CommandContext(ctx, "docker", "inspect", name), filepath.Join(path, "inspect.json")),
+				func() error {
+					f, err := common.FileOnHost(filepath.Join(path, "serial.log"))
+					if err != nil {
+						return err
+					}
+					defer f.Close()
+					return node.SerialLogs(f)
+				},
+			)
+		}
 	}
 
 	// run and collect up all errors
@@ -17,74 +17,112 @@ limitations under the License.
 package docker
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"time"
 
 	"sigs.k8s.io/kind/pkg/cluster/constants"
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/exec"
 	"sigs.k8s.io/kind/pkg/fs"
+	"sigs.k8s.io/kind/pkg/log"
 
-	"sigs.k8s.io/kind/pkg/cluster/internal/loadbalancer"
+	"sigs.k8s.io/kind/pkg/cluster/internal/disk"
+	"sigs.k8s.io/kind/pkg/cluster/internal/oidc"
 	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
 	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/events"
 )
 
+// vmRunArgs returns args with the docker OCI runtime set to vmRuntime, if
+// any, so that nodes run as VMs with their own kernel. args is not modified
+func vmRunArgs(args []string, vmRuntime string) []string {
+	if vmRuntime == "" {
+		return args
+	}
+	return append(append([]string{}, args...), "--runtime", vmRuntime)
+}
+
 // planCreation creates a slice of funcs that will create the containers
-func planCreation(cfg *config.Cluster, networkName string) (createContainerFuncs []func() error, err error) {
+// if vmRuntime is set, kubernetes node containers will be run with it
+// if only is set, just the node with that name is planned
+func planCreation(ctx context.Context, logger log.Logger, cfg *config.Cluster, networkName, vmRuntime, only string) (createContainerFuncs []func() error, err error) {
 	// we need to know all the names for NO_PROXY
 	// compute the names first before any actual node details
-	nodeNamer := common.MakeNodeNamer(cfg.Name)
-	names := make([]string, len(cfg.Nodes))
-	for i, node := range cfg.Nodes {
-		name := nodeNamer(string(node.Role)) // name the node
-		names[i] = name
-	}
+	names := config.NodeNames(cfg)
 	haveLoadbalancer := clusterHasImplicitLoadBalancer(cfg)
 	if haveLoadbalancer {
-		names = append(names, nodeNamer(constants.ExternalLoadBalancerNodeRoleValue))
+		names = append(names, common.MakeNodeNamer(cfg.Name)(constants.ExternalLoadBalancerNodeRoleValue))
+	}
+	if cfg.Authentication.OIDC.TestIssuer {
+		names = append(names, oidc.IssuerName(cfg.Name))
 	}
 
 	// these apply to all container creation
-	genericArgs, err := commonArgs(cfg.Name, cfg, networkName, names)
+	genericArgs, err := commonArgs(ctx, cfg.Name, cfg, networkName, names)
 	if err != nil {
 		return nil, err
 	}
 
 	// only the external LB should reflect the port if we have multiple control planes
 	apiServerPort := cfg.Networking.APIServerPort
-	apiServerAddress := cfg.Networking.APIServerAddress
+	apiServerAddresses := append([]string{cfg.Networking.APIServerAddress}, cfg.Networking.AdditionalAPIServerAddresses...)
 	if haveLoadbalancer {
 		// TODO: picking ports locally is less than ideal with remote docker
 		// but this is supposed to be an implementation detail and NOT picking
 		// them breaks host reboot ...
 		// For now remote docker + multi control plane is not supported
-		apiServerPort = 0              // replaced with random ports
-		apiServerAddress = "127.0.0.1" // only the LB needs to be non-local
+		apiServerPort = 0                          // replaced with random ports
+		apiServerAddresses = []string{"127.0.0.1"} // only the LB needs to be non-local
 		if clusterIsIPv6(cfg) {
-			apiServerAddress = "::1" // only the LB needs to be non-local
+			apiServerAddresses = []string{"::1"} // only the LB needs to be non-local
 		}
+	}
+	if haveLoadbalancer && only == "" {
 		// plan loadbalancer node
-		name := names[len(names)-1]
+		name := common.MakeNodeNamer(cfg.Name)(constants.ExternalLoadBalancerNodeRoleValue)
 		createContainerFuncs = append(createContainerFuncs, func() error {
 			args, err := runArgsForLoadBalancer(cfg, name, genericArgs)
 			if err != nil {
 				return err
 			}
-			return createContainer(args)
+			return createContainer(ctx, logger, name, args)
+		})
+	}
+
+	// plan the test OIDC issuer, which is configured later
+	if cfg.Authentication.OIDC.TestIssuer && only == "" {
+		name := oidc.IssuerName(cfg.Name)
+		createContainerFuncs = append(createContainerFuncs, func() error {
+			return createContainer(ctx, logger, name, runArgsForOIDCIssuer(cfg, name, genericArgs))
 		})
 	}
 
+	// windows style mount paths need translating when running under WSL
+	wsl := isWSL()
+
+	// only kubernetes nodes run as VMs, the load balancer is just haproxy
+	nodeArgs := vmRunArgs(genericArgs, vmRuntime)
+
 	// plan normal nodes
 	for i, node := range cfg.Nodes {
-		node := node.DeepCopy() // copy so we can modify
 		name := names[i]
+		if only != "" && name != only {
+			continue
+		}
+		node := node.DeepCopy() // copy so we can modify
+
+		// the static pods directory is mounted like any other extra mount
+		node.ExtraMounts = append(node.ExtraMounts, common.StaticPodsMounts(node)...)
 
 		// fixup relative paths, docker can only handle absolute paths
 		for m := range node.ExtraMounts {
-			hostPath := node.ExtraMounts[m].HostPath
+			hostPath := translateHostPath(node.ExtraMounts[m].HostPath, runtime.GOOS, wsl)
+			node.ExtraMounts[m].HostPath = hostPath
 			if !fs.IsAbs(hostPath) {
 				absHostPath, err := filepath.Abs(hostPath)
 				if err != nil {
@@ -98,26 +136,34 @@ func planCreation(cfg *config.Cluster, networkName string) (createContainerFuncs
 		switch node.Role {
 		case config.ControlPlaneRole:
 			createContainerFuncs = append(createContainerFuncs, func() error {
-				node.ExtraPortMappings = append(node.ExtraPortMappings,
-					config.PortMapping{
-						ListenAddress: apiServerAddress,
-						HostPort:      apiServerPort,
-						ContainerPort: common.APIServerInternalPort,
-					},
-				)
-				args, err := runArgsForNode(node, cfg.Networking.IPFamily, name, genericArgs)
+				for _, address := range apiServerAddresses {
+					node.ExtraPortMappings = append(node.ExtraPortMappings,
+						config.PortMapping{
+							ListenAddress: address,
+							HostPort:      apiServerPort,
+							ContainerPort: common.APIServerInternalPort,
+						},
+					)
+				}
+				etcdArgs, err := common.EtcdDataArgs(cfg, node, name, func(volume string) (bool, error) {
+					return common.VolumeExists(ctx, "docker", volume)
+				})
+				if err != nil {
+					return err
+				}
+				args, err := runArgsForNode(node, cfg.Networking.IPFamily, name, append(nodeStorageArgs(cfg, name), etcdArgs...), nodeArgs)
 				if err != nil {
 					return err
 				}
-				return createContainer(args)
+				return createContainer(ctx, logger, name, args)
 			})
 		case config.WorkerRole:
 			createContainerFuncs = append(createContainerFuncs, func() error {
-				args, err := runArgsForNode(node, cfg.Networking.IPFamily, name, genericArgs)
+				args, err := runArgsForNode(node, cfg.Networking.IPFamily, name, nodeStorageArgs(cfg, name), nodeArgs)
 				if err != nil {
 					return err
 				}
-				return createContainer(args)
+				return createContainer(ctx, logger, name, args)
 			})
 		default:
 			return nil, errors.Errorf("unknown node role: %q", node.Role)
@@ -126,10 +172,12 @@ func planCreation(cfg *config.Cluster, networkName string) (createContainerFuncs
 	return createContainerFuncs, nil
 }
 
-func createContainer(args []string) error {
-	if err := exec.Command("docker", args...).Run(); err != nil {
-		return errors.Wrap(err, "docker run error")
+func createContainer(ctx context.Context, logger log.Logger, name string, args []string) error {
+	start := time.Now()
+	if err := common.CreateContainer(ctx, logger, "docker", name, args); err != nil {
+		return err
 	}
+	events.Emit(logger, events.Event{Type: events.NodeCreated, Node: name, Duration: time.Since(start)})
 	return nil
 }
 
@@ -149,7 +197,7 @@ func clusterHasImplicitLoadBalancer(cfg *config.Cluster) bool {
 }
 
 // commonArgs computes static arguments that apply to all containers
-func commonArgs(cluster string, cfg *config.Cluster, networkName string, nodeNames []string) ([]string, error) {
+func commonArgs(ctx context.Context, cluster string, cfg *config.Cluster, networkName string, nodeNames []string) ([]string, error) {
 	// standard arguments all nodes containers need, computed once
 	args := []string{
 		"--detach", // run the container detached
@@ -165,7 +213,7 @@ func commonArgs(cluster string, cfg *config.Cluster, networkName string, nodeNam
 		// - always
 		// https://docs.docker.com/engine/reference/commandline/run/#restart-policies---restart
 		//
-		// What we desire is:
+		// What we desire by default is:
 		// - restart on host / dockerd reboot
 		// - don't restart for any other reason
 		//
@@ -182,7 +230,9 @@ func commonArgs(cluster string, cfg *config.Cluster, networkName string, nodeNam
 		// retries is 0, so only restart on reboots.
 		// however this _actually_ means the same thing as always
 		// so the closest thing is on-failure:1, which will retry *once*
-		"--restart=on-failure:1",
+		//
+		// users may select another policy with the restartPolicy config field
+		common.RestartPolicyArg(cfg),
 	}
 
 	// enable IPv6 if necessary
@@ -190,8 +240,13 @@ func commonArgs(cluster string, cfg *config.Cluster, networkName string, nodeNam
 		args = append(args, "--sysctl=net.ipv6.conf.all.disable_ipv6=0", "--sysctl=net.ipv6.conf.all.forwarding=1")
 	}
 
+	// the fuse based containerd snapshotters need the fuse device
+	if common.NeedsFuse(cfg) {
+		args = append(args, "--device", "/dev/fuse")
+	}
+
 	// pass proxy environment variables
-	proxyEnv, err := getProxyEnv(cfg, networkName, nodeNames)
+	proxyEnv, err := getProxyEnv(ctx, cfg, networkName, nodeNames)
 	if err != nil {
 		return nil, errors.Wrap(err, "proxy setup error")
 	}
@@ -200,23 +255,40 @@ func commonArgs(cluster string, cfg *config.Cluster, networkName string, nodeNam
 	}
 
 	// handle hosts that have user namespace remapping enabled
-	if usernsRemap() {
+	if usernsRemap(ctx) {
 		args = append(args, "--userns=host")
 	}
 
 	// handle Docker on Btrfs or ZFS
 	// https://github.com/kubernetes-sigs/kind/issues/1416#issuecomment-606514724
-	if mountDevMapper() {
+	if mountDevMapper(ctx) {
 		args = append(args, "--volume", "/dev/mapper:/dev/mapper")
 	}
 
 	return args, nil
 }
 
-func runArgsForNode(node *config.Node, clusterIPFamily config.ClusterIPFamily, name string, args []string) ([]string, error) {
+// nodeStorageArgs returns the args for the node's persistent storage
+func nodeStorageArgs(cfg *config.Cluster, name string) []string {
+	// with a diskQuota the storage is on the size limited cluster disk
+	if cfg.DiskQuota != "" {
+		return disk.NodeMountArgs(cfg.Name, name)
+	}
+	// this ensures that E.G. pods, logs etc. are not on the container
+	// filesystem, which is not only better for performance, but allows
+	// running kind in kind for "party tricks"
+	// (please don't depend on doing this though!)
+	return []string{
+		"--volume", "/var/lib/containerd",
+		"--volume", "/var/lib/kubelet",
+		"--volume", "/var/log",
+	}
+}
+
+func runArgsForNode(node *config.Node, clusterIPFamily config.ClusterIPFamily, name string, storageArgs, args []string) ([]string, error) {
 	args = append([]string{
 		"run",
-		"--hostname", name, // make hostname match container name
+		"--hostname", common.NodeHostname(node, name), // hostname defaults to the container name
 		"--name", name, // ... and set the container name
 		// label the node with the role ID
 		"--label", fmt.Sprintf("%s=%s", nodeRoleLabelKey, node.Role),
@@ -231,22 +303,34 @@ func runArgsForNode(node *config.Node, clusterIPFamily config.ClusterIPFamily, n
 		// runtime temporary storage
 		"--tmpfs", "/tmp", // various things depend on working /tmp
 		"--tmpfs", "/run", // systemd wants a writable /run
-		// runtime persistent storage
-		// this ensures that E.G. pods, logs etc. are not on the container
-		// filesystem, which is not only better for performance, but allows
-		// running kind in kind for "party tricks"
-		// (please don't depend on doing this though!)
-		"--volume", "/var/lib/containerd",
-		"--volume", "/var/lib/kubelet",
-		"--volume", "/var/log",
 		// some k8s things want to read /lib/modules
 		"--volume", "/lib/modules:/lib/modules:ro",
 	},
 		args...,
 	)
 
+	// runtime persistent storage
+	args = append(args, storageArgs...)
+
 	// convert mounts and port mappings to container run args
 	args = append(args, generateMountBindings(node.ExtraMounts...)...)
+	args = append(args, common.NodeResourceArgs(node)...)
+	args = append(args, common.NodeIdentityArgs(node)...)
+	// make a custom hostname resolvable on the cluster network like the name
+	if node.Hostname != "" {
+		args = append(args, "--network-alias", node.Hostname)
+	}
+
+	// request GPUs and expose the host's NVIDIA toolkit to containerd
+	if node.GPUs != "" {
+		gpus := node.GPUs
+		// docker parses --gpus as CSV, so device lists need quoting
+		if strings.Contains(gpus, ",") {
+			gpus = `"` + gpus + `"`
+		}
+		args = append(args, "--gpus", gpus)
+		args = append(args, generateMountBindings(common.NVIDIAToolkitMounts()...)...)
+	}
 	mappingArgs, err := generatePortMappings(clusterIPFamily, node.ExtraPortMappings...)
 	if err != nil {
 		return nil, err
@@ -268,28 +352,119 @@ func runArgsForLoadBalancer(cfg *config.Cluster, name string, args []string) ([]
 		args...,
 	)
 
-	// load balancer port mapping
-	mappingArgs, err := generatePortMappings(cfg.Networking.IPFamily,
-		config.PortMapping{
-			ListenAddress: cfg.Networking.APIServerAddress,
+	// load balancer port mapping, plus any extra frontends published on the host
+	portMappings := []config.PortMapping{{
+		ListenAddress: cfg.Networking.APIServerAddress,
+		HostPort:      cfg.Networking.APIServerPort,
+		ContainerPort: common.APIServerInternalPort,
+	}}
+	for _, address := range cfg.Networking.AdditionalAPIServerAddresses {
+		portMappings = append(portMappings, config.PortMapping{
+			ListenAddress: address,
 			HostPort:      cfg.Networking.APIServerPort,
 			ContainerPort: common.APIServerInternalPort,
-		},
+		})
+	}
+	for _, f := range cfg.LoadBalancer.ExtraFrontends {
+		if f.HostPort == 0 {
+			continue
+		}
+		portMappings = append(portMappings, config.PortMapping{
+			ListenAddress: cfg.Networking.APIServerAddress,
+			HostPort:      f.HostPort,
+			ContainerPort: f.Port,
+		})
+	}
+	mappingArgs, err := generatePortMappings(cfg.Networking.IPFamily, portMappings...)
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, mappingArgs...)
+
+	// finally, specify the image to run
+	return append(args, common.LoadBalancerImage(cfg)), nil
+}
+
+// runArgsForProxy returns the args to run the `kind expose` proxy, it is
+// configured like the load balancer once created
+func runArgsForProxy(cfg *config.Cluster, name string, portMappings []config.PortMapping, args []string) ([]string, error) {
+	args = append([]string{
+		"run",
+		"--hostname", name, // make hostname match container name
+		"--name", name, // ... and set the container name
+		// label the node with the role ID
+		"--label", fmt.Sprintf("%s=%s", nodeRoleLabelKey, constants.ExposeProxyNodeRoleValue),
+	},
+		args...,
 	)
+	mappingArgs, err := generatePortMappings(cfg.Networking.IPFamily, portMappings...)
 	if err != nil {
 		return nil, err
 	}
 	args = append(args, mappingArgs...)
 
 	// finally, specify the image to run
-	return append(args, loadbalancer.Image), nil
+	return append(args, common.LoadBalancerImage(cfg)), nil
+}
+
+// createDisk creates the disk container for a cluster with a diskQuota, and
+// waits for it to mount the cluster disk the nodes' storage is placed on
+func createDisk(ctx context.Context, logger log.Logger, cfg *config.Cluster) error {
+	size, err := config.DiskQuotaBytes(cfg.DiskQuota)
+	if err != nil {
+		return err
+	}
+	image := cfg.Nodes[0].Image
+	prepareArgs := append([]string{"run"}, disk.PrepareArgs(cfg.Name, image)...)
+	if err := exec.CommandContext(ctx, "docker", prepareArgs...).Run(); err != nil {
+		return errors.Wrap(err, "failed to create the cluster disk directory")
+	}
+	name := disk.Name(cfg.Name)
+	command := disk.Command(size, config.NodeNames(cfg)[:len(cfg.Nodes)])
+	args := []string{
+		"run",
+		"--detach",
+		"--hostname", name, // make hostname match container name
+		"--name", name, // ... and set the container name
+		// label the node with the cluster and role IDs
+		"--label", fmt.Sprintf("%s=%s", clusterLabelKey, cfg.Name),
+		"--label", fmt.Sprintf("%s=%s", nodeRoleLabelKey, constants.DiskNodeRoleValue),
+		common.RestartPolicyArg(cfg),
+		"--net", "none",
+		// mounting loop devices requires privileged
+		"--privileged",
+		// the disk is mounted with shared propagation so the nodes see it
+		"--volume", fmt.Sprintf("%s:%s:rshared", disk.HostPath(cfg.Name), disk.ContainerPath),
+		"--entrypoint", command[0],
+		image,
+	}
+	if err := createContainer(ctx, logger, name, append(args, command[1:]...)); err != nil {
+		return err
+	}
+	return disk.WaitReady(ctx, "docker", name)
+}
+
+// runArgsForOIDCIssuer returns the args to run the test OIDC issuer, it
+// waits for its config to be written by cluster creation
+func runArgsForOIDCIssuer(cfg *config.Cluster, name string, args []string) []string {
+	args = append([]string{
+		"run",
+		"--hostname", name, // make hostname match container name
+		"--name", name, // ... and set the container name
+		// label the node with the role ID
+		"--label", fmt.Sprintf("%s=%s", nodeRoleLabelKey, constants.OIDCIssuerNodeRoleValue),
+		"--entrypoint", oidc.Command[0],
+	},
+		args...,
+	)
+	return append(append(args, common.OverrideImage(cfg, oidc.Image)), oidc.Command[1:]...)
 }
 
-func getProxyEnv(cfg *config.Cluster, networkName string, nodeNames []string) (map[string]string, error) {
+func getProxyEnv(ctx context.Context, cfg *config.Cluster, networkName string, nodeNames []string) (map[string]string, error) {
 	envs := common.GetProxyEnvs(cfg)
 	// Specifically add the docker network subnets to NO_PROXY if we are using a proxy
 	if len(envs) > 0 {
-		subnets, err := getSubnets(networkName)
+		subnets, err := getSubnets(ctx, networkName)
 		if err != nil {
 			return nil, err
 		}
@@ -309,9 +484,9 @@ func getProxyEnv(cfg *config.Cluster, networkName string, nodeNames []string) (m
 	return envs, nil
 }
 
-func getSubnets(networkName string) ([]string, error) {
+func getSubnets(ctx context.Context, networkName string) ([]string, error) {
 	format := `{{range (index (index . "IPAM") "Config")}}{{index . "Subnet"}} {{end}}`
-	cmd := exec.Command("docker", "network", "inspect", "-f", format, networkName)
+	cmd := exec.CommandContext(ctx, "docker", "network", "inspect", "-f", format, networkName)
 	lines, err := exec.CombinedOutputLines(cmd)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get subnets")
@@ -323,7 +498,7 @@ func getSubnets(networkName string) ([]string, error) {
 // '<HostPath>:<ContainerPath>[:options]', where 'options'
 // is a comma-separated list of the following strings:
 // 'ro', if the path is read only
-// 'Z', if the volume requires SELinux relabeling
+// 'z' or 'Z', if the volume requires shared or private SELinux relabeling
 func generateMountBindings(mounts ...config.Mount) []string {
 	args := make([]string, 0, len(mounts))
 	for _, m := range mounts {
@@ -336,8 +511,12 @@ func generateMountBindings(mounts ...config.Mount) []string {
 		// does not provide an SELinux context relabeling will label the volume with
 		// the container's randomly allocated MCS label. This would restrict access
 		// to the volume to the container which mounts it first.
-		if m.SelinuxRelabel {
+		switch m.SelinuxRelabel {
+		case config.MountSelinuxRelabelShared:
+			attrs = append(attrs, "z")
+		case config.MountSelinuxRelabelPrivate:
 			attrs = append(attrs, "Z")
+		default: // no relabeling
 		}
 		switch m.Propagation {
 		case config.MountPropagationNone:
@@ -0,0 +1,43 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestVMRunArgs(t *testing.T) {
+	t.Parallel()
+	args := []string{"--detach", "--privileged"}
+	assert.DeepEqual(t, args, vmRunArgs(args, ""))
+	assert.DeepEqual(t,
+		[]string{"--detach", "--privileged", "--runtime", "kata-runtime"},
+		vmRunArgs(args, "kata-runtime"),
+	)
+	// the generic args are shared with the load balancer, which is not a VM
+	assert.DeepEqual(t, []string{"--detach", "--privileged"}, args)
+}
+
+func TestHasLine(t *testing.T) {
+	t.Parallel()
+	lines := []string{"io.containerd.runc.v2", " kata-runtime ", "runc"}
+	assert.BoolEqual(t, true, hasLine(lines, "kata-runtime"))
+	assert.BoolEqual(t, false, hasLine(lines, "kata"))
+	assert.BoolEqual(t, false, hasLine(nil, "runc"))
+}
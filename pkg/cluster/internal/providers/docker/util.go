@@ -17,9 +17,18 @@ limitations under the License.
 package docker
 
 import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"regexp"
+	"runtime"
 	"strings"
 
+	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
 )
 
 // IsAvailable checks if docker is available in the system
@@ -32,9 +41,35 @@ func IsAvailable() bool {
 	return strings.HasPrefix(lines[0], "Docker version")
 }
 
+// withUnavailableReason marks err with errors.ErrProviderUnavailable
+// if docker is not available
+func withUnavailableReason(err error) error {
+	if err != nil && !IsAvailable() {
+		return errors.WithReason(err, errors.ErrProviderUnavailable)
+	}
+	return err
+}
+
+// hasRuntime checks if dockerd has the named OCI runtime configured
+func hasRuntime(ctx context.Context, name string) bool {
+	cmd := exec.CommandContext(ctx, "docker", "info", "--format", `{{range $name, $_ := .Runtimes}}{{$name}}{{"\n"}}{{end}}`)
+	lines, err := exec.OutputLines(cmd)
+	return err == nil && hasLine(lines, name)
+}
+
+// hasLine returns whether one of lines is name, ignoring surrounding space
+func hasLine(lines []string, name string) bool {
+	for _, line := range lines {
+		if strings.TrimSpace(line) == name {
+			return true
+		}
+	}
+	return false
+}
+
 // usernsRemap checks if userns-remap is enabled in dockerd
-func usernsRemap() bool {
-	cmd := exec.Command("docker", "info", "--format", "'{{json .SecurityOptions}}'")
+func usernsRemap(ctx context.Context) bool {
+	cmd := exec.CommandContext(ctx, "docker", "info", "--format", "'{{json .SecurityOptions}}'")
 	lines, err := exec.CombinedOutputLines(cmd)
 	if err != nil {
 		return false
@@ -48,9 +83,9 @@ func usernsRemap() bool {
 }
 
 // mountDevMapper checks if the Docker storage driver is Btrfs or ZFS
-func mountDevMapper() bool {
+func mountDevMapper(ctx context.Context) bool {
 	storage := ""
-	cmd := exec.Command("docker", "info", "-f", "{{.Driver}}")
+	cmd := exec.CommandContext(ctx, "docker", "info", "-f", "{{.Driver}}")
 	lines, err := exec.CombinedOutputLines(cmd)
 	if err != nil {
 		return false
@@ -60,3 +95,109 @@ func mountDevMapper() bool {
 	}
 	return storage == "btrfs" || storage == "zfs"
 }
+
+// isDockerDesktop checks if the docker daemon is provided by Docker Desktop,
+// in which case it runs inside a VM (Hyper-V or WSL2) rather than on the host
+func isDockerDesktop(ctx context.Context) bool {
+	cmd := exec.CommandContext(ctx, "docker", "info", "-f", "{{.OperatingSystem}}")
+	lines, err := exec.OutputLines(cmd)
+	if err != nil || len(lines) != 1 {
+		return false
+	}
+	return strings.Contains(lines[0], "Docker Desktop")
+}
+
+// detectVM returns the VM the docker daemon runs in if it is Docker Desktop
+// or Colima, or nil
+func detectVM(ctx context.Context) *common.VM {
+	home, _ := os.UserHomeDir()
+	if isDockerDesktop(ctx) {
+		return dockerDesktopVM(runtime.GOOS, isWSL(), home)
+	}
+	// the colima VM hostname is the profile, "colima" or "colima-<profile>"
+	if strings.HasPrefix(dockerInfo(ctx, "{{.Name}}"), "colima") {
+		return colimaVM(home)
+	}
+	return nil
+}
+
+// dockerDesktopVM returns the Docker Desktop VM with the default file
+// sharing for goos, on windows and under WSL every path is shared
+func dockerDesktopVM(goos string, wsl bool, home string) *common.VM {
+	vm := &common.VM{
+		Name:      "Docker Desktop",
+		ShareHint: "add the directory in Docker Desktop's Settings > Resources > File sharing",
+	}
+	switch {
+	case goos == "windows" || wsl:
+	case goos == "darwin":
+		vm.SharedPaths = []string{"/Users", "/Volumes", "/private", "/tmp", "/var/folders"}
+	default:
+		vm.SharedPaths = []string{home}
+	}
+	return vm
+}
+
+// colimaVM returns the Colima VM, which shares the home directory and
+// /tmp/colima by default
+func colimaVM(home string) *common.VM {
+	return &common.VM{
+		Name:              "Colima",
+		SharedPaths:       []string{home, "/tmp/colima"},
+		ShareHint:         "restart colima with the directory mounted, e.g. colima start --mount DIR:w",
+		LoopbackPortsOnly: true,
+	}
+}
+
+// isWSL checks if kind itself is running inside a WSL distro
+func isWSL() bool {
+	if runtime.GOOS != "linux" {
+		return false
+	}
+	release, err := ioutil.ReadFile("/proc/sys/kernel/osrelease")
+	if err != nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(string(release)), "microsoft")
+}
+
+// windowsPathRE matches windows drive paths like C:\foo or C:/foo
+var windowsPathRE = regexp.MustCompile(`^([a-zA-Z]):[\\/]`)
+
+// translateHostPath converts windows style host paths into a form that
+// docker will accept as a bind mount source on the current host.
+//
+// On windows the path is normalized to forward slashes (C:/foo/bar), and
+// under WSL the path is translated to the drive mount (/mnt/c/foo/bar).
+// Other paths are returned unmodified.
+func translateHostPath(hostPath string, goos string, wsl bool) string {
+	m := windowsPathRE.FindStringSubmatch(hostPath)
+	if m == nil {
+		return hostPath
+	}
+	rest := strings.Replace(hostPath[len(m[0]):], "\\", "/", -1)
+	switch {
+	case goos == "windows":
+		return m[1] + ":/" + rest
+	case wsl:
+		return "/mnt/" + strings.ToLower(m[1]) + "/" + rest
+	}
+	return hostPath
+}
+
+// reachableHostIP maps the unspecified address a port is published on
+// to the matching loopback address, windows hosts in particular cannot
+// connect to 0.0.0.0
+func reachableHostIP(hostIP string) string {
+	ip := net.ParseIP(hostIP)
+	if hostIP != "" && ip == nil {
+		return hostIP
+	}
+	if ip == nil || ip.IsUnspecified() {
+		if ip != nil && ip.To4() == nil {
+			return "::1"
+		}
+		return "127.0.0.1"
+	}
+	return hostIP
+}
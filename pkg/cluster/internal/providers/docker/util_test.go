@@ -0,0 +1,113 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"testing"
+)
+
+func Test_translateHostPath(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name     string
+		hostPath string
+		goos     string
+		wsl      bool
+		expected string
+	}{
+		{
+			name:     "linux path is untouched",
+			hostPath: "/foo/bar",
+			goos:     "linux",
+			expected: "/foo/bar",
+		},
+		{
+			name:     "windows path on windows",
+			hostPath: `C:\Users\kind\data`,
+			goos:     "windows",
+			expected: "C:/Users/kind/data",
+		},
+		{
+			name:     "windows path under WSL",
+			hostPath: `D:\src\kind`,
+			goos:     "linux",
+			wsl:      true,
+			expected: "/mnt/d/src/kind",
+		},
+		{
+			name:     "forward slash windows path under WSL",
+			hostPath: "C:/src",
+			goos:     "linux",
+			wsl:      true,
+			expected: "/mnt/c/src",
+		},
+		{
+			name:     "windows path on plain linux",
+			hostPath: `C:\src`,
+			goos:     "linux",
+			expected: `C:\src`,
+		},
+		{
+			name:     "relative path",
+			hostPath: "./data",
+			goos:     "windows",
+			expected: "./data",
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture variable
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if result := translateHostPath(tc.hostPath, tc.goos, tc.wsl); result != tc.expected {
+				t.Errorf("expected %q but got %q", tc.expected, result)
+			}
+		})
+	}
+}
+
+func Test_reachableHostIP(t *testing.T) {
+	t.Parallel()
+	cases := map[string]string{
+		"":          "127.0.0.1",
+		"0.0.0.0":   "127.0.0.1",
+		"::":        "::1",
+		"127.0.0.1": "127.0.0.1",
+		"10.0.0.2":  "10.0.0.2",
+		"localhost": "localhost",
+	}
+	for hostIP, expected := range cases {
+		if result := reachableHostIP(hostIP); result != expected {
+			t.Errorf("reachableHostIP(%q): expected %q but got %q", hostIP, expected, result)
+		}
+	}
+}
+
+func Test_dockerDesktopVM(t *testing.T) {
+	t.Parallel()
+	if vm := dockerDesktopVM("windows", false, `C:\Users\kind`); len(vm.SharedPaths) != 0 {
+		t.Errorf("expected every path to be shared on windows, got %v", vm.SharedPaths)
+	}
+	if vm := dockerDesktopVM("linux", true, "/home/kind"); len(vm.SharedPaths) != 0 {
+		t.Errorf("expected every path to be shared under WSL, got %v", vm.SharedPaths)
+	}
+	if vm := dockerDesktopVM("darwin", false, "/Users/kind"); !vm.IsShared("/Users/kind/src") || vm.IsShared("/opt/data") {
+		t.Errorf("expected only the default file sharing on darwin, got %v", vm.SharedPaths)
+	}
+	if vm := dockerDesktopVM("linux", false, "/home/kind"); !vm.IsShared("/home/kind/src") || vm.IsShared("/opt/data") {
+		t.Errorf("expected only the home directory to be shared on linux, got %v", vm.SharedPaths)
+	}
+}
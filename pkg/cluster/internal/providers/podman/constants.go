@@ -19,6 +19,14 @@ package podman
 // clusterLabelKey is applied to each "node" podman container for identification
 const clusterLabelKey = "io.x-k8s.kind.cluster"
 
+// cloneLabelKey is applied to node images committed by kind clone, with the
+// name of the cluster they were committed for
+const cloneLabelKey = "io.x-k8s.kind.clone"
+
 // nodeRoleLabelKey is applied to each "node" podman container for categorization
 // of nodes by role
 const nodeRoleLabelKey = "io.x-k8s.kind.role"
+
+// defaultNetworkName is the podman default bridge network the nodes are
+// attached to (https://docs.podman.com/network/bridge/#use-the-default-bridge-network)
+const defaultNetworkName = "bridge"
@@ -0,0 +1,108 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podman
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
+)
+
+// NodeEvents is part of the providers.Provider interface
+func (p *Provider) NodeEvents(ctx context.Context, cluster string, opts provider.NodeEventsOptions, fn func(provider.NodeEvent)) error {
+	// podman does not filter events by label, this is done below
+	args := []string{
+		"events",
+		"--format", "json",
+		"--filter", "type=container",
+	}
+	if !opts.Since.IsZero() {
+		args = append(args, "--since", opts.Since.Format(time.RFC3339))
+	}
+	if !opts.Follow {
+		args = append(args, "--stream=false")
+	}
+	cmd := exec.CommandContext(ctx, "podman", args...)
+	return common.StreamOutputLines(ctx, cmd, func(line string) error {
+		if e, ok := parseEvent(line, cluster); ok {
+			fn(e)
+		}
+		return nil
+	})
+}
+
+// podmanEventActions maps podman's event names to docker's
+var podmanEventActions = map[string]string{
+	"died":   "die",
+	"remove": "destroy",
+}
+
+// parseEvent parses a line of podman events --format json output, returning
+// false for lines that are not lifecycle events of the cluster's nodes
+func parseEvent(line, cluster string) (provider.NodeEvent, bool) {
+	event := struct {
+		Name              string
+		Status            string
+		Type              string
+		Attributes        map[string]string
+		ContainerExitCode *int
+		// older podman versions set Time to an RFC3339 timestamp
+		Time     json.RawMessage
+		TimeNano int64 `json:"timeNano"`
+	}{}
+	if err := json.Unmarshal([]byte(line), &event); err != nil || event.Type != "container" {
+		return provider.NodeEvent{}, false
+	}
+	if event.Attributes[clusterLabelKey] != cluster {
+		return provider.NodeEvent{}, false
+	}
+	action := event.Status
+	if a, renamed := podmanEventActions[action]; renamed {
+		action = a
+	}
+	if !isNodeEventAction(action) {
+		return provider.NodeEvent{}, false
+	}
+	e := provider.NodeEvent{
+		Time:   time.Unix(0, event.TimeNano),
+		Node:   event.Name,
+		Action: action,
+	}
+	var timestamp string
+	if event.TimeNano == 0 && json.Unmarshal(event.Time, &timestamp) == nil {
+		e.Time, _ = time.Parse(time.RFC3339Nano, timestamp)
+	}
+	if action == "die" && event.ContainerExitCode != nil {
+		e.ExitCode = strconv.Itoa(*event.ContainerExitCode)
+	}
+	return e, true
+}
+
+func isNodeEventAction(action string) bool {
+	for _, a := range provider.NodeEventActions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
@@ -29,6 +29,8 @@ import (
 // nodes.Node implementation for the podman provider
 type node struct {
 	name string
+	// ctx bounds all commands run for this node
+	ctx context.Context
 }
 
 func (n *node) String() string {
@@ -36,7 +38,7 @@ func (n *node) String() string {
 }
 
 func (n *node) Role() (string, error) {
-	cmd := exec.Command("podman", "inspect",
+	cmd := exec.CommandContext(n.ctx, "podman", "inspect",
 		"--format", fmt.Sprintf(`{{ index .Config.Labels "%s"}}`, nodeRoleLabelKey),
 		n.name,
 	)
@@ -52,7 +54,7 @@ func (n *node) Role() (string, error) {
 
 func (n *node) IP() (ipv4 string, ipv6 string, err error) {
 	// retrieve the IP address of the node using podman inspect
-	cmd := exec.Command("podman", "inspect",
+	cmd := exec.CommandContext(n.ctx, "podman", "inspect",
 		"-f", "{{.NetworkSettings.IPAddress}},{{.NetworkSettings.GlobalIPv6Address}}",
 		n.name, // ... against the "node" container
 	)
@@ -71,11 +73,7 @@ func (n *node) IP() (ipv4 string, ipv6 string, err error) {
 }
 
 func (n *node) Command(command string, args ...string) exec.Cmd {
-	return &nodeCmd{
-		nameOrID: n.name,
-		command:  command,
-		args:     args,
-	}
+	return n.CommandContext(n.ctx, command, args...)
 }
 
 func (n *node) CommandContext(ctx context.Context, command string, args ...string) exec.Cmd {
@@ -128,12 +126,7 @@ func (c *nodeCmd) Run() error {
 		// finally, with the caller args
 		c.args...,
 	)
-	var cmd exec.Cmd
-	if c.ctx != nil {
-		cmd = exec.CommandContext(c.ctx, "podman", args...)
-	} else {
-		cmd = exec.Command("podman", args...)
-	}
+	cmd := exec.CommandContext(c.ctx, "podman", args...)
 	if c.stdin != nil {
 		cmd.SetStdin(c.stdin)
 	}
@@ -167,5 +160,5 @@ func (c *nodeCmd) SetStderr(w io.Writer) exec.Cmd {
 }
 
 func (n *node) SerialLogs(w io.Writer) error {
-	return exec.Command("podman", "logs", n.name).SetStdout(w).SetStderr(w).Run()
+	return exec.CommandContext(n.ctx, "podman", "logs", n.name).SetStdout(w).SetStderr(w).Run()
 }
@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podman
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// preflightInfo gathers what common.Preflight needs from podman, this is
+// best effort and anything that can't be determined is left unset
+func preflightInfo(ctx context.Context, cfg *config.Cluster) *common.PreflightInfo {
+	info := &common.PreflightInfo{
+		Architecture:       podmanInfo(ctx, "{{.Host.Arch}}"),
+		ImageArchitectures: map[string]string{},
+		StoragePath:        podmanInfo(ctx, "{{.Store.GraphRoot}}"),
+		StorageDriver:      podmanInfo(ctx, "{{.Store.GraphDriverName}}"),
+		Rootless:           podmanInfo(ctx, "{{.Host.Security.Rootless}}") == "true",
+		VM:                 detectVM(ctx),
+	}
+	for _, image := range common.RequiredNodeImages(cfg).List() {
+		_, image := sanitizeImage(image)
+		cmd := exec.CommandContext(ctx, "podman", "image", "inspect", "-f", "{{.Architecture}}", image)
+		if lines, err := exec.OutputLines(cmd); err == nil && len(lines) == 1 {
+			info.ImageArchitectures[image] = strings.TrimSpace(lines[0])
+		}
+	}
+	return info
+}
+
+// detectVM returns the podman machine VM the podman service runs in, if
+// any, or nil
+func detectVM(ctx context.Context) *common.VM {
+	if podmanInfo(ctx, "{{.Host.ServiceIsRemote}}") != "true" {
+		return nil
+	}
+	home, _ := os.UserHomeDir()
+	return podmanMachineVM(runtime.GOOS, home)
+}
+
+// podmanMachineVM returns the podman machine VM with its default volumes
+// for goos, or nil if podman machine is not used on goos. On windows the
+// machine is a WSL distro with the drives mounted under /mnt
+func podmanMachineVM(goos, home string) *common.VM {
+	vm := &common.VM{
+		Name:              "podman machine",
+		ShareHint:         "recreate the machine with the directory mounted, e.g. podman machine init --volume DIR:DIR",
+		LoopbackPortsOnly: true,
+	}
+	switch goos {
+	case "darwin":
+		vm.SharedPaths = []string{home, "/private/folders", "/var/folders"}
+	case "windows":
+		vm.DriveRoot = "/mnt"
+	default:
+		// a remote podman service on linux is not a podman machine
+		return nil
+	}
+	return vm
+}
+
+// podmanInfo returns the podman info field for format, or "" if unavailable
+func podmanInfo(ctx context.Context, format string) string {
+	lines, err := exec.OutputLines(exec.CommandContext(ctx, "podman", "info", "-f", format))
+	if err != nil || len(lines) != 1 {
+		return ""
+	}
+	return strings.TrimSpace(lines[0])
+}
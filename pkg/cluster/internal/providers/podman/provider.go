@@ -17,6 +17,7 @@ limitations under the License.
 package podman
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net"
@@ -33,6 +34,7 @@ import (
 	"sigs.k8s.io/kind/pkg/exec"
 	"sigs.k8s.io/kind/pkg/log"
 
+	"sigs.k8s.io/kind/pkg/cluster/internal/disk"
 	internallogs "sigs.k8s.io/kind/pkg/cluster/internal/logs"
 	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
 	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
@@ -54,9 +56,14 @@ type Provider struct {
 	logger log.Logger
 }
 
+// String is part of the providers.Provider interface
+func (p *Provider) String() string {
+	return "podman"
+}
+
 // Provision is part of the providers.Provider interface
-func (p *Provider) Provision(status *cli.Status, cfg *config.Cluster) (err error) {
-	if err := ensureMinVersion(); err != nil {
+func (p *Provider) Provision(ctx context.Context, status *cli.Status, cfg *config.Cluster) (err error) {
+	if err := ensureMinVersion(ctx); err != nil {
 		return err
 	}
 
@@ -68,7 +75,12 @@ func (p *Provider) Provision(status *cli.Status, cfg *config.Cluster) (err error
 
 	// TODO: validate cfg
 	// ensure node images are pulled before actually provisioning
-	if err := ensureNodeImages(p.logger, status, cfg); err != nil {
+	if err := ensureNodeImages(ctx, p.logger, status, cfg); err != nil {
+		return err
+	}
+
+	// fail fast on host problems that would otherwise break creation midway
+	if err := common.Preflight(ctx, p.logger, cfg, preflightInfo(ctx, cfg)); err != nil {
 		return err
 	}
 
@@ -78,7 +90,7 @@ func (p *Provider) Provision(status *cli.Status, cfg *config.Cluster) (err error
 	defer func() { status.End(err == nil) }()
 
 	// plan creating the containers
-	createContainerFuncs, err := planCreation(cfg)
+	createContainerFuncs, err := planCreation(ctx, p.logger, cfg, "")
 	if err != nil {
 		return err
 	}
@@ -87,9 +99,52 @@ func (p *Provider) Provision(status *cli.Status, cfg *config.Cluster) (err error
 	return errors.UntilErrorConcurrent(createContainerFuncs)
 }
 
+// ProvisionNode is part of the providers.Provider interface
+func (p *Provider) ProvisionNode(ctx context.Context, status *cli.Status, cfg *config.Cluster, name string) (err error) {
+	if err := ensureNodeImages(ctx, p.logger, status, cfg); err != nil {
+		return err
+	}
+
+	status.Start(fmt.Sprintf("Preparing node %s 📦", name))
+	defer func() { status.End(err == nil) }()
+
+	createContainerFuncs, err := planCreation(ctx, p.logger, cfg, name)
+	if err != nil {
+		return err
+	}
+	if len(createContainerFuncs) == 0 {
+		return errors.Errorf("node %q is not in the config", name)
+	}
+	return errors.UntilErrorConcurrent(createContainerFuncs)
+}
+
+// Info is part of the providers.Provider interface
+func (p *Provider) Info(ctx context.Context) (*provider.Info, error) {
+	lines, err := exec.OutputLines(exec.CommandContext(ctx, "podman", "info", "-f", "{{.Host.Security.Rootless}}"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get podman info")
+	}
+	return &provider.Info{
+		Rootless: strings.TrimSpace(strings.Join(lines, "")) == "true",
+	}, nil
+}
+
+// MissingImages is part of the providers.Provider interface
+func (p *Provider) MissingImages(ctx context.Context, cfg *config.Cluster) ([]string, error) {
+	return missingImages(ctx, cfg)
+}
+
+// ImageCommand is part of the providers.Provider interface
+func (p *Provider) ImageCommand(ctx context.Context, image, command string, args ...string) exec.Cmd {
+	_, image = sanitizeImage(image)
+	return exec.CommandContext(ctx, "podman",
+		append([]string{"run", "--rm", "--entrypoint", command, image}, args...)...,
+	)
+}
+
 // ListClusters is part of the providers.Provider interface
-func (p *Provider) ListClusters() ([]string, error) {
-	cmd := exec.Command("podman",
+func (p *Provider) ListClusters(ctx context.Context) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "podman",
 		"ps",
 		"-a", // show stopped nodes
 		// filter for nodes with the cluster label
@@ -99,14 +154,14 @@ func (p *Provider) ListClusters() ([]string, error) {
 	)
 	lines, err := exec.OutputLines(cmd)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to list clusters")
+		return nil, withUnavailableReason(errors.Wrap(err, "failed to list clusters"))
 	}
 	return sets.NewString(lines...).List(), nil
 }
 
 // ListNodes is part of the providers.Provider interface
-func (p *Provider) ListNodes(cluster string) ([]nodes.Node, error) {
-	cmd := exec.Command("podman",
+func (p *Provider) ListNodes(ctx context.Context, cluster string) ([]nodes.Node, error) {
+	cmd := exec.CommandContext(ctx, "podman",
 		"ps",
 		"-a", // show stopped nodes
 		// filter for nodes with the cluster label
@@ -116,18 +171,44 @@ func (p *Provider) ListNodes(cluster string) ([]nodes.Node, error) {
 	)
 	lines, err := exec.OutputLines(cmd)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to list clusters")
+		return nil, withUnavailableReason(errors.Wrap(err, "failed to list clusters"))
 	}
 	// convert names to node handles
 	ret := make([]nodes.Node, 0, len(lines))
 	for _, name := range lines {
-		ret = append(ret, p.node(name))
+		ret = append(ret, p.node(ctx, name))
 	}
 	return ret, nil
 }
 
 // DeleteNodes is part of the providers.Provider interface
-func (p *Provider) DeleteNodes(n []nodes.Node) error {
+func (p *Provider) DeleteNodes(ctx context.Context, n []nodes.Node) error {
+	if len(n) == 0 {
+		return nil
+	}
+	// the cluster disk can only be unmounted once the nodes using it are gone
+	n, disks, err := disk.SplitNodes(n)
+	if err != nil {
+		return err
+	}
+	if err := deleteNodes(ctx, n); err != nil {
+		return err
+	}
+	for _, d := range disks {
+		// this fails if the disk container is stopped, which must not
+		// prevent deleting the cluster
+		if err := d.CommandContext(ctx, disk.CleanupCommand[0], disk.CleanupCommand[1:]...).Run(); err != nil {
+			p.logger.Warnf("failed to remove the cluster disk, unmount and remove it in %s on the host: %v", disk.HostPathFor(d.String()), err)
+		}
+		if err := exec.CommandContext(ctx, "podman", "rm", "-f", "-v", d.String()).Run(); err != nil {
+			return errors.Wrap(err, "failed to delete nodes")
+		}
+	}
+	return nil
+}
+
+// deleteNodes deletes the node containers and their volumes
+func deleteNodes(ctx context.Context, n []nodes.Node) error {
 	if len(n) == 0 {
 		return nil
 	}
@@ -141,24 +222,119 @@ func (p *Provider) DeleteNodes(n []nodes.Node) error {
 	for _, node := range n {
 		args = append(args, node.String())
 	}
-	if err := exec.Command(command, args...).Run(); err != nil {
+	if err := exec.CommandContext(ctx, command, args...).Run(); err != nil {
 		return errors.Wrap(err, "failed to delete nodes")
 	}
+	// look up the volumes of all nodes concurrently
+	volumes := make([][]string, len(n))
+	fns := make([]func() error, 0, len(n))
+	for i, node := range n {
+		i, node := i, node // capture loop variables
+		fns = append(fns, func() error {
+			var err error
+			volumes[i], err = getVolumes(ctx, node.String())
+			return err
+		})
+	}
+	if err := errors.UntilErrorConcurrent(fns); err != nil {
+		return err
+	}
 	var nodeVolumes []string
+	for _, v := range volumes {
+		nodeVolumes = append(nodeVolumes, v...)
+	}
+	return deleteVolumes(ctx, nodeVolumes)
+}
+
+// RestartNodes is part of the providers.Provider interface
+func (p *Provider) RestartNodes(ctx context.Context, n []nodes.Node) error {
+	if len(n) == 0 {
+		return nil
+	}
+	args := []string{"restart"}
 	for _, node := range n {
-		volumes, err := getVolumes(node.String())
-		if err != nil {
-			return err
-		}
-		nodeVolumes = append(nodeVolumes, volumes...)
+		args = append(args, node.String())
+	}
+	if err := exec.CommandContext(ctx, "podman", args...).Run(); err != nil {
+		return errors.Wrap(err, "failed to restart nodes")
+	}
+	return nil
+}
+
+// CreateProxy is part of the providers.Provider interface
+func (p *Provider) CreateProxy(ctx context.Context, cfg *config.Cluster, name string, portMappings []config.PortMapping) error {
+	args, err := commonArgs(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	args, err = runArgsForProxy(cfg, name, portMappings, args)
+	if err != nil {
+		return err
+	}
+	return createContainer(ctx, p.logger, name, args)
+}
+
+// CommitNode is part of the providers.Provider interface
+func (p *Provider) CommitNode(ctx context.Context, n nodes.Node, image, cluster string) error {
+	if err := exec.CommandContext(ctx, "podman",
+		"commit",
+		// the node's stable machine-id, if any, must not carry over
+		"--change", "ENV KIND_MACHINE_ID=",
+		"--change", fmt.Sprintf("LABEL %s=%s", cloneLabelKey, cluster),
+		n.String(), image,
+	).Run(); err != nil {
+		return errors.Wrapf(err, "failed to commit node %s", n.String())
 	}
-	return deleteVolumes(nodeVolumes)
+	return nil
+}
+
+// DeleteImages is part of the providers.Provider interface
+func (p *Provider) DeleteImages(ctx context.Context, cluster string) error {
+	cmd := exec.CommandContext(ctx, "podman",
+		"images",
+		"--filter", fmt.Sprintf("label=%s=%s", cloneLabelKey, cluster),
+		"--format", "{{.Repository}}:{{.Tag}}",
+	)
+	images, err := exec.OutputLines(cmd)
+	if err != nil {
+		return errors.Wrap(err, "failed to list committed node images")
+	}
+	if len(images) == 0 {
+		return nil
+	}
+	// remove by reference, images cloned from these keep the shared layers
+	if err := exec.CommandContext(ctx, "podman", append([]string{"rmi"}, images...)...).Run(); err != nil {
+		return errors.Wrap(err, "failed to delete committed node images")
+	}
+	return nil
+}
+
+// InspectNodes is part of the providers.Provider interface
+func (p *Provider) InspectNodes(ctx context.Context, n []nodes.Node) ([]byte, error) {
+	args := []string{"inspect", "--type=container"}
+	for _, node := range n {
+		args = append(args, node.String())
+	}
+	out, err := exec.Output(exec.CommandContext(ctx, "podman", args...))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to inspect nodes")
+	}
+	return out, nil
+}
+
+// NetworkInfo is part of the providers.Provider interface
+func (p *Provider) NetworkInfo(ctx context.Context, cluster string) (*provider.NetworkInfo, error) {
+	out, err := exec.Output(exec.CommandContext(ctx, "podman", "network", "inspect", defaultNetworkName))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to inspect the cluster network")
+	}
+	return common.ParseNetworkInspect(out)
 }
 
 // GetAPIServerEndpoint is part of the providers.Provider interface
-func (p *Provider) GetAPIServerEndpoint(cluster string) (string, error) {
+func (p *Provider) GetAPIServerEndpoint(ctx context.Context, cluster string) (string, error) {
 	// locate the node that hosts this
-	allNodes, err := p.ListNodes(cluster)
+	allNodes, err := p.ListNodes(ctx, cluster)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to list nodes")
 	}
@@ -168,7 +344,8 @@ func (p *Provider) GetAPIServerEndpoint(cluster string) (string, error) {
 	}
 
 	// retrieve the specific port mapping using podman inspect
-	cmd := exec.Command(
+	cmd := exec.CommandContext(
+		ctx,
 		"podman", "inspect",
 		"--format",
 		"{{ json .NetworkSettings.Ports }}",
@@ -230,9 +407,9 @@ func (p *Provider) GetAPIServerEndpoint(cluster string) (string, error) {
 }
 
 // GetAPIServerInternalEndpoint is part of the providers.Provider interface
-func (p *Provider) GetAPIServerInternalEndpoint(cluster string) (string, error) {
+func (p *Provider) GetAPIServerInternalEndpoint(ctx context.Context, cluster string) (string, error) {
 	// locate the node that hosts this
-	allNodes, err := p.ListNodes(cluster)
+	allNodes, err := p.ListNodes(ctx, cluster)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to list nodes")
 	}
@@ -251,14 +428,15 @@ func (p *Provider) GetAPIServerInternalEndpoint(cluster string) (string, error)
 }
 
 // node returns a new node handle for this provider
-func (p *Provider) node(name string) nodes.Node {
+func (p *Provider) node(ctx context.Context, name string) nodes.Node {
 	return &node{
 		name: name,
+		ctx:  ctx,
 	}
 }
 
 // CollectLogs will populate dir with cluster logs and other debug files
-func (p *Provider) CollectLogs(dir string, nodes []nodes.Node) error {
+func (p *Provider) CollectLogs(ctx context.Context, dir string, nodes []nodes.Node, opts *internallogs.Options) error {
 	execToPathFn := func(cmd exec.Cmd, path string) func() error {
 		return func() error {
 			f, err := common.FileOnHost(path)
@@ -274,7 +452,7 @@ func (p *Provider) CollectLogs(dir string, nodes []nodes.Node) error {
 		// TODO(bentheelder): record the kind version here as well
 		// record info about the host podman
 		execToPathFn(
-			exec.Command("podman", "info"),
+			exec.CommandContext(ctx, "podman", "info"),
 			filepath.Join(dir, "podman-info.txt"),
 		),
 	}
@@ -285,21 +463,23 @@ func (p *Provider) CollectLogs(dir string, nodes []nodes.Node) error {
 		node := n // https://golang.org/doc/faq#closures_and_goroutines
 		name := node.String()
 		path := filepath.Join(dir, name)
-		if err := internallogs.DumpDir(p.logger, node, "/var/log", path); err != nil {
+		if err := internallogs.DumpLogDirs(p.logger, node, opts, path); err != nil {
 			errs = append(errs, err)
 		}
 
-		fns = append(fns,
-			func() error { return common.CollectLogs(node, path) },
-			execToPathFn(exec.Command("podman", "inspect", name), filepath.Join(path, "inspect.json")),
-			func() error {
-				f, err := common.FileOnHost(filepath.Join(path, "serial.log"))
-				if err != nil {
-					return err
-				}
-				return node.SerialLogs(f)
-			},
-		)
+		fns = append(fns, func() error { return common.CollectLogs(node, path, opts) })
+		if opts.Has(internallogs.ComponentNode) {
+			fns = append(fns,
+				execToPathFn(exec.CommandContext(ctx, "podman", "inspect", name), filepath.Join(path, "inspect.json")),
+				func() error {
+					f, err := common.FileOnHost(filepath.Join(path, "serial.log"))
+					if err != nil {
+						return err
+					}
+					return node.SerialLogs(f)
+				},
+			)
+		}
 	}
 
 	// run and collect up all errors
@@ -17,58 +17,84 @@ limitations under the License.
 package podman
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"sigs.k8s.io/kind/pkg/cluster/constants"
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/log"
 
-	"sigs.k8s.io/kind/pkg/cluster/internal/loadbalancer"
+	"sigs.k8s.io/kind/pkg/cluster/internal/disk"
+	"sigs.k8s.io/kind/pkg/cluster/internal/oidc"
 	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
 	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/events"
 )
 
 // planCreation creates a slice of funcs that will create the containers
-func planCreation(cfg *config.Cluster) (createContainerFuncs []func() error, err error) {
+// if only is set, just the node with that name is planned
+func planCreation(ctx context.Context, logger log.Logger, cfg *config.Cluster, only string) (createContainerFuncs []func() error, err error) {
 	// these apply to all container creation
-	nodeNamer := common.MakeNodeNamer(cfg.Name)
-	genericArgs, err := commonArgs(cfg)
+	genericArgs, err := commonArgs(ctx, cfg)
 	if err != nil {
 		return nil, err
 	}
 
 	// only the external LB should reflect the port if we have multiple control planes
 	apiServerPort := cfg.Networking.APIServerPort
-	apiServerAddress := cfg.Networking.APIServerAddress
-	if clusterHasImplicitLoadBalancer(cfg) {
+	apiServerAddresses := append([]string{cfg.Networking.APIServerAddress}, cfg.Networking.AdditionalAPIServerAddresses...)
+	haveLoadbalancer := clusterHasImplicitLoadBalancer(cfg)
+	if haveLoadbalancer {
 		// TODO: picking ports locally is less than ideal with a remote runtime
 		// (does podman have this?)
 		// but this is supposed to be an implementation detail and NOT picking
 		// them breaks host reboot ...
 		// For now remote podman + multi control plane is not supported
-		apiServerPort = 0              // replaced with random ports
-		apiServerAddress = "127.0.0.1" // only the LB needs to be non-local
+		apiServerPort = 0                          // replaced with random ports
+		apiServerAddresses = []string{"127.0.0.1"} // only the LB needs to be non-local
 		if clusterIsIPv6(cfg) {
-			apiServerAddress = "::1" // only the LB needs to be non-local
+			apiServerAddresses = []string{"::1"} // only the LB needs to be non-local
 		}
+	}
+	if haveLoadbalancer && only == "" {
 		// plan loadbalancer node
-		name := nodeNamer(constants.ExternalLoadBalancerNodeRoleValue)
+		name := common.MakeNodeNamer(cfg.Name)(constants.ExternalLoadBalancerNodeRoleValue)
 		createContainerFuncs = append(createContainerFuncs, func() error {
 			args, err := runArgsForLoadBalancer(cfg, name, genericArgs)
 			if err != nil {
 				return err
 			}
-			return createContainer(args)
+			return createContainer(ctx, logger, name, args)
+		})
+	}
+
+	// plan the test OIDC issuer, which is configured later
+	if cfg.Authentication.OIDC.TestIssuer && only == "" {
+		name := oidc.IssuerName(cfg.Name)
+		createContainerFuncs = append(createContainerFuncs, func() error {
+			return createContainer(ctx, logger, name, runArgsForOIDCIssuer(cfg, name, genericArgs))
 		})
 	}
 
 	// plan normal nodes
-	for _, node := range cfg.Nodes {
-		node := node.DeepCopy()              // copy so we can modify
-		name := nodeNamer(string(node.Role)) // name the node
+	// mounts are resolved inside the podman machine VM, if any
+	vm := detectVM(ctx)
+
+	names := config.NodeNames(cfg)
+	for i, node := range cfg.Nodes {
+		name := names[i]
+		if only != "" && name != only {
+			continue
+		}
+		node := node.DeepCopy() // copy so we can modify
+
+		// the static pods directory is mounted like any other extra mount
+		node.ExtraMounts = append(node.ExtraMounts, common.StaticPodsMounts(node)...)
 
 		// fixup relative paths, podman can only handle absolute paths
 		for i := range node.ExtraMounts {
@@ -78,32 +104,51 @@ func planCreation(cfg *config.Cluster) (createContainerFuncs []func() error, err
 				return nil, errors.Wrapf(err, "unable to resolve absolute path for hostPath: %q", hostPath)
 			}
 			node.ExtraMounts[i].HostPath = absHostPath
+			if vm != nil {
+				node.ExtraMounts[i].HostPath = vm.TranslateHostPath(absHostPath)
+			}
 		}
 
 		// plan actual creation based on role
 		switch node.Role {
 		case config.ControlPlaneRole:
 			createContainerFuncs = append(createContainerFuncs, func() error {
-				node.ExtraPortMappings = append(node.ExtraPortMappings,
-					config.PortMapping{
-						ListenAddress: apiServerAddress,
-						HostPort:      apiServerPort,
-						ContainerPort: common.APIServerInternalPort,
-					},
-				)
-				args, err := runArgsForNode(node, cfg.Networking.IPFamily, name, genericArgs)
+				for _, address := range apiServerAddresses {
+					node.ExtraPortMappings = append(node.ExtraPortMappings,
+						config.PortMapping{
+							ListenAddress: address,
+							HostPort:      apiServerPort,
+							ContainerPort: common.APIServerInternalPort,
+						},
+					)
+				}
+				storageArgs, err := nodeStorageArgs(ctx, cfg, name)
 				if err != nil {
 					return err
 				}
-				return createContainer(args)
+				etcdArgs, err := common.EtcdDataArgs(cfg, node, name, func(volume string) (bool, error) {
+					return common.VolumeExists(ctx, "podman", volume)
+				})
+				if err != nil {
+					return err
+				}
+				args, err := runArgsForNode(node, cfg.Networking.IPFamily, name, append(storageArgs, etcdArgs...), genericArgs)
+				if err != nil {
+					return err
+				}
+				return createContainer(ctx, logger, name, args)
 			})
 		case config.WorkerRole:
 			createContainerFuncs = append(createContainerFuncs, func() error {
-				args, err := runArgsForNode(node, cfg.Networking.IPFamily, name, genericArgs)
+				storageArgs, err := nodeStorageArgs(ctx, cfg, name)
+				if err != nil {
+					return err
+				}
+				args, err := runArgsForNode(node, cfg.Networking.IPFamily, name, storageArgs, genericArgs)
 				if err != nil {
 					return err
 				}
-				return createContainer(args)
+				return createContainer(ctx, logger, name, args)
 			})
 		default:
 			return nil, errors.Errorf("unknown node role: %q", node.Role)
@@ -112,10 +157,12 @@ func planCreation(cfg *config.Cluster) (createContainerFuncs []func() error, err
 	return createContainerFuncs, nil
 }
 
-func createContainer(args []string) error {
-	if err := exec.Command("podman", args...).Run(); err != nil {
-		return errors.Wrap(err, "podman run error")
+func createContainer(ctx context.Context, logger log.Logger, name string, args []string) error {
+	start := time.Now()
+	if err := common.CreateContainer(ctx, logger, "podman", name, args); err != nil {
+		return err
 	}
+	events.Emit(logger, events.Event{Type: events.NodeCreated, Node: name, Duration: time.Since(start)})
 	return nil
 }
 
@@ -135,13 +182,15 @@ func clusterHasImplicitLoadBalancer(cfg *config.Cluster) bool {
 }
 
 // commonArgs computes static arguments that apply to all containers
-func commonArgs(cfg *config.Cluster) ([]string, error) {
+func commonArgs(ctx context.Context, cfg *config.Cluster) ([]string, error) {
 	// standard arguments all nodes containers need, computed once
 	args := []string{
 		"--detach", // run the container detached
 		"--tty",    // allocate a tty for entrypoint logs
 		// label the node with the cluster ID
 		"--label", fmt.Sprintf("%s=%s", clusterLabelKey, cfg.Name),
+		// restart the same as docker nodes, see the docker provider
+		common.RestartPolicyArg(cfg),
 	}
 
 	// enable IPv6 if necessary
@@ -149,8 +198,13 @@ func commonArgs(cfg *config.Cluster) ([]string, error) {
 		args = append(args, "--sysctl=net.ipv6.conf.all.disable_ipv6=0", "--sysctl=net.ipv6.conf.all.forwarding=1")
 	}
 
+	// the fuse based containerd snapshotters need the fuse device
+	if common.NeedsFuse(cfg) {
+		args = append(args, "--device", "/dev/fuse")
+	}
+
 	// pass proxy environment variables
-	proxyEnv, err := getProxyEnv(cfg)
+	proxyEnv, err := getProxyEnv(ctx, cfg)
 	if err != nil {
 		return nil, errors.Wrap(err, "proxy setup error")
 	}
@@ -161,27 +215,49 @@ func commonArgs(cfg *config.Cluster) ([]string, error) {
 	return args, nil
 }
 
-func runArgsForNode(node *config.Node, clusterIPFamily config.ClusterIPFamily, name string, args []string) ([]string, error) {
+// nodeStorageArgs returns the args for the node's persistent storage
+func nodeStorageArgs(ctx context.Context, cfg *config.Cluster, name string) ([]string, error) {
+	// with a diskQuota the storage is on the size limited cluster disk
+	if cfg.DiskQuota != "" {
+		return disk.NodeMountArgs(cfg.Name, name), nil
+	}
+
 	// Pre-create anonymous volumes to enable specifying mount options
 	// during container run time
-	containerdVolume, err := createAnonymousVolume(name)
+	containerdVolume, err := createAnonymousVolume(ctx, name)
 	if err != nil {
 		return nil, err
 	}
 
-	kubeletVolume, err := createAnonymousVolume(name)
+	kubeletVolume, err := createAnonymousVolume(ctx, name)
 	if err != nil {
 		return nil, err
 	}
 
-	logVolume, err := createAnonymousVolume(name)
+	logVolume, err := createAnonymousVolume(ctx, name)
 	if err != nil {
 		return nil, err
 	}
 
+	// this ensures that E.G. pods, logs etc. are not on the container
+	// filesystem, which is not only better for performance, but allows
+	// running kind in kind for "party tricks"
+	// (please don't depend on doing this though!)
+	// also enable default docker volume options
+	// suid: SUID applications on the volume will be able to change their privilege
+	// exec: executables on the volume will be able to executed within the container
+	// dev: devices on the volume will be able to be used by processes within the container
+	return []string{
+		"--volume", fmt.Sprintf("%s:/var/lib/containerd:suid,exec,dev", containerdVolume),
+		"--volume", fmt.Sprintf("%s:/var/lib/kubelet:suid,exec,dev", kubeletVolume),
+		"--volume", fmt.Sprintf("%s:/var/log:suid,exec,dev", logVolume),
+	}, nil
+}
+
+func runArgsForNode(node *config.Node, clusterIPFamily config.ClusterIPFamily, name string, storageArgs, args []string) ([]string, error) {
 	args = append([]string{
 		"run",
-		"--hostname", name, // make hostname match container name
+		"--hostname", common.NodeHostname(node, name), // hostname defaults to the container name
 		"--name", name, // ... and set the container name
 		// label the node with the role ID
 		"--label", fmt.Sprintf("%s=%s", nodeRoleLabelKey, node.Role),
@@ -194,26 +270,27 @@ func runArgsForNode(node *config.Node, clusterIPFamily config.ClusterIPFamily, n
 		// runtime temporary storage
 		"--tmpfs", "/tmp", // various things depend on working /tmp
 		"--tmpfs", "/run", // systemd wants a writable /run
-		// runtime persistent storage
-		// this ensures that E.G. pods, logs etc. are not on the container
-		// filesystem, which is not only better for performance, but allows
-		// running kind in kind for "party tricks"
-		// (please don't depend on doing this though!)
-		// also enable default docker volume options
-		// suid: SUID applications on the volume will be able to change their privilege
-		// exec: executables on the volume will be able to executed within the container
-		// dev: devices on the volume will be able to be used by processes within the container
-		"--volume", fmt.Sprintf("%s:/var/lib/containerd:suid,exec,dev", containerdVolume),
-		"--volume", fmt.Sprintf("%s:/var/lib/kubelet:suid,exec,dev", kubeletVolume),
-		"--volume", fmt.Sprintf("%s:/var/log:suid,exec,dev", logVolume),
 		// some k8s things want to read /lib/modules
 		"--volume", "/lib/modules:/lib/modules:ro",
 	},
 		args...,
 	)
 
+	// runtime persistent storage
+	args = append(args, storageArgs...)
+
 	// convert mounts and port mappings to container run args
 	args = append(args, generateMountBindings(node.ExtraMounts...)...)
+	args = append(args, common.NodeResourceArgs(node)...)
+	args = append(args, common.NodeIdentityArgs(node)...)
+
+	// request GPUs via CDI and expose the host's NVIDIA toolkit to containerd
+	if node.GPUs != "" {
+		for _, device := range common.CDIDevices(node.GPUs) {
+			args = append(args, "--device", device)
+		}
+		args = append(args, generateMountBindings(common.NVIDIAToolkitMounts()...)...)
+	}
 	mappingArgs, err := generatePortMappings(clusterIPFamily, node.ExtraPortMappings...)
 	if err != nil {
 		return nil, err
@@ -236,30 +313,123 @@ func runArgsForLoadBalancer(cfg *config.Cluster, name string, args []string) ([]
 		args...,
 	)
 
-	// load balancer port mapping
-	mappingArgs, err := generatePortMappings(cfg.Networking.IPFamily,
-		config.PortMapping{
-			ListenAddress: cfg.Networking.APIServerAddress,
+	// load balancer port mapping, plus any extra frontends published on the host
+	portMappings := []config.PortMapping{{
+		ListenAddress: cfg.Networking.APIServerAddress,
+		HostPort:      cfg.Networking.APIServerPort,
+		ContainerPort: common.APIServerInternalPort,
+	}}
+	for _, address := range cfg.Networking.AdditionalAPIServerAddresses {
+		portMappings = append(portMappings, config.PortMapping{
+			ListenAddress: address,
 			HostPort:      cfg.Networking.APIServerPort,
 			ContainerPort: common.APIServerInternalPort,
-		},
+		})
+	}
+	for _, f := range cfg.LoadBalancer.ExtraFrontends {
+		if f.HostPort == 0 {
+			continue
+		}
+		portMappings = append(portMappings, config.PortMapping{
+			ListenAddress: cfg.Networking.APIServerAddress,
+			HostPort:      f.HostPort,
+			ContainerPort: f.Port,
+		})
+	}
+	mappingArgs, err := generatePortMappings(cfg.Networking.IPFamily, portMappings...)
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, mappingArgs...)
+
+	// finally, specify the image to run
+	_, image := sanitizeImage(common.LoadBalancerImage(cfg))
+	return append(args, image), nil
+}
+
+// runArgsForProxy returns the args to run the `kind expose` proxy, it is
+// configured like the load balancer once created
+func runArgsForProxy(cfg *config.Cluster, name string, portMappings []config.PortMapping, args []string) ([]string, error) {
+	args = append([]string{
+		"run",
+		"--hostname", name, // make hostname match container name
+		"--name", name, // ... and set the container name
+		// label the node with the role ID
+		"--label", fmt.Sprintf("%s=%s", nodeRoleLabelKey, constants.ExposeProxyNodeRoleValue),
+	},
+		args...,
 	)
+	mappingArgs, err := generatePortMappings(cfg.Networking.IPFamily, portMappings...)
 	if err != nil {
 		return nil, err
 	}
 	args = append(args, mappingArgs...)
 
 	// finally, specify the image to run
-	_, image := sanitizeImage(loadbalancer.Image)
+	_, image := sanitizeImage(common.LoadBalancerImage(cfg))
 	return append(args, image), nil
 }
 
-func getProxyEnv(cfg *config.Cluster) (map[string]string, error) {
+// createDisk creates the disk container for a cluster with a diskQuota, and
+// waits for it to mount the cluster disk the nodes' storage is placed on
+func createDisk(ctx context.Context, logger log.Logger, cfg *config.Cluster) error {
+	size, err := config.DiskQuotaBytes(cfg.DiskQuota)
+	if err != nil {
+		return err
+	}
+	image := cfg.Nodes[0].Image
+	// podman does not create missing bind mount sources
+	prepareArgs := append([]string{"run"}, disk.PrepareArgs(cfg.Name, image)...)
+	if err := exec.CommandContext(ctx, "podman", prepareArgs...).Run(); err != nil {
+		return errors.Wrap(err, "failed to create the cluster disk directory")
+	}
+	name := disk.Name(cfg.Name)
+	command := disk.Command(size, config.NodeNames(cfg)[:len(cfg.Nodes)])
+	args := []string{
+		"run",
+		"--detach",
+		"--hostname", name, // make hostname match container name
+		"--name", name, // ... and set the container name
+		// label the node with the cluster and role IDs
+		"--label", fmt.Sprintf("%s=%s", clusterLabelKey, cfg.Name),
+		"--label", fmt.Sprintf("%s=%s", nodeRoleLabelKey, constants.DiskNodeRoleValue),
+		common.RestartPolicyArg(cfg),
+		"--net", "none",
+		// mounting loop devices requires privileged
+		"--privileged",
+		// the disk is mounted with shared propagation so the nodes see it
+		"--volume", fmt.Sprintf("%s:%s:rshared", disk.HostPath(cfg.Name), disk.ContainerPath),
+		"--entrypoint", command[0],
+		image,
+	}
+	if err := createContainer(ctx, logger, name, append(args, command[1:]...)); err != nil {
+		return err
+	}
+	return disk.WaitReady(ctx, "podman", name)
+}
+
+// runArgsForOIDCIssuer returns the args to run the test OIDC issuer, it
+// waits for its config to be written by cluster creation
+func runArgsForOIDCIssuer(cfg *config.Cluster, name string, args []string) []string {
+	args = append([]string{
+		"run",
+		"--hostname", name, // make hostname match container name
+		"--name", name, // ... and set the container name
+		// label the node with the role ID
+		"--label", fmt.Sprintf("%s=%s", nodeRoleLabelKey, constants.OIDCIssuerNodeRoleValue),
+		"--entrypoint", oidc.Command[0],
+	},
+		args...,
+	)
+	_, image := sanitizeImage(common.OverrideImage(cfg, oidc.Image))
+	return append(append(args, image), oidc.Command[1:]...)
+}
+
+func getProxyEnv(ctx context.Context, cfg *config.Cluster) (map[string]string, error) {
 	envs := common.GetProxyEnvs(cfg)
 	// Specifically add the podman network subnets to NO_PROXY if we are using a proxy
 	if len(envs) > 0 {
-		// podman default bridge network is named "bridge" (https://docs.podman.com/network/bridge/#use-the-default-bridge-network)
-		subnets, err := getSubnets("bridge")
+		subnets, err := getSubnets(ctx, defaultNetworkName)
 		if err != nil {
 			return nil, err
 		}
@@ -277,9 +447,9 @@ func getProxyEnv(cfg *config.Cluster) (map[string]string, error) {
 	return envs, nil
 }
 
-func getSubnets(networkName string) ([]string, error) {
+func getSubnets(ctx context.Context, networkName string) ([]string, error) {
 	format := `{{range (index (index . "IPAM") "Config")}}{{index . "Subnet"}} {{end}}`
-	cmd := exec.Command("podman", "network", "inspect", "-f", format, networkName)
+	cmd := exec.CommandContext(ctx, "podman", "network", "inspect", "-f", format, networkName)
 	lines, err := exec.CombinedOutputLines(cmd)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get subnets")
@@ -291,7 +461,7 @@ func getSubnets(networkName string) ([]string, error) {
 // '<HostPath>:<ContainerPath>[:options]', where 'options'
 // is a comma-separated list of the following strings:
 // 'ro', if the path is read only
-// 'Z', if the volume requires SELinux relabeling
+// 'z' or 'Z', if the volume requires shared or private SELinux relabeling
 func generateMountBindings(mounts ...config.Mount) []string {
 	args := make([]string, 0, len(mounts))
 	for _, m := range mounts {
@@ -304,8 +474,12 @@ func generateMountBindings(mounts ...config.Mount) []string {
 		// does not provide an SELinux context relabeling will label the volume with
 		// the container's randomly allocated MCS label. This would restrict access
 		// to the volume to the container which mounts it first.
-		if m.SelinuxRelabel {
+		switch m.SelinuxRelabel {
+		case config.MountSelinuxRelabelShared:
+			attrs = append(attrs, "z")
+		case config.MountSelinuxRelabelPrivate:
 			attrs = append(attrs, "Z")
+		default: // no relabeling
 		}
 		switch m.Propagation {
 		case config.MountPropagationNone:
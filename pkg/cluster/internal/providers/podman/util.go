@@ -17,6 +17,7 @@ limitations under the License.
 package podman
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -36,8 +37,17 @@ func IsAvailable() bool {
 	return strings.HasPrefix(lines[0], "podman version")
 }
 
-func getPodmanVersion() (*version.Version, error) {
-	cmd := exec.Command("podman", "--version")
+// withUnavailableReason marks err with errors.ErrProviderUnavailable
+// if podman is not available
+func withUnavailableReason(err error) error {
+	if err != nil && !IsAvailable() {
+		return errors.WithReason(err, errors.ErrProviderUnavailable)
+	}
+	return err
+}
+
+func getPodmanVersion(ctx context.Context) (*version.Version, error) {
+	cmd := exec.CommandContext(ctx, "podman", "--version")
 	lines, err := exec.CombinedOutputLines(cmd)
 	if err != nil {
 		return nil, err
@@ -58,9 +68,9 @@ const (
 	minSupportedVersion = "1.8.0"
 )
 
-func ensureMinVersion() error {
+func ensureMinVersion(ctx context.Context) error {
 	// ensure that podman version is a compatible version
-	v, err := getPodmanVersion()
+	v, err := getPodmanVersion(ctx)
 	if err != nil {
 		return errors.Wrap(err, "failed to check podman version")
 	}
@@ -73,8 +83,8 @@ func ensureMinVersion() error {
 // createAnonymousVolume creates a new anonymous volume
 // with the specified label=true
 // returns the name of the volume created
-func createAnonymousVolume(label string) (string, error) {
-	cmd := exec.Command("podman",
+func createAnonymousVolume(ctx context.Context, label string) (string, error) {
+	cmd := exec.CommandContext(ctx, "podman",
 		"volume",
 		"create",
 		// podman only support filter on key during list
@@ -88,8 +98,8 @@ func createAnonymousVolume(label string) (string, error) {
 }
 
 // getVolumes gets volume names filtered on specified label
-func getVolumes(label string) ([]string, error) {
-	cmd := exec.Command("podman",
+func getVolumes(ctx context.Context, label string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "podman",
 		"volume",
 		"ls",
 		"--filter", fmt.Sprintf("label=%s", label),
@@ -105,13 +115,13 @@ func getVolumes(label string) ([]string, error) {
 	return strings.Split(string(trimmedOutput), "\n"), nil
 }
 
-func deleteVolumes(names []string) error {
+func deleteVolumes(ctx context.Context, names []string) error {
 	args := []string{
 		"volume",
 		"rm",
 		"--force",
 	}
 	args = append(args, names...)
-	cmd := exec.Command("podman", args...)
+	cmd := exec.CommandContext(ctx, "podman", args...)
 	return cmd.Run()
 }
@@ -0,0 +1,79 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+// CreateContainer runs binary, e.g. "docker", with args starting with "run"
+// to create the container named name, retrying transient failures.
+//
+// A failed attempt may leave the container created, this is removed by the
+// ID the runtime recorded with --cidfile, so that a container by the same
+// name that the attempt did not create is never removed. A name conflict is
+// not transient and is not retried.
+func CreateContainer(ctx context.Context, logger log.Logger, binary, name string, args []string) error {
+	return Retry(ctx, logger, "create node "+name, func() error {
+		return runContainer(ctx, binary, args)
+	})
+}
+
+func runContainer(ctx context.Context, binary string, args []string) error {
+	dir, err := ioutil.TempDir("", "kind-cid")
+	if err != nil {
+		return errors.Wrap(err, "failed to create container id dir")
+	}
+	defer os.RemoveAll(dir)
+	cidFile := filepath.Join(dir, "cid")
+
+	runArgs := append([]string{args[0], "--cidfile", cidFile}, args[1:]...)
+	err = exec.CommandContext(ctx, binary, runArgs...).Run()
+	if err == nil {
+		return nil
+	}
+	err = errors.Wrapf(err, "%s run error", binary)
+	if isNameConflict(err) {
+		return Permanent(err)
+	}
+	// the cidfile is written once the container is created
+	if id, rerr := ioutil.ReadFile(cidFile); rerr == nil && strings.TrimSpace(string(id)) != "" {
+		_ = exec.CommandContext(ctx, binary, "rm", "-f", "-v", strings.TrimSpace(string(id))).Run()
+	}
+	return WithPortConflictReason(err)
+}
+
+// isNameConflict returns true if err is from running a container with the
+// name of an existing container
+func isNameConflict(err error) bool {
+	runErr := exec.RunErrorForError(err)
+	if runErr == nil {
+		return false
+	}
+	// docker: Conflict. The container name "/foo" is already in use by ...
+	// podman: the container name "foo" is already in use by ...
+	output := string(runErr.Output)
+	return strings.Contains(output, "container name") && strings.Contains(output, "is already in use")
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+// fakeRuntime logs each invocation to $0.log, for run it writes the
+// container id to the --cidfile if there is one, then prints the output
+// and exits with the exit code
+const fakeRuntime = `#!/bin/sh
+echo "$@" >> "$0.log"
+if [ "$1" = "run" ]; then
+  if [ -n "%[1]s" ]; then echo "%[1]s" > "$3"; fi
+  echo '%[2]s' >&2
+  exit %[3]d
+fi
+`
+
+func TestCreateContainer(t *testing.T) {
+	t.Parallel()
+	ctx := WithRetryPolicy(context.Background(), RetryPolicy{Attempts: 2, Backoff: time.Millisecond})
+	cases := []struct {
+		Name        string
+		Create      string
+		Output      string
+		Exit        int
+		ExpectError bool
+		ExpectLog   []string
+	}{
+		{
+			Name:      "success",
+			Create:    "abc123",
+			ExpectLog: []string{"run --cidfile CIDFILE --name foo image"},
+		},
+		{
+			Name:        "name conflict is not retried or removed",
+			Output:      `docker: Error response from daemon: Conflict. The container name "/foo" is already in use by container "def456".`,
+			Exit:        125,
+			ExpectError: true,
+			ExpectLog:   []string{"run --cidfile CIDFILE --name foo image"},
+		},
+		{
+			Name:        "created container is removed by id and retried",
+			Create:      "abc123",
+			Output:      "Error response from daemon: failed to start",
+			Exit:        125,
+			ExpectError: true,
+			ExpectLog: []string{
+				"run --cidfile CIDFILE --name foo image",
+				"rm -f -v abc123",
+				"run --cidfile CIDFILE --name foo image",
+				"rm -f -v abc123",
+			},
+		},
+		{
+			Name:        "nothing is removed when nothing was created",
+			Output:      "Error response from daemon: pull failed",
+			Exit:        125,
+			ExpectError: true,
+			ExpectLog: []string{
+				"run --cidfile CIDFILE --name foo image",
+				"run --cidfile CIDFILE --name foo image",
+			},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			dir, err := ioutil.TempDir("", "kind-container-test")
+			if err != nil {
+				t.Fatalf("failed to create temp dir: %v", err)
+			}
+			defer os.RemoveAll(dir)
+			binary := filepath.Join(dir, "runtime")
+			script := []byte(fmt.Sprintf(fakeRuntime, tc.Create, tc.Output, tc.Exit))
+			if err := ioutil.WriteFile(binary, script, 0755); err != nil {
+				t.Fatalf("failed to write fake runtime: %v", err)
+			}
+
+			err = CreateContainer(ctx, log.NoopLogger{}, binary, "foo", []string{"run", "--name", "foo", "image"})
+			assert.ExpectError(t, tc.ExpectError, err)
+			raw, err := ioutil.ReadFile(binary + ".log")
+			if err != nil {
+				t.Fatalf("failed to read fake runtime log: %v", err)
+			}
+			lines := strings.Split(strings.TrimSpace(string(raw)), "\n")
+			for i, line := range lines {
+				// the cidfile is in a new temp dir each attempt
+				fields := strings.Fields(line)
+				if len(fields) > 2 && fields[1] == "--cidfile" {
+					fields[2] = "CIDFILE"
+				}
+				lines[i] = strings.Join(fields, " ")
+			}
+			assert.DeepEqual(t, tc.ExpectLog, lines)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// EtcdDataDir is the etcd data dir kubeadm uses on the control plane nodes
+const EtcdDataDir = "/var/lib/etcd"
+
+// EtcdDataArgs returns the run args placing the etcd data dir of the control
+// plane node name on cfg.Etcd.DataVolume, if set. Each node gets its own
+// volume, or host directory which is created if missing. Both must not hold
+// data already, etcd would otherwise start with the data of an old cluster
+// and the node would fail to join, volumeExists reports existing volumes.
+func EtcdDataArgs(cfg *config.Cluster, node *config.Node, name string, volumeExists func(name string) (bool, error)) ([]string, error) {
+	volume := cfg.Etcd.DataVolume
+	if volume == "" || node.Role != config.ControlPlaneRole {
+		return nil, nil
+	}
+	source := volume + "-" + name
+	if filepath.IsAbs(volume) {
+		source = filepath.Join(volume, name)
+		// etcd expects its data dir to only be accessible by its user
+		if err := os.MkdirAll(source, 0700); err != nil {
+			return nil, errors.Wrap(err, "failed to create the etcd data dir")
+		}
+		entries, err := ioutil.ReadDir(source)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read the etcd data dir")
+		}
+		if len(entries) > 0 {
+			return nil, errors.Errorf("etcd data dir %s is not empty, remove the data of the old cluster first", source)
+		}
+	} else {
+		exists, err := volumeExists(source)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to check for the etcd data volume")
+		}
+		if exists {
+			return nil, errors.Errorf("etcd data volume %s already exists, remove the volume of the old cluster first", source)
+		}
+	}
+	return []string{"--volume", fmt.Sprintf("%s:%s", source, EtcdDataDir)}, nil
+}
+
+// VolumeExists returns true if the container runtime binary has a volume
+// called name
+func VolumeExists(ctx context.Context, binary, name string) (bool, error) {
+	lines, err := exec.OutputLines(exec.CommandContext(ctx, binary, "volume", "ls", "--quiet", "--filter", "name="+name))
+	if err != nil {
+		return false, err
+	}
+	// the name filter also matches substrings
+	for _, line := range lines {
+		if line == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
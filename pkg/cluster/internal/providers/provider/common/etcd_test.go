@@ -0,0 +1,108 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestEtcdDataArgs(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "etcd-data")
+	if err != nil {
+		t.Fatalf("failed to create tempdir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	controlPlane := &config.Node{Role: config.ControlPlaneRole}
+	worker := &config.Node{Role: config.WorkerRole}
+	// an old cluster left data in this one
+	used, err := ioutil.TempDir("", "etcd-data")
+	if err != nil {
+		t.Fatalf("failed to create tempdir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(used) })
+	if err := os.MkdirAll(filepath.Join(used, "kind-control-plane", "member"), 0700); err != nil {
+		t.Fatalf("failed to create etcd data: %v", err)
+	}
+	volumeExists := func(name string) (bool, error) {
+		return name == "used-kind-control-plane", nil
+	}
+	cases := []struct {
+		Name          string
+		DataVolume    string
+		Node          *config.Node
+		Expected      []string
+		ExpectedError bool
+	}{
+		{
+			Name:     "not set",
+			Node:     controlPlane,
+			Expected: nil,
+		},
+		{
+			Name:       "named volume per node",
+			DataVolume: "etcd",
+			Node:       controlPlane,
+			Expected:   []string{"--volume", "etcd-kind-control-plane:/var/lib/etcd"},
+		},
+		{
+			Name:       "host directory per node",
+			DataVolume: dir,
+			Node:       controlPlane,
+			Expected:   []string{"--volume", filepath.Join(dir, "kind-control-plane") + ":/var/lib/etcd"},
+		},
+		{
+			Name:          "existing named volume",
+			DataVolume:    "used",
+			Node:          controlPlane,
+			ExpectedError: true,
+		},
+		{
+			Name:          "host directory with data",
+			DataVolume:    used,
+			Node:          controlPlane,
+			ExpectedError: true,
+		},
+		{
+			Name:       "workers have no etcd",
+			DataVolume: "etcd",
+			Node:       worker,
+			Expected:   nil,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			cfg := &config.Cluster{Etcd: config.Etcd{DataVolume: tc.DataVolume}}
+			args, err := EtcdDataArgs(cfg, tc.Node, "kind-control-plane", volumeExists)
+			assert.ExpectError(t, tc.ExpectedError, err)
+			assert.DeepEqual(t, tc.Expected, args)
+			if tc.DataVolume == dir {
+				if info, err := os.Stat(filepath.Join(dir, "kind-control-plane")); err != nil || !info.IsDir() {
+					t.Errorf("expected the etcd data dir to be created, got: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -18,6 +18,11 @@ package common
 
 import (
 	"net"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
 )
 
 // PortOrGetFreePort is a helper that either returns the provided port
@@ -45,3 +50,35 @@ func GetFreePort(listenAddr string) (int32, error) {
 	port := dummyListener.Addr().(*net.TCPAddr).Port
 	return int32(port), nil
 }
+
+// IsPortFree returns true if port can currently be bound on listenAddr
+func IsPortFree(port int32, listenAddr string) bool {
+	l, err := net.Listen("tcp", net.JoinHostPort(listenAddr, strconv.Itoa(int(port))))
+	if err != nil {
+		return false
+	}
+	l.Close()
+	return true
+}
+
+// portConflictMessages are substrings of container runtime output
+// indicating that a host port is already in use
+var portConflictMessages = []string{
+	"port is already allocated",
+	"address already in use",
+}
+
+// WithPortConflictReason marks err with errors.ErrPortConflict if err is
+// from a container runtime failing to bind a host port
+func WithPortConflictReason(err error) error {
+	runErr := exec.RunErrorForError(err)
+	if runErr == nil {
+		return err
+	}
+	for _, msg := range portConflictMessages {
+		if strings.Contains(string(runErr.Output), msg) {
+			return errors.WithReason(err, errors.ErrPortConflict)
+		}
+	}
+	return err
+}
@@ -16,7 +16,10 @@ limitations under the License.
 
 package common
 
-import "testing"
+import (
+	"net"
+	"testing"
+)
 
 func TestPortOrGetFreePort(t *testing.T) {
 	t.Parallel()
@@ -95,3 +98,19 @@ func TestGetFreePort(t *testing.T) {
 		})
 	}
 }
+
+func TestIsPortFree(t *testing.T) {
+	t.Parallel()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	port := int32(l.Addr().(*net.TCPAddr).Port)
+	if IsPortFree(port, "127.0.0.1") {
+		t.Errorf("IsPortFree() = true for bound port %d", port)
+	}
+	l.Close()
+	if !IsPortFree(port, "127.0.0.1") {
+		t.Errorf("IsPortFree() = false for released port %d", port)
+	}
+}
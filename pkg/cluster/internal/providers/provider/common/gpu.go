@@ -0,0 +1,80 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// nvidiaToolkitPaths are the host paths of the NVIDIA container toolkit,
+// which containerd inside of the node needs to expose GPUs to pods
+var nvidiaToolkitPaths = []string{
+	"/usr/bin/nvidia-container-runtime",
+	"/usr/bin/nvidia-container-runtime-hook",
+	"/usr/bin/nvidia-container-cli",
+	"/usr/bin/nvidia-ctk",
+	"/etc/nvidia-container-runtime",
+	"/usr/lib/*-linux-gnu/libnvidia-container*.so*",
+	"/usr/lib64/libnvidia-container*.so*",
+}
+
+// NVIDIAToolkitMounts returns read-only mounts for the NVIDIA container
+// toolkit paths present on the host
+func NVIDIAToolkitMounts() []config.Mount {
+	var mounts []config.Mount
+	for _, pattern := range nvidiaToolkitPaths {
+		matches, _ := filepath.Glob(pattern)
+		for _, path := range matches {
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+			mounts = append(mounts, config.Mount{
+				HostPath:      path,
+				ContainerPath: path,
+				Readonly:      true,
+			})
+		}
+	}
+	return mounts
+}
+
+// CDIDevices converts a node's gpus field to NVIDIA CDI device names
+func CDIDevices(gpus string) []string {
+	const prefix = "nvidia.com/gpu="
+	if gpus == "all" {
+		return []string{prefix + "all"}
+	}
+	if strings.HasPrefix(gpus, "device=") {
+		ids := strings.Split(strings.TrimPrefix(gpus, "device="), ",")
+		devices := make([]string, len(ids))
+		for i, id := range ids {
+			devices[i] = prefix + id
+		}
+		return devices
+	}
+	count, _ := strconv.Atoi(gpus)
+	devices := make([]string, count)
+	for i := range devices {
+		devices[i] = prefix + strconv.Itoa(i)
+	}
+	return devices
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCDIDevices(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		gpus     string
+		expected []string
+	}{
+		{
+			gpus:     "all",
+			expected: []string{"nvidia.com/gpu=all"},
+		},
+		{
+			gpus:     "2",
+			expected: []string{"nvidia.com/gpu=0", "nvidia.com/gpu=1"},
+		},
+		{
+			gpus:     "device=1,GPU-abc",
+			expected: []string{"nvidia.com/gpu=1", "nvidia.com/gpu=GPU-abc"},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.gpus, func(t *testing.T) {
+			t.Parallel()
+			if result := CDIDevices(tc.gpus); !reflect.DeepEqual(result, tc.expected) {
+				t.Errorf("expected %v but got %v", tc.expected, result)
+			}
+		})
+	}
+}
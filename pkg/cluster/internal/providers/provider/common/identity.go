@@ -0,0 +1,55 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// NodeHostname returns the hostname of the node container named name
+func NodeHostname(node *config.Node, name string) string {
+	if node.Hostname != "" {
+		return node.Hostname
+	}
+	return name
+}
+
+// KubernetesNodeName returns the name node is registered with in Kubernetes,
+// which is its hostname, given the config the cluster was created from.
+// If cfg is nil or node is not from cfg this is the node container name
+func KubernetesNodeName(cfg *config.Cluster, node nodes.Node) string {
+	if cfg == nil {
+		return node.String()
+	}
+	for i, name := range config.NodeNames(cfg) {
+		if name == node.String() {
+			return NodeHostname(&cfg.Nodes[i], name)
+		}
+	}
+	return node.String()
+}
+
+// NodeIdentityArgs returns the container run arguments for the node's
+// machine-id, these are shared by docker and podman.
+// The node image entrypoint writes KIND_MACHINE_ID to /etc/machine-id.
+func NodeIdentityArgs(node *config.Node) []string {
+	if node.MachineID == "" {
+		return nil
+	}
+	return []string{"-e", "KIND_MACHINE_ID=" + node.MachineID}
+}
@@ -17,8 +17,13 @@ limitations under the License.
 package common
 
 import (
+	"regexp"
+	"sort"
+	"strings"
+
 	"k8s.io/apimachinery/pkg/util/sets"
 
+	"sigs.k8s.io/kind/pkg/cluster/internal/loadbalancer"
 	"sigs.k8s.io/kind/pkg/internal/apis/config"
 )
 
@@ -32,3 +37,39 @@ func RequiredNodeImages(cfg *config.Cluster) sets.String {
 	}
 	return images
 }
+
+// LoadBalancerImage returns the load balancer image for the config
+func LoadBalancerImage(cfg *config.Cluster) string {
+	if cfg.Images.LoadBalancer != "" {
+		return cfg.Images.LoadBalancer
+	}
+	return loadbalancer.ImageFor(cfg.LoadBalancer.Implementation)
+}
+
+// OverrideImage returns the images.overrides image of cfg for image, or image
+// if it is not overridden
+func OverrideImage(cfg *config.Cluster, image string) string {
+	if override, ok := cfg.Images.Overrides[image]; ok {
+		return override
+	}
+	return image
+}
+
+// OverrideManifestImages returns manifest with the images overridden by cfg
+// replaced, wherever they appear as a whole value, e.g. in an image field or
+// a command line argument
+func OverrideManifestImages(cfg *config.Cluster, manifest string) string {
+	if len(cfg.Images.Overrides) == 0 {
+		return manifest
+	}
+	images := make([]string, 0, len(cfg.Images.Overrides))
+	for image := range cfg.Images.Overrides {
+		images = append(images, regexp.QuoteMeta(image))
+	}
+	sort.Strings(images)
+	re := regexp.MustCompile(`(?m)(^|[\s"'])(` + strings.Join(images, "|") + `)([\s"']|$)`)
+	return re.ReplaceAllStringFunc(manifest, func(match string) string {
+		m := re.FindStringSubmatch(match)
+		return m[1] + cfg.Images.Overrides[m[2]] + m[3]
+	})
+}
@@ -66,3 +66,28 @@ func TestRequiredNodeImages(t *testing.T) {
 		})
 	}
 }
+
+func TestOverrideManifestImages(t *testing.T) {
+	t.Parallel()
+	cfg := &config.Cluster{Images: config.Images{Overrides: map[string]string{
+		"kindest/kindnetd:v1":   "registry.example/kindnetd:v1",
+		"debian-base:v2.1.0":    "registry.example/debian-base:v2.1.0",
+		"registry.example/a:v1": "registry.example/b:v1",
+	}}}
+	manifest := `image: kindest/kindnetd:v1
+        image: "kindest/kindnetd:v1.2"
+        - --helper-image
+        - debian-base:v2.1.0
+        - image: 'registry.example/a:v1'`
+	want := `image: registry.example/kindnetd:v1
+        image: "kindest/kindnetd:v1.2"
+        - --helper-image
+        - registry.example/debian-base:v2.1.0
+        - image: 'registry.example/b:v1'`
+	if got := OverrideManifestImages(cfg, manifest); got != want {
+		t.Errorf("OverrideManifestImages() = %q, want %q", got, want)
+	}
+	if got := OverrideImage(cfg, "kindest/kindnetd:v1.2"); got != "kindest/kindnetd:v1.2" {
+		t.Errorf("OverrideImage() = %q, want the image", got)
+	}
+}
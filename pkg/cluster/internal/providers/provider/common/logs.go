@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"sigs.k8s.io/kind/pkg/cluster/internal/logs"
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/exec"
@@ -11,7 +12,7 @@ import (
 
 // CollectLogs provides the common functionality
 // to get various debug info from the node
-func CollectLogs(n nodes.Node, dir string) error {
+func CollectLogs(n nodes.Node, dir string, opts *logs.Options) error {
 	execToPathFn := func(cmd exec.Cmd, path string) func() error {
 		return func() error {
 			f, err := FileOnHost(filepath.Join(dir, path))
@@ -22,25 +23,56 @@ func CollectLogs(n nodes.Node, dir string) error {
 			return cmd.SetStdout(f).SetStderr(f).Run()
 		}
 	}
-	return errors.AggregateConcurrent([]func() error{
+	// bestEffortFn runs script in a shell on the node, ignoring failures
+	// since these tools and files are not present in every node image
+	bestEffortFn := func(script, path string) func() error {
+		return execToPathFn(n.Command("sh", "-c", script+" || true"), path)
+	}
+	fns := []func() error{
 		// record info about the node container
 		execToPathFn(
 			n.Command("cat", "/kind/version"),
 			"kubernetes-version.txt",
 		),
-		execToPathFn(
-			n.Command("journalctl", "--no-pager"),
+	}
+	if opts.Has(logs.ComponentJournal) {
+		fns = append(fns, execToPathFn(
+			n.Command("journalctl", opts.JournalArgs()...),
 			"journal.log",
-		),
-		execToPathFn(
-			n.Command("journalctl", "--no-pager", "-u", "kubelet.service"),
+		))
+	}
+	if opts.Has(logs.ComponentKubelet) {
+		fns = append(fns, execToPathFn(
+			n.Command("journalctl", opts.JournalArgs("-u", "kubelet.service")...),
 			"kubelet.log",
-		),
-		execToPathFn(
-			n.Command("journalctl", "--no-pager", "-u", "containerd.service"),
+		))
+	}
+	if opts.Has(logs.ComponentContainerd) {
+		fns = append(fns, execToPathFn(
+			n.Command("journalctl", opts.JournalArgs("-u", "containerd.service")...),
 			"containerd.log",
-		),
-	})
+		))
+	}
+	if opts.Has(logs.ComponentAudit) {
+		fns = append(fns, execToPathFn(
+			n.Command("journalctl", opts.JournalArgs("_TRANSPORT=audit")...),
+			"audit.log",
+		))
+	}
+	if opts.Has(logs.ComponentNetwork) {
+		fns = append(fns,
+			bestEffortFn("iptables-save", "iptables.txt"),
+			bestEffortFn("ip6tables-save", "ip6tables.txt"),
+			bestEffortFn("nft list ruleset", "nftables.txt"),
+		)
+	}
+	if opts.Has(logs.ComponentConfig) {
+		fns = append(fns,
+			bestEffortFn("cat /etc/containerd/config.toml", "containerd-config.toml"),
+			bestEffortFn("cat /kind/kubeadm.conf", "kubeadm.conf"),
+		)
+	}
+	return errors.AggregateConcurrent(fns)
 }
 
 // FileOnHost is a helper to create a file at path
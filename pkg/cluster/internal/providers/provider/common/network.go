@@ -0,0 +1,59 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"encoding/json"
+
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
+)
+
+// inspectedNetwork is the subset of docker / podman network inspect output we need
+type inspectedNetwork struct {
+	Name string
+	IPAM struct {
+		Config []struct {
+			Subnet  string
+			Gateway string
+		}
+	}
+}
+
+// ParseNetworkInspect returns the network name and subnets from the docker
+// compatible `network inspect` output for a single network
+func ParseNetworkInspect(inspect []byte) (*provider.NetworkInfo, error) {
+	networks := []inspectedNetwork{}
+	if err := json.Unmarshal(inspect, &networks); err != nil {
+		return nil, errors.Wrap(err, "failed to decode network details")
+	}
+	if len(networks) != 1 {
+		return nil, errors.Errorf("expected details of one network, got %d", len(networks))
+	}
+	info := &provider.NetworkInfo{
+		Name:    networks[0].Name,
+		Subnets: []provider.NetworkSubnet{},
+	}
+	for _, c := range networks[0].IPAM.Config {
+		info.Subnets = append(info.Subnets, provider.NetworkSubnet{
+			Subnet:  c.Subnet,
+			Gateway: c.Gateway,
+		})
+	}
+	return info, nil
+}
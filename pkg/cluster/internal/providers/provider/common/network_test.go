@@ -0,0 +1,79 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
+)
+
+func TestParseNetworkInspect(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name        string
+		Inspect     string
+		Expected    *provider.NetworkInfo
+		ExpectError bool
+	}{
+		{
+			Name: "dual stack",
+			Inspect: `[{"Name": "kind", "Driver": "bridge", "IPAM": {"Driver": "default", "Config": [
+				{"Subnet": "172.18.0.0/16", "Gateway": "172.18.0.1"},
+				{"Subnet": "fc00:f853:ccd:e793::/64", "Gateway": "fc00:f853:ccd:e793::1"}
+			]}}]`,
+			Expected: &provider.NetworkInfo{
+				Name: "kind",
+				Subnets: []provider.NetworkSubnet{
+					{Subnet: "172.18.0.0/16", Gateway: "172.18.0.1"},
+					{Subnet: "fc00:f853:ccd:e793::/64", Gateway: "fc00:f853:ccd:e793::1"},
+				},
+			},
+		},
+		{
+			Name:    "no subnets",
+			Inspect: `[{"Name": "kind", "IPAM": {"Config": null}}]`,
+			Expected: &provider.NetworkInfo{
+				Name:    "kind",
+				Subnets: []provider.NetworkSubnet{},
+			},
+		},
+		{
+			Name:        "no network",
+			Inspect:     `[]`,
+			ExpectError: true,
+		},
+		{
+			Name:        "invalid",
+			Inspect:     `Error: No such network: kind`,
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			info, err := ParseNetworkInspect([]byte(tc.Inspect))
+			assert.ExpectError(t, tc.ExpectError, err)
+			if err == nil {
+				assert.DeepEqual(t, tc.Expected, info)
+			}
+		})
+	}
+}
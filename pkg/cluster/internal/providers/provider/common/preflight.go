@@ -0,0 +1,434 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/warnings"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+// MinFreeDiskSpace is the free space below which node creation will fail
+// for lack of room for the node container layers and images
+const MinFreeDiskSpace = 1 << 30
+
+// PreflightInfo is what a node provider knows about its host for Preflight,
+// zero values skip the corresponding checks
+type PreflightInfo struct {
+	// Architecture is the container runtime host architecture, e.g. "amd64"
+	Architecture string
+	// ImageArchitectures maps each node image to its architecture
+	ImageArchitectures map[string]string
+	// Emulation is true if the runtime can run images for other architectures
+	Emulation bool
+	// NetworkSubnets are the subnets of the network the nodes will join
+	NetworkSubnets []string
+	// StoragePath is the container runtime storage directory on the host
+	StoragePath string
+	// StorageDriver is the container runtime storage driver, e.g. "overlay2"
+	StorageDriver string
+	// DockerDesktop is true if the container runtime runs in a Docker
+	// Desktop VM
+	DockerDesktop bool
+	// VM is the VM the container runtime runs in, if any
+	VM *VM
+	// Rootless is true if the container runtime runs without root
+	Rootless bool
+	// Remote is true if the container runtime is on another host,
+	// host ports and disk space can't be checked from here then
+	Remote bool
+}
+
+// Preflight checks that the host can run cfg, before any nodes are created,
+// so that creation fails fast with actionable messages instead of midway.
+// Problems that are only likely to cause trouble are reported as warnings.
+func Preflight(ctx context.Context, logger log.Logger, cfg *config.Cluster, info *PreflightInfo) error {
+	errs := []error{}
+	if !info.Remote {
+		errs = append(errs, checkHostPorts(cfg, IsPortFree, isUDPPortFree)...)
+	}
+	errs = append(errs, checkArchitectures(info, hasBinfmtEmulation)...)
+	errs = append(errs, checkSubnets(cfg, info.NetworkSubnets)...)
+	errs = append(errs, checkVM(cfg, info.VM, filepath.Abs)...)
+	if !info.Remote {
+		if err := checkDiskSpace(info.StoragePath); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := checkDiskQuota(cfg, info); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkSwap(cfg, info); err != nil {
+		errs = append(errs, err)
+	}
+	errs = append(errs, checkInotify(cfg, info, readSysctlInt)...)
+	ws := selinuxRelabelWarnings(cfg)
+	ws = append(ws, hugepagesWarnings(cfg)...)
+	ws = append(ws, inotifyWarnings(cfg, readSysctlInt)...)
+	ws = append(ws, runtimeWarnings(cfg, info)...)
+	if err := warnings.Report(ctx, logger, ws...); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	// keep the reason of the first failure that has one, e.g. a port conflict
+	err := errors.Wrap(errors.NewAggregate(errs), "preflight checks failed")
+	for _, e := range errs {
+		if r := errors.ReasonForError(e); r != nil {
+			return errors.WithReason(err, r)
+		}
+	}
+	return err
+}
+
+// checkHostPorts checks every host port requested by cfg is free and
+// requested only once
+func checkHostPorts(cfg *config.Cluster, tcpFree, udpFree func(int32, string) bool) []error {
+	type binding struct {
+		address  string
+		port     int32
+		protocol string
+	}
+	bindings := []binding{}
+	if cfg.Networking.APIServerPort > 0 {
+		bindings = append(bindings, binding{cfg.Networking.APIServerAddress, cfg.Networking.APIServerPort, "TCP"})
+		for _, address := range cfg.Networking.AdditionalAPIServerAddresses {
+			bindings = append(bindings, binding{address, cfg.Networking.APIServerPort, "TCP"})
+		}
+	}
+	for _, n := range cfg.Nodes {
+		for _, pm := range n.ExtraPortMappings {
+			if pm.HostPort <= 0 {
+				continue
+			}
+			protocol := string(pm.Protocol)
+			if protocol == "" {
+				protocol = string(config.PortMappingProtocolTCP)
+			}
+			bindings = append(bindings, binding{pm.ListenAddress, pm.HostPort, protocol})
+		}
+	}
+
+	errs := []error{}
+	seen := map[binding]bool{}
+	for _, b := range bindings {
+		if seen[b] {
+			errs = append(errs, errors.Errorf("host port %s/%s is requested more than once", net.JoinHostPort(b.address, strconv.Itoa(int(b.port))), b.protocol))
+			continue
+		}
+		seen[b] = true
+		free := true
+		switch b.protocol {
+		case string(config.PortMappingProtocolTCP):
+			free = tcpFree(b.port, b.address)
+		case string(config.PortMappingProtocolUDP):
+			free = udpFree(b.port, b.address)
+		}
+		if !free {
+			errs = append(errs, errors.WithReason(
+				errors.Errorf("host port %s/%s is already in use, free it or pick another port (0 picks a free one)", net.JoinHostPort(b.address, strconv.Itoa(int(b.port))), b.protocol),
+				errors.ErrPortConflict,
+			))
+		}
+	}
+	return errs
+}
+
+// isUDPPortFree returns true if the UDP port can currently be bound on listenAddr
+func isUDPPortFree(port int32, listenAddr string) bool {
+	c, err := net.ListenPacket("udp", net.JoinHostPort(listenAddr, strconv.Itoa(int(port))))
+	if err != nil {
+		return false
+	}
+	c.Close()
+	return true
+}
+
+// checkArchitectures checks the node images can run on the runtime host
+func checkArchitectures(info *PreflightInfo, emulated func(arch string) bool) []error {
+	if info.Architecture == "" {
+		return nil
+	}
+	host := NormalizeArchitecture(info.Architecture)
+	errs := []error{}
+	for image, arch := range info.ImageArchitectures {
+		arch = NormalizeArchitecture(arch)
+		if arch == "" || arch == host || info.Emulation || emulated(arch) {
+			continue
+		}
+		errs = append(errs, errors.Errorf(
+			"node image %q is for %s but the host is %s, use a %s image or enable emulation for %s (e.g. with qemu-user-static binfmt_misc handlers)",
+			image, arch, host, host, arch,
+		))
+	}
+	return errs
+}
+
+// NormalizeArchitecture converts uname style architectures to GOARCH style,
+// e.g. "x86_64" to "amd64"
+func NormalizeArchitecture(arch string) string {
+	switch arch {
+	case "x86_64":
+		return "amd64"
+	case "aarch64":
+		return "arm64"
+	case "armv7l", "armhf":
+		return "arm"
+	}
+	return arch
+}
+
+// hasBinfmtEmulation returns true if the local kernel has a qemu
+// binfmt_misc handler registered for the GOARCH style arch
+func hasBinfmtEmulation(arch string) bool {
+	qemuArch := map[string]string{
+		"amd64": "x86_64",
+		"arm64": "aarch64",
+	}[arch]
+	if qemuArch == "" {
+		qemuArch = arch
+	}
+	_, err := os.Stat("/proc/sys/fs/binfmt_misc/qemu-" + qemuArch)
+	return err == nil
+}
+
+// checkSubnets checks the pod and service subnets don't overlap each other
+// or the network the nodes are on, otherwise traffic would be misrouted
+func checkSubnets(cfg *config.Cluster, networkSubnets []string) []error {
+	errs := []error{}
+	if overlaps(cfg.Networking.PodSubnet, cfg.Networking.ServiceSubnet) {
+		errs = append(errs, errors.Errorf(
+			"networking.podSubnet %s overlaps networking.serviceSubnet %s, they must not collide",
+			cfg.Networking.PodSubnet, cfg.Networking.ServiceSubnet,
+		))
+	}
+	for _, subnet := range networkSubnets {
+		for _, s := range []struct {
+			field string
+			value string
+		}{
+			{"podSubnet", cfg.Networking.PodSubnet},
+			{"serviceSubnet", cfg.Networking.ServiceSubnet},
+		} {
+			if overlaps(subnet, s.value) {
+				errs = append(errs, errors.Errorf(
+					"networking.%s %s overlaps the node network subnet %s, pick a %s that does not collide",
+					s.field, s.value, subnet, s.field,
+				))
+			}
+		}
+	}
+	return errs
+}
+
+// overlaps returns true if the CIDRs a and b overlap, invalid CIDRs never do
+func overlaps(a, b string) bool {
+	_, aNet, err := net.ParseCIDR(a)
+	if err != nil {
+		return false
+	}
+	_, bNet, err := net.ParseCIDR(b)
+	if err != nil {
+		return false
+	}
+	return aNet.Contains(bNet.IP) || bNet.Contains(aNet.IP)
+}
+
+// checkDiskQuota checks the container runtime can create the cluster disk
+// for a diskQuota, mounting a loop device requires root
+func checkDiskQuota(cfg *config.Cluster, info *PreflightInfo) error {
+	if cfg.DiskQuota == "" || !info.Rootless {
+		return nil
+	}
+	return errors.New("diskQuota requires a rootful container runtime, the cluster disk is a loop device")
+}
+
+// checkSwap checks the container runtime can enable swap on the nodes,
+// swapon requires root on the host
+func checkSwap(cfg *config.Cluster, info *PreflightInfo) error {
+	if !info.Rootless {
+		return nil
+	}
+	for _, n := range cfg.Nodes {
+		if n.Swap.Size != "" {
+			return errors.New("swap requires a rootful container runtime, the swapfile is enabled on the host kernel")
+		}
+	}
+	return nil
+}
+
+// checkDiskSpace checks the container runtime storage has room for the nodes,
+// this is skipped if the storage is not on this host
+func checkDiskSpace(path string) error {
+	if path == "" {
+		return nil
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	free, err := freeDiskSpace(path)
+	if err != nil {
+		return nil
+	}
+	if free < MinFreeDiskSpace {
+		return errors.Errorf(
+			"only %dMiB of disk space is free for container storage at %s, at least %dMiB is needed, try pruning unused images and containers",
+			free>>20, path, MinFreeDiskSpace>>20,
+		)
+	}
+	return nil
+}
+
+// freeDiskSpace returns the bytes available at path as reported by df
+func freeDiskSpace(path string) (int64, error) {
+	lines, err := exec.OutputLines(exec.Command("df", "-Pk", path))
+	if err != nil {
+		return 0, err
+	}
+	return parseDfAvailable(lines)
+}
+
+// parseDfAvailable parses the available space from POSIX `df -Pk` output
+func parseDfAvailable(lines []string) (int64, error) {
+	if len(lines) < 2 {
+		return 0, errors.Errorf("unexpected df output: %q", lines)
+	}
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) < 4 {
+		return 0, errors.Errorf("unexpected df output: %q", lines)
+	}
+	kb, err := strconv.ParseInt(fields[3], 10, 64)
+	if err != nil {
+		return 0, errors.Wrap(err, "unexpected df output")
+	}
+	return kb << 10, nil
+}
+
+// recommended inotify limits, see the known issues documentation
+const (
+	recommendedMaxUserWatches   = 524288
+	recommendedMaxUserInstances = 512
+)
+
+// inotifyLimit is a host fs.inotify limit
+type inotifyLimit struct {
+	name        string
+	field       string
+	recommended int
+	configured  int
+}
+
+// inotifyLimits returns the host inotify limits with the values cfg raises
+// them to, if any
+func inotifyLimits(cfg *config.Cluster) []inotifyLimit {
+	return []inotifyLimit{
+		{"max_user_watches", "maxUserWatches", recommendedMaxUserWatches, int(cfg.Inotify.MaxUserWatches)},
+		{"max_user_instances", "maxUserInstances", recommendedMaxUserInstances, int(cfg.Inotify.MaxUserInstances)},
+	}
+}
+
+// checkInotify checks the host inotify limits can be raised to the values
+// in cfg, a rootless container runtime cannot write them
+func checkInotify(cfg *config.Cluster, info *PreflightInfo, readInt func(path string) (int, error)) []error {
+	if !info.Rootless || info.Remote {
+		return nil
+	}
+	errs := []error{}
+	for _, limit := range inotifyLimits(cfg) {
+		value, err := readInt(fmt.Sprintf("/proc/sys/fs/inotify/%s", limit.name))
+		if err != nil || value >= limit.configured {
+			continue
+		}
+		errs = append(errs, errors.Errorf(
+			"fs.inotify.%s is %d, below inotify.%s: %d, a rootless container runtime cannot raise it, run `sudo sysctl fs.inotify.%s=%d` first",
+			limit.name, value, limit.field, limit.configured, limit.name, limit.configured,
+		))
+	}
+	return errs
+}
+
+// inotifyWarnings warns if the host inotify limits are low enough that
+// the kubelets and pods may fail with "too many open files", this is shared
+// by all nodes so only multi-node clusters are likely to run out
+func inotifyWarnings(cfg *config.Cluster, readInt func(path string) (int, error)) []warnings.Warning {
+	if len(cfg.Nodes) < 2 {
+		return nil
+	}
+	ws := []warnings.Warning{}
+	for _, limit := range inotifyLimits(cfg) {
+		value, err := readInt(fmt.Sprintf("/proc/sys/fs/inotify/%s", limit.name))
+		if err != nil {
+			continue
+		}
+		// the limit is raised while creating the cluster
+		if value < limit.configured {
+			value = limit.configured
+		}
+		if value < limit.recommended {
+			ws = append(ws, preflightWarning(
+				"fs.inotify.%s is %d, clusters with %d nodes may fail with \"too many open files\", consider `sysctl fs.inotify.%s=%d`",
+				limit.name, value, len(cfg.Nodes), limit.name, limit.recommended,
+			))
+		}
+	}
+	return ws
+}
+
+// runtimeWarnings warns about container runtime setups that are known to
+// work poorly for cfg
+func runtimeWarnings(cfg *config.Cluster, info *PreflightInfo) []warnings.Warning {
+	ws := []warnings.Warning{}
+	switch driver := strings.ToLower(info.StorageDriver); driver {
+	case "btrfs", "zfs":
+		ws = append(ws, preflightWarning(
+			"the container runtime storage driver is %s, which is known to be slow for the node containers, overlay2 is recommended",
+			driver,
+		))
+	}
+	if info.DockerDesktop && cfg.Networking.IPFamily == config.IPv6Family {
+		ws = append(ws, preflightWarning(
+			"networking.ipFamily %s is not supported by Docker Desktop, which does not route IPv6 to containers, the cluster is unlikely to work",
+			cfg.Networking.IPFamily,
+		))
+	}
+	return ws
+}
+
+// preflightWarning returns a preflight warning with the formatted message
+func preflightWarning(format string, args ...interface{}) warnings.Warning {
+	return warnings.Warning{Source: "preflight", Message: fmt.Sprintf(format, args...)}
+}
+
+func readSysctlInt(path string) (int, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(raw)))
+}
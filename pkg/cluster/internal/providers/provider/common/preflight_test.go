@@ -0,0 +1,206 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestCheckHostPorts(t *testing.T) {
+	t.Parallel()
+	cfg := &config.Cluster{
+		Networking: config.Networking{
+			APIServerAddress: "127.0.0.1",
+			APIServerPort:    6443,
+		},
+		Nodes: []config.Node{{
+			ExtraPortMappings: []config.PortMapping{
+				{ListenAddress: "0.0.0.0", HostPort: 80},
+				{ListenAddress: "0.0.0.0", HostPort: 80, Protocol: config.PortMappingProtocolTCP},
+				{ListenAddress: "0.0.0.0", HostPort: 53, Protocol: config.PortMappingProtocolUDP},
+				{ListenAddress: "0.0.0.0", HostPort: -1},
+			},
+		}},
+	}
+	inUse := map[int32]bool{6443: true, 53: true}
+	free := func(port int32, _ string) bool { return !inUse[port] }
+	errs := checkHostPorts(cfg, free, free)
+	// api server in use, duplicate 80, udp 53 in use
+	if len(errs) != 3 {
+		t.Fatalf("expected 3 errors, got %v", errs)
+	}
+	assert.BoolEqual(t, true, errors.ReasonForError(errs[0]) == errors.ErrPortConflict)
+	assert.BoolEqual(t, true, errors.ReasonForError(errs[1]) == nil)
+}
+
+func TestCheckArchitectures(t *testing.T) {
+	t.Parallel()
+	info := &PreflightInfo{
+		Architecture: "x86_64",
+		ImageArchitectures: map[string]string{
+			"kindest/node:amd64": "amd64",
+			"kindest/node:arm64": "arm64",
+		},
+	}
+	noEmulation := func(string) bool { return false }
+	if errs := checkArchitectures(info, noEmulation); len(errs) != 1 {
+		t.Errorf("expected 1 error, got %v", errs)
+	}
+	if errs := checkArchitectures(info, func(arch string) bool { return arch == "arm64" }); len(errs) != 0 {
+		t.Errorf("expected 0 errors, got %v", errs)
+	}
+	info.Emulation = true
+	if errs := checkArchitectures(info, noEmulation); len(errs) != 0 {
+		t.Errorf("expected 0 errors, got %v", errs)
+	}
+}
+
+func TestCheckSubnets(t *testing.T) {
+	t.Parallel()
+	cfg := &config.Cluster{
+		Networking: config.Networking{
+			PodSubnet:     "172.18.0.0/16",
+			ServiceSubnet: "10.96.0.0/12",
+		},
+	}
+	if errs := checkSubnets(cfg, []string{"172.18.0.0/16", "fc00:f853:ccd:e793::/64"}); len(errs) != 1 {
+		t.Errorf("expected 1 error, got %v", errs)
+	}
+	if errs := checkSubnets(cfg, []string{"172.19.0.0/16"}); len(errs) != 0 {
+		t.Errorf("expected 0 errors, got %v", errs)
+	}
+	cfg.Networking.ServiceSubnet = "172.18.128.0/20"
+	if errs := checkSubnets(cfg, nil); len(errs) != 1 {
+		t.Errorf("expected 1 error, got %v", errs)
+	}
+}
+
+func TestCheckDiskQuota(t *testing.T) {
+	t.Parallel()
+	cfg := &config.Cluster{DiskQuota: "10Gi"}
+	assert.ExpectError(t, false, checkDiskQuota(cfg, &PreflightInfo{}))
+	assert.ExpectError(t, true, checkDiskQuota(cfg, &PreflightInfo{Rootless: true}))
+	assert.ExpectError(t, false, checkDiskQuota(&config.Cluster{}, &PreflightInfo{Rootless: true}))
+}
+
+func TestCheckSwap(t *testing.T) {
+	t.Parallel()
+	cfg := &config.Cluster{Nodes: []config.Node{{}, {Swap: config.Swap{Size: "1Gi"}}}}
+	assert.ExpectError(t, false, checkSwap(cfg, &PreflightInfo{}))
+	assert.ExpectError(t, true, checkSwap(cfg, &PreflightInfo{Rootless: true}))
+	assert.ExpectError(t, false, checkSwap(&config.Cluster{Nodes: []config.Node{{}}}, &PreflightInfo{Rootless: true}))
+}
+
+func TestParseDfAvailable(t *testing.T) {
+	t.Parallel()
+	free, err := parseDfAvailable([]string{
+		"Filesystem     1024-blocks      Used Available Capacity Mounted on",
+		"/dev/sda1        102687672  52018120  45409064      54% /",
+	})
+	assert.ExpectError(t, false, err)
+	if free != 45409064<<10 {
+		t.Errorf("expected %d bytes free, got %d", int64(45409064)<<10, free)
+	}
+	_, err = parseDfAvailable([]string{"garbage"})
+	assert.ExpectError(t, true, err)
+}
+
+func TestInotifyWarnings(t *testing.T) {
+	t.Parallel()
+	cfg := &config.Cluster{Nodes: []config.Node{{}, {}}}
+	limits := map[string]int{
+		"/proc/sys/fs/inotify/max_user_watches":   8192,
+		"/proc/sys/fs/inotify/max_user_instances": recommendedMaxUserInstances,
+	}
+	readInt := func(path string) (int, error) { return limits[path], nil }
+	ws := inotifyWarnings(cfg, readInt)
+	if len(ws) != 1 || !strings.Contains(ws[0].Message, "fs.inotify.max_user_watches is 8192") {
+		t.Errorf("expected a max_user_watches warning, got %v", ws)
+	}
+	// a single node is unlikely to run out
+	if ws := inotifyWarnings(&config.Cluster{Nodes: []config.Node{{}}}, readInt); len(ws) != 0 {
+		t.Errorf("expected no warnings for a single node, got %v", ws)
+	}
+	// the configured limits are raised
+	cfg.Inotify.MaxUserWatches = recommendedMaxUserWatches
+	if ws := inotifyWarnings(cfg, readInt); len(ws) != 0 {
+		t.Errorf("expected no warnings with inotify.maxUserWatches, got %v", ws)
+	}
+}
+
+func TestCheckInotify(t *testing.T) {
+	t.Parallel()
+	cfg := &config.Cluster{Inotify: config.Inotify{MaxUserWatches: recommendedMaxUserWatches}}
+	readInt := func(path string) (int, error) { return 8192, nil }
+	if errs := checkInotify(cfg, &PreflightInfo{Rootless: true}, readInt); len(errs) != 1 {
+		t.Errorf("expected 1 error, got %v", errs)
+	}
+	if errs := checkInotify(cfg, &PreflightInfo{}, readInt); len(errs) != 0 {
+		t.Errorf("expected no errors with a rootful runtime, got %v", errs)
+	}
+	if errs := checkInotify(&config.Cluster{}, &PreflightInfo{Rootless: true}, readInt); len(errs) != 0 {
+		t.Errorf("expected no errors without inotify limits, got %v", errs)
+	}
+}
+
+func TestRuntimeWarnings(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name     string
+		IPFamily config.ClusterIPFamily
+		Info     PreflightInfo
+		Expected int
+	}{
+		{
+			Name:     "overlay",
+			IPFamily: config.IPv6Family,
+			Info:     PreflightInfo{StorageDriver: "overlay2"},
+			Expected: 0,
+		},
+		{
+			Name:     "btrfs",
+			IPFamily: config.IPv4Family,
+			Info:     PreflightInfo{StorageDriver: "btrfs"},
+			Expected: 1,
+		},
+		{
+			Name:     "ipv6 on Docker Desktop",
+			IPFamily: config.IPv6Family,
+			Info:     PreflightInfo{StorageDriver: "zfs", DockerDesktop: true},
+			Expected: 2,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			cfg := &config.Cluster{Networking: config.Networking{IPFamily: tc.IPFamily}}
+			ws := runtimeWarnings(cfg, &tc.Info)
+			if len(ws) != tc.Expected {
+				t.Errorf("expected %d warnings, got %v", tc.Expected, ws)
+			}
+			for _, w := range ws {
+				assert.StringEqual(t, "preflight", w.Source)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/warnings"
+)
+
+// NodeResourceArgs returns the container run arguments for the node's
+// cgroupns, cpuset, hugepages and ulimits settings, these are shared by
+// docker and podman
+func NodeResourceArgs(node *config.Node) []string {
+	args := []string{}
+	if node.CgroupNS != "" {
+		args = append(args, "--cgroupns", node.CgroupNS)
+	}
+	if node.CPUSet != "" {
+		args = append(args, "--cpuset-cpus", node.CPUSet)
+	}
+	if len(node.Hugepages) > 0 {
+		args = append(args, "--volume", "/dev/hugepages:/dev/hugepages")
+	}
+	// sorted for stable args
+	ulimits := make([]string, 0, len(node.Ulimits))
+	for name := range node.Ulimits {
+		ulimits = append(ulimits, name)
+	}
+	sort.Strings(ulimits)
+	for _, name := range ulimits {
+		args = append(args, "--ulimit", fmt.Sprintf("%s=%s", name, node.Ulimits[name]))
+	}
+	return args
+}
+
+// hugepagesWarnings warns if the host does not have enough free hugepages
+// for all of the nodes in cfg, the pages must be preallocated by the user
+func hugepagesWarnings(cfg *config.Cluster) []warnings.Warning {
+	requested := map[string]int32{}
+	for _, n := range cfg.Nodes {
+		for size, pages := range n.Hugepages {
+			requested[size] += pages
+		}
+	}
+	sizes := make([]string, 0, len(requested))
+	for size := range requested {
+		sizes = append(sizes, size)
+	}
+	sort.Strings(sizes)
+	ws := []warnings.Warning{}
+	for _, size := range sizes {
+		free, err := freeHugepages(config.HugepageSizes[size])
+		if err != nil {
+			ws = append(ws, preflightWarning("unable to determine free %s hugepages on the host: %v", size, err))
+			continue
+		}
+		if free < int(requested[size]) {
+			ws = append(ws, preflightWarning("nodes request %d %s hugepages but the host only has %d free", requested[size], size, free))
+		}
+	}
+	return ws
+}
+
+func freeHugepages(sysfsSize string) (int, error) {
+	raw, err := ioutil.ReadFile(fmt.Sprintf("/sys/kernel/mm/hugepages/hugepages-%s/free_hugepages", sysfsSize))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(raw)))
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+func TestNodeResourceArgs(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name     string
+		node     config.Node
+		expected []string
+	}{
+		{
+			name:     "no resources",
+			node:     config.Node{},
+			expected: []string{},
+		},
+		{
+			name: "all resources",
+			node: config.Node{
+				CgroupNS:  "private",
+				CPUSet:    "0-1",
+				Hugepages: map[string]int32{"2Mi": 64},
+			},
+			expected: []string{
+				"--cgroupns", "private",
+				"--cpuset-cpus", "0-1",
+				"--volume", "/dev/hugepages:/dev/hugepages",
+			},
+		},
+		{
+			name: "ulimits",
+			node: config.Node{
+				Ulimits: map[string]string{"nproc": "-1", "nofile": "65536:1048576"},
+			},
+			expected: []string{
+				"--ulimit", "nofile=65536:1048576",
+				"--ulimit", "nproc=-1",
+			},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if result := NodeResourceArgs(&tc.node); !reflect.DeepEqual(result, tc.expected) {
+				t.Errorf("expected %v but got %v", tc.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// RestartPolicyArg returns the docker / podman run flag for the node
+// container restart policy in cfg
+func RestartPolicyArg(cfg *config.Cluster) string {
+	switch cfg.RestartPolicy {
+	case config.NoRestartPolicy, config.UnlessStoppedRestartPolicy, config.AlwaysRestartPolicy:
+		return "--restart=" + string(cfg.RestartPolicy)
+	}
+	// on-failure:0 would mean the same thing as always, so the closest to
+	// restarting only on host / runtime restarts is retrying *once*
+	return "--restart=on-failure:1"
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestRestartPolicyArg(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Policy   config.RestartPolicy
+		Expected string
+	}{
+		{Policy: "", Expected: "--restart=on-failure:1"},
+		{Policy: config.OnFailureRestartPolicy, Expected: "--restart=on-failure:1"},
+		{Policy: config.NoRestartPolicy, Expected: "--restart=no"},
+		{Policy: config.UnlessStoppedRestartPolicy, Expected: "--restart=unless-stopped"},
+		{Policy: config.AlwaysRestartPolicy, Expected: "--restart=always"},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(string(tc.Policy), func(t *testing.T) {
+			t.Parallel()
+			assert.StringEqual(t, tc.Expected, RestartPolicyArg(&config.Cluster{RestartPolicy: tc.Policy}))
+		})
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+// RetryPolicy configures retrying provider operations that can fail
+// transiently, such as pulling images and creating networks or containers
+type RetryPolicy struct {
+	// Attempts is the total number of attempts, values below 1 mean 1
+	Attempts int
+	// Backoff is the delay before the first retry, it doubles for each retry
+	Backoff time.Duration
+}
+
+// DefaultRetryPolicy is used when no policy is set on the context
+var DefaultRetryPolicy = RetryPolicy{
+	Attempts: 5,
+	Backoff:  time.Second,
+}
+
+type retryPolicyKey struct{}
+
+// WithRetryPolicy returns a copy of ctx carrying policy for Retry
+func WithRetryPolicy(ctx context.Context, policy RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyKey{}, policy)
+}
+
+// RetryPolicyFromContext returns the policy set with WithRetryPolicy,
+// or DefaultRetryPolicy if there is none
+func RetryPolicyFromContext(ctx context.Context) RetryPolicy {
+	if policy, ok := ctx.Value(retryPolicyKey{}).(RetryPolicy); ok {
+		return policy
+	}
+	return DefaultRetryPolicy
+}
+
+// Retry calls fn until it succeeds, following the policy from ctx.
+// Errors with a Reason (such as a port conflict) or marked with Permanent are
+// not transient and are returned immediately, as is the last error once ctx
+// is done.
+// what describes the operation for logging, e.g. "pull image kindest/node"
+func Retry(ctx context.Context, logger log.Logger, what string, fn func() error) error {
+	policy := RetryPolicyFromContext(ctx)
+	backoff := policy.Backoff
+	err := fn()
+	for attempt := 2; err != nil && attempt <= policy.Attempts; attempt++ {
+		if errors.ReasonForError(err) != nil || isPermanent(err) {
+			return err
+		}
+		logger.V(1).Infof("Failed to %s, retrying in %v (attempt %d/%d): %v", what, backoff, attempt, policy.Attempts, err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		err = fn()
+	}
+	return err
+}
+
+// Permanent marks err as not transient, so that Retry returns it immediately
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// isPermanent returns true if err or any of its causes is marked Permanent
+func isPermanent(err error) bool {
+	for err != nil {
+		if _, ok := err.(*permanentError); ok {
+			return true
+		}
+		causerErr, ok := err.(errors.Causer)
+		if !ok {
+			return false
+		}
+		err = causerErr.Cause()
+	}
+	return false
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+// Cause mimics github.com/pkg/errors's Cause pattern for errors
+func (e *permanentError) Cause() error {
+	return e.err
+}
+
+// Unwrap supports the standard library's errors.Unwrap
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+func TestRetry(t *testing.T) {
+	t.Parallel()
+	ctx := WithRetryPolicy(context.Background(), RetryPolicy{Attempts: 3, Backoff: time.Millisecond})
+	cases := []struct {
+		Name          string
+		Errs          []error
+		ExpectCalls   int
+		ExpectSuccess bool
+	}{
+		{
+			Name:          "succeeds first time",
+			Errs:          []error{nil},
+			ExpectCalls:   1,
+			ExpectSuccess: true,
+		},
+		{
+			Name:          "succeeds after transient errors",
+			Errs:          []error{errors.New("flake"), errors.New("flake"), nil},
+			ExpectCalls:   3,
+			ExpectSuccess: true,
+		},
+		{
+			Name:        "gives up after attempts",
+			Errs:        []error{errors.New("flake"), errors.New("flake"), errors.New("flake"), nil},
+			ExpectCalls: 3,
+		},
+		{
+			Name:        "does not retry errors with a reason",
+			Errs:        []error{errors.WithReason(errors.New("in use"), errors.ErrPortConflict), nil},
+			ExpectCalls: 1,
+		},
+		{
+			Name:        "does not retry permanent errors",
+			Errs:        []error{errors.Wrap(Permanent(errors.New("conflict")), "wrapped"), nil},
+			ExpectCalls: 1,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			calls := 0
+			err := Retry(ctx, log.NoopLogger{}, "test", func() error {
+				calls++
+				return tc.Errs[calls-1]
+			})
+			if calls != tc.ExpectCalls {
+				t.Errorf("expected %d calls but got %d", tc.ExpectCalls, calls)
+			}
+			if (err == nil) != tc.ExpectSuccess {
+				t.Errorf("unexpected error result: %v", err)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyFromContext(t *testing.T) {
+	t.Parallel()
+	if p := RetryPolicyFromContext(context.Background()); p != DefaultRetryPolicy {
+		t.Errorf("expected the default policy but got %+v", p)
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"io/ioutil"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/warnings"
+)
+
+// SELinuxEnforcing returns true if the host has SELinux in enforcing mode
+func SELinuxEnforcing() bool {
+	enforce, err := ioutil.ReadFile("/sys/fs/selinux/enforce")
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(string(enforce)) == "1"
+}
+
+// MountsWithoutSelinuxRelabel returns the host paths of all extraMounts in
+// cfg that do not specify selinuxRelabel
+func MountsWithoutSelinuxRelabel(cfg *config.Cluster) []string {
+	var hostPaths []string
+	for _, n := range cfg.Nodes {
+		for _, m := range n.ExtraMounts {
+			if m.SelinuxRelabel == "" {
+				hostPaths = append(hostPaths, m.HostPath)
+			}
+		}
+	}
+	return hostPaths
+}
+
+// selinuxRelabelWarnings warns about extraMounts which are likely to be
+// denied access because the host enforces SELinux and no relabeling was
+// requested
+func selinuxRelabelWarnings(cfg *config.Cluster) []warnings.Warning {
+	hostPaths := MountsWithoutSelinuxRelabel(cfg)
+	if len(hostPaths) == 0 || !SELinuxEnforcing() {
+		return nil
+	}
+	return []warnings.Warning{preflightWarning(
+		"SELinux is enforcing on this host and the following extraMounts do not set selinuxRelabel, access may be denied: %s. Set selinuxRelabel to shared, private, or none on each mount to silence this warning",
+		strings.Join(hostPaths, ", "),
+	)}
+}
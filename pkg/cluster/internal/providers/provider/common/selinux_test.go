@@ -0,0 +1,48 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+func TestMountsWithoutSelinuxRelabel(t *testing.T) {
+	t.Parallel()
+	cfg := &config.Cluster{
+		Nodes: []config.Node{
+			{
+				ExtraMounts: []config.Mount{
+					{HostPath: "/unset"},
+					{HostPath: "/shared", SelinuxRelabel: config.MountSelinuxRelabelShared},
+				},
+			},
+			{
+				ExtraMounts: []config.Mount{
+					{HostPath: "/none", SelinuxRelabel: config.MountSelinuxRelabelNone},
+					{HostPath: "/also-unset"},
+				},
+			},
+		},
+	}
+	expected := []string{"/unset", "/also-unset"}
+	if result := MountsWithoutSelinuxRelabel(cfg); !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v but got %v", expected, result)
+	}
+}
@@ -0,0 +1,31 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// NeedsFuse returns true if the containerd snapshotter in cfg
+// requires /dev/fuse in the node containers
+func NeedsFuse(cfg *config.Cluster) bool {
+	switch cfg.ContainerdSnapshotter {
+	case config.FuseOverlayFSSnapshotter, config.StargzSnapshotter:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// StaticPodsContainerPath is where the staticPodsHostPath of a node is
+// mounted in the node
+const StaticPodsContainerPath = "/kind/static-pods"
+
+// StaticPodsMounts returns the read-only mount of the node's
+// staticPodsHostPath, if it has one
+func StaticPodsMounts(node *config.Node) []config.Mount {
+	if node.StaticPodsHostPath == "" {
+		return nil
+	}
+	return []config.Mount{{
+		HostPath:      node.StaticPodsHostPath,
+		ContainerPath: StaticPodsContainerPath,
+		Readonly:      true,
+	}}
+}
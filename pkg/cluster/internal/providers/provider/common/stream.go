@@ -0,0 +1,52 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"bufio"
+	"context"
+	"io"
+
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// StreamOutputLines runs cmd, calling fn with each line of its stdout as it
+// is written. cmd being stopped because ctx is done is not an error, this is
+// how following a stream such as events ends
+func StreamOutputLines(ctx context.Context, cmd exec.Cmd, fn func(line string) error) error {
+	pr, pw := io.Pipe()
+	cmd.SetStdout(pw)
+	errCh := make(chan error, 1)
+	go func() {
+		err := cmd.Run()
+		pw.Close()
+		errCh <- err
+	}()
+	scanner := bufio.NewScanner(pr)
+	for scanner.Scan() {
+		if err := fn(scanner.Text()); err != nil {
+			// cmd fails on its next write, it may be waiting on a stream
+			pr.CloseWithError(err)
+			return err
+		}
+	}
+	err := <-errCh
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,37 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestStreamOutputLines(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	lines := []string{}
+	err := StreamOutputLines(ctx, exec.CommandContext(ctx, "printf", `a\nb\n`), func(line string) error {
+		lines = append(lines, line)
+		return nil
+	})
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, []string{"a", "b"}, lines)
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"net"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// VM describes the VM the container runtime runs in when it is not on the
+// host kind runs on, e.g. Docker Desktop, podman machine or Colima. Bind
+// mount sources are then paths inside the VM, which only match the host for
+// the directories the VM shares with it, and ports are published in the VM
+// and forwarded to the host
+type VM struct {
+	// Name is the VM product, e.g. "Docker Desktop", for messages
+	Name string
+	// SharedPaths are the host directories available at the same path in
+	// the VM, if empty every host path is available
+	SharedPaths []string
+	// DriveRoot is where windows drives are mounted in the VM, e.g. "/mnt"
+	// for C:\foo at /mnt/c/foo, if empty windows paths are left to the
+	// container runtime CLI
+	DriveRoot string
+	// ShareHint explains how to share another host directory with the VM
+	ShareHint string
+	// LoopbackPortsOnly is true if the VM only forwards ports published on
+	// its loopback or unspecified address to the host, e.g. podman machine
+	// and Colima, unlike Docker Desktop which forwards ports published on
+	// specific host addresses too
+	LoopbackPortsOnly bool
+}
+
+// vmDrivePathRE matches windows drive paths like C:\foo or C:/foo
+var vmDrivePathRE = regexp.MustCompile(`^([a-zA-Z]):[\\/]`)
+
+// TranslateHostPath returns the path in the VM of the absolute host path
+func (vm *VM) TranslateHostPath(hostPath string) string {
+	m := vmDrivePathRE.FindStringSubmatch(hostPath)
+	if m == nil || vm.DriveRoot == "" {
+		return hostPath
+	}
+	rest := strings.Replace(hostPath[len(m[0]):], "\\", "/", -1)
+	return vm.DriveRoot + "/" + strings.ToLower(m[1]) + "/" + rest
+}
+
+// IsShared returns true if the absolute host path is available in the VM
+func (vm *VM) IsShared(hostPath string) bool {
+	if len(vm.SharedPaths) == 0 || vmDrivePathRE.MatchString(hostPath) {
+		return true
+	}
+	hostPath = filepath.Clean(hostPath)
+	for _, shared := range vm.SharedPaths {
+		shared = filepath.Clean(shared)
+		if hostPath == shared || strings.HasPrefix(hostPath, strings.TrimSuffix(shared, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// checkVM checks that the extraMounts host paths of cfg are shared with
+// the VM the container runtime runs in, otherwise they would silently mount
+// an empty directory created in the VM, and that ports are only published
+// on addresses the VM forwards from the host if it only forwards loopback
+func checkVM(cfg *config.Cluster, vm *VM, abs func(string) (string, error)) []error {
+	if vm == nil {
+		return nil
+	}
+	errs := []error{}
+	for i, n := range cfg.Nodes {
+		type hostPath struct{ field, path string }
+		hostPaths := []hostPath{}
+		for _, m := range n.ExtraMounts {
+			hostPaths = append(hostPaths, hostPath{"extraMounts hostPath", m.HostPath})
+		}
+		if n.StaticPodsHostPath != "" {
+			hostPaths = append(hostPaths, hostPath{"staticPodsHostPath", n.StaticPodsHostPath})
+		}
+		for _, hp := range hostPaths {
+			absPath := hp.path
+			if !vmDrivePathRE.MatchString(hp.path) {
+				if p, err := abs(hp.path); err == nil {
+					absPath = p
+				}
+			}
+			if !vm.IsShared(absPath) {
+				errs = append(errs, errors.Errorf(
+					"nodes[%d].%s %q is not shared with the %s VM the container runtime runs in, "+
+						"it would mount an empty directory inside the VM instead. Use a path under one of %s, or %s",
+					i, hp.field, hp.path, vm.Name, strings.Join(vm.SharedPaths, ", "), vm.ShareHint,
+				))
+			}
+		}
+		for _, pm := range n.ExtraPortMappings {
+			if vm.LoopbackPortsOnly && !vmForwardsAddress(pm.ListenAddress) {
+				errs = append(errs, errors.Errorf(
+					"nodes[%d].extraPortMappings listenAddress %s is not an address of the %s VM the container runtime runs in, "+
+						"ports are published in the VM and forwarded to the host from 127.0.0.1 or 0.0.0.0 only, use one of those",
+					i, pm.ListenAddress, vm.Name,
+				))
+			}
+		}
+	}
+	if vm.LoopbackPortsOnly && !vmForwardsAddress(cfg.Networking.APIServerAddress) {
+		errs = append(errs, errors.Errorf(
+			"networking.apiServerAddress %s is not an address of the %s VM the container runtime runs in, use 127.0.0.1",
+			cfg.Networking.APIServerAddress, vm.Name,
+		))
+	}
+	return errs
+}
+
+// vmForwardsAddress returns true if a port published on address in a VM
+// that only forwards loopback ports is forwarded to the host
+func vmForwardsAddress(address string) bool {
+	if address == "" {
+		return true
+	}
+	ip := net.ParseIP(address)
+	return ip != nil && (ip.IsLoopback() || ip.IsUnspecified())
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"path/filepath"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestVMTranslateHostPath(t *testing.T) {
+	t.Parallel()
+	wsl := &VM{Name: "podman machine", DriveRoot: "/mnt"}
+	assert.StringEqual(t, "/mnt/c/Users/kind/data", wsl.TranslateHostPath(`C:\Users\kind\data`))
+	assert.StringEqual(t, "/mnt/d/src", wsl.TranslateHostPath("D:/src"))
+	assert.StringEqual(t, "/home/kind", wsl.TranslateHostPath("/home/kind"))
+	desktop := &VM{Name: "Docker Desktop"}
+	assert.StringEqual(t, "C:/src", desktop.TranslateHostPath("C:/src"))
+}
+
+func TestVMIsShared(t *testing.T) {
+	t.Parallel()
+	vm := &VM{Name: "Colima", SharedPaths: []string{"/Users/kind", "/tmp/colima/"}}
+	cases := map[string]bool{
+		"/Users/kind":            true,
+		"/Users/kind/src/../app": true,
+		"/tmp/colima/data":       true,
+		"/Users/kindred":         false,
+		"/opt/data":              false,
+		`C:\data`:                true,
+	}
+	for hostPath, expected := range cases {
+		assert.BoolEqual(t, expected, vm.IsShared(hostPath))
+	}
+	assert.BoolEqual(t, true, (&VM{Name: "Docker Desktop"}).IsShared("/opt/data"))
+}
+
+func TestCheckVM(t *testing.T) {
+	t.Parallel()
+	abs := func(p string) (string, error) {
+		if filepath.IsAbs(p) {
+			return p, nil
+		}
+		return filepath.Join("/Users/kind/src", p), nil
+	}
+	cfg := &config.Cluster{
+		Networking: config.Networking{APIServerAddress: "127.0.0.1"},
+		Nodes: []config.Node{
+			{
+				ExtraMounts: []config.Mount{
+					{HostPath: "./data"},
+					{HostPath: "/opt/data"},
+				},
+				StaticPodsHostPath: "/opt/pods",
+				ExtraPortMappings: []config.PortMapping{
+					{ListenAddress: "0.0.0.0", HostPort: 80},
+					{ListenAddress: "::1", HostPort: 81},
+					{ListenAddress: "192.168.1.10", HostPort: 443},
+				},
+			},
+		},
+	}
+	assert.DeepEqual(t, 0, len(checkVM(cfg, nil, abs)))
+	vm := &VM{Name: "Colima", SharedPaths: []string{"/Users/kind"}, LoopbackPortsOnly: true}
+	// the host paths outside the shared paths, and the LAN listen address
+	if errs := checkVM(cfg, vm, abs); len(errs) != 3 {
+		t.Errorf("expected 3 errors, got %v", errs)
+	}
+	cfg.Networking.APIServerAddress = "192.168.1.10"
+	if errs := checkVM(cfg, vm, abs); len(errs) != 4 {
+		t.Errorf("expected 4 errors, got %v", errs)
+	}
+	// Docker Desktop forwards ports published on specific host addresses
+	vm = &VM{Name: "Docker Desktop", SharedPaths: []string{"/Users/kind"}}
+	if errs := checkVM(cfg, vm, abs); len(errs) != 2 {
+		t.Errorf("expected 2 errors, got %v", errs)
+	}
+}
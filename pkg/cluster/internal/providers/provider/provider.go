@@ -17,32 +17,143 @@ limitations under the License.
 package provider
 
 import (
+	"context"
+	"time"
+
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/exec"
 
+	"sigs.k8s.io/kind/pkg/cluster/internal/logs"
 	"sigs.k8s.io/kind/pkg/internal/apis/config"
 	"sigs.k8s.io/kind/pkg/internal/cli"
 )
 
 // Provider represents a provider of cluster / node infrastructure
 // This is an alpha-grade internal API
+//
+// All methods accept a context which bounds every command they run,
+// nodes returned by ListNodes bind the context they were listed with.
 type Provider interface {
+	// String returns the provider name, e.g. "docker"
+	String() string
 	// Provision should create and start the nodes, just short of
 	// actually starting up Kubernetes, based on the given cluster config
-	Provision(status *cli.Status, cfg *config.Cluster) error
+	Provision(ctx context.Context, status *cli.Status, cfg *config.Cluster) error
+	// ProvisionNode creates and starts the container of the node named name
+	// in cfg, as Provision would, for an existing cluster
+	ProvisionNode(ctx context.Context, status *cli.Status, cfg *config.Cluster, name string) error
+	// Info returns details of the container runtime host that change how
+	// clusters are created
+	Info(ctx context.Context) (*Info, error)
+	// MissingImages returns the images Provision would need to pull for cfg,
+	// the node images and any load balancer image not present locally
+	MissingImages(ctx context.Context, cfg *config.Cluster) ([]string, error)
+	// ImageCommand returns a command that runs in a throwaway container
+	// started from image, the image is pulled if it is not present
+	ImageCommand(ctx context.Context, image, command string, args ...string) exec.Cmd
 	// ListClusters discovers the clusters that currently have resources
 	// under this providers
-	ListClusters() ([]string, error)
+	ListClusters(ctx context.Context) ([]string, error)
 	// ListNodes returns the nodes under this provider for the given
 	// cluster name, they may or may not be running correctly
-	ListNodes(cluster string) ([]nodes.Node, error)
+	ListNodes(ctx context.Context, cluster string) ([]nodes.Node, error)
 	// DeleteNodes deletes the provided list of nodes
 	// These should be from results previously returned by this provider
 	// E.G. by ListNodes()
-	DeleteNodes([]nodes.Node) error
+	DeleteNodes(ctx context.Context, n []nodes.Node) error
+	// RestartNodes restarts the provided list of nodes, as on a host reboot
+	// These should be from results previously returned by this provider
+	RestartNodes(ctx context.Context, n []nodes.Node) error
+	// CreateProxy creates and starts a container named name for the cluster
+	// cfg from the load balancer image, publishing portMappings on the host
+	CreateProxy(ctx context.Context, cfg *config.Cluster, name string, portMappings []config.PortMapping) error
+	// CommitNode saves the node container's filesystem as image so that new
+	// nodes can be started from it, volumes such as /var are not included.
+	// The image is labeled as committed for cluster
+	CommitNode(ctx context.Context, n nodes.Node, image, cluster string) error
+	// DeleteImages deletes the images committed for cluster by CommitNode
+	DeleteImages(ctx context.Context, cluster string) error
+	// InspectNodes returns the runtime's inspect output for the nodes, as a
+	// JSON array of docker compatible container details
+	InspectNodes(ctx context.Context, n []nodes.Node) ([]byte, error)
+	// NetworkInfo returns the name and subnets of the container network
+	// the cluster's nodes are attached to
+	NetworkInfo(ctx context.Context, cluster string) (*NetworkInfo, error)
 	// GetAPIServerEndpoint returns the host endpoint for the cluster's API server
-	GetAPIServerEndpoint(cluster string) (string, error)
+	GetAPIServerEndpoint(ctx context.Context, cluster string) (string, error)
 	// GetAPIServerEndpoint returns the internal network endpoint for the cluster's API server
-	GetAPIServerInternalEndpoint(cluster string) (string, error)
+	GetAPIServerInternalEndpoint(ctx context.Context, cluster string) (string, error)
 	// CollectLogs will populate dir with cluster logs and other debug files
-	CollectLogs(dir string, nodes []nodes.Node) error
+	// opts may be nil to collect everything
+	CollectLogs(ctx context.Context, dir string, nodes []nodes.Node, opts *logs.Options) error
+	// NodeEvents calls fn with the container lifecycle events of the
+	// cluster's nodes in order, with opts.Follow it streams new events
+	// until ctx is done
+	NodeEvents(ctx context.Context, cluster string, opts NodeEventsOptions, fn func(NodeEvent)) error
+}
+
+// NodeEventsOptions selects the events returned by NodeEvents
+type NodeEventsOptions struct {
+	// Since only returns events after this time, zero for every event the
+	// container runtime still has
+	Since time.Time
+	// Follow streams new events instead of returning once the past events
+	// have been read
+	Follow bool
+}
+
+// NodeEvent is a container lifecycle event of a node
+type NodeEvent struct {
+	// Time is when the event happened
+	Time time.Time `json:"time"`
+	// Node is the node container name
+	Node string `json:"node"`
+	// Action is one of NodeEventActions
+	Action string `json:"action"`
+	// ExitCode is the container exit code of "die" events
+	ExitCode string `json:"exitCode,omitempty"`
+}
+
+// NodeEventActions are the container lifecycle events reported by
+// NodeEvents, with docker's names. Not every runtime reports "oom"
+var NodeEventActions = []string{
+	"create", "start", "restart", "pause", "unpause",
+	"oom", "kill", "die", "stop", "destroy",
+}
+
+// NetworkInfo describes the container network of a cluster
+type NetworkInfo struct {
+	// Name is the container runtime network name, e.g. "kind"
+	Name string `json:"name"`
+	// Subnets are the IPv4 and IPv6 subnets of the network
+	Subnets []NetworkSubnet `json:"subnets"`
+	// MTU is the MTU of the nodes' network interface, zero if unknown
+	MTU int `json:"mtu,omitempty"`
+	// Nodes are the addresses of the cluster's containers on the network
+	Nodes []NodeAddress `json:"nodes,omitempty"`
+}
+
+// NetworkSubnet is a subnet of a container network
+type NetworkSubnet struct {
+	// Subnet is the subnet in CIDR notation, e.g. "172.18.0.0/16"
+	Subnet string `json:"subnet"`
+	// Gateway is the subnet's gateway address, if any
+	Gateway string `json:"gateway,omitempty"`
+}
+
+// NodeAddress is the address of a cluster container on its network
+type NodeAddress struct {
+	// Name is the container name
+	Name string `json:"name"`
+	// IPv4 is the container's IPv4 address, if any
+	IPv4 string `json:"ipv4,omitempty"`
+	// IPv6 is the container's IPv6 address, if any
+	IPv6 string `json:"ipv6,omitempty"`
+}
+
+// Info describes the container runtime host
+type Info struct {
+	// Rootless is true if the container runtime runs as an unprivileged user,
+	// it cannot publish host ports below net.ipv4.ip_unprivileged_port_start
+	Rootless bool
 }
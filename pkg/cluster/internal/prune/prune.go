@@ -0,0 +1,229 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package prune implements removing unused images from the nodes of a cluster
+package prune
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
+)
+
+// PreloadedImagesPath is where the IDs of the images baked into the node image
+// are recorded on each node, one per line
+const PreloadedImagesPath = "/kind/preloaded-images"
+
+// RecordPreloadedImages records the IDs of the images currently in node's
+// containerd store at PreloadedImagesPath, unless they are already recorded,
+// e.g. in a node committed from another cluster. This must run before any
+// image is loaded or pulled. The containerd of the base image never sets the
+// CRI pinned field, so this is how Images tells the preloaded images apart
+func RecordPreloadedImages(node nodes.Node) error {
+	script := fmt.Sprintf("[ -f %[1]s ] || { crictl images -q > %[1]s.tmp && mv %[1]s.tmp %[1]s; }", PreloadedImagesPath)
+	return node.Command("sh", "-c", script).Run()
+}
+
+// Options configures Images
+type Options struct {
+	// DryRun only reports the unused images, without removing them
+	DryRun bool
+}
+
+// Image is an image in a node's containerd store
+type Image struct {
+	// Name is the first tag of the image, or its digest or ID if untagged
+	Name string
+	// ID is the image ID, e.g. "sha256:..."
+	ID string
+	// Size is the size of the image in bytes
+	Size int64
+}
+
+// NodeImages are the unused images of a node
+type NodeImages struct {
+	Node   string
+	Images []Image
+}
+
+// Images removes the images that no container uses from the containerd
+// store of each Kubernetes node of the cluster, and returns them. The
+// sandbox (pause) image, the images preloaded in the node image and pinned
+// images are kept
+func Images(ctx context.Context, p provider.Provider, cluster string, opts Options) ([]NodeImages, error) {
+	n, err := p.ListNodes(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	if len(n) == 0 {
+		return nil, errors.ClusterNotFound(cluster)
+	}
+	internalNodes, err := nodeutils.InternalNodes(n)
+	if err != nil {
+		return nil, err
+	}
+	out := []NodeImages{}
+	for _, node := range internalNodes {
+		unused, err := nodeUnusedImages(ctx, node)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list the unused images of node %s", node.String())
+		}
+		if len(unused) > 0 && !opts.DryRun {
+			args := []string{"rmi"}
+			for _, image := range unused {
+				args = append(args, image.ID)
+			}
+			if err := node.CommandContext(ctx, "crictl", args...).Run(); err != nil {
+				return nil, errors.Wrapf(err, "failed to remove the unused images of node %s", node.String())
+			}
+		}
+		out = append(out, NodeImages{Node: node.String(), Images: unused})
+	}
+	return out, nil
+}
+
+// crictlImages is the subset of `crictl images -o json` we use
+type crictlImages struct {
+	Images []struct {
+		ID          string   `json:"id"`
+		RepoTags    []string `json:"repoTags"`
+		RepoDigests []string `json:"repoDigests"`
+		// the CRI API size is a uint64, which is encoded as a string
+		Size   string `json:"size"`
+		Pinned bool   `json:"pinned"`
+	} `json:"images"`
+}
+
+// crictlContainers is the subset of `crictl ps -a -o json` we use
+type crictlContainers struct {
+	Containers []struct {
+		Image struct {
+			Image string `json:"image"`
+		} `json:"image"`
+		ImageRef string `json:"imageRef"`
+	} `json:"containers"`
+}
+
+// crictlInfo is the subset of `crictl info` we use
+type crictlInfo struct {
+	Config struct {
+		SandboxImage string `json:"sandboxImage"`
+	} `json:"config"`
+}
+
+func nodeUnusedImages(ctx context.Context, node nodes.Node) ([]Image, error) {
+	preloaded, err := preloadedImages(ctx, node)
+	if err != nil {
+		return nil, err
+	}
+	var images crictlImages
+	if err := crictlJSON(ctx, node, &images, "images", "-o", "json"); err != nil {
+		return nil, err
+	}
+	var containers crictlContainers
+	if err := crictlJSON(ctx, node, &containers, "ps", "-a", "-o", "json"); err != nil {
+		return nil, err
+	}
+	var info crictlInfo
+	if err := crictlJSON(ctx, node, &info, "info"); err != nil {
+		return nil, err
+	}
+	return unusedImages(images, containers, info.Config.SandboxImage, preloaded)
+}
+
+// preloadedImages reads the image IDs recorded by RecordPreloadedImages
+func preloadedImages(ctx context.Context, node nodes.Node) (map[string]bool, error) {
+	var buff bytes.Buffer
+	if err := node.CommandContext(ctx, "cat", PreloadedImagesPath).SetStdout(&buff).Run(); err != nil {
+		return nil, errors.Wrap(err, "failed to read the preloaded images, clusters created by older kind versions must be recreated to prune images")
+	}
+	return parsePreloaded(buff.String()), nil
+}
+
+func parsePreloaded(s string) map[string]bool {
+	out := map[string]bool{}
+	for _, id := range strings.Fields(s) {
+		out[id] = true
+	}
+	return out
+}
+
+func crictlJSON(ctx context.Context, node nodes.Node, v interface{}, args ...string) error {
+	var buff bytes.Buffer
+	if err := node.CommandContext(ctx, "crictl", args...).SetStdout(&buff).Run(); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(buff.Bytes(), v); err != nil {
+		return errors.Wrapf(err, "failed to parse crictl %s output", args[0])
+	}
+	return nil
+}
+
+// unusedImages returns the images not referenced by any container, by ID,
+// tag or digest, excluding the sandbox image, the preloaded image IDs and
+// pinned images, by Name
+func unusedImages(images crictlImages, containers crictlContainers, sandboxImage string, preloaded map[string]bool) ([]Image, error) {
+	inUse := map[string]bool{}
+	for _, c := range containers.Containers {
+		inUse[c.Image.Image] = true
+		inUse[c.ImageRef] = true
+	}
+	if sandboxImage != "" {
+		inUse[sandboxImage] = true
+	}
+	out := []Image{}
+	for _, image := range images.Images {
+		refs := append([]string{image.ID}, image.RepoTags...)
+		refs = append(refs, image.RepoDigests...)
+		used := image.Pinned || preloaded[image.ID]
+		for _, ref := range refs {
+			used = used || inUse[ref]
+		}
+		if used {
+			continue
+		}
+		size, err := strconv.ParseInt(image.Size, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid size of image %s", image.ID)
+		}
+		out = append(out, Image{Name: imageName(image.ID, image.RepoTags, image.RepoDigests), ID: image.ID, Size: size})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+	return out, nil
+}
+
+// imageName prefers a tag, then a digest, over the image ID
+func imageName(id string, tags, digests []string) string {
+	if len(tags) > 0 {
+		return tags[0]
+	}
+	if len(digests) > 0 {
+		return digests[0]
+	}
+	return id
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prune
+
+import (
+	"encoding/json"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestUnusedImages(t *testing.T) {
+	t.Parallel()
+	var images crictlImages
+	if err := json.Unmarshal([]byte(`{"images": [
+		{"id": "sha256:aaa", "repoTags": ["registry.k8s.io/pause:3.10"], "repoDigests": [], "size": "320368"},
+		{"id": "sha256:bbb", "repoTags": ["docker.io/kindest/kindnetd:v20241108"], "repoDigests": [], "size": "39000000"},
+		{"id": "sha256:ccc", "repoTags": ["docker.io/library/nginx:1.27"], "repoDigests": ["docker.io/library/nginx@sha256:123"], "size": "72000000"},
+		{"id": "sha256:ddd", "repoTags": [], "repoDigests": ["docker.io/library/busybox@sha256:456"], "size": "2150000"},
+		{"id": "sha256:eee", "repoTags": [], "repoDigests": [], "size": "1000"},
+		{"id": "sha256:fff", "repoTags": ["registry.example/pinned:v1"], "repoDigests": [], "size": "1000", "pinned": true},
+		{"id": "sha256:ggg", "repoTags": ["registry.example/by-digest:v1"], "repoDigests": ["registry.example/by-digest@sha256:789"], "size": "1000"}
+	]}`), &images); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var containers crictlContainers
+	if err := json.Unmarshal([]byte(`{"containers": [
+		{"image": {"image": "sha256:bbb"}, "imageRef": "sha256:bbb"},
+		{"image": {"image": "registry.example/by-digest@sha256:789"}, "imageRef": "registry.example/by-digest@sha256:789"}
+	]}`), &containers); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	preloaded := parsePreloaded("sha256:aaa\nsha256:eee\n")
+	unused, err := unusedImages(images, containers, "registry.k8s.io/pause:3.10", preloaded)
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, []Image{
+		{Name: "docker.io/library/busybox@sha256:456", ID: "sha256:ddd", Size: 2150000},
+		{Name: "docker.io/library/nginx:1.27", ID: "sha256:ccc", Size: 72000000},
+	}, unused)
+
+	images.Images[2].Size = "bogus"
+	_, err = unusedImages(images, containers, "", nil)
+	assert.ExpectError(t, true, err)
+}
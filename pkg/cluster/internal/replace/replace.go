@@ -0,0 +1,416 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package replace implements replacing a node of a cluster with a new node
+// container while keeping the cluster available
+package replace
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/approvecsrs"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/kubeadminit"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/kubeadmjoin"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/loadbalancer"
+	"sigs.k8s.io/kind/pkg/cluster/internal/kubeadm"
+	"sigs.k8s.io/kind/pkg/cluster/internal/oidc"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
+	"sigs.k8s.io/kind/pkg/cluster/internal/state"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/apis/config/encoding"
+	"sigs.k8s.io/kind/pkg/internal/cli"
+)
+
+// Options configures the replacement node
+type Options struct {
+	// Image is the node image of the new node, by default the image of the
+	// old node in the cluster's recorded config
+	Image string
+	// Timeout bounds waiting for the new node to be Ready, and draining the
+	// old node
+	Timeout time.Duration
+}
+
+// Node replaces the node named node of cluster with a new node container,
+// created from the cluster's recorded config with opts applied. The new node
+// joins the cluster and must become Ready before the old node is drained,
+// removed from the cluster and deleted. It returns the new node's name.
+func Node(ctx context.Context, logger log.Logger, p provider.Provider, cluster, node string, opts Options) (string, error) {
+	allNodes, err := p.ListNodes(ctx, cluster)
+	if err != nil {
+		return "", err
+	}
+	if len(allNodes) == 0 {
+		return "", errors.ClusterNotFound(cluster)
+	}
+	old, err := findNode(allNodes, node)
+	if err != nil {
+		return "", errors.Wrapf(err, "unknown node in cluster %q", cluster)
+	}
+	role, err := old.Role()
+	if err != nil {
+		return "", err
+	}
+	if role != constants.ControlPlaneNodeRoleValue && role != constants.WorkerNodeRoleValue {
+		return "", errors.Errorf("node %q has role %q, only control-plane and worker nodes can be replaced", node, role)
+	}
+
+	cfg, err := state.Default().ReadConfig(cluster)
+	if err != nil {
+		return "", err
+	}
+	if cfg.ExternalControlPlane.Endpoint != "" {
+		return "", errors.New("replacing the nodes of a cluster with an external control plane is not supported")
+	}
+	// a remaining control plane node runs kubectl and kubeadm for us
+	controller, err := otherControlPlane(allNodes, node)
+	if err != nil {
+		return "", err
+	}
+	index, err := configIndex(cfg, node)
+	if err != nil {
+		return "", err
+	}
+	// the old node keeps its host ports until the new node is Ready
+	for _, m := range cfg.Nodes[index].ExtraPortMappings {
+		if m.HostPort != 0 {
+			return "", errors.Errorf("node %q publishes host port %d, which can't be published by its replacement at the same time", node, m.HostPort)
+		}
+	}
+
+	// the new node is added to the config under an unused name
+	inUse := sets.NewString(config.NodeNames(cfg)...)
+	for _, n := range allNodes {
+		inUse.Insert(n.String())
+	}
+	newCfg := cfg.DeepCopy()
+	newName := config.UnusedNodeName(cfg, cfg.Nodes[index].Role, inUse.Has)
+	newCfg.Nodes[index].Name = newName
+	// the old node's hostname and machine-id would clash while both nodes
+	// are in the cluster, the new node is registered under its own name
+	newCfg.Nodes[index].Hostname = ""
+	newCfg.Nodes[index].MachineID = ""
+	if opts.Image != "" {
+		newCfg.Nodes[index].Image = opts.Image
+	}
+
+	status := cli.StatusForLogger(logger)
+	if err := addNode(ctx, logger, status, p, newCfg, newName, controller, opts); err != nil {
+		if derr := deleteFailedNode(logger, status, p, cfg, newCfg, newName, controller); derr != nil {
+			logger.Warnf("failed to delete replacement node %s: %v", newName, derr)
+			return "", errors.Wrapf(err, "failed to add replacement node %s, deleting it also failed", newName)
+		}
+		return "", errors.Wrapf(err, "failed to add replacement node %s, it was deleted and %s was left unchanged", newName, node)
+	}
+	if err := removeNode(ctx, logger, status, p, newCfg, old, common.KubernetesNodeName(cfg, old), controller, opts.Timeout); err != nil {
+		return "", errors.Wrapf(err, "failed to remove node %s after adding %s", node, newName)
+	}
+
+	// record the new node so later operations match it to its config
+	if err := recordReplacement(state.Default(), cfg, index, newName, opts.Image); err != nil {
+		logger.Warnf("failed to record the replacement node: %v", err)
+	}
+	return newName, nil
+}
+
+// addNode creates, configures and joins the node name of cfg and waits for
+// it to be Ready
+func addNode(ctx context.Context, logger log.Logger, status *cli.Status, p provider.Provider, cfg *config.Cluster, name string, controller nodes.Node, opts Options) (err error) {
+	if err := p.ProvisionNode(ctx, status, cfg, name); err != nil {
+		return err
+	}
+	allNodes, err := p.ListNodes(ctx, cfg.Name)
+	if err != nil {
+		return err
+	}
+	node, err := findNode(allNodes, name)
+	if err != nil {
+		return err
+	}
+	role, err := node.Role()
+	if err != nil {
+		return err
+	}
+	if err := nodeutils.WaitForBoot(ctx, node); err != nil {
+		return err
+	}
+
+	// the node setup actions only act on the new node
+	actionsContext := actions.NewActionContext(ctx, logger, status, p, cfg)
+	for _, action := range create.NodeSetupActions(cfg, nil) {
+		if err := action.Execute(actionsContext.WithNodes([]nodes.Node{node})); err != nil {
+			return err
+		}
+	}
+
+	status.Start(fmt.Sprintf("Joining node %s 🚜", name))
+	defer func() { status.End(err == nil) }()
+	if role == constants.ControlPlaneNodeRoleValue {
+		files := kubeadminit.SharedControlPlaneFiles
+		if cfg.Authentication.OIDC.TestIssuer || cfg.Authentication.OIDC.CA != "" {
+			files = append(append([]string{}, files...), oidc.CAPath)
+		}
+		for _, file := range files {
+			if err := nodeutils.CopyNodeToNode(controller, node, file); err != nil {
+				return errors.Wrapf(err, "failed to copy %s", file)
+			}
+		}
+	}
+	if err := refreshBootstrapToken(ctx, controller); err != nil {
+		return err
+	}
+	if err := kubeadmjoin.RunKubeadmJoin(logger, node, actions.DefaultKubeadmVerbosity); err != nil {
+		return err
+	}
+	if cfg.KubeletServerTLSBootstrap {
+		if err := approvecsrs.Approve(ctx, controller, []string{common.KubernetesNodeName(cfg, node)}); err != nil {
+			return err
+		}
+	}
+	if err := waitForReady(ctx, controller, common.KubernetesNodeName(cfg, node), opts.Timeout); err != nil {
+		return err
+	}
+	status.End(true)
+
+	// the load balancer also forwards to the new control plane node
+	if role == constants.ControlPlaneNodeRoleValue {
+		return loadbalancer.NewAction().Execute(actions.NewActionContext(ctx, logger, status, p, cfg))
+	}
+	return nil
+}
+
+// deleteFailedNode removes the node name that failed to be added from the
+// cluster, if it joined, and deletes it. prevCfg is the config before adding
+// the node, the load balancer is configured for it again.
+func deleteFailedNode(logger log.Logger, status *cli.Status, p provider.Provider, prevCfg, cfg *config.Cluster, name string, controller nodes.Node) error {
+	// NOTE: this uses a fresh context, ctx may have been cancelled
+	ctx := context.Background()
+	allNodes, err := p.ListNodes(ctx, cfg.Name)
+	if err != nil {
+		return err
+	}
+	node, err := findNode(allNodes, name)
+	if err != nil {
+		// the node container was never created
+		return nil
+	}
+	role, err := node.Role()
+	if err != nil {
+		return err
+	}
+	logger.V(0).Infof("Deleting replacement node %s ...", name)
+	// these fail if the node did not get to join, which is fine
+	if role == constants.ControlPlaneNodeRoleValue {
+		// this removes the node's etcd member if it was added
+		_ = node.CommandContext(ctx, "kubeadm", "reset", "--force").Run()
+	}
+	_ = kubectl(ctx, controller, "delete", "node", common.KubernetesNodeName(cfg, node), "--ignore-not-found").Run()
+	if err := p.DeleteNodes(ctx, []nodes.Node{node}); err != nil {
+		return err
+	}
+	// the load balancer may already forward to the deleted node
+	if role == constants.ControlPlaneNodeRoleValue {
+		return loadbalancer.NewAction().Execute(actions.NewActionContext(ctx, logger, status, p, prevCfg))
+	}
+	return nil
+}
+
+// removeNode drains node, which is registered in Kubernetes as nodeName,
+// removes it from the cluster and deletes it
+func removeNode(ctx context.Context, logger log.Logger, status *cli.Status, p provider.Provider, cfg *config.Cluster, node nodes.Node, nodeName string, controller nodes.Node, timeout time.Duration) (err error) {
+	status.Start(fmt.Sprintf("Draining node %s 🚰", node.String()))
+	defer func() { status.End(err == nil) }()
+	kubeVersion, err := nodeutils.KubeVersion(controller)
+	if err != nil {
+		return err
+	}
+	args, err := drainArgs(kubeVersion, nodeName, timeout)
+	if err != nil {
+		return err
+	}
+	if err := kubectl(ctx, controller, args...).Run(); err != nil {
+		return errors.Wrap(err, "failed to drain node")
+	}
+	role, err := node.Role()
+	if err != nil {
+		return err
+	}
+	// this removes the node's etcd member, the control plane would lose
+	// quorum once enough removed nodes are missing
+	if role == constants.ControlPlaneNodeRoleValue {
+		if err := node.CommandContext(ctx, "kubeadm", "reset", "--force").Run(); err != nil {
+			return errors.Wrap(err, "failed to reset control plane node")
+		}
+	}
+	if err := kubectl(ctx, controller, "delete", "node", nodeName).Run(); err != nil {
+		return errors.Wrap(err, "failed to delete node from the cluster")
+	}
+	if err := p.DeleteNodes(ctx, []nodes.Node{node}); err != nil {
+		return err
+	}
+	status.End(true)
+
+	// stop forwarding to the deleted control plane node
+	if role == constants.ControlPlaneNodeRoleValue {
+		return loadbalancer.NewAction().Execute(actions.NewActionContext(ctx, logger, status, p, cfg))
+	}
+	return nil
+}
+
+// drainArgs returns the kubectl drain arguments for kubectl kubeVersion
+func drainArgs(kubeVersion, node string, timeout time.Duration) ([]string, error) {
+	v, err := version.ParseGeneric(kubeVersion)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse kubernetes version %q", kubeVersion)
+	}
+	args := []string{"drain", node, "--ignore-daemonsets", "--force"}
+	// --delete-local-data was renamed in kubernetes 1.20
+	if v.LessThan(version.MustParseSemantic("v1.20.0")) {
+		args = append(args, "--delete-local-data")
+	} else {
+		args = append(args, "--delete-emptydir-data")
+	}
+	if timeout > 0 {
+		args = append(args, fmt.Sprintf("--timeout=%s", timeout))
+	}
+	return args, nil
+}
+
+// refreshBootstrapToken recreates the well known bootstrap token used by the
+// kubeadm join config, it expires 24 hours after the cluster is created
+func refreshBootstrapToken(ctx context.Context, controller nodes.Node) error {
+	// deleting fails if the token has already expired, which is fine
+	_ = controller.CommandContext(ctx,
+		"kubeadm", "token", "delete", kubeadm.Token, "--kubeconfig=/etc/kubernetes/admin.conf",
+	).Run()
+	return errors.Wrap(controller.CommandContext(ctx,
+		"kubeadm", "token", "create", kubeadm.Token, "--ttl=1h", "--kubeconfig=/etc/kubernetes/admin.conf",
+	).Run(), "failed to refresh the bootstrap token")
+}
+
+// waitForReady waits for the Kubernetes node name to be Ready, for at most
+// timeout if it is not zero
+func waitForReady(ctx context.Context, controller nodes.Node, name string, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	for {
+		// the node may not be registered yet, that's not an error
+		lines, _ := exec.OutputLines(kubectl(ctx, controller,
+			"get", "node", name, `-o=jsonpath={.status.conditions[?(@.type=="Ready")].status}`,
+		))
+		if strings.TrimSpace(strings.Join(lines, "")) == "True" {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Errorf("timed out waiting for node %s to be Ready", name)
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
+func kubectl(ctx context.Context, node nodes.Node, args ...string) exec.Cmd {
+	return node.CommandContext(ctx, "kubectl", append([]string{"--kubeconfig=/etc/kubernetes/admin.conf"}, args...)...)
+}
+
+// recordReplacement names the node at index of the recorded config newName,
+// with image if set, so it matches the replacement node
+func recordReplacement(store *state.Store, cfg *config.Cluster, index int, newName, image string) error {
+	recorded, err := store.Read(cfg.Name)
+	if err != nil || recorded == nil {
+		return err
+	}
+	overrides := []string{fmt.Sprintf("nodes[%d].name=%s", index, newName)}
+	// the replacement node was created without these
+	if cfg.Nodes[index].Hostname != "" {
+		overrides = append(overrides, fmt.Sprintf(`nodes[%d].hostname=""`, index))
+	}
+	if cfg.Nodes[index].MachineID != "" {
+		overrides = append(overrides, fmt.Sprintf(`nodes[%d].machineID=""`, index))
+	}
+	if image != "" {
+		// the recorded node image override applies to every node, so it
+		// is moved to the other nodes
+		if recorded.NodeImage != "" {
+			for i := range cfg.Nodes {
+				if i != index {
+					overrides = append(overrides, fmt.Sprintf("nodes[%d].image=%s", i, recorded.NodeImage))
+				}
+			}
+			recorded.NodeImage = ""
+		}
+		overrides = append(overrides, fmt.Sprintf("nodes[%d].image=%s", index, image))
+	}
+	raw, err := encoding.ApplyOverrides([]byte(recorded.Config), overrides)
+	if err != nil {
+		return err
+	}
+	recorded.Config = string(raw)
+	return store.Write(recorded)
+}
+
+// findNode returns the node in n named name
+func findNode(n []nodes.Node, name string) (nodes.Node, error) {
+	for _, node := range n {
+		if node.String() == name {
+			return node, nil
+		}
+	}
+	return nil, errors.Errorf("no node named %q", name)
+}
+
+// otherControlPlane returns a control plane node in allNodes other than node
+func otherControlPlane(allNodes []nodes.Node, node string) (nodes.Node, error) {
+	controlPlanes, err := nodeutils.ControlPlaneNodes(allNodes)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range controlPlanes {
+		if n.String() != node {
+			return n, nil
+		}
+	}
+	return nil, errors.Errorf("node %q is the only control plane node, replacing it would make the cluster unavailable", node)
+}
+
+// configIndex returns the index of the config entry node was created from
+func configIndex(cfg *config.Cluster, node string) (int, error) {
+	for i, name := range config.NodeNames(cfg) {
+		if name == node {
+			return i, nil
+		}
+	}
+	return 0, errors.Errorf("failed to match node %q to the recorded config of cluster %q", node, cfg.Name)
+}
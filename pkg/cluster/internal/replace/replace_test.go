@@ -0,0 +1,267 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replace
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/apis/config/encoding"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
+	"sigs.k8s.io/kind/pkg/cluster/internal/state"
+)
+
+type fakeProvider struct {
+	provider.Provider
+	nodes   []nodes.Node
+	deleted []string
+}
+
+func (p *fakeProvider) ListNodes(ctx context.Context, cluster string) ([]nodes.Node, error) {
+	return p.nodes, nil
+}
+
+func (p *fakeProvider) DeleteNodes(ctx context.Context, n []nodes.Node) error {
+	for _, node := range n {
+		p.deleted = append(p.deleted, node.String())
+	}
+	return nil
+}
+
+// fakeNode records the commands run on it, which all succeed, cat prints version
+type fakeNode struct {
+	nodes.Node
+	name     string
+	role     string
+	version  string
+	commands []string
+}
+
+func (n *fakeNode) String() string {
+	return n.name
+}
+
+func (n *fakeNode) Role() (string, error) {
+	return n.role, nil
+}
+
+func (n *fakeNode) Command(command string, args ...string) exec.Cmd {
+	return n.CommandContext(context.Background(), command, args...)
+}
+
+func (n *fakeNode) CommandContext(ctx context.Context, command string, args ...string) exec.Cmd {
+	if command == "cat" {
+		return &fakeCmd{output: n.version + "\n"}
+	}
+	n.commands = append(n.commands, strings.Join(append([]string{command}, args...), " "))
+	return &fakeCmd{}
+}
+
+type fakeCmd struct {
+	exec.Cmd
+	output string
+	stdout io.Writer
+}
+
+func (c *fakeCmd) Run() error {
+	if c.stdout != nil {
+		_, err := io.WriteString(c.stdout, c.output)
+		return err
+	}
+	return nil
+}
+
+func (c *fakeCmd) SetStdout(w io.Writer) exec.Cmd {
+	c.stdout = w
+	return c
+}
+
+func (c *fakeCmd) SetStderr(io.Writer) exec.Cmd {
+	return c
+}
+
+func TestDrainArgs(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name        string
+		Version     string
+		Timeout     time.Duration
+		Expected    []string
+		ExpectError bool
+	}{
+		{
+			Name:     "current kubectl",
+			Version:  "v1.20.2",
+			Timeout:  time.Minute,
+			Expected: []string{"drain", "kind-worker", "--ignore-daemonsets", "--force", "--delete-emptydir-data", "--timeout=1m0s"},
+		},
+		{
+			Name:     "kubectl before 1.20",
+			Version:  "v1.19.7",
+			Expected: []string{"drain", "kind-worker", "--ignore-daemonsets", "--force", "--delete-local-data"},
+		},
+		{
+			Name:        "invalid version",
+			Version:     "unknown",
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			args, err := drainArgs(tc.Version, "kind-worker", tc.Timeout)
+			assert.ExpectError(t, tc.ExpectError, err)
+			if err == nil {
+				assert.DeepEqual(t, tc.Expected, args)
+			}
+		})
+	}
+}
+
+func TestRecordReplacement(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kind-replace-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	store := state.NewStore(dir)
+	raw := `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+nodes:
+- role: control-plane
+- role: worker
+- role: worker
+`
+	assert.ExpectError(t, false, store.Write(&state.Cluster{
+		Name:      "kind",
+		Config:    raw,
+		NodeImage: "kindest/node:v1.19.7",
+	}))
+	cfg, err := store.ReadConfig("kind")
+	assert.ExpectError(t, false, err)
+
+	assert.ExpectError(t, false, recordReplacement(store, cfg, 1, "kind-worker3", "kindest/node:v1.20.2"))
+	recorded, err := store.Read("kind")
+	assert.ExpectError(t, false, err)
+	assert.StringEqual(t, "", recorded.NodeImage)
+	updated, err := encoding.Parse([]byte(recorded.Config))
+	assert.ExpectError(t, false, err)
+	updated.Name = "kind"
+	assert.DeepEqual(t, []string{"kind-control-plane", "kind-worker3", "kind-worker2"}, config.NodeNames(updated))
+	for i, image := range []string{"kindest/node:v1.19.7", "kindest/node:v1.20.2", "kindest/node:v1.19.7"} {
+		assert.StringEqual(t, image, updated.Nodes[i].Image)
+	}
+}
+
+func TestDeleteFailedNode(t *testing.T) {
+	t.Parallel()
+	cfg := &config.Cluster{
+		Name: "kind",
+		Nodes: []config.Node{
+			{Role: config.ControlPlaneRole},
+			{Role: config.ControlPlaneRole},
+			{Role: config.WorkerRole, Name: "kind-worker2"},
+		},
+	}
+	status := cli.StatusForLogger(log.NoopLogger{})
+
+	t.Run("joined", func(t *testing.T) {
+		t.Parallel()
+		controller := &fakeNode{name: "kind-control-plane", role: constants.ControlPlaneNodeRoleValue}
+		worker := &fakeNode{name: "kind-worker2", role: constants.WorkerNodeRoleValue}
+		p := &fakeProvider{nodes: []nodes.Node{controller, worker}}
+		assert.ExpectError(t, false, deleteFailedNode(log.NoopLogger{}, status, p, cfg, cfg, "kind-worker2", controller))
+		assert.DeepEqual(t, []string{"kind-worker2"}, p.deleted)
+		assert.DeepEqual(t, []string{
+			"kubectl --kubeconfig=/etc/kubernetes/admin.conf delete node kind-worker2 --ignore-not-found",
+		}, controller.commands)
+	})
+
+	t.Run("not created", func(t *testing.T) {
+		t.Parallel()
+		controller := &fakeNode{name: "kind-control-plane", role: constants.ControlPlaneNodeRoleValue}
+		p := &fakeProvider{nodes: []nodes.Node{controller}}
+		assert.ExpectError(t, false, deleteFailedNode(log.NoopLogger{}, status, p, cfg, cfg, "kind-worker2", controller))
+		assert.DeepEqual(t, []string(nil), p.deleted)
+		assert.DeepEqual(t, []string(nil), controller.commands)
+	})
+}
+
+func TestRemoveNode(t *testing.T) {
+	t.Parallel()
+	cfg := &config.Cluster{
+		Name: "kind",
+		Nodes: []config.Node{
+			{Role: config.ControlPlaneRole},
+			{Role: config.WorkerRole, Name: "kind-worker2"},
+		},
+	}
+	controller := &fakeNode{name: "kind-control-plane", role: constants.ControlPlaneNodeRoleValue, version: "v1.21.1"}
+	old := &fakeNode{name: "kind-worker", role: constants.WorkerNodeRoleValue}
+	p := &fakeProvider{nodes: []nodes.Node{controller, old}}
+	status := cli.StatusForLogger(log.NoopLogger{})
+	// the old node is registered in Kubernetes by its hostname
+	assert.ExpectError(t, false, removeNode(context.Background(), log.NoopLogger{}, status, p, cfg, old, "worker.example.com", controller, time.Minute))
+	assert.DeepEqual(t, []string{
+		"kubectl --kubeconfig=/etc/kubernetes/admin.conf drain worker.example.com --ignore-daemonsets --force --delete-emptydir-data --timeout=1m0s",
+		"kubectl --kubeconfig=/etc/kubernetes/admin.conf delete node worker.example.com",
+	}, controller.commands)
+	assert.DeepEqual(t, []string{"kind-worker"}, p.deleted)
+}
+
+func TestRecordReplacementHostname(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kind-replace-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	store := state.NewStore(dir)
+	raw := `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+nodes:
+- role: control-plane
+- role: worker
+  hostname: worker.example.com
+  machineID: 0123456789abcdef0123456789abcdef
+`
+	assert.ExpectError(t, false, store.Write(&state.Cluster{Name: "kind", Config: raw}))
+	cfg, err := store.ReadConfig("kind")
+	assert.ExpectError(t, false, err)
+
+	assert.ExpectError(t, false, recordReplacement(store, cfg, 1, "kind-worker2", ""))
+	updated, err := store.ReadConfig("kind")
+	assert.ExpectError(t, false, err)
+	assert.StringEqual(t, "kind-worker2", updated.Nodes[1].Name)
+	assert.StringEqual(t, "", updated.Nodes[1].Hostname)
+	assert.StringEqual(t, "", updated.Nodes[1].MachineID)
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package requiredimages determines the images creating a cluster will use
+package requiredimages
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/installgpu"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/presets"
+)
+
+// Sources of required images
+const (
+	SourceNode         = "node"
+	SourceLoadBalancer = "load-balancer"
+	SourceOIDCIssuer   = "oidc-issuer"
+	SourceKubeadm      = "kubeadm"
+	SourcePause        = "pause"
+	SourceCNI          = "cni"
+	SourceStorage      = "storage"
+	SourcePreset       = "preset"
+	SourceGPU          = "gpu"
+)
+
+// Image is an image required to create a cluster
+type Image struct {
+	// Image is the image reference, e.g. "k8s.gcr.io/pause:3.2"
+	Image string `json:"image"`
+	// Source is what uses the image, one of the Source* constants
+	Source string `json:"source"`
+}
+
+// Runner returns a command that runs in a node, or in a container
+// of the node image
+type Runner func(command string, args ...string) exec.Cmd
+
+// ForKubernetes returns the images used to set up Kubernetes on the nodes
+// of cfg, sorted by image. run must run commands on the first control plane
+// node, kubeadmArgs are passed to `kubeadm config images list`
+func ForKubernetes(cfg *config.Cluster, run Runner, kubeadmArgs ...string) ([]Image, error) {
+	images := map[string]string{}
+	// kubeadm images for a control plane we don't manage depend on its version
+	if cfg.ExternalControlPlane.Endpoint == "" {
+		lines, err := exec.OutputLines(run(
+			"kubeadm", append([]string{"config", "images", "list"}, kubeadmArgs...)...,
+		))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list kubeadm images")
+		}
+		for _, line := range lines {
+			if line = strings.TrimSpace(line); line != "" {
+				images[overrideImage(cfg, line)] = kubeadmSource(line)
+			}
+		}
+	}
+	if cfg.Images.Pause != "" {
+		images[cfg.Images.Pause] = SourcePause
+	}
+	// the default manifests deployed after kubeadm
+	manifests := map[string]string{}
+	if !cfg.Networking.DisableDefaultCNI && cfg.ExternalControlPlane.Endpoint == "" {
+		manifests["/kind/manifests/default-cni.yaml"] = SourceCNI
+	}
+	if cfg.Storage.Provisioner == config.LocalPathProvisioner {
+		manifests["/kind/manifests/default-storage.yaml"] = SourceStorage
+	}
+	for manifest, source := range manifests {
+		var raw bytes.Buffer
+		if err := run("cat", manifest).SetStdout(&raw).Run(); err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s", manifest)
+		}
+		for _, image := range ManifestImages(common.OverrideManifestImages(cfg, raw.String())) {
+			images[image] = source
+		}
+	}
+	for _, p := range cfg.Presets {
+		if p == config.ObservabilityPreset {
+			for _, image := range presets.ObservabilityImages {
+				images[common.OverrideImage(cfg, image)] = SourcePreset
+			}
+		}
+		if p == config.NodeProblemDetectorPreset {
+			for _, image := range presets.NodeProblemDetectorImages {
+				images[common.OverrideImage(cfg, image)] = SourcePreset
+			}
+		}
+	}
+	if cfg.GPUDevicePlugin {
+		for _, n := range cfg.Nodes {
+			if n.GPUs != "" {
+				for _, image := range ManifestImages(installgpu.DevicePluginManifest) {
+					images[common.OverrideImage(cfg, image)] = SourceGPU
+				}
+				break
+			}
+		}
+	}
+	return sortImages(images), nil
+}
+
+// kubeadmSource returns the source of an image listed by kubeadm
+func kubeadmSource(image string) string {
+	if imageName(image) == "pause" {
+		return SourcePause
+	}
+	return SourceKubeadm
+}
+
+// overrideImage returns the images override from cfg for an image
+// listed by kubeadm, or image if there is none
+func overrideImage(cfg *config.Cluster, image string) string {
+	switch imageName(image) {
+	case "coredns":
+		if cfg.Images.CoreDNS != "" {
+			return cfg.Images.CoreDNS
+		}
+	case "etcd":
+		if cfg.Images.Etcd != "" {
+			return cfg.Images.Etcd
+		}
+	}
+	return image
+}
+
+// imageName returns the last path element of image without the tag or
+// digest, e.g. "k8s.gcr.io/coredns:1.6.7" becomes "coredns"
+func imageName(image string) string {
+	name := image[strings.LastIndex(image, "/")+1:]
+	if i := strings.IndexAny(name, ":@"); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+func sortImages(images map[string]string) []Image {
+	sorted := make([]Image, 0, len(images))
+	for image, source := range images {
+		sorted = append(sorted, Image{Image: image, Source: source})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Image < sorted[j].Image
+	})
+	return sorted
+}
+
+// manifestImageRE matches container images in a manifest
+var manifestImageRE = regexp.MustCompile(`(?m)^\s*(?:-\s+)?image:\s*["']?([^"'\s]+)["']?\s*$`)
+
+// ManifestImages returns the images referenced by a manifest
+func ManifestImages(manifest string) []string {
+	images := []string{}
+	for _, m := range manifestImageRE.FindAllStringSubmatch(manifest, -1) {
+		images = append(images, m[1])
+	}
+	return images
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package requiredimages
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestManifestImages(t *testing.T) {
+	t.Parallel()
+	manifest := `spec:
+  containers:
+  - image: kindest/kindnetd:v1
+    name: kindnet
+  - name: helper
+    image: "rancher/local-path-provisioner:v0.0.14"
+  initContainers:
+  - image: 'busybox'
+`
+	assert.DeepEqual(t, []string{
+		"kindest/kindnetd:v1",
+		"rancher/local-path-provisioner:v0.0.14",
+		"busybox",
+	}, ManifestImages(manifest))
+}
+
+func TestOverrideImage(t *testing.T) {
+	t.Parallel()
+	cfg := &config.Cluster{
+		Images: config.Images{
+			CoreDNS: "registry.example/coredns:1.7.0",
+		},
+	}
+	assert.StringEqual(t, "registry.example/coredns:1.7.0", overrideImage(cfg, "k8s.gcr.io/coredns:1.6.7"))
+	assert.StringEqual(t, "k8s.gcr.io/etcd:3.4.3-0", overrideImage(cfg, "k8s.gcr.io/etcd:3.4.3-0"))
+	assert.StringEqual(t, SourcePause, kubeadmSource("k8s.gcr.io/pause:3.2"))
+	assert.StringEqual(t, SourceKubeadm, kubeadmSource("k8s.gcr.io/kube-proxy:v1.18.8"))
+}
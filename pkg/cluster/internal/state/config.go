@@ -0,0 +1,48 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/apis/config/encoding"
+)
+
+// ReadConfig returns the defaulted config the named cluster was created with,
+// including any node image override, or the default config if it was not recorded
+func (s *Store) ReadConfig(name string) (*config.Cluster, error) {
+	recorded, err := s.Read(name)
+	if err != nil {
+		return nil, err
+	}
+	var cfg *config.Cluster
+	if recorded == nil || recorded.Config == "" {
+		cfg, err = encoding.Load("")
+	} else {
+		cfg, err = encoding.Parse([]byte(recorded.Config))
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the recorded config of cluster %q", name)
+	}
+	cfg.Name = name
+	if recorded != nil && recorded.NodeImage != "" {
+		for i := range cfg.Nodes {
+			cfg.Nodes[i].Image = recorded.NodeImage
+		}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// lockPollInterval is how often a held lock is retried while waiting
+const lockPollInterval = 500 * time.Millisecond
+
+// lockHolder is recorded in a lock file to identify who holds it
+type lockHolder struct {
+	PID   int       `json:"pid"`
+	Since time.Time `json:"since"`
+}
+
+func (s *Store) lockPath(name string) string {
+	return filepath.Join(s.dir, name+".lock")
+}
+
+// Lock acquires the lock for the named cluster, so that kind processes
+// creating, deleting or exporting the same cluster do not race.
+// If another process holds the lock Lock waits up to wait for it to be
+// released, then fails with errors.ErrClusterBusy. The returned function
+// releases the lock.
+//
+// Where the platform supports it the lock is an flock of the lock file, which
+// is released when the holder exits however it exits, the lock file itself is
+// never removed as that would race with other processes locking it.
+func (s *Store) Lock(ctx context.Context, name string, wait time.Duration) (func(), error) {
+	if s.dir == "" {
+		return func() {}, nil
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return nil, errors.Wrap(err, "failed to create state directory")
+	}
+	path := s.lockPath(name)
+	deadline := time.Now().Add(wait)
+	for {
+		unlock, err := tryLock(path)
+		if err != nil {
+			return nil, err
+		}
+		if unlock != nil {
+			return unlock, nil
+		}
+		if !time.Now().Before(deadline) {
+			return nil, busyError(name, path)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, errors.Wrap(ctx.Err(), "cancelled waiting for the cluster lock")
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+// holderJSON returns the lockHolder for this process
+func holderJSON() ([]byte, error) {
+	return json.Marshal(&lockHolder{PID: os.Getpid(), Since: time.Now().UTC()})
+}
+
+// busyError describes the holder of the lock at path, if it can be read
+func busyError(name, path string) error {
+	holder := &lockHolder{}
+	b, err := ioutil.ReadFile(path)
+	if err == nil {
+		err = json.Unmarshal(b, holder)
+	}
+	if err != nil {
+		return errors.WithReason(
+			errors.Errorf("cluster %q is busy, another kind process holds %s", name, path),
+			errors.ErrClusterBusy,
+		)
+	}
+	return errors.WithReason(
+		errors.Errorf(
+			"cluster %q is busy, kind process %d has held %s since %s%s",
+			name, holder.PID, path, holder.Since.Format(time.RFC3339), staleLockHint,
+		),
+		errors.ErrClusterBusy,
+	)
+}
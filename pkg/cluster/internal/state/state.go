@@ -0,0 +1,236 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package state implements kind's local store of cluster metadata
+package state
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// Cluster is the metadata recorded for a cluster when it is created
+type Cluster struct {
+	// Name is the cluster name
+	Name string `json:"name"`
+	// Provider is the node provider the cluster was created with, e.g. "docker"
+	Provider string `json:"provider"`
+	// CreationTimestamp is when the cluster was created
+	CreationTimestamp time.Time `json:"creationTimestamp"`
+	// Config is the raw cluster config the cluster was created from, if any
+	Config string `json:"config,omitempty"`
+	// NodeImage is the node image override the cluster was created with, if any
+	NodeImage string `json:"nodeImage,omitempty"`
+	// Labels are arbitrary user supplied labels
+	Labels map[string]string `json:"labels,omitempty"`
+	// ExpirationTimestamp is when the cluster expires and may be deleted by
+	// `kind gc`, if it was created with a TTL
+	ExpirationTimestamp *time.Time `json:"expirationTimestamp,omitempty"`
+}
+
+// Ports are the host ports of a cluster, including those chosen for random
+// (zero) ports. Unlike Cluster these are kept when the cluster is deleted so
+// that recreating it can reuse the same ports.
+type Ports struct {
+	// APIServer is the API server host port
+	APIServer int32 `json:"apiServer,omitempty"`
+	// Mappings are the node port mappings
+	Mappings []PortMapping `json:"mappings,omitempty"`
+	// Forwards are the mappings published on another port for a rootless
+	// container runtime, to be forwarded from the requested port by `kind forward`
+	Forwards []Forward `json:"forwards,omitempty"`
+}
+
+// PortMapping is a host port mapped to a node
+type PortMapping struct {
+	// Node is the node container name
+	Node string `json:"node"`
+	// ListenAddress is the host address the port is bound on
+	ListenAddress string `json:"listenAddress"`
+	// HostPort is the port on the host
+	HostPort int32 `json:"hostPort"`
+	// ContainerPort is the port in the node
+	ContainerPort int32 `json:"containerPort"`
+	// Protocol is the mapping protocol, e.g. "TCP"
+	Protocol string `json:"protocol"`
+}
+
+// Forward is a privileged host port that is published on TargetPort instead
+type Forward struct {
+	// ListenAddress is the host address of the requested port
+	ListenAddress string `json:"listenAddress"`
+	// HostPort is the requested port on the host
+	HostPort int32 `json:"hostPort"`
+	// TargetAddress is the host address the mapping is published on instead
+	TargetAddress string `json:"targetAddress"`
+	// TargetPort is the host port the mapping is published on instead
+	TargetPort int32 `json:"targetPort"`
+}
+
+// Progress records the phases completed while creating a cluster, so that
+// a failed creation can be resumed. It is removed once creation succeeds.
+type Progress struct {
+	// Config is the raw cluster config creation was started with, if any
+	Config string `json:"config,omitempty"`
+	// NodeImage is the node image override creation was started with, if any
+	NodeImage string `json:"nodeImage,omitempty"`
+	// Completed are the names of the completed phases, in order
+	Completed []string `json:"completed,omitempty"`
+}
+
+// Store reads and writes cluster metadata in a directory
+type Store struct {
+	dir string
+}
+
+// NewStore returns a Store backed by dir
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// DefaultDir returns the default state directory, ~/.kind/state
+func DefaultDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".kind", "state")
+}
+
+// Default returns a Store backed by DefaultDir()
+func Default() *Store {
+	return NewStore(DefaultDir())
+}
+
+func (s *Store) path(name string) string {
+	return filepath.Join(s.dir, name+".json")
+}
+
+func (s *Store) portsPath(name string) string {
+	return filepath.Join(s.dir, name+".ports.json")
+}
+
+func (s *Store) progressPath(name string) string {
+	return filepath.Join(s.dir, name+".progress.json")
+}
+
+// Write records c, replacing any existing record for the cluster
+func (s *Store) Write(c *Cluster) error {
+	return s.write(s.path(c.Name), c)
+}
+
+// Read returns the record for the named cluster, or nil if there is none
+func (s *Store) Read(name string) (*Cluster, error) {
+	c := &Cluster{}
+	found, err := s.read(s.path(name), c)
+	if err != nil || !found {
+		return nil, err
+	}
+	return c, nil
+}
+
+// WritePorts records the ports chosen for the named cluster
+func (s *Store) WritePorts(name string, p *Ports) error {
+	return s.write(s.portsPath(name), p)
+}
+
+// ReadPorts returns the ports last chosen for the named cluster, or nil if
+// there are none, these may be from a previous cluster of the same name
+func (s *Store) ReadPorts(name string) (*Ports, error) {
+	p := &Ports{}
+	found, err := s.read(s.portsPath(name), p)
+	if err != nil || !found {
+		return nil, err
+	}
+	return p, nil
+}
+
+// WriteProgress records the creation progress of the named cluster
+func (s *Store) WriteProgress(name string, p *Progress) error {
+	return s.write(s.progressPath(name), p)
+}
+
+// ReadProgress returns the creation progress of the named cluster, or nil
+// if there is none
+func (s *Store) ReadProgress(name string) (*Progress, error) {
+	p := &Progress{}
+	found, err := s.read(s.progressPath(name), p)
+	if err != nil || !found {
+		return nil, err
+	}
+	return p, nil
+}
+
+// RemoveProgress deletes the creation progress of the named cluster, if any
+func (s *Store) RemoveProgress(name string) error {
+	return s.remove(s.progressPath(name))
+}
+
+func (s *Store) write(path string, v interface{}) error {
+	if s.dir == "" {
+		return errors.New("no state directory")
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return errors.Wrap(err, "failed to create state directory")
+	}
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode cluster state")
+	}
+	// write then rename so readers never see a partial record
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0600); err != nil {
+		return errors.Wrap(err, "failed to write cluster state")
+	}
+	return errors.Wrap(os.Rename(tmp, path), "failed to write cluster state")
+}
+
+// read decodes path into v, returning false if there is no such record
+func (s *Store) read(path string, v interface{}) (bool, error) {
+	if s.dir == "" {
+		return false, nil
+	}
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Wrap(err, "failed to read cluster state")
+	}
+	return true, errors.Wrapf(json.Unmarshal(b, v), "failed to decode %s", path)
+}
+
+// Remove deletes the record and creation progress for the named cluster, if any
+func (s *Store) Remove(name string) error {
+	if err := s.remove(s.path(name)); err != nil {
+		return err
+	}
+	return s.RemoveProgress(name)
+}
+
+func (s *Store) remove(path string) error {
+	if s.dir == "" {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to remove cluster state")
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestStore(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kind-state-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	s := NewStore(dir)
+
+	// missing records are not an error
+	c, err := s.Read("foo")
+	assert.ExpectError(t, false, err)
+	if c != nil {
+		t.Fatalf("expected no record, got %+v", c)
+	}
+
+	expected := &Cluster{
+		Name:              "foo",
+		Provider:          "docker",
+		CreationTimestamp: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Config:            "kind: Cluster\n",
+		Labels:            map[string]string{"team": "a"},
+	}
+	assert.ExpectError(t, false, s.Write(expected))
+	c, err = s.Read("foo")
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, expected, c)
+
+	assert.ExpectError(t, false, s.Remove("foo"))
+	assert.ExpectError(t, false, s.Remove("foo"))
+	c, err = s.Read("foo")
+	assert.ExpectError(t, false, err)
+	if c != nil {
+		t.Fatalf("expected no record after removal, got %+v", c)
+	}
+}
+
+func TestStorePorts(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kind-state-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	s := NewStore(dir)
+
+	p, err := s.ReadPorts("foo")
+	assert.ExpectError(t, false, err)
+	if p != nil {
+		t.Fatalf("expected no ports, got %+v", p)
+	}
+
+	expected := &Ports{
+		APIServer: 40000,
+		Mappings: []PortMapping{{
+			Node:          "foo-worker",
+			ListenAddress: "0.0.0.0",
+			HostPort:      40001,
+			ContainerPort: 80,
+			Protocol:      "TCP",
+		}},
+	}
+	assert.ExpectError(t, false, s.WritePorts("foo", expected))
+	// ports outlive the cluster record
+	assert.ExpectError(t, false, s.Remove("foo"))
+	p, err = s.ReadPorts("foo")
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, expected, p)
+}
+
+func TestStoreProgress(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kind-state-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	s := NewStore(dir)
+
+	p, err := s.ReadProgress("foo")
+	assert.ExpectError(t, false, err)
+	if p != nil {
+		t.Fatalf("expected no progress, got %+v", p)
+	}
+
+	expected := &Progress{
+		Config:    "kind: Cluster\n",
+		Completed: []string{"config", "kubeadminit"},
+	}
+	assert.ExpectError(t, false, s.WriteProgress("foo", expected))
+	p, err = s.ReadProgress("foo")
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, expected, p)
+
+	// removing the cluster record removes its progress
+	assert.ExpectError(t, false, s.Remove("foo"))
+	p, err = s.ReadProgress("foo")
+	assert.ExpectError(t, false, err)
+	if p != nil {
+		t.Fatalf("expected no progress after removal, got %+v", p)
+	}
+}
+
+func TestStoreReadConfig(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kind-state-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	s := NewStore(dir)
+
+	// without a record this is the default config
+	cfg, err := s.ReadConfig("foo")
+	assert.ExpectError(t, false, err)
+	assert.StringEqual(t, "foo", cfg.Name)
+	if len(cfg.Nodes) != 1 {
+		t.Fatalf("expected the default single node, got %+v", cfg.Nodes)
+	}
+
+	assert.ExpectError(t, false, s.Write(&Cluster{
+		Name:      "foo",
+		Config:    "kind: Cluster\napiVersion: kind.x-k8s.io/v1alpha4\nnodes:\n- role: control-plane\n- role: worker\n",
+		NodeImage: "kindest/node:test",
+	}))
+	cfg, err = s.ReadConfig("foo")
+	assert.ExpectError(t, false, err)
+	if len(cfg.Nodes) != 2 {
+		t.Fatalf("expected the recorded two nodes, got %+v", cfg.Nodes)
+	}
+	for _, n := range cfg.Nodes {
+		assert.StringEqual(t, "kindest/node:test", n.Image)
+	}
+}
+
+func TestStoreLock(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kind-state-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	s := NewStore(dir)
+	ctx := context.Background()
+
+	unlock, err := s.Lock(ctx, "foo", 0)
+	assert.ExpectError(t, false, err)
+
+	// the lock is held
+	_, err = s.Lock(ctx, "foo", 0)
+	assert.ExpectError(t, true, err)
+	assert.BoolEqual(t, true, errors.ReasonForError(err) == errors.ErrClusterBusy)
+
+	// other clusters are not affected
+	unlockBar, err := s.Lock(ctx, "bar", 0)
+	assert.ExpectError(t, false, err)
+	unlockBar()
+
+	// waiting succeeds once the lock is released
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		unlock()
+	}()
+	unlock, err = s.Lock(ctx, "foo", 10*time.Second)
+	assert.ExpectError(t, false, err)
+	unlock()
+}
@@ -0,0 +1,69 @@
+//go:build !windows
+// +build !windows
+
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"os"
+	"syscall"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// staleLockHint is appended to busy errors, an flock is never stale
+const staleLockHint = ""
+
+// tryLock takes an exclusive flock of the lock file at path and records this
+// process as the holder, returning a nil unlock function if it is held
+func tryLock(path string) (func(), error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open cluster lock")
+	}
+	for {
+		err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if err != syscall.EINTR {
+			break
+		}
+	}
+	if err == syscall.EWOULDBLOCK {
+		f.Close()
+		return nil, nil
+	} else if err != nil {
+		f.Close()
+		return nil, errors.Wrap(err, "failed to lock cluster lock")
+	}
+	unlock := func() {
+		_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}
+	// replace the record of any previous holder
+	b, err := holderJSON()
+	if err == nil {
+		err = f.Truncate(0)
+	}
+	if err == nil {
+		_, err = f.WriteAt(b, 0)
+	}
+	if err != nil {
+		unlock()
+		return nil, errors.Wrap(err, "failed to write cluster lock")
+	}
+	return unlock, nil
+}
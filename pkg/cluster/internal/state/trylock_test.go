@@ -0,0 +1,84 @@
+//go:build !windows
+// +build !windows
+
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestStoreLockStale(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kind-state-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	s := NewStore(dir)
+	ctx := context.Background()
+
+	// a process killed while holding the lock leaves the lock file behind
+	cmd := exec.Command("sleep", "60")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start process: %v", err)
+	}
+	if err := cmd.Process.Kill(); err != nil {
+		t.Fatalf("failed to kill process: %v", err)
+	}
+	_ = cmd.Wait()
+	b, err := json.Marshal(&lockHolder{PID: cmd.Process.Pid, Since: time.Now().UTC()})
+	if err != nil {
+		t.Fatalf("failed to encode lock holder: %v", err)
+	}
+	if err := ioutil.WriteFile(s.lockPath("foo"), b, 0600); err != nil {
+		t.Fatalf("failed to write stale lock: %v", err)
+	}
+
+	// which must not keep the cluster busy
+	unlock, err := s.Lock(ctx, "foo", 0)
+	assert.ExpectError(t, false, err)
+	b, err = ioutil.ReadFile(s.lockPath("foo"))
+	if err != nil {
+		t.Fatalf("failed to read lock: %v", err)
+	}
+	holder := &lockHolder{}
+	if err := json.Unmarshal(b, holder); err != nil {
+		t.Fatalf("failed to decode lock holder: %v", err)
+	}
+	assert.DeepEqual(t, os.Getpid(), holder.PID)
+
+	// while held it is busy
+	_, err = s.Lock(ctx, "foo", 0)
+	assert.BoolEqual(t, true, errors.ReasonForError(err) == errors.ErrClusterBusy)
+	unlock()
+
+	// and the lock can be taken again once released
+	unlock, err = s.Lock(ctx, "foo", 0)
+	assert.ExpectError(t, false, err)
+	unlock()
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"os"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// staleLockHint is appended to busy errors, the lock file is left behind if
+// the holder is killed
+const staleLockHint = ", if it is no longer running delete the lock file"
+
+// tryLock creates the lock file at path and records this process as the
+// holder, returning a nil unlock function if it exists
+func tryLock(path string) (func(), error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if os.IsExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to create cluster lock")
+	}
+	defer f.Close()
+	b, err := holderJSON()
+	if err == nil {
+		_, err = f.Write(b)
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return nil, errors.Wrap(err, "failed to write cluster lock")
+	}
+	return func() { _ = os.Remove(path) }, nil
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package swap contains the node swapfile and kubelet swap configuration
+// for nodes with swap
+package swap
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// Path is the swapfile on the node, /var is a volume so unlike the overlay
+// root filesystem it supports swapfiles
+const Path = "/var/swapfile"
+
+// KubeletConfigPath is the node kubelet config written by kubeadm
+const KubeletConfigPath = "/var/lib/kubelet/config.yaml"
+
+// DisableCommand disables the node swapfile, if any. swapoff -a must not be
+// used, /proc/swaps is not namespaced and lists the host swap devices
+const DisableCommand = "[ ! -f " + Path + " ] || swapoff " + Path
+
+// minVersion is the first Kubernetes version with the NodeSwap feature
+var minVersion = version.MustParseSemantic("v1.22.0")
+
+// defaultOnVersion is the first Kubernetes version with NodeSwap enabled by default
+var defaultOnVersion = version.MustParseSemantic("v1.30.0")
+
+// EnableCommand returns the command creating and enabling a swapfile of size
+// bytes on the node, fallocate is not supported by every filesystem so this
+// falls back to writing the whole file
+func EnableCommand(size int64) string {
+	mib := (size + 1<<20 - 1) >> 20
+	return fmt.Sprintf(
+		"(fallocate -l %[2]d %[1]s || dd if=/dev/zero of=%[1]s bs=1M count=%[3]d) && chmod 600 %[1]s && mkswap %[1]s && swapon %[1]s",
+		Path, size, mib,
+	)
+}
+
+// KubeletConfig returns the kubelet config file contents with swapBehavior,
+// enabling the NodeSwap feature gate on kubeVersion if it is off by default
+func KubeletConfig(contents []byte, behavior config.SwapBehavior, kubeVersion string) ([]byte, error) {
+	v, err := version.ParseGeneric(kubeVersion)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse kubernetes version %q", kubeVersion)
+	}
+	if v.LessThan(minVersion) {
+		return nil, errors.Errorf("swap requires Kubernetes %s or later, the node is %s", minVersion, kubeVersion)
+	}
+	if behavior == "" {
+		behavior = config.LimitedSwapBehavior
+	}
+	cfg := map[string]interface{}{}
+	if err := yaml.Unmarshal(contents, &cfg); err != nil {
+		return nil, errors.Wrap(err, "failed to parse kubelet config")
+	}
+	cfg["failSwapOn"] = false
+	cfg["memorySwap"] = map[string]interface{}{"swapBehavior": string(behavior)}
+	if v.LessThan(defaultOnVersion) {
+		gates, _ := cfg["featureGates"].(map[string]interface{})
+		if gates == nil {
+			gates = map[string]interface{}{}
+		}
+		// an explicit value from the featureGates config wins
+		if _, ok := gates["NodeSwap"]; !ok {
+			gates["NodeSwap"] = true
+		}
+		cfg["featureGates"] = gates
+	}
+	return yaml.Marshal(cfg)
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package swap
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestEnableCommand(t *testing.T) {
+	t.Parallel()
+	assert.StringEqual(t,
+		"(fallocate -l 1610612737 /var/swapfile || dd if=/dev/zero of=/var/swapfile bs=1M count=1537) && chmod 600 /var/swapfile && mkswap /var/swapfile && swapon /var/swapfile",
+		EnableCommand(1536<<20+1),
+	)
+}
+
+func TestKubeletConfig(t *testing.T) {
+	t.Parallel()
+	const kubeadmConfig = `apiVersion: kubelet.config.k8s.io/v1beta1
+kind: KubeletConfiguration
+memorySwap: {}
+`
+	cases := []struct {
+		Name        string
+		Contents    string
+		Behavior    config.SwapBehavior
+		KubeVersion string
+		Expected    string
+		ExpectError bool
+	}{
+		{
+			Name:        "default behavior",
+			Contents:    kubeadmConfig,
+			KubeVersion: "v1.31.0",
+			Expected: `apiVersion: kubelet.config.k8s.io/v1beta1
+failSwapOn: false
+kind: KubeletConfiguration
+memorySwap:
+  swapBehavior: LimitedSwap
+`,
+		},
+		{
+			Name:        "feature gate off by default",
+			Contents:    kubeadmConfig + "featureGates:\n  Foo: true\n",
+			Behavior:    config.NoSwapBehavior,
+			KubeVersion: "v1.28.3",
+			Expected: `apiVersion: kubelet.config.k8s.io/v1beta1
+failSwapOn: false
+featureGates:
+  Foo: true
+  NodeSwap: true
+kind: KubeletConfiguration
+memorySwap:
+  swapBehavior: NoSwap
+`,
+		},
+		{
+			Name:        "explicit feature gate",
+			Contents:    kubeadmConfig + "featureGates:\n  NodeSwap: false\n",
+			KubeVersion: "v1.28.3",
+			Expected: `apiVersion: kubelet.config.k8s.io/v1beta1
+failSwapOn: false
+featureGates:
+  NodeSwap: false
+kind: KubeletConfiguration
+memorySwap:
+  swapBehavior: LimitedSwap
+`,
+		},
+		{
+			Name:        "too old",
+			Contents:    kubeadmConfig,
+			KubeVersion: "v1.21.1",
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			out, err := KubeletConfig([]byte(tc.Contents), tc.Behavior, tc.KubeVersion)
+			assert.ExpectError(t, tc.ExpectError, err)
+			if err == nil {
+				assert.StringEqual(t, tc.Expected, string(out))
+			}
+		})
+	}
+}
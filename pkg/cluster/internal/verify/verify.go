@@ -0,0 +1,420 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package verify implements a quick smoke test of a running cluster
+package verify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/configureregistries"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// Namespace is where the test pods are created, it is deleted after the
+// checks
+const Namespace = "kind-verify"
+
+// DefaultImage is the image of the test pods, it needs bash and getent.
+// Node images preload it as the local-path-provisioner helper image, so the
+// test pods do not pull anything
+const DefaultImage = "k8s.gcr.io/build-image/debian-base:v2.1.0"
+
+// DefaultTimeout bounds each check if Options.Timeout is zero
+const DefaultTimeout = time.Minute
+
+// Options configures the checks
+type Options struct {
+	// Image is the image of the test pods, DefaultImage if empty
+	Image string
+	// PullImages are pulled on a node to check the configured registries and
+	// registry mirrors, each registry is checked with the first of these or
+	// else Image hosted by it
+	PullImages []string
+	// Timeout bounds each check, DefaultTimeout if zero
+	Timeout time.Duration
+}
+
+// Result is the outcome of one check
+type Result struct {
+	// Name describes the check, e.g. "cluster DNS resolves"
+	Name string
+	// Err is why the check failed, nil if it passed or was skipped
+	Err error
+	// Skipped is why the check was not run, if it was not
+	Skipped string
+	// Duration is how long the check took
+	Duration time.Duration
+}
+
+// Passed returns true if the check ran without error
+func (r *Result) Passed() bool {
+	return r.Err == nil && r.Skipped == ""
+}
+
+// Cluster runs the checks against cluster, cfg is its config. The checks
+// are run in order and a failed check skips the checks that depend on it.
+// The returned error is only for failing to run the checks at all
+func Cluster(ctx context.Context, p provider.Provider, cfg *config.Cluster, opts Options) ([]Result, error) {
+	if opts.Image == "" {
+		opts.Image = DefaultImage
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	n, err := p.ListNodes(ctx, cfg.Name)
+	if err != nil {
+		return nil, err
+	}
+	if len(n) == 0 {
+		return nil, errors.ClusterNotFound(cfg.Name)
+	}
+	node, err := nodeutils.BootstrapControlPlaneNode(n)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &verifier{ctx: ctx, node: node, timeout: opts.Timeout}
+	results := []Result{}
+	run := func(name string, check func() error) bool {
+		r := v.run(name, check)
+		results = append(results, r)
+		return r.Passed()
+	}
+	skip := func(name, reason string) {
+		results = append(results, Result{Name: name, Skipped: reason})
+	}
+
+	if cfg.ControlPlaneMode == config.APIServerOnlyMode {
+		const reason = "there is no scheduler or registered node in the APIServerOnly control plane mode"
+		skip("nodes are ready", reason)
+		skip("pod is scheduled and running", reason)
+		skip("cluster DNS resolves", reason)
+		skip("ClusterIP service is reachable", reason)
+	} else {
+		ready := run("nodes are ready", v.nodesReady)
+		running := false
+		if ready {
+			running = run("pod is scheduled and running", func() error {
+				return v.startPod(opts.Image)
+			})
+		} else {
+			skip("pod is scheduled and running", "the nodes are not ready")
+		}
+		if running {
+			run("cluster DNS resolves", v.dns)
+			run("ClusterIP service is reachable", v.service)
+		} else {
+			const reason = "the test pod is not running"
+			skip("cluster DNS resolves", reason)
+			skip("ClusterIP service is reachable", reason)
+		}
+		// leave nothing behind, without waiting for the pods to go away
+		_, _ = v.kubectl(ctx, "delete", "namespace", Namespace, "--ignore-not-found", "--wait=false")
+	}
+
+	mirrors, err := v.mirrors()
+	if err != nil {
+		results = append(results, Result{Name: "registries respond", Err: err})
+		return results, nil
+	}
+	endpoints := registryEndpoints(cfg, mirrors)
+	if len(endpoints) == 0 {
+		skip("registries respond", "no registries or registry mirrors are configured")
+	}
+	for _, e := range endpoints {
+		e := e // capture loop variable
+		run(fmt.Sprintf("registry %s responds", e.URL), func() error {
+			return v.registry(e)
+		})
+	}
+	pullImages := append(append([]string{}, opts.PullImages...), opts.Image)
+	for _, host := range registryHosts(cfg, mirrors) {
+		name := fmt.Sprintf("images pull from %s", host)
+		image := imageFrom(pullImages, host)
+		if image == "" {
+			skip(name, "no image from this registry is known, pass one with --pull-image")
+			continue
+		}
+		run(name, func() error {
+			return v.pull(image)
+		})
+	}
+	return results, nil
+}
+
+// verifier runs the checks with kubectl and curl on node
+type verifier struct {
+	ctx     context.Context
+	node    nodes.Node
+	timeout time.Duration
+}
+
+// run runs and times check
+func (v *verifier) run(name string, check func() error) Result {
+	start := time.Now()
+	err := check()
+	return Result{Name: name, Err: err, Duration: time.Since(start)}
+}
+
+func (v *verifier) nodesReady() error {
+	_, err := v.kubectl(v.ctx, "wait", "--for=condition=Ready", "nodes", "--all", "--timeout="+v.timeout.String())
+	return err
+}
+
+// startPod creates the test pod and waits for it to be ready. It starts by
+// removing the namespace if a previous run left it behind
+func (v *verifier) startPod(image string) error {
+	if _, err := v.kubectl(v.ctx, "delete", "namespace", Namespace, "--ignore-not-found", "--timeout="+v.timeout.String()); err != nil {
+		return errors.Wrap(err, "failed to remove the test namespace of a previous run")
+	}
+	cmd := v.node.CommandContext(v.ctx, "kubectl", "--kubeconfig=/etc/kubernetes/admin.conf", "apply", "-f", "-")
+	var stderr bytes.Buffer
+	if err := cmd.SetStdin(strings.NewReader(manifests(image))).SetStderr(&stderr).Run(); err != nil {
+		return withOutput(errors.Wrap(err, "failed to create the test pod"), stderr.String())
+	}
+	_, err := v.kubectl(v.ctx, "--namespace", Namespace, "wait", "--for=condition=Ready", "pods", "--all", "--timeout="+v.timeout.String())
+	return err
+}
+
+func (v *verifier) dns() error {
+	ctx, cancel := context.WithTimeout(v.ctx, v.timeout)
+	defer cancel()
+	_, err := v.kubectl(ctx, "--namespace", Namespace, "exec", "client", "--", "getent", "hosts", "kubernetes.default")
+	return err
+}
+
+// service requests the CoreDNS metrics through the kube-dns ClusterIP
+// service, this needs no server image
+func (v *verifier) service() error {
+	ctx, cancel := context.WithTimeout(v.ctx, v.timeout)
+	defer cancel()
+	out, err := v.kubectl(ctx, "--namespace", Namespace, "exec", "client", "--", "bash", "-c", serviceScript)
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(string(out), " 200 ") {
+		return errors.Errorf("unexpected response from the kube-dns service: %q", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// serviceScript prints the status line of the CoreDNS metrics endpoint
+const serviceScript = `exec 3<>/dev/tcp/kube-dns.kube-system/9153 && printf 'GET /metrics HTTP/1.0\r\n\r\n' >&3 && head -n 1 <&3`
+
+// pull pulls image with crictl on v.node, through the configured registry
+// host files and mirrors
+func (v *verifier) pull(image string) error {
+	ctx, cancel := context.WithTimeout(v.ctx, v.timeout)
+	defer cancel()
+	var stderr bytes.Buffer
+	if err := v.node.CommandContext(ctx, "crictl", "pull", image).SetStderr(&stderr).Run(); err != nil {
+		return withOutput(errors.Wrapf(err, "failed to pull %s", image), stderr.String())
+	}
+	return nil
+}
+
+// registryEndpoint is a registry URL and the curl flags to verify it with
+type registryEndpoint struct {
+	URL  string
+	Args []string
+}
+
+// mirrors returns the registry mirrors in the containerd config of v.node
+func (v *verifier) mirrors() (map[string][]string, error) {
+	var info bytes.Buffer
+	if err := v.node.CommandContext(v.ctx, "crictl", "info").SetStdout(&info).Run(); err != nil {
+		return nil, errors.Wrap(err, "failed to read the containerd config")
+	}
+	return parseMirrors(info.Bytes())
+}
+
+// registryEndpoints returns the registries of cfg and the endpoints of
+// mirrors, the registry mirrors in the containerd config
+func registryEndpoints(cfg *config.Cluster, mirrors map[string][]string) []registryEndpoint {
+	endpoints := []registryEndpoint{}
+	for _, r := range cfg.Registries {
+		e := registryEndpoint{URL: configureregistries.Server(r)}
+		if r.InsecureSkipVerify {
+			e.Args = append(e.Args, "--insecure")
+		}
+		if r.CA != "" {
+			e.Args = append(e.Args, "--cacert", configureregistries.CAPath(r.Host))
+		}
+		endpoints = append(endpoints, e)
+	}
+	for _, m := range mirrorEndpoints(mirrors) {
+		endpoints = append(endpoints, registryEndpoint{URL: m})
+	}
+	return endpoints
+}
+
+// registryHosts returns the hosts of the registries of cfg and of the
+// registries with mirrors, sorted and without duplicates
+func registryHosts(cfg *config.Cluster, mirrors map[string][]string) []string {
+	seen := map[string]bool{}
+	hosts := []string{}
+	add := func(host string) {
+		// "*" configures the mirrors of every registry
+		if host != "*" && !seen[host] {
+			seen[host] = true
+			hosts = append(hosts, host)
+		}
+	}
+	for _, r := range cfg.Registries {
+		add(r.Host)
+	}
+	for host, endpoints := range mirrors {
+		if len(endpoints) > 0 {
+			add(host)
+		}
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// imageFrom returns the first of images hosted by the registry host
+func imageFrom(images []string, host string) string {
+	for _, image := range images {
+		if imageHost(image) == host {
+			return image
+		}
+	}
+	return ""
+}
+
+// imageHost returns the registry host of image, as containerd resolves it
+func imageHost(image string) string {
+	i := strings.Index(image, "/")
+	if i == -1 {
+		return "docker.io"
+	}
+	first := image[:i]
+	if strings.ContainsAny(first, ".:") || first == "localhost" {
+		return first
+	}
+	return "docker.io"
+}
+
+// registry checks that e answers registry API requests, credentials are not
+// needed as an unauthorized response still shows the registry is serving
+func (v *verifier) registry(e registryEndpoint) error {
+	ctx, cancel := context.WithTimeout(v.ctx, v.timeout)
+	defer cancel()
+	args := append([]string{
+		"--silent", "--show-error", "--output", "/dev/null",
+		"--max-time", fmt.Sprint(int(v.timeout.Seconds())),
+	}, e.Args...)
+	var stderr bytes.Buffer
+	cmd := v.node.CommandContext(ctx, "curl", append(args, strings.TrimSuffix(e.URL, "/")+"/v2/")...)
+	if err := cmd.SetStderr(&stderr).Run(); err != nil {
+		return withOutput(err, stderr.String())
+	}
+	return nil
+}
+
+// parseMirrors returns the registry mirror endpoints by registry host from
+// the crictl info output
+func parseMirrors(info []byte) (map[string][]string, error) {
+	parsed := struct {
+		Config struct {
+			Registry struct {
+				Mirrors map[string]struct {
+					Endpoint []string `json:"endpoint"`
+				} `json:"mirrors"`
+			} `json:"registry"`
+		} `json:"config"`
+	}{}
+	if err := json.Unmarshal(info, &parsed); err != nil {
+		return nil, errors.Wrap(err, "failed to parse crictl info")
+	}
+	mirrors := map[string][]string{}
+	for host, m := range parsed.Config.Registry.Mirrors {
+		mirrors[host] = m.Endpoint
+	}
+	return mirrors, nil
+}
+
+// mirrorEndpoints returns the endpoints of mirrors, sorted and without
+// duplicates
+func mirrorEndpoints(mirrors map[string][]string) []string {
+	seen := map[string]bool{}
+	endpoints := []string{}
+	for _, m := range mirrors {
+		for _, e := range m {
+			if !seen[e] {
+				seen[e] = true
+				endpoints = append(endpoints, e)
+			}
+		}
+	}
+	sort.Strings(endpoints)
+	return endpoints
+}
+
+// kubectl runs kubectl with the admin kubeconfig on v.node and returns the
+// output, errors include stderr
+func (v *verifier) kubectl(ctx context.Context, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := v.node.CommandContext(ctx,
+		"kubectl", append([]string{"--kubeconfig=/etc/kubernetes/admin.conf"}, args...)...,
+	)
+	if err := cmd.SetStdout(&stdout).SetStderr(&stderr).Run(); err != nil {
+		return stdout.Bytes(), withOutput(err, stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
+
+// withOutput wraps err with the command output, if any
+func withOutput(err error, output string) error {
+	if msg := strings.TrimSpace(output); msg != "" {
+		return errors.Wrap(err, msg)
+	}
+	return err
+}
+
+// manifests returns the test namespace and client pod
+func manifests(image string) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: Namespace
+metadata:
+  name: %[1]s
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: client
+  namespace: %[1]s
+spec:
+  terminationGracePeriodSeconds: 0
+  containers:
+  - name: client
+    image: %[2]s
+    imagePullPolicy: IfNotPresent
+    command: ["sleep", "3600"]
+`, Namespace, image)
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verify
+
+import (
+	"errors"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestMirrorEndpoints(t *testing.T) {
+	t.Parallel()
+	info := []byte(`{
+  "status": {},
+  "config": {
+    "registry": {
+      "mirrors": {
+        "docker.io": {"endpoint": ["http://kind-registry:5000", "https://registry-1.docker.io"]},
+        "localhost:5000": {"endpoint": ["http://kind-registry:5000"]}
+      }
+    }
+  }
+}`)
+	mirrors, err := parseMirrors(info)
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, []string{"http://kind-registry:5000", "https://registry-1.docker.io"}, mirrorEndpoints(mirrors))
+
+	cfg := &config.Cluster{Registries: []config.Registry{{Host: "registry.local:5000"}}}
+	assert.DeepEqual(t, []string{"docker.io", "localhost:5000", "registry.local:5000"}, registryHosts(cfg, mirrors))
+
+	mirrors, err = parseMirrors([]byte(`{"config": {"registry": {"configPath": "/etc/containerd/certs.d"}}}`))
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, []string{}, mirrorEndpoints(mirrors))
+
+	_, err = parseMirrors([]byte(`not json`))
+	assert.ExpectError(t, true, err)
+}
+
+func TestImageFrom(t *testing.T) {
+	t.Parallel()
+	images := []string{"localhost:5000/app:v1", "nginx:1.19", DefaultImage}
+	assert.StringEqual(t, "nginx:1.19", imageFrom(images, "docker.io"))
+	assert.StringEqual(t, "localhost:5000/app:v1", imageFrom(images, "localhost:5000"))
+	assert.StringEqual(t, DefaultImage, imageFrom(images, "k8s.gcr.io"))
+	assert.StringEqual(t, "", imageFrom(images, "quay.io"))
+	assert.StringEqual(t, "docker.io", imageHost("library/nginx"))
+	assert.StringEqual(t, "localhost", imageHost("localhost/app"))
+}
+
+func TestResultPassed(t *testing.T) {
+	t.Parallel()
+	assert.BoolEqual(t, true, (&Result{Name: "ok"}).Passed())
+	assert.BoolEqual(t, false, (&Result{Name: "failed", Err: errors.New("boom")}).Passed())
+	assert.BoolEqual(t, false, (&Result{Name: "skipped", Skipped: "not needed"}).Passed())
+}
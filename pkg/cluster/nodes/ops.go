@@ -0,0 +1,263 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodes
+
+import (
+	"archive/tar"
+	"context"
+	"io"
+	"os"
+	osexec "os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// ExecOptions configures ExecStream
+type ExecOptions struct {
+	// Command is the command and arguments to run on the node
+	Command []string
+	// Env is additional environment, each entry of the form "key=value"
+	Env []string
+	// Stdin, Stdout, and Stderr are connected to the command if non-nil
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// ExecStream runs a command on the node, streaming stdin, stdout, and stderr
+// It returns the command's exit code, a non-zero exit code is not an error.
+// err is only set if the command could not be run at all.
+func ExecStream(ctx context.Context, n Node, opts ExecOptions) (exitCode int, err error) {
+	if len(opts.Command) == 0 {
+		return -1, errors.New("no command specified")
+	}
+	cmd := n.CommandContext(ctx, opts.Command[0], opts.Command[1:]...)
+	if len(opts.Env) > 0 {
+		cmd.SetEnv(opts.Env...)
+	}
+	if opts.Stdin != nil {
+		cmd.SetStdin(opts.Stdin)
+	}
+	if opts.Stdout != nil {
+		cmd.SetStdout(opts.Stdout)
+	}
+	if opts.Stderr != nil {
+		cmd.SetStderr(opts.Stderr)
+	}
+	if err := cmd.Run(); err != nil {
+		if code, ok := exitCodeFor(err); ok {
+			return code, nil
+		}
+		return -1, err
+	}
+	return 0, nil
+}
+
+// exitCodeFor returns the exit code of the process that produced err, if any
+func exitCodeFor(err error) (int, bool) {
+	if runErr, ok := err.(*exec.RunError); ok {
+		err = runErr.Inner
+	}
+	if exitErr, ok := err.(*osexec.ExitError); ok {
+		return exitErr.ExitCode(), true
+	}
+	return 0, false
+}
+
+// CopyTo copies the file or directory at hostPath to nodePath on the node
+// nodePath is the full destination path, not the parent directory
+func CopyTo(ctx context.Context, n Node, hostPath, nodePath string) error {
+	nodeDir, nodeBase := path.Split(path.Clean(nodePath))
+	if nodeDir == "" {
+		return errors.Errorf("node path must be absolute: %q", nodePath)
+	}
+	if err := n.CommandContext(ctx, "mkdir", "-p", nodeDir).Run(); err != nil {
+		return errors.Wrapf(err, "failed to create directory %q", nodeDir)
+	}
+	// stream a tarball of hostPath to tar on the node
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeTar(pw, hostPath, nodeBase))
+	}()
+	defer pr.Close()
+	cmd := n.CommandContext(ctx, "tar", "--no-same-owner", "-x", "-C", nodeDir).SetStdin(pr)
+	if err := cmd.Run(); err != nil {
+		return errors.Wrapf(err, "failed to copy %q to node", hostPath)
+	}
+	return nil
+}
+
+// CopyFrom copies the file or directory at nodePath on the node to hostPath
+// hostPath is the full destination path, not the parent directory
+func CopyFrom(ctx context.Context, n Node, nodePath, hostPath string) error {
+	nodeDir, nodeBase := path.Split(path.Clean(nodePath))
+	if nodeDir == "" {
+		return errors.Errorf("node path must be absolute: %q", nodePath)
+	}
+	pr, pw := io.Pipe()
+	cmd := n.CommandContext(ctx, "tar", "-c", "-C", nodeDir, nodeBase).SetStdout(pw)
+	go func() {
+		pw.CloseWithError(cmd.Run())
+	}()
+	defer pr.Close()
+	if err := readTar(pr, nodeBase, hostPath); err != nil {
+		return errors.Wrapf(err, "failed to copy %q from node", nodePath)
+	}
+	return nil
+}
+
+// LoadImage loads an image onto the node, where archive is a Reader over an
+// image archive as produced by `docker save`
+func LoadImage(ctx context.Context, n Node, archive io.Reader) error {
+	cmd := n.CommandContext(ctx, "ctr", "--namespace=k8s.io", "images", "import", "-").SetStdin(archive)
+	if err := cmd.Run(); err != nil {
+		return errors.Wrap(err, "failed to load image")
+	}
+	return nil
+}
+
+// writeTar writes src to w as a tar archive, with src renamed to name
+func writeTar(w io.Writer, src, name string) error {
+	tw := tar.NewWriter(w)
+	err := filepath.Walk(src, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, file)
+		if err != nil {
+			return err
+		}
+		link := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(file); err != nil {
+				return err
+			}
+		}
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		hdr.Name = path.Join(name, filepath.ToSlash(rel))
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// readTar extracts the tar archive from r, renaming the top level entry
+// name to dest
+func readTar(r io.Reader, name, dest string) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		// only extract entries under name, rejecting anything escaping it
+		clean := path.Clean(hdr.Name)
+		rel := strings.TrimPrefix(strings.TrimPrefix(clean, name), "/")
+		if clean != name && !strings.HasPrefix(clean, name+"/") {
+			return errors.Errorf("unexpected path in archive: %q", hdr.Name)
+		}
+		target := filepath.Join(dest, filepath.FromSlash(rel))
+		// never write through a symlink, e.g. one from an earlier entry
+		if err := checkNoSymlinks(dest, target); err != nil {
+			return err
+		}
+		mode := os.FileMode(hdr.Mode).Perm()
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, mode); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if !linkWithin(dest, target, hdr.Linkname) {
+				return errors.Errorf("symlink %q in archive points outside of the destination: %q", hdr.Name, hdr.Linkname)
+			}
+			if err := os.Symlink(hdr.Linkname, target); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			f.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// linkWithin returns whether the symlink at target to linkname stays within
+// dest, absolute links are never allowed
+func linkWithin(dest, target, linkname string) bool {
+	if filepath.IsAbs(linkname) || path.IsAbs(linkname) {
+		return false
+	}
+	resolved := filepath.Join(filepath.Dir(target), filepath.FromSlash(linkname))
+	rel, err := filepath.Rel(dest, resolved)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// checkNoSymlinks returns an error if target or any of its parents below
+// dest is an existing symlink
+func checkNoSymlinks(dest, target string) error {
+	rel, err := filepath.Rel(dest, target)
+	if err != nil || rel == "." {
+		return err
+	}
+	current := dest
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if os.IsNotExist(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return errors.Errorf("refusing to write %q through the symlink %q", target, current)
+		}
+	}
+	return nil
+}
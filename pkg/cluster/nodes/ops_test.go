@@ -0,0 +1,155 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodes
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTarRoundTrip(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kind-nodes-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, "src")
+	if err := os.MkdirAll(filepath.Join(src, "sub"), 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(src, "sub", "file"), []byte("hello"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeTar(pw, src, "renamed"))
+	}()
+	dest := filepath.Join(dir, "dest")
+	if err := readTar(pr, "renamed", dest); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	contents, err := ioutil.ReadFile(filepath.Join(dest, "sub", "file"))
+	if err != nil {
+		t.Fatalf("failed to read copied file: %v", err)
+	}
+	if string(contents) != "hello" {
+		t.Errorf("expected %q, got %q", "hello", string(contents))
+	}
+}
+
+func TestReadTarRejectsUnexpectedPaths(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kind-nodes-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "file"), []byte("hello"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeTar(pw, filepath.Join(dir, "file"), "../escape"))
+	}()
+	defer pr.Close()
+	if err := readTar(pr, "file", filepath.Join(dir, "dest")); err == nil {
+		t.Errorf("expected an error for a path outside of the expected entry")
+	}
+}
+
+func TestReadTarSymlinks(t *testing.T) {
+	t.Parallel()
+	type entry struct {
+		name     string
+		linkname string // a symlink if set, otherwise a file
+	}
+	cases := []struct {
+		Name          string
+		Entries       []entry
+		ExpectedError bool
+	}{
+		{
+			Name:    "relative link within the destination",
+			Entries: []entry{{name: "name/sub/file"}, {name: "name/link", linkname: "sub/file"}},
+		},
+		{
+			Name:          "absolute link",
+			Entries:       []entry{{name: "name/x", linkname: "/etc"}},
+			ExpectedError: true,
+		},
+		{
+			Name:          "relative link escaping the destination",
+			Entries:       []entry{{name: "name/sub/x", linkname: "../../etc"}},
+			ExpectedError: true,
+		},
+		{
+			Name:          "writing through an earlier link",
+			Entries:       []entry{{name: "name/sub/file"}, {name: "name/x", linkname: "sub"}, {name: "name/x/passwd"}},
+			ExpectedError: true,
+		},
+		{
+			Name:          "overwriting an earlier link",
+			Entries:       []entry{{name: "name/sub/file"}, {name: "name/x", linkname: "sub/file"}, {name: "name/x"}},
+			ExpectedError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			dir, err := ioutil.TempDir("", "kind-nodes-test")
+			if err != nil {
+				t.Fatalf("failed to create temp dir: %v", err)
+			}
+			defer os.RemoveAll(dir)
+			var buf bytes.Buffer
+			tw := tar.NewWriter(&buf)
+			for _, e := range tc.Entries {
+				hdr := &tar.Header{Name: e.name, Mode: 0644, Typeflag: tar.TypeReg, Size: 5}
+				if e.linkname != "" {
+					hdr = &tar.Header{Name: e.name, Mode: 0777, Typeflag: tar.TypeSymlink, Linkname: e.linkname}
+				}
+				if err := tw.WriteHeader(hdr); err != nil {
+					t.Fatalf("failed to write header: %v", err)
+				}
+				if e.linkname == "" {
+					if _, err := tw.Write([]byte("hello")); err != nil {
+						t.Fatalf("failed to write file: %v", err)
+					}
+				}
+			}
+			if err := tw.Close(); err != nil {
+				t.Fatalf("failed to close archive: %v", err)
+			}
+			err = readTar(&buf, "name", filepath.Join(dir, "dest"))
+			if tc.ExpectedError && err == nil {
+				t.Errorf("expected an error")
+			} else if !tc.ExpectedError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
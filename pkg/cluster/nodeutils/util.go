@@ -18,6 +18,7 @@ package nodeutils
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 	"path"
@@ -42,6 +43,41 @@ func KubeVersion(n nodes.Node) (version string, err error) {
 	return lines[0], nil
 }
 
+// ContainerdVersion returns the version of containerd installed on the node
+func ContainerdVersion(n nodes.Node) (string, error) {
+	// containerd github.com/containerd/containerd v1.4.0 09814d4...
+	lines, err := exec.OutputLines(n.Command("containerd", "--version"))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get containerd version")
+	}
+	if len(lines) != 1 {
+		return "", errors.Errorf("containerd version should only be one line, got %d lines", len(lines))
+	}
+	fields := strings.Fields(lines[0])
+	if len(fields) < 3 {
+		return "", errors.Errorf("unexpected containerd version output: %q", lines[0])
+	}
+	return fields[2], nil
+}
+
+// WaitForBoot blocks until the init system of each node has finished booting,
+// e.g. after the node containers have been restarted
+func WaitForBoot(ctx context.Context, n ...nodes.Node) error {
+	fns := make([]func() error, 0, len(n))
+	for _, node := range n {
+		node := node // capture loop variable
+		fns = append(fns, func() error {
+			// this exits non-zero for a degraded system, which is fine here
+			// so we only need it to block until boot has finished
+			_, err := nodes.ExecStream(ctx, node, nodes.ExecOptions{
+				Command: []string{"systemctl", "is-system-running", "--wait"},
+			})
+			return errors.Wrapf(err, "failed waiting for node %s to boot", node.String())
+		})
+	}
+	return errors.UntilErrorConcurrent(fns)
+}
+
 // WriteFile writes content to dest on the node
 func WriteFile(n nodes.Node, dest, content string) error {
 	// create destination directory
@@ -75,12 +111,9 @@ func CopyNodeToNode(a, b nodes.Node, file string) error {
 }
 
 // LoadImageArchive loads image onto the node, where image is a Reader over an image archive
+// See also nodes.LoadImage
 func LoadImageArchive(n nodes.Node, image io.Reader) error {
-	cmd := n.Command("ctr", "--namespace=k8s.io", "images", "import", "-").SetStdin(image)
-	if err := cmd.Run(); err != nil {
-		return errors.Wrap(err, "failed to load image")
-	}
-	return nil
+	return nodes.LoadImage(context.Background(), n, image)
 }
 
 // ImageID returns ID of image on the node with the given image name if present
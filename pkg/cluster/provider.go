@@ -17,19 +17,42 @@ limitations under the License.
 package cluster
 
 import (
+	"context"
+	"io"
+	"net"
+	"os"
 	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"sigs.k8s.io/kind/pkg/cluster/constants"
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/events"
 	"sigs.k8s.io/kind/pkg/log"
 
+	internalartifacts "sigs.k8s.io/kind/pkg/cluster/internal/artifacts"
+	internalclone "sigs.k8s.io/kind/pkg/cluster/internal/clone"
+	"sigs.k8s.io/kind/pkg/cluster/internal/compose"
 	internalcreate "sigs.k8s.io/kind/pkg/cluster/internal/create"
 	internaldelete "sigs.k8s.io/kind/pkg/cluster/internal/delete"
+	internaldisk "sigs.k8s.io/kind/pkg/cluster/internal/disk"
+	internalexpose "sigs.k8s.io/kind/pkg/cluster/internal/expose"
+	internalheal "sigs.k8s.io/kind/pkg/cluster/internal/heal"
+	"sigs.k8s.io/kind/pkg/cluster/internal/inspect"
+	internaljoin "sigs.k8s.io/kind/pkg/cluster/internal/join"
 	"sigs.k8s.io/kind/pkg/cluster/internal/kubeconfig"
+	internallogs "sigs.k8s.io/kind/pkg/cluster/internal/logs"
+	internalnetwork "sigs.k8s.io/kind/pkg/cluster/internal/network"
 	"sigs.k8s.io/kind/pkg/cluster/internal/providers/docker"
 	"sigs.k8s.io/kind/pkg/cluster/internal/providers/podman"
 	internalprovider "sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
+	internalprune "sigs.k8s.io/kind/pkg/cluster/internal/prune"
+	internalreplace "sigs.k8s.io/kind/pkg/cluster/internal/replace"
+	"sigs.k8s.io/kind/pkg/cluster/internal/state"
+	internalverify "sigs.k8s.io/kind/pkg/cluster/internal/verify"
 )
 
 // DefaultName is the default cluster name
@@ -43,24 +66,30 @@ func defaultName(name string) string {
 	return name
 }
 
+// NamePrefixEnv is the environment variable setting the default
+// cluster name prefix, see ProviderWithNamePrefix
+const NamePrefixEnv = "KIND_CLUSTER_PREFIX"
+
 // Provider is used to perform cluster operations
 type Provider struct {
-	provider internalprovider.Provider
-	logger   log.Logger
+	provider   internalprovider.Provider
+	logger     log.Logger
+	namePrefix string
+	lockWait   time.Duration
 }
 
 // NewProvider returns a new provider based on the supplied options
 func NewProvider(options ...ProviderOption) *Provider {
 	p := &Provider{
-		logger: log.NoopLogger{},
+		logger:     log.NoopLogger{},
+		namePrefix: os.Getenv(NamePrefixEnv),
 	}
 	// Ensure we apply the logger options first, while maintaining the order
 	// otherwise. This way we can trivially init the internal provider with
 	// the logger.
+	// Event sink options are applied next, as they wrap the logger.
 	sort.SliceStable(options, func(i, j int) bool {
-		_, iIsLogger := options[i].(providerLoggerOption)
-		_, jIsLogger := options[j].(providerLoggerOption)
-		return iIsLogger && !jIsLogger
+		return optionPriority(options[i]) < optionPriority(options[j])
 	})
 	for _, o := range options {
 		if o != nil {
@@ -71,17 +100,51 @@ func NewProvider(options ...ProviderOption) *Provider {
 	if p.provider == nil {
 		// auto-detect based on each package IsAvailable() function
 		// default to docker for backwards compatibility
-		if docker.IsAvailable() {
-			p.provider = docker.NewProvider(p.logger)
-		} else if podman.IsAvailable() {
+		if name, err := DetectNodeProvider(); err == nil && name == "podman" {
 			p.provider = podman.NewProvider(p.logger)
 		} else {
-			p.provider = docker.NewProvider(p.logger)
+			p.provider = docker.NewProvider(p.logger, p.namePrefix)
 		}
 	}
 	return p
 }
 
+// nodeProviderDetectionOrder is the order in which node providers are
+// checked for availability when auto-detecting
+var nodeProviderDetectionOrder = []string{"docker", "podman"}
+
+// NodeProviderDetectionOrder returns the names of the node providers in the
+// order they are checked for availability when auto-detecting
+func NodeProviderDetectionOrder() []string {
+	return append([]string(nil), nodeProviderDetectionOrder...)
+}
+
+// DetectNodeProvider returns the name of the first available node provider
+// in NodeProviderDetectionOrder, or an error if none are available
+func DetectNodeProvider() (string, error) {
+	for _, name := range nodeProviderDetectionOrder {
+		if IsNodeProviderAvailable(name) {
+			return name, nil
+		}
+	}
+	return "", errors.WithReason(
+		errors.Errorf("no node provider available, tried: %s", strings.Join(nodeProviderDetectionOrder, ", ")),
+		errors.ErrProviderUnavailable,
+	)
+}
+
+// IsNodeProviderAvailable returns true if the named node provider's
+// runtime is installed and usable
+func IsNodeProviderAvailable(name string) bool {
+	switch name {
+	case "docker":
+		return docker.IsAvailable()
+	case "podman":
+		return podman.IsAvailable()
+	}
+	return false
+}
+
 // ProviderOption is an option for configuring a provider
 type ProviderOption interface {
 	apply(p *Provider)
@@ -97,6 +160,28 @@ func (a providerLoggerOption) apply(p *Provider) {
 
 var _ ProviderOption = providerLoggerOption(nil)
 
+// providerEventsOption is a trivial ProviderOption adapter
+// we use a type specific to event options so we can handle them
+// after logging options, but before the internal provider is created
+type providerEventsOption func(p *Provider)
+
+func (a providerEventsOption) apply(p *Provider) {
+	a(p)
+}
+
+var _ ProviderOption = providerEventsOption(nil)
+
+// optionPriority returns the order in which options should be applied
+func optionPriority(o ProviderOption) int {
+	switch o.(type) {
+	case providerLoggerOption:
+		return 0
+	case providerEventsOption, providerNameOption:
+		return 1
+	}
+	return 2
+}
+
 // ProviderWithLogger configures the provider to use Logger logger
 func ProviderWithLogger(logger log.Logger) ProviderOption {
 	return providerLoggerOption(func(p *Provider) {
@@ -104,6 +189,59 @@ func ProviderWithLogger(logger log.Logger) ProviderOption {
 	})
 }
 
+// ProviderWithEventSink configures the provider to send progress events for
+// create and delete to sink, so that callers may render their own progress.
+// Sends block until received, the caller must drain sink for the lifetime
+// of the provider. sink is never closed.
+func ProviderWithEventSink(sink chan<- Event) ProviderOption {
+	return providerEventsOption(func(p *Provider) {
+		p.logger = events.NewLogger(p.logger, sink)
+	})
+}
+
+// providerNameOption is a trivial ProviderOption adapter
+// we use a type specific to naming options so we can handle them
+// before the internal provider is created
+type providerNameOption func(p *Provider)
+
+func (a providerNameOption) apply(p *Provider) {
+	a(p)
+}
+
+var _ ProviderOption = providerNameOption(nil)
+
+// ProviderWithNamePrefix configures the provider to namespace all cluster
+// names with prefix, so that cluster "foo" has nodes, kubeconfig context
+// and state named for "<prefix>-foo" and the docker network is
+// "<prefix>-kind". List only returns the clusters with this prefix.
+//
+// This allows sharing a host between CI jobs or users without collisions,
+// it defaults to the value of KIND_CLUSTER_PREFIX, empty disables it.
+func ProviderWithNamePrefix(prefix string) ProviderOption {
+	return providerNameOption(func(p *Provider) {
+		p.namePrefix = prefix
+	})
+}
+
+// providerLockOption is a trivial ProviderOption adapter
+type providerLockOption func(p *Provider)
+
+func (a providerLockOption) apply(p *Provider) {
+	a(p)
+}
+
+var _ ProviderOption = providerLockOption(nil)
+
+// ProviderWithLockWait configures how long create, delete and kubeconfig
+// export wait for another kind process operating on the same cluster to
+// finish, before failing with errors.ErrClusterBusy. The default is zero,
+// failing immediately.
+func ProviderWithLockWait(wait time.Duration) ProviderOption {
+	return providerLockOption(func(p *Provider) {
+		p.lockWait = wait
+	})
+}
+
 // providerLoggerOption is a trivial ProviderOption adapter
 // we use a type specific to logging options so we can handle them first
 type providerRuntimeOption func(p *Provider)
@@ -117,7 +255,19 @@ var _ ProviderOption = providerRuntimeOption(nil)
 // ProviderWithDocker configures the provider to use docker runtime
 func ProviderWithDocker() ProviderOption {
 	return providerRuntimeOption(func(p *Provider) {
-		p.provider = docker.NewProvider(p.logger)
+		p.provider = docker.NewProvider(p.logger, p.namePrefix)
+	})
+}
+
+// ProviderWithDockerVM configures the provider to use docker with each node
+// running as a lightweight VM under the docker runtime ociRuntime, which
+// defaults to kata-runtime if empty.
+//
+// This is experimental and allows testing kernel dependent features
+// (kernel versions, modules, swap, cgroups) that shared kernel nodes cannot.
+func ProviderWithDockerVM(ociRuntime string) ProviderOption {
+	return providerRuntimeOption(func(p *Provider) {
+		p.provider = docker.NewVMProvider(p.logger, p.namePrefix, ociRuntime)
 	})
 }
 
@@ -128,36 +278,445 @@ func ProviderWithPodman() ProviderOption {
 	})
 }
 
+// ClusterName returns the full name of the cluster name, which is
+// defaulted and then prefixed with the provider's name prefix, if any.
+// This is the name used for the cluster's nodes and kubeconfig context.
+func (p *Provider) ClusterName(name string) string {
+	name = defaultName(name)
+	if p.namePrefix == "" {
+		return name
+	}
+	return p.namePrefix + "-" + name
+}
+
 // Create provisions and starts a kubernetes-in-docker cluster
 // TODO: move name to an option to override config
 func (p *Provider) Create(name string, options ...CreateOption) error {
+	return p.CreateContext(context.Background(), name, options...)
+}
+
+// CreateContext is like Create but ctx bounds the work done, if ctx is
+// cancelled creation stops and the partially created cluster is deleted
+// unless CreateWithRetain is set
+func (p *Provider) CreateContext(ctx context.Context, name string, options ...CreateOption) error {
 	// apply options
 	opts := &internalcreate.ClusterOptions{
 		NameOverride: name,
+		NamePrefix:   p.namePrefix,
+		LockWait:     p.lockWait,
+	}
+	for _, o := range options {
+		if err := o.apply(opts); err != nil {
+			return err
+		}
+	}
+	return internalcreate.Cluster(ctx, p.logger, p.provider, opts)
+}
+
+// Clone creates the cluster name as a copy of the existing cluster from,
+// starting the new nodes from images committed from the existing nodes.
+// Config options are ignored, the config from was created with is used.
+func (p *Provider) Clone(from, name string, options ...CreateOption) error {
+	return p.CloneContext(context.Background(), from, name, options...)
+}
+
+// CloneContext is like Clone but ctx bounds the work done
+func (p *Provider) CloneContext(ctx context.Context, from, name string, options ...CreateOption) error {
+	if name == "" {
+		name = defaultName(from) + "-clone"
+	}
+	opts := &internalcreate.ClusterOptions{
+		NameOverride: p.ClusterName(name),
+		LockWait:     p.lockWait,
 	}
 	for _, o := range options {
 		if err := o.apply(opts); err != nil {
 			return err
 		}
 	}
-	return internalcreate.Cluster(p.logger, p.provider, opts)
+	return internalclone.Cluster(ctx, p.logger, p.provider, p.ClusterName(from), opts)
+}
+
+// RequiredImage is an image that creating a cluster would use
+type RequiredImage struct {
+	// Image is the image reference, e.g. "k8s.gcr.io/pause:3.2"
+	Image string `json:"image"`
+	// Source is what uses the image, e.g. "node", "kubeadm", "pause", "cni"
+	// "storage", "load-balancer", "preset" or "gpu"
+	Source string `json:"source"`
+}
+
+// RequiredImages returns every image that Create with the same options
+// would use, so they can be mirrored for creating clusters offline.
+// Nothing is created, but the node image is pulled if needed.
+func (p *Provider) RequiredImages(options ...CreateOption) ([]RequiredImage, error) {
+	return p.RequiredImagesContext(context.Background(), options...)
+}
+
+// RequiredImagesContext is like RequiredImages but ctx bounds the work done
+func (p *Provider) RequiredImagesContext(ctx context.Context, options ...CreateOption) ([]RequiredImage, error) {
+	opts := &internalcreate.ClusterOptions{}
+	for _, o := range options {
+		if err := o.apply(opts); err != nil {
+			return nil, err
+		}
+	}
+	images, err := internalcreate.RequiredImages(ctx, p.provider, opts)
+	if err != nil {
+		return nil, err
+	}
+	required := make([]RequiredImage, 0, len(images))
+	for _, image := range images {
+		required = append(required, RequiredImage{Image: image.Image, Source: image.Source})
+	}
+	return required, nil
+}
+
+// RenderedKubeadmConfig is the kubeadm config generated for a node
+type RenderedKubeadmConfig struct {
+	// Node is the node name
+	Node string
+	// Config is the kubeadm config, with all patches applied
+	Config string
+}
+
+// RenderKubeadmConfig returns the kubeadm config that Create with the same
+// name and options would generate for each node, without creating anything.
+// Node addresses are not known until the nodes exist, they are rendered as
+// "NODE_ADDRESS". If kubernetesVersion is empty it is read from the node
+// images, pulling them if needed.
+func (p *Provider) RenderKubeadmConfig(name, kubernetesVersion string, options ...CreateOption) ([]RenderedKubeadmConfig, error) {
+	return p.RenderKubeadmConfigContext(context.Background(), name, kubernetesVersion, options...)
+}
+
+// RenderKubeadmConfigContext is like RenderKubeadmConfig but ctx bounds the work done
+func (p *Provider) RenderKubeadmConfigContext(ctx context.Context, name, kubernetesVersion string, options ...CreateOption) ([]RenderedKubeadmConfig, error) {
+	opts := &internalcreate.ClusterOptions{
+		NameOverride: name,
+		NamePrefix:   p.namePrefix,
+	}
+	for _, o := range options {
+		if err := o.apply(opts); err != nil {
+			return nil, err
+		}
+	}
+	configs, err := internalcreate.RenderKubeadmConfigs(ctx, p.provider, opts, kubernetesVersion)
+	if err != nil {
+		return nil, err
+	}
+	rendered := make([]RenderedKubeadmConfig, 0, len(configs))
+	for _, c := range configs {
+		rendered = append(rendered, RenderedKubeadmConfig{Node: c.Node, Config: c.Config})
+	}
+	return rendered, nil
 }
 
 // Delete tears down a kubernetes-in-docker cluster
-func (p *Provider) Delete(name, explicitKubeconfigPath string) error {
-	return internaldelete.Cluster(p.logger, p.provider, defaultName(name), explicitKubeconfigPath)
+func (p *Provider) Delete(name, explicitKubeconfigPath string, options ...DeleteOption) error {
+	return p.DeleteContext(context.Background(), name, explicitKubeconfigPath, options...)
+}
+
+// DeleteContext is like Delete but ctx bounds the work done
+func (p *Provider) DeleteContext(ctx context.Context, name, explicitKubeconfigPath string, options ...DeleteOption) error {
+	opts := &deleteOptions{}
+	for _, o := range options {
+		if err := o.apply(opts); err != nil {
+			return err
+		}
+	}
+	name = p.ClusterName(name)
+	unlock, err := state.Default().Lock(ctx, name, p.lockWait)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if opts.graceful && !opts.force {
+		if err := internaldelete.Shutdown(ctx, p.logger, p.provider, name); err != nil {
+			return err
+		}
+	}
+	return internaldelete.Cluster(ctx, p.logger, p.provider, name, explicitKubeconfigPath)
 }
 
 // List returns a list of clusters for which nodes exist
+// If the provider has a name prefix, only clusters with the prefix are
+// listed, with the prefix removed
 func (p *Provider) List() ([]string, error) {
-	return p.provider.ListClusters()
+	return p.ListContext(context.Background())
+}
+
+// ListContext is like List but ctx bounds the work done
+func (p *Provider) ListContext(ctx context.Context) ([]string, error) {
+	clusters, err := p.provider.ListClusters(ctx)
+	if err != nil || p.namePrefix == "" {
+		return clusters, err
+	}
+	prefixed := []string{}
+	for _, c := range clusters {
+		if strings.HasPrefix(c, p.namePrefix+"-") {
+			prefixed = append(prefixed, strings.TrimPrefix(c, p.namePrefix+"-"))
+		}
+	}
+	return prefixed, nil
+}
+
+// ClusterInfo is the metadata kind recorded locally for a cluster
+type ClusterInfo struct {
+	// Name is the cluster name
+	Name string
+	// Provider is the node provider the cluster was created with, e.g. "docker"
+	Provider string
+	// CreationTimestamp is when the cluster was created, if Recorded
+	CreationTimestamp time.Time
+	// Config is the raw cluster config the cluster was created from, if any
+	Config string
+	// NodeImage is the node image override the cluster was created with, if any
+	NodeImage string
+	// Labels are from CreateWithLabels
+	Labels map[string]string
+	// ExpirationTimestamp is when the cluster expires, from CreateWithTTL,
+	// or zero if it does not
+	ExpirationTimestamp time.Time
+	// Recorded is false if the cluster exists but has no local state,
+	// e.g. if it was created by an older version of kind
+	Recorded bool
+}
+
+// ClusterInfo returns the locally recorded metadata for the cluster
+func (p *Provider) ClusterInfo(name string) (*ClusterInfo, error) {
+	return p.ClusterInfoContext(context.Background(), name)
+}
+
+// ClusterInfoContext is like ClusterInfo but ctx bounds the work done
+func (p *Provider) ClusterInfoContext(ctx context.Context, name string) (*ClusterInfo, error) {
+	name = p.ClusterName(name)
+	n, err := p.provider.ListNodes(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(n) == 0 {
+		return nil, errors.ClusterNotFound(name)
+	}
+	recorded, err := state.Default().Read(name)
+	if err != nil {
+		return nil, err
+	}
+	if recorded == nil {
+		return &ClusterInfo{Name: name, Provider: p.provider.String()}, nil
+	}
+	info := &ClusterInfo{
+		Name:              recorded.Name,
+		Provider:          recorded.Provider,
+		CreationTimestamp: recorded.CreationTimestamp,
+		Config:            recorded.Config,
+		NodeImage:         recorded.NodeImage,
+		Labels:            recorded.Labels,
+		Recorded:          true,
+	}
+	if recorded.ExpirationTimestamp != nil {
+		info.ExpirationTimestamp = *recorded.ExpirationTimestamp
+	}
+	return info, nil
+}
+
+// Endpoint is a host endpoint exposed by a cluster
+type Endpoint struct {
+	// Node is the node container the endpoint maps to, empty for the API server
+	Node string
+	// Address is the host address, e.g. "127.0.0.1:43567"
+	Address string
+	// ContainerPort is the port in the node, zero for the API server
+	ContainerPort int32
+	// Protocol is the port mapping protocol, e.g. "TCP"
+	Protocol string
+}
+
+// Endpoints returns the API server endpoint followed by the recorded node
+// port mappings, including the host ports kind picked for random ports
+func (p *Provider) Endpoints(name string) ([]Endpoint, error) {
+	return p.EndpointsContext(context.Background(), name)
+}
+
+// EndpointsContext is like Endpoints but ctx bounds the work done
+func (p *Provider) EndpointsContext(ctx context.Context, name string) ([]Endpoint, error) {
+	name = p.ClusterName(name)
+	n, err := p.provider.ListNodes(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(n) == 0 {
+		return nil, errors.ClusterNotFound(name)
+	}
+	apiServer, err := p.provider.GetAPIServerEndpoint(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	endpoints := []Endpoint{{Address: apiServer, Protocol: "TCP"}}
+	ports, err := state.Default().ReadPorts(name)
+	if err != nil || ports == nil {
+		return endpoints, err
+	}
+	// the record may be from a previous cluster, skip nodes that don't exist
+	exists := make(map[string]bool, len(n))
+	for _, node := range n {
+		exists[node.String()] = true
+	}
+	for _, m := range ports.Mappings {
+		if !exists[m.Node] {
+			continue
+		}
+		endpoints = append(endpoints, Endpoint{
+			Node:          m.Node,
+			Address:       net.JoinHostPort(m.ListenAddress, strconv.Itoa(int(m.HostPort))),
+			ContainerPort: m.ContainerPort,
+			Protocol:      m.Protocol,
+		})
+	}
+	return endpoints, nil
+}
+
+// NetworkInfo describes the container network of a cluster, with the
+// addresses of the cluster's containers on it
+type NetworkInfo = internalprovider.NetworkInfo
+
+// NetworkSubnet is a subnet of a cluster's container network
+type NetworkSubnet = internalprovider.NetworkSubnet
+
+// NodeAddress is the address of a cluster container on its network
+type NodeAddress = internalprovider.NodeAddress
+
+// NetworkInfo returns the container network the cluster's nodes are attached
+// to: its name and IPv4 / IPv6 subnets and gateways, the nodes' addresses and
+// the MTU of their network interface, e.g. for configuring MetalLB address
+// pools or routes to the nodes
+func (p *Provider) NetworkInfo(name string) (*NetworkInfo, error) {
+	return p.NetworkInfoContext(context.Background(), name)
+}
+
+// NetworkInfoContext is like NetworkInfo but ctx bounds the work done
+func (p *Provider) NetworkInfoContext(ctx context.Context, name string) (*NetworkInfo, error) {
+	return internalnetwork.Info(ctx, p.provider, p.ClusterName(name))
+}
+
+// ComposeFormat is an output format for Provider.Compose
+type ComposeFormat string
+
+const (
+	// ComposeFormatCompose is a docker compose file
+	ComposeFormatCompose ComposeFormat = compose.Compose
+	// ComposeFormatKube is Kubernetes Pod YAML, as consumed by `podman kube play`
+	ComposeFormatKube ComposeFormat = compose.Kube
+)
+
+// Compose renders the cluster's node containers, including their mounts,
+// networks and port mappings, from the node provider's inspect data
+func (p *Provider) Compose(name string, format ComposeFormat) (string, error) {
+	return p.ComposeContext(context.Background(), name, format)
+}
+
+// ComposeContext is like Compose but ctx bounds the work done
+func (p *Provider) ComposeContext(ctx context.Context, name string, format ComposeFormat) (string, error) {
+	name = p.ClusterName(name)
+	n, err := p.provider.ListNodes(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	if len(n) == 0 {
+		return "", errors.ClusterNotFound(name)
+	}
+	inspect, err := p.provider.InspectNodes(ctx, n)
+	if err != nil {
+		return "", err
+	}
+	return compose.Render(inspect, name, string(format))
+}
+
+// InspectFormat is an output format for Provider.Inspect
+type InspectFormat string
+
+const (
+	// InspectFormatYAML is YAML output
+	InspectFormatYAML InspectFormat = inspect.YAML
+	// InspectFormatJSON is JSON output
+	InspectFormatJSON InspectFormat = inspect.JSON
+)
+
+// Inspect renders everything kind resolved for the cluster: the fully
+// defaulted config, the kubeadm, containerd and load balancer configs
+// generated on each node, and the node provider's container specs
+func (p *Provider) Inspect(name string, format InspectFormat) (string, error) {
+	return p.InspectContext(context.Background(), name, format)
+}
+
+// InspectContext is like Inspect but ctx bounds the work done
+func (p *Provider) InspectContext(ctx context.Context, name string, format InspectFormat) (string, error) {
+	report, err := inspect.Cluster(ctx, p.provider, p.ClusterName(name))
+	if err != nil {
+		return "", err
+	}
+	return inspect.Render(report, string(format))
 }
 
 // KubeConfig returns the KUBECONFIG for the cluster
 // If internal is true, this will contain the internal IP etc.
 // If internal is false, this will contain the host IP etc.
 func (p *Provider) KubeConfig(name string, internal bool) (string, error) {
-	return kubeconfig.Get(p.provider, defaultName(name), !internal)
+	return p.KubeConfigContext(context.Background(), name, internal)
+}
+
+// KubeConfigContext is like KubeConfig but ctx bounds the work done
+func (p *Provider) KubeConfigContext(ctx context.Context, name string, internal bool) (string, error) {
+	return kubeconfig.Get(ctx, p.provider, p.ClusterName(name), !internal)
+}
+
+// KubeConfigInternalLoadBalancer returns the internal KUBECONFIG for the
+// cluster with the server set to the control plane load balancer, so that
+// clients on the cluster network survive individual control plane restarts.
+// It fails for clusters without a load balancer, i.e. one control plane node.
+func (p *Provider) KubeConfigInternalLoadBalancer(name string) (string, error) {
+	return p.KubeConfigInternalLoadBalancerContext(context.Background(), name)
+}
+
+// KubeConfigInternalLoadBalancerContext is like KubeConfigInternalLoadBalancer
+// but ctx bounds the work done
+func (p *Provider) KubeConfigInternalLoadBalancerContext(ctx context.Context, name string) (string, error) {
+	return kubeconfig.GetInternalLoadBalancer(ctx, p.provider, p.ClusterName(name))
+}
+
+// KubeConfigObject holds the details from a cluster's KUBECONFIG needed to
+// construct a client directly, e.g. a client-go rest.Config
+type KubeConfigObject struct {
+	// Context is the kubeconfig context name for the cluster
+	Context string
+	// Server is the API server address (https://hostname:port)
+	Server string
+	// CertificateAuthorityData is the PEM encoded CA bundle for the server
+	CertificateAuthorityData []byte
+	// ClientCertificateData is the PEM encoded client certificate
+	ClientCertificateData []byte
+	// ClientKeyData is the PEM encoded client key
+	ClientKeyData []byte
+}
+
+// KubeConfigObject is like KubeConfig but returns the parsed connection
+// details rather than the serialized KUBECONFIG
+func (p *Provider) KubeConfigObject(name string, internal bool) (*KubeConfigObject, error) {
+	return p.KubeConfigObjectContext(context.Background(), name, internal)
+}
+
+// KubeConfigObjectContext is like KubeConfigObject but ctx bounds the work done
+func (p *Provider) KubeConfigObjectContext(ctx context.Context, name string, internal bool) (*KubeConfigObject, error) {
+	c, err := kubeconfig.GetCredentials(ctx, p.provider, p.ClusterName(name), !internal)
+	if err != nil {
+		return nil, err
+	}
+	return &KubeConfigObject{
+		Context:                  c.Context,
+		Server:                   c.Server,
+		CertificateAuthorityData: c.CertificateAuthorityData,
+		ClientCertificateData:    c.ClientCertificateData,
+		ClientKeyData:            c.ClientKeyData,
+	}, nil
 }
 
 // ExportKubeConfig exports the KUBECONFIG for the cluster, merging
@@ -165,31 +724,440 @@ func (p *Provider) KubeConfig(name string, internal bool) (string, error) {
 // https://kubernetes.io/docs/reference/generated/kubectl/kubectl-commands#config
 // where explicitPath is the --kubeconfig value.
 func (p *Provider) ExportKubeConfig(name string, explicitPath string) error {
-	return kubeconfig.Export(p.provider, defaultName(name), explicitPath)
+	return p.ExportKubeConfigContext(context.Background(), name, explicitPath)
+}
+
+// ExportKubeConfigContext is like ExportKubeConfig but ctx bounds the work done
+func (p *Provider) ExportKubeConfigContext(ctx context.Context, name string, explicitPath string) error {
+	name = p.ClusterName(name)
+	unlock, err := state.Default().Lock(ctx, name, p.lockWait)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return kubeconfig.Export(ctx, p.provider, name, explicitPath)
 }
 
 // ListNodes returns the list of container IDs for the "nodes" in the cluster
 func (p *Provider) ListNodes(name string) ([]nodes.Node, error) {
-	return p.provider.ListNodes(defaultName(name))
+	return p.ListNodesContext(context.Background(), name)
+}
+
+// ListNodesContext is like ListNodes but ctx bounds the work done,
+// commands run on the returned nodes are also bound to ctx
+func (p *Provider) ListNodesContext(ctx context.Context, name string) ([]nodes.Node, error) {
+	return p.provider.ListNodes(ctx, p.ClusterName(name))
 }
 
 // ListInternalNodes returns the list of container IDs for the "nodes" in the cluster
 // that are not external
 func (p *Provider) ListInternalNodes(name string) ([]nodes.Node, error) {
-	n, err := p.provider.ListNodes(name)
+	return p.ListInternalNodesContext(context.Background(), name)
+}
+
+// ListInternalNodesContext is like ListInternalNodes but ctx bounds the
+// work done, commands run on the returned nodes are also bound to ctx
+func (p *Provider) ListInternalNodesContext(ctx context.Context, name string) ([]nodes.Node, error) {
+	n, err := p.provider.ListNodes(ctx, p.ClusterName(name))
 	if err != nil {
 		return nil, err
 	}
 	return nodeutils.InternalNodes(n)
 }
 
+// RebootNodes restarts the given nodes, as if the host had rebooted, and
+// waits for each node's init system to finish booting
+func (p *Provider) RebootNodes(n ...nodes.Node) error {
+	return p.RebootNodesContext(context.Background(), n...)
+}
+
+// RebootNodesContext is like RebootNodes but ctx bounds the work done
+func (p *Provider) RebootNodesContext(ctx context.Context, n ...nodes.Node) error {
+	if err := p.provider.RestartNodes(ctx, n); err != nil {
+		return err
+	}
+	return nodeutils.WaitForBoot(ctx, n...)
+}
+
+// Heal restarts the node containers of the cluster that have exited, such as
+// after the container runtime restarts with a restartPolicy that does not
+// restart them, then reconfigures the load balancer for any new node addresses.
+// It returns the restarted nodes, which is empty when all nodes are running.
+func (p *Provider) Heal(name string) ([]nodes.Node, error) {
+	return p.HealContext(context.Background(), name)
+}
+
+// HealContext is like Heal but ctx bounds the work done
+func (p *Provider) HealContext(ctx context.Context, name string) ([]nodes.Node, error) {
+	name = p.ClusterName(name)
+	unlock, err := state.Default().Lock(ctx, name, p.lockWait)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return internalheal.Cluster(ctx, p.logger, p.provider, name)
+}
+
+// VerifyOptions configures the checks run by Verify
+type VerifyOptions struct {
+	// Image is the image of the test pod, which needs bash and getent.
+	// Defaults to an image preloaded in the node image
+	Image string
+	// PullImages are pulled on a node to check the configured registries and
+	// registry mirrors, each registry is checked with the first of these or
+	// else Image hosted by it
+	PullImages []string
+	// Timeout bounds each check, defaults to a minute
+	Timeout time.Duration
+}
+
+// VerifyResult is the outcome of one Verify check
+type VerifyResult = internalverify.Result
+
+// Verify runs a quick smoke test of the cluster: the nodes are ready, a pod
+// is scheduled and resolves cluster DNS and reaches a ClusterIP service, and
+// the configured registries and registry mirrors respond and serve images
+// pulled through them. It returns the
+// result of each check, the error is only for failing to run the checks
+func (p *Provider) Verify(name string, opts VerifyOptions) ([]VerifyResult, error) {
+	return p.VerifyContext(context.Background(), name, opts)
+}
+
+// VerifyContext is like Verify but ctx bounds the work done
+func (p *Provider) VerifyContext(ctx context.Context, name string, opts VerifyOptions) ([]VerifyResult, error) {
+	cfg, err := state.Default().ReadConfig(p.ClusterName(name))
+	if err != nil {
+		return nil, err
+	}
+	return internalverify.Cluster(ctx, p.provider, cfg, internalverify.Options{
+		Image:      opts.Image,
+		PullImages: opts.PullImages,
+		Timeout:    opts.Timeout,
+	})
+}
+
+// ReplaceNodeOptions configures the new node created by ReplaceNode
+type ReplaceNodeOptions struct {
+	// Image is the node image of the new node, by default the image the old
+	// node was created with
+	Image string
+	// Timeout bounds waiting for the new node to be Ready and draining the
+	// old node, zero waits without a limit
+	Timeout time.Duration
+}
+
+// ReplaceNode replaces the node named node of the cluster with a new node
+// container created from the same config, with opts applied. The new node
+// joins the cluster and must become Ready before the old node is drained,
+// removed from the cluster and deleted, so that the cluster stays available.
+// Control plane nodes can only be replaced with more than one control plane.
+// It returns the name of the new node.
+func (p *Provider) ReplaceNode(name, node string, opts ReplaceNodeOptions) (string, error) {
+	return p.ReplaceNodeContext(context.Background(), name, node, opts)
+}
+
+// ReplaceNodeContext is like ReplaceNode but ctx bounds the work done
+func (p *Provider) ReplaceNodeContext(ctx context.Context, name, node string, opts ReplaceNodeOptions) (string, error) {
+	name = p.ClusterName(name)
+	unlock, err := state.Default().Lock(ctx, name, p.lockWait)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+	return internalreplace.Node(ctx, p.logger, p.provider, name, node, internalreplace.Options{
+		Image:   opts.Image,
+		Timeout: opts.Timeout,
+	})
+}
+
+// ExposeOptions configures how Expose republishes a cluster
+type ExposeOptions struct {
+	// Address is the host address to listen on, e.g. 0.0.0.0 for all interfaces
+	Address string
+	// Port is the host port for the API server, zero picks a free port
+	Port int32
+	// NodePorts are also published on Address, on the same host port
+	NodePorts []int32
+	// SANs are added to the API server certificate, the first is used as the
+	// kubeconfig server. When empty they default to Address and the host name,
+	// or the host's addresses and name if Address is unspecified
+	SANs []string
+}
+
+// Expose republishes the API server of the cluster, and any opts.NodePorts,
+// on opts.Address through a proxy container, for accessing a cluster on a
+// remote host. The exposed names are added to the API server certificate.
+// It returns a kubeconfig for the exposed endpoint.
+func (p *Provider) Expose(name string, opts ExposeOptions) (string, error) {
+	return p.ExposeContext(context.Background(), name, opts)
+}
+
+// ExposeContext is like Expose but ctx bounds the work done
+func (p *Provider) ExposeContext(ctx context.Context, name string, opts ExposeOptions) (string, error) {
+	name = p.ClusterName(name)
+	unlock, err := state.Default().Lock(ctx, name, p.lockWait)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+	endpoint, err := internalexpose.Cluster(ctx, p.logger, p.provider, name, internalexpose.Options{
+		Address:   opts.Address,
+		Port:      opts.Port,
+		NodePorts: opts.NodePorts,
+		SANs:      opts.SANs,
+	})
+	if err != nil {
+		return "", err
+	}
+	return kubeconfig.GetForServer(ctx, p.provider, name, "https://"+endpoint)
+}
+
+// Unexpose removes the proxy created by Expose, if any
+func (p *Provider) Unexpose(name string) error {
+	return p.UnexposeContext(context.Background(), name)
+}
+
+// UnexposeContext is like Unexpose but ctx bounds the work done
+func (p *Provider) UnexposeContext(ctx context.Context, name string) error {
+	name = p.ClusterName(name)
+	unlock, err := state.Default().Lock(ctx, name, p.lockWait)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return internalexpose.Delete(ctx, p.provider, name)
+}
+
+// JoinCommandOptions configures the command generated by JoinCommand
+type JoinCommandOptions struct {
+	// ControlPlane joins as an additional control plane node
+	ControlPlane bool
+	// TTL is the lifetime of the bootstrap token, zero for kubeadm's
+	// default of 24h
+	TTL time.Duration
+	// Endpoint overrides the API server host:port to join, by default it is
+	// the endpoint the nodes use, only reachable on the cluster's network
+	Endpoint string
+}
+
+// JoinCommand creates a fresh bootstrap token, and a certificate key for
+// opts.ControlPlane, and returns the kubeadm join command for adding a
+// machine or container not created by kind to the cluster
+func (p *Provider) JoinCommand(name string, opts JoinCommandOptions) (string, error) {
+	return p.JoinCommandContext(context.Background(), name, opts)
+}
+
+// JoinCommandContext is like JoinCommand but ctx bounds the work done
+func (p *Provider) JoinCommandContext(ctx context.Context, name string, opts JoinCommandOptions) (string, error) {
+	return internaljoin.Command(ctx, p.provider, p.ClusterName(name), internaljoin.Options{
+		ControlPlane: opts.ControlPlane,
+		TTL:          opts.TTL,
+		Endpoint:     opts.Endpoint,
+	})
+}
+
+// DiskUsage is the disk space used by a cluster
+type DiskUsage struct {
+	// Nodes is the space used by each node's storage, for containerd,
+	// the kubelet and logs, in bytes by node name
+	Nodes []NodeDiskUsage
+	// Quota is the usable size of the cluster disk in bytes, zero if the
+	// cluster was created without a diskQuota
+	Quota int64
+	// Used is the space used on the cluster disk in bytes, including
+	// filesystem overhead
+	Used int64
+}
+
+// NodeDiskUsage is the disk space used by a node's storage
+type NodeDiskUsage struct {
+	Name  string
+	Bytes int64
+}
+
+// DiskUsage returns the disk space used by the cluster's nodes, and the
+// usage of its diskQuota if any. The nodes must be running
+func (p *Provider) DiskUsage(name string) (*DiskUsage, error) {
+	return p.DiskUsageContext(context.Background(), name)
+}
+
+// DiskUsageContext is like DiskUsage but ctx bounds the work done
+func (p *Provider) DiskUsageContext(ctx context.Context, name string) (*DiskUsage, error) {
+	usage, err := internaldisk.ClusterUsage(ctx, p.provider, p.ClusterName(name))
+	if err != nil {
+		return nil, err
+	}
+	out := &DiskUsage{Quota: usage.Size, Used: usage.Used}
+	for _, node := range usage.Nodes {
+		out.Nodes = append(out.Nodes, NodeDiskUsage{Name: node.Name, Bytes: node.Bytes})
+	}
+	return out, nil
+}
+
+// Artifact is a file shipped in node images that GetArtifact can read,
+// such as the Kubernetes binaries
+type Artifact = internalartifacts.Artifact
+
+// Artifacts returns the artifacts GetArtifact can read
+func Artifacts() []Artifact {
+	return append([]Artifact(nil), internalartifacts.All...)
+}
+
+// LookupArtifact returns the artifact of Artifacts named name
+func LookupArtifact(name string) (Artifact, error) {
+	return internalartifacts.Lookup(name)
+}
+
+// ArtifactSource is where GetArtifact reads an artifact from, a node image
+// or a node of the cluster
+type ArtifactSource = internalartifacts.Source
+
+// GetArtifact writes the artifact named artifact, one of Artifacts, from
+// src to w. Unless src.Image is set it is read from a node of the cluster
+func (p *Provider) GetArtifact(name string, src ArtifactSource, artifact string, w io.Writer) error {
+	return p.GetArtifactContext(context.Background(), name, src, artifact, w)
+}
+
+// GetArtifactContext is like GetArtifact but ctx bounds the work done
+func (p *Provider) GetArtifactContext(ctx context.Context, name string, src ArtifactSource, artifact string, w io.Writer) error {
+	a, err := internalartifacts.Lookup(artifact)
+	if err != nil {
+		return err
+	}
+	return internalartifacts.Copy(ctx, p.provider, p.ClusterName(name), src, a, w)
+}
+
+// PruneImagesOptions configures PruneImages
+type PruneImagesOptions struct {
+	// DryRun only returns the unused images, without removing them
+	DryRun bool
+}
+
+// PrunedImages are the unused images removed from a node by PruneImages
+type PrunedImages struct {
+	Node   string
+	Images []PrunedImage
+}
+
+// PrunedImage is an image removed from a node by PruneImages
+type PrunedImage struct {
+	// Name is the first tag of the image, or its digest or ID if untagged
+	Name string
+	ID   string
+	// Size is the size of the image in bytes
+	Size int64
+}
+
+// PruneImages removes the images no container uses from the containerd
+// store of each of the cluster's Kubernetes nodes, keeping the sandbox image
+// and pinned images. Images loaded with `kind load` that no pod has used
+// yet are removed too. The nodes must be running
+func (p *Provider) PruneImages(name string, opts PruneImagesOptions) ([]PrunedImages, error) {
+	return p.PruneImagesContext(context.Background(), name, opts)
+}
+
+// PruneImagesContext is like PruneImages but ctx bounds the work done
+func (p *Provider) PruneImagesContext(ctx context.Context, name string, opts PruneImagesOptions) ([]PrunedImages, error) {
+	pruned, err := internalprune.Images(ctx, p.provider, p.ClusterName(name), internalprune.Options{
+		DryRun: opts.DryRun,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := []PrunedImages{}
+	for _, node := range pruned {
+		images := []PrunedImage{}
+		for _, image := range node.Images {
+			images = append(images, PrunedImage{Name: image.Name, ID: image.ID, Size: image.Size})
+		}
+		out = append(out, PrunedImages{Node: node.Node, Images: images})
+	}
+	return out, nil
+}
+
+// NodeEvent is a container lifecycle event of a node, such as "oom" or "die"
+type NodeEvent = internalprovider.NodeEvent
+
+// NodeEventsOptions selects the events returned by NodeEvents
+type NodeEventsOptions = internalprovider.NodeEventsOptions
+
+// NodeEvents calls fn with the container lifecycle events of the cluster's
+// nodes in order, such as nodes being OOM killed. With opts.Follow it
+// streams new events until ctx is done, see NodeEventsContext
+func (p *Provider) NodeEvents(name string, opts NodeEventsOptions, fn func(NodeEvent)) error {
+	return p.NodeEventsContext(context.Background(), name, opts, fn)
+}
+
+// NodeEventsContext is like NodeEvents but ctx bounds the work done
+func (p *Provider) NodeEventsContext(ctx context.Context, name string, opts NodeEventsOptions, fn func(NodeEvent)) error {
+	return p.provider.NodeEvents(ctx, p.ClusterName(name), opts, fn)
+}
+
 // CollectLogs will populate dir with cluster logs and other debug files
 func (p *Provider) CollectLogs(name, dir string) error {
+	return p.CollectLogsContext(context.Background(), name, dir)
+}
+
+// CollectLogsContext is like CollectLogs but ctx bounds the work done
+func (p *Provider) CollectLogsContext(ctx context.Context, name, dir string) error {
+	return p.CollectLogsWithOptions(ctx, name, dir, CollectLogsOptions{})
+}
+
+// CollectLogsOptions limits which logs CollectLogsWithOptions collects,
+// the zero value collects everything
+type CollectLogsOptions struct {
+	// Nodes limits collection to the nodes with these names, if non-empty
+	Nodes []string
+	// Since limits journal logs to entries newer than this, if non-zero
+	Since time.Duration
+	// Components limits collection to these log components, if non-empty
+	// See LogComponents for the valid values
+	Components []string
+}
+
+// LogComponents lists the valid values for CollectLogsOptions.Components
+var LogComponents = internallogs.Components
+
+// CollectLogsWithOptions is like CollectLogsContext but collects only
+// the logs selected by opts
+func (p *Provider) CollectLogsWithOptions(ctx context.Context, name, dir string, opts CollectLogsOptions) error {
+	logOpts := &internallogs.Options{
+		Since:      opts.Since,
+		Components: opts.Components,
+	}
+	if err := logOpts.Validate(); err != nil {
+		return err
+	}
 	// TODO: should use ListNodes and Collect should handle nodes differently
 	// based on role ...
-	n, err := p.ListInternalNodes(name)
+	n, err := p.ListInternalNodesContext(ctx, name)
 	if err != nil {
 		return err
 	}
-	return p.provider.CollectLogs(dir, n)
+	if len(opts.Nodes) > 0 {
+		selected, err := selectNodesByName(n, opts.Nodes)
+		if err != nil {
+			return err
+		}
+		n = selected
+	}
+	return p.provider.CollectLogs(ctx, dir, n, logOpts)
+}
+
+// selectNodesByName returns the nodes in all with the given names,
+// it is an error if any name does not match a node
+func selectNodesByName(all []nodes.Node, names []string) ([]nodes.Node, error) {
+	byName := make(map[string]nodes.Node, len(all))
+	for _, n := range all {
+		byName[n.String()] = n
+	}
+	selected := make([]nodes.Node, 0, len(names))
+	for _, name := range names {
+		n, ok := byName[name]
+		if !ok {
+			return nil, errors.Errorf("unknown node %q", name)
+		}
+		selected = append(selected, n)
+	}
+	return selected, nil
 }
@@ -0,0 +1,111 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+
+	internalprovider "sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
+)
+
+type fakeProvider struct {
+	internalprovider.Provider
+	clusters []string
+}
+
+func (f *fakeProvider) ListClusters(ctx context.Context) ([]string, error) {
+	return f.clusters, nil
+}
+
+func TestClusterName(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name     string
+		Prefix   string
+		Cluster  string
+		Expected string
+	}{
+		{Name: "default", Expected: DefaultName},
+		{Name: "named", Cluster: "foo", Expected: "foo"},
+		{Name: "prefixed default", Prefix: "ci-42", Expected: "ci-42-" + DefaultName},
+		{Name: "prefixed", Prefix: "ci-42", Cluster: "foo", Expected: "ci-42-foo"},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			p := &Provider{namePrefix: tc.Prefix}
+			assert.StringEqual(t, tc.Expected, p.ClusterName(tc.Cluster))
+		})
+	}
+}
+
+func TestListContext(t *testing.T) {
+	t.Parallel()
+	clusters := []string{"kind", "ci-42-kind", "ci-42-foo", "ci-43-kind", "ci-42"}
+	cases := []struct {
+		Name     string
+		Prefix   string
+		Expected []string
+	}{
+		{Name: "no prefix", Expected: clusters},
+		{Name: "prefix", Prefix: "ci-42", Expected: []string{"kind", "foo"}},
+		{Name: "no matches", Prefix: "ci-44", Expected: []string{}},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			p := &Provider{
+				provider:   &fakeProvider{clusters: clusters},
+				namePrefix: tc.Prefix,
+			}
+			listed, err := p.ListContext(context.Background())
+			assert.ExpectError(t, false, err)
+			assert.DeepEqual(t, tc.Expected, listed)
+		})
+	}
+}
+
+func TestProviderWithNamePrefix(t *testing.T) {
+	t.Parallel()
+	p := &Provider{namePrefix: "from-env"}
+	ProviderWithNamePrefix("ci-42").apply(p)
+	assert.StringEqual(t, "ci-42", p.namePrefix)
+	// the prefix must be set before the runtime option creates the runtime provider
+	if optionPriority(ProviderWithNamePrefix("")) >= optionPriority(ProviderWithDocker()) {
+		t.Errorf("expected the name prefix option to be applied before runtime options")
+	}
+}
+
+func TestNodeProviderDetectionOrder(t *testing.T) {
+	t.Parallel()
+	order := NodeProviderDetectionOrder()
+	order[0] = "mutated"
+	assert.DeepEqual(t, []string{"docker", "podman"}, NodeProviderDetectionOrder())
+}
+
+func TestArtifacts(t *testing.T) {
+	t.Parallel()
+	artifacts := Artifacts()
+	name := artifacts[0].Name
+	artifacts[0].Name = "mutated"
+	assert.StringEqual(t, name, Artifacts()[0].Name)
+}
@@ -0,0 +1,222 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package waiter defines what to wait for once a cluster is created, see
+// cluster.CreateWithWaiters
+package waiter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// DefaultTimeout is the timeout of a Waiter that does not set one
+const DefaultTimeout = 5 * time.Minute
+
+// Cluster is the cluster being waited for
+type Cluster struct {
+	// Name is the cluster name
+	Name string
+	// ControlPlane is a control plane node, with the admin kubeconfig at
+	// /etc/kubernetes/admin.conf
+	ControlPlane nodes.Node
+	// Nodes are the Kubernetes nodes of the cluster
+	Nodes []nodes.Node
+}
+
+// Kubectl runs kubectl with args as the cluster admin inside the control
+// plane node, and returns the output lines joined by spaces
+func (c *Cluster) Kubectl(ctx context.Context, args ...string) (string, error) {
+	cmd := c.ControlPlane.CommandContext(ctx,
+		"kubectl",
+		append([]string{"--kubeconfig=/etc/kubernetes/admin.conf"}, args...)...,
+	)
+	lines, err := exec.OutputLines(cmd)
+	return strings.Join(lines, " "), err
+}
+
+// ReadyFunc returns whether what is waited for is ready. It is called
+// repeatedly until it returns true or the Waiter times out. An error counts
+// as not ready yet, the last error is reported if the Waiter times out
+type ReadyFunc func(ctx context.Context, c *Cluster) (bool, error)
+
+// Waiter is something to wait for once a cluster is created, waiters are
+// waited for in order and each timeout counts from when waiting starts for
+// the first waiter. Creating the cluster fails if a Waiter times out, unless
+// it is WithWarnOnly
+type Waiter struct {
+	description string
+	ready       ReadyFunc
+	timeout     time.Duration
+	warnOnly    bool
+}
+
+// WithTimeout returns a copy of w that times out after timeout
+func (w Waiter) WithTimeout(timeout time.Duration) Waiter {
+	w.timeout = timeout
+	return w
+}
+
+// WithDescription returns a copy of w that is reported as description while
+// waiting for it, e.g. "my operator = Available"
+func (w Waiter) WithDescription(description string) Waiter {
+	w.description = description
+	return w
+}
+
+// WithWarnOnly returns a copy of w that only warns when it times out, the
+// cluster is still created
+func (w Waiter) WithWarnOnly() Waiter {
+	w.warnOnly = true
+	return w
+}
+
+// WarnOnly returns whether w only warns when it times out
+func (w Waiter) WarnOnly() bool {
+	return w.warnOnly
+}
+
+// Description returns how w is reported while waiting for it
+func (w Waiter) Description() string {
+	return w.description
+}
+
+// Timeout returns how long to wait for w, DefaultTimeout if not set
+func (w Waiter) Timeout() time.Duration {
+	if w.timeout <= 0 {
+		return DefaultTimeout
+	}
+	return w.timeout
+}
+
+// Ready returns whether what w waits for is ready in c
+func (w Waiter) Ready(ctx context.Context, c *Cluster) (bool, error) {
+	if w.ready == nil {
+		return false, errors.New("the waiter has nothing to wait for")
+	}
+	return w.ready(ctx, c)
+}
+
+// Custom returns a Waiter for ready, describe it with WithDescription
+func Custom(ready ReadyFunc) Waiter {
+	return Waiter{description: "custom condition", ready: ready}
+}
+
+// controlPlaneLabels are the labels of control plane nodes, Kubernetes 1.20
+// added the control-plane label and 1.24 removed the master label
+var controlPlaneLabels = []string{
+	"node-role.kubernetes.io/control-plane",
+	"node-role.kubernetes.io/master",
+}
+
+// ControlPlane returns a Waiter for the control plane nodes to be Ready
+func ControlPlane() Waiter {
+	return Waiter{
+		description: "control-plane = Ready",
+		ready: func(ctx context.Context, c *Cluster) (bool, error) {
+			for _, label := range controlPlaneLabels {
+				status, err := c.Kubectl(ctx, "get", "nodes", "--selector="+label,
+					`-o=jsonpath={range .items[*]}{.status.conditions[?(@.type=="Ready")].status}{" "}{end}`,
+				)
+				if err != nil {
+					return false, err
+				}
+				// e.g. `True True True` if there are three ready control planes
+				if statuses := strings.Fields(status); len(statuses) > 0 {
+					return allTrue(statuses, len(statuses)), nil
+				}
+			}
+			return false, nil
+		},
+	}
+}
+
+// Nodes returns a Waiter for every node to be Ready
+func Nodes() Waiter {
+	return NodeCondition("Ready")
+}
+
+// NodeCondition returns a Waiter for every node to have the condition of
+// type condition, e.g. "NetworkUnavailable"
+func NodeCondition(condition string) Waiter {
+	return Waiter{
+		description: "nodes = " + condition,
+		ready: func(ctx context.Context, c *Cluster) (bool, error) {
+			status, err := c.Kubectl(ctx, "get", "nodes",
+				fmt.Sprintf(`-o=jsonpath={range .items[*]}{.status.conditions[?(@.type=="%s")].status}{" "}{end}`, condition),
+			)
+			return err == nil && allTrue(strings.Fields(status), len(c.Nodes)), err
+		},
+	}
+}
+
+// Deployment returns a Waiter for the deployment to be Available
+func Deployment(namespace, name string) Waiter {
+	return Waiter{
+		description: fmt.Sprintf("deployment %s/%s = Available", namespace, name),
+		ready: func(ctx context.Context, c *Cluster) (bool, error) {
+			status, err := c.Kubectl(ctx, "--namespace="+namespace, "get", "deployment", name,
+				`-o=jsonpath={.status.conditions[?(@.type=="Available")].status}`,
+			)
+			return err == nil && allTrue(strings.Fields(status), 1), err
+		},
+	}
+}
+
+// ServiceAccount returns a Waiter for the service account to exist, pods
+// using it cannot be created before
+func ServiceAccount(namespace, name string) Waiter {
+	return Waiter{
+		description: fmt.Sprintf("service account %s/%s", namespace, name),
+		ready: func(ctx context.Context, c *Cluster) (bool, error) {
+			_, err := c.Kubectl(ctx, "--namespace="+namespace, "get", "serviceaccount", name)
+			return err == nil, err
+		},
+	}
+}
+
+// APIService returns a Waiter for the aggregated API to be Available, e.g.
+// "v1beta1.metrics.k8s.io"
+func APIService(name string) Waiter {
+	return Waiter{
+		description: fmt.Sprintf("APIService %s = Available", name),
+		ready: func(ctx context.Context, c *Cluster) (bool, error) {
+			status, err := c.Kubectl(ctx, "get", "apiservice", name,
+				`-o=jsonpath={.status.conditions[?(@.type=="Available")].status}`,
+			)
+			return err == nil && allTrue(strings.Fields(status), 1), err
+		},
+	}
+}
+
+// allTrue returns whether there are count statuses and all are True
+func allTrue(statuses []string, count int) bool {
+	if len(statuses) != count {
+		return false
+	}
+	for _, s := range statuses {
+		if s != "True" {
+			return false
+		}
+	}
+	return true
+}
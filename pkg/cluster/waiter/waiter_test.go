@@ -0,0 +1,167 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package waiter
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+// fakeNode prints the statuses of the nodes matching the kubectl selector
+type fakeNode struct {
+	nodes.Node
+	statuses map[string]string
+}
+
+func (n *fakeNode) CommandContext(ctx context.Context, command string, args ...string) exec.Cmd {
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--selector=") {
+			return &fakeCmd{output: n.statuses[strings.TrimPrefix(arg, "--selector=")]}
+		}
+	}
+	return &fakeCmd{}
+}
+
+type fakeCmd struct {
+	exec.Cmd
+	output string
+	stdout io.Writer
+}
+
+func (c *fakeCmd) Run() error {
+	_, err := io.WriteString(c.stdout, c.output)
+	return err
+}
+
+func (c *fakeCmd) SetStdout(w io.Writer) exec.Cmd {
+	c.stdout = w
+	return c
+}
+
+func TestWaiterOptions(t *testing.T) {
+	t.Parallel()
+	w := Deployment("kube-system", "coredns")
+	assert.StringEqual(t, "deployment kube-system/coredns = Available", w.Description())
+	if w.Timeout() != DefaultTimeout {
+		t.Errorf("expected the default timeout, got %v", w.Timeout())
+	}
+	w2 := w.WithTimeout(time.Minute).WithDescription("coredns")
+	if w2.Timeout() != time.Minute {
+		t.Errorf("expected a timeout of 1m, got %v", w2.Timeout())
+	}
+	assert.StringEqual(t, "coredns", w2.Description())
+	// the original is unchanged
+	assert.StringEqual(t, "deployment kube-system/coredns = Available", w.Description())
+	assert.BoolEqual(t, false, w.WarnOnly())
+	assert.BoolEqual(t, true, w.WithWarnOnly().WarnOnly())
+}
+
+func TestControlPlane(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name     string
+		Statuses map[string]string
+		Expected bool
+	}{
+		{
+			Name:     "control-plane label",
+			Statuses: map[string]string{"node-role.kubernetes.io/control-plane": "True True "},
+			Expected: true,
+		},
+		{
+			Name:     "one not ready",
+			Statuses: map[string]string{"node-role.kubernetes.io/control-plane": "True False "},
+		},
+		{
+			Name:     "only the master label",
+			Statuses: map[string]string{"node-role.kubernetes.io/master": "True "},
+			Expected: true,
+		},
+		{
+			Name: "no control plane nodes yet",
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			c := &Cluster{ControlPlane: &fakeNode{statuses: tc.Statuses}}
+			ready, err := ControlPlane().Ready(context.Background(), c)
+			assert.ExpectError(t, false, err)
+			assert.BoolEqual(t, tc.Expected, ready)
+		})
+	}
+}
+
+func TestCustom(t *testing.T) {
+	t.Parallel()
+	calls := 0
+	w := Custom(func(ctx context.Context, c *Cluster) (bool, error) {
+		calls++
+		if c.Name != "kind" {
+			return false, errors.Errorf("unexpected cluster %q", c.Name)
+		}
+		return calls > 1, nil
+	})
+	assert.StringEqual(t, "custom condition", w.Description())
+	c := &Cluster{Name: "kind"}
+	ready, err := w.Ready(context.Background(), c)
+	assert.ExpectError(t, false, err)
+	assert.BoolEqual(t, false, ready)
+	ready, err = w.Ready(context.Background(), c)
+	assert.ExpectError(t, false, err)
+	assert.BoolEqual(t, true, ready)
+	_, err = w.Ready(context.Background(), &Cluster{Name: "other"})
+	assert.ExpectError(t, true, err)
+}
+
+func TestZeroWaiter(t *testing.T) {
+	t.Parallel()
+	ready, err := Waiter{}.Ready(context.Background(), &Cluster{})
+	assert.ExpectError(t, true, err)
+	assert.BoolEqual(t, false, ready)
+}
+
+func TestAllTrue(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name     string
+		Statuses []string
+		Count    int
+		Expected bool
+	}{
+		{Name: "all true", Statuses: []string{"True", "True"}, Count: 2, Expected: true},
+		{Name: "one false", Statuses: []string{"True", "False"}, Count: 2},
+		{Name: "missing", Statuses: []string{"True"}, Count: 2},
+		{Name: "none", Count: 1},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			assert.BoolEqual(t, tc.Expected, allTrue(tc.Statuses, tc.Count))
+		})
+	}
+}
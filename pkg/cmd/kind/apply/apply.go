@@ -0,0 +1,344 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package apply implements the `apply` command
+package apply
+
+import (
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/apis/config/encoding"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+)
+
+type flagpole struct {
+	Config     string
+	Prune      bool
+	Recreate   bool
+	DryRun     bool
+	Wait       time.Duration
+	Kubeconfig string
+}
+
+// NewCommand returns a new cobra.Command for apply
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "apply",
+		Short: "Reconciles the local kind clusters toward the declared clusters",
+		Long: "Reconciles the local kind clusters toward the clusters declared in --config, " +
+			"a file of one or more kind cluster configs separated by '---'.\n\n" +
+			"Missing clusters are created. Existing clusters that differ from the config, " +
+			"in their nodes or in the config recorded when they were created, " +
+			"cannot be updated in place, they are reported unless --recreate is set. " +
+			"Clusters that are not declared are only deleted with --prune.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(logger, streams, flags)
+		},
+	}
+	cmd.Flags().StringVar(&flags.Config, "config", "", "path to the declared cluster configs, or - for stdin")
+	cmd.Flags().BoolVar(&flags.Prune, "prune", false, "delete clusters that are not declared in --config")
+	cmd.Flags().BoolVar(&flags.Recreate, "recreate", false, "delete and recreate clusters whose nodes differ from --config")
+	cmd.Flags().BoolVar(&flags.DryRun, "dry-run", false, "only print the planned changes")
+	cmd.Flags().DurationVar(&flags.Wait, "wait", time.Duration(0), "wait for control plane node to be ready (default 0s)")
+	cmd.Flags().StringVar(&flags.Kubeconfig, "kubeconfig", "", "sets kubeconfig path instead of $KUBECONFIG or $HOME/.kube/config")
+	return cmd
+}
+
+func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
+	if flags.Config == "" {
+		return errors.New("--config is required")
+	}
+	desired, err := readDesired(flags.Config, streams)
+	if err != nil {
+		return err
+	}
+
+	provider := cluster.NewProvider(
+		cluster.ProviderWithLogger(logger),
+		runtime.GetDefault(logger),
+		runtime.NamePrefix(),
+	)
+	existing, err := observe(provider)
+	if err != nil {
+		return err
+	}
+
+	steps := plan(desired, existing, flags.Prune, flags.Recreate)
+	for _, s := range steps {
+		if len(s.Changed) > 0 {
+			fmt.Fprintf(streams.Out, "cluster %q: %s, changed: %s\n", s.Name, s.Action, strings.Join(s.Changed, ", "))
+			continue
+		}
+		fmt.Fprintf(streams.Out, "cluster %q: %s\n", s.Name, s.Action)
+	}
+	if flags.DryRun {
+		return nil
+	}
+
+	for _, s := range steps {
+		switch s.Action {
+		case actionDelete, actionRecreate:
+			if err := provider.Delete(s.Name, flags.Kubeconfig); err != nil {
+				return errors.Wrapf(err, "failed to delete cluster %q", s.Name)
+			}
+		}
+		switch s.Action {
+		case actionCreate, actionRecreate:
+			if err := provider.Create(
+				s.Name,
+				cluster.CreateWithRawConfig(s.Config),
+				cluster.CreateWithWaitForReady(flags.Wait),
+				cluster.CreateWithKubeconfigPath(flags.Kubeconfig),
+			); err != nil {
+				return errors.Wrapf(err, "failed to create cluster %q", s.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// action is a planned change to a single cluster
+type action string
+
+const (
+	actionCreate    action = "create"
+	actionDelete    action = "delete"
+	actionRecreate  action = "recreate"
+	actionUnchanged action = "unchanged"
+	// actionDrifted is reported for clusters that differ from the
+	// declared config, when we will not recreate them
+	actionDrifted action = "differs from config, use --recreate to recreate it"
+	// actionExtra is reported for undeclared clusters, when not pruning
+	actionExtra action = "not declared, use --prune to delete it"
+)
+
+// step is one entry of the reconcile plan
+type step struct {
+	Name   string
+	Action action
+	// Config is the raw declared config, for create and recreate
+	Config []byte
+	// Changed lists what differs from the declared config, for recreate
+	// and drifted clusters
+	Changed []string
+}
+
+// desiredCluster is a cluster declared in the config
+type desiredCluster struct {
+	Name   string
+	Roles  roleCounts
+	Config []byte
+	// Parsed is Config after defaulting
+	Parsed *config.Cluster
+}
+
+// observedCluster is an existing cluster
+type observedCluster struct {
+	Roles roleCounts
+	// Config is the defaulted config recorded when the cluster was created,
+	// nil if kind did not record it, e.g. for clusters from older versions
+	Config *config.Cluster
+}
+
+// roleCounts counts nodes by role
+type roleCounts map[string]int
+
+func (r roleCounts) equal(o roleCounts) bool {
+	if len(r) != len(o) {
+		return false
+	}
+	for role, n := range r {
+		if o[role] != n {
+			return false
+		}
+	}
+	return true
+}
+
+// plan computes the steps to reconcile existing toward desired
+func plan(desired []desiredCluster, existing map[string]observedCluster, prune, recreate bool) []step {
+	steps := []step{}
+	declared := map[string]bool{}
+	for _, d := range desired {
+		declared[d.Name] = true
+		observed, exists := existing[d.Name]
+		if !exists {
+			steps = append(steps, step{Name: d.Name, Action: actionCreate, Config: d.Config})
+			continue
+		}
+		changed := diff(d, observed)
+		switch {
+		case len(changed) == 0:
+			steps = append(steps, step{Name: d.Name, Action: actionUnchanged})
+		case recreate:
+			steps = append(steps, step{Name: d.Name, Action: actionRecreate, Config: d.Config, Changed: changed})
+		default:
+			steps = append(steps, step{Name: d.Name, Action: actionDrifted, Changed: changed})
+		}
+	}
+	// delete extra clusters first so that we free up resources
+	extra := []step{}
+	names := make([]string, 0, len(existing))
+	for name := range existing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if declared[name] {
+			continue
+		}
+		if prune {
+			extra = append(extra, step{Name: name, Action: actionDelete})
+		} else {
+			extra = append(extra, step{Name: name, Action: actionExtra})
+		}
+	}
+	return append(extra, steps...)
+}
+
+// diff returns the config fields that differ between the declared and the
+// observed cluster, the node roles are always compared and the rest of the
+// config only if it was recorded
+func diff(d desiredCluster, o observedCluster) []string {
+	changed := []string{}
+	if !o.Roles.equal(d.Roles) {
+		changed = append(changed, "node roles")
+	}
+	if o.Config == nil || d.Parsed == nil {
+		return changed
+	}
+	// the name may come from --name rather than the config
+	want, got := d.Parsed.DeepCopy(), o.Config.DeepCopy()
+	want.Name, got.Name = "", ""
+	wantValue, gotValue := reflect.ValueOf(want).Elem(), reflect.ValueOf(got).Elem()
+	for i := 0; i < wantValue.NumField(); i++ {
+		if !reflect.DeepEqual(wantValue.Field(i).Interface(), gotValue.Field(i).Interface()) {
+			changed = append(changed, wantValue.Type().Field(i).Name)
+		}
+	}
+	return changed
+}
+
+// readDesired reads and parses the declared clusters from path
+func readDesired(path string, streams cmd.IOStreams) ([]desiredCluster, error) {
+	var raw []byte
+	var err error
+	if path == "-" {
+		raw, err = ioutil.ReadAll(streams.In)
+	} else {
+		raw, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read config")
+	}
+	docs, err := encoding.SplitDocuments(raw)
+	if err != nil {
+		return nil, err
+	}
+	desired := []desiredCluster{}
+	seen := map[string]bool{}
+	for i, doc := range docs {
+		cfg, err := encoding.Parse(doc)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid cluster config at document %d", i)
+		}
+		name := cfg.Name
+		if name == "" {
+			name = cluster.DefaultName
+		}
+		if seen[name] {
+			return nil, errors.Errorf("cluster %q is declared more than once", name)
+		}
+		seen[name] = true
+		roles := roleCounts{}
+		for _, n := range cfg.Nodes {
+			roles[string(n.Role)]++
+		}
+		desired = append(desired, desiredCluster{Name: name, Roles: roles, Config: doc, Parsed: cfg})
+	}
+	return desired, nil
+}
+
+// observe returns the node roles and recorded configs of all existing clusters
+func observe(provider *cluster.Provider) (map[string]observedCluster, error) {
+	clusters, err := provider.List()
+	if err != nil {
+		return nil, err
+	}
+	existing := map[string]observedCluster{}
+	for _, name := range clusters {
+		n, err := provider.ListInternalNodes(name)
+		if err != nil {
+			return nil, err
+		}
+		roles := roleCounts{}
+		for _, node := range n {
+			role, err := node.Role()
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to get role for node %s", node.String())
+			}
+			roles[role]++
+		}
+		recorded, err := recordedConfig(provider, name)
+		if err != nil {
+			return nil, err
+		}
+		existing[name] = observedCluster{Roles: roles, Config: recorded}
+	}
+	return existing, nil
+}
+
+// recordedConfig returns the defaulted config the cluster was created from,
+// with any node image override applied, or nil if it was not recorded
+func recordedConfig(provider *cluster.Provider, name string) (*config.Cluster, error) {
+	info, err := provider.ClusterInfo(name)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Recorded {
+		return nil, nil
+	}
+	// clusters created without a config use the defaults
+	cfg := &config.Cluster{}
+	config.SetDefaultsCluster(cfg)
+	if info.Config != "" {
+		cfg, err = encoding.Parse([]byte(info.Config))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid recorded config for cluster %q", name)
+		}
+	}
+	if info.NodeImage != "" {
+		for i := range cfg.Nodes {
+			cfg.Nodes[i].Image = info.NodeImage
+		}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apply
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/apis/config/encoding"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestPlan(t *testing.T) {
+	t.Parallel()
+	single := roleCounts{"control-plane": 1}
+	withWorker := roleCounts{"control-plane": 1, "worker": 1}
+	desired := []desiredCluster{
+		{Name: "missing", Roles: single},
+		{Name: "same", Roles: single},
+		{Name: "changed", Roles: withWorker},
+	}
+	existing := map[string]observedCluster{
+		"same":    {Roles: single},
+		"changed": {Roles: single},
+		"extra":   {Roles: single},
+	}
+	cases := []struct {
+		Name     string
+		Prune    bool
+		Recreate bool
+		Expected []action
+	}{
+		{
+			Name:     "defaults",
+			Expected: []action{actionExtra, actionCreate, actionUnchanged, actionDrifted},
+		},
+		{
+			Name:     "prune and recreate",
+			Prune:    true,
+			Recreate: true,
+			Expected: []action{actionDelete, actionCreate, actionUnchanged, actionRecreate},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			actions := []action{}
+			for _, s := range plan(desired, existing, tc.Prune, tc.Recreate) {
+				actions = append(actions, s.Action)
+			}
+			assert.DeepEqual(t, tc.Expected, actions)
+		})
+	}
+}
+
+func TestPlanRecordedConfig(t *testing.T) {
+	t.Parallel()
+	parse := func(raw string) *config.Cluster {
+		cfg, err := encoding.Parse([]byte(raw))
+		if err != nil {
+			t.Fatalf("failed to parse config: %v", err)
+		}
+		return cfg
+	}
+	base := "kind: Cluster\napiVersion: kind.x-k8s.io/v1alpha4\n"
+	single := roleCounts{"control-plane": 1}
+	desired := []desiredCluster{
+		{Name: "same", Roles: single, Parsed: parse(base + "name: same\n")},
+		{Name: "networking", Roles: single, Parsed: parse(base + "networking:\n  ipFamily: ipv6\n")},
+		{Name: "image", Roles: single, Parsed: parse(base + "nodes:\n- role: control-plane\n  image: kindest/node:v1.19.1\n")},
+		{Name: "unrecorded", Roles: single, Parsed: parse(base + "networking:\n  ipFamily: ipv6\n")},
+	}
+	existing := map[string]observedCluster{
+		// the recorded name may differ, e.g. from --name
+		"same":       {Roles: single, Config: parse(base)},
+		"networking": {Roles: single, Config: parse(base)},
+		"image":      {Roles: single, Config: parse(base)},
+		"unrecorded": {Roles: single},
+	}
+	expected := []step{
+		{Name: "same", Action: actionUnchanged},
+		{Name: "networking", Action: actionDrifted, Changed: []string{"Networking"}},
+		{Name: "image", Action: actionDrifted, Changed: []string{"Nodes"}},
+		{Name: "unrecorded", Action: actionUnchanged},
+	}
+	assert.DeepEqual(t, expected, plan(desired, existing, false, false))
+}
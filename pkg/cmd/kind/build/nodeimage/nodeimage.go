@@ -31,6 +31,8 @@ type flagpole struct {
 	Image     string
 	BaseImage string
 	KubeRoot  string
+	// PresetImages is true to include optional preset images
+	PresetImages bool
 }
 
 // NewCommand returns a new cobra.Command for building the node image
@@ -65,6 +67,10 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 		nodeimage.DefaultBaseImage,
 		"name:tag of the base image to use for the build",
 	)
+	cmd.Flags().BoolVar(
+		&flags.PresetImages, "preset-images",
+		false, "include the images used by optional cluster presets, e.g. observability",
+	)
 	return cmd
 }
 
@@ -74,6 +80,7 @@ func runE(logger log.Logger, flags *flagpole) error {
 		nodeimage.WithImage(flags.Image),
 		nodeimage.WithBaseImage(flags.BaseImage),
 		nodeimage.WithKuberoot(flags.KubeRoot),
+		nodeimage.WithPresetImages(flags.PresetImages),
 		nodeimage.WithLogger(logger),
 	); err != nil {
 		return errors.Wrap(err, "error building node image")
@@ -0,0 +1,83 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package clone implements the `clone` command
+package clone
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+)
+
+type flagpole struct {
+	From        string
+	Name        string
+	Retain      bool
+	Wait        time.Duration
+	Kubeconfig  string
+	WaitForLock time.Duration
+}
+
+// NewCommand returns a new cobra.Command for cloning a cluster
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "clone",
+		Short: "Creates a copy of an existing cluster",
+		Long: `Creates a new cluster from images committed from the nodes of an existing cluster.
+
+The new cluster has the same config and node filesystems, including images
+loaded into the nodes, but new node names, certificates and machine-ids.
+Fixed host ports are replaced with random ones.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(logger, flags)
+		},
+	}
+	cmd.Flags().StringVar(&flags.From, "from", cluster.DefaultName, "the name of the cluster to clone")
+	cmd.Flags().StringVar(&flags.Name, "name", "", "the new cluster name (default <from>-clone)")
+	cmd.Flags().BoolVar(&flags.Retain, "retain", false, "retain nodes for debugging when cluster creation fails")
+	cmd.Flags().DurationVar(&flags.Wait, "wait", time.Duration(0), "wait for control plane node to be ready (default 0s)")
+	cmd.Flags().StringVar(&flags.Kubeconfig, "kubeconfig", "", "sets kubeconfig path instead of $KUBECONFIG or $HOME/.kube/config")
+	cmd.Flags().DurationVar(&flags.WaitForLock, "wait-for-lock", 0, "wait for another kind process operating on the cluster to finish instead of failing (default 0s)")
+	return cmd
+}
+
+func runE(logger log.Logger, flags *flagpole) error {
+	provider := cluster.NewProvider(
+		cluster.ProviderWithLogger(logger),
+		runtime.GetDefault(logger),
+		runtime.NamePrefix(),
+		cluster.ProviderWithLockWait(flags.WaitForLock),
+	)
+	err := provider.Clone(
+		flags.From,
+		flags.Name,
+		cluster.CreateWithRetain(flags.Retain),
+		cluster.CreateWithWaitForReady(flags.Wait),
+		cluster.CreateWithKubeconfigPath(flags.Kubeconfig),
+		cluster.CreateWithDisplayUsage(true),
+	)
+	return errors.Wrapf(err, "failed to clone cluster %q", flags.From)
+}
@@ -24,22 +24,39 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
 	"sigs.k8s.io/kind/pkg/cluster"
 	"sigs.k8s.io/kind/pkg/cmd"
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/log"
 
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
 	"sigs.k8s.io/kind/pkg/internal/cli"
 	"sigs.k8s.io/kind/pkg/internal/runtime"
 )
 
 type flagpole struct {
-	Name       string
-	Config     string
-	ImageName  string
-	Retain     bool
-	Wait       time.Duration
-	Kubeconfig string
+	Name             string
+	Config           string
+	ImageName        string
+	Retain           bool
+	Wait             string
+	Kubeconfig       string
+	Watch            bool
+	Timing           bool
+	TimingFile       string
+	Presets          []string
+	Ingress          string
+	Offline          bool
+	Archives         []string
+	Resume           bool
+	Retries          int
+	Backoff          time.Duration
+	WaitForLock      time.Duration
+	KubeadmVerbosity int
+	WarningsAsErrors bool
+	TTL              time.Duration
+	Set              []string
 }
 
 // NewCommand returns a new cobra.Command for cluster creation
@@ -56,54 +73,150 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVar(&flags.Name, "name", "", "cluster name, overrides KIND_CLUSTER_NAME, config (default kind)")
-	cmd.Flags().StringVar(&flags.Config, "config", "", "path to a kind config file")
+	cmd.Flags().StringVar(&flags.Config, "config", "", "path to a kind config file, or - for stdin, multiple YAML documents are merged in order")
+	cmd.Flags().StringArrayVar(&flags.Set, "set", nil, "override a config field before validation as path=value, e.g. nodes[1].image=kindest/node:v1.20.0, may be repeated")
 	cmd.Flags().StringVar(&flags.ImageName, "image", "", "node docker image to use for booting the cluster")
 	cmd.Flags().BoolVar(&flags.Retain, "retain", false, "retain nodes for debugging when cluster creation fails")
-	cmd.Flags().DurationVar(&flags.Wait, "wait", time.Duration(0), "wait for control plane node to be ready (default 0s)")
+	cmd.Flags().StringVar(&flags.Wait, "wait", "0s", "wait for control plane node to be ready for a duration, or for comma separated targets NAME[=CONDITION][:TIMEOUT] (control-plane, nodes, coredns, default-sa, metrics)")
 	cmd.Flags().StringVar(&flags.Kubeconfig, "kubeconfig", "", "sets kubeconfig path instead of $KUBECONFIG or $HOME/.kube/config")
+	cmd.Flags().BoolVar(&flags.Watch, "watch", false, "keep running and recreate the cluster when the --config file changes")
+	cmd.Flags().StringSliceVar(&flags.Presets, "preset", nil, "optional presets to install, may be repeated, one of: observability, node-problem-detector")
+	cmd.Flags().StringVar(&flags.Ingress, "ingress", "none", "ingress controller to install on the first control-plane node, forwarding ports 80 and 443, one of: nginx, contour, none")
+	cmd.Flags().BoolVar(&flags.Offline, "offline", false, "fail instead of pulling images or fetching anything remote, missing images are listed")
+	cmd.Flags().StringSliceVar(&flags.Archives, "image-archive", nil, "image archive to load into the nodes before setting up Kubernetes, may be repeated")
+	cmd.Flags().BoolVar(&flags.Resume, "resume", false, "continue a failed creation of the cluster from the last completed phase, nodes are kept if it fails")
+	cmd.Flags().IntVar(&flags.Retries, "retries", 0, "attempts for pulling images and creating the network and nodes before failing (default 5)")
+	cmd.Flags().DurationVar(&flags.Backoff, "retry-backoff", time.Second, "wait before the first retry, doubled for each retry after")
+	cmd.Flags().BoolVar(&flags.Timing, "timing", false, "print a summary of the time taken by each phase of cluster creation")
+	cmd.Flags().StringVar(&flags.TimingFile, "timing-file", "", "write the time taken by each phase of cluster creation to this file as JSON, with --watch it is rewritten for each creation")
+	cmd.Flags().IntVar(&flags.KubeadmVerbosity, "kubeadm-verbosity", 6, "kubeadm log level, the kubeadm output is streamed live at -v 3 and above")
+	cmd.Flags().BoolVar(&flags.WarningsAsErrors, "warnings-as-errors", false, "fail instead of continuing when there are warnings about the config or host, e.g. for strict CI")
+	cmd.Flags().DurationVar(&flags.TTL, "ttl", 0, "mark the cluster as expiring this long after it is created, e.g. 4h, for `kind gc` to delete it (default 0s, never expires)")
+	cmd.Flags().DurationVar(&flags.WaitForLock, "wait-for-lock", 0, "wait for another kind process operating on the cluster to finish instead of failing (default 0s)")
 	return cmd
 }
 
 func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
-	provider := cluster.NewProvider(
+	providerOpts := []cluster.ProviderOption{
 		cluster.ProviderWithLogger(logger),
 		runtime.GetDefault(logger),
-	)
+		runtime.NamePrefix(),
+		cluster.ProviderWithLockWait(flags.WaitForLock),
+	}
+	// record phase timings from provider events if requested
+	var t *timer
+	if flags.Timing || flags.TimingFile != "" {
+		t = startTimer()
+		providerOpts = append(providerOpts, cluster.ProviderWithEventSink(t.sink()))
+	}
+	provider := cluster.NewProvider(providerOpts...)
+
+	if flags.Watch {
+		return watch(logger, provider, flags, t)
+	}
 
 	// handle config flag, we might need to read from stdin
-	withConfig, err := configOption(flags.Config, streams.In)
+	withConfig, err := configOption(flags.Config, flags.Set, streams.In)
 	if err != nil {
 		return err
 	}
 
 	// create the cluster
-	if err = provider.Create(
+	err = provider.Create(
 		flags.Name,
 		withConfig,
 		cluster.CreateWithNodeImage(flags.ImageName),
 		cluster.CreateWithRetain(flags.Retain),
-		cluster.CreateWithWaitForReady(flags.Wait),
+		waitOption(flags.Wait),
 		cluster.CreateWithKubeconfigPath(flags.Kubeconfig),
 		cluster.CreateWithDisplayUsage(true),
 		cluster.CreateWithDisplaySalutation(true),
-	); err != nil {
-		return errors.Wrap(err, "failed to create cluster")
+		cluster.CreateWithPresets(presets(flags.Presets)...),
+		cluster.CreateWithIngress(flags.Ingress),
+		cluster.CreateWithOffline(flags.Offline),
+		cluster.CreateWithImageArchives(flags.Archives...),
+		cluster.CreateWithResume(flags.Resume),
+		cluster.CreateWithRetry(flags.Retries, flags.Backoff),
+		cluster.CreateWithKubeadmVerbosity(flags.KubeadmVerbosity),
+		cluster.CreateWithWarningsAsErrors(flags.WarningsAsErrors),
+		cluster.CreateWithTTL(flags.TTL),
+	)
+	if t != nil {
+		reportTiming(logger, flags, t.stop(timingClusterName(flags), err == nil))
 	}
+	return errors.Wrap(err, "failed to create cluster")
+}
+
+// reportTiming prints and writes summary as requested by --timing and
+// --timing-file
+func reportTiming(logger log.Logger, flags *flagpole, summary timingSummary) {
+	if flags.Timing {
+		logger.V(0).Infof("Cluster creation timing:\n%s", summary.table())
+	}
+	if flags.TimingFile != "" {
+		if err := summary.writeFile(flags.TimingFile); err != nil {
+			logger.Warnf("failed to write --timing-file: %v", err)
+		}
+	}
+}
 
-	return nil
+// presets converts the --preset flag values
+func presets(values []string) []v1alpha4.Preset {
+	out := make([]v1alpha4.Preset, len(values))
+	for i, v := range values {
+		out[i] = v1alpha4.Preset(v)
+	}
+	return out
 }
 
-// configOption converts the raw --config flag value to a cluster creation
-// option matching it. it will read from stdin if the flag value is `-`
-func configOption(rawConfigFlag string, stdin io.Reader) (cluster.CreateOption, error) {
+// waitOption converts the --wait flag value, either a duration to wait for
+// the control plane or a list of wait targets
+func waitOption(wait string) cluster.CreateOption {
+	if d, err := time.ParseDuration(wait); err == nil {
+		return cluster.CreateWithWaitForReady(d)
+	}
+	return cluster.CreateWithWaitFor(wait)
+}
+
+// timingClusterName returns the cluster name for the timing summary,
+// or empty if it can't be determined without consuming stdin
+func timingClusterName(flags *flagpole) string {
+	if flags.Name != "" || (flags.Config == "" && len(flags.Set) == 0) {
+		return clusterName(flags.Name, &config.Cluster{})
+	}
+	if flags.Config == "-" {
+		return ""
+	}
+	_, cfg, err := readConfig(flags.Config, flags.Set)
+	if err != nil {
+		return ""
+	}
+	return clusterName(flags.Name, cfg)
+}
+
+// configOption converts the raw --config flag value and --set overrides to a
+// cluster creation option matching them. it will read from stdin if the flag
+// value is `-`
+func configOption(rawConfigFlag string, overrides []string, stdin io.Reader) (cluster.CreateOption, error) {
 	// if not - then we are using a real file
 	if rawConfigFlag != "-" {
-		return cluster.CreateWithConfigFile(rawConfigFlag), nil
+		if len(overrides) == 0 {
+			return cluster.CreateWithConfigFile(rawConfigFlag), nil
+		}
+		raw, _, err := readConfig(rawConfigFlag, overrides)
+		if err != nil {
+			return nil, err
+		}
+		return cluster.CreateWithRawConfig(raw), nil
 	}
 	// otherwise read from stdin
 	raw, err := ioutil.ReadAll(stdin)
 	if err != nil {
 		return nil, errors.Wrap(err, "error reading config from stdin")
 	}
+	raw, err = applyOverrides(raw, overrides)
+	if err != nil {
+		return nil, err
+	}
 	return cluster.CreateWithRawConfig(raw), nil
 }
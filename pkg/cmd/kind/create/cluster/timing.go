@@ -0,0 +1,183 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// timingRecord is the duration of a single timed part of cluster creation
+type timingRecord struct {
+	// Kind is one of "phase", "step", "image" or "node"
+	Kind     string  `json:"kind"`
+	Name     string  `json:"name"`
+	Success  bool    `json:"success"`
+	Duration float64 `json:"durationSeconds"`
+}
+
+// timingSummary is the --timing-file representation of a cluster creation
+type timingSummary struct {
+	Cluster  string         `json:"cluster,omitempty"`
+	Started  time.Time      `json:"started"`
+	Success  bool           `json:"success"`
+	Duration float64        `json:"durationSeconds"`
+	Records  []timingRecord `json:"records"`
+}
+
+// timer records timings from provider events while a cluster is created
+type timer struct {
+	events  chan cluster.Event
+	laps    chan func()
+	done    chan struct{}
+	started time.Time
+	records []timingRecord
+}
+
+// startTimer returns a timer that is recording events sent to its sink
+func startTimer() *timer {
+	t := &timer{
+		events:  make(chan cluster.Event),
+		laps:    make(chan func()),
+		done:    make(chan struct{}),
+		started: time.Now(),
+	}
+	go func() {
+		defer close(t.done)
+		for {
+			select {
+			case e, ok := <-t.events:
+				if !ok {
+					return
+				}
+				t.record(e)
+			case lap := <-t.laps:
+				lap()
+			}
+		}
+	}()
+	return t
+}
+
+// sink returns the channel to pass to cluster.ProviderWithEventSink
+func (t *timer) sink() chan<- cluster.Event {
+	return t.events
+}
+
+// stop stops recording and returns the summary, the provider must no
+// longer be used to send events
+func (t *timer) stop(name string, success bool) timingSummary {
+	close(t.events)
+	<-t.done
+	return t.summary(name, success)
+}
+
+// lap returns the summary of the events recorded since the timer started or
+// the previous lap, including all events sent before calling it, and starts
+// recording anew for the next cluster creation
+func (t *timer) lap(name string, success bool) timingSummary {
+	var s timingSummary
+	done := make(chan struct{})
+	// run on the recording goroutine, after any event it already received
+	t.laps <- func() {
+		s = t.summary(name, success)
+		t.started, t.records = time.Now(), nil
+		close(done)
+	}
+	<-done
+	return s
+}
+
+func (t *timer) summary(name string, success bool) timingSummary {
+	return timingSummary{
+		Cluster:  name,
+		Started:  t.started,
+		Success:  success,
+		Duration: time.Since(t.started).Seconds(),
+		Records:  t.records,
+	}
+}
+
+func (t *timer) record(e cluster.Event) {
+	r := timingRecord{Success: true, Duration: e.Duration.Seconds()}
+	switch e.Type {
+	case cluster.EventPhaseCompleted:
+		r.Kind, r.Name, r.Success = "phase", phaseName(e.Phase), e.Success
+	case cluster.EventStepCompleted:
+		r.Kind, r.Name = "step", e.Phase
+	case cluster.EventImagePulled:
+		r.Kind, r.Name = "image", e.Image
+	case cluster.EventNodeCreated:
+		r.Kind, r.Name = "node", e.Node
+	default:
+		return
+	}
+	t.records = append(t.records, r)
+}
+
+// phaseName trims the trailing status icons from a phase
+func phaseName(phase string) string {
+	return strings.TrimRightFunc(phase, func(r rune) bool {
+		return r > unicode.MaxASCII || unicode.IsSpace(r)
+	})
+}
+
+// table renders s as a human readable table
+func (s timingSummary) table() string {
+	var buff bytes.Buffer
+	w := tabwriter.NewWriter(&buff, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tNAME\tDURATION\tRESULT")
+	for _, r := range s.Records {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Kind, r.Name, formatSeconds(r.Duration), result(r.Success))
+	}
+	name := s.Cluster
+	if name == "" {
+		name = "cluster"
+	}
+	fmt.Fprintf(w, "total\t%s\t%s\t%s\n", name, formatSeconds(s.Duration), result(s.Success))
+	_ = w.Flush()
+	return buff.String()
+}
+
+// writeFile writes s to path as JSON
+func (s timingSummary) writeFile(path string) error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode timing")
+	}
+	return errors.Wrap(ioutil.WriteFile(path, append(b, '\n'), 0644), "failed to write timing file")
+}
+
+func formatSeconds(seconds float64) string {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Millisecond).String()
+}
+
+func result(success bool) string {
+	if success {
+		return "ok"
+	}
+	return "failed"
+}
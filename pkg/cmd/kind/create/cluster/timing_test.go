@@ -0,0 +1,75 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestTimer(t *testing.T) {
+	t.Parallel()
+	tm := startTimer()
+	sink := tm.sink()
+	sink <- cluster.Event{Type: cluster.EventPhaseStarted, Phase: "Preparing nodes 📦 📦"}
+	sink <- cluster.Event{Type: cluster.EventStepCompleted, Phase: "Ensuring network", Duration: time.Second}
+	sink <- cluster.Event{Type: cluster.EventNodeCreated, Node: "kind-control-plane", Duration: 2 * time.Second}
+	sink <- cluster.Event{Type: cluster.EventPhaseCompleted, Phase: "Preparing nodes 📦 📦", Success: true, Duration: 3 * time.Second}
+	sink <- cluster.Event{Type: cluster.EventWarning, Message: "ignored"}
+	sink <- cluster.Event{Type: cluster.EventPhaseCompleted, Phase: "Installing CNI 🔌", Duration: 500 * time.Millisecond}
+	summary := tm.stop("kind", false)
+
+	assert.StringEqual(t, "kind", summary.Cluster)
+	assert.BoolEqual(t, false, summary.Success)
+	assert.DeepEqual(t, []timingRecord{
+		{Kind: "step", Name: "Ensuring network", Success: true, Duration: 1},
+		{Kind: "node", Name: "kind-control-plane", Success: true, Duration: 2},
+		{Kind: "phase", Name: "Preparing nodes", Success: true, Duration: 3},
+		{Kind: "phase", Name: "Installing CNI", Success: false, Duration: 0.5},
+	}, summary.Records)
+
+	lines := strings.Split(summary.table(), "\n")
+	assert.StringEqual(t, "phase  Installing CNI      500ms     failed", lines[4])
+	if !strings.HasPrefix(lines[5], "total  kind") {
+		t.Errorf("expected a total line, got: %q", lines[5])
+	}
+}
+
+func TestTimerLap(t *testing.T) {
+	t.Parallel()
+	tm := startTimer()
+	sink := tm.sink()
+	sink <- cluster.Event{Type: cluster.EventNodeCreated, Node: "kind-control-plane", Duration: time.Second}
+	first := tm.lap("kind", true)
+	sink <- cluster.Event{Type: cluster.EventNodeCreated, Node: "kind-worker", Duration: 2 * time.Second}
+	second := tm.stop("kind", false)
+
+	assert.DeepEqual(t, []timingRecord{
+		{Kind: "node", Name: "kind-control-plane", Success: true, Duration: 1},
+	}, first.Records)
+	assert.BoolEqual(t, true, first.Success)
+	assert.DeepEqual(t, []timingRecord{
+		{Kind: "node", Name: "kind-worker", Success: true, Duration: 2},
+	}, second.Records)
+	if second.Started.Before(first.Started) {
+		t.Errorf("expected the second lap to start after the first")
+	}
+}
@@ -0,0 +1,238 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"reflect"
+	"syscall"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/apis/config/encoding"
+)
+
+// watchInterval is how often the config file is checked for changes
+const watchInterval = time.Second
+
+// watchSettle is how long a change must be stable before the cluster is
+// recreated, so that a burst of saves only recreates it once
+const watchSettle = 2 * time.Second
+
+// watch creates the cluster and then recreates it each time the effective
+// config in flags.Config changes, until interrupted. If t is not nil, it
+// receives the events of provider and each creation is timed
+func watch(logger log.Logger, provider *cluster.Provider, flags *flagpole, t *timer) error {
+	if flags.Config == "" || flags.Config == "-" {
+		return errors.New("--watch requires --config to be a file")
+	}
+
+	// stop watching on interrupt, aborting any in progress creation
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		<-signals
+		cancel()
+	}()
+
+	w, err := newConfigWatcher(func() ([]byte, *config.Cluster, error) {
+		return readConfig(flags.Config, flags.Set)
+	}, watchSettle)
+	if err != nil {
+		return err
+	}
+	name := clusterName(flags.Name, w.cfg)
+	if err := recreate(ctx, logger, provider, t, flags, "", w.raw, w.cfg); err != nil {
+		return err
+	}
+
+	logger.V(0).Infof("Watching %s for changes, press Ctrl+C to stop", flags.Config)
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			logger.V(0).Infof("Stopped watching, cluster %q was left running", name)
+			return nil
+		case now := <-ticker.C:
+			changed, err := w.check(now)
+			if err != nil {
+				// the file may be mid-edit, keep the current cluster
+				logger.Warnf("ignoring invalid config: %v", err)
+			}
+			if !changed {
+				continue
+			}
+		}
+		logger.V(0).Infof("Config changed, recreating cluster %q ...", name)
+		if err := recreate(ctx, logger, provider, t, flags, name, w.raw, w.cfg); err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			// keep watching so the next edit can fix the problem
+			logger.Errorf("failed to recreate cluster: %v", err)
+		}
+		// the cluster may have been renamed in the config
+		name = clusterName(flags.Name, w.cfg)
+	}
+}
+
+// configWatcher detects settled changes to the effective config
+type configWatcher struct {
+	read   func() ([]byte, *config.Cluster, error)
+	settle time.Duration
+	// raw and cfg are the config the cluster was last created from
+	raw []byte
+	cfg *config.Cluster
+	// pending is a changed raw config that has not settled yet
+	pending      []byte
+	pendingSince time.Time
+	// invalid is the error last returned for an invalid config, if any
+	invalid string
+}
+
+// newConfigWatcher returns a configWatcher for the config returned by read,
+// which must currently be valid
+func newConfigWatcher(read func() ([]byte, *config.Cluster, error), settle time.Duration) (*configWatcher, error) {
+	raw, cfg, err := read()
+	if err != nil {
+		return nil, err
+	}
+	return &configWatcher{read: read, settle: settle, raw: raw, cfg: cfg}, nil
+}
+
+// check reads the config as of now, and returns true once a change to the
+// effective config has been stable for the settle duration. Changes that do
+// not affect the cluster, e.g. to comments, are ignored. An invalid config is
+// only returned as an error the first time it is read, until it changes
+func (w *configWatcher) check(now time.Time) (bool, error) {
+	raw, cfg, err := w.read()
+	if err != nil {
+		w.pending = nil
+		if err.Error() == w.invalid {
+			return false, nil
+		}
+		w.invalid = err.Error()
+		return false, err
+	}
+	w.invalid = ""
+	if string(raw) == string(w.raw) {
+		w.pending = nil
+		return false, nil
+	}
+	if w.pending == nil || string(raw) != string(w.pending) {
+		w.pending = raw
+		w.pendingSince = now
+	}
+	if now.Sub(w.pendingSince) < w.settle {
+		return false, nil
+	}
+	w.raw, w.pending = raw, nil
+	if reflect.DeepEqual(cfg, w.cfg) {
+		return false, nil
+	}
+	w.cfg = cfg
+	return true, nil
+}
+
+// recreate creates the cluster from raw, which parses to cfg, deleting the
+// existing cluster first if any, and reports the timing of the creation if t
+// is not nil
+func recreate(ctx context.Context, logger log.Logger, provider *cluster.Provider, t *timer, flags *flagpole, existing string, raw []byte, cfg *config.Cluster) error {
+	if existing != "" {
+		if err := provider.DeleteContext(ctx, existing, flags.Kubeconfig); err != nil {
+			return errors.Wrap(err, "failed to delete cluster")
+		}
+	}
+	if t != nil {
+		// only time the creation, not the deletion
+		t.lap("", true)
+	}
+	err := provider.CreateContext(
+		ctx,
+		flags.Name,
+		cluster.CreateWithRawConfig(raw),
+		cluster.CreateWithNodeImage(flags.ImageName),
+		cluster.CreateWithRetain(flags.Retain),
+		waitOption(flags.Wait),
+		cluster.CreateWithKubeconfigPath(flags.Kubeconfig),
+		cluster.CreateWithDisplayUsage(existing == ""),
+		cluster.CreateWithIngress(flags.Ingress),
+		cluster.CreateWithOffline(flags.Offline),
+		cluster.CreateWithImageArchives(flags.Archives...),
+		cluster.CreateWithRetry(flags.Retries, flags.Backoff),
+		cluster.CreateWithKubeadmVerbosity(flags.KubeadmVerbosity),
+		cluster.CreateWithWarningsAsErrors(flags.WarningsAsErrors),
+		cluster.CreateWithTTL(flags.TTL),
+	)
+	if t != nil {
+		reportTiming(logger, flags, t.lap(clusterName(flags.Name, cfg), err == nil))
+	}
+	return errors.Wrap(err, "failed to create cluster")
+}
+
+// readConfig reads the config file at path, if any, applies the --set
+// overrides to it and parses it
+func readConfig(path string, overrides []string) ([]byte, *config.Cluster, error) {
+	var raw []byte
+	if path != "" {
+		var err error
+		raw, err = ioutil.ReadFile(path)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "error reading config")
+		}
+	}
+	raw, err := applyOverrides(raw, overrides)
+	if err != nil {
+		return nil, nil, err
+	}
+	cfg, err := encoding.Parse(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	return raw, cfg, nil
+}
+
+// applyOverrides applies the --set overrides to the raw config, if any
+func applyOverrides(raw []byte, overrides []string) ([]byte, error) {
+	if len(overrides) == 0 {
+		return raw, nil
+	}
+	raw, err := encoding.ApplyOverrides(raw, overrides)
+	return raw, errors.Wrap(err, "invalid --set")
+}
+
+// clusterName returns the name the cluster will be created with
+func clusterName(flagName string, cfg *config.Cluster) string {
+	if flagName != "" {
+		return flagName
+	}
+	if cfg.Name != "" {
+		return cfg.Name
+	}
+	return cluster.DefaultName
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/apis/config/encoding"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+const watchTestConfig = `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+nodes:
+- role: control-plane
+`
+
+func TestConfigWatcher(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kind-watch")
+	if err != nil {
+		t.Fatalf("failed to create tempdir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "kind.yaml")
+	write := func(contents string) {
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+	}
+	write(watchTestConfig)
+
+	w, err := newConfigWatcher(func() ([]byte, *config.Cluster, error) {
+		return readConfig(path, nil)
+	}, 2*time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error creating watcher: %v", err)
+	}
+	start := time.Now()
+	check := func(description string, after time.Duration, expectChanged, expectErr bool) {
+		changed, err := w.check(start.Add(after))
+		assert.ExpectError(t, expectErr, err)
+		if changed != expectChanged {
+			t.Fatalf("%s: expected changed to be %v", description, expectChanged)
+		}
+	}
+
+	check("unchanged", time.Second, false, false)
+
+	// comment only changes are not a change to the cluster
+	write(watchTestConfig + "# comment\n")
+	check("comment seen", 2*time.Second, false, false)
+	check("comment settled", 4*time.Second, false, false)
+
+	// an invalid config mid-edit is reported, but not a change
+	write(watchTestConfig + "- role: [\n")
+	check("invalid", 5*time.Second, false, true)
+	check("still invalid", 5500*time.Millisecond, false, false)
+	write(watchTestConfig + "- role: [worker\n")
+	check("differently invalid", 5700*time.Millisecond, false, true)
+
+	// each further edit restarts the settle period
+	write(watchTestConfig + "- role: worker\n")
+	check("first edit", 6*time.Second, false, false)
+	write(watchTestConfig + "- role: worker\n- role: worker\n")
+	check("second edit", 7*time.Second, false, false)
+	check("second edit not settled", 8*time.Second, false, false)
+	check("second edit settled", 9*time.Second, true, false)
+	assert.StringEqual(t, watchTestConfig+"- role: worker\n- role: worker\n", string(w.raw))
+	if len(w.cfg.Nodes) != 3 {
+		t.Fatalf("expected the changed config to have 3 nodes, got %d", len(w.cfg.Nodes))
+	}
+	check("after change", 12*time.Second, false, false)
+
+	// reverting before a change settles is not a change
+	write(watchTestConfig)
+	check("revert seen", 13*time.Second, false, false)
+	write(watchTestConfig + "- role: worker\n- role: worker\n")
+	check("revert undone", 16*time.Second, false, false)
+}
+
+func TestNewConfigWatcherInvalid(t *testing.T) {
+	t.Parallel()
+	_, err := newConfigWatcher(func() ([]byte, *config.Cluster, error) {
+		return readConfig(filepath.Join("testdata", "does-not-exist.yaml"), nil)
+	}, time.Second)
+	assert.ExpectError(t, true, err)
+}
+
+func TestApplyOverrides(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name        string
+		Overrides   []string
+		ExpectNodes int
+		ExpectName  string
+		ExpectError bool
+	}{
+		{
+			Name:        "no overrides",
+			ExpectNodes: 1,
+		},
+		{
+			Name:        "set name and append node",
+			Overrides:   []string{"name=watched", "nodes[1].role=worker"},
+			ExpectNodes: 2,
+			ExpectName:  "watched",
+		},
+		{
+			Name:        "invalid override",
+			Overrides:   []string{"nodes"},
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			raw, err := applyOverrides([]byte(watchTestConfig), tc.Overrides)
+			assert.ExpectError(t, tc.ExpectError, err)
+			if err != nil {
+				return
+			}
+			cfg, err := encoding.Parse(raw)
+			if err != nil {
+				t.Fatalf("unexpected error parsing overridden config: %v", err)
+			}
+			if len(cfg.Nodes) != tc.ExpectNodes {
+				t.Fatalf("expected %d nodes, got %d", tc.ExpectNodes, len(cfg.Nodes))
+			}
+			assert.StringEqual(t, tc.ExpectName, cfg.Name)
+		})
+	}
+}
+
+func TestClusterName(t *testing.T) {
+	t.Parallel()
+	assert.StringEqual(t, "flag", clusterName("flag", &config.Cluster{Name: "config"}))
+	assert.StringEqual(t, "config", clusterName("", &config.Cluster{Name: "config"}))
+	assert.StringEqual(t, "kind", clusterName("", &config.Cluster{}))
+}
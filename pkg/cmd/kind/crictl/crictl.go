@@ -0,0 +1,37 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package crictl implements the `crictl` command
+package crictl
+
+import (
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/nodeexec"
+)
+
+// NewCommand returns a new cobra.Command for running crictl on a node
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	return nodeexec.NewCommand(logger, streams, nodeexec.Tool{
+		Name:    "crictl",
+		Short:   "Runs crictl on a node, configured for the node's containerd",
+		Example: "  kind crictl --node worker -- ps",
+		Args:    []string{"--runtime-endpoint", "unix://" + nodeexec.ContainerdAddress},
+	})
+}
@@ -18,6 +18,8 @@ limitations under the License.
 package cluster
 
 import (
+	"time"
+
 	"github.com/spf13/cobra"
 
 	"sigs.k8s.io/kind/pkg/cluster"
@@ -30,8 +32,11 @@ import (
 )
 
 type flagpole struct {
-	Name       string
-	Kubeconfig string
+	Name        string
+	Kubeconfig  string
+	Force       bool
+	Graceful    bool
+	WaitForLock time.Duration
 }
 
 // NewCommand returns a new cobra.Command for cluster creation
@@ -50,6 +55,9 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 	}
 	cmd.Flags().StringVar(&flags.Name, "name", cluster.DefaultName, "the cluster name")
 	cmd.Flags().StringVar(&flags.Kubeconfig, "kubeconfig", "", "sets kubeconfig path instead of $KUBECONFIG or $HOME/.kube/config")
+	cmd.Flags().BoolVar(&flags.Force, "force", false, "delete the cluster as quickly as possible, skipping --graceful")
+	cmd.Flags().BoolVar(&flags.Graceful, "graceful", false, "drain the nodes and cleanly stop Kubernetes and etcd before deleting the nodes")
+	cmd.Flags().DurationVar(&flags.WaitForLock, "wait-for-lock", 0, "wait for another kind process operating on the cluster to finish instead of failing (default 0s)")
 	return cmd
 }
 
@@ -57,10 +65,15 @@ func deleteCluster(logger log.Logger, flags *flagpole) error {
 	provider := cluster.NewProvider(
 		cluster.ProviderWithLogger(logger),
 		runtime.GetDefault(logger),
+		runtime.NamePrefix(),
+		cluster.ProviderWithLockWait(flags.WaitForLock),
 	)
 	// Delete individual cluster
 	logger.V(0).Infof("Deleting cluster %q ...", flags.Name)
-	if err := provider.Delete(flags.Name, flags.Kubeconfig); err != nil {
+	if err := provider.Delete(flags.Name, flags.Kubeconfig,
+		cluster.DeleteWithForce(flags.Force),
+		cluster.DeleteWithGracefulShutdown(flags.Graceful),
+	); err != nil {
 		return errors.Wrapf(err, "failed to delete cluster %q", flags.Name)
 	}
 	return nil
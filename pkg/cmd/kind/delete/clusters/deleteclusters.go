@@ -18,6 +18,13 @@ limitations under the License.
 package clusters
 
 import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	osexec "os/exec"
+	"sync"
+	"time"
+
 	"github.com/spf13/cobra"
 
 	"sigs.k8s.io/kind/pkg/cluster"
@@ -29,8 +36,13 @@ import (
 )
 
 type flagpole struct {
-	Kubeconfig string
-	All        bool
+	Kubeconfig  string
+	All         bool
+	Force       bool
+	Async       bool
+	Graceful    bool
+	WaitForLock time.Duration
+	Parallel    int
 }
 
 // NewCommand returns a new cobra.Command for cluster creation
@@ -46,12 +58,20 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 			if !flags.All && len(args) == 0 {
 				return errors.New("no cluster names provided")
 			}
+			if flags.Parallel < 1 {
+				return errors.New("--parallel must be at least 1")
+			}
 
 			return deleteClusters(logger, flags, args)
 		},
 	}
 	cmd.Flags().StringVar(&flags.Kubeconfig, "kubeconfig", "", "sets kubeconfig path instead of $KUBECONFIG or $HOME/.kube/config")
 	cmd.Flags().BoolVar(&flags.All, "all", false, "delete all clusters")
+	cmd.Flags().BoolVar(&flags.Force, "force", false, "delete the clusters as quickly as possible, skipping --graceful")
+	cmd.Flags().BoolVar(&flags.Async, "async", false, "return immediately and delete the clusters in a background process, logging to a temporary file")
+	cmd.Flags().BoolVar(&flags.Graceful, "graceful", false, "drain the nodes and cleanly stop Kubernetes and etcd before deleting the nodes")
+	cmd.Flags().DurationVar(&flags.WaitForLock, "wait-for-lock", 0, "wait for another kind process operating on the cluster to finish instead of failing (default 0s)")
+	cmd.Flags().IntVar(&flags.Parallel, "parallel", 4, "the number of clusters to delete at once")
 	return cmd
 }
 
@@ -59,6 +79,8 @@ func deleteClusters(logger log.Logger, flags *flagpole, clusters []string) error
 	provider := cluster.NewProvider(
 		cluster.ProviderWithLogger(logger),
 		runtime.GetDefault(logger),
+		runtime.NamePrefix(),
+		cluster.ProviderWithLockWait(flags.WaitForLock),
 	)
 	var err error
 	if flags.All {
@@ -67,10 +89,20 @@ func deleteClusters(logger log.Logger, flags *flagpole, clusters []string) error
 			return errors.Wrap(err, "failed listing clusters for delete")
 		}
 	}
+	if flags.Async {
+		return deleteInBackground(logger, flags, clusters)
+	}
+
+	failed := deleteAll(clusters, flags.Parallel, func(name string) error {
+		return provider.Delete(name, flags.Kubeconfig,
+			cluster.DeleteWithForce(flags.Force),
+			cluster.DeleteWithGracefulShutdown(flags.Graceful),
+		)
+	})
 	var success []string
-	for _, cluster := range clusters {
-		if err = provider.Delete(cluster, flags.Kubeconfig); err != nil {
-			logger.V(0).Infof("%s\n", errors.Wrapf(err, "failed to delete cluster %q", cluster))
+	for i, cluster := range clusters {
+		if failed[i] != nil {
+			logger.V(0).Infof("%s\n", errors.Wrapf(failed[i], "failed to delete cluster %q", cluster))
 			continue
 		}
 		success = append(success, cluster)
@@ -78,3 +110,72 @@ func deleteClusters(logger log.Logger, flags *flagpole, clusters []string) error
 	logger.V(0).Infof("Deleted clusters: %q", success)
 	return nil
 }
+
+// deleteAll deletes the clusters concurrently, at most parallel at a time,
+// returning the result of each delete in the order of clusters
+func deleteAll(clusters []string, parallel int, deleteCluster func(name string) error) []error {
+	failed := make([]error, len(clusters))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, name := range clusters {
+		i, name := i, name // capture loop variables
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			failed[i] = deleteCluster(name)
+		}()
+	}
+	wg.Wait()
+	return failed
+}
+
+// deleteInBackground starts a detached kind process deleting the clusters
+// and returns without waiting for it
+func deleteInBackground(logger log.Logger, flags *flagpole, clusters []string) error {
+	if len(clusters) == 0 {
+		logger.V(0).Info("No clusters to delete")
+		return nil
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return errors.Wrap(err, "failed to find the kind binary")
+	}
+	// the name prefix is inherited from the environment, the runtime
+	// is passed along as it may have been set by flag
+	selection, err := runtime.Select()
+	if err != nil {
+		return err
+	}
+	args := backgroundArgs(flags, selection.Runtime, clusters)
+	logFile, err := ioutil.TempFile("", "kind-delete-*.log")
+	if err != nil {
+		return errors.Wrap(err, "failed to create log file")
+	}
+	defer logFile.Close()
+	child := osexec.Command(self, args...)
+	child.Stdout = logFile
+	child.Stderr = logFile
+	detach(child)
+	if err := child.Start(); err != nil {
+		return errors.Wrap(err, "failed to start background delete")
+	}
+	logger.V(0).Infof("Deleting clusters %q in the background, see %s", clusters, logFile.Name())
+	return child.Process.Release()
+}
+
+// backgroundArgs returns the arguments for the kind process started by
+// deleteInBackground, carrying over the flags of this invocation
+func backgroundArgs(flags *flagpole, runtimeName string, clusters []string) []string {
+	args := []string{
+		"delete", "clusters",
+		"--runtime", runtimeName,
+		"--kubeconfig", flags.Kubeconfig,
+		"--wait-for-lock", flags.WaitForLock.String(),
+		fmt.Sprintf("--force=%t", flags.Force),
+		fmt.Sprintf("--graceful=%t", flags.Graceful),
+		fmt.Sprintf("--parallel=%d", flags.Parallel),
+	}
+	return append(args, clusters...)
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusters
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+func TestDeleteAll(t *testing.T) {
+	t.Parallel()
+	clusters := []string{"a", "b", "c", "d", "e", "f", "g"}
+	const parallel = 2
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	failed := deleteAll(clusters, parallel, func(name string) error {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		if name == "c" {
+			return errors.New("boom")
+		}
+		return nil
+	})
+	if maxRunning > parallel {
+		t.Errorf("expected at most %d concurrent deletes, got %d", parallel, maxRunning)
+	}
+	assert.BoolEqual(t, true, len(failed) == len(clusters))
+	for i, name := range clusters {
+		assert.BoolEqual(t, name == "c", failed[i] != nil)
+	}
+}
+
+func TestBackgroundArgs(t *testing.T) {
+	t.Parallel()
+	flags := &flagpole{
+		Kubeconfig:  "/tmp/kubeconfig",
+		Force:       true,
+		WaitForLock: time.Minute,
+		Parallel:    3,
+	}
+	assert.DeepEqual(t, []string{
+		"delete", "clusters",
+		"--runtime", "podman",
+		"--kubeconfig", "/tmp/kubeconfig",
+		"--wait-for-lock", "1m0s",
+		"--force=true",
+		"--graceful=false",
+		"--parallel=3",
+		"a", "b",
+	}, backgroundArgs(flags, "podman", []string{"a", "b"}))
+}
+
+func TestNewCommandValidation(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name string
+		Args []string
+	}{
+		{
+			Name: "no clusters",
+			Args: []string{},
+		},
+		{
+			Name: "parallel zero",
+			Args: []string{"--parallel", "0", "kind"},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			c := NewCommand(log.NoopLogger{}, cmd.IOStreams{})
+			c.SetArgs(tc.Args)
+			c.SilenceErrors = true
+			c.SilenceUsage = true
+			assert.ExpectError(t, true, c.Execute())
+		})
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package compose implements the `compose` command
+package compose
+
+import (
+	"io/ioutil"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+)
+
+type flagpole struct {
+	Name   string
+	Format string
+}
+
+// NewCommand returns a new cobra.Command for exporting a compose file
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.MaximumNArgs(1),
+		Use:   "compose [output-file]",
+		Short: "Exports the cluster's node containers as a compose file",
+		Long: "Exports the cluster's node containers, including their mounts, networks and port mappings, " +
+			"as a docker compose file or as Kubernetes Pod YAML for `podman kube play`.\n" +
+			"The output is written to stdout unless an output file is given.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			return runE(logger, streams, flags, args)
+		},
+	}
+	cmd.Flags().StringVar(
+		&flags.Name,
+		"name",
+		cluster.DefaultName,
+		"the cluster context name",
+	)
+	cmd.Flags().StringVar(
+		&flags.Format,
+		"format",
+		string(cluster.ComposeFormatCompose),
+		"output format, one of: compose, kube",
+	)
+	return cmd
+}
+
+func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole, args []string) error {
+	format := cluster.ComposeFormat(flags.Format)
+	switch format {
+	case cluster.ComposeFormatCompose, cluster.ComposeFormatKube:
+	default:
+		return errors.Errorf("unknown --format %q, expected one of: compose, kube", flags.Format)
+	}
+	provider := cluster.NewProvider(
+		cluster.ProviderWithLogger(logger),
+		runtime.GetDefault(logger),
+		runtime.NamePrefix(),
+	)
+	out, err := provider.Compose(flags.Name, format)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 || args[0] == "-" {
+		_, err := streams.Out.Write([]byte(out))
+		return err
+	}
+	if err := ioutil.WriteFile(args[0], []byte(out), 0644); err != nil {
+		return errors.Wrapf(err, "failed to write %s", args[0])
+	}
+	logger.V(0).Infof("Exported cluster %q to %s", flags.Name, args[0])
+	return nil
+}
@@ -21,8 +21,10 @@ import (
 	"github.com/spf13/cobra"
 
 	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/cmd/kind/export/compose"
 	"sigs.k8s.io/kind/pkg/cmd/kind/export/kubeconfig"
 	"sigs.k8s.io/kind/pkg/cmd/kind/export/logs"
+	"sigs.k8s.io/kind/pkg/cmd/kind/export/supportbundle"
 	"sigs.k8s.io/kind/pkg/log"
 )
 
@@ -32,11 +34,13 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 		Args: cobra.NoArgs,
 		// TODO(bentheelder): more detailed usage
 		Use:   "export",
-		Short: "Exports one of [kubeconfig, logs]",
-		Long:  "Exports one of [kubeconfig, logs]",
+		Short: "Exports one of [compose, kubeconfig, logs, support-bundle]",
+		Long:  "Exports one of [compose, kubeconfig, logs, support-bundle]",
 	}
 	// add subcommands
 	cmd.AddCommand(logs.NewCommand(logger, streams))
 	cmd.AddCommand(kubeconfig.NewCommand(logger, streams))
+	cmd.AddCommand(compose.NewCommand(logger, streams))
+	cmd.AddCommand(supportbundle.NewCommand(logger, streams))
 	return cmd
 }
@@ -18,6 +18,8 @@ limitations under the License.
 package kubeconfig
 
 import (
+	"time"
+
 	"github.com/spf13/cobra"
 
 	"sigs.k8s.io/kind/pkg/cluster"
@@ -29,8 +31,9 @@ import (
 )
 
 type flagpole struct {
-	Name       string
-	Kubeconfig string
+	Name        string
+	Kubeconfig  string
+	WaitForLock time.Duration
 }
 
 // NewCommand returns a new cobra.Command for exporting the kubeconfig
@@ -58,6 +61,12 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 		"",
 		"sets kubeconfig path instead of $KUBECONFIG or $HOME/.kube/config",
 	)
+	cmd.Flags().DurationVar(
+		&flags.WaitForLock,
+		"wait-for-lock",
+		0,
+		"wait for another kind process operating on the cluster to finish instead of failing (default 0s)",
+	)
 	return cmd
 }
 
@@ -65,12 +74,14 @@ func runE(logger log.Logger, flags *flagpole) error {
 	provider := cluster.NewProvider(
 		cluster.ProviderWithLogger(logger),
 		runtime.GetDefault(logger),
+		runtime.NamePrefix(),
+		cluster.ProviderWithLockWait(flags.WaitForLock),
 	)
 	if err := provider.ExportKubeConfig(flags.Name, flags.Kubeconfig); err != nil {
 		return err
 	}
 	// TODO: get kind-name from a method? OTOH we probably want to keep this
 	// naming scheme stable anyhow...
-	logger.V(0).Infof(`Set kubectl context to "kind-%s"`, flags.Name)
+	logger.V(0).Infof(`Set kubectl context to "kind-%s"`, provider.ClusterName(flags.Name))
 	return nil
 }
@@ -18,21 +18,34 @@ limitations under the License.
 package logs
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"sigs.k8s.io/kind/pkg/cluster"
 	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/fs"
 	"sigs.k8s.io/kind/pkg/log"
 
+	"sigs.k8s.io/kind/pkg/internal/archive"
 	"sigs.k8s.io/kind/pkg/internal/cli"
 	"sigs.k8s.io/kind/pkg/internal/runtime"
 )
 
 type flagpole struct {
-	Name string
+	Name       string
+	Nodes      []string
+	Since      time.Duration
+	Components []string
+	All        bool
+	Selector   string
+	Parallel   int
 }
 
 // NewCommand returns a new cobra.Command for getting the cluster logs
@@ -43,13 +56,44 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 		// TODO(bentheelder): more detailed usage
 		Use:   "logs [output-dir]",
 		Short: "Exports logs to a tempdir or [output-dir] if specified",
-		Long:  "Exports logs to a tempdir or [output-dir] if specified",
+		Long: "Exports logs to a tempdir or [output-dir] if specified\n\n" +
+			"If [output-dir] is \"-\" the logs are written to stdout as a gzipped tarball\n\n" +
+			"With --all or --selector the logs of each matching cluster are exported to a\n" +
+			"subdirectory named after the cluster",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			multi := flags.All || flags.Selector != ""
+			if multi && cmd.Flags().Changed("name") {
+				return errors.New("--name cannot be combined with --all or --selector")
+			}
+			if multi && len(flags.Nodes) > 0 {
+				return errors.New("--nodes cannot be combined with --all or --selector")
+			}
+			if flags.Parallel < 1 {
+				return errors.New("--parallel must be at least 1")
+			}
 			cli.OverrideDefaultName(cmd.Flags())
 			return runE(logger, streams, flags, args)
 		},
 	}
 	cmd.Flags().StringVar(&flags.Name, "name", cluster.DefaultName, "the cluster context name")
+	cmd.Flags().StringSliceVar(
+		&flags.Nodes, "nodes",
+		nil, "comma separated list of nodes to collect logs from, defaults to all nodes",
+	)
+	cmd.Flags().DurationVar(
+		&flags.Since, "since",
+		0, "only collect journal logs newer than this duration, e.g. 30m",
+	)
+	cmd.Flags().StringSliceVar(
+		&flags.Components, "components",
+		nil, "comma separated list of log components to collect, defaults to all of: "+strings.Join(cluster.LogComponents, ","),
+	)
+	cmd.Flags().BoolVar(&flags.All, "all", false, "export the logs of all clusters")
+	cmd.Flags().StringVarP(
+		&flags.Selector, "selector", "l",
+		"", "export the logs of the clusters with these labels, e.g. ci-job=1234,team=net",
+	)
+	cmd.Flags().IntVar(&flags.Parallel, "parallel", 4, "the number of clusters to export logs from at once, with --all or --selector")
 	return cmd
 }
 
@@ -57,20 +101,37 @@ func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole, args []stri
 	provider := cluster.NewProvider(
 		cluster.ProviderWithLogger(logger),
 		runtime.GetDefault(logger),
+		runtime.NamePrefix(),
 	)
 
-	// Check if the cluster has any running nodes
-	nodes, err := provider.ListNodes(flags.Name)
-	if err != nil {
-		return err
-	}
-	if len(nodes) == 0 {
-		return fmt.Errorf("unknown cluster %q", flags.Name)
+	multi := flags.All || flags.Selector != ""
+	var clusters []string
+	if multi {
+		selected, err := selectClusters(logger, provider, flags.Selector)
+		if err != nil {
+			return err
+		}
+		if len(selected) == 0 {
+			logger.V(0).Info("No kind clusters found.")
+			return nil
+		}
+		clusters = selected
+	} else {
+		// Check if the cluster has any running nodes
+		nodes, err := provider.ListNodes(flags.Name)
+		if err != nil {
+			return err
+		}
+		if len(nodes) == 0 {
+			return errors.ClusterNotFound(flags.Name)
+		}
 	}
 
 	// get the optional directory argument, or create a tempdir
+	// when streaming to stdout we collect to a tempdir and archive it after
+	toStdout := len(args) > 0 && args[0] == "-"
 	var dir string
-	if len(args) == 0 {
+	if len(args) == 0 || toStdout {
 		t, err := fs.TempDir("", "")
 		if err != nil {
 			return err
@@ -79,13 +140,115 @@ func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole, args []stri
 	} else {
 		dir = args[0]
 	}
+	if toStdout {
+		defer os.RemoveAll(dir)
+	}
 
 	// collect the logs
-	if err := provider.CollectLogs(flags.Name, dir); err != nil {
+	opts := cluster.CollectLogsOptions{
+		Nodes:      flags.Nodes,
+		Since:      flags.Since,
+		Components: flags.Components,
+	}
+	if multi {
+		// the other clusters are still exported if one fails, and the
+		// failures reported together after
+		err := collectAll(logger, provider, clusters, dir, opts, flags.Parallel)
+		if toStdout {
+			if archiveErr := archive.WriteTarGz(streams.Out, dir); archiveErr != nil {
+				return archiveErr
+			}
+			return err
+		}
+		logger.V(0).Infof("Exported logs for %d clusters to:", len(clusters))
+		fmt.Fprintln(streams.Out, dir)
+		return err
+	}
+	if err := provider.CollectLogsWithOptions(context.Background(), flags.Name, dir, opts); err != nil {
 		return err
 	}
 
+	if toStdout {
+		return archive.WriteTarGz(streams.Out, dir)
+	}
 	logger.V(0).Infof("Exported logs for cluster %q to:", flags.Name)
 	fmt.Fprintln(streams.Out, dir)
 	return nil
 }
+
+// selectClusters lists the clusters with the labels in selector, or all
+// clusters if selector is empty. Clusters without recorded labels are
+// skipped with a warning, like `kind gc` does
+func selectClusters(logger log.Logger, provider *cluster.Provider, selector string) ([]string, error) {
+	want, err := parseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	clusters, err := provider.List()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list clusters")
+	}
+	if len(want) == 0 {
+		return clusters, nil
+	}
+	selected := []string{}
+	for _, name := range clusters {
+		info, err := provider.ClusterInfo(name)
+		if err != nil {
+			logger.Warnf("skipping cluster %q: %v", name, err)
+			continue
+		}
+		if matchesLabels(info.Labels, want) {
+			selected = append(selected, name)
+		}
+	}
+	return selected, nil
+}
+
+// collectAll collects the logs of clusters into a subdirectory of dir each,
+// at most parallel clusters at a time
+func collectAll(logger log.Logger, provider *cluster.Provider, clusters []string, dir string, opts cluster.CollectLogsOptions, parallel int) error {
+	sem := make(chan struct{}, parallel)
+	fns := make([]func() error, len(clusters))
+	for i, name := range clusters {
+		name := name // capture loop variable
+		fns[i] = func() error {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			clusterDir := filepath.Join(dir, name)
+			if err := provider.CollectLogsWithOptions(context.Background(), name, clusterDir, opts); err != nil {
+				return errors.Wrapf(err, "failed to export logs for cluster %q", name)
+			}
+			logger.V(1).Infof("Exported logs for cluster %q to %s", name, clusterDir)
+			return nil
+		}
+	}
+	return errors.AggregateConcurrent(fns)
+}
+
+// parseSelector parses a comma separated list of key=value label requirements
+func parseSelector(selector string) (map[string]string, error) {
+	labels := map[string]string{}
+	if selector == "" {
+		return labels, nil
+	}
+	for _, requirement := range strings.Split(selector, ",") {
+		parts := strings.SplitN(requirement, "=", 2)
+		key := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || key == "" {
+			return nil, errors.Errorf("invalid --selector %q, expected key=value pairs", selector)
+		}
+		labels[key] = strings.TrimSpace(parts[1])
+	}
+	return labels, nil
+}
+
+// matchesLabels returns true if labels has every label in want
+func matchesLabels(labels, want map[string]string) bool {
+	for k, v := range want {
+		if got, ok := labels[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
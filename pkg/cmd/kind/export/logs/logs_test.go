@@ -0,0 +1,79 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestParseSelector(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name        string
+		Selector    string
+		Expected    map[string]string
+		ExpectError bool
+	}{
+		{
+			Name:     "empty",
+			Selector: "",
+			Expected: map[string]string{},
+		},
+		{
+			Name:     "pairs",
+			Selector: "ci-job=1234, team=net",
+			Expected: map[string]string{"ci-job": "1234", "team": "net"},
+		},
+		{
+			Name:     "empty value",
+			Selector: "team=",
+			Expected: map[string]string{"team": ""},
+		},
+		{
+			Name:        "missing value",
+			Selector:    "team",
+			ExpectError: true,
+		},
+		{
+			Name:        "missing key",
+			Selector:    "=net",
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			labels, err := parseSelector(tc.Selector)
+			assert.ExpectError(t, tc.ExpectError, err)
+			if !tc.ExpectError {
+				assert.DeepEqual(t, tc.Expected, labels)
+			}
+		})
+	}
+}
+
+func TestMatchesLabels(t *testing.T) {
+	t.Parallel()
+	labels := map[string]string{"ci-job": "1234", "team": "net"}
+	assert.BoolEqual(t, true, matchesLabels(labels, map[string]string{"team": "net"}))
+	assert.BoolEqual(t, false, matchesLabels(labels, map[string]string{"team": "storage"}))
+	assert.BoolEqual(t, false, matchesLabels(nil, map[string]string{"team": "net"}))
+	assert.BoolEqual(t, true, matchesLabels(nil, map[string]string{}))
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package supportbundle
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestRedactDir(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kind-supportbundle-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"kubeconfig":        "    client-key-data: LS0tLS1CRUdJTg==\n",
+		"node/kubelet.log":  "nothing secret\n",
+		"node/inspect.json": `"Env": ["GITHUB_TOKEN=abc123"]`,
+	}
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assert.ExpectError(t, false, redactDir(dir))
+	for name, expected := range map[string]string{
+		"kubeconfig":        "    client-key-data: REDACTED\n",
+		"node/kubelet.log":  "nothing secret\n",
+		"node/inspect.json": `"Env": ["GITHUB_TOKEN=REDACTED"]`,
+	} {
+		b, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.StringEqual(t, expected, string(b))
+	}
+}
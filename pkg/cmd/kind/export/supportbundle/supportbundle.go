@@ -0,0 +1,210 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package supportbundle implements the `support-bundle` command
+package supportbundle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/cmd/kind/version"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/fs"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/archive"
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+)
+
+type flagpole struct {
+	Name string
+}
+
+// NewCommand returns a new cobra.Command for exporting a support bundle
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.MaximumNArgs(1),
+		Use:   "support-bundle [output-file]",
+		Short: "Exports a redacted archive of diagnostics for filing bug reports",
+		Long: "Exports a redacted archive of diagnostics for filing bug reports\n\n" +
+			"The archive contains the kind version, host and node provider details,\n" +
+			"the cluster config, node logs and network settings.\n" +
+			"Private keys, tokens and other credentials are redacted.\n\n" +
+			"The archive is written to [output-file] if specified, \"-\" for stdout,\n" +
+			"otherwise to kind-support-bundle-<name>-<timestamp>.tar.gz",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			return runE(logger, streams, flags, args)
+		},
+	}
+	cmd.Flags().StringVar(&flags.Name, "name", cluster.DefaultName, "the cluster context name")
+	return cmd
+}
+
+func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole, args []string) error {
+	provider := cluster.NewProvider(
+		cluster.ProviderWithLogger(logger),
+		runtime.GetDefault(logger),
+		runtime.NamePrefix(),
+	)
+
+	nodes, err := provider.ListNodes(flags.Name)
+	if err != nil {
+		return err
+	}
+	if len(nodes) == 0 {
+		return errors.ClusterNotFound(flags.Name)
+	}
+
+	bundleName := fmt.Sprintf("kind-support-bundle-%s-%s", flags.Name, time.Now().Format("20060102-150405"))
+	output := bundleName + ".tar.gz"
+	if len(args) > 0 {
+		output = args[0]
+	}
+
+	// collect everything under a top level directory named for the bundle
+	tmp, err := fs.TempDir("", "kind-support-bundle-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	dir := filepath.Join(tmp, bundleName)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	// everything is best effort, a partial bundle is more useful than none
+	// so failures are recorded in the bundle instead of aborting
+	var failures []string
+	collect := func(what string, fn func() error) {
+		if err := fn(); err != nil {
+			logger.Warnf("Failed to collect %s: %v", what, err)
+			failures = append(failures, fmt.Sprintf("%s: %v", what, err))
+		}
+	}
+	collect("host info", func() error {
+		return writeHostInfo(dir)
+	})
+	collect("cluster info", func() error {
+		return writeClusterInfo(provider, flags.Name, dir)
+	})
+	collect("logs", func() error {
+		return provider.CollectLogsWithOptions(
+			context.Background(), flags.Name, filepath.Join(dir, "logs"), cluster.CollectLogsOptions{},
+		)
+	})
+	if len(failures) > 0 {
+		if err := writeFile(dir, "errors.txt", strings.Join(failures, "\n")+"\n"); err != nil {
+			return err
+		}
+	}
+
+	if err := redactDir(dir); err != nil {
+		return errors.Wrap(err, "failed to redact support bundle")
+	}
+
+	if output == "-" {
+		return archive.WriteTarGz(streams.Out, tmp)
+	}
+	f, err := os.Create(output)
+	if err != nil {
+		return errors.Wrap(err, "failed to create support bundle")
+	}
+	defer f.Close()
+	if err := archive.WriteTarGz(f, tmp); err != nil {
+		return err
+	}
+	logger.V(0).Infof("Exported support bundle for cluster %q to:", flags.Name)
+	fmt.Fprintln(streams.Out, output)
+	return nil
+}
+
+// writeHostInfo records the kind version and node provider runtime details
+func writeHostInfo(dir string) error {
+	if err := writeFile(dir, "kind-version.txt", version.DisplayVersion()+"\n"); err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+	selection, err := runtime.Select()
+	if err != nil {
+		fmt.Fprintf(&b, "runtime: unknown (%v)\n", err)
+	} else {
+		fmt.Fprintf(&b, "runtime: %s (%s)\n", selection.Runtime, selection.Reason)
+	}
+	for _, name := range cluster.NodeProviderDetectionOrder() {
+		fmt.Fprintf(&b, "%s available: %t\n", name, cluster.IsNodeProviderAvailable(name))
+	}
+	if err := writeFile(dir, "runtime.txt", b.String()); err != nil {
+		return err
+	}
+
+	// the runtime version and info include the host OS, kernel, cgroup driver
+	// storage driver and resources which explain many node level issues
+	if selection.Runtime == "" {
+		return nil
+	}
+	// e.g. docker-vm runs the nodes with the docker CLI
+	binary := runtime.Binary(selection.Runtime)
+	for _, subcommand := range []string{"version", "info"} {
+		out, err := exec.Output(exec.Command(binary, subcommand))
+		if err != nil {
+			return errors.Wrapf(err, "failed to get %s %s", binary, subcommand)
+		}
+		if err := writeFile(dir, binary+"-"+subcommand+".txt", string(out)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeClusterInfo records the locally recorded cluster metadata and config
+func writeClusterInfo(provider *cluster.Provider, name, dir string) error {
+	info, err := provider.ClusterInfo(name)
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFile(dir, "cluster-info.json", string(b)+"\n"); err != nil {
+		return err
+	}
+	if info.Config == "" {
+		return nil
+	}
+	return writeFile(dir, "cluster-config.yaml", info.Config)
+}
+
+func writeFile(dir, name, contents string) error {
+	return ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0644)
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package expose implements the `expose` command
+package expose
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+)
+
+type flagpole struct {
+	Name       string
+	Address    string
+	Port       int32
+	NodePorts  []int32
+	SANs       []string
+	Kubeconfig string
+	Delete     bool
+}
+
+// NewCommand returns a new cobra.Command for exposing a cluster on other host addresses
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "expose",
+		Short: "Republishes a cluster's API server and NodePorts for remote access",
+		Long: `Republishes the API server of a cluster, and any --node-port, on --address through
+a proxy container, then adds the exposed names to the API server certificate
+and prints a kubeconfig for the exposed endpoint.
+
+This is intended for reaching kind on a remote development machine, kind is not
+hardened for exposure to untrusted networks. Use --delete to remove the proxy.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			return runE(logger, streams, flags)
+		},
+	}
+	cmd.Flags().StringVar(&flags.Name, "name", cluster.DefaultName, "the cluster name")
+	cmd.Flags().StringVar(&flags.Address, "address", "0.0.0.0", "the host address to listen on")
+	cmd.Flags().Int32Var(&flags.Port, "port", 0, "the host port for the API server, by default a free port is picked")
+	cmd.Flags().Int32SliceVar(&flags.NodePorts, "node-port", nil, "a NodePort to also publish on the same host port, may be repeated")
+	cmd.Flags().StringSliceVar(&flags.SANs, "san", nil, "a name clients use to reach the host, added to the API server certificate, the first is used in the kubeconfig, may be repeated (default the host's addresses and name)")
+	cmd.Flags().StringVar(&flags.Kubeconfig, "kubeconfig", "", "write the kubeconfig to this file instead of printing it")
+	cmd.Flags().BoolVar(&flags.Delete, "delete", false, "remove the proxy of a previously exposed cluster")
+	return cmd
+}
+
+func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
+	provider := cluster.NewProvider(
+		cluster.ProviderWithLogger(logger),
+		runtime.GetDefault(logger),
+		runtime.NamePrefix(),
+	)
+	if flags.Delete {
+		return provider.Unexpose(flags.Name)
+	}
+	for _, p := range flags.NodePorts {
+		if p < 1 || p > 65535 {
+			return errors.Errorf("invalid --node-port %d", p)
+		}
+	}
+	kubeconfig, err := provider.Expose(flags.Name, cluster.ExposeOptions{
+		Address:   flags.Address,
+		Port:      flags.Port,
+		NodePorts: flags.NodePorts,
+		SANs:      flags.SANs,
+	})
+	if err != nil {
+		return err
+	}
+	if flags.Kubeconfig == "" {
+		fmt.Fprintln(streams.Out, kubeconfig)
+		return nil
+	}
+	if err := ioutil.WriteFile(flags.Kubeconfig, []byte(kubeconfig), 0600); err != nil {
+		return errors.Wrap(err, "failed to write kubeconfig")
+	}
+	logger.V(0).Infof("Wrote the kubeconfig for the exposed cluster to %s", flags.Kubeconfig)
+	return nil
+}
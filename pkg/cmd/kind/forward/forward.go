@@ -0,0 +1,104 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package forward implements the `forward` command
+package forward
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/portforward"
+)
+
+type flagpole struct {
+	Ports        []string
+	SystemdUnits bool
+	UnitName     string
+}
+
+// NewCommand returns a new cobra.Command for forwarding privileged host ports
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "forward",
+		Short: "Forwards privileged host ports for clusters on rootless container runtimes",
+		Long: `Forwards TCP connections from each --port LISTEN=TARGET until interrupted.
+
+Rootless container runtimes cannot publish host ports below
+net.ipv4.ip_unprivileged_port_start, so kind publishes those extraPortMappings
+on loopback ports instead and prints the kind forward command to run for them.
+Listening on the privileged ports needs root, e.g. with sudo.
+
+With --systemd-units the systemd socket and service units running the forward
+are printed instead. The socket unit listens on the ports, so the forwarder
+itself runs unprivileged when the socket activates it.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(logger, streams, flags)
+		},
+	}
+	cmd.Flags().StringArrayVar(&flags.Ports, "port", nil, "a forward as LISTEN_ADDRESS:PORT=TARGET_ADDRESS:PORT, may be repeated")
+	cmd.Flags().BoolVar(&flags.SystemdUnits, "systemd-units", false, "print systemd units running the forwards instead")
+	cmd.Flags().StringVar(&flags.UnitName, "unit-name", "kind-forward", "the name of the systemd units")
+	return cmd
+}
+
+func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
+	if len(flags.Ports) == 0 {
+		return errors.New("at least one --port is required")
+	}
+	forwards := make([]portforward.Forward, 0, len(flags.Ports))
+	for _, p := range flags.Ports {
+		f, err := portforward.Parse(p)
+		if err != nil {
+			return err
+		}
+		forwards = append(forwards, f)
+	}
+
+	if flags.SystemdUnits {
+		executable, err := os.Executable()
+		if err != nil {
+			return errors.Wrap(err, "failed to locate the kind binary")
+		}
+		socket, service := portforward.SystemdUnits(flags.UnitName, executable, forwards)
+		fmt.Fprintf(streams.Out, "# /etc/systemd/system/%s.socket\n%s\n", flags.UnitName, socket)
+		fmt.Fprintf(streams.Out, "# /etc/systemd/system/%s.service\n%s", flags.UnitName, service)
+		return nil
+	}
+
+	// forward until interrupted
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		<-signals
+		cancel()
+	}()
+	return portforward.Run(ctx, logger, forwards)
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gc implements the `gc` command
+package gc
+
+import (
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+)
+
+type flagpole struct {
+	Kubeconfig string
+	DryRun     bool
+}
+
+// NewCommand returns a new cobra.Command for deleting expired clusters
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "gc",
+		Short: "Deletes expired clusters",
+		Long: `Deletes the clusters created with a --ttl that has passed.
+
+The expiration is recorded in the local cluster state of the user that created
+the cluster, so this only deletes clusters created by the same user. Run it
+periodically, e.g. from cron, to keep shared hosts clean.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(logger, flags)
+		},
+	}
+	cmd.Flags().StringVar(&flags.Kubeconfig, "kubeconfig", "", "sets kubeconfig path instead of $KUBECONFIG or $HOME/.kube/config")
+	cmd.Flags().BoolVar(&flags.DryRun, "dry-run", false, "only print the expired clusters, without deleting them")
+	return cmd
+}
+
+func runE(logger log.Logger, flags *flagpole) error {
+	provider := cluster.NewProvider(
+		cluster.ProviderWithLogger(logger),
+		runtime.GetDefault(logger),
+		runtime.NamePrefix(),
+	)
+	clusters, err := provider.List()
+	if err != nil {
+		return errors.Wrap(err, "failed to list clusters")
+	}
+	infos := []*cluster.ClusterInfo{}
+	for _, name := range clusters {
+		info, err := provider.ClusterInfo(name)
+		if err != nil {
+			logger.Warnf("skipping cluster %q: %v", name, err)
+			continue
+		}
+		infos = append(infos, info)
+	}
+
+	toDelete := expired(infos, time.Now())
+	if len(toDelete) == 0 {
+		logger.V(0).Info("No expired clusters")
+		return nil
+	}
+	errs := []error{}
+	for _, info := range toDelete {
+		expiredFor := time.Since(info.ExpirationTimestamp).Round(time.Second)
+		if flags.DryRun {
+			logger.V(0).Infof("Would delete cluster %q, expired %s ago", info.Name, expiredFor)
+			continue
+		}
+		logger.V(0).Infof("Deleting cluster %q, expired %s ago ...", info.Name, expiredFor)
+		if err := provider.Delete(info.Name, flags.Kubeconfig); err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to delete cluster %q", info.Name))
+		}
+	}
+	return errors.NewAggregate(errs)
+}
+
+// expired returns the clusters of infos that have expired by now, the
+// longest expired first
+func expired(infos []*cluster.ClusterInfo, now time.Time) []*cluster.ClusterInfo {
+	out := []*cluster.ClusterInfo{}
+	for _, info := range infos {
+		if !info.ExpirationTimestamp.IsZero() && now.After(info.ExpirationTimestamp) {
+			out = append(out, info)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].ExpirationTimestamp.Before(out[j].ExpirationTimestamp)
+	})
+	return out
+}
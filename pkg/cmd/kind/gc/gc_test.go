@@ -0,0 +1,41 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gc
+
+import (
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestExpired(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	infos := []*cluster.ClusterInfo{
+		{Name: "no-ttl"},
+		{Name: "recent", ExpirationTimestamp: now.Add(-time.Minute)},
+		{Name: "alive", ExpirationTimestamp: now.Add(time.Hour)},
+		{Name: "old", ExpirationTimestamp: now.Add(-24 * time.Hour)},
+	}
+	names := []string{}
+	for _, info := range expired(infos, now) {
+		names = append(names, info.Name)
+	}
+	assert.DeepEqual(t, []string{"old", "recent"}, names)
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package artifacts implements the `artifacts` command
+package artifacts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+)
+
+type flagpole struct {
+	Name      string
+	Image     string
+	Node      string
+	OutputDir string
+}
+
+// NewCommand returns a new cobra.Command for getting files from a node image
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.ArbitraryArgs,
+		Use:   "artifacts [" + strings.Join(artifactNames(), "|") + "]...",
+		Short: "Extracts the Kubernetes binaries or containerd config from a node image or node",
+		Long: "Extracts the kubectl, kubeadm and kubelet binaries or the containerd config\n" +
+			"from a node image with --image, or from a node of a running cluster.\n\n" +
+			"A single artifact may be written to stdout, otherwise --output-dir is required.\n" +
+			"With no arguments every artifact is extracted.",
+		Example: "  kind get artifacts kubectl --name foo --output-dir ./bin\n" +
+			"  kind get artifacts containerd-config --image kindest/node:v1.31.0",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			return runE(logger, streams, flags, args)
+		},
+	}
+	cmd.Flags().StringVar(
+		&flags.Name,
+		"name",
+		cluster.DefaultName,
+		"the cluster context name",
+	)
+	cmd.Flags().StringVar(
+		&flags.Image,
+		"image",
+		"",
+		"extract from this node image instead of a node of the cluster",
+	)
+	cmd.Flags().StringVar(
+		&flags.Node,
+		"node",
+		"",
+		"the node to extract from, defaults to the first control plane",
+	)
+	cmd.Flags().StringVar(
+		&flags.OutputDir,
+		"output-dir",
+		"",
+		"write the artifacts to files in this directory instead of stdout",
+	)
+	return cmd
+}
+
+func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole, args []string) error {
+	if flags.Image != "" && flags.Node != "" {
+		return errors.New("only one of --image and --node may be set")
+	}
+	if len(args) == 0 {
+		args = artifactNames()
+	}
+	if flags.OutputDir == "" && len(args) > 1 {
+		return errors.New("--output-dir is required to extract more than one artifact")
+	}
+	selected := []cluster.Artifact{}
+	for _, name := range args {
+		a, err := cluster.LookupArtifact(name)
+		if err != nil {
+			return err
+		}
+		selected = append(selected, a)
+	}
+
+	provider := cluster.NewProvider(
+		cluster.ProviderWithLogger(logger),
+		runtime.GetDefault(logger),
+		runtime.NamePrefix(),
+	)
+	src := cluster.ArtifactSource{Image: flags.Image, Node: flags.Node}
+	if flags.OutputDir == "" {
+		return provider.GetArtifact(flags.Name, src, selected[0].Name, streams.Out)
+	}
+	if err := os.MkdirAll(flags.OutputDir, 0755); err != nil {
+		return errors.Wrap(err, "failed to create --output-dir")
+	}
+	for _, a := range selected {
+		path := filepath.Join(flags.OutputDir, a.File)
+		if err := writeArtifact(provider, flags.Name, src, a, path); err != nil {
+			return err
+		}
+		logger.V(0).Infof("Wrote %s to %s", a.Name, path)
+	}
+	return nil
+}
+
+// writeArtifact writes the artifact a from src to path, removing path if
+// that fails part way
+func writeArtifact(provider *cluster.Provider, name string, src cluster.ArtifactSource, a cluster.Artifact, path string) error {
+	var mode os.FileMode = 0644
+	if a.Executable {
+		mode = 0755
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %s", path)
+	}
+	err = provider.GetArtifact(name, src, a.Name, f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+	return nil
+}
+
+func artifactNames() []string {
+	names := []string{}
+	for _, a := range cluster.Artifacts() {
+		names = append(names, a.Name)
+	}
+	return names
+}
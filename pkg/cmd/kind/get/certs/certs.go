@@ -0,0 +1,144 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package certs implements the `certs` command
+package certs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+)
+
+type flagpole struct {
+	Name        string
+	CA          bool
+	AdminClient bool
+	OutputDir   string
+}
+
+// NewCommand returns a new cobra.Command for getting the cluster certificates
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "certs",
+		Short: "Prints the cluster CA and admin client certificates",
+		Long: "Prints the cluster CA certificate and the admin client certificate and key in PEM format, " +
+			"or writes them to ca.crt, admin.crt and admin.key in --output-dir",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			return runE(logger, streams, flags)
+		},
+	}
+	cmd.Flags().StringVar(
+		&flags.Name,
+		"name",
+		cluster.DefaultName,
+		"the cluster context name",
+	)
+	cmd.Flags().BoolVar(
+		&flags.CA,
+		"ca",
+		false,
+		"only get the cluster CA certificate",
+	)
+	cmd.Flags().BoolVar(
+		&flags.AdminClient,
+		"admin-client",
+		false,
+		"only get the admin client certificate and key",
+	)
+	cmd.Flags().StringVar(
+		&flags.OutputDir,
+		"output-dir",
+		"",
+		"write the certificates to files in this directory instead of stdout",
+	)
+	return cmd
+}
+
+func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
+	provider := cluster.NewProvider(
+		cluster.ProviderWithLogger(logger),
+		runtime.GetDefault(logger),
+		runtime.NamePrefix(),
+	)
+	cfg, err := provider.KubeConfigObject(flags.Name, false)
+	if err != nil {
+		return err
+	}
+	selected := certFiles(cfg, flags.CA, flags.AdminClient)
+	for _, f := range selected {
+		if len(f.Data) == 0 {
+			return errors.Errorf("the kubeconfig for cluster %q has no %s", flags.Name, f.Description)
+		}
+	}
+	if flags.OutputDir == "" {
+		for _, f := range selected {
+			if _, err := streams.Out.Write(f.Data); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := os.MkdirAll(flags.OutputDir, 0755); err != nil {
+		return errors.Wrap(err, "failed to create --output-dir")
+	}
+	for _, f := range selected {
+		path := filepath.Join(flags.OutputDir, f.Name)
+		if err := ioutil.WriteFile(path, f.Data, f.Mode); err != nil {
+			return errors.Wrapf(err, "failed to write %s", path)
+		}
+		logger.V(0).Infof("Wrote the %s to %s", f.Description, path)
+	}
+	return nil
+}
+
+// certFile is one of the files written by the certs command
+type certFile struct {
+	Name        string
+	Description string
+	Data        []byte
+	Mode        os.FileMode
+}
+
+// certFiles returns the files to write for the selection flags,
+// selecting neither ca nor adminClient selects both
+func certFiles(cfg *cluster.KubeConfigObject, ca, adminClient bool) []certFile {
+	files := []certFile{}
+	if ca || !adminClient {
+		files = append(files, certFile{"ca.crt", "CA certificate", cfg.CertificateAuthorityData, 0644})
+	}
+	if adminClient || !ca {
+		files = append(files,
+			certFile{"admin.crt", "admin client certificate", cfg.ClientCertificateData, 0644},
+			// the key grants cluster-admin, only the user may read it
+			certFile{"admin.key", "admin client key", cfg.ClientKeyData, 0600},
+		)
+	}
+	return files
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certs
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestCertFiles(t *testing.T) {
+	t.Parallel()
+	cfg := &cluster.KubeConfigObject{
+		CertificateAuthorityData: []byte("ca"),
+		ClientCertificateData:    []byte("cert"),
+		ClientKeyData:            []byte("key"),
+	}
+	cases := []struct {
+		Name        string
+		CA          bool
+		AdminClient bool
+		Expected    []string
+	}{
+		{
+			Name:     "all by default",
+			Expected: []string{"ca.crt", "admin.crt", "admin.key"},
+		},
+		{
+			Name:     "only the ca",
+			CA:       true,
+			Expected: []string{"ca.crt"},
+		},
+		{
+			Name:        "only the admin client",
+			AdminClient: true,
+			Expected:    []string{"admin.crt", "admin.key"},
+		},
+		{
+			Name:        "both",
+			CA:          true,
+			AdminClient: true,
+			Expected:    []string{"ca.crt", "admin.crt", "admin.key"},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			names := []string{}
+			for _, f := range certFiles(cfg, tc.CA, tc.AdminClient) {
+				names = append(names, f.Name)
+				if f.Name == "admin.key" && f.Mode != 0600 {
+					t.Errorf("expected admin.key to only be readable by the user, got mode %v", f.Mode)
+				}
+			}
+			assert.DeepEqual(t, tc.Expected, names)
+		})
+	}
+}
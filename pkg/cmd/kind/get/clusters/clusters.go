@@ -48,6 +48,7 @@ func runE(logger log.Logger, streams cmd.IOStreams) error {
 	provider := cluster.NewProvider(
 		cluster.ProviderWithLogger(logger),
 		runtime.GetDefault(logger),
+		runtime.NamePrefix(),
 	)
 	clusters, err := provider.List()
 	if err != nil {
@@ -0,0 +1,45 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package du
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestFormatBytes(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Bytes    int64
+		Expected string
+	}{
+		{Bytes: 0, Expected: "0B"},
+		{Bytes: 1023, Expected: "1023B"},
+		{Bytes: 1536, Expected: "1.5KiB"},
+		{Bytes: 300 << 20, Expected: "300.0MiB"},
+		{Bytes: 10 << 30, Expected: "10.0GiB"},
+		{Bytes: 2048 << 40, Expected: "2048.0TiB"},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Expected, func(t *testing.T) {
+			t.Parallel()
+			assert.StringEqual(t, tc.Expected, formatBytes(tc.Bytes))
+		})
+	}
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package events implements the `events` command
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+)
+
+type flagpole struct {
+	Name   string
+	Since  time.Duration
+	Watch  bool
+	Output string
+}
+
+// NewCommand returns a new cobra.Command for getting the node container events of a cluster
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "events",
+		Short: "Prints the container lifecycle events of the nodes of a cluster",
+		Long: `Prints the container lifecycle events of the nodes of a cluster, such as nodes
+being created, started, OOM killed or dying, with their timestamps.
+
+With --watch new events are printed until interrupted. The container runtime
+only keeps recent events.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			return runE(logger, streams, flags)
+		},
+	}
+	cmd.Flags().StringVar(&flags.Name, "name", cluster.DefaultName, "the cluster context name")
+	cmd.Flags().DurationVar(&flags.Since, "since", 0, "only print events newer than this, e.g. 1h, 0 for all events")
+	cmd.Flags().BoolVarP(&flags.Watch, "watch", "w", false, "keep printing new events until interrupted")
+	cmd.Flags().StringVarP(&flags.Output, "output", "o", "text", "output format, one of: text, json")
+	return cmd
+}
+
+func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
+	switch flags.Output {
+	case "text", "json":
+	default:
+		return errors.Errorf("unknown --output %q, expected one of: text, json", flags.Output)
+	}
+	if flags.Since < 0 {
+		return errors.New("--since must not be negative")
+	}
+	provider := cluster.NewProvider(
+		cluster.ProviderWithLogger(logger),
+		runtime.GetDefault(logger),
+		runtime.NamePrefix(),
+	)
+	opts := cluster.NodeEventsOptions{Follow: flags.Watch}
+	if flags.Since > 0 {
+		opts.Since = time.Now().Add(-flags.Since)
+	}
+
+	// stop watching on interrupt
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		<-signals
+		cancel()
+	}()
+
+	printEvent := printText
+	if flags.Output == "json" {
+		printEvent = printJSON
+	}
+	return provider.NodeEventsContext(ctx, flags.Name, opts, func(e cluster.NodeEvent) {
+		printEvent(streams.Out, e)
+	})
+}
+
+// printText prints e on one line, events are printed as they are received
+// so they are not aligned in a table
+func printText(w io.Writer, e cluster.NodeEvent) {
+	line := fmt.Sprintf("%s  %s  %s", e.Time.Format(time.RFC3339Nano), e.Node, e.Action)
+	if details := eventDetails(e); details != "" {
+		line += "  " + details
+	}
+	fmt.Fprintln(w, line)
+}
+
+// printJSON prints e as a JSON object on one line
+func printJSON(w io.Writer, e cluster.NodeEvent) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	fmt.Fprintln(w, string(b))
+}
+
+// eventDetails explains the events that usually cause flaky clusters
+func eventDetails(e cluster.NodeEvent) string {
+	switch e.Action {
+	case "oom":
+		return "the node ran out of memory"
+	case "die":
+		switch e.ExitCode {
+		case "":
+			return ""
+		case "137":
+			return "exit code 137, the node was killed, e.g. by the OOM killer or docker kill"
+		}
+		return "exit code " + e.ExitCode
+	}
+	return ""
+}
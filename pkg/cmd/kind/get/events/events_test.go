@@ -0,0 +1,66 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestPrintText(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name     string
+		Event    cluster.NodeEvent
+		Expected string
+	}{
+		{
+			Name:     "start",
+			Event:    cluster.NodeEvent{Node: "kind-worker", Action: "start"},
+			Expected: "2020-10-14T10:00:00.5Z  kind-worker  start\n",
+		},
+		{
+			Name:     "oom",
+			Event:    cluster.NodeEvent{Node: "kind-worker", Action: "oom"},
+			Expected: "2020-10-14T10:00:00.5Z  kind-worker  oom  the node ran out of memory\n",
+		},
+		{
+			Name:     "killed",
+			Event:    cluster.NodeEvent{Node: "kind-worker", Action: "die", ExitCode: "137"},
+			Expected: "2020-10-14T10:00:00.5Z  kind-worker  die  exit code 137, the node was killed, e.g. by the OOM killer or docker kill\n",
+		},
+		{
+			Name:     "exited",
+			Event:    cluster.NodeEvent{Node: "kind-worker", Action: "die", ExitCode: "0"},
+			Expected: "2020-10-14T10:00:00.5Z  kind-worker  die  exit code 0\n",
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			tc.Event.Time = time.Date(2020, 10, 14, 10, 0, 0, 5e8, time.UTC)
+			var buff bytes.Buffer
+			printText(&buff, tc.Event)
+			assert.StringEqual(t, tc.Expected, buff.String())
+		})
+	}
+}
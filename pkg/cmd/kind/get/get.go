@@ -21,9 +21,17 @@ import (
 	"github.com/spf13/cobra"
 
 	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/cmd/kind/get/artifacts"
+	"sigs.k8s.io/kind/pkg/cmd/kind/get/certs"
 	"sigs.k8s.io/kind/pkg/cmd/kind/get/clusters"
+	"sigs.k8s.io/kind/pkg/cmd/kind/get/du"
+	"sigs.k8s.io/kind/pkg/cmd/kind/get/endpoints"
+	"sigs.k8s.io/kind/pkg/cmd/kind/get/events"
+	"sigs.k8s.io/kind/pkg/cmd/kind/get/joincommand"
 	"sigs.k8s.io/kind/pkg/cmd/kind/get/kubeconfig"
+	"sigs.k8s.io/kind/pkg/cmd/kind/get/network"
 	"sigs.k8s.io/kind/pkg/cmd/kind/get/nodes"
+	"sigs.k8s.io/kind/pkg/cmd/kind/get/requiredimages"
 	"sigs.k8s.io/kind/pkg/log"
 )
 
@@ -33,12 +41,20 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 		Args: cobra.NoArgs,
 		// TODO(bentheelder): more detailed usage
 		Use:   "get",
-		Short: "Gets one of [clusters, nodes, kubeconfig]",
-		Long:  "Gets one of [clusters, nodes, kubeconfig]",
+		Short: "Gets one of [clusters, nodes, kubeconfig, endpoints, required-images, join-command, du, events, certs, network, artifacts]",
+		Long:  "Gets one of [clusters, nodes, kubeconfig, endpoints, required-images, join-command, du, events, certs, network, artifacts]",
 	}
 	// add subcommands
 	cmd.AddCommand(clusters.NewCommand(logger, streams))
 	cmd.AddCommand(nodes.NewCommand(logger, streams))
 	cmd.AddCommand(kubeconfig.NewCommand(logger, streams))
+	cmd.AddCommand(endpoints.NewCommand(logger, streams))
+	cmd.AddCommand(requiredimages.NewCommand(logger, streams))
+	cmd.AddCommand(joincommand.NewCommand(logger, streams))
+	cmd.AddCommand(du.NewCommand(logger, streams))
+	cmd.AddCommand(events.NewCommand(logger, streams))
+	cmd.AddCommand(certs.NewCommand(logger, streams))
+	cmd.AddCommand(network.NewCommand(logger, streams))
+	cmd.AddCommand(artifacts.NewCommand(logger, streams))
 	return cmd
 }
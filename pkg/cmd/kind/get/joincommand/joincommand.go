@@ -0,0 +1,98 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package joincommand implements the `join-command` command
+package joincommand
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+)
+
+type flagpole struct {
+	Name         string
+	ControlPlane bool
+	TTL          time.Duration
+	Endpoint     string
+}
+
+// NewCommand returns a new cobra.Command for getting a kubeadm join command
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "join-command",
+		Short: "Prints a kubeadm join command with a fresh bootstrap token for a cluster",
+		Long: "Creates a fresh bootstrap token on the control plane, and a certificate key with --control-plane,\n" +
+			"and prints the kubeadm join command for adding machines or containers not created by kind to the cluster",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			return runE(logger, streams, flags)
+		},
+	}
+	cmd.Flags().StringVar(
+		&flags.Name,
+		"name",
+		cluster.DefaultName,
+		"the cluster context name",
+	)
+	cmd.Flags().BoolVar(
+		&flags.ControlPlane,
+		"control-plane",
+		false,
+		"join as an additional control plane node, the certificate key expires after 2h",
+	)
+	cmd.Flags().DurationVar(
+		&flags.TTL,
+		"ttl",
+		0,
+		"lifetime of the bootstrap token, 0 for kubeadm's default of 24h",
+	)
+	cmd.Flags().StringVar(
+		&flags.Endpoint,
+		"endpoint",
+		"",
+		"API server host:port to join instead of the one on the cluster's network, e.g. from kind expose",
+	)
+	return cmd
+}
+
+func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
+	provider := cluster.NewProvider(
+		cluster.ProviderWithLogger(logger),
+		runtime.GetDefault(logger),
+		runtime.NamePrefix(),
+	)
+	command, err := provider.JoinCommand(flags.Name, cluster.JoinCommandOptions{
+		ControlPlane: flags.ControlPlane,
+		TTL:          flags.TTL,
+		Endpoint:     flags.Endpoint,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(streams.Out, command)
+	return nil
+}
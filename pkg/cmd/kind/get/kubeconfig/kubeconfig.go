@@ -24,6 +24,7 @@ import (
 
 	"sigs.k8s.io/kind/pkg/cluster"
 	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/log"
 
 	"sigs.k8s.io/kind/pkg/internal/cli"
@@ -31,8 +32,9 @@ import (
 )
 
 type flagpole struct {
-	Name     string
-	Internal bool
+	Name         string
+	Internal     bool
+	LoadBalancer bool
 }
 
 // NewCommand returns a new cobra.Command for getting the kubeconfig
@@ -60,6 +62,12 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 		false,
 		"use internal address instead of external",
 	)
+	cmd.Flags().BoolVar(
+		&flags.LoadBalancer,
+		"load-balancer",
+		false,
+		"with --internal, target the control plane load balancer of a multi control plane cluster",
+	)
 	return cmd
 }
 
@@ -67,8 +75,18 @@ func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
 	provider := cluster.NewProvider(
 		cluster.ProviderWithLogger(logger),
 		runtime.GetDefault(logger),
+		runtime.NamePrefix(),
 	)
-	cfg, err := provider.KubeConfig(flags.Name, flags.Internal)
+	var cfg string
+	var err error
+	if flags.LoadBalancer {
+		if !flags.Internal {
+			return errors.New("--load-balancer requires --internal")
+		}
+		cfg, err = provider.KubeConfigInternalLoadBalancer(flags.Name)
+	} else {
+		cfg, err = provider.KubeConfig(flags.Name, flags.Internal)
+	}
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,106 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package network implements the `network` command
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+)
+
+type flagpole struct {
+	Name   string
+	Output string
+}
+
+// NewCommand returns a new cobra.Command for getting the container network of a cluster
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "network",
+		Short: "Prints the container network of a cluster",
+		Long: `Prints the container network the nodes of a cluster are attached to: its name,
+IPv4 and IPv6 subnets and gateways, the MTU of the nodes' network interface and
+the address of each node, e.g. for configuring MetalLB address pools or routes.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			return runE(logger, streams, flags)
+		},
+	}
+	cmd.Flags().StringVar(&flags.Name, "name", cluster.DefaultName, "the cluster context name")
+	cmd.Flags().StringVarP(&flags.Output, "output", "o", "text", "output format, one of: text, json")
+	return cmd
+}
+
+func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
+	switch flags.Output {
+	case "text", "json":
+	default:
+		return errors.Errorf("unknown --output %q, expected one of: text, json", flags.Output)
+	}
+	provider := cluster.NewProvider(
+		cluster.ProviderWithLogger(logger),
+		runtime.GetDefault(logger),
+		runtime.NamePrefix(),
+	)
+	info, err := provider.NetworkInfo(flags.Name)
+	if err != nil {
+		return err
+	}
+	if flags.Output == "json" {
+		b, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "failed to encode network info")
+		}
+		fmt.Fprintln(streams.Out, string(b))
+		return nil
+	}
+	return printText(streams.Out, info)
+}
+
+// printText prints info as tables of the subnets and nodes
+func printText(out io.Writer, info *cluster.NetworkInfo) error {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintf(w, "Network:\t%s\n", info.Name)
+	if info.MTU != 0 {
+		fmt.Fprintf(w, "MTU:\t%d\n", info.MTU)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "SUBNET\tGATEWAY")
+	for _, s := range info.Subnets {
+		fmt.Fprintf(w, "%s\t%s\n", s.Subnet, s.Gateway)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "NODE\tIPV4\tIPV6")
+	for _, n := range info.Nodes {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", n.Name, n.IPv4, n.IPv6)
+	}
+	return w.Flush()
+}
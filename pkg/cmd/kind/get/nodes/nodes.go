@@ -61,6 +61,7 @@ func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
 	provider := cluster.NewProvider(
 		cluster.ProviderWithLogger(logger),
 		runtime.GetDefault(logger),
+		runtime.NamePrefix(),
 	)
 	n, err := provider.ListNodes(flags.Name)
 	if err != nil {
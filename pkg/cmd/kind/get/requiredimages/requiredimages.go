@@ -0,0 +1,120 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package requiredimages implements the `required-images` command
+package requiredimages
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+)
+
+type flagpole struct {
+	Config     string
+	ImageName  string
+	K8sVersion string
+	Presets    []string
+	Output     string
+}
+
+// NewCommand returns a new cobra.Command for listing the images a cluster needs
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "required-images",
+		Short: "Lists every image creating a cluster would use",
+		Long: "Lists every image creating a cluster with the same flags would use, " +
+			"including the node image, the images preloaded for kubeadm, the CNI and the storage provisioner, " +
+			"so they can be mirrored before creating clusters with --offline.\n" +
+			"The node image is pulled if it is not present.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(logger, streams, flags)
+		},
+	}
+	cmd.Flags().StringVar(&flags.Config, "config", "", "path to a kind config file")
+	cmd.Flags().StringVar(&flags.ImageName, "image", "", "node docker image to use for booting the cluster")
+	cmd.Flags().StringVar(&flags.K8sVersion, "k8s-version", "", "Kubernetes version, e.g. v1.18.8, selects the kindest/node image of that version")
+	cmd.Flags().StringSliceVar(&flags.Presets, "preset", nil, "optional presets to install, may be repeated, one of: observability, node-problem-detector")
+	cmd.Flags().StringVarP(&flags.Output, "output", "o", "text", "output format, one of: text, json")
+	return cmd
+}
+
+// versionRE matches the Kubernetes versions kindest/node images are tagged with
+var versionRE = regexp.MustCompile(`^v\d+\.\d+\.\d+$`)
+
+func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
+	switch flags.Output {
+	case "text", "json":
+	default:
+		return errors.Errorf("unknown --output %q, expected one of: text, json", flags.Output)
+	}
+	image := flags.ImageName
+	if flags.K8sVersion != "" {
+		if image != "" {
+			return errors.New("only one of --image and --k8s-version may be set")
+		}
+		if !versionRE.MatchString(flags.K8sVersion) {
+			return errors.Errorf("invalid --k8s-version %q, expected a version like v1.18.8", flags.K8sVersion)
+		}
+		image = "kindest/node:" + flags.K8sVersion
+	}
+	presets := make([]v1alpha4.Preset, 0, len(flags.Presets))
+	for _, p := range flags.Presets {
+		presets = append(presets, v1alpha4.Preset(p))
+	}
+
+	provider := cluster.NewProvider(
+		cluster.ProviderWithLogger(logger),
+		runtime.GetDefault(logger),
+		runtime.NamePrefix(),
+	)
+	images, err := provider.RequiredImages(
+		cluster.CreateWithConfigFile(flags.Config),
+		cluster.CreateWithNodeImage(image),
+		cluster.CreateWithPresets(presets...),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to list required images")
+	}
+
+	if flags.Output == "json" {
+		b, err := json.MarshalIndent(images, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(streams.Out, string(b))
+		return err
+	}
+	w := tabwriter.NewWriter(streams.Out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "IMAGE\tSOURCE")
+	for _, i := range images {
+		fmt.Fprintf(w, "%s\t%s\n", i.Image, i.Source)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package heal implements the `heal` command
+package heal
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+)
+
+type flagpole struct {
+	Name     string
+	Watch    bool
+	Interval time.Duration
+}
+
+// NewCommand returns a new cobra.Command for restarting exited nodes
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "heal",
+		Short: "Restarts exited node containers of a cluster",
+		Long: `Restarts the node containers of a cluster that have exited, waits for them to boot
+and reconfigures the load balancer for any new node addresses.
+
+With --watch the nodes are checked every --interval until interrupted.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			return runE(logger, flags)
+		},
+	}
+	cmd.Flags().StringVar(&flags.Name, "name", cluster.DefaultName, "the cluster name")
+	cmd.Flags().BoolVar(&flags.Watch, "watch", false, "keep running and heal the cluster whenever nodes exit")
+	cmd.Flags().DurationVar(&flags.Interval, "interval", 30*time.Second, "how often to check the nodes with --watch")
+	return cmd
+}
+
+func runE(logger log.Logger, flags *flagpole) error {
+	if flags.Watch && flags.Interval <= 0 {
+		return errors.New("--interval must be positive")
+	}
+	provider := cluster.NewProvider(
+		cluster.ProviderWithLogger(logger),
+		runtime.GetDefault(logger),
+		runtime.NamePrefix(),
+	)
+	if !flags.Watch {
+		return heal(context.Background(), logger, provider, flags.Name)
+	}
+
+	// stop watching on interrupt, aborting any in progress healing
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		<-signals
+		cancel()
+	}()
+
+	logger.V(0).Infof("Watching the nodes of cluster %q, press Ctrl+C to stop", flags.Name)
+	ticker := time.NewTicker(flags.Interval)
+	defer ticker.Stop()
+	for {
+		// keep watching after failures, e.g. while the runtime is restarting
+		if err := heal(ctx, logger, provider, flags.Name); err != nil && ctx.Err() == nil {
+			logger.Errorf("failed to heal cluster %q: %v", flags.Name, err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func heal(ctx context.Context, logger log.Logger, provider *cluster.Provider, name string) error {
+	restarted, err := provider.HealContext(ctx, name)
+	if err != nil {
+		return err
+	}
+	if len(restarted) == 0 {
+		logger.V(1).Infof("All nodes of cluster %q are running", name)
+		return nil
+	}
+	for _, n := range restarted {
+		logger.V(0).Infof("Restarted node %s", n.String())
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package inspect implements the `inspect` command
+package inspect
+
+import (
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+)
+
+type flagpole struct {
+	Name   string
+	Output string
+}
+
+// NewCommand returns a new cobra.Command for inspecting a cluster
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "inspect",
+		Short: "Prints the resolved configuration of a cluster",
+		Long: "Prints everything kind resolved for a running cluster: the fully defaulted cluster config, " +
+			"the kubeadm and containerd configs generated on each node, the load balancer config " +
+			"and the node provider's container specs.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			return runE(logger, streams, flags)
+		},
+	}
+	cmd.Flags().StringVar(
+		&flags.Name,
+		"name",
+		cluster.DefaultName,
+		"the cluster context name",
+	)
+	cmd.Flags().StringVarP(
+		&flags.Output,
+		"output",
+		"o",
+		string(cluster.InspectFormatYAML),
+		"output format, one of: yaml, json",
+	)
+	return cmd
+}
+
+func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
+	format := cluster.InspectFormat(flags.Output)
+	switch format {
+	case cluster.InspectFormatYAML, cluster.InspectFormatJSON:
+	default:
+		return errors.Errorf("unknown --output %q, expected one of: yaml, json", flags.Output)
+	}
+	provider := cluster.NewProvider(
+		cluster.ProviderWithLogger(logger),
+		runtime.GetDefault(logger),
+		runtime.NamePrefix(),
+	)
+	out, err := provider.Inspect(flags.Name, format)
+	if err != nil {
+		return err
+	}
+	_, err = streams.Out.Write([]byte(out))
+	return err
+}
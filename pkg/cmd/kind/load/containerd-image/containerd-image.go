@@ -0,0 +1,141 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package load implements the `load containerd-image` command
+package load
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/internal/imageload"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+)
+
+type flagpole struct {
+	Name      string
+	Nodes     []string
+	Namespace string
+}
+
+// NewCommand returns a new cobra.Command for loading images from the local
+// containerd image store into a cluster
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return errors.New("name of image is required")
+			}
+			return nil
+		},
+		Use:   "containerd-image <IMAGE> [IMAGE...]",
+		Short: "Loads images from the containerd image store into nodes",
+		Long:  "Loads images from the local containerd image store into all or specified nodes by name, streaming them without writing an archive to disk",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			return runE(logger, flags, args)
+		},
+	}
+	cmd.Flags().StringVar(
+		&flags.Name,
+		"name",
+		cluster.DefaultName,
+		"the cluster context name",
+	)
+	cmd.Flags().StringSliceVar(
+		&flags.Nodes,
+		"nodes",
+		nil,
+		"comma separated list of nodes to load images into",
+	)
+	cmd.Flags().StringVar(
+		&flags.Namespace,
+		"namespace",
+		"default",
+		"the containerd namespace of the images, nerdctl uses default",
+	)
+	return cmd
+}
+
+func runE(logger log.Logger, flags *flagpole, args []string) error {
+	provider := cluster.NewProvider(
+		cluster.ProviderWithLogger(logger),
+		runtime.GetDefault(logger),
+		runtime.NamePrefix(),
+	)
+
+	// containerd only knows images by their fully qualified name
+	images := make([]string, len(args))
+	for i, arg := range args {
+		images[i] = normalizeImage(arg)
+		present, err := imagePresent(flags.Namespace, images[i])
+		if err != nil {
+			return errors.Wrap(err, "failed to list containerd images")
+		}
+		if !present {
+			return fmt.Errorf("image: %q not present locally in containerd namespace %q", arg, flags.Namespace)
+		}
+	}
+
+	selectedNodes, err := imageload.SelectNodes(provider, flags.Name, flags.Nodes)
+	if err != nil {
+		return err
+	}
+
+	// NOTE: containerd does not list image IDs as they are known to the
+	// nodes, so images are always loaded, importing skips existing content
+	logger.V(0).Infof("Loading images %q into %d node(s) ...", images, len(selectedNodes))
+	args = append([]string{"--namespace", flags.Namespace, "images", "export", "-"}, images...)
+	return imageload.Stream(exec.Command("ctr", args...), selectedNodes)
+}
+
+// imagePresent returns true if image is in the containerd namespace
+func imagePresent(namespace, image string) (bool, error) {
+	cmd := exec.Command("ctr", "--namespace", namespace,
+		"images", "list", "--quiet", "name=="+image,
+	)
+	lines, err := exec.OutputLines(cmd)
+	if err != nil {
+		return false, err
+	}
+	return len(lines) > 0, nil
+}
+
+// normalizeImage returns the fully qualified form of image as listed by
+// containerd, e.g. "kindest/kindnetd:v1" becomes "docker.io/kindest/kindnetd:v1"
+func normalizeImage(image string) string {
+	if name := image[strings.LastIndex(image, "/")+1:]; !strings.ContainsAny(name, ":@") {
+		image += ":latest"
+	}
+	parts := strings.SplitN(image, "/", 2)
+	if len(parts) == 1 {
+		return "docker.io/library/" + image
+	}
+	if !strings.ContainsAny(parts[0], ".:") && parts[0] != "localhost" {
+		return "docker.io/" + image
+	}
+	return image
+}
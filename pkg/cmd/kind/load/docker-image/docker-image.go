@@ -79,6 +79,7 @@ func runE(logger log.Logger, flags *flagpole, args []string) error {
 	provider := cluster.NewProvider(
 		cluster.ProviderWithLogger(logger),
 		runtime.GetDefault(logger),
+		runtime.NamePrefix(),
 	)
 
 	// Check that the image exists locally and gets its ID, if not return error
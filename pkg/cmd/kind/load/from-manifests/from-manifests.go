@@ -0,0 +1,239 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package load implements the `load from-manifests` command
+package load
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	yaml "gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/internal/imageload"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+)
+
+type flagpole struct {
+	Name  string
+	Nodes []string
+}
+
+// NewCommand returns a new cobra.Command for loading the images used by
+// Kubernetes manifests into a cluster
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return errors.New("a manifest file or directory is required, use - to read from stdin")
+			}
+			return nil
+		},
+		Use:   "from-manifests <FILE|DIR|->...",
+		Short: "Loads the images used by Kubernetes manifests from host into nodes",
+		Long: "Loads the images referenced by the containers in Kubernetes manifests from the image store of the selected container runtime into all or specified nodes by name.\n" +
+			"Directories are searched for .yaml, .yml and .json files, - reads manifests from stdin, e.g. rendered with `helm template`.\n" +
+			"Images that are not present on the host are listed instead of loaded.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			return runE(logger, streams, flags, args)
+		},
+	}
+	cmd.Flags().StringVar(
+		&flags.Name,
+		"name",
+		cluster.DefaultName,
+		"the cluster context name",
+	)
+	cmd.Flags().StringSliceVar(
+		&flags.Nodes,
+		"nodes",
+		nil,
+		"comma separated list of nodes to load images into",
+	)
+	return cmd
+}
+
+func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole, args []string) error {
+	provider := cluster.NewProvider(
+		cluster.ProviderWithLogger(logger),
+		runtime.GetDefault(logger),
+		runtime.NamePrefix(),
+	)
+
+	// find the images used by the manifests
+	images := sets.NewString()
+	for _, path := range args {
+		found, err := imagesFromPath(path, streams.In)
+		if err != nil {
+			return err
+		}
+		images.Insert(found...)
+	}
+	if images.Len() == 0 {
+		logger.V(0).Info("No images found in the manifests")
+		return nil
+	}
+
+	// the images are loaded from the store of the runtime the nodes run in
+	selection, err := runtime.Select()
+	if err != nil {
+		return err
+	}
+	binary := runtime.Binary(selection.Runtime)
+
+	// only images present on the host can be loaded
+	present := map[string]string{}
+	missing := []string{}
+	for _, image := range images.List() {
+		id, err := imageload.ImageID(binary, image)
+		if err != nil {
+			missing = append(missing, image)
+			continue
+		}
+		present[image] = id
+	}
+
+	// Check if the cluster nodes exist
+	candidateNodes, err := imageload.SelectNodes(provider, flags.Name, flags.Nodes)
+	if err != nil {
+		return err
+	}
+
+	// pick the images each node doesn't have yet
+	toLoad := sets.NewString()
+	selectedNodes := []nodes.Node{}
+	for _, node := range candidateNodes {
+		selected := false
+		for image, imageID := range present {
+			id, err := nodeutils.ImageID(node, image)
+			if err != nil || !imageload.SameID(id, imageID) {
+				toLoad.Insert(image)
+				selected = true
+				logger.V(0).Infof("Image: %q with ID %q not yet present on node %q, loading...", image, imageID, node.String())
+			}
+		}
+		if selected {
+			selectedNodes = append(selectedNodes, node)
+		}
+	}
+
+	if len(selectedNodes) > 0 {
+		if err := imageload.Stream(imageload.Save(binary, toLoad.List()), selectedNodes); err != nil {
+			return err
+		}
+	}
+
+	if len(missing) > 0 {
+		logger.V(0).Infof(
+			"The following images are not present locally and were not loaded, pull them and run again or let the nodes pull them:\n  %s",
+			strings.Join(missing, "\n  "),
+		)
+	}
+	return nil
+}
+
+// manifestExtensions are the extensions of the files read from directories
+var manifestExtensions = sets.NewString(".yaml", ".yml", ".json")
+
+// imagesFromPath returns the images used by the manifests in the file or
+// directory at path, or read from stdin if path is -
+func imagesFromPath(path string, stdin io.Reader) ([]string, error) {
+	if path == "-" {
+		images, err := imagesFromManifests(stdin)
+		return images, errors.Wrap(err, "failed to parse manifests from stdin")
+	}
+	images := []string{}
+	err := filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		// the path itself is always read, files in directories by extension
+		if info.IsDir() || (file != path && !manifestExtensions.Has(filepath.Ext(file))) {
+			return nil
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		found, err := imagesFromManifests(f)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse manifests in %s", file)
+		}
+		images = append(images, found...)
+		return nil
+	})
+	return images, err
+}
+
+// imagesFromManifests returns the container images of all of the pod specs
+// in the YAML (or JSON) documents read from r, including those of
+// workloads, lists and any other resource embedding a pod template.
+// The images are in no particular order and may repeat.
+func imagesFromManifests(r io.Reader) ([]string, error) {
+	images := []string{}
+	decoder := yaml.NewDecoder(r)
+	for {
+		var doc interface{}
+		if err := decoder.Decode(&doc); err == io.EOF {
+			return images, nil
+		} else if err != nil {
+			return nil, err
+		}
+		images = appendImages(images, doc)
+	}
+}
+
+// containerFields are the pod spec fields listing containers
+var containerFields = []string{"containers", "initContainers", "ephemeralContainers"}
+
+// appendImages appends the container images found anywhere in v to images
+func appendImages(images []string, v interface{}) []string {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, field := range containerFields {
+			containers, _ := v[field].([]interface{})
+			for _, c := range containers {
+				container, _ := c.(map[string]interface{})
+				if image, ok := container["image"].(string); ok && image != "" {
+					images = append(images, image)
+				}
+			}
+		}
+		for _, value := range v {
+			images = appendImages(images, value)
+		}
+	case []interface{}:
+		for _, value := range v {
+			images = appendImages(images, value)
+		}
+	}
+	return images
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package load
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+const manifests = `# Source: chart/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: busybox:1.32
+      containers:
+      - name: app
+        image: example.com/app:v1
+      - name: sidecar
+        image: envoyproxy/envoy:v1.16.0
+---
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: batch/v1beta1
+  kind: CronJob
+  spec:
+    jobTemplate:
+      spec:
+        template:
+          spec:
+            containers:
+            - name: job
+              image: example.com/job@sha256:0123
+- {"apiVersion": "v1", "kind": "Pod", "spec": {"containers": [{"name": "json", "image": "nginx"}]}}
+---
+apiVersion: v1
+kind: ConfigMap
+data:
+  image: not-a-container
+`
+
+func TestImagesFromManifests(t *testing.T) {
+	t.Parallel()
+	images, err := imagesFromManifests(strings.NewReader(manifests))
+	assert.ExpectError(t, false, err)
+	sort.Strings(images)
+	assert.DeepEqual(t, []string{
+		"busybox:1.32",
+		"envoyproxy/envoy:v1.16.0",
+		"example.com/app:v1",
+		"example.com/job@sha256:0123",
+		"nginx",
+	}, images)
+
+	_, err = imagesFromManifests(strings.NewReader("kind: [Pod"))
+	assert.ExpectError(t, true, err)
+}
+
+func TestImagesFromPath(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "manifests")
+	assert.ExpectError(t, false, err)
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"deploy/app.yaml":    manifests,
+		"deploy/pod.json":    `{"kind": "Pod", "spec": {"containers": [{"image": "redis:6"}]}}`,
+		"deploy/README.md":   "image: ignored",
+		"deploy/chart/a.yml": "spec:\n  containers:\n  - image: memcached\n",
+	}
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		assert.ExpectError(t, false, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.ExpectError(t, false, ioutil.WriteFile(path, []byte(contents), 0644))
+	}
+
+	images, err := imagesFromPath(filepath.Join(dir, "deploy"), nil)
+	assert.ExpectError(t, false, err)
+	sort.Strings(images)
+	assert.DeepEqual(t, []string{
+		"busybox:1.32",
+		"envoyproxy/envoy:v1.16.0",
+		"example.com/app:v1",
+		"example.com/job@sha256:0123",
+		"memcached",
+		"nginx",
+		"redis:6",
+	}, images)
+
+	// stdin, e.g. from helm template
+	images, err = imagesFromPath("-", strings.NewReader(files["deploy/pod.json"]))
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, []string{"redis:6"}, images)
+}
@@ -21,8 +21,11 @@ import (
 	"github.com/spf13/cobra"
 
 	"sigs.k8s.io/kind/pkg/cmd"
+	containerdimage "sigs.k8s.io/kind/pkg/cmd/kind/load/containerd-image"
 	dockerimage "sigs.k8s.io/kind/pkg/cmd/kind/load/docker-image"
+	frommanifests "sigs.k8s.io/kind/pkg/cmd/kind/load/from-manifests"
 	imagearchive "sigs.k8s.io/kind/pkg/cmd/kind/load/image-archive"
+	podmanimage "sigs.k8s.io/kind/pkg/cmd/kind/load/podman-image"
 	"sigs.k8s.io/kind/pkg/log"
 )
 
@@ -35,7 +38,10 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 		Long:  "Loads images into node from an archive or image on host",
 	}
 	// add subcommands
+	cmd.AddCommand(containerdimage.NewCommand(logger, streams))
 	cmd.AddCommand(dockerimage.NewCommand(logger, streams))
+	cmd.AddCommand(frommanifests.NewCommand(logger, streams))
 	cmd.AddCommand(imagearchive.NewCommand(logger, streams))
+	cmd.AddCommand(podmanimage.NewCommand(logger, streams))
 	return cmd
 }
@@ -0,0 +1,121 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package load implements the `load podman-image` command
+package load
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/internal/imageload"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+)
+
+type flagpole struct {
+	Name  string
+	Nodes []string
+}
+
+// NewCommand returns a new cobra.Command for loading images from the local
+// podman image store into a cluster
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return errors.New("name of image is required")
+			}
+			return nil
+		},
+		Use:   "podman-image <IMAGE> [IMAGE...]",
+		Short: "Loads images from the podman image store into nodes",
+		Long:  "Loads images from the local podman image store into all or specified nodes by name, streaming them without writing an archive to disk",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			return runE(logger, flags, args)
+		},
+	}
+	cmd.Flags().StringVar(
+		&flags.Name,
+		"name",
+		cluster.DefaultName,
+		"the cluster context name",
+	)
+	cmd.Flags().StringSliceVar(
+		&flags.Nodes,
+		"nodes",
+		nil,
+		"comma separated list of nodes to load images into",
+	)
+	return cmd
+}
+
+func runE(logger log.Logger, flags *flagpole, images []string) error {
+	provider := cluster.NewProvider(
+		cluster.ProviderWithLogger(logger),
+		runtime.GetDefault(logger),
+		runtime.NamePrefix(),
+	)
+
+	// Check that the images exist locally and get their IDs
+	imageIDs := map[string]string{}
+	for _, image := range images {
+		id, err := imageload.ImageID("podman", image)
+		if err != nil {
+			return fmt.Errorf("image: %q not present locally", image)
+		}
+		imageIDs[image] = id
+	}
+
+	candidateNodes, err := imageload.SelectNodes(provider, flags.Name, flags.Nodes)
+	if err != nil {
+		return err
+	}
+
+	// pick only the nodes missing one of the images
+	toLoad := sets.NewString()
+	selectedNodes := []nodes.Node{}
+	for _, node := range candidateNodes {
+		selected := false
+		for _, image := range images {
+			id, err := nodeutils.ImageID(node, image)
+			if err != nil || !imageload.SameID(id, imageIDs[image]) {
+				toLoad.Insert(image)
+				selected = true
+				logger.V(0).Infof("Image: %q with ID %q not yet present on node %q, loading...", image, imageIDs[image], node.String())
+			}
+		}
+		if selected {
+			selectedNodes = append(selectedNodes, node)
+		}
+	}
+	if len(selectedNodes) == 0 {
+		return nil
+	}
+
+	return imageload.Stream(imageload.Save("podman", toLoad.List()), selectedNodes)
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package images implements the `images` command
+package images
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+)
+
+type flagpole struct {
+	Name   string
+	DryRun bool
+}
+
+// NewCommand returns a new cobra.Command for removing unused images from the nodes
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "images",
+		Short: "Removes unused images from the nodes of a cluster",
+		Long: `Removes the images that no container uses from the containerd store of each
+node, keeping the sandbox (pause) image and pinned images.
+
+Images loaded with "kind load" that no pod has used yet are removed too.`,
+		Example: "  kind prune images --name foo --dry-run",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			return runE(logger, flags)
+		},
+	}
+	cmd.Flags().StringVar(&flags.Name, "name", cluster.DefaultName, "the cluster context name")
+	cmd.Flags().BoolVar(&flags.DryRun, "dry-run", false, "only print the unused images, without removing them")
+	return cmd
+}
+
+func runE(logger log.Logger, flags *flagpole) error {
+	provider := cluster.NewProvider(
+		cluster.ProviderWithLogger(logger),
+		runtime.GetDefault(logger),
+		runtime.NamePrefix(),
+	)
+	pruned, err := provider.PruneImages(flags.Name, cluster.PruneImagesOptions{
+		DryRun: flags.DryRun,
+	})
+	if err != nil {
+		return err
+	}
+	verb := "Removed"
+	if flags.DryRun {
+		verb = "Would remove"
+	}
+	for _, node := range pruned {
+		var size int64
+		for _, image := range node.Images {
+			size += image.Size
+			logger.V(1).Infof("%s image %s from node %s", verb, image.Name, node.Node)
+		}
+		logger.V(0).Infof("%s %d images (%s) from node %s", verb, len(node.Images), formatMiB(size), node.Node)
+	}
+	return nil
+}
+
+// formatMiB formats bytes in MiB, e.g. "72.4MiB"
+func formatMiB(bytes int64) string {
+	return fmt.Sprintf("%.1fMiB", float64(bytes)/(1<<20))
+}
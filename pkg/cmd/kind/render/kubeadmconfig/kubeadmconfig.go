@@ -0,0 +1,97 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kubeadmconfig implements the `kubeadm-config` command
+package kubeadmconfig
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+)
+
+type flagpole struct {
+	Name       string
+	Config     string
+	ImageName  string
+	K8sVersion string
+	Node       string
+}
+
+// NewCommand returns a new cobra.Command for rendering kubeadm configs
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "kubeadm-config",
+		Short: "Renders the kubeadm config creating a cluster would generate for each node",
+		Long: "Renders the kubeadm config creating a cluster with the same flags would generate for each node, " +
+			"with kubeadmConfigPatches applied, in the kubeadm API version for the node's Kubernetes version.\n" +
+			"Node addresses are only known once the nodes exist, they are rendered as NODE_ADDRESS.\n" +
+			"The Kubernetes version is read from the node images, pulling them if needed, unless --k8s-version is set.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			return runE(logger, streams, flags)
+		},
+	}
+	cmd.Flags().StringVar(&flags.Name, "name", "", "cluster name, overrides KIND_CLUSTER_NAME, config (default kind)")
+	cmd.Flags().StringVar(&flags.Config, "config", "", "path to a kind config file")
+	cmd.Flags().StringVar(&flags.ImageName, "image", "", "node docker image to use for booting the cluster")
+	cmd.Flags().StringVar(&flags.K8sVersion, "k8s-version", "", "Kubernetes version to render for, e.g. v1.31.0, instead of reading it from the node images")
+	cmd.Flags().StringVar(&flags.Node, "node", "", "only render the config of this node")
+	return cmd
+}
+
+func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
+	provider := cluster.NewProvider(
+		cluster.ProviderWithLogger(logger),
+		runtime.GetDefault(logger),
+		runtime.NamePrefix(),
+	)
+	configs, err := provider.RenderKubeadmConfig(
+		flags.Name,
+		flags.K8sVersion,
+		cluster.CreateWithConfigFile(flags.Config),
+		cluster.CreateWithNodeImage(flags.ImageName),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to render kubeadm config")
+	}
+	found := false
+	for _, c := range configs {
+		if flags.Node != "" && c.Node != flags.Node {
+			continue
+		}
+		// separate the nodes so the output remains a valid YAML stream
+		if found {
+			fmt.Fprintln(streams.Out, "---")
+		}
+		found = true
+		fmt.Fprintf(streams.Out, "# node: %s\n%s", c.Node, c.Config)
+	}
+	if !found {
+		return errors.Errorf("unknown node %q", flags.Node)
+	}
+	return nil
+}
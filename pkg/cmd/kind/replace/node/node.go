@@ -0,0 +1,87 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package node implements the `replace node` command
+package node
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+)
+
+type flagpole struct {
+	Name        string
+	Image       string
+	Timeout     time.Duration
+	WaitForLock time.Duration
+}
+
+// NewCommand returns a new cobra.Command for replacing a node
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.ExactArgs(1),
+		Use:   "node NODE",
+		Short: "Replaces a node of a cluster with a new node container",
+		Long: `Replaces a node of a cluster with a new node container created from the same config,
+optionally with a different --image, keeping the cluster available.
+
+The new node joins the cluster and must become Ready before the old node is drained,
+removed from the cluster and deleted. The new node is named like a node added to the
+config, e.g. kind-worker3, and the recorded config of the cluster is updated to match.
+
+Control plane nodes can only be replaced in clusters with more than one control plane,
+and nodes publishing fixed host ports can't be replaced. Images loaded into the old
+node are not copied to the new node.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			return runE(logger, streams, flags, args[0])
+		},
+	}
+	cmd.Flags().StringVar(&flags.Name, "name", cluster.DefaultName, "the cluster name")
+	cmd.Flags().StringVar(&flags.Image, "image", "", "node image for the new node (default the image of the old node)")
+	cmd.Flags().DurationVar(&flags.Timeout, "timeout", 5*time.Minute, "how long to wait for the new node to be Ready, and for draining the old node")
+	cmd.Flags().DurationVar(&flags.WaitForLock, "wait-for-lock", 0, "wait for another kind process operating on the cluster to finish instead of failing (default 0s)")
+	return cmd
+}
+
+func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole, node string) error {
+	provider := cluster.NewProvider(
+		cluster.ProviderWithLogger(logger),
+		runtime.GetDefault(logger),
+		runtime.NamePrefix(),
+		cluster.ProviderWithLockWait(flags.WaitForLock),
+	)
+	newNode, err := provider.ReplaceNode(flags.Name, node, cluster.ReplaceNodeOptions{
+		Image:   flags.Image,
+		Timeout: flags.Timeout,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to replace node %q", node)
+	}
+	fmt.Fprintln(streams.Out, newNode)
+	return nil
+}
@@ -18,20 +18,40 @@ limitations under the License.
 package kind
 
 import (
-	"io"
-	"io/ioutil"
+	"os"
 
 	"github.com/spf13/cobra"
 
 	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/cmd/kind/apply"
 	"sigs.k8s.io/kind/pkg/cmd/kind/build"
+	"sigs.k8s.io/kind/pkg/cmd/kind/clone"
 	"sigs.k8s.io/kind/pkg/cmd/kind/completion"
 	"sigs.k8s.io/kind/pkg/cmd/kind/create"
+	"sigs.k8s.io/kind/pkg/cmd/kind/crictl"
+	"sigs.k8s.io/kind/pkg/cmd/kind/ctr"
 	"sigs.k8s.io/kind/pkg/cmd/kind/delete"
 	"sigs.k8s.io/kind/pkg/cmd/kind/export"
+	"sigs.k8s.io/kind/pkg/cmd/kind/expose"
+	"sigs.k8s.io/kind/pkg/cmd/kind/forward"
+	"sigs.k8s.io/kind/pkg/cmd/kind/gc"
 	"sigs.k8s.io/kind/pkg/cmd/kind/get"
+	"sigs.k8s.io/kind/pkg/cmd/kind/heal"
+	"sigs.k8s.io/kind/pkg/cmd/kind/inspect"
 	"sigs.k8s.io/kind/pkg/cmd/kind/load"
+	"sigs.k8s.io/kind/pkg/cmd/kind/prune"
+	"sigs.k8s.io/kind/pkg/cmd/kind/render"
+	"sigs.k8s.io/kind/pkg/cmd/kind/replace"
+	kindruntime "sigs.k8s.io/kind/pkg/cmd/kind/runtime"
+	"sigs.k8s.io/kind/pkg/cmd/kind/serve"
+	kindtrace "sigs.k8s.io/kind/pkg/cmd/kind/trace"
+	"sigs.k8s.io/kind/pkg/cmd/kind/verify"
 	"sigs.k8s.io/kind/pkg/cmd/kind/version"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+	"sigs.k8s.io/kind/pkg/internal/trace"
 	"sigs.k8s.io/kind/pkg/log"
 )
 
@@ -39,6 +59,17 @@ type flagpole struct {
 	LogLevel  string
 	Verbosity int32
 	Quiet     bool
+	Progress  string
+	NoColor   bool
+	Runtime   string
+	LogFormat string
+	// NamePrefix is passed to providers by runtime.NamePrefix
+	NamePrefix string
+	// ErrorFormat is handled by app.Run, it is only registered here
+	ErrorFormat string
+	TraceFile   string
+	// recorder writes the --trace-file, if set, see closeTraceAfterRun
+	recorder *trace.Recorder
 }
 
 // NewCommand returns a new cobra.Command implementing the root command for kind
@@ -76,20 +107,105 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 		"quiet",
 		"q",
 		false,
-		"silence all stderr output",
+		"silence all stderr output except errors, same as --progress=quiet",
+	)
+	cmd.PersistentFlags().StringVar(
+		&flags.Progress,
+		"progress",
+		cli.AutoProgress,
+		"status output style, one of: auto, rich, plain, quiet (auto uses rich on a terminal and plain otherwise)",
+	)
+	cmd.PersistentFlags().BoolVar(
+		&flags.NoColor,
+		"no-color",
+		false,
+		"disable colored output (also disabled by NO_COLOR)",
+	)
+	cmd.PersistentFlags().StringVar(
+		&flags.Runtime,
+		"runtime",
+		"",
+		"node provider runtime, one of: docker, podman, docker-vm, auto (defaults to KIND_EXPERIMENTAL_PROVIDER, ~/.kind/config.yaml, then auto)",
+	)
+	cmd.PersistentFlags().StringVar(
+		&flags.NamePrefix,
+		"name-prefix",
+		"",
+		"prefix for all cluster names, to share a host between CI jobs or users (defaults to KIND_CLUSTER_PREFIX)",
+	)
+	cmd.PersistentFlags().StringVar(
+		&flags.LogFormat,
+		"log-format",
+		"text",
+		"format for log output written to stderr, one of: text, json",
+	)
+	cmd.PersistentFlags().StringVar(
+		&flags.ErrorFormat,
+		"error-format",
+		"text",
+		"format for errors written to stderr, one of: text, json",
+	)
+	cmd.PersistentFlags().StringVar(
+		&flags.TraceFile,
+		"trace-file",
+		"",
+		"append every container runtime command run to this JSONL file, see kind trace show (defaults to KIND_TRACE_FILE)",
 	)
 	// add all top level subcommands
+	cmd.AddCommand(apply.NewCommand(logger, streams))
 	cmd.AddCommand(build.NewCommand(logger, streams))
+	cmd.AddCommand(clone.NewCommand(logger, streams))
 	cmd.AddCommand(completion.NewCommand(logger, streams))
 	cmd.AddCommand(create.NewCommand(logger, streams))
+	cmd.AddCommand(crictl.NewCommand(logger, streams))
+	cmd.AddCommand(ctr.NewCommand(logger, streams))
 	cmd.AddCommand(delete.NewCommand(logger, streams))
 	cmd.AddCommand(export.NewCommand(logger, streams))
+	cmd.AddCommand(expose.NewCommand(logger, streams))
+	cmd.AddCommand(forward.NewCommand(logger, streams))
+	cmd.AddCommand(gc.NewCommand(logger, streams))
 	cmd.AddCommand(get.NewCommand(logger, streams))
+	cmd.AddCommand(heal.NewCommand(logger, streams))
+	cmd.AddCommand(inspect.NewCommand(logger, streams))
 	cmd.AddCommand(version.NewCommand(logger, streams))
 	cmd.AddCommand(load.NewCommand(logger, streams))
+	cmd.AddCommand(prune.NewCommand(logger, streams))
+	cmd.AddCommand(render.NewCommand(logger, streams))
+	cmd.AddCommand(replace.NewCommand(logger, streams))
+	cmd.AddCommand(kindruntime.NewCommand(logger, streams))
+	cmd.AddCommand(serve.NewCommand(logger, streams))
+	cmd.AddCommand(kindtrace.NewCommand(logger, streams))
+	cmd.AddCommand(verify.NewCommand(logger, streams))
+	closeTraceAfterRun(logger, flags, cmd)
 	return cmd
 }
 
+// closeTraceAfterRun wraps the RunE of c and its subcommands to close the
+// trace file once the command has run and report failing to write it. This
+// can't be a post run hook, cobra skips those when the command fails
+func closeTraceAfterRun(logger log.Logger, flags *flagpole, c *cobra.Command) {
+	if run := c.RunE; run != nil {
+		c.RunE = func(c *cobra.Command, args []string) error {
+			err := run(c, args)
+			if flags.recorder == nil {
+				return err
+			}
+			exec.SetTracer(nil)
+			if traceErr := flags.recorder.Close(); traceErr != nil {
+				if err != nil {
+					logger.Warnf("%v", traceErr)
+					return err
+				}
+				return traceErr
+			}
+			return err
+		}
+	}
+	for _, sub := range c.Commands() {
+		closeTraceAfterRun(logger, flags, sub)
+	}
+}
+
 func runE(logger log.Logger, flags *flagpole, command *cobra.Command) error {
 	// handle limited migration for --loglevel
 	setLogLevel := command.Flag("loglevel").Changed
@@ -103,12 +219,45 @@ func runE(logger log.Logger, flags *flagpole, command *cobra.Command) error {
 		}
 	}
 	// normal logger setup
+	maybeSetVerbosity(logger, log.Level(flags.Verbosity))
+	if err := maybeSetFormat(logger, flags.LogFormat); err != nil {
+		return err
+	}
+	// app.Run only recognizes json, reject typos rather than ignoring them
+	if flags.ErrorFormat != "text" && flags.ErrorFormat != "json" {
+		return errors.Errorf("unknown error format %q, expected one of: text, json", flags.ErrorFormat)
+	}
 	if flags.Quiet {
-		// NOTE: if we are coming from app.Run handling this flag is
-		// redundant, however it doesn't hurt, and this may be called directly.
-		maybeSetWriter(logger, ioutil.Discard)
+		flags.Progress = cli.QuietProgress
+	}
+	if err := maybeSetProgress(logger, flags.Progress); err != nil {
+		return err
+	}
+	if flags.NoColor {
+		maybeSetColor(logger, false)
+	}
+	// record the runtime selection for commands that create a provider
+	if err := runtime.SetFlag(flags.Runtime); err != nil {
+		return err
+	}
+	// record the name prefix for commands that create a provider, if set,
+	// otherwise providers default it from the environment
+	if command.Flag("name-prefix").Changed {
+		runtime.SetNamePrefix(flags.NamePrefix)
+	}
+	// record the commands run, if requested
+	traceFile := flags.TraceFile
+	if traceFile == "" {
+		traceFile = os.Getenv(trace.FileEnv)
+	}
+	if traceFile != "" {
+		recorder, err := trace.Open(traceFile)
+		if err != nil {
+			return err
+		}
+		exec.SetTracer(recorder.Record)
+		flags.recorder = recorder
 	}
-	maybeSetVerbosity(logger, log.Level(flags.Verbosity))
 	// warn about deprecated flag if used
 	if setLogLevel {
 		if cmd.ColorEnabled(logger) {
@@ -120,17 +269,44 @@ func runE(logger log.Logger, flags *flagpole, command *cobra.Command) error {
 	return nil
 }
 
-// maybeSetWriter will call logger.SetWriter(w) if logger has a SetWriter method
-func maybeSetWriter(logger log.Logger, w io.Writer) {
-	type writerSetter interface {
-		SetWriter(io.Writer)
+// maybeSetProgress will call logger.SetProgress(style) if logger
+// has a SetProgress method
+func maybeSetProgress(logger log.Logger, style string) error {
+	type progresser interface {
+		SetProgress(string) error
+	}
+	v, ok := logger.(progresser)
+	if ok {
+		return v.SetProgress(style)
+	}
+	return nil
+}
+
+// maybeSetColor will call logger.SetColor(enabled) if logger
+// has a SetColor method
+func maybeSetColor(logger log.Logger, enabled bool) {
+	type colorer interface {
+		SetColor(bool)
 	}
-	v, ok := logger.(writerSetter)
+	v, ok := logger.(colorer)
 	if ok {
-		v.SetWriter(w)
+		v.SetColor(enabled)
 	}
 }
 
+// maybeSetFormat will call logger.SetFormat(format) if logger
+// has a SetFormat method
+func maybeSetFormat(logger log.Logger, format string) error {
+	type formatter interface {
+		SetFormat(string) error
+	}
+	v, ok := logger.(formatter)
+	if ok {
+		return v.SetFormat(format)
+	}
+	return nil
+}
+
 // maybeSetVerbosity will call logger.SetVerbosity(verbosity) if logger
 // has a SetVerbosity method
 func maybeSetVerbosity(logger log.Logger, verbosity log.Level) {
@@ -0,0 +1,62 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package info implements the `info` command
+package info
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+// NewCommand returns a new cobra.Command for runtime info
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "info",
+		Short: "Shows which node provider runtime kind will use and why",
+		Long:  "Shows which node provider runtime kind will use and why",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(streams)
+		},
+	}
+	return cmd
+}
+
+func runE(streams cmd.IOStreams) error {
+	// still show the detection details if selection failed
+	s, err := runtime.Select()
+	if err != nil {
+		s = runtime.Selection{Runtime: "none", Reason: err.Error()}
+	}
+	fmt.Fprintf(streams.Out, "Runtime: %s\n", s.Runtime)
+	fmt.Fprintf(streams.Out, "Reason: %s\n", s.Reason)
+	fmt.Fprintln(streams.Out, "Detection order:")
+	for _, name := range cluster.NodeProviderDetectionOrder() {
+		available := "not available"
+		if cluster.IsNodeProviderAvailable(name) {
+			available = "available"
+		}
+		fmt.Fprintf(streams.Out, "  %s: %s\n", name, available)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package serve implements the `serve` command
+package serve
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+	"sigs.k8s.io/kind/pkg/internal/serve"
+)
+
+type flagpole struct {
+	Socket string
+}
+
+// NewCommand returns a new cobra.Command for serve
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "serve",
+		Short: "Serves a local API for managing kind clusters",
+		Long: "Serves a local HTTP API over a unix socket for managing kind clusters (EXPERIMENTAL)\n\n" +
+			"Cluster creation streams progress events as newline delimited JSON.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(logger, flags)
+		},
+	}
+	cmd.Flags().StringVar(
+		&flags.Socket,
+		"socket",
+		defaultSocket(),
+		"path of the unix socket to serve on",
+	)
+	return cmd
+}
+
+func runE(logger log.Logger, flags *flagpole) error {
+	if flags.Socket == "" {
+		return errors.New("--socket is required")
+	}
+	if err := os.MkdirAll(filepath.Dir(flags.Socket), 0700); err != nil {
+		return errors.Wrap(err, "failed to create socket directory")
+	}
+	logger.Warn("WARNING: kind serve is experimental, the API may change")
+	newProvider := func(options ...cluster.ProviderOption) *cluster.Provider {
+		return cluster.NewProvider(append([]cluster.ProviderOption{
+			cluster.ProviderWithLogger(logger),
+			runtime.GetDefault(logger),
+			runtime.NamePrefix(),
+		}, options...)...)
+	}
+	// serve until interrupted
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		<-signals
+		cancel()
+	}()
+	return serve.NewServer(logger, newProvider).ListenAndServe(ctx, flags.Socket)
+}
+
+// defaultSocket returns ~/.kind/kind.sock
+func defaultSocket() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".kind", "kind.sock")
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package show implements the `show` command
+package show
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/internal/trace"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+type flagpole struct {
+	Failed  bool
+	Slowest int
+	Output  bool
+}
+
+// NewCommand returns a new cobra.Command for showing a trace file
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.ExactArgs(1),
+		Use:   "show FILE",
+		Short: "Shows the commands recorded in a trace file",
+		Long: `Shows the container runtime commands recorded in a trace file written with
+--trace-file, in the order they were run, with when each started relative to
+the first, how long it took and its exit code. FILE may be - for stdin.
+
+For example, to record a failing create in CI and inspect it later:
+
+  kind create cluster --trace-file=kind-trace.jsonl
+  kind trace show --failed --output kind-trace.jsonl`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(streams, flags, args[0])
+		},
+	}
+	cmd.Flags().BoolVar(&flags.Failed, "failed", false, "only show the commands that failed")
+	cmd.Flags().IntVar(&flags.Slowest, "slowest", 0, "only show the N slowest commands, slowest first")
+	cmd.Flags().BoolVar(&flags.Output, "output", false, "show the recorded output of each command")
+	return cmd
+}
+
+func runE(streams cmd.IOStreams, flags *flagpole, path string) error {
+	if flags.Slowest < 0 {
+		return errors.New("--slowest must not be negative")
+	}
+	var r io.Reader = streams.In
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return errors.Wrap(err, "failed to open trace file")
+		}
+		defer f.Close()
+		r = f
+	}
+	entries, err := trace.Read(r)
+	if err != nil {
+		return err
+	}
+	show(streams.Out, entries, flags)
+	return nil
+}
+
+// show writes the entries selected by flags to out, followed by a summary
+// of all entries
+func show(out io.Writer, entries []exec.TraceEntry, flags *flagpole) {
+	if len(entries) == 0 {
+		fmt.Fprintln(out, "No commands recorded")
+		return
+	}
+	start := entries[0].Started
+	failed := 0
+	total := 0.0
+	for _, e := range entries {
+		if e.Started.Before(start) {
+			start = e.Started
+		}
+		if e.ExitCode != 0 {
+			failed++
+		}
+		total += e.Duration
+	}
+
+	selected := []exec.TraceEntry{}
+	for _, e := range entries {
+		if !flags.Failed || e.ExitCode != 0 {
+			selected = append(selected, e)
+		}
+	}
+	if flags.Slowest > 0 {
+		sort.SliceStable(selected, func(i, j int) bool {
+			return selected[i].Duration > selected[j].Duration
+		})
+		if len(selected) > flags.Slowest {
+			selected = selected[:flags.Slowest]
+		}
+	}
+
+	for _, e := range selected {
+		fmt.Fprintf(out, "+%-9s %9s  exit %-3d %s\n",
+			formatSeconds(e.Started.Sub(start).Seconds()),
+			formatSeconds(e.Duration),
+			e.ExitCode,
+			exec.PrettyCommand(e.Command[0], e.Command[1:]...),
+		)
+		if e.Error != "" && e.ExitCode == -1 {
+			fmt.Fprintf(out, "    error: %s\n", e.Error)
+		}
+		if flags.Output && e.Output != "" {
+			for _, line := range strings.Split(strings.TrimRight(e.Output, "\n"), "\n") {
+				fmt.Fprintf(out, "    | %s\n", line)
+			}
+			if e.Truncated() {
+				fmt.Fprintf(out, "    | ... %d more bytes not recorded\n", e.OutputBytes-len(e.Output))
+			}
+		}
+	}
+	fmt.Fprintf(out, "%d commands, %d failed, %s spent running commands\n",
+		len(entries), failed, formatSeconds(total),
+	)
+}
+
+// formatSeconds formats seconds as a duration rounded to milliseconds
+func formatSeconds(seconds float64) string {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Millisecond).String()
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package show
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestShow(t *testing.T) {
+	t.Parallel()
+	started := time.Date(2020, 10, 14, 12, 0, 0, 0, time.UTC)
+	entries := []exec.TraceEntry{
+		{Command: []string{"docker", "ps"}, Started: started, Duration: 0.25},
+		{Command: []string{"docker", "run", "--name", "kind control-plane"}, Started: started.Add(time.Second), Duration: 2, ExitCode: 125, Output: "Error: port is already allocated\n", OutputBytes: 40},
+		{Command: []string{"podman"}, Started: started.Add(3 * time.Second), Duration: 0.001, ExitCode: -1, Error: "exec: \"podman\": executable file not found in $PATH"},
+	}
+	cases := []struct {
+		Name     string
+		Flags    flagpole
+		Entries  []exec.TraceEntry
+		Expected string
+	}{
+		{
+			Name:     "empty",
+			Entries:  []exec.TraceEntry{},
+			Expected: "No commands recorded\n",
+		},
+		{
+			Name:    "all",
+			Entries: entries,
+			Expected: "+0s            250ms  exit 0   docker ps\n" +
+				"+1s               2s  exit 125 docker run --name 'kind control-plane'\n" +
+				"+3s              1ms  exit -1  podman\n" +
+				"    error: exec: \"podman\": executable file not found in $PATH\n" +
+				"3 commands, 2 failed, 2.251s spent running commands\n",
+		},
+		{
+			Name:    "failed with output",
+			Flags:   flagpole{Failed: true, Output: true},
+			Entries: entries[:2],
+			Expected: "+1s               2s  exit 125 docker run --name 'kind control-plane'\n" +
+				"    | Error: port is already allocated\n" +
+				"    | ... 7 more bytes not recorded\n" +
+				"2 commands, 1 failed, 2.25s spent running commands\n",
+		},
+		{
+			Name:    "slowest",
+			Flags:   flagpole{Slowest: 2},
+			Entries: entries,
+			Expected: "+1s               2s  exit 125 docker run --name 'kind control-plane'\n" +
+				"+0s            250ms  exit 0   docker ps\n" +
+				"3 commands, 2 failed, 2.251s spent running commands\n",
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			var out bytes.Buffer
+			show(&out, tc.Entries, &tc.Flags)
+			assert.StringEqual(t, tc.Expected, out.String())
+		})
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package verify implements the `verify` command
+package verify
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+)
+
+type flagpole struct {
+	Name       string
+	Image      string
+	PullImages []string
+	Timeout    time.Duration
+}
+
+// NewCommand returns a new cobra.Command for smoke testing a cluster
+func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "verify",
+		Short: "Runs a quick smoke test of a cluster",
+		Long: `Runs a quick smoke test of a cluster and reports the result of each check:
+the nodes are ready, a test pod is scheduled, cluster DNS resolves, a ClusterIP
+service is reachable and the configured registries and registry mirrors respond
+and serve images pulled through them.
+
+The test pod is created in the kind-verify namespace, which is deleted after.
+Each registry is checked by pulling the first --pull-image, or else --image,
+hosted by it.
+The command fails if any check fails.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			return runE(logger, streams, flags)
+		},
+	}
+	cmd.Flags().StringVar(&flags.Name, "name", cluster.DefaultName, "the cluster name")
+	cmd.Flags().StringVar(&flags.Image, "image", "", "the image of the test pod, it needs bash and getent (default an image preloaded in the node image)")
+	cmd.Flags().StringSliceVar(&flags.PullImages, "pull-image", nil, "images to pull through the configured registries and registry mirrors")
+	cmd.Flags().DurationVar(&flags.Timeout, "timeout", time.Minute, "bounds each check")
+	return cmd
+}
+
+func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
+	provider := cluster.NewProvider(
+		cluster.ProviderWithLogger(logger),
+		runtime.GetDefault(logger),
+		runtime.NamePrefix(),
+	)
+	results, err := provider.Verify(flags.Name, cluster.VerifyOptions{
+		Image:      flags.Image,
+		PullImages: flags.PullImages,
+		Timeout:    flags.Timeout,
+	})
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for _, r := range results {
+		switch {
+		case r.Skipped != "":
+			fmt.Fprintf(streams.Out, "- %s: skipped, %s\n", r.Name, r.Skipped)
+		case r.Err != nil:
+			failed++
+			fmt.Fprintf(streams.Out, "✗ %s (%s): %v\n", r.Name, r.Duration.Round(100*time.Millisecond), r.Err)
+		default:
+			fmt.Fprintf(streams.Out, "✓ %s (%s)\n", r.Name, r.Duration.Round(100*time.Millisecond))
+		}
+	}
+	if failed > 0 {
+		return errors.Errorf("%d of %d checks failed for cluster %q", failed, len(results), flags.Name)
+	}
+	return nil
+}
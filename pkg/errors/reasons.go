@@ -0,0 +1,163 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package errors
+
+// Reason is a stable, machine readable cause of failure
+// Reasons are compared by identity, use the exported Err* values
+type Reason struct {
+	// Code is a stable identifier for the reason, e.g. "ClusterNotFound"
+	Code string
+	// ExitCode is the kind CLI exit code for this reason
+	ExitCode int
+}
+
+// Error implements error
+func (r *Reason) Error() string {
+	return r.Code
+}
+
+var (
+	// ErrClusterNotFound means the requested cluster does not exist
+	ErrClusterNotFound = &Reason{Code: "ClusterNotFound", ExitCode: 3}
+	// ErrProviderUnavailable means no usable node provider (docker, podman) was found
+	ErrProviderUnavailable = &Reason{Code: "ProviderUnavailable", ExitCode: 4}
+	// ErrNodeImagePullFailed means a node image could not be pulled
+	ErrNodeImagePullFailed = &Reason{Code: "NodeImagePullFailed", ExitCode: 5}
+	// ErrPortConflict means a host port required by the cluster is in use
+	ErrPortConflict = &Reason{Code: "PortConflict", ExitCode: 6}
+	// ErrNodeCommandFailed means a command run on a node exited non-zero,
+	// the kind CLI exits with the command's exit code where known
+	ErrNodeCommandFailed = &Reason{Code: "NodeCommandFailed", ExitCode: 7}
+	// ErrImagesMissing means images required in offline mode are not present
+	ErrImagesMissing = &Reason{Code: "ImagesMissing", ExitCode: 8}
+	// ErrClusterBusy means another kind process is operating on the cluster
+	ErrClusterBusy = &Reason{Code: "ClusterBusy", ExitCode: 9}
+	// ErrWarnings means warnings were reported and are treated as errors
+	ErrWarnings = &Reason{Code: "Warnings", ExitCode: 10}
+)
+
+// DefaultExitCode is the exit code for errors without a Reason
+const DefaultExitCode = 1
+
+// WithReason annotates err with reason, such that ReasonForError(err) and the
+// standard library's errors.Is(err, reason) identify it.
+// If err is nil, WithReason returns nil.
+func WithReason(err error, reason *Reason) error {
+	if err == nil {
+		return nil
+	}
+	return &reasonError{err: err, reason: reason}
+}
+
+// ClusterNotFound returns an error for the missing cluster name, with the
+// ErrClusterNotFound reason
+func ClusterNotFound(name string) error {
+	return WithReason(Errorf("unknown cluster %q", name), ErrClusterNotFound)
+}
+
+// ReasonForError returns the outermost Reason in err's Cause chain, or nil
+func ReasonForError(err error) *Reason {
+	for err != nil {
+		switch v := err.(type) {
+		case *reasonError:
+			return v.reason
+		case *Reason:
+			return v
+		}
+		causerErr, ok := err.(Causer)
+		if !ok {
+			break
+		}
+		err = causerErr.Cause()
+	}
+	return nil
+}
+
+// WithExitCode annotates err with an explicit CLI exit code, which takes
+// precedence over the exit code of any Reason it wraps, e.g. to pass
+// through the exit code of a command run on a node.
+// If err is nil, WithExitCode returns nil.
+func WithExitCode(err error, exitCode int) error {
+	if err == nil {
+		return nil
+	}
+	return &exitCodeError{err: err, exitCode: exitCode}
+}
+
+// ExitCodeForError returns the CLI exit code for err, this is the outermost
+// explicit exit code or Reason exit code in err's Cause chain
+func ExitCodeForError(err error) int {
+	for err != nil {
+		switch v := err.(type) {
+		case *exitCodeError:
+			return v.exitCode
+		case *reasonError:
+			return v.reason.ExitCode
+		case *Reason:
+			return v.ExitCode
+		}
+		causerErr, ok := err.(Causer)
+		if !ok {
+			break
+		}
+		err = causerErr.Cause()
+	}
+	return DefaultExitCode
+}
+
+type reasonError struct {
+	err    error
+	reason *Reason
+}
+
+func (e *reasonError) Error() string {
+	return e.err.Error()
+}
+
+// Cause mimics github.com/pkg/errors's Cause pattern for errors
+func (e *reasonError) Cause() error {
+	return e.err
+}
+
+// Unwrap supports the standard library's errors.Unwrap
+func (e *reasonError) Unwrap() error {
+	return e.err
+}
+
+// Is supports the standard library's errors.Is
+func (e *reasonError) Is(target error) bool {
+	return target == e.reason
+}
+
+type exitCodeError struct {
+	err      error
+	exitCode int
+}
+
+func (e *exitCodeError) Error() string {
+	return e.err.Error()
+}
+
+// Cause mimics github.com/pkg/errors's Cause pattern for errors
+func (e *exitCodeError) Cause() error {
+	return e.err
+}
+
+// Unwrap supports the standard library's errors.Unwrap
+func (e *exitCodeError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package errors
+
+import (
+	stderrors "errors"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestReasonForError(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name             string
+		Err              error
+		ExpectedReason   *Reason
+		ExpectedExitCode int
+	}{
+		{
+			Name:             "nil",
+			Err:              nil,
+			ExpectedExitCode: DefaultExitCode,
+		},
+		{
+			Name:             "no reason",
+			Err:              New("foo"),
+			ExpectedExitCode: DefaultExitCode,
+		},
+		{
+			Name:             "wrapped reason",
+			Err:              Wrap(WithReason(New("foo"), ErrPortConflict), "bar"),
+			ExpectedReason:   ErrPortConflict,
+			ExpectedExitCode: ErrPortConflict.ExitCode,
+		},
+		{
+			Name:             "outermost reason wins",
+			Err:              WithReason(Wrap(WithReason(New("foo"), ErrPortConflict), "bar"), ErrClusterNotFound),
+			ExpectedReason:   ErrClusterNotFound,
+			ExpectedExitCode: ErrClusterNotFound.ExitCode,
+		},
+		{
+			Name:             "cluster not found",
+			Err:              Wrap(ClusterNotFound("foo"), "bar"),
+			ExpectedReason:   ErrClusterNotFound,
+			ExpectedExitCode: ErrClusterNotFound.ExitCode,
+		},
+		{
+			Name:             "bare reason",
+			Err:              Wrap(ErrProviderUnavailable, "bar"),
+			ExpectedReason:   ErrProviderUnavailable,
+			ExpectedExitCode: ErrProviderUnavailable.ExitCode,
+		},
+		{
+			Name:             "explicit exit code",
+			Err:              Wrap(WithExitCode(WithReason(New("foo"), ErrNodeCommandFailed), 42), "bar"),
+			ExpectedReason:   ErrNodeCommandFailed,
+			ExpectedExitCode: 42,
+		},
+		{
+			Name:             "outer reason over inner exit code",
+			Err:              WithReason(WithExitCode(New("foo"), 42), ErrClusterBusy),
+			ExpectedReason:   ErrClusterBusy,
+			ExpectedExitCode: ErrClusterBusy.ExitCode,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			if r := ReasonForError(tc.Err); r != tc.ExpectedReason {
+				t.Errorf("expected reason %v but got %v", tc.ExpectedReason, r)
+			}
+			assert.DeepEqual(t, tc.ExpectedExitCode, ExitCodeForError(tc.Err))
+		})
+	}
+}
+
+func TestWithReasonIs(t *testing.T) {
+	t.Parallel()
+	err := Wrap(WithReason(New("foo"), ErrNodeImagePullFailed), "bar")
+	if !stderrors.Is(err, ErrNodeImagePullFailed) {
+		t.Errorf("expected errors.Is to match the reason")
+	}
+	if stderrors.Is(err, ErrPortConflict) {
+		t.Errorf("expected errors.Is not to match a different reason")
+	}
+	if WithReason(nil, ErrPortConflict) != nil {
+		t.Errorf("expected WithReason(nil) to be nil")
+	}
+}
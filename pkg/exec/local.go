@@ -22,6 +22,7 @@ import (
 	"io"
 	osexec "os/exec"
 	"sync"
+	"time"
 
 	"sigs.k8s.io/kind/pkg/errors"
 )
@@ -119,8 +120,15 @@ func (cmd *LocalCmd) Run() error {
 			cmd.Stderr = combinedOutputWriter
 		}
 	}
+	started := time.Now()
 	// TODO: should be in the caller or logger should be injected somehow ...
-	if err := cmd.Cmd.Run(); err != nil {
+	err := cmd.Cmd.Run()
+	exitCode := -1
+	if cmd.ProcessState != nil {
+		exitCode = cmd.ProcessState.ExitCode()
+	}
+	trace(cmd.Args, started, combinedOutput.Bytes(), exitCode, err)
+	if err != nil {
 		return errors.WithStack(&RunError{
 			Command: cmd.Args,
 			Output:  combinedOutput.Bytes(),
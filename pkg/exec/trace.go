@@ -0,0 +1,118 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exec
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/internal/redact"
+)
+
+// TraceOutputLimit is the number of bytes of output kept in a TraceEntry,
+// the rest is dropped
+const TraceOutputLimit = 4096
+
+// TraceEntry records a command run by a LocalCmd, see SetTracer.
+// Credentials and other secrets in the command, error and output are
+// redacted, and the output of commands printing secrets is not recorded
+type TraceEntry struct {
+	// Command is [Name Args...]
+	Command []string `json:"command"`
+	// Started is when the command was started
+	Started time.Time `json:"started"`
+	// Duration is how long the command ran
+	Duration float64 `json:"durationSeconds"`
+	// ExitCode is the exit code of the command, -1 if it did not exit
+	ExitCode int `json:"exitCode"`
+	// Error is the error running the command, if any
+	Error string `json:"error,omitempty"`
+	// Output is the combined stdout and stderr of the command, up to
+	// TraceOutputLimit bytes
+	Output string `json:"output,omitempty"`
+	// OutputBytes is the size of the whole output
+	OutputBytes int `json:"outputBytes"`
+}
+
+// Truncated returns whether Output is only part of the output
+func (e *TraceEntry) Truncated() bool {
+	return len(e.Output) < e.OutputBytes
+}
+
+// Tracer is called with an entry for each command run by a LocalCmd,
+// possibly concurrently
+type Tracer func(TraceEntry)
+
+var (
+	tracerMu sync.RWMutex
+	tracer   Tracer
+)
+
+// SetTracer sets the Tracer called for every command run by a LocalCmd,
+// including those from DefaultCmder. nil disables tracing
+func SetTracer(t Tracer) {
+	tracerMu.Lock()
+	defer tracerMu.Unlock()
+	tracer = t
+}
+
+// trace calls the tracer set by SetTracer, if any, for a command that ran
+// from started until now
+func trace(command []string, started time.Time, output []byte, exitCode int, err error) {
+	tracerMu.RLock()
+	t := tracer
+	tracerMu.RUnlock()
+	if t == nil {
+		return
+	}
+	e := TraceEntry{
+		Command:     redact.Args(command),
+		Started:     started,
+		Duration:    time.Since(started).Seconds(),
+		ExitCode:    exitCode,
+		OutputBytes: len(output),
+	}
+	if err != nil {
+		e.Error = redact.String(err.Error())
+	}
+	if printsSecrets(command) {
+		output = nil
+	}
+	if len(output) > TraceOutputLimit {
+		output = output[:TraceOutputLimit]
+	}
+	e.Output = redact.String(string(output))
+	t(e)
+}
+
+// printsSecrets returns whether command is known to print secrets that
+// can't be reliably redacted, e.g. kubeadm bootstrap tokens and the
+// certificate key for joining control plane nodes
+func printsSecrets(command []string) bool {
+	for i, arg := range command {
+		if arg != "kubeadm" {
+			continue
+		}
+		// the command may run in a node container, e.g. docker exec
+		args := strings.Join(command[i+1:], " ")
+		if strings.HasPrefix(args, "token") || strings.Contains(args, "upload-certs") {
+			return true
+		}
+	}
+	return false
+}
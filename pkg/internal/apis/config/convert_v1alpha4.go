@@ -31,6 +31,20 @@ func Convertv1alpha4(in *v1alpha4.Cluster) *Cluster {
 		KubeadmConfigPatchesJSON6902:    make([]PatchJSON6902, len(in.KubeadmConfigPatchesJSON6902)),
 		ContainerdConfigPatches:         in.ContainerdConfigPatches,
 		ContainerdConfigPatchesJSON6902: in.ContainerdConfigPatchesJSON6902,
+		GPUDevicePlugin:                 in.GPUDevicePlugin,
+		Presets:                         make([]Preset, len(in.Presets)),
+		Registries:                      make([]Registry, len(in.Registries)),
+		NodeNameTemplate:                in.NodeNameTemplate,
+		RuntimeConfig:                   in.RuntimeConfig,
+		ControlPlaneMode:                ControlPlaneMode(in.ControlPlaneMode),
+		RestartPolicy:                   RestartPolicy(in.RestartPolicy),
+		ContainerdSnapshotter:           ContainerdSnapshotter(in.ContainerdSnapshotter),
+		TargetedKubeadmConfigPatches:    make([]TargetedPatch, len(in.TargetedKubeadmConfigPatches)),
+		KubeletServerTLSBootstrap:       in.KubeletServerTLSBootstrap,
+		AdmissionConfiguration:          in.AdmissionConfiguration,
+		AuthorizationConfiguration:      in.AuthorizationConfiguration,
+		DiskQuota:                       in.DiskQuota,
+		ContainerdMetrics:               in.ContainerdMetrics,
 	}
 
 	for i := range in.Nodes {
@@ -43,18 +57,180 @@ func Convertv1alpha4(in *v1alpha4.Cluster) *Cluster {
 		convertv1alpha4PatchJSON6902(&in.KubeadmConfigPatchesJSON6902[i], &out.KubeadmConfigPatchesJSON6902[i])
 	}
 
+	for i := range in.TargetedKubeadmConfigPatches {
+		convertv1alpha4TargetedPatch(&in.TargetedKubeadmConfigPatches[i], &out.TargetedKubeadmConfigPatches[i])
+	}
+
+	for i := range in.Presets {
+		out.Presets[i] = Preset(in.Presets[i])
+	}
+
+	for i := range in.Registries {
+		out.Registries[i] = Registry(in.Registries[i])
+	}
+
+	convertv1alpha4ContainerLogs(&in.ContainerLogs, &out.ContainerLogs)
+	convertv1alpha4ImageGC(&in.ImageGC, &out.ImageGC)
+	convertv1alpha4Inotify(&in.Inotify, &out.Inotify)
+	convertv1alpha4SecurityProfiles(&in.SecurityProfiles, &out.SecurityProfiles)
+	convertv1alpha4Trust(&in.Trust, &out.Trust)
+
+	convertv1alpha4CredentialProviders(&in.CredentialProviders, &out.CredentialProviders)
+	convertv1alpha4Etcd(&in.Etcd, &out.Etcd)
+	convertv1alpha4ComponentFeatureGates(&in.ComponentFeatureGates, &out.ComponentFeatureGates)
+	convertv1alpha4Storage(&in.Storage, &out.Storage)
+	convertv1alpha4ExternalControlPlane(&in.ExternalControlPlane, &out.ExternalControlPlane)
+	convertv1alpha4Images(&in.Images, &out.Images)
+	convertv1alpha4LoadBalancer(&in.LoadBalancer, &out.LoadBalancer)
+	convertv1alpha4OIDC(&in.Authentication.OIDC, &out.Authentication.OIDC)
+
 	return out
 }
 
+func convertv1alpha4OIDC(in *v1alpha4.OIDC, out *OIDC) {
+	out.IssuerURL = in.IssuerURL
+	out.ClientID = in.ClientID
+	out.CA = in.CA
+	out.UsernameClaim = in.UsernameClaim
+	out.UsernamePrefix = in.UsernamePrefix
+	out.GroupsClaim = in.GroupsClaim
+	out.GroupsPrefix = in.GroupsPrefix
+	out.RequiredClaims = in.RequiredClaims
+	out.TestIssuer = in.TestIssuer
+}
+
+func convertv1alpha4LoadBalancer(in *v1alpha4.LoadBalancer, out *LoadBalancer) {
+	out.Implementation = LoadBalancerImplementation(in.Implementation)
+	out.HealthCheck = LoadBalancerHealthCheck{
+		Interval: in.HealthCheck.Interval,
+		Rise:     in.HealthCheck.Rise,
+		Fall:     in.HealthCheck.Fall,
+	}
+	out.ExtraFrontends = make([]LoadBalancerFrontend, len(in.ExtraFrontends))
+	for i := range in.ExtraFrontends {
+		f := &in.ExtraFrontends[i]
+		out.ExtraFrontends[i] = LoadBalancerFrontend{
+			Name:        f.Name,
+			Port:        f.Port,
+			BackendPort: f.BackendPort,
+			HostPort:    f.HostPort,
+		}
+	}
+}
+
+func convertv1alpha4Images(in *v1alpha4.Images, out *Images) {
+	out.Repository = in.Repository
+	out.Pause = in.Pause
+	out.CoreDNS = in.CoreDNS
+	out.Etcd = in.Etcd
+	out.LoadBalancer = in.LoadBalancer
+	out.Overrides = in.Overrides
+}
+
+func convertv1alpha4ExternalControlPlane(in *v1alpha4.ExternalControlPlane, out *ExternalControlPlane) {
+	out.Endpoint = in.Endpoint
+	out.Token = in.Token
+	out.CACertHashes = in.CACertHashes
+}
+
+func convertv1alpha4Storage(in *v1alpha4.Storage, out *Storage) {
+	out.Provisioner = StorageProvisioner(in.Provisioner)
+	out.ExtraStorageClasses = make([]StorageClass, len(in.ExtraStorageClasses))
+	for i := range in.ExtraStorageClasses {
+		c := &in.ExtraStorageClasses[i]
+		out.ExtraStorageClasses[i] = StorageClass{
+			Name:              c.Name,
+			Provisioner:       c.Provisioner,
+			Default:           c.Default,
+			Parameters:        c.Parameters,
+			ReclaimPolicy:     c.ReclaimPolicy,
+			VolumeBindingMode: c.VolumeBindingMode,
+		}
+	}
+}
+
+func convertv1alpha4ComponentFeatureGates(in *v1alpha4.ComponentFeatureGates, out *ComponentFeatureGates) {
+	out.APIServer = in.APIServer
+	out.ControllerManager = in.ControllerManager
+	out.Scheduler = in.Scheduler
+	out.Kubelet = in.Kubelet
+	out.KubeProxy = in.KubeProxy
+}
+
+func convertv1alpha4ContainerLogs(in *v1alpha4.ContainerLogs, out *ContainerLogs) {
+	out.MaxSize = in.MaxSize
+	out.MaxFiles = in.MaxFiles
+	out.MaxLineSize = in.MaxLineSize
+}
+
+func convertv1alpha4ImageGC(in *v1alpha4.ImageGC, out *ImageGC) {
+	out.HighThresholdPercent = in.HighThresholdPercent
+	out.LowThresholdPercent = in.LowThresholdPercent
+	out.MinimumAge = in.MinimumAge
+}
+
+func convertv1alpha4SecurityProfiles(in *v1alpha4.SecurityProfiles, out *SecurityProfiles) {
+	out.SeccompDir = in.SeccompDir
+	out.AppArmor = in.AppArmor
+}
+
+func convertv1alpha4CredentialProviders(in *v1alpha4.CredentialProviders, out *CredentialProviders) {
+	out.BinDir = in.BinDir
+	out.Providers = make([]CredentialProvider, len(in.Providers))
+	for i, p := range in.Providers {
+		out.Providers[i] = CredentialProvider{
+			Name:                 p.Name,
+			MatchImages:          p.MatchImages,
+			DefaultCacheDuration: p.DefaultCacheDuration,
+			Args:                 p.Args,
+			Env:                  p.Env,
+		}
+	}
+}
+
+func convertv1alpha4Etcd(in *v1alpha4.Etcd, out *Etcd) {
+	out.QuotaBackendBytes = in.QuotaBackendBytes
+	out.HeartbeatInterval = in.HeartbeatInterval
+	out.ElectionTimeout = in.ElectionTimeout
+	out.DataVolume = in.DataVolume
+}
+
+func convertv1alpha4Trust(in *v1alpha4.Trust, out *Trust) {
+	out.ExtraCAs = in.ExtraCAs
+}
+
+func convertv1alpha4Inotify(in *v1alpha4.Inotify, out *Inotify) {
+	out.MaxUserWatches = in.MaxUserWatches
+	out.MaxUserInstances = in.MaxUserInstances
+}
+
+func convertv1alpha4Swap(in *v1alpha4.Swap, out *Swap) {
+	out.Size = in.Size
+	out.Behavior = SwapBehavior(in.Behavior)
+}
+
 func convertv1alpha4Node(in *v1alpha4.Node, out *Node) {
 	out.Role = NodeRole(in.Role)
 	out.Image = in.Image
+	out.GPUs = in.GPUs
 
 	out.KubeadmConfigPatches = in.KubeadmConfigPatches
+	out.CgroupNS = in.CgroupNS
+	out.CPUSet = in.CPUSet
+	out.Hugepages = in.Hugepages
+	out.ClockOffset = in.ClockOffset
+	out.Name = in.Name
+	out.Hostname = in.Hostname
+	out.MachineID = in.MachineID
+	out.Labels = in.Labels
+	out.Ulimits = in.Ulimits
+	out.StaticPodsHostPath = in.StaticPodsHostPath
 	out.ExtraMounts = make([]Mount, len(in.ExtraMounts))
 	out.ExtraPortMappings = make([]PortMapping, len(in.ExtraPortMappings))
 	out.KubeadmConfigPatchesJSON6902 = make([]PatchJSON6902, len(in.KubeadmConfigPatchesJSON6902))
 
+	convertv1alpha4Swap(&in.Swap, &out.Swap)
+
 	for i := range in.ExtraMounts {
 		convertv1alpha4Mount(&in.ExtraMounts[i], &out.ExtraMounts[i])
 	}
@@ -63,18 +239,43 @@ func convertv1alpha4Node(in *v1alpha4.Node, out *Node) {
 		convertv1alpha4PortMapping(&in.ExtraPortMappings[i], &out.ExtraPortMappings[i])
 	}
 
+	out.PersistentVolumes = make([]PersistentVolume, len(in.PersistentVolumes))
+	for i := range in.PersistentVolumes {
+		convertv1alpha4PersistentVolume(&in.PersistentVolumes[i], &out.PersistentVolumes[i])
+	}
+
 	for i := range in.KubeadmConfigPatchesJSON6902 {
 		convertv1alpha4PatchJSON6902(&in.KubeadmConfigPatchesJSON6902[i], &out.KubeadmConfigPatchesJSON6902[i])
 	}
 }
 
+func convertv1alpha4PersistentVolume(in *v1alpha4.PersistentVolume, out *PersistentVolume) {
+	out.Name = in.Name
+	out.Path = in.Path
+	out.Capacity = in.Capacity
+	out.StorageClassName = in.StorageClassName
+	out.ReclaimPolicy = in.ReclaimPolicy
+}
+
 func convertv1alpha4PatchJSON6902(in *v1alpha4.PatchJSON6902, out *PatchJSON6902) {
 	out.Group = in.Group
 	out.Version = in.Version
 	out.Kind = in.Kind
+	convertv1alpha4PatchTarget(&in.Target, &out.Target)
 	out.Patch = in.Patch
 }
 
+func convertv1alpha4TargetedPatch(in *v1alpha4.TargetedPatch, out *TargetedPatch) {
+	convertv1alpha4PatchTarget(&in.Target, &out.Target)
+	out.Patch = in.Patch
+}
+
+func convertv1alpha4PatchTarget(in *v1alpha4.PatchTarget, out *PatchTarget) {
+	out.Role = NodeRole(in.Role)
+	out.NodeName = in.NodeName
+	out.Component = PatchComponent(in.Component)
+}
+
 func convertv1alpha4Networking(in *v1alpha4.Networking, out *Networking) {
 	out.IPFamily = ClusterIPFamily(in.IPFamily)
 	out.APIServerPort = in.APIServerPort
@@ -83,13 +284,17 @@ func convertv1alpha4Networking(in *v1alpha4.Networking, out *Networking) {
 	out.KubeProxyMode = ProxyMode(in.KubeProxyMode)
 	out.ServiceSubnet = in.ServiceSubnet
 	out.DisableDefaultCNI = in.DisableDefaultCNI
+	out.AdditionalAPIServerAddresses = append([]string(nil), in.AdditionalAPIServerAddresses...)
 }
 
 func convertv1alpha4Mount(in *v1alpha4.Mount, out *Mount) {
 	out.ContainerPath = in.ContainerPath
 	out.HostPath = in.HostPath
 	out.Readonly = in.Readonly
-	out.SelinuxRelabel = in.SelinuxRelabel
+	out.SelinuxRelabel = MountSelinuxRelabel(in.SelinuxRelabelMode)
+	if out.SelinuxRelabel == "" && in.SelinuxRelabel {
+		out.SelinuxRelabel = MountSelinuxRelabelPrivate
+	}
 	out.Propagation = MountPropagation(in.Propagation)
 }
 
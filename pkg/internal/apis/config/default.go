@@ -81,6 +81,56 @@ func SetDefaultsCluster(obj *Cluster) {
 	if obj.Networking.KubeProxyMode == "" {
 		obj.Networking.KubeProxyMode = IPTablesMode
 	}
+	// default to rotating container logs more aggressively than kubelet,
+	// nodes share the host disk and clusters are often long lived in CI
+	if obj.ContainerLogs.MaxSize == "" {
+		obj.ContainerLogs.MaxSize = "5Mi"
+	}
+	if obj.ContainerLogs.MaxFiles == 0 {
+		obj.ContainerLogs.MaxFiles = 3
+	}
+	// default to the node image's local path provisioner
+	if obj.Storage.Provisioner == "" {
+		obj.Storage.Provisioner = LocalPathProvisioner
+	}
+	if obj.ControlPlaneMode == "" {
+		obj.ControlPlaneMode = FullControlPlaneMode
+	}
+	if obj.RestartPolicy == "" {
+		obj.RestartPolicy = OnFailureRestartPolicy
+	}
+	if obj.ContainerdSnapshotter == "" {
+		obj.ContainerdSnapshotter = OverlayFSSnapshotter
+	}
+	// default to haproxy with its default health checks
+	if obj.LoadBalancer.Implementation == "" {
+		obj.LoadBalancer.Implementation = HAProxyLoadBalancer
+	}
+	if obj.LoadBalancer.HealthCheck.Interval == "" {
+		obj.LoadBalancer.HealthCheck.Interval = "2s"
+	}
+	if obj.LoadBalancer.HealthCheck.Rise == 0 {
+		obj.LoadBalancer.HealthCheck.Rise = 2
+	}
+	if obj.LoadBalancer.HealthCheck.Fall == 0 {
+		obj.LoadBalancer.HealthCheck.Fall = 3
+	}
+	for i := range obj.LoadBalancer.ExtraFrontends {
+		f := &obj.LoadBalancer.ExtraFrontends[i]
+		if f.BackendPort == 0 {
+			f.BackendPort = f.Port
+		}
+	}
+
+	// the test issuer has a single client, and users identified by email
+	if obj.Authentication.OIDC.TestIssuer {
+		if obj.Authentication.OIDC.ClientID == "" {
+			obj.Authentication.OIDC.ClientID = "kind"
+		}
+		if obj.Authentication.OIDC.UsernameClaim == "" {
+			obj.Authentication.OIDC.UsernameClaim = "email"
+		}
+	}
 }
 
 // SetDefaultsNode sets uninitialized fields to their default value.
@@ -92,4 +142,15 @@ func SetDefaultsNode(obj *Node) {
 	if obj.Role == "" {
 		obj.Role = ControlPlaneRole
 	}
+
+	// pre-provisioned volumes keep their data unless asked otherwise
+	for i := range obj.PersistentVolumes {
+		pv := &obj.PersistentVolumes[i]
+		if pv.StorageClassName == "" {
+			pv.StorageClassName = "standard"
+		}
+		if pv.ReclaimPolicy == "" {
+			pv.ReclaimPolicy = "Retain"
+		}
+	}
 }
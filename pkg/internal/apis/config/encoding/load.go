@@ -53,7 +53,16 @@ func Load(path string) (*config.Cluster, error) {
 // Parse parses a cluster config from raw (yaml) bytes
 // It will always return the current internal version after defaulting and
 // conversion from the read version
+// Multiple documents in raw are merged into one, see ApplyOverrides
 func Parse(raw []byte) (*config.Cluster, error) {
+	if docs, err := SplitDocuments(raw); err == nil && len(docs) > 1 {
+		merged, err := ApplyOverrides(raw, nil)
+		if err != nil {
+			return nil, err
+		}
+		raw = merged
+	}
+
 	// get kind & apiVersion
 	tm := typeMeta{}
 	if err := yaml.Unmarshal(raw, &tm); err != nil {
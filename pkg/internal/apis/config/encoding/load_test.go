@@ -18,6 +18,11 @@ package encoding
 
 import (
 	"testing"
+
+	yaml "gopkg.in/yaml.v3"
+
+	"sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
 )
 
 func TestLoadCurrent(t *testing.T) {
@@ -72,6 +77,16 @@ func TestLoadCurrent(t *testing.T) {
 			Path:        "./testdata/v1alpha4/valid-port-and-mount.yaml",
 			ExpectError: false,
 		},
+		{
+			TestName:    "v1alpha4 config with selinux relabel options",
+			Path:        "./testdata/v1alpha4/valid-selinux-relabel.yaml",
+			ExpectError: false,
+		},
+		{
+			TestName:    "v1alpha4 config with invalid selinux relabel",
+			Path:        "./testdata/v1alpha4/invalid-selinux-relabel.yaml",
+			ExpectError: true,
+		},
 		{
 			TestName:    "v1alpha4 non-existent field",
 			Path:        "./testdata/v1alpha4/invalid-bogus-field.yaml",
@@ -123,3 +138,52 @@ func TestLoadCurrent(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadSelinuxRelabel(t *testing.T) {
+	t.Parallel()
+	cfg, err := Load("./testdata/v1alpha4/valid-selinux-relabel.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error while Loading config: %v", err)
+	}
+	expected := []config.MountSelinuxRelabel{
+		config.MountSelinuxRelabelShared,
+		config.MountSelinuxRelabelPrivate,
+		config.MountSelinuxRelabelNone,
+		config.MountSelinuxRelabelPrivate,
+		config.MountSelinuxRelabelNone,
+	}
+	mounts := cfg.Nodes[0].ExtraMounts
+	if len(mounts) != len(expected) {
+		t.Fatalf("expected %d mounts but got %d", len(expected), len(mounts))
+	}
+	for i := range expected {
+		if mounts[i].SelinuxRelabel != expected[i] {
+			t.Errorf("mount %s: expected %q but got %q", mounts[i].HostPath, expected[i], mounts[i].SelinuxRelabel)
+		}
+	}
+}
+
+func TestMarshalSelinuxRelabel(t *testing.T) {
+	t.Parallel()
+	mounts := []v1alpha4.Mount{
+		{HostPath: "/shared", SelinuxRelabelMode: v1alpha4.MountSelinuxRelabelShared},
+		{HostPath: "/legacy", SelinuxRelabel: true},
+		{HostPath: "/unset"},
+	}
+	raw, err := yaml.Marshal(mounts)
+	if err != nil {
+		t.Fatalf("unexpected error while marshalling mounts: %v", err)
+	}
+	var decoded []v1alpha4.Mount
+	if err := yamlUnmarshalStrict(raw, &decoded); err != nil {
+		t.Fatalf("unexpected error while unmarshalling mounts: %v", err)
+	}
+	if len(decoded) != len(mounts) {
+		t.Fatalf("expected %d mounts but got %d", len(mounts), len(decoded))
+	}
+	for i := range mounts {
+		if decoded[i] != mounts[i] {
+			t.Errorf("expected %+v but got %+v", mounts[i], decoded[i])
+		}
+	}
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encoding
+
+import (
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// defaultDocument is the config overrides are applied to when there is no
+// config to start from
+const defaultDocument = "kind: Cluster\napiVersion: kind.x-k8s.io/v1alpha4\n"
+
+// ApplyOverrides merges the YAML documents in raw into one and then applies
+// each of overrides to it, returning the resulting single document.
+//
+// Each document is merged over the ones before it: mappings are merged
+// key by key, any other value, including a list, replaces the previous one.
+//
+// An override has the form path=value, where path is a dot separated list of
+// fields with optional list indexes, e.g. nodes[1].image or networking.ipFamily,
+// and value is parsed as YAML, falling back to a plain string.
+// A dot within a field name may be escaped with a backslash, e.g.
+// nodes[0].labels.topology\.kubernetes\.io/zone=a
+// Missing fields are created, and the index equal to the length of a list
+// appends a new entry to it.
+//
+// If raw contains no documents the overrides are applied to an empty
+// v1alpha4 Cluster config.
+func ApplyOverrides(raw []byte, overrides []string) ([]byte, error) {
+	docs, err := SplitDocuments(raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		docs = [][]byte{[]byte(defaultDocument)}
+	}
+	var merged interface{}
+	for _, doc := range docs {
+		var v interface{}
+		if err := yaml.Unmarshal(doc, &v); err != nil {
+			return nil, errors.Wrap(err, "failed to decode config document")
+		}
+		merged = mergeValues(merged, v)
+	}
+	for _, override := range overrides {
+		eq := strings.Index(override, "=")
+		if eq < 1 {
+			return nil, errors.Errorf("invalid override %q, expected path=value", override)
+		}
+		path, err := parsePath(override[:eq])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid override %q", override)
+		}
+		merged, err = setPath(merged, path, parseValue(override[eq+1:]))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to apply override %q", override)
+		}
+	}
+	out, err := yaml.Marshal(merged)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode config")
+	}
+	return out, nil
+}
+
+// mergeValues returns overlay merged over base
+func mergeValues(base, overlay interface{}) interface{} {
+	b, ok := base.(map[string]interface{})
+	if !ok {
+		return overlay
+	}
+	o, ok := overlay.(map[string]interface{})
+	if !ok {
+		return overlay
+	}
+	for k, v := range o {
+		b[k] = mergeValues(b[k], v)
+	}
+	return b
+}
+
+// pathElement is a field name or list index in an override path
+type pathElement struct {
+	field string
+	index int
+	// isIndex is true if this element is a list index
+	isIndex bool
+}
+
+// parsePath parses an override path, e.g. nodes[1].image
+func parsePath(path string) ([]pathElement, error) {
+	elements := []pathElement{}
+	i := 0
+	for {
+		// read a field name up to the next unescaped . or [
+		var field strings.Builder
+		for ; i < len(path) && path[i] != '.' && path[i] != '['; i++ {
+			if path[i] == '\\' {
+				i++
+				if i == len(path) {
+					return nil, errors.New("trailing backslash")
+				}
+			}
+			field.WriteByte(path[i])
+		}
+		if field.Len() == 0 {
+			return nil, errors.New("empty field name")
+		}
+		elements = append(elements, pathElement{field: field.String()})
+		// read any list indexes following the field
+		for i < len(path) && path[i] == '[' {
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, errors.New("missing ]")
+			}
+			index, err := strconv.Atoi(path[i+1 : i+end])
+			if err != nil || index < 0 {
+				return nil, errors.Errorf("invalid list index %q", path[i+1:i+end])
+			}
+			elements = append(elements, pathElement{index: index, isIndex: true})
+			i += end + 1
+		}
+		if i == len(path) {
+			return elements, nil
+		}
+		if path[i] != '.' {
+			return nil, errors.Errorf("expected . before %q", path[i:])
+		}
+		i++
+	}
+}
+
+// setPath sets the value at path within v, creating missing fields
+func setPath(v interface{}, path []pathElement, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	e := path[0]
+	if e.isIndex {
+		var list []interface{}
+		if v != nil {
+			l, ok := v.([]interface{})
+			if !ok {
+				return nil, errors.Errorf("cannot index [%d] into a non-list value", e.index)
+			}
+			list = l
+		}
+		if e.index > len(list) {
+			return nil, errors.Errorf("index [%d] is out of range for a list of length %d", e.index, len(list))
+		}
+		if e.index == len(list) {
+			list = append(list, nil)
+		}
+		item, err := setPath(list[e.index], path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		list[e.index] = item
+		return list, nil
+	}
+	m := map[string]interface{}{}
+	if v != nil {
+		existing, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("cannot set field %q of a non-mapping value", e.field)
+		}
+		m = existing
+	}
+	field, err := setPath(m[e.field], path[1:], value)
+	if err != nil {
+		return nil, err
+	}
+	m[e.field] = field
+	return m, nil
+}
+
+// parseValue parses an override value as YAML, e.g. so that
+// --set networking.disableDefaultCNI=true sets a boolean, values that are
+// not valid YAML are used as plain strings
+func parseValue(value string) interface{} {
+	var v interface{}
+	if err := yaml.Unmarshal([]byte(value), &v); err != nil {
+		return value
+	}
+	return v
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encoding
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestApplyOverrides(t *testing.T) {
+	t.Parallel()
+	const base = `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+nodes:
+- role: control-plane
+- role: worker
+  labels:
+    tier: a
+`
+	cases := []struct {
+		Name        string
+		Raw         string
+		Overrides   []string
+		Expected    string
+		ExpectError bool
+	}{
+		{
+			Name:     "no overrides",
+			Raw:      base,
+			Expected: base,
+		},
+		{
+			Name:      "no config",
+			Overrides: []string{"name=ci", "networking.ipFamily=ipv6"},
+			Expected: `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+name: ci
+networking:
+  ipFamily: ipv6
+`,
+		},
+		{
+			Name:      "list index and typed values",
+			Raw:       base,
+			Overrides: []string{"nodes[1].image=kindest/node:v1.20.0", "networking.disableDefaultCNI=true", "networking.apiServerPort=6443"},
+			Expected: `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+networking:
+  disableDefaultCNI: true
+  apiServerPort: 6443
+nodes:
+- role: control-plane
+- role: worker
+  image: kindest/node:v1.20.0
+  labels:
+    tier: a
+`,
+		},
+		{
+			Name:      "append to list and escaped dots",
+			Raw:       base,
+			Overrides: []string{"nodes[2].role=worker", `nodes[2].labels.topology\.kubernetes\.io/zone=b`},
+			Expected: base + `- role: worker
+  labels:
+    topology.kubernetes.io/zone: b
+`,
+		},
+		{
+			Name: "documents are merged in order",
+			Raw: base + `---
+name: merged
+nodes:
+- role: control-plane
+`,
+			Expected: `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+name: merged
+nodes:
+- role: control-plane
+`,
+		},
+		{
+			Name:        "index out of range",
+			Raw:         base,
+			Overrides:   []string{"nodes[3].role=worker"},
+			ExpectError: true,
+		},
+		{
+			Name:        "field of a scalar",
+			Raw:         base,
+			Overrides:   []string{"nodes[0].role.name=worker"},
+			ExpectError: true,
+		},
+		{
+			Name:        "missing value",
+			Raw:         base,
+			Overrides:   []string{"name"},
+			ExpectError: true,
+		},
+		{
+			Name:        "empty field name",
+			Raw:         base,
+			Overrides:   []string{"networking..ipFamily=ipv6"},
+			ExpectError: true,
+		},
+		{
+			Name:        "invalid index",
+			Raw:         base,
+			Overrides:   []string{"nodes[-1].role=worker"},
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			out, err := ApplyOverrides([]byte(tc.Raw), tc.Overrides)
+			assert.ExpectError(t, tc.ExpectError, err)
+			if err != nil {
+				return
+			}
+			actual, err := Parse(out)
+			if err != nil {
+				t.Fatalf("failed to parse result: %v\n%s", err, out)
+			}
+			expected, err := Parse([]byte(tc.Expected))
+			if err != nil {
+				t.Fatalf("failed to parse expected config: %v", err)
+			}
+			assert.DeepEqual(t, expected, actual)
+		})
+	}
+}
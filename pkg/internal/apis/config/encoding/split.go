@@ -0,0 +1,59 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encoding
+
+import (
+	"bytes"
+	"io"
+
+	yaml "gopkg.in/yaml.v3"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// SplitDocuments splits a YAML stream into raw documents, skipping empty
+// documents, so that each may be passed to Parse
+func SplitDocuments(raw []byte) ([][]byte, error) {
+	docs := [][]byte{}
+	d := yaml.NewDecoder(bytes.NewReader(raw))
+	for {
+		var node yaml.Node
+		if err := d.Decode(&node); err == io.EOF {
+			return docs, nil
+		} else if err != nil {
+			return nil, errors.Wrap(err, "failed to split YAML documents")
+		}
+		if isEmptyDocument(&node) {
+			continue
+		}
+		doc, err := yaml.Marshal(&node)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to re-encode YAML document")
+		}
+		docs = append(docs, doc)
+	}
+}
+
+// isEmptyDocument returns true for a document with no content, e.g.
+// between two consecutive "---" separators
+func isEmptyDocument(node *yaml.Node) bool {
+	if len(node.Content) == 0 {
+		return true
+	}
+	c := node.Content[0]
+	return c.Kind == yaml.ScalarNode && c.Tag == "!!null"
+}
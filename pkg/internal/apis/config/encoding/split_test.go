@@ -0,0 +1,60 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encoding
+
+import (
+	"testing"
+)
+
+func TestSplitDocuments(t *testing.T) {
+	t.Parallel()
+	raw := []byte(`kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+name: a
+---
+---
+kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+name: b
+nodes:
+- role: control-plane
+- role: worker
+`)
+	docs, err := SplitDocuments(raw)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(docs) != 2 {
+		t.Fatalf("expected 2 documents, got %d", len(docs))
+	}
+	for i, name := range []string{"a", "b"} {
+		cfg, err := Parse(docs[i])
+		if err != nil {
+			t.Fatalf("failed to parse document %d: %v", i, err)
+		}
+		if cfg.Name != name {
+			t.Errorf("expected document %d to have name %q, got %q", i, name, cfg.Name)
+		}
+	}
+}
+
+func TestSplitDocumentsInvalid(t *testing.T) {
+	t.Parallel()
+	if _, err := SplitDocuments([]byte("a: [")); err == nil {
+		t.Errorf("expected an error for invalid YAML")
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// nodeNameTemplatePlaceholderRE matches the {{placeholder}}s of NodeNameTemplate
+var nodeNameTemplatePlaceholderRE = regexp.MustCompile(`{{\s*([^}]*?)\s*}}`)
+
+// nodeNameRE matches names that are valid as container names, hostnames
+// and Kubernetes node names
+var nodeNameRE = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+
+// machineIDRE matches valid /etc/machine-id contents, see machine-id(5)
+var machineIDRE = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// NodeNames returns the container name of each of c.Nodes, in order.
+// Node.Name is used if set, otherwise the name is generated from
+// c.NodeNameTemplate or the default "<cluster>-<role><index>" scheme,
+// where the index is omitted for the first node of each role.
+func NodeNames(c *Cluster) []string {
+	names := make([]string, len(c.Nodes))
+	counts := map[NodeRole]int{}
+	for i, n := range c.Nodes {
+		// explicitly named nodes still count, so adding a name to one node
+		// does not rename the others
+		counts[n.Role]++
+		if n.Name != "" {
+			names[i] = n.Name
+		} else {
+			names[i] = generateNodeName(c, n.Role, counts[n.Role])
+		}
+	}
+	return names
+}
+
+// UnusedNodeName returns the first name generated for a node of role, as if
+// appending nodes of role to c, that inUse returns false for
+func UnusedNodeName(c *Cluster, role NodeRole, inUse func(name string) bool) string {
+	first := generateNodeName(c, role, 1)
+	for index := 1; ; index++ {
+		name := generateNodeName(c, role, index)
+		// a template without {{index}} generates the same name each time
+		if index > 1 && name == first {
+			name = first + strconv.Itoa(index)
+		}
+		if !inUse(name) {
+			return name
+		}
+	}
+}
+
+// generateNodeName returns the name of the index'th node of role,
+// counting from 1, that has no explicit name
+func generateNodeName(c *Cluster, role NodeRole, index int) string {
+	if c.NodeNameTemplate != "" {
+		return expandNodeNameTemplate(c.NodeNameTemplate, c.Name, role, index)
+	}
+	suffix := ""
+	if index > 1 {
+		suffix = strconv.Itoa(index)
+	}
+	return fmt.Sprintf("%s-%s%s", c.Name, role, suffix)
+}
+
+func expandNodeNameTemplate(template, cluster string, role NodeRole, index int) string {
+	return nodeNameTemplatePlaceholderRE.ReplaceAllStringFunc(template, func(match string) string {
+		switch nodeNameTemplatePlaceholderRE.FindStringSubmatch(match)[1] {
+		case "cluster":
+			return cluster
+		case "role":
+			return string(role)
+		case "index":
+			return strconv.Itoa(index)
+		}
+		return match
+	})
+}
+
+// validateNodeNames checks the user controlled node names are usable and unique
+func validateNodeNames(c *Cluster) []error {
+	errs := []error{}
+	for _, m := range nodeNameTemplatePlaceholderRE.FindAllStringSubmatch(c.NodeNameTemplate, -1) {
+		switch m[1] {
+		case "cluster", "role", "index":
+		default:
+			errs = append(errs, errors.Errorf("invalid nodeNameTemplate: unknown placeholder %q, expected one of {{cluster}}, {{role}}, {{index}}", m[0]))
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	// the implicit load balancer, test OIDC issuer, expose proxy and disk are
+	// named like a node with their own role
+	seen := map[string]bool{
+		c.Name + "-" + constants.ExternalLoadBalancerNodeRoleValue: true,
+		c.Name + "-" + constants.OIDCIssuerNodeRoleValue:           true,
+		c.Name + "-" + constants.ExposeProxyNodeRoleValue:          true,
+		c.Name + "-" + constants.DiskNodeRoleValue:                 true,
+	}
+	for i, name := range NodeNames(c) {
+		custom := c.Nodes[i].Name != "" || c.NodeNameTemplate != ""
+		if custom && (len(name) > 63 || !nodeNameRE.MatchString(name)) {
+			errs = append(errs, errors.Errorf("invalid name for node %d: %q must be at most 63 lowercase alphanumeric characters, '-' or '.'", i, name))
+		}
+		if seen[name] {
+			errs = append(errs, errors.Errorf("duplicate node name: %q", name))
+		}
+		seen[name] = true
+	}
+
+	// hostnames become Kubernetes node names, which must also be unique,
+	// duplicate names without a hostname override are reported above
+	hostnames := map[string]bool{}
+	names := NodeNames(c)
+	for i, name := range names {
+		if c.Nodes[i].Hostname == "" {
+			hostnames[name] = true
+		}
+	}
+	for i := range names {
+		hostname := c.Nodes[i].Hostname
+		if hostname == "" {
+			continue
+		}
+		if hostnames[hostname] {
+			errs = append(errs, errors.Errorf("duplicate node hostname: %q", hostname))
+		}
+		hostnames[hostname] = true
+	}
+	return errs
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestNodeNames(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name     string
+		Template string
+		Nodes    []Node
+		Expected []string
+	}{
+		{
+			Name: "default names",
+			Nodes: []Node{
+				{Role: ControlPlaneRole}, {Role: WorkerRole}, {Role: WorkerRole},
+			},
+			Expected: []string{"kind-control-plane", "kind-worker", "kind-worker2"},
+		},
+		{
+			Name:     "template",
+			Template: "{{cluster}}-{{ role }}-{{index}}",
+			Nodes: []Node{
+				{Role: ControlPlaneRole}, {Role: WorkerRole}, {Role: WorkerRole},
+			},
+			Expected: []string{"kind-control-plane-1", "kind-worker-1", "kind-worker-2"},
+		},
+		{
+			Name: "explicit names are kept and still counted",
+			Nodes: []Node{
+				{Role: ControlPlaneRole}, {Role: WorkerRole, Name: "ingress"}, {Role: WorkerRole},
+			},
+			Expected: []string{"kind-control-plane", "ingress", "kind-worker2"},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			c := &Cluster{Name: "kind", NodeNameTemplate: tc.Template, Nodes: tc.Nodes}
+			assert.DeepEqual(t, tc.Expected, NodeNames(c))
+		})
+	}
+}
+
+func TestUnusedNodeName(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name     string
+		Template string
+		Role     NodeRole
+		InUse    []string
+		Expected string
+	}{
+		{
+			Name:     "first unused default name",
+			Role:     WorkerRole,
+			InUse:    []string{"kind-worker", "kind-worker2"},
+			Expected: "kind-worker3",
+		},
+		{
+			Name:     "gaps are reused",
+			Role:     ControlPlaneRole,
+			InUse:    []string{"kind-control-plane", "kind-control-plane3"},
+			Expected: "kind-control-plane2",
+		},
+		{
+			Name:     "template",
+			Template: "{{cluster}}-{{role}}-{{index}}",
+			Role:     WorkerRole,
+			InUse:    []string{"kind-worker-1"},
+			Expected: "kind-worker-2",
+		},
+		{
+			Name:     "template without index",
+			Template: "{{cluster}}-{{role}}",
+			Role:     WorkerRole,
+			InUse:    []string{"kind-worker"},
+			Expected: "kind-worker2",
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			c := &Cluster{Name: "kind", NodeNameTemplate: tc.Template}
+			inUse := func(name string) bool {
+				for _, n := range tc.InUse {
+					if n == name {
+						return true
+					}
+				}
+				return false
+			}
+			assert.StringEqual(t, tc.Expected, UnusedNodeName(c, tc.Role, inUse))
+		})
+	}
+}
+
+func TestValidateNodeNames(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name         string
+		Template     string
+		Nodes        []Node
+		ExpectErrors int
+	}{
+		{
+			Name:     "valid",
+			Template: "{{cluster}}-{{role}}-{{index}}",
+			Nodes:    []Node{{Role: ControlPlaneRole}, {Role: WorkerRole, Name: "edge.example"}},
+		},
+		{
+			Name:         "unknown placeholder",
+			Template:     "{{cluster}}-{{zone}}",
+			Nodes:        []Node{{Role: ControlPlaneRole}},
+			ExpectErrors: 1,
+		},
+		{
+			Name:         "duplicate from template",
+			Template:     "{{cluster}}-{{role}}",
+			Nodes:        []Node{{Role: WorkerRole}, {Role: WorkerRole}},
+			ExpectErrors: 1,
+		},
+		{
+			Name:         "duplicate hostname",
+			Nodes:        []Node{{Role: ControlPlaneRole, Hostname: "node"}, {Role: WorkerRole, Hostname: "node"}},
+			ExpectErrors: 1,
+		},
+		{
+			Name:         "invalid and load balancer names",
+			Nodes:        []Node{{Role: ControlPlaneRole, Name: "Bad_Name"}, {Role: WorkerRole, Name: "kind-external-load-balancer"}},
+			ExpectErrors: 2,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			c := &Cluster{Name: "kind", NodeNameTemplate: tc.Template, Nodes: tc.Nodes}
+			if errs := validateNodeNames(c); len(errs) != tc.ExpectErrors {
+				t.Errorf("expected %d errors, got %v", tc.ExpectErrors, errs)
+			}
+		})
+	}
+}
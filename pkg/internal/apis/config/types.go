@@ -28,6 +28,12 @@ type Cluster struct {
 	// control plane load balancer will be provisioned implicitly
 	Nodes []Node
 
+	// NodeNameTemplate generates the names of nodes without a name, e.g.
+	// "{{cluster}}-{{role}}-{{index}}", where {{index}} counts nodes of the same
+	// role from 1. If unset nodes are named like "kind-control-plane" and
+	// "kind-worker2".
+	NodeNameTemplate string
+
 	/* Advanced fields */
 
 	// Networking contains cluster wide network settings
@@ -39,6 +45,29 @@ type Cluster struct {
 	// https://kubernetes.io/docs/reference/command-line-tools-reference/feature-gates/
 	FeatureGates map[string]bool
 
+	// ComponentFeatureGates contains feature gates for individual Kubernetes
+	// components, these are merged over FeatureGates for that component only.
+	ComponentFeatureGates ComponentFeatureGates
+
+	// RuntimeConfig is passed to kube-apiserver as --runtime-config, it enables
+	// or disables API group versions, e.g. `"api/alpha": "true"` or
+	// `"batch/v2alpha1": "false"`.
+	//
+	// https://kubernetes.io/docs/reference/command-line-tools-reference/kube-apiserver/
+	RuntimeConfig map[string]string
+
+	// AdmissionConfiguration is the path to an AdmissionConfiguration file on the
+	// host, passed to the API server as --admission-control-config-file. Its
+	// directory is mounted read-only into the API server, so files it references
+	// relative to it are available too.
+	AdmissionConfiguration string
+
+	// AuthorizationConfiguration is the path to a structured AuthorizationConfiguration
+	// file on the host, passed to the API server as --authorization-config
+	// instead of --authorization-mode. Like AdmissionConfiguration its directory
+	// is mounted into the API server. This requires Kubernetes v1.30 or later.
+	AuthorizationConfiguration string
+
 	// KubeadmConfigPatches are applied to the generated kubeadm config as
 	// strategic merge patches to `kustomize build` internally
 	// https://github.com/kubernetes/community/blob/a9cf5c8f3380bb52ebe57b1e2dbdec136d8dd484/contributors/devel/sig-api-machinery/strategic-merge-patch.md
@@ -49,6 +78,11 @@ type Cluster struct {
 	// as patchesJson6902 to `kustomize build`
 	KubeadmConfigPatchesJSON6902 []PatchJSON6902
 
+	// TargetedKubeadmConfigPatches are merge patches like KubeadmConfigPatches
+	// that only apply to the nodes and component selected by their Target.
+	// They are applied after KubeadmConfigPatches and before the node-level patches.
+	TargetedKubeadmConfigPatches []TargetedPatch
+
 	// ContainerdConfigPatches are applied to every node's containerd config
 	// in the order listed.
 	// These should be toml stringsto be applied as merge patches
@@ -58,6 +92,99 @@ type Cluster struct {
 	// in the order listed.
 	// These should be YAML or JSON formatting RFC 6902 JSON patches
 	ContainerdConfigPatchesJSON6902 []string
+
+	// GPUDevicePlugin deploys the NVIDIA device plugin when any node requests
+	// GPUs, so that pods may request nvidia.com/gpu resources.
+	GPUDevicePlugin bool
+
+	// Presets are optional bundles of components installed after the CNI
+	Presets []Preset
+
+	// ContainerLogs configures container log rotation on all nodes
+	ContainerLogs ContainerLogs
+
+	// ImageGC configures kubelet image garbage collection on all nodes
+	ImageGC ImageGC
+
+	// KubeletServerTLSBootstrap has kubelets request serving certificates signed
+	// by the cluster CA instead of self-signing them, kind approves these during
+	// cluster creation. This allows e.g. metrics-server to verify kubelets.
+	KubeletServerTLSBootstrap bool
+
+	// Storage configures the default storage provisioner and any additional
+	// StorageClasses installed while creating the cluster
+	Storage Storage
+
+	// ControlPlaneMode selects which control plane components are run,
+	// APIServerOnlyMode runs only etcd and the API server on a single node,
+	// for testing controllers and webhooks against a real API server
+	// Defaults to FullControlPlaneMode
+	ControlPlaneMode ControlPlaneMode
+
+	// ExternalControlPlane configures the nodes to join an existing control plane
+	// that is not managed by kind, e.g. another cluster, a VM or a cloud cluster.
+	// If set, all nodes must be workers.
+	ExternalControlPlane ExternalControlPlane
+
+	// Images overrides the infrastructure images kind uses, e.g. to redirect
+	// them to a mirrored registry in air-gapped environments
+	Images Images
+
+	// RestartPolicy is the restart policy of the node containers, it selects when
+	// the container runtime restarts them after they exit, see RestartPolicy
+	// Defaults to OnFailureRestartPolicy, restarting them once, such as on host reboot
+	RestartPolicy RestartPolicy
+
+	// ContainerdSnapshotter selects the snapshotter containerd uses on all nodes,
+	// FuseOverlayFSSnapshotter works where overlayfs cannot be nested, e.g. rootless,
+	// StargzSnapshotter lazily pulls eStargz images
+	// Defaults to OverlayFSSnapshotter
+	ContainerdSnapshotter ContainerdSnapshotter
+
+	// ContainerdMetrics enables containerd's Prometheus metrics endpoint on all
+	// nodes, it is served on port 1338 of each node's IP at /v1/metrics
+	ContainerdMetrics bool
+
+	// LoadBalancer configures the external load balancer kind runs in front of
+	// the API servers of clusters with multiple control plane nodes
+	LoadBalancer LoadBalancer
+
+	// Authentication configures how the API server authenticates requests
+	Authentication Authentication
+
+	// DiskQuota limits the total disk space used by the containerd storage, kubelet
+	// data (including emptyDir volumes) and logs of all of the nodes, e.g. "50Gi".
+	// These are placed on a filesystem of this size in a sparse image file on the
+	// container runtime host, so a runaway workload cannot fill the host disk.
+	// This requires a rootful container runtime on a Linux host or VM.
+	DiskQuota string
+
+	// Inotify raises the host fs.inotify limits while creating the cluster, if
+	// they are lower. These limits are not namespaced, so they are shared by
+	// every node and the host, and cannot be set separately for each node.
+	Inotify Inotify
+
+	// SecurityProfiles installs custom seccomp profiles and AppArmor policies
+	// on the nodes, for testing pod security settings that use them.
+	SecurityProfiles SecurityProfiles
+
+	// Trust configures extra certificate authorities trusted by the nodes, for
+	// TLS-intercepting proxies and private registries with internal CAs.
+	Trust Trust
+
+	// Registries configures how every node connects to the listed image
+	// registries, e.g. over plain HTTP or with a private CA, using containerd
+	// registry host files instead of containerdConfigPatches.
+	Registries []Registry
+
+	// CredentialProviders configures kubelet image credential providers on
+	// every node, to test registry credential helpers such as the ECR, GCR or
+	// ACR credential providers.
+	CredentialProviders CredentialProviders
+
+	// Etcd tunes the local etcd of the control-plane nodes and can place its
+	// data on a dedicated volume, for long-lived clusters and experiments.
+	Etcd Etcd
 }
 
 // Node contains settings for a node in the `kind` Cluster.
@@ -70,6 +197,22 @@ type Node struct {
 	// Defaults to "control-plane"
 	Role NodeRole
 
+	// Name is the node container name, which is also its hostname and Kubernetes
+	// node name. If unset the name is generated, see Cluster.NodeNameTemplate.
+	Name string
+
+	// Hostname overrides the node hostname, which kubeadm also uses as the
+	// Kubernetes node name. Defaults to the node name.
+	Hostname string
+
+	// MachineID sets a stable /etc/machine-id for the node, 32 lowercase hex
+	// characters. If unset a random one is generated each time the node starts.
+	MachineID string
+
+	// Labels are extra Kubernetes labels registered for the node by its kubelet,
+	// e.g. ingress-ready: "true"
+	Labels map[string]string
+
 	// Image is the node image to use when creating this node
 	// If unset a default image will be used, see defaults.Image
 	Image string
@@ -80,10 +223,55 @@ type Node struct {
 	// These may be used to bind a hostPath
 	ExtraMounts []Mount
 
+	// PersistentVolumes are local PersistentVolumes pre-provisioned on this node.
+	// Each path must be within one of ExtraMounts, so that the data persists
+	// across node container restarts.
+	PersistentVolumes []PersistentVolume
+
 	// ExtraPortMappings describes additional port mappings for the node container
 	// binded to a host Port
 	ExtraPortMappings []PortMapping
 
+	// GPUs requests GPU devices for the node container, e.g. "all", "2"
+	// or "device=0,1". This maps to `--gpus` for docker and to CDI device
+	// requests for podman, and requires the NVIDIA container toolkit on the host.
+	GPUs string
+
+	// CgroupNS sets the cgroup namespace mode of the node container, one of
+	// "private" or "host". If unset the container runtime default is used.
+	CgroupNS string
+
+	// CPUSet pins the node container to host CPUs, e.g. "0-3" or "0,2".
+	// This is useful to exercise the kubelet CPU Manager with a static policy.
+	CPUSet string
+
+	// Hugepages requests hugepages for the node, keyed by page size ("2Mi" or
+	// "1Gi") to the number of pages. The pages must be preallocated on the
+	// host, kind checks that enough are free and mounts hugetlbfs into the node.
+	Hugepages map[string]int32
+
+	// Ulimits sets resource limits of the node container by name, e.g. "nofile",
+	// to "soft:hard" or a single value for both, -1 is unlimited.
+	Ulimits map[string]string
+
+	// Swap adds a swapfile to the node and configures the kubelet swapBehavior,
+	// to exercise the Kubernetes swap support. Swap is not namespaced, so the
+	// swapfile is used by the whole host until the node is deleted.
+	Swap Swap
+
+	// ClockOffset shifts the wall clock of the kubelet and containerd on the
+	// node by a duration such as "720h" or "-24h". This uses libfaketime from
+	// the node image and is intended for testing certificate rotation and
+	// token expiry. Pods and commands run on the node, e.g. kubeadm and
+	// kubectl, keep the real clock.
+	ClockOffset string
+
+	// StaticPodsHostPath is a host directory of static pod manifests for the node,
+	// in addition to those generated by kubeadm. The directory is mounted into the
+	// node and its *.yaml, *.yml and *.json files are linked into the kubelet
+	// static pod path, following files added or removed later.
+	StaticPodsHostPath string
+
 	// KubeadmConfigPatches are applied to the generated kubeadm config as
 	// strategic merge patches to `kustomize build` internally
 	// https://github.com/kubernetes/community/blob/a9cf5c8f3380bb52ebe57b1e2dbdec136d8dd484/contributors/devel/sig-api-machinery/strategic-merge-patch.md
@@ -121,10 +309,15 @@ type Networking struct {
 	// is restarted it will be randomized. Leave this unset to allow kind to pick it.
 	APIServerPort int32
 	// APIServerAddress is the listen address on the host for the Kubernetes
-	// API Server. This should be an IP address.
+	// API Server. This should be an IP address, or `iface:<name>` to listen on
+	// the address of that host network interface, resolved at creation time.
 	//
 	// Defaults to 127.0.0.1
 	APIServerAddress string
+	// AdditionalAPIServerAddresses are further listen addresses on the host for
+	// the Kubernetes API Server, on the same port as APIServerAddress.
+	// Like APIServerAddress each entry is an IP address or `iface:<name>`.
+	AdditionalAPIServerAddresses []string
 	// PodSubnet is the CIDR used for pod IPs
 	// kind will select a default if unspecified
 	PodSubnet string
@@ -134,7 +327,8 @@ type Networking struct {
 	// If DisableDefaultCNI is true, kind will not install the default CNI setup.
 	// Instead the user should install their own CNI after creating the cluster.
 	DisableDefaultCNI bool
-	// KubeProxyMode defines if kube-proxy should operate in iptables or ipvs mode
+	// KubeProxyMode defines if kube-proxy should operate in iptables or ipvs mode,
+	// or "none" to not deploy kube-proxy at all which requires DisableDefaultCNI
 	KubeProxyMode ProxyMode
 }
 
@@ -156,6 +350,373 @@ const (
 	IPTablesMode ProxyMode = "iptables"
 	// IPVSMode sets ProxyMode to iptables
 	IPVSMode ProxyMode = "ipvs"
+	// NoneProxyMode disables kube-proxy, e.g. for CNIs that replace it
+	NoneProxyMode ProxyMode = "none"
+)
+
+// ComponentFeatureGates configures feature gates for individual Kubernetes
+// components, each entry takes precedence over the cluster wide FeatureGates
+type ComponentFeatureGates struct {
+	APIServer         map[string]bool
+	ControllerManager map[string]bool
+	Scheduler         map[string]bool
+	Kubelet           map[string]bool
+	KubeProxy         map[string]bool
+}
+
+// ImageGC configures kubelet image garbage collection on the nodes
+type ImageGC struct {
+	// HighThresholdPercent is kubelet's imageGCHighThresholdPercent,
+	// zero means 100, disabling image garbage collection
+	HighThresholdPercent int32
+	// LowThresholdPercent is kubelet's imageGCLowThresholdPercent,
+	// zero means the kubelet default
+	LowThresholdPercent int32
+	// MinimumAge is kubelet's imageMinimumGCAge, empty means the kubelet default
+	MinimumAge string
+}
+
+// ContainerLogs configures container log rotation on the nodes
+type ContainerLogs struct {
+	// MaxSize is kubelet's containerLogMaxSize
+	MaxSize string
+	// MaxFiles is kubelet's containerLogMaxFiles
+	MaxFiles int32
+	// MaxLineSize is containerd's max_container_log_line_size,
+	// zero means the containerd default
+	MaxLineSize int32
+}
+
+// Storage configures the storage provisioner installed by kind
+type Storage struct {
+	// Provisioner is "local-path" (the default) to install the node image's
+	// local path provisioner, "none" to install no provisioner, or the
+	// http(s) URL of a manifest to apply instead, e.g. a CSI driver
+	Provisioner StorageProvisioner
+
+	// ExtraStorageClasses are created after the provisioner is installed
+	ExtraStorageClasses []StorageClass
+}
+
+// Images are overrides for the images kind uses besides the node image,
+// an empty value uses the default
+type Images struct {
+	// Repository is the registry the control plane component images are
+	// pulled from, this is the kubeadm imageRepository and also applies to
+	// CoreDNS and etcd unless these are set
+	Repository string
+
+	// Pause is the pod sandbox image containerd uses
+	Pause string
+
+	// CoreDNS is the CoreDNS image, e.g. "registry.example/coredns:1.6.7"
+	// kubeadm only allows overriding the repository and tag, the image
+	// name must be coredns
+	CoreDNS string
+
+	// Etcd is the etcd image, e.g. "registry.example/etcd:3.4.3-0"
+	// As with CoreDNS, the image name must be etcd
+	Etcd string
+
+	// LoadBalancer is the load balancer image used with multiple
+	// control-plane nodes
+	LoadBalancer string
+
+	// Overrides maps the other images kind uses, as listed by
+	// `kind get required-images`, to the image to use instead, e.g. for
+	// kindnetd, the local-path provisioner and its helper, the presets and
+	// the OIDC test issuer
+	Overrides map[string]string
+}
+
+// LoadBalancer configures the external load balancer
+type LoadBalancer struct {
+	// Implementation selects the load balancer, see LoadBalancerImplementation
+	// Images.LoadBalancer overrides the image for the implementation
+	// Defaults to HAProxyLoadBalancer
+	Implementation LoadBalancerImplementation
+	// HealthCheck configures checking the control plane nodes
+	HealthCheck LoadBalancerHealthCheck
+	// ExtraFrontends are additional TCP ports the load balancer forwards to
+	// the control plane nodes, e.g. for services running on all of them
+	ExtraFrontends []LoadBalancerFrontend
+}
+
+// LoadBalancerImplementation is the software used for the load balancer
+type LoadBalancerImplementation string
+
+const (
+	// HAProxyLoadBalancer actively health checks the API servers
+	HAProxyLoadBalancer LoadBalancerImplementation = "haproxy"
+	// NginxLoadBalancer only passively checks the backends, taking a
+	// backend out for the Interval after Fall failed connections
+	NginxLoadBalancer LoadBalancerImplementation = "nginx"
+)
+
+// LoadBalancerHealthCheck configures checking the load balancer backends
+type LoadBalancerHealthCheck struct {
+	// Interval is the time between checks, e.g. "2s", the default
+	Interval string
+	// Rise is the number of passed checks before a backend is used again
+	// Defaults to 2, only used by HAProxyLoadBalancer
+	Rise int32
+	// Fall is the number of failed checks before a backend is taken out
+	// Defaults to 3
+	Fall int32
+}
+
+// LoadBalancerFrontend is an additional TCP port forwarded by the load
+// balancer to all control plane nodes
+type LoadBalancerFrontend struct {
+	// Name identifies the frontend, e.g. "konnectivity"
+	Name string
+	// Port is the port the load balancer listens on in the cluster network
+	Port int32
+	// BackendPort is the port on the control plane nodes, defaults to Port
+	BackendPort int32
+	// HostPort optionally publishes Port on the host at
+	// Networking.APIServerAddress, zero does not publish it
+	HostPort int32
+}
+
+// Authentication configures how the API server authenticates requests
+type Authentication struct {
+	// OIDC configures authenticating OpenID Connect ID tokens
+	OIDC OIDC
+}
+
+// OIDC configures the API server --oidc-* flags, it is enabled when
+// IssuerURL is set or TestIssuer is true
+type OIDC struct {
+	// IssuerURL is the https URL of the OpenID Connect provider, it must be
+	// reachable from the control plane nodes
+	IssuerURL string
+	// ClientID is the audience ID tokens must be issued for
+	ClientID string
+	// CA is the PEM encoded CA certificate for the provider, if unset the
+	// node's system roots are used
+	CA string
+	// UsernameClaim is the claim used as the user name, the API server
+	// defaults to "sub"
+	UsernameClaim string
+	// UsernamePrefix is prepended to user names
+	UsernamePrefix string
+	// GroupsClaim is the claim used as the user's groups
+	GroupsClaim string
+	// GroupsPrefix is prepended to group names
+	GroupsPrefix string
+	// RequiredClaims must be present in ID tokens with these values
+	RequiredClaims map[string]string
+
+	// TestIssuer runs a dex OpenID Connect provider for testing in a container
+	// on the cluster network, IssuerURL and CA must not be set as they are
+	// generated. It has a static user admin@example.com with the password
+	// "password", and the client "kind" with the secret "kind-secret".
+	TestIssuer bool
+}
+
+// Inotify configures the host fs.inotify limits, zero values are unchanged
+type Inotify struct {
+	// MaxUserWatches is the minimum fs.inotify.max_user_watches
+	MaxUserWatches int32
+	// MaxUserInstances is the minimum fs.inotify.max_user_instances
+	MaxUserInstances int32
+}
+
+// Etcd configures the local etcd of the control-plane nodes
+type Etcd struct {
+	// QuotaBackendBytes is the backend size quota, a quantity such as "8Gi",
+	// etcd defaults to 2Gi
+	QuotaBackendBytes string
+	// HeartbeatInterval is the raft heartbeat interval, a duration such as
+	// "250ms", etcd defaults to 100ms
+	HeartbeatInterval string
+	// ElectionTimeout is the raft election timeout, a duration such as
+	// "2500ms", etcd defaults to 1s. It must be at least five times the
+	// heartbeat interval.
+	ElectionTimeout string
+	// DataVolume places the etcd data dir on a named volume of the node
+	// runtime, or on a host directory if it is an absolute path. Each
+	// control-plane node gets its own volume or subdirectory, named after the
+	// node, e.g. "etcd-kind-control-plane" or "/data/etcd/kind-control-plane".
+	// These are kept when the cluster is deleted and must be removed before
+	// reusing them for a new cluster.
+	DataVolume string
+}
+
+// CredentialProviders configures the kubelet image credential providers
+type CredentialProviders struct {
+	// BinDir is a host directory with the credential provider binaries, it is
+	// mounted read-only on every node
+	BinDir string
+	// Providers are the credential providers the kubelet runs, in order
+	Providers []CredentialProvider
+}
+
+// CredentialProvider is a kubelet image credential provider, see the kubelet
+// CredentialProviderConfig documentation for the meaning of each field
+type CredentialProvider struct {
+	// Name is the file name of the provider binary in BinDir
+	Name string
+	// MatchImages are the image patterns the provider is used for, e.g.
+	// "*.dkr.ecr.*.amazonaws.com"
+	MatchImages []string
+	// DefaultCacheDuration is how long the kubelet caches the credentials if
+	// the provider does not say, e.g. "12h"
+	DefaultCacheDuration string
+	// Args are passed to the provider binary
+	Args []string
+	// Env are environment variables set for the provider binary
+	Env map[string]string
+}
+
+// Trust configures the certificate authorities the nodes trust
+type Trust struct {
+	// ExtraCAs are host paths of PEM encoded CA certificates, or inline PEM,
+	// installed in every node's system trust store. containerd verifies
+	// registries against the system trust store, so this covers image pulls.
+	ExtraCAs []string
+}
+
+// Registry configures the connection to an image registry
+type Registry struct {
+	// Host is the registry host and optional port as used in image names,
+	// e.g. "registry.local:5000"
+	Host string
+	// PlainHTTP connects to the registry over HTTP instead of HTTPS
+	PlainHTTP bool
+	// InsecureSkipVerify connects over HTTPS without verifying the registry
+	// certificate
+	InsecureSkipVerify bool
+	// CA is a host path of a PEM encoded CA certificate, or inline PEM, used
+	// to verify the registry certificate instead of the system trust store
+	CA string
+}
+
+// SecurityProfiles are the seccomp profiles and AppArmor policies for the nodes
+type SecurityProfiles struct {
+	// SeccompDir is a host directory of seccomp profiles, mounted read-only on
+	// every node at /var/lib/kubelet/seccomp/profiles. Pods use them with e.g.
+	// localhostProfile: profiles/audit.json
+	SeccompDir string
+	// AppArmor are host paths of AppArmor profiles, these are loaded into the
+	// host kernel from a node if the host has AppArmor enabled
+	AppArmor []string
+}
+
+// Swap configures a swapfile for a node, the zero value has no swap
+type Swap struct {
+	// Size of the swapfile, a quantity such as "1Gi"
+	Size string
+	// Behavior is the kubelet memorySwap.swapBehavior, LimitedSwap by default
+	Behavior SwapBehavior
+}
+
+// SwapBehavior is the kubelet memorySwap.swapBehavior
+type SwapBehavior string
+
+const (
+	// LimitedSwapBehavior limits the swap Burstable pods may use by their
+	// memory requests, other pods cannot use swap
+	LimitedSwapBehavior SwapBehavior = "LimitedSwap"
+	// NoSwapBehavior keeps all pods from using swap, only system daemons use it
+	NoSwapBehavior SwapBehavior = "NoSwap"
+)
+
+// ExternalControlPlane is a control plane not managed by kind for the nodes
+// to join, with the values from e.g. `kubeadm token create --print-join-command`
+type ExternalControlPlane struct {
+	// Endpoint is the host:port of the API server, it must be reachable
+	// from the node containers
+	Endpoint string
+
+	// Token is a bootstrap token for joining the control plane
+	Token string
+
+	// CACertHashes are the "sha256:<hex>" hashes of the cluster CA public key
+	// used to verify the control plane, if empty the CA is not verified
+	CACertHashes []string
+}
+
+// ControlPlaneMode selects the control plane components, see ControlPlaneMode
+type ControlPlaneMode string
+
+const (
+	// FullControlPlaneMode runs a complete cluster, this is the default
+	FullControlPlaneMode ControlPlaneMode = "Full"
+	// APIServerOnlyMode runs only etcd and kube-apiserver on a single
+	// control-plane node, with kubeadm generated certificates.
+	// There is no scheduler, controller-manager, CNI or registered Node,
+	// the kubelet only runs the static pods for etcd and the API server.
+	APIServerOnlyMode ControlPlaneMode = "APIServerOnly"
+)
+
+// RestartPolicy selects when node containers are restarted, see RestartPolicy
+type RestartPolicy string
+
+const (
+	// OnFailureRestartPolicy restarts node containers once after they fail,
+	// including when the host or container runtime restarts, this is the default
+	OnFailureRestartPolicy RestartPolicy = "on-failure"
+	// NoRestartPolicy never restarts node containers
+	NoRestartPolicy RestartPolicy = "no"
+	// UnlessStoppedRestartPolicy always restarts node containers unless
+	// they were stopped explicitly
+	UnlessStoppedRestartPolicy RestartPolicy = "unless-stopped"
+	// AlwaysRestartPolicy always restarts node containers
+	AlwaysRestartPolicy RestartPolicy = "always"
+)
+
+// ContainerdSnapshotter selects the containerd snapshotter, see ContainerdSnapshotter
+type ContainerdSnapshotter string
+
+const (
+	// OverlayFSSnapshotter is the kernel overlayfs snapshotter, this is the default
+	OverlayFSSnapshotter ContainerdSnapshotter = "overlayfs"
+	// FuseOverlayFSSnapshotter is the userspace fuse-overlayfs snapshotter,
+	// it requires /dev/fuse on the host
+	FuseOverlayFSSnapshotter ContainerdSnapshotter = "fuse-overlayfs"
+	// StargzSnapshotter is the stargz snapshotter, it lazily pulls eStargz
+	// images and requires /dev/fuse on the host
+	StargzSnapshotter ContainerdSnapshotter = "stargz"
+)
+
+// StorageProvisioner selects the storage provisioner, see Storage
+type StorageProvisioner string
+
+const (
+	// LocalPathProvisioner installs the node image's default storage manifest
+	LocalPathProvisioner StorageProvisioner = "local-path"
+	// NoneProvisioner installs no storage provisioner or StorageClass
+	NoneProvisioner StorageProvisioner = "none"
+)
+
+// StorageClass is a minimal storage.k8s.io/v1 StorageClass
+type StorageClass struct {
+	// Name is the StorageClass name
+	Name string
+	// Provisioner is the name of the volume provisioner, e.g. a CSI driver
+	Provisioner string
+	// Default marks this as the default StorageClass, replacing the
+	// local-path "standard" class as the default
+	Default bool
+	// Parameters are passed to the provisioner
+	Parameters map[string]string
+	// ReclaimPolicy is Delete (the default) or Retain
+	ReclaimPolicy string
+	// VolumeBindingMode is Immediate (the default) or WaitForFirstConsumer
+	VolumeBindingMode string
+}
+
+// Preset is an optional bundle of cluster components
+type Preset string
+
+const (
+	// ObservabilityPreset installs metrics-server and kube-state-metrics
+	ObservabilityPreset Preset = "observability"
+	// NodeProblemDetectorPreset installs node-problem-detector, reporting
+	// kernel problems such as OOM kills, and runtime restarts, on the nodes
+	NodeProblemDetectorPreset Preset = "node-problem-detector"
 )
 
 // PatchJSON6902 represents an inline kustomize json 6902 patch
@@ -165,10 +726,53 @@ type PatchJSON6902 struct {
 	Group   string
 	Version string
 	Kind    string
+
+	// Target optionally selects the nodes and component the patch applies to,
+	// with a component the group, version and kind are implied and the
+	// patch paths are relative to the component
+	Target PatchTarget
 	// Patch should contain the contents of the json patch as a string
 	Patch string
 }
 
+// TargetedPatch is a kubeadm config merge patch for selected nodes and components
+type TargetedPatch struct {
+	// Target selects the nodes and component the patch applies to
+	Target PatchTarget
+	// Patch is the merge patch as an inline yaml blob-string, with a
+	// Target.Component it is relative to the component and `kind` is implied
+	Patch string
+}
+
+// PatchTarget selects the nodes and component a kubeadm config patch
+// applies to, unset fields match all nodes or the whole config
+type PatchTarget struct {
+	// Role selects the nodes with this role
+	Role NodeRole
+	// NodeName selects the node with this name, e.g. "kind-worker2"
+	NodeName string
+	// Component selects the config of a single component, see PatchComponent
+	Component PatchComponent
+}
+
+// PatchComponent is a Kubernetes component configured by the kubeadm config
+type PatchComponent string
+
+const (
+	// APIServerComponent is ClusterConfiguration.apiServer
+	APIServerComponent PatchComponent = "kube-apiserver"
+	// ControllerManagerComponent is ClusterConfiguration.controllerManager
+	ControllerManagerComponent PatchComponent = "kube-controller-manager"
+	// SchedulerComponent is ClusterConfiguration.scheduler
+	SchedulerComponent PatchComponent = "kube-scheduler"
+	// EtcdComponent is ClusterConfiguration.etcd.local
+	EtcdComponent PatchComponent = "etcd"
+	// KubeletComponent is the KubeletConfiguration
+	KubeletComponent PatchComponent = "kubelet"
+	// KubeProxyComponent is the KubeProxyConfiguration
+	KubeProxyComponent PatchComponent = "kube-proxy"
+)
+
 // Mount specifies a host volume to mount into a container.
 // This is a close copy of the upstream cri Mount type
 // see: k8s.io/kubernetes/pkg/kubelet/apis/cri/runtime/v1alpha2
@@ -179,9 +783,10 @@ type PatchJSON6902 struct {
 //  containerPath: /foo
 //  hostPath: /bar
 //  readOnly: true
-//  selinuxRelabel: false
+//  selinuxRelabel: shared
 //  propagation: None
 // Propagation may be one of: None, HostToContainer, Bidirectional
+// SelinuxRelabel may be one of: shared, private, none
 type Mount struct {
 	// Path of the mount within the container.
 	ContainerPath string
@@ -191,12 +796,36 @@ type Mount struct {
 	HostPath string
 	// If set, the mount is read-only.
 	Readonly bool
-	// If set, the mount needs SELinux relabeling.
-	SelinuxRelabel bool
+	// SelinuxRelabel sets the SELinux relabeling of the mount, "shared"
+	// (:z) allows all containers to use the content while "private" (:Z)
+	// restricts it to this node.
+	SelinuxRelabel MountSelinuxRelabel
 	// Requested propagation mode.
 	Propagation MountPropagation
 }
 
+// PersistentVolume is a local PersistentVolume with node affinity for the
+// node it is declared on
+// In yaml this looks like:
+//  name: data-0
+//  path: /data/pv0
+//  capacity: 10Gi
+//  storageClassName: standard
+//  reclaimPolicy: Retain
+type PersistentVolume struct {
+	// Name is the PersistentVolume name, unique within the cluster
+	Name string
+	// Path is the directory backing the volume in the node container, it
+	// must be within the containerPath of one of the node's extraMounts
+	Path string
+	// Capacity is the declared size of the volume, e.g. "10Gi"
+	Capacity string
+	// StorageClassName defaults to "standard", the default StorageClass
+	StorageClassName string
+	// ReclaimPolicy must be Retain (the default), the host data is never deleted
+	ReclaimPolicy string
+}
+
 // PortMapping specifies a host port mapped into a container port.
 // In yaml this looks like:
 //  containerPort: 80
@@ -238,6 +867,21 @@ const (
 	MountPropagationBidirectional MountPropagation = "Bidirectional"
 )
 
+// MountSelinuxRelabel represents an "enum" for SELinux relabeling options,
+// see also Mount.
+type MountSelinuxRelabel string
+
+const (
+	// MountSelinuxRelabelShared relabels the content with a shared label
+	// so that all containers may read and write it (":z").
+	MountSelinuxRelabelShared MountSelinuxRelabel = "shared"
+	// MountSelinuxRelabelPrivate relabels the content with a private
+	// unshared label for this node only (":Z").
+	MountSelinuxRelabelPrivate MountSelinuxRelabel = "private"
+	// MountSelinuxRelabelNone specifies the content should not be relabeled.
+	MountSelinuxRelabelNone MountSelinuxRelabel = "none"
+)
+
 // PortMappingProtocol represents an "enum" for port mapping protocol options,
 // see also PortMapping.
 type PortMappingProtocol string
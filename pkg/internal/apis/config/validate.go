@@ -17,7 +17,17 @@ limitations under the License.
 package config
 
 import (
+	"encoding/pem"
+	"fmt"
 	"net"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"sigs.k8s.io/kind/pkg/errors"
 )
@@ -36,6 +46,23 @@ func (c *Cluster) Validate() error {
 		}
 	}
 
+	// the api server addresses should be IPs or host interface names
+	if err := validateAPIServerAddress(c.Networking.APIServerAddress); err != nil {
+		errs = append(errs, errors.Wrap(err, "invalid apiServerAddress"))
+	}
+	seenAddresses := map[string]bool{c.Networking.APIServerAddress: true}
+	for _, address := range c.Networking.AdditionalAPIServerAddresses {
+		if err := validateAPIServerAddress(address); err != nil {
+			errs = append(errs, errors.Wrap(err, "invalid additionalAPIServerAddresses"))
+		} else if seenAddresses[address] {
+			errs = append(errs, errors.Errorf("invalid additionalAPIServerAddresses: %q is listed more than once", address))
+		}
+		seenAddresses[address] = true
+	}
+	if len(c.Networking.AdditionalAPIServerAddresses) > 0 && c.Networking.APIServerPort < 0 {
+		errs = append(errs, errors.New("additionalAPIServerAddresses require a fixed or kind picked apiServerPort, not -1"))
+	}
+
 	// podSubnet should be a valid CIDR
 	if _, _, err := net.ParseCIDR(c.Networking.PodSubnet); err != nil {
 		errs = append(errs, errors.Wrapf(err, "invalid podSubnet"))
@@ -45,11 +72,60 @@ func (c *Cluster) Validate() error {
 		errs = append(errs, errors.Wrapf(err, "invalid serviceSubnet"))
 	}
 
-	// KubeProxyMode should be iptables or ipvs
-	if c.Networking.KubeProxyMode != IPTablesMode && c.Networking.KubeProxyMode != IPVSMode {
+	// KubeProxyMode should be iptables, ipvs or none
+	switch c.Networking.KubeProxyMode {
+	case IPTablesMode, IPVSMode:
+	case NoneProxyMode:
+		// the default CNI reaches the API server through the kubernetes
+		// service, which doesn't work without kube-proxy
+		if !c.Networking.DisableDefaultCNI {
+			errs = append(errs, errors.Errorf("kubeProxyMode: %s requires disableDefaultCNI: true and a CNI that replaces kube-proxy", NoneProxyMode))
+		}
+	default:
 		errs = append(errs, errors.Errorf("invalid kubeProxyMode: %s", c.Networking.KubeProxyMode))
 	}
 
+	// container log rotation must be accepted by kubelet and containerd
+	if !containerLogMaxSizeRE.MatchString(c.ContainerLogs.MaxSize) {
+		errs = append(errs, errors.Errorf("invalid containerLogs.maxSize: %q", c.ContainerLogs.MaxSize))
+	}
+	if c.ContainerLogs.MaxFiles < 2 {
+		errs = append(errs, errors.Errorf("invalid containerLogs.maxFiles: %d, must be at least 2", c.ContainerLogs.MaxFiles))
+	}
+	if c.ContainerLogs.MaxLineSize < -1 {
+		errs = append(errs, errors.Errorf("invalid containerLogs.maxLineSize: %d", c.ContainerLogs.MaxLineSize))
+	}
+
+	errs = append(errs, validateImageGC(c.ImageGC)...)
+
+	// presets must be known
+	for _, p := range c.Presets {
+		if p != ObservabilityPreset && p != NodeProblemDetectorPreset {
+			errs = append(errs, errors.Errorf("invalid preset: %s", p))
+		}
+	}
+
+	// image overrides must be usable by kubeadm
+	errs = append(errs, validateImages(&c.Images)...)
+
+	// the storage provisioner and storage classes must be well formed
+	errs = append(errs, validateStorage(&c.Storage)...)
+	errs = append(errs, validateLoadBalancer(&c.LoadBalancer)...)
+	errs = append(errs, validateOIDC(&c.Authentication.OIDC)...)
+
+	// feature gates and runtime config must be well formed, they are
+	// checked against the node image's Kubernetes version at create time
+	errs = append(errs, validateFeatureGates("featureGates", c.FeatureGates)...)
+	errs = append(errs, validateFeatureGates("componentFeatureGates.apiServer", c.ComponentFeatureGates.APIServer)...)
+	errs = append(errs, validateFeatureGates("componentFeatureGates.controllerManager", c.ComponentFeatureGates.ControllerManager)...)
+	errs = append(errs, validateFeatureGates("componentFeatureGates.scheduler", c.ComponentFeatureGates.Scheduler)...)
+	errs = append(errs, validateFeatureGates("componentFeatureGates.kubelet", c.ComponentFeatureGates.Kubelet)...)
+	errs = append(errs, validateFeatureGates("componentFeatureGates.kubeProxy", c.ComponentFeatureGates.KubeProxy)...)
+	errs = append(errs, validateRuntimeConfig(c.RuntimeConfig)...)
+
+	// patch targets must select something that exists
+	errs = append(errs, validatePatchTargets(c)...)
+
 	// validate nodes
 	numByRole := make(map[NodeRole]int32)
 	// All nodes in the config should be valid
@@ -66,12 +142,128 @@ func (c *Cluster) Validate() error {
 		}
 	}
 
-	// there must be at least one control plane node
+	// persistent volumes are cluster scoped, so names must be unique
+	pvNames := map[string]bool{}
+	for _, n := range c.Nodes {
+		for _, pv := range n.PersistentVolumes {
+			if pvNames[pv.Name] {
+				errs = append(errs, errors.Errorf("duplicate persistentVolumes name: %q", pv.Name))
+			}
+			pvNames[pv.Name] = true
+		}
+	}
+
+	// node names must be unique, and valid if chosen by the user
+	errs = append(errs, validateNodeNames(c)...)
+
+	// there must be at least one control plane node, unless the nodes
+	// are joining an external control plane
 	numControlPlane, anyControlPlane := numByRole[ControlPlaneRole]
-	if !anyControlPlane || numControlPlane < 1 {
+	if c.ExternalControlPlane.Endpoint != "" {
+		errs = append(errs, validateExternalControlPlane(&c.ExternalControlPlane)...)
+		if anyControlPlane {
+			errs = append(errs, errors.Errorf("externalControlPlane requires that all nodes are %s nodes", string(WorkerRole)))
+		}
+		if c.ControlPlaneMode != FullControlPlaneMode {
+			errs = append(errs, errors.Errorf("externalControlPlane is not supported with controlPlaneMode: %s", c.ControlPlaneMode))
+		}
+		if c.Authentication.OIDC.IssuerURL != "" || c.Authentication.OIDC.TestIssuer {
+			errs = append(errs, errors.New("authentication is not supported with externalControlPlane"))
+		}
+		if c.AdmissionConfiguration != "" || c.AuthorizationConfiguration != "" {
+			errs = append(errs, errors.New("admissionConfiguration and authorizationConfiguration are not supported with externalControlPlane"))
+		}
+		// kind cannot approve the serving certificates on the external control plane
+		if c.KubeletServerTLSBootstrap {
+			errs = append(errs, errors.New("kubeletServerTLSBootstrap is not supported with externalControlPlane"))
+		}
+		if c.Etcd != (Etcd{}) {
+			errs = append(errs, errors.New("etcd is not supported with externalControlPlane"))
+		}
+		// these are applied with the kubeconfig of a local control plane
+		for i := range c.Nodes {
+			if len(c.Nodes[i].PersistentVolumes) > 0 {
+				errs = append(errs, errors.New("persistentVolumes are not supported with externalControlPlane"))
+				break
+			}
+		}
+		for i := range c.Nodes {
+			if c.Nodes[i].ClockOffset != "" {
+				errs = append(errs, errors.New("clockOffset is not supported with externalControlPlane"))
+				break
+			}
+		}
+	} else if !anyControlPlane || numControlPlane < 1 {
 		errs = append(errs, errors.Errorf("must have at least one %s node", string(ControlPlaneRole)))
 	}
 
+	switch c.RestartPolicy {
+	case OnFailureRestartPolicy, NoRestartPolicy, UnlessStoppedRestartPolicy, AlwaysRestartPolicy:
+	default:
+		errs = append(errs, errors.Errorf("invalid restartPolicy: %q", c.RestartPolicy))
+	}
+
+	switch c.ContainerdSnapshotter {
+	case OverlayFSSnapshotter, FuseOverlayFSSnapshotter, StargzSnapshotter:
+	default:
+		errs = append(errs, errors.Errorf("invalid containerdSnapshotter: %q", c.ContainerdSnapshotter))
+	}
+
+	if c.Inotify.MaxUserWatches < 0 || c.Inotify.MaxUserInstances < 0 {
+		errs = append(errs, errors.New("invalid inotify: limits must not be negative"))
+	}
+
+	errs = append(errs, validateCredentialProviders(&c.CredentialProviders)...)
+	errs = append(errs, validateEtcd(&c.Etcd)...)
+
+	// inline CAs must be certificates, files are read when creating the cluster
+	for _, ca := range c.Trust.ExtraCAs {
+		if ca == "" {
+			errs = append(errs, errors.New("invalid trust.extraCAs: entries must not be empty"))
+		} else if IsInlinePEM(ca) && !IsCertificatePEM(ca) {
+			errs = append(errs, errors.New("invalid trust.extraCAs: expected PEM encoded certificates"))
+		}
+	}
+
+	errs = append(errs, validateRegistries(c)...)
+
+	// AppArmor profiles are copied to the node by file name
+	appArmorNames := map[string]bool{}
+	for _, p := range c.SecurityProfiles.AppArmor {
+		name := filepath.Base(p)
+		if p == "" {
+			errs = append(errs, errors.New("invalid securityProfiles.appArmor: paths must not be empty"))
+		} else if appArmorNames[name] {
+			errs = append(errs, errors.Errorf("invalid securityProfiles.appArmor: more than one profile file is named %q", name))
+		}
+		appArmorNames[name] = true
+	}
+
+	if c.DiskQuota != "" {
+		if quota, err := DiskQuotaBytes(c.DiskQuota); err != nil {
+			errs = append(errs, err)
+		} else if quota < MinDiskQuota {
+			errs = append(errs, errors.Errorf("invalid diskQuota: %q must be at least 1Gi", c.DiskQuota))
+		}
+	}
+
+	// the API server only mode has nowhere to schedule anything else
+	switch c.ControlPlaneMode {
+	case FullControlPlaneMode:
+	case APIServerOnlyMode:
+		if len(c.Nodes) != 1 {
+			errs = append(errs, errors.Errorf("controlPlaneMode: %s requires exactly one %s node", APIServerOnlyMode, string(ControlPlaneRole)))
+		}
+		if len(c.Presets) > 0 {
+			errs = append(errs, errors.Errorf("controlPlaneMode: %s does not support presets", APIServerOnlyMode))
+		}
+		if c.KubeletServerTLSBootstrap {
+			errs = append(errs, errors.Errorf("controlPlaneMode: %s runs a standalone kubelet that never registers with the API server, so it can't request a serving certificate and kubeletServerTLSBootstrap is not supported", APIServerOnlyMode))
+		}
+	default:
+		errs = append(errs, errors.Errorf("invalid controlPlaneMode: %s", c.ControlPlaneMode))
+	}
+
 	if len(errs) > 0 {
 		return errors.NewAggregate(errs)
 	}
@@ -96,6 +288,21 @@ func (n *Node) Validate() error {
 		errs = append(errs, errors.New("image is a required field"))
 	}
 
+	// labels are passed to the kubelet as a comma separated list of key=value
+	for k, v := range n.Labels {
+		if k == "" || strings.ContainsAny(k, ",= ") || strings.ContainsAny(v, ",= ") {
+			errs = append(errs, errors.Errorf("invalid label %q=%q", k, v))
+		}
+	}
+
+	// node patches already apply to a single node
+	for i, p := range n.KubeadmConfigPatchesJSON6902 {
+		if p.Target.Role != "" || p.Target.NodeName != "" {
+			errs = append(errs, errors.Errorf("invalid kubeadmConfigPatchesJSON6902[%d]: only target.component may be set in node patches", i))
+		}
+		errs = append(errs, validatePatchComponent(fmt.Sprintf("kubeadmConfigPatchesJSON6902[%d]", i), p)...)
+	}
+
 	// validate extra port forwards
 	for _, mapping := range n.ExtraPortMappings {
 		if err := validatePort(mapping.HostPort); err != nil {
@@ -106,6 +313,69 @@ func (n *Node) Validate() error {
 		}
 	}
 
+	if n.GPUs != "" {
+		if err := validateGPUs(n.GPUs); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	switch n.CgroupNS {
+	case "", "private", "host":
+	default:
+		errs = append(errs, errors.Errorf("invalid cgroupns: %q, expected \"private\" or \"host\"", n.CgroupNS))
+	}
+
+	if n.CPUSet != "" && !cpusetRE.MatchString(n.CPUSet) {
+		errs = append(errs, errors.Errorf("invalid cpuset: %q", n.CPUSet))
+	}
+
+	for size, pages := range n.Hugepages {
+		if _, ok := HugepageSizes[size]; !ok {
+			errs = append(errs, errors.Errorf("invalid hugepages size: %q, expected \"2Mi\" or \"1Gi\"", size))
+		}
+		if pages < 1 {
+			errs = append(errs, errors.Errorf("invalid number of %s hugepages: %d", size, pages))
+		}
+	}
+
+	for name, value := range n.Ulimits {
+		if !ulimitNames[name] {
+			errs = append(errs, errors.Errorf("invalid ulimit name: %q", name))
+		} else if err := validateUlimit(value); err != nil {
+			errs = append(errs, errors.Wrapf(err, "invalid ulimit %s", name))
+		}
+	}
+
+	if n.Swap.Size != "" {
+		if _, err := SwapBytes(n.Swap.Size); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	switch n.Swap.Behavior {
+	case "", LimitedSwapBehavior, NoSwapBehavior:
+	default:
+		errs = append(errs, errors.Errorf("invalid swap.behavior: %q, expected %q or %q", n.Swap.Behavior, LimitedSwapBehavior, NoSwapBehavior))
+	}
+	if n.Swap.Behavior != "" && n.Swap.Size == "" {
+		errs = append(errs, errors.New("swap.behavior requires swap.size"))
+	}
+
+	if n.Hostname != "" && (len(n.Hostname) > 63 || !nodeNameRE.MatchString(n.Hostname)) {
+		errs = append(errs, errors.Errorf("invalid hostname: %q must be at most 63 lowercase alphanumeric characters, '-' or '.'", n.Hostname))
+	}
+
+	if n.MachineID != "" && !machineIDRE.MatchString(n.MachineID) {
+		errs = append(errs, errors.Errorf("invalid machineID: %q, expected 32 lowercase hexadecimal characters", n.MachineID))
+	}
+
+	errs = append(errs, validatePersistentVolumes(n)...)
+
+	if n.ClockOffset != "" {
+		if _, err := time.ParseDuration(n.ClockOffset); err != nil {
+			errs = append(errs, errors.Errorf("invalid clockOffset: %q, expected a duration such as \"720h\"", n.ClockOffset))
+		}
+	}
+
 	if len(errs) > 0 {
 		return errors.NewAggregate(errs)
 	}
@@ -113,6 +383,616 @@ func (n *Node) Validate() error {
 	return nil
 }
 
+// containerLogMaxSizeRE matches the quantities kubelet accepts for
+// containerLogMaxSize, such as "512Ki" or "10Mi"
+var containerLogMaxSizeRE = regexp.MustCompile(`^\d+(Ki|Mi|Gi|K|M|G)?$`)
+
+// cpusetRE matches cpuset lists like "0-3" or "0,2-4"
+var cpusetRE = regexp.MustCompile(`^\d+(-\d+)?(,\d+(-\d+)?)*$`)
+
+// ulimitNames are the resource limits that may be set on node containers
+var ulimitNames = map[string]bool{
+	"core": true, "cpu": true, "data": true, "fsize": true, "locks": true,
+	"memlock": true, "msgqueue": true, "nice": true, "nofile": true,
+	"nproc": true, "rss": true, "rtprio": true, "rttime": true,
+	"sigpending": true, "stack": true,
+}
+
+// ulimitRE matches ulimit values, "soft:hard" or one value for both
+var ulimitRE = regexp.MustCompile(`^(-1|\d+)(:(-1|\d+))?$`)
+
+// validateUlimit checks value is a valid ulimit with soft <= hard, where -1
+// is unlimited
+func validateUlimit(value string) error {
+	m := ulimitRE.FindStringSubmatch(value)
+	if m == nil {
+		return errors.Errorf("%q, expected \"soft:hard\" or a single limit", value)
+	}
+	if m[3] == "" || m[3] == "-1" {
+		return nil
+	}
+	soft, err := strconv.ParseInt(m[1], 10, 64)
+	if err != nil {
+		return errors.Errorf("%q is too large", value)
+	}
+	hard, err := strconv.ParseInt(m[3], 10, 64)
+	if err != nil {
+		return errors.Errorf("%q is too large", value)
+	}
+	if m[1] == "-1" || soft > hard {
+		return errors.Errorf("%q, the soft limit must not be above the hard limit", value)
+	}
+	return nil
+}
+
+// IsInlinePEM returns true if value is inline PEM rather than a file path
+func IsInlinePEM(value string) bool {
+	return strings.Contains(value, "-----BEGIN ")
+}
+
+// IsCertificatePEM returns true if value has one or more PEM encoded
+// certificates and nothing else
+func IsCertificatePEM(value string) bool {
+	rest := []byte(value)
+	found := false
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return false
+		}
+		found = true
+	}
+	return found && strings.TrimSpace(string(rest)) == ""
+}
+
+// MinDiskQuota is the smallest diskQuota, about what a single node needs
+const MinDiskQuota = 1 << 30
+
+// quantityRE matches byte quantities, such as "500Mi" or "50G"
+var quantityRE = regexp.MustCompile(`^(\d+)(Ki|Mi|Gi|Ti|K|M|G|T)?$`)
+
+// DiskQuotaBytes returns the bytes of a diskQuota quantity, the suffixes
+// are as for Kubernetes resource quantities, e.g. "Gi" or "G"
+func DiskQuotaBytes(quota string) (int64, error) {
+	return quantityBytes("diskQuota", quota)
+}
+
+// SwapBytes returns the bytes of a swap.size quantity, as for DiskQuotaBytes
+func SwapBytes(size string) (int64, error) {
+	return quantityBytes("swap.size", size)
+}
+
+// quantityBytes returns the bytes of the quantity value of field
+func quantityBytes(field, value string) (int64, error) {
+	m := quantityRE.FindStringSubmatch(value)
+	if m == nil {
+		return 0, errors.Errorf("invalid %s: %q, expected a quantity such as \"50Gi\"", field, value)
+	}
+	n, err := strconv.ParseInt(m[1], 10, 64)
+	if err != nil {
+		return 0, errors.Errorf("invalid %s: %q", field, value)
+	}
+	multiplier := map[string]int64{
+		"": 1, "K": 1e3, "M": 1e6, "G": 1e9, "T": 1e12,
+		"Ki": 1 << 10, "Mi": 1 << 20, "Gi": 1 << 30, "Ti": 1 << 40,
+	}[m[2]]
+	if n > (1<<63-1)/multiplier {
+		return 0, errors.Errorf("invalid %s: %q is too large", field, value)
+	}
+	return n * multiplier, nil
+}
+
+// HugepageSizes maps the supported hugepage sizes to their kernel (sysfs) names
+var HugepageSizes = map[string]string{
+	"2Mi": "2048kB",
+	"1Gi": "1048576kB",
+}
+
+// validateGPUs checks the gpus field is one of the forms docker accepts
+// for --gpus: "all", a count, or "device=<id>[,<id>...]"
+func validateGPUs(gpus string) error {
+	if gpus == "all" {
+		return nil
+	}
+	if strings.HasPrefix(gpus, "device=") {
+		for _, id := range strings.Split(strings.TrimPrefix(gpus, "device="), ",") {
+			if id == "" {
+				return errors.Errorf("invalid gpus: %q has an empty device ID", gpus)
+			}
+		}
+		return nil
+	}
+	if count, err := strconv.Atoi(gpus); err != nil || count < 1 {
+		return errors.Errorf("invalid gpus: %q, expected \"all\", a count, or \"device=<id>,...\"", gpus)
+	}
+	return nil
+}
+
+// featureGateRE matches Kubernetes feature gate names, e.g. "IPv6DualStack"
+var featureGateRE = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+
+// runtimeConfigKeyRE matches kube-apiserver --runtime-config keys, e.g.
+// "api/alpha", "v1", "batch/v2alpha1" or "batch/v2alpha1/cronjobs"
+var runtimeConfigKeyRE = regexp.MustCompile(`^(api/(all|ga|beta|alpha)|v1|[a-z0-9]([-a-z0-9.]*[a-z0-9])?/v[0-9]+((alpha|beta)[0-9]+)?(/[a-z0-9]+)?)$`)
+
+// validateFeatureGates checks the feature gate names in gates are well formed,
+// field is used to identify where gates came from in errors
+func validateFeatureGates(field string, gates map[string]bool) []error {
+	errs := []error{}
+	for name := range gates {
+		if !featureGateRE.MatchString(name) {
+			errs = append(errs, errors.Errorf("invalid %s: %q is not a valid feature gate name", field, name))
+		}
+	}
+	return errs
+}
+
+// validateRuntimeConfig checks the runtimeConfig entries are of the form
+// kube-apiserver accepts for --runtime-config
+func validateRuntimeConfig(runtimeConfig map[string]string) []error {
+	errs := []error{}
+	for key, value := range runtimeConfig {
+		if !runtimeConfigKeyRE.MatchString(key) {
+			errs = append(errs, errors.Errorf("invalid runtimeConfig: %q is not an API group version", key))
+		}
+		if value != "true" && value != "false" {
+			errs = append(errs, errors.Errorf("invalid runtimeConfig: %q must be \"true\" or \"false\", got %q", key, value))
+		}
+	}
+	return errs
+}
+
+// validatePatchTargets checks the targets of the cluster-level patches
+// are valid and that any node names they select exist
+func validatePatchTargets(c *Cluster) []error {
+	errs := []error{}
+	names := map[string]bool{}
+	for _, name := range NodeNames(c) {
+		names[name] = true
+	}
+	validateTarget := func(field string, t PatchTarget) {
+		switch t.Role {
+		case "", ControlPlaneRole, WorkerRole:
+		default:
+			errs = append(errs, errors.Errorf("invalid %s: %q is not a valid node role", field, t.Role))
+		}
+		if t.NodeName != "" && !names[t.NodeName] {
+			errs = append(errs, errors.Errorf("invalid %s: there is no node named %q", field, t.NodeName))
+		}
+	}
+	for i, p := range c.TargetedKubeadmConfigPatches {
+		field := fmt.Sprintf("targetedKubeadmConfigPatches[%d]", i)
+		validateTarget(field, p.Target)
+		if p.Target.Component != "" {
+			if err := validateComponent(field, p.Target.Component); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if p.Patch == "" {
+			errs = append(errs, errors.Errorf("invalid %s: patch is required", field))
+		}
+	}
+	for i, p := range c.KubeadmConfigPatchesJSON6902 {
+		field := fmt.Sprintf("kubeadmConfigPatchesJSON6902[%d]", i)
+		validateTarget(field, p.Target)
+		errs = append(errs, validatePatchComponent(field, p)...)
+	}
+	return errs
+}
+
+// validatePatchComponent checks a JSON 6902 patch targets a known component,
+// if any, and does not also select a kind, which the component implies
+func validatePatchComponent(field string, p PatchJSON6902) []error {
+	if p.Target.Component == "" {
+		return nil
+	}
+	if err := validateComponent(field, p.Target.Component); err != nil {
+		return []error{err}
+	}
+	if p.Group != "" || p.Version != "" || p.Kind != "" {
+		return []error{errors.Errorf("invalid %s: group, version and kind are implied by target.component", field)}
+	}
+	return nil
+}
+
+func validateComponent(field string, c PatchComponent) error {
+	switch c {
+	case APIServerComponent, ControllerManagerComponent, SchedulerComponent, EtcdComponent, KubeletComponent, KubeProxyComponent:
+		return nil
+	}
+	return errors.Errorf("invalid %s: unknown component %q", field, c)
+}
+
+// kubeadmImageRE matches images kubeadm can be configured with, which must
+// be in a repository and tagged, capturing the image name
+var kubeadmImageRE = regexp.MustCompile(`^[^@\s]+/([a-z0-9._-]+):[\w][\w.-]{0,127}$`)
+
+// overrideImageRE matches an image reference in images.overrides
+var overrideImageRE = regexp.MustCompile(`^[^\s"'$]+$`)
+
+// validateImages checks the CoreDNS and etcd overrides can be expressed as a
+// kubeadm imageRepository and imageTag, and that overrides are image references
+func validateImages(i *Images) []error {
+	errs := []error{}
+	for _, image := range []struct {
+		field, value, name string
+	}{
+		{"images.coreDNS", i.CoreDNS, "coredns"},
+		{"images.etcd", i.Etcd, "etcd"},
+	} {
+		if image.value == "" {
+			continue
+		}
+		if m := kubeadmImageRE.FindStringSubmatch(image.value); m == nil || m[1] != image.name {
+			errs = append(errs, errors.Errorf("invalid %s: %q, expected <repository>/%s:<tag>", image.field, image.value, image.name))
+		}
+	}
+	overridden := make([]string, 0, len(i.Overrides))
+	for image := range i.Overrides {
+		overridden = append(overridden, image)
+	}
+	sort.Strings(overridden)
+	for _, image := range overridden {
+		if !overrideImageRE.MatchString(image) || !overrideImageRE.MatchString(i.Overrides[image]) {
+			errs = append(errs, errors.Errorf("invalid images.overrides: %q: %q, expected image references", image, i.Overrides[image]))
+		}
+	}
+	return errs
+}
+
+// bootstrapTokenRE matches kubeadm bootstrap tokens, e.g. "abcdef.0123456789abcdef"
+var bootstrapTokenRE = regexp.MustCompile(`^[a-z0-9]{6}\.[a-z0-9]{16}$`)
+
+// caCertHashRE matches kubeadm discovery CA public key hashes
+var caCertHashRE = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// validateExternalControlPlane checks the join parameters are of the form
+// kubeadm join accepts
+func validateExternalControlPlane(e *ExternalControlPlane) []error {
+	errs := []error{}
+	if _, port, err := net.SplitHostPort(e.Endpoint); err != nil {
+		errs = append(errs, errors.Wrapf(err, "invalid externalControlPlane.endpoint"))
+	} else if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+		errs = append(errs, errors.Errorf("invalid externalControlPlane.endpoint: invalid port %q", port))
+	}
+	if !bootstrapTokenRE.MatchString(e.Token) {
+		errs = append(errs, errors.Errorf("invalid externalControlPlane.token: expected the form [a-z0-9]{6}.[a-z0-9]{16}"))
+	}
+	for _, h := range e.CACertHashes {
+		if !caCertHashRE.MatchString(h) {
+			errs = append(errs, errors.Errorf("invalid externalControlPlane.caCertHashes: %q, expected sha256:<hex>", h))
+		}
+	}
+	return errs
+}
+
+// validateStorage checks the provisioner is known or a manifest URL, and
+// that the extra storage classes are valid with at most one default
+func validateStorage(s *Storage) []error {
+	errs := []error{}
+	switch s.Provisioner {
+	case LocalPathProvisioner, NoneProvisioner:
+	default:
+		if !strings.HasPrefix(string(s.Provisioner), "https://") && !strings.HasPrefix(string(s.Provisioner), "http://") {
+			errs = append(errs, errors.Errorf("invalid storage.provisioner: %q, expected %s, %s or a manifest URL", s.Provisioner, LocalPathProvisioner, NoneProvisioner))
+		}
+	}
+	seen := make(map[string]bool, len(s.ExtraStorageClasses))
+	defaults := 0
+	for i, class := range s.ExtraStorageClasses {
+		if !nodeNameRE.MatchString(class.Name) || len(class.Name) > 253 {
+			errs = append(errs, errors.Errorf("invalid storage.extraStorageClasses[%d].name: %q", i, class.Name))
+		} else if seen[class.Name] {
+			errs = append(errs, errors.Errorf("invalid storage.extraStorageClasses[%d].name: %q is a duplicate", i, class.Name))
+		}
+		seen[class.Name] = true
+		if class.Provisioner == "" {
+			errs = append(errs, errors.Errorf("invalid storage.extraStorageClasses[%d]: provisioner is required", i))
+		}
+		switch class.ReclaimPolicy {
+		case "", "Delete", "Retain":
+		default:
+			errs = append(errs, errors.Errorf("invalid storage.extraStorageClasses[%d].reclaimPolicy: %q, expected Delete or Retain", i, class.ReclaimPolicy))
+		}
+		switch class.VolumeBindingMode {
+		case "", "Immediate", "WaitForFirstConsumer":
+		default:
+			errs = append(errs, errors.Errorf("invalid storage.extraStorageClasses[%d].volumeBindingMode: %q, expected Immediate or WaitForFirstConsumer", i, class.VolumeBindingMode))
+		}
+		if class.Default {
+			defaults++
+		}
+	}
+	if defaults > 1 {
+		errs = append(errs, errors.Errorf("invalid storage.extraStorageClasses: only one may be the default, got %d", defaults))
+	}
+	return errs
+}
+
+// loadBalancerAPIServerPort is the port of the load balancer's API server
+// frontend, which extra frontends may not use
+const loadBalancerAPIServerPort = 6443
+
+// validateLoadBalancer checks the implementation is known, the health check
+// parameters are positive and the extra frontends have unique names and ports
+func validateLoadBalancer(lb *LoadBalancer) []error {
+	errs := []error{}
+	switch lb.Implementation {
+	case HAProxyLoadBalancer, NginxLoadBalancer:
+	default:
+		errs = append(errs, errors.Errorf("invalid loadBalancer.implementation: %q, expected %s or %s", lb.Implementation, HAProxyLoadBalancer, NginxLoadBalancer))
+	}
+	if d, err := time.ParseDuration(lb.HealthCheck.Interval); err != nil || d <= 0 {
+		errs = append(errs, errors.Errorf("invalid loadBalancer.healthCheck.interval: %q, expected a positive duration such as \"2s\"", lb.HealthCheck.Interval))
+	}
+	if lb.HealthCheck.Rise < 1 {
+		errs = append(errs, errors.Errorf("invalid loadBalancer.healthCheck.rise: %d, must be positive", lb.HealthCheck.Rise))
+	}
+	if lb.HealthCheck.Fall < 1 {
+		errs = append(errs, errors.Errorf("invalid loadBalancer.healthCheck.fall: %d, must be positive", lb.HealthCheck.Fall))
+	}
+	names := map[string]bool{}
+	ports := map[int32]bool{loadBalancerAPIServerPort: true}
+	for i, f := range lb.ExtraFrontends {
+		if !nodeNameRE.MatchString(f.Name) {
+			errs = append(errs, errors.Errorf("invalid loadBalancer.extraFrontends[%d].name: %q", i, f.Name))
+		} else if names[f.Name] || f.Name == "control-plane" || f.Name == "kube-apiservers" {
+			errs = append(errs, errors.Errorf("invalid loadBalancer.extraFrontends[%d].name: %q is already in use", i, f.Name))
+		}
+		names[f.Name] = true
+		if f.Port < 1 || f.Port > 65535 {
+			errs = append(errs, errors.Errorf("invalid loadBalancer.extraFrontends[%d].port: %d", i, f.Port))
+		} else if ports[f.Port] {
+			errs = append(errs, errors.Errorf("invalid loadBalancer.extraFrontends[%d].port: %d is already in use", i, f.Port))
+		}
+		ports[f.Port] = true
+		if f.BackendPort < 1 || f.BackendPort > 65535 {
+			errs = append(errs, errors.Errorf("invalid loadBalancer.extraFrontends[%d].backendPort: %d", i, f.BackendPort))
+		}
+		if f.HostPort < 0 || f.HostPort > 65535 {
+			errs = append(errs, errors.Errorf("invalid loadBalancer.extraFrontends[%d].hostPort: %d", i, f.HostPort))
+		}
+	}
+	return errs
+}
+
+// EtcdQuotaBackendBytes returns the bytes of etcd.quotaBackendBytes,
+// or 0 if it is not set
+func EtcdQuotaBackendBytes(e *Etcd) (int64, error) {
+	if e.QuotaBackendBytes == "" {
+		return 0, nil
+	}
+	return quantityBytes("etcd.quotaBackendBytes", e.QuotaBackendBytes)
+}
+
+// EtcdMilliseconds returns the milliseconds of the etcd duration value of
+// field, as etcd takes them, or 0 if it is not set
+func EtcdMilliseconds(field, value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 || d%time.Millisecond != 0 {
+		return 0, errors.Errorf("invalid %s: %q, expected a duration in milliseconds such as \"250ms\"", field, value)
+	}
+	return int64(d / time.Millisecond), nil
+}
+
+// volumeNameRE matches the names docker and podman allow for volumes
+var volumeNameRE = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// defaultImageGCLowThresholdPercent is kubelet's default imageGCLowThresholdPercent
+const defaultImageGCLowThresholdPercent = 80
+
+// validateImageGC checks the image garbage collection settings will be
+// accepted by kubelet
+func validateImageGC(gc ImageGC) []error {
+	errs := []error{}
+	if gc.HighThresholdPercent < 0 || gc.HighThresholdPercent > 100 {
+		errs = append(errs, errors.Errorf("invalid imageGC.highThresholdPercent: %d, must be between 0 and 100", gc.HighThresholdPercent))
+	}
+	if gc.LowThresholdPercent < 0 || gc.LowThresholdPercent > 100 {
+		errs = append(errs, errors.Errorf("invalid imageGC.lowThresholdPercent: %d, must be between 0 and 100", gc.LowThresholdPercent))
+	}
+	high, low := gc.HighThresholdPercent, gc.LowThresholdPercent
+	if high == 0 {
+		high = 100
+	}
+	if low == 0 {
+		low = defaultImageGCLowThresholdPercent
+	}
+	if len(errs) == 0 && low >= high {
+		errs = append(errs, errors.Errorf("imageGC.lowThresholdPercent (%d) must be below imageGC.highThresholdPercent (%d), it defaults to %d", low, high, defaultImageGCLowThresholdPercent))
+	}
+	if gc.MinimumAge != "" {
+		if d, err := time.ParseDuration(gc.MinimumAge); err != nil || d < 0 {
+			errs = append(errs, errors.Errorf("invalid imageGC.minimumAge: %q, must be a duration such as 10m", gc.MinimumAge))
+		}
+	}
+	return errs
+}
+
+// validateEtcd checks the etcd tuning is valid for etcd, and that the data
+// volume is a volume name or an absolute path
+// validateRegistries checks the registries, the hosts are directory names
+// on the nodes and containerd refuses the old registry config alongside them
+func validateRegistries(c *Cluster) []error {
+	errs := []error{}
+	hosts := map[string]bool{}
+	for _, r := range c.Registries {
+		switch {
+		case r.Host == "":
+			errs = append(errs, errors.New("invalid registries: host must be set"))
+		case strings.Contains(r.Host, "://") || strings.ContainsAny(r.Host, "/ "):
+			errs = append(errs, errors.Errorf("invalid registries: host %q must be a host and optional port, without a scheme or path", r.Host))
+		case hosts[r.Host]:
+			errs = append(errs, errors.Errorf("invalid registries: host %q is listed more than once", r.Host))
+		}
+		hosts[r.Host] = true
+		if r.PlainHTTP && (r.InsecureSkipVerify || r.CA != "") {
+			errs = append(errs, errors.Errorf("invalid registries: %q uses plainHTTP, insecureSkipVerify and ca only apply to HTTPS", r.Host))
+		}
+		if IsInlinePEM(r.CA) && !IsCertificatePEM(r.CA) {
+			errs = append(errs, errors.Errorf("invalid registries: ca of %q is not a PEM encoded certificate", r.Host))
+		}
+	}
+	if len(c.Registries) > 0 {
+		for _, p := range c.ContainerdConfigPatches {
+			if strings.Contains(p, "registry.mirrors") || strings.Contains(p, "registry.configs") {
+				errs = append(errs, errors.New("invalid registries: containerdConfigPatches must not also configure registry.mirrors or registry.configs"))
+				break
+			}
+		}
+	}
+	return errs
+}
+
+func validateEtcd(e *Etcd) []error {
+	errs := []error{}
+	if _, err := EtcdQuotaBackendBytes(e); err != nil {
+		errs = append(errs, err)
+	}
+	heartbeat, heartbeatErr := EtcdMilliseconds("etcd.heartbeatInterval", e.HeartbeatInterval)
+	if heartbeatErr != nil {
+		errs = append(errs, heartbeatErr)
+	}
+	election, electionErr := EtcdMilliseconds("etcd.electionTimeout", e.ElectionTimeout)
+	if electionErr != nil {
+		errs = append(errs, electionErr)
+	}
+	// etcd refuses to start otherwise, compare against its defaults if unset
+	if heartbeatErr == nil && electionErr == nil && (heartbeat != 0 || election != 0) {
+		if heartbeat == 0 {
+			heartbeat = 100
+		}
+		if election == 0 {
+			election = 1000
+		}
+		if election < 5*heartbeat {
+			errs = append(errs, errors.Errorf("invalid etcd.electionTimeout: %dms must be at least five times the heartbeat interval of %dms", election, heartbeat))
+		}
+	}
+	if e.DataVolume != "" && !filepath.IsAbs(e.DataVolume) && !volumeNameRE.MatchString(e.DataVolume) {
+		errs = append(errs, errors.Errorf("invalid etcd.dataVolume: %q, expected a volume name or an absolute path", e.DataVolume))
+	}
+	return errs
+}
+
+// validateCredentialProviders checks each provider has a binary and images
+// to match, and that the provider binaries are mounted
+func validateCredentialProviders(c *CredentialProviders) []error {
+	errs := []error{}
+	if len(c.Providers) > 0 && c.BinDir == "" {
+		errs = append(errs, errors.New("invalid credentialProviders: binDir is required"))
+	}
+	names := map[string]bool{}
+	for _, p := range c.Providers {
+		if p.Name == "" || strings.ContainsAny(p.Name, "/\\") {
+			errs = append(errs, errors.Errorf("invalid credentialProviders.providers name: %q must be a file name in binDir", p.Name))
+		} else if names[p.Name] {
+			errs = append(errs, errors.Errorf("invalid credentialProviders.providers: %q is configured more than once", p.Name))
+		}
+		names[p.Name] = true
+		if len(p.MatchImages) == 0 {
+			errs = append(errs, errors.Errorf("invalid credentialProviders.providers %q: matchImages is required", p.Name))
+		}
+		if p.DefaultCacheDuration != "" {
+			if _, err := time.ParseDuration(p.DefaultCacheDuration); err != nil {
+				errs = append(errs, errors.Errorf("invalid credentialProviders.providers %q: defaultCacheDuration %q is not a duration", p.Name, p.DefaultCacheDuration))
+			}
+		}
+	}
+	return errs
+}
+
+// validateOIDC checks the OIDC flags are well formed if OIDC is enabled
+func validateOIDC(o *OIDC) []error {
+	errs := []error{}
+	if o.TestIssuer {
+		if o.IssuerURL != "" || o.CA != "" {
+			errs = append(errs, errors.New("invalid authentication.oidc: issuerURL and ca are generated for the testIssuer and must not be set"))
+		}
+	} else if o.IssuerURL == "" {
+		return errs
+	} else if u, err := url.Parse(o.IssuerURL); err != nil || u.Scheme != "https" || u.Host == "" {
+		errs = append(errs, errors.Errorf("invalid authentication.oidc.issuerURL: %q, expected an https URL", o.IssuerURL))
+	}
+	if o.ClientID == "" {
+		errs = append(errs, errors.New("invalid authentication.oidc: clientID is required"))
+	}
+	if o.CA != "" {
+		if block, _ := pem.Decode([]byte(o.CA)); block == nil || block.Type != "CERTIFICATE" {
+			errs = append(errs, errors.New("invalid authentication.oidc.ca: expected a PEM encoded certificate"))
+		}
+	}
+	for k, v := range o.RequiredClaims {
+		if k == "" || strings.ContainsAny(k, ",=") || strings.Contains(v, ",") {
+			errs = append(errs, errors.Errorf("invalid authentication.oidc.requiredClaims: %q=%q", k, v))
+		}
+	}
+	return errs
+}
+
+// capacityRE matches the resource quantities used for volume capacity
+var capacityRE = regexp.MustCompile(`^\d+(Ki|Mi|Gi|Ti|Pi|K|M|G|T|P)?$`)
+
+// validatePersistentVolumes checks the node's persistent volumes are
+// well formed and backed by one of its extraMounts
+func validatePersistentVolumes(n *Node) []error {
+	errs := []error{}
+	for i, pv := range n.PersistentVolumes {
+		if !nodeNameRE.MatchString(pv.Name) || len(pv.Name) > 253 {
+			errs = append(errs, errors.Errorf("invalid persistentVolumes[%d].name: %q", i, pv.Name))
+		}
+		if !capacityRE.MatchString(pv.Capacity) {
+			errs = append(errs, errors.Errorf("invalid persistentVolumes[%d].capacity: %q, expected a quantity such as \"10Gi\"", i, pv.Capacity))
+		}
+		// nothing deletes the data of a static hostPath volume, so a Delete
+		// policy would only leave the released volume failed
+		if pv.ReclaimPolicy != "Retain" {
+			errs = append(errs, errors.Errorf("invalid persistentVolumes[%d].reclaimPolicy: %q, only Retain is supported", i, pv.ReclaimPolicy))
+		}
+		if !path.IsAbs(pv.Path) {
+			errs = append(errs, errors.Errorf("invalid persistentVolumes[%d].path: %q must be absolute", i, pv.Path))
+		} else if !withinExtraMounts(n.ExtraMounts, pv.Path) {
+			errs = append(errs, errors.Errorf("invalid persistentVolumes[%d].path: %q is not within any extraMounts containerPath", i, pv.Path))
+		}
+	}
+	return errs
+}
+
+// withinExtraMounts returns true if p is at or below the containerPath of
+// one of mounts
+func withinExtraMounts(mounts []Mount, p string) bool {
+	p = path.Clean(p)
+	for _, m := range mounts {
+		containerPath := path.Clean(m.ContainerPath)
+		if p == containerPath || strings.HasPrefix(p, strings.TrimSuffix(containerPath, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// validateAPIServerAddress checks address is an IP, or `iface:<name>` which
+// is resolved to the address of that host interface at creation time
+func validateAPIServerAddress(address string) error {
+	if strings.HasPrefix(address, "iface:") {
+		if strings.TrimPrefix(address, "iface:") == "" {
+			return errors.Errorf("%q is missing the interface name", address)
+		}
+		return nil
+	}
+	if net.ParseIP(address) == nil {
+		return errors.Errorf("%q is not an IP address or iface:<name>", address)
+	}
+	return nil
+}
+
 func validatePort(port int32) error {
 	// NOTE: -1 is a special value for auto-selecting the port in the container
 	// backend where possible as opposed to in kind itself.
@@ -18,16 +18,43 @@ package cli
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"runtime"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
+	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/log"
 
 	"sigs.k8s.io/kind/pkg/internal/env"
+	"sigs.k8s.io/kind/pkg/internal/events"
+)
+
+// Log output formats supported by Logger.SetFormat
+const (
+	// TextFormat is the default human readable format
+	TextFormat = "text"
+	// JSONFormat writes one JSON object per log line
+	JSONFormat = "json"
+)
+
+// Progress styles supported by Logger.SetProgress
+const (
+	// AutoProgress selects RichProgress when writing to a smart terminal
+	// and PlainProgress otherwise
+	AutoProgress = "auto"
+	// RichProgress shows an interactive spinner for the current status
+	RichProgress = "rich"
+	// PlainProgress writes one line as each status starts and ends,
+	// which is suitable for CI logs
+	PlainProgress = "plain"
+	// QuietProgress suppresses status, info and warning output, only
+	// errors are written
+	QuietProgress = "quiet"
 )
 
 // Logger is the kind cli's log.Logger implementation
@@ -38,15 +65,24 @@ type Logger struct {
 	bufferPool *bufferPool
 	// kind special additions
 	isSmartWriter bool
+	// format, the current phase, and the below are protected by writerMu
+	format string
+	phase  string
+	// noColor disables color even when writing to a smart terminal
+	noColor bool
+	// quiet is true for QuietProgress
+	quiet bool
 }
 
 var _ log.Logger = &Logger{}
+var _ events.Emitter = &Logger{}
 
 // NewLogger returns a new Logger with the given verbosity
 func NewLogger(writer io.Writer, verbosity log.Level) *Logger {
 	l := &Logger{
 		verbosity:  verbosity,
 		bufferPool: newBufferPool(),
+		format:     TextFormat,
 	}
 	l.SetWriter(writer)
 	return l
@@ -58,14 +94,84 @@ func (l *Logger) SetWriter(w io.Writer) {
 	defer l.writerMu.Unlock()
 	l.writer = w
 	_, isSpinner := w.(*Spinner)
-	l.isSmartWriter = isSpinner || env.IsSmartTerminal(w)
+	l.isSmartWriter = (isSpinner || env.IsSmartTerminal(w)) && l.format != JSONFormat
+}
+
+// SetFormat sets the output format, one of TextFormat or JSONFormat
+// JSONFormat disables color and the status spinner
+func (l *Logger) SetFormat(format string) error {
+	if format != TextFormat && format != JSONFormat {
+		return errors.Errorf("unknown log format %q, expected one of: %s, %s", format, TextFormat, JSONFormat)
+	}
+	l.writerMu.Lock()
+	defer l.writerMu.Unlock()
+	l.format = format
+	if format == JSONFormat {
+		// structured output should not contain spinner frames
+		if spinner, ok := l.writer.(*Spinner); ok {
+			l.writer = spinner.writer
+		}
+		l.isSmartWriter = false
+	}
+	return nil
+}
+
+// SetProgress sets the status output style, one of AutoProgress,
+// RichProgress, PlainProgress or QuietProgress
+// AutoProgress keeps the writer selected by the caller, see SetWriter
+func (l *Logger) SetProgress(style string) error {
+	l.writerMu.Lock()
+	defer l.writerMu.Unlock()
+	spinner, isSpinner := l.writer.(*Spinner)
+	switch style {
+	case AutoProgress:
+	case RichProgress:
+		// JSONFormat never uses the spinner
+		if !isSpinner && l.format != JSONFormat {
+			l.writer = NewSpinner(l.writer)
+			l.isSmartWriter = true
+		}
+	case PlainProgress, QuietProgress:
+		if isSpinner {
+			l.writer = spinner.writer
+		}
+	default:
+		return errors.Errorf(
+			"unknown progress style %q, expected one of: %s, %s, %s, %s",
+			style, AutoProgress, RichProgress, PlainProgress, QuietProgress,
+		)
+	}
+	l.quiet = style == QuietProgress
+	return nil
+}
+
+// SetColor enables or disables colored output, color is only ever
+// enabled when writing to a smart terminal
+func (l *Logger) SetColor(enabled bool) {
+	l.writerMu.Lock()
+	defer l.writerMu.Unlock()
+	l.noColor = !enabled
+}
+
+// isQuiet returns true if the logger is only writing errors
+func (l *Logger) isQuiet() bool {
+	l.writerMu.Lock()
+	defer l.writerMu.Unlock()
+	return l.quiet
+}
+
+// isJSON returns true if the logger is writing JSONFormat
+func (l *Logger) isJSON() bool {
+	l.writerMu.Lock()
+	defer l.writerMu.Unlock()
+	return l.format == JSONFormat
 }
 
 // ColorEnabled returns true if the caller is OK to write colored output
 func (l *Logger) ColorEnabled() bool {
 	l.writerMu.Lock()
 	defer l.writerMu.Unlock()
-	return l.isSmartWriter
+	return l.isSmartWriter && !l.noColor
 }
 
 func (l *Logger) getVerbosity() log.Level {
@@ -95,14 +201,87 @@ func (l *Logger) writeBuffer(buf *bytes.Buffer) {
 	_, _ = l.write(buf.Bytes())
 }
 
+// jsonRecord is a single JSONFormat log line
+type jsonRecord struct {
+	Timestamp time.Time `json:"timestamp"`
+	Level     string    `json:"level"`
+	Phase     string    `json:"phase,omitempty"`
+	Node      string    `json:"node,omitempty"`
+	Message   string    `json:"message"`
+}
+
+// writeJSON writes a JSONFormat line for message in the current phase
+func (l *Logger) writeJSON(level, node, message string) {
+	l.writerMu.Lock()
+	defer l.writerMu.Unlock()
+	if l.quiet && level != "error" {
+		return
+	}
+	b, err := json.Marshal(jsonRecord{
+		Timestamp: time.Now().UTC(),
+		Level:     level,
+		Phase:     l.phase,
+		Node:      node,
+		Message:   strings.TrimSpace(message),
+	})
+	if err != nil {
+		return
+	}
+	_, _ = l.writer.Write(append(b, '\n'))
+}
+
+// EmitEvent implements events.Emitter, tracking the current phase and
+// logging node and phase events when writing JSONFormat
+func (l *Logger) EmitEvent(e events.Event) {
+	if !l.isJSON() {
+		return
+	}
+	switch e.Type {
+	case events.PhaseStarted:
+		l.writerMu.Lock()
+		l.phase = e.Phase
+		l.writerMu.Unlock()
+		l.writeJSON("info", "", "phase started")
+	case events.PhaseCompleted:
+		if e.Success {
+			l.writeJSON("info", "", fmt.Sprintf("phase completed in %v", e.Duration))
+		} else {
+			l.writeJSON("error", "", fmt.Sprintf("phase failed after %v", e.Duration))
+		}
+		l.writerMu.Lock()
+		l.phase = ""
+		l.writerMu.Unlock()
+	case events.StepCompleted:
+		l.writeJSON("debug", e.Node, fmt.Sprintf("%s completed in %v", e.Phase, e.Duration))
+	case events.NodeCreated:
+		l.writeJSON("info", e.Node, fmt.Sprintf("node created in %v", e.Duration))
+	case events.ImagePulled:
+		l.writeJSON("info", "", fmt.Sprintf("pulled image %s in %v", e.Image, e.Duration))
+	}
+}
+
 // print writes a simple string to the log writer
-func (l *Logger) print(message string) {
+func (l *Logger) print(level, message string) {
+	if level != "error" && l.isQuiet() {
+		return
+	}
+	if l.isJSON() {
+		l.writeJSON(level, "", message)
+		return
+	}
 	buf := bytes.NewBufferString(message)
 	l.writeBuffer(buf)
 }
 
 // printf is roughly fmt.Fprintf against the log writer
-func (l *Logger) printf(format string, args ...interface{}) {
+func (l *Logger) printf(level, format string, args ...interface{}) {
+	if level != "error" && l.isQuiet() {
+		return
+	}
+	if l.isJSON() {
+		l.writeJSON(level, "", fmt.Sprintf(format, args...))
+		return
+	}
 	buf := l.bufferPool.Get()
 	fmt.Fprintf(buf, format, args...)
 	l.writeBuffer(buf)
@@ -136,6 +315,10 @@ func addDebugHeader(buf *bytes.Buffer) {
 
 // debug is like print but with a debug log header
 func (l *Logger) debug(message string) {
+	if l.isJSON() {
+		l.writeJSON("debug", "", message)
+		return
+	}
 	buf := l.bufferPool.Get()
 	addDebugHeader(buf)
 	buf.WriteString(message)
@@ -145,6 +328,10 @@ func (l *Logger) debug(message string) {
 
 // debugf is like printf but with a debug log header
 func (l *Logger) debugf(format string, args ...interface{}) {
+	if l.isJSON() {
+		l.writeJSON("debug", "", fmt.Sprintf(format, args...))
+		return
+	}
 	buf := l.bufferPool.Get()
 	addDebugHeader(buf)
 	fmt.Fprintf(buf, format, args...)
@@ -154,22 +341,22 @@ func (l *Logger) debugf(format string, args ...interface{}) {
 
 // Warn is part of the log.Logger interface
 func (l *Logger) Warn(message string) {
-	l.print(message)
+	l.print("warning", message)
 }
 
 // Warnf is part of the log.Logger interface
 func (l *Logger) Warnf(format string, args ...interface{}) {
-	l.printf(format, args...)
+	l.printf("warning", format, args...)
 }
 
 // Error is part of the log.Logger interface
 func (l *Logger) Error(message string) {
-	l.print(message)
+	l.print("error", message)
 }
 
 // Errorf is part of the log.Logger interface
 func (l *Logger) Errorf(format string, args ...interface{}) {
-	l.printf(format, args...)
+	l.printf("error", format, args...)
 }
 
 // V is part of the log.Logger interface
@@ -177,7 +364,7 @@ func (l *Logger) V(level log.Level) log.InfoLogger {
 	return infoLogger{
 		logger:  l,
 		level:   level,
-		enabled: level <= l.getVerbosity(),
+		enabled: level <= l.getVerbosity() && !l.isQuiet(),
 	}
 }
 
@@ -202,7 +389,7 @@ func (i infoLogger) Info(message string) {
 	if i.level > 0 {
 		i.logger.debug(message)
 	} else {
-		i.logger.print(message)
+		i.logger.print("info", message)
 	}
 }
 
@@ -215,7 +402,7 @@ func (i infoLogger) Infof(format string, args ...interface{}) {
 	if i.level > 0 {
 		i.logger.debugf(format, args...)
 	} else {
-		i.logger.printf(format, args...)
+		i.logger.printf("info", format, args...)
 	}
 }
 
@@ -0,0 +1,166 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+	"sigs.k8s.io/kind/pkg/internal/events"
+)
+
+func TestLoggerJSONFormat(t *testing.T) {
+	t.Parallel()
+	var buff bytes.Buffer
+	l := NewLogger(&buff, 0)
+	assert.ExpectError(t, true, l.SetFormat("yaml"))
+	assert.ExpectError(t, false, l.SetFormat(JSONFormat))
+
+	status := StatusForLogger(l)
+	status.Start("Preparing nodes")
+	events.Emit(l, events.Event{Type: events.NodeCreated, Node: "kind-control-plane"})
+	l.Warn("something is odd")
+	status.End(false)
+	l.Errorf("ERROR: %s", "failed")
+
+	lines := strings.Split(strings.TrimSpace(buff.String()), "\n")
+	records := make([]jsonRecord, len(lines))
+	for i, line := range lines {
+		if err := json.Unmarshal([]byte(line), &records[i]); err != nil {
+			t.Fatalf("failed to parse line %q: %v", line, err)
+		}
+		if records[i].Timestamp.IsZero() {
+			t.Errorf("expected a timestamp in line %q", line)
+		}
+	}
+	type summary struct{ Level, Phase, Node, Message string }
+	result := []summary{}
+	for _, r := range records {
+		if strings.HasPrefix(r.Message, "phase failed") {
+			r.Message = "phase failed"
+		}
+		result = append(result, summary{r.Level, r.Phase, r.Node, r.Message})
+	}
+	assert.DeepEqual(t, []summary{
+		{"info", "Preparing nodes", "", "phase started"},
+		{"info", "Preparing nodes", "kind-control-plane", "node created in 0s"},
+		{"warning", "Preparing nodes", "", "something is odd"},
+		{"error", "Preparing nodes", "", "phase failed"},
+		{"error", "", "", "ERROR: failed"},
+	}, result)
+}
+
+func TestLoggerSetProgress(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name          string
+		Style         string
+		Spinner       bool
+		ExpectError   bool
+		ExpectSpinner bool
+	}{
+		{
+			Name:          "auto keeps the spinner",
+			Style:         AutoProgress,
+			Spinner:       true,
+			ExpectSpinner: true,
+		},
+		{
+			Name:    "auto keeps a plain writer",
+			Style:   AutoProgress,
+			Spinner: false,
+		},
+		{
+			Name:          "rich adds a spinner",
+			Style:         RichProgress,
+			ExpectSpinner: true,
+		},
+		{
+			Name:    "plain removes the spinner",
+			Style:   PlainProgress,
+			Spinner: true,
+		},
+		{
+			Name:    "quiet removes the spinner",
+			Style:   QuietProgress,
+			Spinner: true,
+		},
+		{
+			Name:          "unknown style",
+			Style:         "fancy",
+			Spinner:       true,
+			ExpectError:   true,
+			ExpectSpinner: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			var w io.Writer = &bytes.Buffer{}
+			if tc.Spinner {
+				w = NewSpinner(w)
+			}
+			l := NewLogger(w, 0)
+			assert.ExpectError(t, tc.ExpectError, l.SetProgress(tc.Style))
+			_, isSpinner := l.writer.(*Spinner)
+			assert.BoolEqual(t, tc.ExpectSpinner, isSpinner)
+		})
+	}
+}
+
+func TestLoggerQuietProgress(t *testing.T) {
+	t.Parallel()
+	var buff bytes.Buffer
+	l := NewLogger(&buff, 3)
+	assert.ExpectError(t, false, l.SetProgress(QuietProgress))
+
+	status := StatusForLogger(l)
+	status.Start("Preparing nodes")
+	l.V(0).Info("some info")
+	l.V(1).Info("some debug info")
+	l.Warn("something is odd")
+	status.End(false)
+	l.Errorf("ERROR: %s", "failed")
+
+	assert.StringEqual(t, "ERROR: failed\n", buff.String())
+}
+
+func TestLoggerPlainProgress(t *testing.T) {
+	t.Parallel()
+	var buff bytes.Buffer
+	l := NewLogger(&buff, 0)
+	assert.ExpectError(t, false, l.SetProgress(PlainProgress))
+	l.SetColor(false)
+
+	status := StatusForLogger(l)
+	status.Start("Preparing nodes")
+	status.Start("Writing configuration")
+	status.End(false)
+
+	assert.StringEqual(t, strings.Join([]string{
+		" • Preparing nodes  ...",
+		" ✓ Preparing nodes",
+		" • Writing configuration  ...",
+		" ✗ Writing configuration",
+		"",
+	}, "\n"), buff.String())
+}
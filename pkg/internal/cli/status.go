@@ -18,7 +18,9 @@ package cli
 
 import (
 	"fmt"
+	"time"
 
+	"sigs.k8s.io/kind/pkg/internal/events"
 	"sigs.k8s.io/kind/pkg/log"
 )
 
@@ -27,7 +29,10 @@ import (
 type Status struct {
 	spinner *Spinner
 	status  string
+	started time.Time
 	logger  log.Logger
+	// structured is true if the logger writes phases itself, see EmitEvent
+	structured bool
 	// for controlling coloring etc
 	successFormat string
 	failureFormat string
@@ -35,18 +40,29 @@ type Status struct {
 
 // StatusForLogger returns a new status object for the logger l,
 // if l is the kind cli logger and the writer is a Spinner, that spinner
-// will be used for the status
+// will be used for the status, see Logger.SetProgress
+// if l carries an event sink, phase events will be emitted for each status
 func StatusForLogger(l log.Logger) *Status {
 	s := &Status{
 		logger:        l,
 		successFormat: " ✓ %s\n",
 		failureFormat: " ✗ %s\n",
 	}
+	// look through the event emitting wrapper for the CLI logger
+	underlying := l
+	if v, ok := l.(*events.Logger); ok {
+		underlying = v.Unwrap()
+	}
 	// if we're using the CLI logger, check for if it has a spinner setup
 	// and wire the status to that
-	if v, ok := l.(*Logger); ok {
+	if v, ok := underlying.(*Logger); ok {
+		s.structured = v.isJSON()
+		v.writerMu.Lock()
 		if v2, ok := v.writer.(*Spinner); ok {
 			s.spinner = v2
+		}
+		v.writerMu.Unlock()
+		if v.ColorEnabled() {
 			// use colored success / failure messages
 			s.successFormat = " \x1b[32m✓\x1b[0m %s\n"
 			s.failureFormat = " \x1b[31m✗\x1b[0m %s\n"
@@ -61,6 +77,15 @@ func (s *Status) Start(status string) {
 	s.End(true)
 	// set new status
 	s.status = status
+	s.started = time.Now()
+	events.Emit(s.logger, events.Event{
+		Type:  events.PhaseStarted,
+		Time:  s.started,
+		Phase: status,
+	})
+	if s.structured {
+		return
+	}
 	if s.spinner != nil {
 		s.spinner.SetSuffix(fmt.Sprintf(" %s ", s.status))
 		s.spinner.Start()
@@ -80,11 +105,20 @@ func (s *Status) End(success bool) {
 		s.spinner.Stop()
 		fmt.Fprint(s.spinner.writer, "\r")
 	}
-	if success {
-		s.logger.V(0).Infof(s.successFormat, s.status)
-	} else {
-		s.logger.V(0).Infof(s.failureFormat, s.status)
+	// a structured logger records the phase outcome from the event below
+	if !s.structured {
+		if success {
+			s.logger.V(0).Infof(s.successFormat, s.status)
+		} else {
+			s.logger.V(0).Infof(s.failureFormat, s.status)
+		}
 	}
+	events.Emit(s.logger, events.Event{
+		Type:     events.PhaseCompleted,
+		Phase:    s.status,
+		Success:  success,
+		Duration: time.Since(s.started),
+	})
 
 	s.status = ""
 }
@@ -0,0 +1,138 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package events implements typed progress events for library consumers
+package events
+
+import (
+	"fmt"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+// Type identifies the kind of an Event
+type Type string
+
+const (
+	// PhaseStarted is emitted when a phase of an operation begins
+	PhaseStarted Type = "PhaseStarted"
+	// PhaseCompleted is emitted when a phase ends, successfully or not
+	PhaseCompleted Type = "PhaseCompleted"
+	// NodeCreated is emitted after a node container is created
+	NodeCreated Type = "NodeCreated"
+	// ImagePulled is emitted after a node image is pulled
+	ImagePulled Type = "ImagePulled"
+	// Warning is emitted for every user facing warning
+	Warning Type = "Warning"
+	// StepCompleted is emitted when an internal step within a phase ends,
+	// for steps that are worth timing but not shown as a phase
+	StepCompleted Type = "StepCompleted"
+)
+
+// Event is a single progress event
+type Event struct {
+	// Type is the type of event, other fields are set depending on Type
+	Type Type
+	// Time is when the event occurred
+	Time time.Time
+	// Phase is the human readable phase name, for phase events,
+	// or the step name for StepCompleted
+	Phase string
+	// Success is true if the phase succeeded, for PhaseCompleted
+	Success bool
+	// Duration is the time taken, for PhaseCompleted, StepCompleted,
+	// NodeCreated and ImagePulled
+	Duration time.Duration
+	// Node is the node name, for NodeCreated
+	Node string
+	// Image is the image reference, for ImagePulled
+	Image string
+	// Message is the warning message, for Warning
+	Message string
+}
+
+// Sink receives events, sends block until the event is received
+type Sink chan<- Event
+
+// Emitter is implemented by loggers that consume events, see Emit
+type Emitter interface {
+	EmitEvent(e Event)
+}
+
+// Logger wraps a log.Logger, carrying a Sink alongside it and emitting
+// a Warning event for each warning logged
+type Logger struct {
+	log.Logger
+	sink Sink
+}
+
+var _ log.Logger = &Logger{}
+
+// NewLogger returns a Logger wrapping logger and emitting to sink
+func NewLogger(logger log.Logger, sink Sink) *Logger {
+	return &Logger{
+		Logger: logger,
+		sink:   sink,
+	}
+}
+
+// Unwrap returns the underlying logger
+func (l *Logger) Unwrap() log.Logger {
+	return l.Logger
+}
+
+// Warn is part of the log.Logger interface
+func (l *Logger) Warn(message string) {
+	l.Logger.Warn(message)
+	l.emit(Event{Type: Warning, Message: message})
+}
+
+// Warnf is part of the log.Logger interface
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.Logger.Warnf(format, args...)
+	l.emit(Event{Type: Warning, Message: fmt.Sprintf(format, args...)})
+}
+
+// EmitEvent implements Emitter, sending e to the Sink and then to the
+// underlying logger if it is also an Emitter
+func (l *Logger) EmitEvent(e Event) {
+	l.emit(e)
+	if v, ok := l.Logger.(Emitter); ok {
+		v.EmitEvent(e)
+	}
+}
+
+func (l *Logger) emit(e Event) {
+	if l.sink == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	l.sink <- e
+}
+
+// Emit sends e to logger if logger is an Emitter, such as a *Logger
+// otherwise it does nothing
+func Emit(logger log.Logger, e Event) {
+	if v, ok := logger.(Emitter); ok {
+		if e.Time.IsZero() {
+			e.Time = time.Now()
+		}
+		v.EmitEvent(e)
+	}
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package imageload implements loading images from a local container
+// runtime's image store into nodes
+package imageload
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// SelectNodes returns the nodes of the cluster name to load images into,
+// all of them unless names is not empty
+func SelectNodes(provider *cluster.Provider, name string, names []string) ([]nodes.Node, error) {
+	nodeList, err := provider.ListInternalNodes(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodeList) == 0 {
+		return nil, fmt.Errorf("no nodes found for cluster %q", name)
+	}
+	if len(names) == 0 {
+		return nodeList, nil
+	}
+	nodesByName := map[string]nodes.Node{}
+	for _, node := range nodeList {
+		nodesByName[node.String()] = node
+	}
+	selected := []nodes.Node{}
+	for _, name := range names {
+		node, ok := nodesByName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown node: %q", name)
+		}
+		selected = append(selected, node)
+	}
+	return selected, nil
+}
+
+// SameID returns true if the image IDs a and b are the same, ignoring
+// the "sha256:" prefix which podman omits
+func SameID(a, b string) bool {
+	return strings.TrimPrefix(a, "sha256:") == strings.TrimPrefix(b, "sha256:")
+}
+
+// ImageID returns the ID of image in the image store of the container
+// runtime binary, e.g. docker or podman
+func ImageID(binary, image string) (string, error) {
+	lines, err := exec.OutputLines(exec.Command(binary, "image", "inspect", "-f", "{{ .Id }}", image))
+	if err != nil {
+		return "", err
+	}
+	if len(lines) != 1 {
+		return "", errors.Errorf("%s image ID should only be one line, got %d lines", binary, len(lines))
+	}
+	return lines[0], nil
+}
+
+// Save returns the command writing an archive of images from the image
+// store of the container runtime binary to stdout, for Stream
+func Save(binary string, images []string) exec.Cmd {
+	args := []string{"save"}
+	if binary == "podman" {
+		// a multi image archive needs the docker format
+		args = append(args, "--format", "docker-archive", "--multi-image-archive")
+	}
+	return exec.Command(binary, append(args, images...)...)
+}
+
+// Stream runs save, which should write an image archive to stdout, and
+// loads the archive into each of nodeList concurrently as it is written
+// save is only run once and the archive is never written to disk
+func Stream(save exec.Cmd, nodeList []nodes.Node) error {
+	loaders := make([]func(io.Reader) error, len(nodeList))
+	for i, node := range nodeList {
+		node := node // capture loop variable
+		loaders[i] = func(r io.Reader) error {
+			err := nodeutils.LoadImageArchive(node, r)
+			return errors.Wrapf(err, "failed to load images into node %q", node.String())
+		}
+	}
+	return stream(save, loaders)
+}
+
+// stream implements Stream for any archive loaders
+func stream(save exec.Cmd, loaders []func(io.Reader) error) error {
+	writers := make([]io.Writer, len(loaders))
+	pipes := make([]*io.PipeWriter, len(loaders))
+	fns := []func() error{}
+	for i, load := range loaders {
+		r, w := io.Pipe()
+		writers[i], pipes[i] = w, w
+		load := load // capture loop variable
+		fns = append(fns, func() error {
+			if err := load(r); err != nil {
+				// fail the save instead of blocking it
+				r.CloseWithError(err)
+				return err
+			}
+			// the loader may stop before the end of the archive padding
+			_, _ = io.Copy(ioutil.Discard, r)
+			return nil
+		})
+	}
+	fns = append(fns, func() error {
+		err := save.SetStdout(io.MultiWriter(writers...)).Run()
+		// a nil error closes the pipes normally, with io.EOF
+		for _, w := range pipes {
+			w.CloseWithError(err)
+		}
+		return errors.Wrap(err, "failed to save images")
+	})
+	return errors.UntilErrorConcurrent(fns)
+}
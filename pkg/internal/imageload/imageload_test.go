@@ -0,0 +1,116 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imageload
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+// fakeSave is an exec.Cmd writing archive to stdout
+type fakeSave struct {
+	archive string
+	err     error
+	stdout  io.Writer
+}
+
+var _ exec.Cmd = &fakeSave{}
+
+func (f *fakeSave) Run() error {
+	if _, err := io.Copy(f.stdout, strings.NewReader(f.archive)); err != nil {
+		return err
+	}
+	return f.err
+}
+
+func (f *fakeSave) SetEnv(...string) exec.Cmd      { return f }
+func (f *fakeSave) SetStdin(io.Reader) exec.Cmd    { return f }
+func (f *fakeSave) SetStderr(io.Writer) exec.Cmd   { return f }
+func (f *fakeSave) SetStdout(w io.Writer) exec.Cmd { f.stdout = w; return f }
+
+func TestStream(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name        string
+		SaveError   error
+		LoadError   error
+		ExpectError bool
+	}{
+		{
+			Name: "streams the archive to every loader",
+		},
+		{
+			Name:        "save fails",
+			SaveError:   errors.New("no such image"),
+			ExpectError: true,
+		},
+		{
+			Name:        "load fails",
+			LoadError:   errors.New("no space left on device"),
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			// large enough to not fit in a pipe write at once
+			archive := strings.Repeat("layer", 100000)
+			var mu sync.Mutex
+			loaded := []string{}
+			loaders := []func(io.Reader) error{}
+			for i := 0; i < 3; i++ {
+				failing := i == 1 && tc.LoadError != nil
+				loaders = append(loaders, func(r io.Reader) error {
+					if failing {
+						return tc.LoadError
+					}
+					// stop before the end, like ctr import at the archive padding
+					var buff bytes.Buffer
+					if _, err := io.CopyN(&buff, r, int64(len(archive)-5)); err != nil {
+						return err
+					}
+					mu.Lock()
+					defer mu.Unlock()
+					loaded = append(loaded, buff.String())
+					return nil
+				})
+			}
+			err := stream(&fakeSave{archive: archive, err: tc.SaveError}, loaders)
+			assert.ExpectError(t, tc.ExpectError, err)
+			if tc.ExpectError {
+				return
+			}
+			head := archive[:len(archive)-5]
+			assert.DeepEqual(t, []string{head, head, head}, loaded)
+		})
+	}
+}
+
+func TestSameID(t *testing.T) {
+	t.Parallel()
+	assert.BoolEqual(t, true, SameID("sha256:abc", "abc"))
+	assert.BoolEqual(t, true, SameID("sha256:abc", "sha256:abc"))
+	assert.BoolEqual(t, false, SameID("sha256:abc", "abd"))
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package nodeexec implements running debugging tools on a node
+// for the kind CLI, e.g. `kind crictl`
+package nodeexec
+
+import (
+	"context"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+)
+
+// ContainerdAddress is the containerd socket on kind nodes
+const ContainerdAddress = "/run/containerd/containerd.sock"
+
+// Tool describes a debugging tool run on a node
+type Tool struct {
+	// Name is both the command name and the binary run on the node
+	Name string
+	// Short is the command's description
+	Short string
+	// Example is the command's usage example
+	Example string
+	// Args are passed to the tool before the user's args, e.g. to
+	// configure the containerd address
+	Args []string
+}
+
+type flagpole struct {
+	Name string
+	Node string
+}
+
+// NewCommand returns a new cobra.Command running tool on a node
+func NewCommand(logger log.Logger, streams cmd.IOStreams, tool Tool) *cobra.Command {
+	flags := &flagpole{}
+	c := &cobra.Command{
+		Args:    cobra.ArbitraryArgs,
+		Use:     tool.Name + " [flags] -- [" + tool.Name + " args]",
+		Short:   tool.Short,
+		Long:    tool.Short,
+		Example: tool.Example,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli.OverrideDefaultName(cmd.Flags())
+			return Run(logger, streams, flags.Name, flags.Node, tool.command(args))
+		},
+	}
+	c.Flags().StringVar(
+		&flags.Name,
+		"name",
+		cluster.DefaultName,
+		"the cluster context name",
+	)
+	c.Flags().StringVar(
+		&flags.Node,
+		"node",
+		"",
+		"the node to run on, by full name or without the cluster prefix (e.g. worker), defaults to the first control plane",
+	)
+	return c
+}
+
+// command returns the command to run on the node for the user's args
+func (t Tool) command(args []string) []string {
+	command := append([]string{t.Name}, t.Args...)
+	return append(command, args...)
+}
+
+// Run runs command on the node named node in the cluster named name,
+// connecting it to streams. If node is empty the first control plane is used.
+// A non-zero exit from command is an error with errors.ErrNodeCommandFailed
+// and the command's exit code, so the kind CLI exits with it
+func Run(logger log.Logger, streams cmd.IOStreams, name, node string, command []string) error {
+	provider := cluster.NewProvider(
+		cluster.ProviderWithLogger(logger),
+		runtime.GetDefault(logger),
+		runtime.NamePrefix(),
+	)
+	all, err := provider.ListNodes(name)
+	if err != nil {
+		return err
+	}
+	if len(all) == 0 {
+		return errors.ClusterNotFound(name)
+	}
+	n, err := selectNode(all, provider.ClusterName(name), node)
+	if err != nil {
+		return err
+	}
+	exitCode, err := nodes.ExecStream(context.Background(), n, nodes.ExecOptions{
+		Command: command,
+		Stdin:   streams.In,
+		Stdout:  streams.Out,
+		Stderr:  streams.ErrOut,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to run %s on node %s", command[0], n.String())
+	}
+	if exitCode != 0 {
+		return errors.WithExitCode(errors.WithReason(
+			errors.Errorf("%s exited with status %d on node %s", command[0], exitCode, n.String()),
+			errors.ErrNodeCommandFailed,
+		), exitCode)
+	}
+	return nil
+}
+
+// selectNode returns the node in all matching node, which may be either the
+// full node name or the name without the cluster prefix, e.g. "worker"
+// if node is empty the bootstrap control plane node is returned
+func selectNode(all []nodes.Node, name, node string) (nodes.Node, error) {
+	if node == "" {
+		return nodeutils.BootstrapControlPlaneNode(all)
+	}
+	names := make([]string, 0, len(all))
+	for _, n := range all {
+		if n.String() == node || n.String() == name+"-"+node {
+			return n, nil
+		}
+		names = append(names, n.String())
+	}
+	return nil, errors.Errorf("unknown node %q, expected one of: %s", node, strings.Join(names, ", "))
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeexec
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+type fakeNode struct {
+	nodes.Node
+	name string
+	role string
+}
+
+func (n *fakeNode) String() string {
+	return n.name
+}
+
+func (n *fakeNode) Role() (string, error) {
+	return n.role, nil
+}
+
+func TestSelectNode(t *testing.T) {
+	t.Parallel()
+	all := []nodes.Node{
+		&fakeNode{name: "foo-worker", role: constants.WorkerNodeRoleValue},
+		&fakeNode{name: "foo-control-plane", role: constants.ControlPlaneNodeRoleValue},
+	}
+	cases := []struct {
+		Name        string
+		Node        string
+		Expected    string
+		ExpectError bool
+	}{
+		{Name: "default to control plane", Node: "", Expected: "foo-control-plane"},
+		{Name: "full name", Node: "foo-worker", Expected: "foo-worker"},
+		{Name: "short name", Node: "worker", Expected: "foo-worker"},
+		{Name: "unknown", Node: "worker2", ExpectError: true},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			n, err := selectNode(all, "foo", tc.Node)
+			assert.ExpectError(t, tc.ExpectError, err)
+			if err == nil {
+				assert.StringEqual(t, tc.Expected, n.String())
+			}
+		})
+	}
+}
+
+func TestToolCommand(t *testing.T) {
+	t.Parallel()
+	tool := Tool{Name: "ctr", Args: []string{"--namespace", "k8s.io"}}
+	assert.DeepEqual(t, []string{"ctr", "--namespace", "k8s.io", "images", "ls"}, tool.command([]string{"images", "ls"}))
+	assert.DeepEqual(t, []string{"ctr", "--namespace", "k8s.io"}, tool.command(nil))
+	// the tool's args must not be shared between commands
+	first := tool.command([]string{"a"})
+	tool.command([]string{"b"})
+	assert.DeepEqual(t, []string{"ctr", "--namespace", "k8s.io", "a"}, first)
+}
+
+func TestNewCommand(t *testing.T) {
+	t.Parallel()
+	c := NewCommand(nil, cmd.StandardIOStreams(), Tool{Name: "crictl", Short: "Runs crictl"})
+	assert.StringEqual(t, "crictl [flags] -- [crictl args]", c.Use)
+	assert.StringEqual(t, "Runs crictl", c.Long)
+	for _, flag := range []string{"name", "node"} {
+		if c.Flags().Lookup(flag) == nil {
+			t.Errorf("expected --%s flag", flag)
+		}
+	}
+}
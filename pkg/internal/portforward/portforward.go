@@ -0,0 +1,203 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package portforward implements the userland TCP forwarder of `kind forward`,
+// which listens on the privileged host ports a rootless container runtime
+// cannot publish and forwards them to the ports published instead
+package portforward
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+// Forward forwards TCP connections to Listen to Target, both host:port
+type Forward struct {
+	Listen string
+	Target string
+}
+
+// String returns f in the `kind forward --port` format, LISTEN=TARGET
+func (f Forward) String() string {
+	return f.Listen + "=" + f.Target
+}
+
+// Parse parses a Forward in the `kind forward --port` format, LISTEN=TARGET
+func Parse(s string) (Forward, error) {
+	parts := strings.Split(s, "=")
+	if len(parts) != 2 {
+		return Forward{}, errors.Errorf("invalid forward %q, expected LISTEN_ADDRESS:PORT=TARGET_ADDRESS:PORT", s)
+	}
+	for _, hostPort := range parts {
+		if _, port, err := net.SplitHostPort(hostPort); err != nil {
+			return Forward{}, errors.Wrapf(err, "invalid forward %q", s)
+		} else if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+			return Forward{}, errors.Errorf("invalid forward %q, %q is not a port", s, port)
+		}
+	}
+	return Forward{Listen: parts[0], Target: parts[1]}, nil
+}
+
+// Run forwards connections until ctx is done. It listens on each Forward's
+// address, unless given a listener for it by systemd socket activation.
+func Run(ctx context.Context, logger log.Logger, forwards []Forward) error {
+	activated, err := activationListeners()
+	if err != nil {
+		return err
+	}
+	listeners := make([]net.Listener, len(forwards))
+	defer func() {
+		for _, l := range listeners {
+			if l != nil {
+				l.Close()
+			}
+		}
+	}()
+	for i, f := range forwards {
+		if l := takeListener(&activated, f.Listen); l != nil {
+			listeners[i] = l
+			continue
+		}
+		l, err := net.Listen("tcp", f.Listen)
+		if err != nil {
+			return errors.Wrapf(err, "failed to listen on %s", f.Listen)
+		}
+		listeners[i] = l
+	}
+	for _, l := range activated {
+		l.Close()
+		logger.Warnf("ignoring socket activated listener %s without a forward", l.Addr())
+	}
+
+	// closing the listeners stops their accept loops
+	go func() {
+		<-ctx.Done()
+		for _, l := range listeners {
+			l.Close()
+		}
+	}()
+	var wg sync.WaitGroup
+	for i, f := range forwards {
+		wg.Add(1)
+		go func(l net.Listener, f Forward) {
+			defer wg.Done()
+			serve(ctx, logger, l, f.Target)
+		}(listeners[i], f)
+		logger.V(0).Infof("Forwarding %s to %s", f.Listen, f.Target)
+	}
+	wg.Wait()
+	return nil
+}
+
+// serve forwards the connections accepted by l to target until l is closed
+func serve(ctx context.Context, logger log.Logger, l net.Listener, target string) {
+	var dialer net.Dialer
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Errorf("failed to accept on %s: %v", l.Addr(), err)
+			}
+			return
+		}
+		go func() {
+			defer conn.Close()
+			upstream, err := dialer.DialContext(ctx, "tcp", target)
+			if err != nil {
+				logger.V(1).Infof("failed to connect to %s: %v", target, err)
+				return
+			}
+			defer upstream.Close()
+			proxy(conn, upstream)
+		}()
+	}
+}
+
+// proxy copies between a and b until both directions are done
+func proxy(a, b net.Conn) {
+	var wg sync.WaitGroup
+	copyHalf := func(dst, src net.Conn) {
+		defer wg.Done()
+		_, _ = io.Copy(dst, src)
+		// let the other side see EOF while the reverse direction drains
+		if c, ok := dst.(interface{ CloseWrite() error }); ok {
+			_ = c.CloseWrite()
+		}
+	}
+	wg.Add(2)
+	go copyHalf(a, b)
+	go copyHalf(b, a)
+	wg.Wait()
+}
+
+// listenFDsStart is the first file descriptor passed by systemd socket activation
+const listenFDsStart = 3
+
+// activationListeners returns the listeners passed by systemd socket
+// activation, see sd_listen_fds(3)
+func activationListeners() ([]net.Listener, error) {
+	if os.Getenv("LISTEN_PID") != strconv.Itoa(os.Getpid()) {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid LISTEN_FDS")
+	}
+	listeners := make([]net.Listener, 0, n)
+	for fd := listenFDsStart; fd < listenFDsStart+n; fd++ {
+		f := os.NewFile(uintptr(fd), "LISTEN_FD_"+strconv.Itoa(fd))
+		l, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			return nil, errors.Wrapf(err, "socket activated file descriptor %d is not a TCP listener", fd)
+		}
+		listeners = append(listeners, l)
+	}
+	return listeners, nil
+}
+
+// takeListener removes and returns the listener in listeners for address,
+// preferring an exact match over one on the same port
+func takeListener(listeners *[]net.Listener, address string) net.Listener {
+	_, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil
+	}
+	match := -1
+	for i, l := range *listeners {
+		if l.Addr().String() == address {
+			match = i
+			break
+		}
+		if _, p, err := net.SplitHostPort(l.Addr().String()); err == nil && p == port && match == -1 {
+			match = i
+		}
+	}
+	if match == -1 {
+		return nil
+	}
+	l := (*listeners)[match]
+	*listeners = append((*listeners)[:match], (*listeners)[match+1:]...)
+	return l
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package portforward
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name        string
+		Value       string
+		Expected    Forward
+		ExpectError bool
+	}{
+		{
+			Name:     "IPv4",
+			Value:    "0.0.0.0:80=127.0.0.1:40080",
+			Expected: Forward{Listen: "0.0.0.0:80", Target: "127.0.0.1:40080"},
+		},
+		{
+			Name:     "IPv6",
+			Value:    "[::]:443=[::1]:40443",
+			Expected: Forward{Listen: "[::]:443", Target: "[::1]:40443"},
+		},
+		{
+			Name:        "missing target",
+			Value:       "0.0.0.0:80",
+			ExpectError: true,
+		},
+		{
+			Name:        "missing port",
+			Value:       "0.0.0.0=127.0.0.1:40080",
+			ExpectError: true,
+		},
+		{
+			Name:        "bogus port",
+			Value:       "0.0.0.0:http=127.0.0.1:40080",
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			result, err := Parse(tc.Value)
+			assert.ExpectError(t, tc.ExpectError, err)
+			assert.DeepEqual(t, tc.Expected, result)
+			if err == nil {
+				assert.StringEqual(t, tc.Value, result.String())
+			}
+		})
+	}
+}
+
+func TestSystemdUnits(t *testing.T) {
+	t.Parallel()
+	socket, service := SystemdUnits("kind-forward", "/usr/local/bin/kind", []Forward{
+		{Listen: "0.0.0.0:80", Target: "127.0.0.1:40080"},
+		{Listen: "0.0.0.0:443", Target: "127.0.0.1:40443"},
+	})
+	assert.StringEqual(t, `[Unit]
+Description=kind port forwards kind-forward
+
+[Socket]
+ListenStream=0.0.0.0:80
+ListenStream=0.0.0.0:443
+BindIPv6Only=ipv6-only
+
+[Install]
+WantedBy=sockets.target
+`, socket)
+	assert.StringEqual(t, `[Unit]
+Description=kind port forwards kind-forward
+Requires=kind-forward.socket
+After=kind-forward.socket
+
+[Service]
+ExecStart=/usr/local/bin/kind forward --port 0.0.0.0:80=127.0.0.1:40080 --port 0.0.0.0:443=127.0.0.1:40443
+DynamicUser=yes
+`, service)
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+	// the target replies with a greeting and closes the connection
+	target, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer target.Close()
+	go func() {
+		for {
+			conn, err := target.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = conn.Write([]byte("hello"))
+			conn.Close()
+		}
+	}()
+
+	// pick a free port for the forward to listen on
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	listen := l.Addr().String()
+	l.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- Run(ctx, log.NoopLogger{}, []Forward{{Listen: listen, Target: target.Addr().String()}})
+	}()
+
+	var conn net.Conn
+	for i := 0; i < 50; i++ {
+		if conn, err = net.Dial("tcp", listen); err == nil {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("failed to connect to the forward: %v", err)
+	}
+	reply, err := ioutil.ReadAll(conn)
+	conn.Close()
+	if err != nil {
+		t.Fatalf("failed to read from the forward: %v", err)
+	}
+	assert.StringEqual(t, "hello", string(reply))
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.ExpectError(t, false, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("Run did not return after cancellation")
+	}
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package portforward
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SystemdUnits returns a systemd socket unit listening on the forwards, and
+// the service unit it activates to run `kind forward` from executable. With
+// socket activation the forwarder itself needs no privileges.
+func SystemdUnits(name, executable string, forwards []Forward) (socket, service string) {
+	var s strings.Builder
+	fmt.Fprintf(&s, "[Unit]\nDescription=kind port forwards %s\n\n[Socket]\n", name)
+	for _, f := range forwards {
+		fmt.Fprintf(&s, "ListenStream=%s\n", f.Listen)
+	}
+	s.WriteString("BindIPv6Only=ipv6-only\n\n[Install]\nWantedBy=sockets.target\n")
+
+	args := []string{executable, "forward"}
+	for _, f := range forwards {
+		args = append(args, "--port", f.String())
+	}
+	service = fmt.Sprintf(`[Unit]
+Description=kind port forwards %[1]s
+Requires=%[1]s.socket
+After=%[1]s.socket
+
+[Service]
+ExecStart=%[2]s
+DynamicUser=yes
+`, name, strings.Join(args, " "))
+	return s.String(), service
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package presets
+
+// Ingress controllers supported by kind create cluster --ingress
+const (
+	IngressNginx   = "nginx"
+	IngressContour = "contour"
+	IngressNone    = "none"
+)
+
+// IngressReadyLabel is set on the node the ingress controller runs on,
+// which also forwards IngressPorts from the host
+const IngressReadyLabel = "ingress-ready"
+
+// IngressPorts are forwarded from the host to the ingress controller
+var IngressPorts = []int32{80, 443}
+
+// IngressManifests are the manifests for each ingress controller
+var IngressManifests = map[string]string{
+	IngressNginx:   "https://raw.githubusercontent.com/kubernetes/ingress-nginx/controller-v1.9.4/deploy/static/provider/kind/deploy.yaml",
+	IngressContour: "https://raw.githubusercontent.com/projectcontour/contour/release-1.27/examples/render/contour.yaml",
+}
+
+// IngressMinKubernetes is the oldest Kubernetes version supported by the
+// manifest of each ingress controller in IngressManifests
+var IngressMinKubernetes = map[string]string{
+	IngressNginx:   "v1.25.0",
+	IngressContour: "v1.26.0",
+}
+
+// ContourEnvoyPatch schedules contour's envoy daemonset on the ingress ready
+// node, the nginx manifest for kind already does this
+const ContourEnvoyPatch = `{
+  "spec": {
+    "template": {
+      "spec": {
+        "nodeSelector": {"ingress-ready": "true"},
+        "tolerations": [
+          {"key": "node-role.kubernetes.io/master", "operator": "Equal", "effect": "NoSchedule"},
+          {"key": "node-role.kubernetes.io/control-plane", "operator": "Equal", "effect": "NoSchedule"}
+        ]
+      }
+    }
+  }
+}`
@@ -0,0 +1,45 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package presets
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestObservabilityImages(t *testing.T) {
+	t.Parallel()
+	// the images baked into node images must match the manifest
+	images := []string{}
+	for _, m := range regexp.MustCompile(`(?m)^\s+image: (\S+)$`).FindAllStringSubmatch(ObservabilityManifest, -1) {
+		images = append(images, m[1])
+	}
+	assert.DeepEqual(t, ObservabilityImages, images)
+}
+
+func TestObservabilityManifestFor(t *testing.T) {
+	t.Parallel()
+	if !strings.Contains(ObservabilityManifestFor(false), insecureKubeletTLSArg) {
+		t.Errorf("expected metrics-server to skip kubelet TLS verification by default")
+	}
+	if strings.Contains(ObservabilityManifestFor(true), "--kubelet-insecure-tls") {
+		t.Errorf("expected metrics-server to verify kubelet TLS")
+	}
+}
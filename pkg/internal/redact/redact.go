@@ -0,0 +1,74 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package redact replaces credentials and other secrets in text kind
+// records for debugging, e.g. support bundles and command traces
+package redact
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces each secret
+const Redacted = "REDACTED"
+
+var (
+	// privateKeyRE matches PEM encoded private keys, including keys cut
+	// off before their end, e.g. by truncating output
+	privateKeyRE = regexp.MustCompile(`(?s)-----BEGIN ([A-Z ]*)PRIVATE KEY-----.*?(?:-----END ([A-Z ]*)PRIVATE KEY-----|\z)`)
+	// kubeconfigDataRE matches credentials embedded in kubeconfig files
+	kubeconfigDataRE = regexp.MustCompile(`((?:client-key-data|client-certificate-data|token):\s*)\S+`)
+	// urlCredentialsRE matches user:password@ in URLs, e.g. proxy settings
+	urlCredentialsRE = regexp.MustCompile(`://[^/\s:@"]+:[^/\s@"]+@`)
+	// secretValueRE matches key: value and KEY=value pairs where the key
+	// names a secret, the value may be quoted
+	secretValueRE = regexp.MustCompile(`(?i)([\w.-]*(?:password|passwd|secret|token|auth|_key|apikey|certificate-key)"?\s*[:=]\s*)("[^"]*"|[^\s,"]+)`)
+)
+
+// String returns s with credentials and other secrets replaced
+func String(s string) string {
+	s = privateKeyRE.ReplaceAllString(s, "-----BEGIN ${1}PRIVATE KEY-----\n"+Redacted+"\n-----END ${1}PRIVATE KEY-----")
+	s = kubeconfigDataRE.ReplaceAllString(s, "${1}"+Redacted)
+	s = urlCredentialsRE.ReplaceAllString(s, "://"+Redacted+"@")
+	return secretValueRE.ReplaceAllStringFunc(s, func(match string) string {
+		parts := secretValueRE.FindStringSubmatch(match)
+		if strings.HasPrefix(parts[2], `"`) {
+			return parts[1] + `"` + Redacted + `"`
+		}
+		return parts[1] + Redacted
+	})
+}
+
+// Args returns a copy of the command line args with secrets replaced,
+// including the values of flags naming a secret passed as a separate
+// argument, e.g. --token abcdef
+func Args(args []string) []string {
+	redacted := make([]string, len(args))
+	for i, arg := range args {
+		if i > 0 && isSecretFlag(args[i-1]) {
+			redacted[i] = Redacted
+		} else {
+			redacted[i] = String(arg)
+		}
+	}
+	return redacted
+}
+
+// isSecretFlag returns whether arg is a flag naming a secret without a value
+func isSecretFlag(arg string) bool {
+	return strings.HasPrefix(arg, "-") && !strings.Contains(arg, "=") && secretValueRE.MatchString(arg+"=value")
+}
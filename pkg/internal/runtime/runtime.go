@@ -1,25 +1,207 @@
 package runtime
 
 import (
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
 
 	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/log"
 )
 
-// GetDefault selected the default runtime from the environment override
-func GetDefault(logger log.Logger) cluster.ProviderOption {
-	switch p := os.Getenv("KIND_EXPERIMENTAL_PROVIDER"); p {
+// Auto is the runtime value requesting auto-detection
+const Auto = "auto"
+
+// DockerVM is the runtime value for docker with each node run as a VM by a
+// docker OCI runtime such as kata-runtime, see VMRuntimeEnv
+const DockerVM = "docker-vm"
+
+// VMRuntimeEnv is the environment variable setting the docker OCI runtime
+// used by DockerVM, it takes precedence over vmRuntime in ConfigPath()
+const VMRuntimeEnv = "KIND_EXPERIMENTAL_VM_RUNTIME"
+
+// Selection describes which node provider runtime was selected and why
+type Selection struct {
+	// Runtime is the selected runtime name, e.g. "docker"
+	Runtime string
+	// Reason is a human readable explanation of how Runtime was chosen
+	Reason string
+	// Detected is true if Runtime was auto-detected
+	Detected bool
+}
+
+// flagValue is the value of the --runtime flag, set by the root command
+var flagValue string
+
+// SetFlag records the value of the --runtime flag, it has the highest priority
+func SetFlag(runtime string) error {
+	if runtime != "" {
+		if err := validate(runtime); err != nil {
+			return errors.Wrap(err, "invalid --runtime")
+		}
+	}
+	flagValue = runtime
+	return nil
+}
+
+// namePrefix is the value of the --name-prefix flag if set, by the root command
+var namePrefix *string
+
+// SetNamePrefix records the value of the --name-prefix flag
+func SetNamePrefix(prefix string) {
+	namePrefix = &prefix
+}
+
+// NamePrefix returns the provider option for the --name-prefix flag, or nil
+// if it was not set so that providers default to cluster.NamePrefixEnv
+func NamePrefix() cluster.ProviderOption {
+	if namePrefix == nil {
+		return nil
+	}
+	return cluster.ProviderWithNamePrefix(*namePrefix)
+}
+
+// ConfigPath returns the path to the kind CLI config file, ~/.kind/config.yaml
+func ConfigPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".kind", "config.yaml")
+}
+
+// Select determines the runtime to use, in order of priority from:
+// the --runtime flag, KIND_EXPERIMENTAL_PROVIDER, the runtime field
+// in ConfigPath(), and finally auto-detection
+func Select() (Selection, error) {
+	s, err := selectExplicit()
+	if err != nil || s.Runtime != "" {
+		return s, err
+	}
+	return detect()
+}
+
+// selectExplicit returns the user specified runtime, if any
+func selectExplicit() (Selection, error) {
+	return selectFrom(flagValue, os.Getenv("KIND_EXPERIMENTAL_PROVIDER"), ConfigPath())
+}
+
+// selectFrom returns the runtime specified by the --runtime flag value, else
+// the KIND_EXPERIMENTAL_PROVIDER value env, else the config file at path
+func selectFrom(flag, env, path string) (Selection, error) {
+	// an explicit --runtime=auto skips the environment and config file
+	switch flag {
+	case Auto:
+		return Selection{}, nil
 	case "":
+	default:
+		return Selection{Runtime: flag, Reason: "set by --runtime flag"}, nil
+	}
+	if env != "" {
+		s := Selection{Runtime: env, Reason: "set by KIND_EXPERIMENTAL_PROVIDER"}
+		return s, validate(env)
+	}
+	cfg, err := readConfigFile(path)
+	if err != nil || cfg.Runtime == "" || cfg.Runtime == Auto {
+		return Selection{}, err
+	}
+	return Selection{Runtime: cfg.Runtime, Reason: "set by runtime in " + path}, validate(cfg.Runtime)
+}
+
+func detect() (Selection, error) {
+	detected, err := cluster.DetectNodeProvider()
+	if err != nil {
+		return Selection{}, err
+	}
+	return Selection{
+		Runtime:  detected,
+		Reason:   "auto-detected as the first available of: " + strings.Join(cluster.NodeProviderDetectionOrder(), ", "),
+		Detected: true,
+	}, nil
+}
+
+// GetDefault selected the default runtime from the --runtime flag,
+// environment override, or config file
+func GetDefault(logger log.Logger) cluster.ProviderOption {
+	s, err := selectExplicit()
+	if err != nil {
+		logger.Warnf("ignoring runtime selection: %v", err)
 		return nil
+	}
+	// auto-detection is the default behavior of cluster.NewProvider
+	if s.Runtime == "" {
+		return nil
+	}
+	logger.V(1).Infof("using %s runtime, %s", s.Runtime, s.Reason)
+	switch s.Runtime {
 	case "podman":
-		logger.Warn("using podman due to KIND_EXPERIMENTAL_PROVIDER")
 		return cluster.ProviderWithPodman()
 	case "docker":
-		logger.Warn("using docker due to KIND_EXPERIMENTAL_PROVIDER")
 		return cluster.ProviderWithDocker()
-	default:
-		logger.Warnf("ignoring unknown value %q for KIND_EXPERIMENTAL_PROVIDER", p)
+	case DockerVM:
+		return cluster.ProviderWithDockerVM(vmRuntime(logger))
+	}
+	return nil
+}
+
+// Binary returns the CLI binary of runtime, e.g. docker for DockerVM
+func Binary(runtime string) string {
+	if runtime == DockerVM {
+		return "docker"
+	}
+	return runtime
+}
+
+// vmRuntime returns the docker OCI runtime for DockerVM, from VMRuntimeEnv
+// or vmRuntime in ConfigPath(), empty for the provider default
+func vmRuntime(logger log.Logger) string {
+	if r := os.Getenv(VMRuntimeEnv); r != "" {
+		return r
+	}
+	cfg, err := readConfigFile(ConfigPath())
+	if err != nil {
+		logger.Warnf("ignoring vmRuntime: %v", err)
+	}
+	return cfg.VMRuntime
+}
+
+func validate(runtime string) error {
+	switch runtime {
+	case Auto, "docker", "podman", DockerVM:
 		return nil
+	case "nerdctl":
+		return errors.Errorf("runtime %q is not supported yet", runtime)
+	}
+	return errors.Errorf("unknown runtime %q, expected one of: docker, podman, docker-vm, auto", runtime)
+}
+
+// fileConfig is the kind CLI config file, see ConfigPath
+type fileConfig struct {
+	// Runtime is the runtime to use, see Select
+	Runtime string `yaml:"runtime"`
+	// VMRuntime is the docker OCI runtime for DockerVM
+	VMRuntime string `yaml:"vmRuntime"`
+}
+
+// readConfigFile reads the kind CLI config file, a missing file is not an
+// error
+func readConfigFile(path string) (fileConfig, error) {
+	cfg := fileConfig{}
+	if path == "" {
+		return cfg, nil
+	}
+	contents, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	} else if err != nil {
+		return cfg, errors.Wrapf(err, "failed to read %s", path)
+	}
+	if err := yaml.Unmarshal(contents, &cfg); err != nil {
+		return fileConfig{}, errors.Wrapf(err, "failed to parse %s", path)
 	}
+	return cfg, nil
 }
@@ -0,0 +1,199 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestReadConfigFile(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kind-runtime-config")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	cases := []struct {
+		Name          string
+		Contents      string
+		Missing       bool
+		Expected      fileConfig
+		ExpectedError bool
+	}{
+		{
+			Name:    "missing file",
+			Missing: true,
+		},
+		{
+			Name:     "runtime",
+			Contents: "runtime: podman\n",
+			Expected: fileConfig{Runtime: "podman"},
+		},
+		{
+			Name:     "vm runtime",
+			Contents: "runtime: docker-vm\nvmRuntime: kata-qemu\n",
+			Expected: fileConfig{Runtime: DockerVM, VMRuntime: "kata-qemu"},
+		},
+		{
+			Name:          "invalid yaml",
+			Contents:      "runtime: [docker\n",
+			ExpectedError: true,
+		},
+	}
+	for i, tc := range cases {
+		tc := tc // capture range variable
+		path := filepath.Join(dir, string(rune('a'+i))+".yaml")
+		if !tc.Missing {
+			if err := ioutil.WriteFile(path, []byte(tc.Contents), 0644); err != nil {
+				t.Fatalf("failed to write config: %v", err)
+			}
+		}
+		t.Run(tc.Name, func(t *testing.T) {
+			cfg, err := readConfigFile(path)
+			assert.ExpectError(t, tc.ExpectedError, err)
+			assert.DeepEqual(t, tc.Expected, cfg)
+		})
+	}
+}
+
+func TestSelectFrom(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kind-runtime-select")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	write := func(name, contents string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		return path
+	}
+	podmanConfig := write("podman.yaml", "runtime: podman\n")
+	autoConfig := write("auto.yaml", "runtime: auto\n")
+	invalidConfig := write("invalid.yaml", "runtime: lxc\n")
+	missingConfig := filepath.Join(dir, "missing.yaml")
+	cases := []struct {
+		Name          string
+		Flag          string
+		Env           string
+		Path          string
+		Expected      Selection
+		ExpectedError bool
+	}{
+		{
+			Name: "nothing set",
+			Path: missingConfig,
+		},
+		{
+			Name:     "flag wins over env and config",
+			Flag:     "docker",
+			Env:      "podman",
+			Path:     podmanConfig,
+			Expected: Selection{Runtime: "docker", Reason: "set by --runtime flag"},
+		},
+		{
+			Name: "auto flag skips env and config",
+			Flag: Auto,
+			Env:  "podman",
+			Path: podmanConfig,
+		},
+		{
+			Name:     "env wins over config",
+			Env:      "docker",
+			Path:     podmanConfig,
+			Expected: Selection{Runtime: "docker", Reason: "set by KIND_EXPERIMENTAL_PROVIDER"},
+		},
+		{
+			Name:     "config",
+			Path:     podmanConfig,
+			Expected: Selection{Runtime: "podman", Reason: "set by runtime in " + podmanConfig},
+		},
+		{
+			Name: "auto in config",
+			Path: autoConfig,
+		},
+		{
+			Name:          "invalid env",
+			Env:           "lxc",
+			Path:          missingConfig,
+			Expected:      Selection{Runtime: "lxc", Reason: "set by KIND_EXPERIMENTAL_PROVIDER"},
+			ExpectedError: true,
+		},
+		{
+			Name:          "unsupported env",
+			Env:           "nerdctl",
+			Path:          missingConfig,
+			Expected:      Selection{Runtime: "nerdctl", Reason: "set by KIND_EXPERIMENTAL_PROVIDER"},
+			ExpectedError: true,
+		},
+		{
+			Name:          "invalid config",
+			Path:          invalidConfig,
+			Expected:      Selection{Runtime: "lxc", Reason: "set by runtime in " + invalidConfig},
+			ExpectedError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			s, err := selectFrom(tc.Flag, tc.Env, tc.Path)
+			assert.ExpectError(t, tc.ExpectedError, err)
+			assert.DeepEqual(t, tc.Expected, s)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+	for _, r := range []string{Auto, "docker", "podman", DockerVM} {
+		assert.ExpectError(t, false, validate(r))
+	}
+	for _, r := range []string{"", "nerdctl", "Docker", "lxc"} {
+		assert.ExpectError(t, true, validate(r))
+	}
+}
+
+func TestBinary(t *testing.T) {
+	t.Parallel()
+	assert.StringEqual(t, "docker", Binary("docker"))
+	assert.StringEqual(t, "docker", Binary(DockerVM))
+	assert.StringEqual(t, "podman", Binary("podman"))
+}
+
+func TestSetFlag(t *testing.T) {
+	assert.ExpectError(t, true, SetFlag("lxc"))
+	assert.ExpectError(t, false, SetFlag(""))
+	assert.StringEqual(t, "", flagValue)
+}
+
+func TestNamePrefix(t *testing.T) {
+	defer func() { namePrefix = nil }()
+	if NamePrefix() != nil {
+		t.Errorf("expected no option when --name-prefix is not set")
+	}
+	SetNamePrefix("")
+	if NamePrefix() == nil {
+		t.Errorf("expected an option when --name-prefix is set, even to empty")
+	}
+}
@@ -0,0 +1,307 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package serve implements kind's local cluster lifecycle API server
+//
+// The API is HTTP over a unix socket:
+//
+//	GET    /v1/clusters                    list clusters
+//	POST   /v1/clusters                    create a cluster, streams progress
+//	DELETE /v1/clusters/{name}             delete a cluster
+//	GET    /v1/clusters/{name}/kubeconfig  get the kubeconfig, ?internal=true
+//	POST   /v1/clusters/{name}/images      load an image archive onto all nodes
+//
+// Streaming responses are newline delimited JSON Messages, ending in a
+// message of type "Result".
+package serve
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+// ProviderFactory returns a new cluster provider, with any options appended
+type ProviderFactory func(options ...cluster.ProviderOption) *cluster.Provider
+
+// CreateRequest is the body of a create cluster request
+type CreateRequest struct {
+	// Name is the cluster name, defaults to cluster.DefaultName
+	Name string `json:"name,omitempty"`
+	// Config is a raw kind cluster config (yaml)
+	Config string `json:"config,omitempty"`
+	// Image overrides the node image
+	Image string `json:"image,omitempty"`
+	// Retain preserves nodes on failure
+	Retain bool `json:"retain,omitempty"`
+	// Wait is how long to wait for the control plane, e.g. "5m"
+	Wait string `json:"wait,omitempty"`
+}
+
+// Message is a single streamed response message or error response
+type Message struct {
+	// Type is an event type or "Result"
+	Type string    `json:"type"`
+	Time time.Time `json:"time,omitempty"`
+	// event fields, see cluster.Event
+	Phase           string  `json:"phase,omitempty"`
+	Success         bool    `json:"success,omitempty"`
+	DurationSeconds float64 `json:"durationSeconds,omitempty"`
+	Node            string  `json:"node,omitempty"`
+	Image           string  `json:"image,omitempty"`
+	Message         string  `json:"message,omitempty"`
+	// Error and Reason are set on a failed Result
+	Error  string `json:"error,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// resultType is the Message.Type of the final message
+const resultType = "Result"
+
+// Server serves the API
+type Server struct {
+	logger      log.Logger
+	newProvider ProviderFactory
+}
+
+// NewServer returns a new Server
+func NewServer(logger log.Logger, newProvider ProviderFactory) *Server {
+	return &Server{
+		logger:      logger,
+		newProvider: newProvider,
+	}
+}
+
+// ListenAndServe serves on the unix socket at path until ctx is done
+// any stale socket at path is removed first
+func (s *Server) ListenAndServe(ctx context.Context, path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to remove existing socket")
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return errors.Wrap(err, "failed to listen")
+	}
+	defer os.Remove(path)
+	// the socket grants full control of clusters, limit it to the user
+	if err := os.Chmod(path, 0600); err != nil {
+		l.Close()
+		return errors.Wrap(err, "failed to restrict socket permissions")
+	}
+	srv := &http.Server{Handler: s.Handler()}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+	s.logger.V(0).Infof("Serving on %s", path)
+	if err := srv.Serve(l); err != nil && err != http.ErrServerClosed {
+		return errors.Wrap(err, "failed to serve")
+	}
+	return nil
+}
+
+// Handler returns the API http.Handler
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/clusters", s.handleClusters)
+	mux.HandleFunc("/v1/clusters/", s.handleCluster)
+	return mux
+}
+
+func (s *Server) handleClusters(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		clusters, err := s.newProvider().ListContext(r.Context())
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if clusters == nil {
+			clusters = []string{}
+		}
+		writeJSON(w, http.StatusOK, clusters)
+	case http.MethodPost:
+		s.create(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, errors.Errorf("unsupported method %s", r.Method))
+	}
+}
+
+func (s *Server) handleCluster(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/clusters/"), "/")
+	name := parts[0]
+	if name == "" || len(parts) > 2 {
+		writeError(w, http.StatusNotFound, errors.Errorf("not found: %s", r.URL.Path))
+		return
+	}
+	sub := ""
+	if len(parts) == 2 {
+		sub = parts[1]
+	}
+	switch {
+	case sub == "" && r.Method == http.MethodDelete:
+		if err := s.newProvider().DeleteContext(r.Context(), name, ""); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case sub == "kubeconfig" && r.Method == http.MethodGet:
+		internal := r.URL.Query().Get("internal") == "true"
+		kubeconfig, err := s.newProvider().KubeConfigContext(r.Context(), name, internal)
+		if err != nil {
+			writeError(w, statusForError(err), err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/yaml")
+		_, _ = w.Write([]byte(kubeconfig))
+	case sub == "images" && r.Method == http.MethodPost:
+		s.loadImage(w, r, name)
+	default:
+		writeError(w, http.StatusNotFound, errors.Errorf("not found: %s %s", r.Method, r.URL.Path))
+	}
+}
+
+func (s *Server) create(w http.ResponseWriter, r *http.Request) {
+	req := CreateRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, errors.Wrap(err, "invalid create request"))
+		return
+	}
+	opts := []cluster.CreateOption{
+		cluster.CreateWithNodeImage(req.Image),
+		cluster.CreateWithRetain(req.Retain),
+	}
+	if req.Config != "" {
+		opts = append(opts, cluster.CreateWithRawConfig([]byte(req.Config)))
+	}
+	if req.Wait != "" {
+		wait, err := time.ParseDuration(req.Wait)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, errors.Wrap(err, "invalid wait"))
+			return
+		}
+		opts = append(opts, cluster.CreateWithWaitForReady(wait))
+	}
+
+	// stream events until creation completes
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+	events := make(chan cluster.Event)
+	done := make(chan error, 1)
+	provider := s.newProvider(cluster.ProviderWithEventSink(events))
+	go func() {
+		done <- provider.CreateContext(r.Context(), req.Name, opts...)
+	}()
+	for {
+		select {
+		case e := <-events:
+			_ = enc.Encode(messageForEvent(e))
+			if flusher != nil {
+				flusher.Flush()
+			}
+		case err := <-done:
+			_ = enc.Encode(resultMessage(err))
+			return
+		}
+	}
+}
+
+func (s *Server) loadImage(w http.ResponseWriter, r *http.Request, name string) {
+	n, err := s.newProvider().ListInternalNodesContext(r.Context(), name)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if len(n) == 0 {
+		err := errors.ClusterNotFound(name)
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	// the body can only be read once, so load onto each node in turn
+	// from a temporary copy of the archive
+	f, err := spool(r)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	for _, node := range n {
+		if _, err := f.Seek(0, 0); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if err := nodes.LoadImage(r.Context(), node, f); err != nil {
+			writeError(w, http.StatusInternalServerError, errors.Wrapf(err, "failed to load image onto %s", node.String()))
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func messageForEvent(e cluster.Event) Message {
+	return Message{
+		Type:            string(e.Type),
+		Time:            e.Time,
+		Phase:           e.Phase,
+		Success:         e.Success,
+		DurationSeconds: e.Duration.Seconds(),
+		Node:            e.Node,
+		Image:           e.Image,
+		Message:         e.Message,
+	}
+}
+
+func resultMessage(err error) Message {
+	m := Message{Type: resultType, Time: time.Now(), Success: err == nil}
+	if err != nil {
+		m.Error = err.Error()
+		if r := errors.ReasonForError(err); r != nil {
+			m.Reason = r.Code
+		}
+	}
+	return m
+}
+
+func statusForError(err error) int {
+	if errors.ReasonForError(err) == errors.ErrClusterNotFound {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, resultMessage(err))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,262 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serve
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+// fakeDocker is a docker binary that knows the clusters "kind" and "ci-1",
+// neither of which has any nodes
+const fakeDocker = `#!/bin/sh
+if [ "$1" = ps ] && [ "$4" = "label=io.x-k8s.kind.cluster" ]; then
+	echo kind
+	echo ci-1
+	echo kind
+fi
+`
+
+// withFakeDocker puts fakeDocker first in PATH until the returned func is
+// called, tests using it must not run in parallel
+func withFakeDocker(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "kind-serve-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "docker"), []byte(fakeDocker), 0755); err != nil {
+		t.Fatalf("failed to write fake docker: %v", err)
+	}
+	path := os.Getenv("PATH")
+	if err := os.Setenv("PATH", dir+string(os.PathListSeparator)+path); err != nil {
+		t.Fatalf("failed to set PATH: %v", err)
+	}
+	return func() {
+		_ = os.Setenv("PATH", path)
+		os.RemoveAll(dir)
+	}
+}
+
+func newFakeProvider(options ...cluster.ProviderOption) *cluster.Provider {
+	return cluster.NewProvider(append([]cluster.ProviderOption{
+		cluster.ProviderWithLogger(log.NoopLogger{}),
+		cluster.ProviderWithDocker(),
+	}, options...)...)
+}
+
+func TestHandler(t *testing.T) {
+	defer withFakeDocker(t)()
+	handler := NewServer(log.NoopLogger{}, newFakeProvider).Handler()
+	cases := []struct {
+		Name           string
+		Method         string
+		Path           string
+		Body           string
+		ExpectedStatus int
+		ExpectedBody   string
+	}{
+		{
+			Name:           "list clusters",
+			Method:         http.MethodGet,
+			Path:           "/v1/clusters",
+			ExpectedStatus: http.StatusOK,
+			ExpectedBody:   `["ci-1","kind"]`,
+		},
+		{
+			Name:           "unsupported method",
+			Method:         http.MethodPut,
+			Path:           "/v1/clusters",
+			ExpectedStatus: http.StatusMethodNotAllowed,
+			ExpectedBody:   `"error":"unsupported method PUT"`,
+		},
+		{
+			Name:           "invalid create request",
+			Method:         http.MethodPost,
+			Path:           "/v1/clusters",
+			Body:           "name: kind",
+			ExpectedStatus: http.StatusBadRequest,
+			ExpectedBody:   `"error":"invalid create request`,
+		},
+		{
+			Name:           "invalid wait",
+			Method:         http.MethodPost,
+			Path:           "/v1/clusters",
+			Body:           `{"name":"kind","wait":"soon"}`,
+			ExpectedStatus: http.StatusBadRequest,
+			ExpectedBody:   `"error":"invalid wait`,
+		},
+		{
+			Name:           "no cluster name",
+			Method:         http.MethodGet,
+			Path:           "/v1/clusters/",
+			ExpectedStatus: http.StatusNotFound,
+		},
+		{
+			Name:           "unknown subresource",
+			Method:         http.MethodGet,
+			Path:           "/v1/clusters/kind/nodes",
+			ExpectedStatus: http.StatusNotFound,
+		},
+		{
+			Name:           "too deep",
+			Method:         http.MethodGet,
+			Path:           "/v1/clusters/kind/kubeconfig/extra",
+			ExpectedStatus: http.StatusNotFound,
+		},
+		{
+			Name:           "load image into unknown cluster",
+			Method:         http.MethodPost,
+			Path:           "/v1/clusters/missing/images",
+			Body:           "not read",
+			ExpectedStatus: http.StatusNotFound,
+			ExpectedBody:   `"reason":"` + errors.ErrClusterNotFound.Code + `"`,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.Method, tc.Path, strings.NewReader(tc.Body))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tc.ExpectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tc.ExpectedStatus, rec.Code, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tc.ExpectedBody) {
+				t.Errorf("expected body to contain %q, got %q", tc.ExpectedBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestListenAndServe(t *testing.T) {
+	defer withFakeDocker(t)()
+	dir, err := ioutil.TempDir("", "kind-serve-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "kind.sock")
+	// a stale socket from a previous server is replaced
+	if err := ioutil.WriteFile(socket, nil, 0644); err != nil {
+		t.Fatalf("failed to write stale socket: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- NewServer(log.NoopLogger{}, newFakeProvider).ListenAndServe(ctx, socket)
+	}()
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	var resp *http.Response
+	for i := 0; i < 50; i++ {
+		if resp, err = client.Get("http://kind/v1/clusters"); err == nil {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("failed to reach the server: %v", err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.StringEqual(t, "[\"ci-1\",\"kind\"]\n", string(body))
+
+	info, err := os.Stat(socket)
+	if err != nil {
+		t.Fatalf("failed to stat socket: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected socket permissions 0600, got %v", info.Mode().Perm())
+	}
+
+	cancel()
+	assert.ExpectError(t, false, <-done)
+	if _, err := os.Stat(socket); !os.IsNotExist(err) {
+		t.Errorf("expected the socket to be removed, got %v", err)
+	}
+}
+
+func TestSpool(t *testing.T) {
+	t.Parallel()
+	req := httptest.NewRequest(http.MethodPost, "/v1/clusters/kind/images", strings.NewReader("image archive"))
+	f, err := spool(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	if _, err := f.Seek(0, 0); err != nil {
+		t.Fatalf("failed to seek: %v", err)
+	}
+	contents, err := ioutil.ReadAll(f)
+	assert.ExpectError(t, false, err)
+	assert.StringEqual(t, "image archive", string(contents))
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+// TestSpoolReadError must not run in parallel with other spooling tests
+func TestSpoolReadError(t *testing.T) {
+	before, _ := filepath.Glob(filepath.Join(os.TempDir(), "kind-serve-image-*"))
+	req := httptest.NewRequest(http.MethodPost, "/v1/clusters/kind/images", errReader{})
+	if _, err := spool(req); err == nil {
+		t.Fatalf("expected an error")
+	}
+	after, _ := filepath.Glob(filepath.Join(os.TempDir(), "kind-serve-image-*"))
+	if len(after) > len(before) {
+		t.Errorf("expected the temporary file to be removed")
+	}
+}
+
+func TestResultMessage(t *testing.T) {
+	t.Parallel()
+	m := resultMessage(nil)
+	assert.StringEqual(t, resultType, m.Type)
+	assert.BoolEqual(t, true, m.Success)
+	notFound := errors.WithReason(errors.New("unknown cluster"), errors.ErrClusterNotFound)
+	m = resultMessage(notFound)
+	assert.BoolEqual(t, false, m.Success)
+	assert.StringEqual(t, "unknown cluster", m.Error)
+	assert.StringEqual(t, errors.ErrClusterNotFound.Code, m.Reason)
+	if statusForError(notFound) != http.StatusNotFound {
+		t.Errorf("expected not found for %v", notFound)
+	}
+	if statusForError(errors.New("boom")) != http.StatusInternalServerError {
+		t.Errorf("expected an internal error for other errors")
+	}
+}
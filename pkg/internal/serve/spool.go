@@ -0,0 +1,41 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serve
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// spool copies the request body to a temporary file, the caller must
+// close and remove the file
+func spool(r *http.Request) (*os.File, error) {
+	f, err := ioutil.TempFile("", "kind-serve-image-")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create temporary file")
+	}
+	if _, err := io.Copy(f, r.Body); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, errors.Wrap(err, "failed to read image archive")
+	}
+	return f, nil
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package trace records the commands kind runs to a JSONL trace file, and
+// reads them back, see the --trace-file flag and kind trace show
+package trace
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// FileEnv is the environment variable setting the default trace file, see
+// the --trace-file flag
+const FileEnv = "KIND_TRACE_FILE"
+
+// Recorder writes trace entries to a file, one JSON object per line
+type Recorder struct {
+	mu   sync.Mutex
+	w    io.Writer
+	err  error
+	path string
+	// closed is set once Close is called, entries recorded after are dropped
+	closed bool
+}
+
+// Open returns a Recorder appending to the trace file at path, creating it
+// if it does not exist. Entries are written as they are recorded, so the
+// file is complete up to the last command even if kind is interrupted.
+// The file is only readable by the user, commands may include secrets
+func Open(path string) (*Recorder, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open trace file")
+	}
+	return &Recorder{w: f, path: path}, nil
+}
+
+// NewRecorder returns a Recorder writing to w
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{w: w}
+}
+
+// Record writes e, it implements exec.Tracer. Only the first error writing
+// is kept, see Err
+func (r *Recorder) Record(e exec.TraceEntry) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		r.setErr(err)
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil || r.closed {
+		return
+	}
+	if _, err := r.w.Write(append(b, '\n')); err != nil {
+		r.err = errors.Wrapf(err, "failed to write trace file %s", r.path)
+	}
+}
+
+// Err returns the first error recording an entry, if any
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// Close closes the trace file of a Recorder from Open, and returns the first
+// error recording an entry or closing the file, if any
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return r.err
+	}
+	r.closed = true
+	if c, ok := r.w.(io.Closer); ok {
+		if err := c.Close(); err != nil && r.err == nil {
+			r.err = errors.Wrapf(err, "failed to close trace file %s", r.path)
+		}
+	}
+	return r.err
+}
+
+func (r *Recorder) setErr(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// Read reads the entries of a trace file from r, in the order they were
+// recorded. A partially written last line, e.g. from an interrupted command,
+// is ignored
+func Read(r io.Reader) ([]exec.TraceEntry, error) {
+	entries := []exec.TraceEntry{}
+	reader := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		b, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return entries, nil
+		} else if err != nil {
+			return nil, errors.Wrap(err, "failed to read trace")
+		}
+		if len(b) == 1 {
+			continue
+		}
+		e := exec.TraceEntry{}
+		if err := json.Unmarshal(b, &e); err != nil {
+			return nil, errors.Wrapf(err, "invalid trace entry on line %d", line)
+		}
+		if len(e.Command) == 0 {
+			return nil, errors.Errorf("invalid trace entry on line %d, no command", line)
+		}
+		entries = append(entries, e)
+	}
+}
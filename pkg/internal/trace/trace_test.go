@@ -0,0 +1,164 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trace
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestRecordRead(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	r := NewRecorder(&buf)
+	started := time.Date(2020, 10, 14, 12, 0, 0, 0, time.UTC)
+	entries := []exec.TraceEntry{
+		{Command: []string{"docker", "ps"}, Started: started, Duration: 0.5, Output: "ok\n", OutputBytes: 3},
+		{Command: []string{"docker", "inspect", "kind-control-plane"}, Started: started.Add(time.Second), ExitCode: 1, Error: "exit status 1", OutputBytes: 0},
+	}
+	for _, e := range entries {
+		r.Record(e)
+	}
+	assert.ExpectError(t, false, r.Err())
+	if lines := strings.Count(buf.String(), "\n"); lines != 2 {
+		t.Errorf("expected one line per entry, got %d lines", lines)
+	}
+	read, err := Read(&buf)
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, entries, read)
+}
+
+func TestOpen(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kind-trace")
+	assert.ExpectError(t, false, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "trace.jsonl")
+	r, err := Open(path)
+	assert.ExpectError(t, false, err)
+	r.Record(exec.TraceEntry{Command: []string{"docker", "ps"}})
+	assert.ExpectError(t, false, r.Close())
+	// entries after closing are dropped
+	r.Record(exec.TraceEntry{Command: []string{"docker", "info"}})
+	assert.ExpectError(t, false, r.Err())
+	info, err := os.Stat(path)
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, os.FileMode(0600), info.Mode().Perm())
+	f, err := os.Open(path)
+	assert.ExpectError(t, false, err)
+	defer f.Close()
+	read, err := Read(f)
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, 1, len(read))
+}
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name          string
+		Trace         string
+		Expected      []exec.TraceEntry
+		ExpectedError bool
+	}{
+		{
+			Name:     "empty",
+			Trace:    "",
+			Expected: []exec.TraceEntry{},
+		},
+		{
+			Name:  "partial last line is ignored",
+			Trace: "{\"command\":[\"docker\",\"ps\"],\"exitCode\":0}\n\n{\"command\":[\"dock",
+			Expected: []exec.TraceEntry{
+				{Command: []string{"docker", "ps"}},
+			},
+		},
+		{
+			Name:          "invalid line",
+			Trace:         "{\"command\":[\"docker\",\"ps\"]}\nnot json\n",
+			ExpectedError: true,
+		},
+		{
+			Name:          "no command",
+			Trace:         "{\"exitCode\":0}\n",
+			ExpectedError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			entries, err := Read(strings.NewReader(tc.Trace))
+			assert.ExpectError(t, tc.ExpectedError, err)
+			if !tc.ExpectedError {
+				assert.DeepEqual(t, tc.Expected, entries)
+			}
+		})
+	}
+}
+
+func TestTracer(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewRecorder(&buf)
+	exec.SetTracer(r.Record)
+	defer exec.SetTracer(nil)
+	long := strings.Repeat("x", exec.TraceOutputLimit+10)
+	_ = exec.Command("sh", "-c", "printf "+long+"; exit 3").Run()
+	exec.SetTracer(nil)
+
+	entries, err := Read(&buf)
+	assert.ExpectError(t, false, err)
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	e := entries[0]
+	assert.StringEqual(t, "sh", e.Command[0])
+	if e.ExitCode != 3 {
+		t.Errorf("expected exit code 3, got %d", e.ExitCode)
+	}
+	assert.StringEqual(t, long[:exec.TraceOutputLimit], e.Output)
+	assert.BoolEqual(t, true, e.Truncated())
+}
+
+func TestTracerRedacts(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewRecorder(&buf)
+	exec.SetTracer(r.Record)
+	defer exec.SetTracer(nil)
+	// sh ignores the arguments after the script, they only show in the trace
+	_ = exec.Command("sh", "-c", `printf "users:\n- user:\n    client-key-data: LS0tLS1CRUdJTg==\n"`, "cat", "--token", "abcdef").Run()
+	_ = exec.Command("sh", "-c", "echo abcdef.0123456789abcdef", "kubeadm", "token", "create").Run()
+	exec.SetTracer(nil)
+
+	entries, err := Read(&buf)
+	assert.ExpectError(t, false, err)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	assert.DeepEqual(t, []string{"cat", "--token", "REDACTED"}, entries[0].Command[3:])
+	assert.StringEqual(t, "users:\n- user:\n    client-key-data: REDACTED\n", entries[0].Output)
+	// the output of commands printing secrets is not recorded
+	assert.StringEqual(t, "", entries[1].Output)
+	assert.BoolEqual(t, true, entries[1].Truncated())
+}
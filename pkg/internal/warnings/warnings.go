@@ -0,0 +1,109 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package warnings implements the channel for problems with the config or
+// the host that do not stop cluster creation but are likely to cause trouble,
+// so that they are printed consistently and can be made fatal for strict CI
+package warnings
+
+import (
+	"context"
+	"sync"
+
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+// Warning is a problem that does not stop cluster creation
+type Warning struct {
+	// Source is where the problem was found, e.g. "config" or "preflight"
+	Source string
+	// Message describes the problem and how to avoid it
+	Message string
+}
+
+// String returns the warning as printed
+func (w Warning) String() string {
+	return w.Source + ": " + w.Message
+}
+
+// Recorder collects the warnings reported while creating a cluster
+type Recorder struct {
+	asErrors bool
+	mu       sync.Mutex
+	warnings []Warning
+}
+
+// NewRecorder returns a new Recorder, if asErrors is set Report fails
+// for any warning
+func NewRecorder(asErrors bool) *Recorder {
+	return &Recorder{asErrors: asErrors}
+}
+
+// Warnings returns the warnings reported so far
+func (r *Recorder) Warnings() []Warning {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Warning(nil), r.warnings...)
+}
+
+type recorderKey struct{}
+
+// WithRecorder returns a copy of ctx carrying r for Report
+func WithRecorder(ctx context.Context, r *Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, r)
+}
+
+// RecorderFromContext returns the Recorder set with WithRecorder, or nil
+func RecorderFromContext(ctx context.Context) *Recorder {
+	r, _ := ctx.Value(recorderKey{}).(*Recorder)
+	return r
+}
+
+// Report prints each of warnings prominently and records them with the
+// Recorder from ctx, if any. It returns an error with ErrWarnings as the
+// reason if there are warnings and the Recorder treats them as errors.
+func Report(ctx context.Context, logger log.Logger, warnings ...Warning) error {
+	if len(warnings) == 0 {
+		return nil
+	}
+	prefix := "WARNING"
+	if cmd.ColorEnabled(logger) {
+		prefix = "\x1b[93mWARNING\x1b[0m"
+	}
+	for _, w := range warnings {
+		logger.Warnf("%s: %s", prefix, w)
+	}
+	r := RecorderFromContext(ctx)
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	r.warnings = append(r.warnings, warnings...)
+	r.mu.Unlock()
+	if !r.asErrors {
+		return nil
+	}
+	errs := make([]error, len(warnings))
+	for i, w := range warnings {
+		errs[i] = errors.New(w.String())
+	}
+	return errors.WithReason(
+		errors.Wrap(errors.NewAggregate(errs), "warnings are treated as errors (--warnings-as-errors)"),
+		errors.ErrWarnings,
+	)
+}
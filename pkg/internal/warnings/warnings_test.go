@@ -0,0 +1,75 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package warnings
+
+import (
+	"context"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+func TestReport(t *testing.T) {
+	t.Parallel()
+	w := Warning{Source: "config", Message: "foo is deprecated"}
+	cases := []struct {
+		Name          string
+		Recorder      *Recorder
+		Warnings      []Warning
+		ExpectedError bool
+	}{
+		{
+			Name:     "no recorder",
+			Warnings: []Warning{w},
+		},
+		{
+			Name:     "recorded",
+			Recorder: NewRecorder(false),
+			Warnings: []Warning{w, w},
+		},
+		{
+			Name:          "as errors",
+			Recorder:      NewRecorder(true),
+			Warnings:      []Warning{w},
+			ExpectedError: true,
+		},
+		{
+			Name:     "nothing to report as errors",
+			Recorder: NewRecorder(true),
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+			if tc.Recorder != nil {
+				ctx = WithRecorder(ctx, tc.Recorder)
+			}
+			err := Report(ctx, log.NoopLogger{}, tc.Warnings...)
+			assert.ExpectError(t, tc.ExpectedError, err)
+			if tc.ExpectedError {
+				assert.BoolEqual(t, true, errors.ReasonForError(err) == errors.ErrWarnings)
+			}
+			if tc.Recorder != nil {
+				assert.DeepEqual(t, append([]Warning(nil), tc.Warnings...), tc.Recorder.Warnings())
+			}
+		})
+	}
+}